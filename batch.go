@@ -0,0 +1,97 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// BatchResult is the outcome of an item of a batch request, see BatchHandler.
+type BatchResult struct {
+	// Status is the status code of the response to the item.
+	Status int `json:"status"`
+	// Body is the body of the response to the item, the body is encoded as a JSON string if
+	// it is not JSON.
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// BatchHandler returns the handler of the batch endpoint of the action mounted on the service mux
+// with the given HTTP method and route path. The handler decodes the request body as a JSON array
+// of payloads and sends one request per payload to the action handler, the requests share the
+// headers and the path parameters of the batch request. The handler responds with status code
+// 207 (Multi-Status) and a JSON array listing the status code and body of the response to each
+// item in order so that the failure of some items does not fail the whole batch.
+// This function is intended for the generated code, see the Batch DSL.
+func (service *Service) BatchHandler(method, path string) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		handle := service.Mux.Lookup(method, path)
+		if handle == nil {
+			return fmt.Errorf("no handler mounted for %s %s", method, path)
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return ErrBadRequest(err)
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil {
+			return InvalidBodyError("application/json", err)
+		}
+		if len(items) == 0 {
+			return ErrBadRequest("empty batch")
+		}
+		params := ContextRequest(ctx).Params
+		itemPath, query, err := jsonrpcPath(path, params)
+		if err != nil {
+			return ErrBadRequest(err)
+		}
+		u := *req.URL
+		u.Path = itemPath
+		u.RawPath = ""
+		u.RawQuery = query.Encode()
+		results := make([]*BatchResult, len(items))
+		for i, item := range items {
+			results[i], err = serveBatchItem(handle, req, method, u.String(), params, item)
+			if err != nil {
+				return err
+			}
+		}
+		b, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		ContextResponse(ctx).Header().Set("Content-Type", "application/json")
+		return service.SendJSON(ctx, http.StatusMultiStatus, b)
+	}
+}
+
+// serveBatchItem invokes handle with a request derived from the batch request req whose body is
+// the given item and returns the recorded response.
+func serveBatchItem(handle MuxHandler, req *http.Request, method, u string, params url.Values, item json.RawMessage) (*BatchResult, error) {
+	hreq, err := http.NewRequest(method, u, bytes.NewReader(item))
+	if err != nil {
+		return nil, err
+	}
+	hreq = hreq.WithContext(req.Context())
+	for k, v := range req.Header {
+		hreq.Header[k] = v
+	}
+	hreq.Header.Del("Content-Length")
+	hreq.Header.Set("Content-Type", "application/json")
+	hreq.RemoteAddr = req.RemoteAddr
+	values := make(url.Values, len(params))
+	for n, v := range params {
+		values[n] = v
+	}
+	rw := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	handle(rw, hreq, values)
+
+	result := bytes.TrimSpace(rw.body.Bytes())
+	if len(result) > 0 && !json.Valid(result) {
+		result, _ = json.Marshal(string(result))
+	}
+	return &BatchResult{Status: rw.status, Body: result}, nil
+}
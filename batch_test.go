@@ -0,0 +1,94 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BatchHandler", func() {
+	var s *goa.Service
+	var body string
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Decoder.Register(goa.NewJSONDecoder, "*/*")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		s.Use(middleware.ErrorHandler(s, false))
+		ctrl := s.NewController("bottle")
+		create := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			payload := goa.ContextRequest(ctx).Payload.(map[string]interface{})
+			if payload["name"] == "" {
+				return s.Send(ctx, 400, goa.ErrBadRequest("missing name"))
+			}
+			payload["account"] = goa.ContextRequest(ctx).Params.Get("accountID")
+			payload["auth"] = req.Header.Get("Authorization")
+			return s.Send(ctx, 201, payload)
+		}
+		unm := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+			var payload map[string]interface{}
+			if err := service.DecodeRequest(req, &payload); err != nil {
+				return err
+			}
+			goa.ContextRequest(ctx).Payload = payload
+			return nil
+		}
+		s.Mux.Handle("POST", "/accounts/:accountID/bottles", ctrl.MuxHandler("create", create, unm))
+		s.Mux.Handle("POST", "/accounts/:accountID/bottles/batch", ctrl.MuxHandler("create batch", s.BatchHandler("POST", "/accounts/:accountID/bottles"), nil))
+	})
+
+	JustBeforeEach(func() {
+		req, err := http.NewRequest("POST", "/accounts/42/bottles/batch", strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer token")
+		rw = httptest.NewRecorder()
+		s.Mux.ServeHTTP(rw, req)
+	})
+
+	Context("with a batch of payloads", func() {
+		BeforeEach(func() {
+			body = `[{"name":"foo"},{"name":""}]`
+		})
+
+		It("invokes the action once per payload", func() {
+			Ω(rw.Code).Should(Equal(207))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+			var results []*goa.BatchResult
+			Ω(json.Unmarshal(rw.Body.Bytes(), &results)).ShouldNot(HaveOccurred())
+			Ω(results).Should(HaveLen(2))
+			Ω(results[0].Status).Should(Equal(201))
+			Ω(string(results[0].Body)).Should(MatchJSON(`{"name":"foo","account":"42","auth":"Bearer token"}`))
+			Ω(results[1].Status).Should(Equal(400))
+			Ω(string(results[1].Body)).Should(ContainSubstring("missing name"))
+		})
+	})
+
+	Context("with a body that is not an array", func() {
+		BeforeEach(func() {
+			body = `{"name":"foo"}`
+		})
+
+		It("responds with a bad request", func() {
+			Ω(rw.Code).Should(Equal(400))
+		})
+	})
+
+	Context("with an empty batch", func() {
+		BeforeEach(func() {
+			body = `[]`
+		})
+
+		It("responds with a bad request", func() {
+			Ω(rw.Code).Should(Equal(400))
+			Ω(rw.Body.String()).Should(ContainSubstring("empty batch"))
+		})
+	})
+})
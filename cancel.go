@@ -0,0 +1,86 @@
+package goa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the non standard status code popularized by nginx that the
+// request logs show for the requests whose client closed the connection before the response was
+// written, see ClientClosedHandler.
+const StatusClientClosedRequest = 499
+
+// ErrClientClosedRequest is returned by the functions that write responses when the client closed
+// the connection.
+var ErrClientClosedRequest = errors.New("client closed request")
+
+// ClientClosed returns ErrClientClosedRequest if the client of the request handled with ctx
+// closed the connection, nil otherwise. Contrary to ctx.Err it does not report the cancellation of
+// the service context on shutdown.
+func ClientClosed(ctx context.Context) error {
+	req := ContextRequest(ctx)
+	if req == nil || req.Request == nil {
+		return nil
+	}
+	if req.Context().Err() == context.Canceled {
+		return ErrClientClosedRequest
+	}
+	return nil
+}
+
+// StreamWrite writes b to the response of the request handled with ctx and flushes it so that
+// the client receives it right away. It returns ErrClientClosedRequest without writing once the
+// client closed the connection so that the handlers that stream responses stop encoding. The
+// generated action contexts expose it as their WriteChunk method.
+func StreamWrite(ctx context.Context, b []byte) error {
+	if err := ClientClosed(ctx); err != nil {
+		return err
+	}
+	resp := ContextResponse(ctx)
+	if resp == nil {
+		return errors.New("no response data in context")
+	}
+	if _, err := resp.Write(b); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
+// ClientClosedHandler returns a handler that reports the requests whose client closed the
+// connection before h returned with the StatusClientClosedRequest status: the status is set in the
+// response data for the request logs and the error returned by h is logged at the info level
+// instead of being handled as an uncaught error. The generated code wraps the handlers of the
+// actions designed with ClientClosedStatus.
+func ClientClosedHandler(h Handler) Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		err := h(ctx, rw, req)
+		if err == nil || ClientClosed(ctx) == nil {
+			return err
+		}
+		if resp := ContextResponse(ctx); resp != nil && !resp.Written() {
+			resp.Status = StatusClientClosedRequest
+		}
+		LogInfo(ctx, "client closed request", "err", err)
+		return nil
+	}
+}
+
+// withClientCancel returns a child context of ctx that is canceled when the client of req closes
+// the connection so that the handlers stop processing requests nobody waits for.
+func withClientCancel(ctx context.Context, req *http.Request) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := req.Context().Done()
+	if done == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
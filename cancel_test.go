@@ -0,0 +1,109 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client cancellation", func() {
+	var (
+		req    *http.Request
+		cancel context.CancelFunc
+		rw     *httptest.ResponseRecorder
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		var reqCtx context.Context
+		reqCtx, cancel = context.WithCancel(context.Background())
+		req = httptest.NewRequest("GET", "/bottles", nil).WithContext(reqCtx)
+		rw = httptest.NewRecorder()
+		ctx = goa.NewContext(context.Background(), rw, req, nil)
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Describe("ClientClosed", func() {
+		It("returns nil while the client is connected", func() {
+			Ω(goa.ClientClosed(ctx)).ShouldNot(HaveOccurred())
+		})
+
+		It("returns ErrClientClosedRequest once the client closed the connection", func() {
+			cancel()
+			Ω(goa.ClientClosed(ctx)).Should(Equal(goa.ErrClientClosedRequest))
+		})
+	})
+
+	Describe("StreamWrite", func() {
+		It("writes and flushes while the client is connected", func() {
+			Ω(goa.StreamWrite(ctx, []byte("chunk"))).ShouldNot(HaveOccurred())
+			Ω(rw.Body.String()).Should(Equal("chunk"))
+			Ω(rw.Flushed).Should(BeTrue())
+		})
+
+		It("stops writing once the client closed the connection", func() {
+			cancel()
+			Ω(goa.StreamWrite(ctx, []byte("chunk"))).Should(Equal(goa.ErrClientClosedRequest))
+			Ω(rw.Body.Len()).Should(Equal(0))
+		})
+	})
+
+	Describe("Send", func() {
+		It("does not encode the response once the client closed the connection", func() {
+			cancel()
+			service := goa.New("test")
+			Ω(service.Send(ctx, 200, "foo")).Should(Equal(goa.ErrClientClosedRequest))
+			Ω(rw.Body.Len()).Should(Equal(0))
+		})
+	})
+
+	Describe("ClientClosedHandler", func() {
+		var err error
+
+		handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return errors.New("canceled")
+		}
+
+		It("returns the handler errors while the client is connected", func() {
+			err = goa.ClientClosedHandler(handler)(ctx, rw, req)
+			Ω(err).Should(HaveOccurred())
+			Ω(goa.ContextResponse(ctx).Status).Should(Equal(0))
+		})
+
+		It("sets the 499 status once the client closed the connection", func() {
+			cancel()
+			err = goa.ClientClosedHandler(handler)(ctx, rw, req)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(goa.ContextResponse(ctx).Status).Should(Equal(goa.StatusClientClosedRequest))
+		})
+	})
+
+	Describe("MuxHandler", func() {
+		It("cancels the handler context when the client closes the connection", func() {
+			done := make(chan error, 1)
+			ctrl := goa.New("test").NewController("test")
+			h := ctrl.MuxHandler("act", func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				cancel()
+				select {
+				case <-ctx.Done():
+					done <- ctx.Err()
+				case <-time.After(time.Second):
+					done <- nil
+				}
+				return nil
+			}, nil)
+			h(rw, req, url.Values{})
+			Ω(<-done).Should(Equal(context.Canceled))
+		})
+	})
+})
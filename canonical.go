@@ -0,0 +1,75 @@
+package goa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CanonicalHash returns the hex encoded SHA-256 digest of the normalized request made of the
+// method, the path, the params sorted by name and value and the canonical JSON encoding of the
+// payload. The digest does not depend on the order of the params or of the payload fields so that
+// it may be used as a cache key, as an idempotency key or as the input of request signatures. The
+// generated contexts expose the digest of their request via their CanonicalHash method.
+func CanonicalHash(method, path string, params url.Values, payload interface{}) (string, error) {
+	body, err := CanonicalJSON(payload)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", strings.ToUpper(method), path, canonicalParams(params))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// CanonicalJSON returns the canonical JSON encoding of v: the object keys are sorted, there is no
+// insignificant whitespace and the numbers are written as encoded by the Go type. CanonicalJSON
+// returns nil if v is nil or a nil pointer.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
+
+// canonicalParams returns the query string encoding of params with the names and the values of
+// each name sorted.
+func canonicalParams(params url.Values) string {
+	names := make([]string, 0, len(params))
+	for n := range params {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, n := range names {
+		vals := append([]string(nil), params[n]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(n))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package goa_test
+
+import (
+	"net/url"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("canonical requests", func() {
+	type payload struct {
+		Name    string            `json:"name"`
+		Vintage int               `json:"vintage"`
+		Labels  map[string]string `json:"labels,omitempty"`
+	}
+
+	Context("CanonicalJSON", func() {
+		It("sorts the object keys", func() {
+			b, err := goa.CanonicalJSON(&payload{Name: "merlot", Vintage: 2015, Labels: map[string]string{"b": "2", "a": "1"}})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(Equal(`{"labels":{"a":"1","b":"2"},"name":"merlot","vintage":2015}`))
+		})
+
+		It("returns nil for nil pointers", func() {
+			var p *payload
+			b, err := goa.CanonicalJSON(p)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(b).Should(BeNil())
+		})
+	})
+
+	Context("CanonicalHash", func() {
+		It("does not depend on the order of the params and payload fields", func() {
+			h1, err := goa.CanonicalHash("post", "/bottles", url.Values{"a": {"2", "1"}, "b": {"x"}},
+				map[string]interface{}{"name": "merlot", "vintage": 2015})
+			Ω(err).ShouldNot(HaveOccurred())
+			h2, err := goa.CanonicalHash("POST", "/bottles", url.Values{"b": {"x"}, "a": {"1", "2"}},
+				&payload{Name: "merlot", Vintage: 2015})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(h1).Should(Equal(h2))
+			Ω(h1).Should(HaveLen(64))
+		})
+
+		It("depends on the request", func() {
+			h1, err := goa.CanonicalHash("GET", "/bottles", url.Values{"a": {"1"}}, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			h2, err := goa.CanonicalHash("GET", "/bottles", url.Values{"a": {"2"}}, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(h1).ShouldNot(Equal(h2))
+		})
+	})
+})
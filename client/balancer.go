@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Host is a base URL that a Balancer sends requests to.
+	Host struct {
+		// URL is the base URL of the host, its scheme and host replace the request ones and
+		// its path, if any, prefixes the request path.
+		URL *url.URL
+		// Weight is the relative share of the requests sent to the host by the weighted
+		// picker, zero is the same as one.
+		Weight int
+	}
+
+	// Picker selects the host of the next request among the healthy hosts. hosts is never
+	// empty. Implementations must be safe for concurrent use.
+	Picker interface {
+		Pick(hosts []*Host) *Host
+	}
+
+	// Router is implemented by the Doers that select the host of the requests such as
+	// Balancer. Route returns the request that the Doer sends unchanged. Client.Route calls it
+	// so that the signers sign the requests once their URL is final.
+	Router interface {
+		Route(req *http.Request) (*http.Request, error)
+	}
+
+	// Balancer is a Doer that distributes the requests across several hosts. Hosts that fail
+	// MaxFailures consecutive times are ejected for EjectionTime, if all the hosts are ejected
+	// the requests are distributed across all of them.
+	Balancer struct {
+		// Doer sends the requests.
+		Doer Doer
+		// Picker selects the host of each request.
+		Picker Picker
+		// MaxFailures is the number of consecutive failures that cause a host to be
+		// ejected. A failure is a transport error or a 5xx response.
+		MaxFailures int
+		// EjectionTime is the duration a failing host is ejected for.
+		EjectionTime time.Duration
+
+		lock  sync.Mutex
+		hosts []*hostState
+	}
+
+	// hostState tracks the health of a host.
+	hostState struct {
+		*Host
+		failures     int
+		ejectedUntil time.Time
+	}
+
+	// roundRobin is the round-robin picker.
+	roundRobin struct {
+		next uint32
+	}
+
+	// randomPicker is the random and weighted picker.
+	randomPicker struct {
+		lock     sync.Mutex
+		rand     *rand.Rand
+		weighted bool
+	}
+)
+
+// NewBalancer returns a Balancer that sends the requests to the given hosts using d. It ejects
+// the hosts that fail 3 consecutive times for 30 seconds. If p is nil the hosts are picked in
+// round-robin.
+func NewBalancer(d Doer, p Picker, hosts ...*Host) *Balancer {
+	if d == nil {
+		d = HTTPClientDoer(http.DefaultClient)
+	}
+	if p == nil {
+		p = RoundRobin()
+	}
+	b := &Balancer{Doer: d, Picker: p, MaxFailures: 3, EjectionTime: 30 * time.Second}
+	for _, h := range hosts {
+		b.hosts = append(b.hosts, &hostState{Host: h})
+	}
+	return b
+}
+
+// ParseHosts builds hosts with the same weight from the given base URLs, e.g.
+// "https://api1.example.com".
+func ParseHosts(urls ...string) ([]*Host, error) {
+	hosts := make([]*Host, len(urls))
+	for i, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, err
+		}
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("invalid host URL %q, must be absolute", u)
+		}
+		hosts[i] = &Host{URL: parsed}
+	}
+	return hosts, nil
+}
+
+// Route selects the host of the request and returns a copy of the request sent to that host. Do
+// sends the routed requests to the selected host unchanged. Route returns req if it has already
+// been routed.
+func (b *Balancer) Route(req *http.Request) (*http.Request, error) {
+	if _, ok := req.Context().Value(routedHostKey).(*hostState); ok {
+		return req, nil
+	}
+	h := b.pick()
+	if h == nil {
+		return nil, fmt.Errorf("no host to send %s %s to", req.Method, req.URL)
+	}
+	r := req.WithContext(context.WithValue(req.Context(), routedHostKey, h))
+	u := *req.URL
+	if h.URL.Scheme != "" {
+		u.Scheme = h.URL.Scheme
+	}
+	u.Host = h.URL.Host
+	if p := strings.TrimSuffix(h.URL.Path, "/"); p != "" {
+		u.Path = p + u.Path
+		if u.RawPath != "" {
+			u.RawPath = p + u.RawPath
+		}
+	}
+	r.URL = &u
+	r.Host = ""
+	return r, nil
+}
+
+// Do sends the request to the host selected by the picker, or by Route if the request has already
+// been routed, and records the outcome.
+func (b *Balancer) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	r, err := b.Route(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := b.Doer.Do(ctx, r)
+	b.record(r.Context().Value(routedHostKey).(*hostState), err == nil && resp.StatusCode < 500)
+	return resp, err
+}
+
+// pick returns the host of the next request.
+func (b *Balancer) pick() *hostState {
+	b.lock.Lock()
+	now := time.Now()
+	healthy := make([]*Host, 0, len(b.hosts))
+	for _, h := range b.hosts {
+		if !now.Before(h.ejectedUntil) {
+			healthy = append(healthy, h.Host)
+		}
+	}
+	if len(healthy) == 0 {
+		for _, h := range b.hosts {
+			healthy = append(healthy, h.Host)
+		}
+	}
+	b.lock.Unlock()
+	if len(healthy) == 0 {
+		return nil
+	}
+	picked := b.Picker.Pick(healthy)
+	for _, h := range b.hosts {
+		if h.Host == picked {
+			return h
+		}
+	}
+	return nil
+}
+
+// record updates the health of the host with the outcome of a request.
+func (b *Balancer) record(h *hostState, success bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if success {
+		h.failures = 0
+		return
+	}
+	h.failures++
+	if b.MaxFailures > 0 && h.failures >= b.MaxFailures {
+		h.failures = 0
+		h.ejectedUntil = time.Now().Add(b.EjectionTime)
+	}
+}
+
+// RoundRobin returns a picker that selects the hosts in turn.
+func RoundRobin() Picker {
+	return &roundRobin{}
+}
+
+// Random returns a picker that selects a host at random.
+func Random() Picker {
+	return &randomPicker{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Weighted returns a picker that selects a host at random with a probability proportional to its
+// weight.
+func Weighted() Picker {
+	return &randomPicker{rand: rand.New(rand.NewSource(time.Now().UnixNano())), weighted: true}
+}
+
+// Pick implements Picker.
+func (r *roundRobin) Pick(hosts []*Host) *Host {
+	n := atomic.AddUint32(&r.next, 1) - 1
+	return hosts[int(n%uint32(len(hosts)))]
+}
+
+// Pick implements Picker.
+func (r *randomPicker) Pick(hosts []*Host) *Host {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.weighted {
+		return hosts[r.rand.Intn(len(hosts))]
+	}
+	total := 0
+	for _, h := range hosts {
+		total += weight(h)
+	}
+	n := r.rand.Intn(total)
+	for _, h := range hosts {
+		if n < weight(h) {
+			return h
+		}
+		n -= weight(h)
+	}
+	return hosts[len(hosts)-1]
+}
+
+// weight returns the weight of the host used by the weighted picker.
+func weight(h *Host) int {
+	if h.Weight <= 0 {
+		return 1
+	}
+	return h.Weight
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// DecodeBatch decodes the results of the items of a batch request from the body of the 207
+// response resp and closes it. The results are listed in the order of the items, use the Status
+// field of a result to tell whether the item succeeded and decode its Body accordingly.
+// DecodeBatch returns a *ResponseError if the batch request failed as a whole, e.g. because the
+// request body is not a JSON array.
+func DecodeBatch(resp *http.Response) ([]*goa.BatchResult, error) {
+	if err := DecodeError(resp, nil); err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var results []*goa.BatchResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode batch results: %s", err)
+	}
+	return results, nil
+}
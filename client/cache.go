@@ -0,0 +1,416 @@
+package client
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// CacheStore stores the responses cached by the Cache middleware. Implementations must be
+	// safe for concurrent use.
+	CacheStore interface {
+		// Get returns the response stored under key, false if there is none.
+		Get(key string) (*CachedResponse, bool)
+		// Set stores the response under key.
+		Set(key string, resp *CachedResponse)
+		// Delete removes the response stored under key if any.
+		Delete(key string)
+	}
+
+	// CachedResponse is a response stored by the Cache middleware. Stored responses must not
+	// be modified.
+	CachedResponse struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+		// RequestHeader contains the values of the request headers listed in the Vary
+		// response header and of the Authorization header. The response is only used for
+		// requests with the same values.
+		RequestHeader http.Header
+		// RequestTime is the time the request was sent.
+		RequestTime time.Time
+		// ResponseTime is the time the response was received.
+		ResponseTime time.Time
+	}
+
+	// cacheControl holds the directives of a Cache-Control header.
+	cacheControl map[string]string
+
+	// lruCacheStore is the in-memory CacheStore returned by NewLRUCacheStore.
+	lruCacheStore struct {
+		lock    sync.Mutex
+		size    int
+		entries *list.List
+		index   map[string]*list.Element
+	}
+
+	// lruEntry is a response stored by lruCacheStore.
+	lruEntry struct {
+		key  string
+		resp *CachedResponse
+	}
+)
+
+// heuristicStatuses lists the statuses of the responses that may be cached without explicit
+// expiration time, see RFC 7231 section 6.1.
+var heuristicStatuses = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 404: true, 405: true, 410: true,
+	414: true, 501: true,
+}
+
+// Cache returns a client middleware that caches the responses to GET requests in store and
+// serves the subsequent requests from the cache as long as the responses are fresh. It implements
+// the subset of RFC 7234 suited to private caches:
+//
+//    - the responses are fresh for the duration given by the max-age directive of their
+//      Cache-Control header or by their Expires header, the responses that define neither
+//      and that have a Last-Modified header are fresh for a tenth of their age
+//    - the stale responses with an ETag or a Last-Modified header are revalidated with a
+//      conditional request, a 304 response refreshes the cached response
+//    - the no-store, no-cache and max-age request and response directives are honored
+//    - the responses are only reused for requests that have the same values for the headers
+//      listed in the Vary header and for the Authorization header
+//    - successful requests that use other methods than GET, HEAD, OPTIONS and TRACE evict the
+//      cached response of their URL
+//
+// Requests that are already conditional are sent as is.
+//
+//    c := client.New(nil)
+//    c.Use(goaclient.Cache(goaclient.NewLRUCacheStore(1024)))
+func Cache(store CacheStore) Middleware {
+	return func(d Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			key := "GET " + req.URL.String()
+			switch req.Method {
+			case "GET":
+			case "HEAD", "OPTIONS", "TRACE":
+				return d.Do(ctx, req)
+			default:
+				resp, err := d.Do(ctx, req)
+				if err == nil && resp.StatusCode < 400 {
+					store.Delete(key)
+				}
+				return resp, err
+			}
+			if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+				return d.Do(ctx, req)
+			}
+			reqCC := parseCacheControl(req.Header)
+			if _, ok := reqCC["no-store"]; ok {
+				return d.Do(ctx, req)
+			}
+			cached, ok := store.Get(key)
+			if ok && !cached.matches(req) {
+				cached, ok = nil, false
+			}
+			if ok && cached.usable(reqCC, time.Now()) {
+				return cached.response(req, time.Now()), nil
+			}
+			outReq := req
+			if ok {
+				if r, ok := cached.validate(req); ok {
+					outReq = r
+				} else {
+					cached = nil
+				}
+			}
+			requestTime := time.Now()
+			resp, err := d.Do(ctx, outReq)
+			if err != nil {
+				return nil, err
+			}
+			responseTime := time.Now()
+			if cached != nil && resp.StatusCode == http.StatusNotModified {
+				resp.Body.Close()
+				refreshed := cached.refresh(resp.Header, requestTime, responseTime)
+				store.Set(key, refreshed)
+				return refreshed.response(req, responseTime), nil
+			}
+			if !cacheable(resp) {
+				return resp, nil
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			store.Set(key, &CachedResponse{
+				Status:        resp.StatusCode,
+				Header:        cloneHeader(resp.Header),
+				Body:          body,
+				RequestHeader: varyHeader(req, resp.Header),
+				RequestTime:   requestTime,
+				ResponseTime:  responseTime,
+			})
+			return resp, nil
+		})
+	}
+}
+
+// NewLRUCacheStore returns an in-memory CacheStore that holds up to size responses, the least
+// recently used responses are evicted first. Zero means no limit.
+func NewLRUCacheStore(size int) CacheStore {
+	return &lruCacheStore{size: size, entries: list.New(), index: make(map[string]*list.Element)}
+}
+
+// Get implements CacheStore.
+func (s *lruCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	el, ok := s.index[key]
+	if !ok {
+		return nil, false
+	}
+	s.entries.MoveToFront(el)
+	return el.Value.(*lruEntry).resp, true
+}
+
+// Set implements CacheStore.
+func (s *lruCacheStore) Set(key string, resp *CachedResponse) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e := &lruEntry{key: key, resp: resp}
+	if el, ok := s.index[key]; ok {
+		el.Value = e
+		s.entries.MoveToFront(el)
+		return
+	}
+	s.index[key] = s.entries.PushFront(e)
+	for s.size > 0 && s.entries.Len() > s.size {
+		last := s.entries.Back()
+		s.entries.Remove(last)
+		delete(s.index, last.Value.(*lruEntry).key)
+	}
+}
+
+// Delete implements CacheStore.
+func (s *lruCacheStore) Delete(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if el, ok := s.index[key]; ok {
+		s.entries.Remove(el)
+		delete(s.index, key)
+	}
+}
+
+// matches returns true if the cached response may be used for the given request.
+func (c *CachedResponse) matches(req *http.Request) bool {
+	for name, vals := range c.RequestHeader {
+		if strings.Join(req.Header[name], ", ") != strings.Join(vals, ", ") {
+			return false
+		}
+	}
+	return true
+}
+
+// usable returns true if the cached response is fresh enough to be returned without contacting
+// the server.
+func (c *CachedResponse) usable(reqCC cacheControl, now time.Time) bool {
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	if _, ok := parseCacheControl(c.Header)["no-cache"]; ok {
+		return false
+	}
+	age := c.age(now)
+	if maxAge, ok := reqCC.duration("max-age"); ok && age > maxAge {
+		return false
+	}
+	return age < c.lifetime()
+}
+
+// age computes the current age of the cached response as defined in RFC 7234 section 4.2.3.
+func (c *CachedResponse) age(now time.Time) time.Duration {
+	var apparent time.Duration
+	if date, err := http.ParseTime(c.Header.Get("Date")); err == nil {
+		if d := c.ResponseTime.Sub(date); d > 0 {
+			apparent = d
+		}
+	}
+	corrected := c.ResponseTime.Sub(c.RequestTime)
+	if secs, err := strconv.Atoi(c.Header.Get("Age")); err == nil && secs > 0 {
+		corrected += time.Duration(secs) * time.Second
+	}
+	if apparent > corrected {
+		corrected = apparent
+	}
+	return corrected + now.Sub(c.ResponseTime)
+}
+
+// lifetime computes the freshness lifetime of the cached response as defined in RFC 7234 section
+// 4.2.1.
+func (c *CachedResponse) lifetime() time.Duration {
+	if maxAge, ok := parseCacheControl(c.Header).duration("max-age"); ok {
+		return maxAge
+	}
+	date, err := http.ParseTime(c.Header.Get("Date"))
+	if err != nil {
+		date = c.ResponseTime
+	}
+	if exp := c.Header.Get("Expires"); exp != "" {
+		expires, err := http.ParseTime(exp)
+		if err != nil {
+			return 0
+		}
+		return expires.Sub(date)
+	}
+	if lm, err := http.ParseTime(c.Header.Get("Last-Modified")); err == nil && heuristicStatuses[c.Status] {
+		return date.Sub(lm) / 10
+	}
+	return 0
+}
+
+// validate returns the conditional request used to revalidate the cached response, false if the
+// response has no validator.
+func (c *CachedResponse) validate(req *http.Request) (*http.Request, bool) {
+	etag, lm := c.Header.Get("ETag"), c.Header.Get("Last-Modified")
+	if etag == "" && lm == "" {
+		return nil, false
+	}
+	r := req.Clone(req.Context())
+	if etag != "" {
+		r.Header.Set("If-None-Match", etag)
+	}
+	if lm != "" {
+		r.Header.Set("If-Modified-Since", lm)
+	}
+	return r, true
+}
+
+// refresh returns a copy of the cached response updated with the headers of a 304 response.
+func (c *CachedResponse) refresh(header http.Header, requestTime, responseTime time.Time) *CachedResponse {
+	h := cloneHeader(c.Header)
+	for k, v := range header {
+		if k != "Content-Length" {
+			h[k] = append([]string(nil), v...)
+		}
+	}
+	if header.Get("Age") == "" {
+		h.Del("Age")
+	}
+	return &CachedResponse{
+		Status:        c.Status,
+		Header:        h,
+		Body:          c.Body,
+		RequestHeader: c.RequestHeader,
+		RequestTime:   requestTime,
+		ResponseTime:  responseTime,
+	}
+}
+
+// response builds the HTTP response returned to the client from the cached response.
+func (c *CachedResponse) response(req *http.Request, now time.Time) *http.Response {
+	h := cloneHeader(c.Header)
+	h.Set("Age", strconv.Itoa(int(c.age(now)/time.Second)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.Status, http.StatusText(c.Status)),
+		StatusCode:    c.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        h,
+		Body:          ioutil.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable returns true if the response may be stored.
+func cacheable(resp *http.Response) bool {
+	if !heuristicStatuses[resp.StatusCode] {
+		return false
+	}
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	for _, v := range resp.Header["Vary"] {
+		if strings.TrimSpace(v) == "*" {
+			return false
+		}
+	}
+	if _, ok := cc["max-age"]; ok {
+		return true
+	}
+	for _, h := range []string{"Expires", "ETag", "Last-Modified"} {
+		if resp.Header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// varyHeader returns the values of the request headers the cached response depends on.
+func varyHeader(req *http.Request, header http.Header) http.Header {
+	h := make(http.Header)
+	names := []string{"Authorization"}
+	for _, v := range header["Vary"] {
+		names = append(names, strings.Split(v, ",")...)
+	}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" {
+			h[name] = append([]string(nil), req.Header[name]...)
+		}
+	}
+	return h
+}
+
+// parseCacheControl returns the directives of the Cache-Control header, a Pragma: no-cache
+// header is treated as Cache-Control: no-cache if there is no Cache-Control header.
+func parseCacheControl(h http.Header) cacheControl {
+	cc := make(cacheControl)
+	vals := h["Cache-Control"]
+	if len(vals) == 0 && strings.Contains(h.Get("Pragma"), "no-cache") {
+		cc["no-cache"] = ""
+	}
+	for _, v := range vals {
+		for _, d := range strings.Split(v, ",") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			name, val := d, ""
+			if i := strings.Index(d, "="); i >= 0 {
+				name, val = d[:i], strings.Trim(d[i+1:], `"`)
+			}
+			cc[strings.ToLower(name)] = val
+		}
+	}
+	return cc
+}
+
+// duration returns the value of the directive in seconds as a duration, false if the directive is
+// missing or invalid.
+func (cc cacheControl) duration(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// cloneHeader returns a deep copy of h.
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -2,16 +2,35 @@ package client
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"golang.org/x/net/websocket"
+	"gopkg.in/yaml.v2"
 )
 
+// OutputOptions configures how HandleResponseOutput renders the response body.
+type OutputOptions struct {
+	// Format is the output format, one of "json", "yaml" or "table". Defaults to "json".
+	Format string
+	// Pretty indents the JSON output.
+	Pretty bool
+	// Columns lists the fields rendered as table columns. The columns default to the union of
+	// the fields of the rendered objects.
+	Columns []string
+	// Select is a jq-like path that selects the rendered fields, e.g. ".items[].name".
+	Select string
+}
+
 // HandleResponse logs the response details and exits the process with a status computed from
 // the response status code. The mapping of response status code to exit status is as follows:
 //
@@ -21,6 +40,12 @@ import (
 //    404: 4
 //    500+: 5
 func HandleResponse(c *Client, resp *http.Response, pretty bool) {
+	HandleResponseOutput(c, resp, &OutputOptions{Pretty: pretty})
+}
+
+// HandleResponseOutput behaves like HandleResponse but renders successful response bodies
+// according to the given options, see FormatOutput.
+func HandleResponseOutput(c *Client, resp *http.Response, opts *OutputOptions) {
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -35,23 +60,10 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 		}
 		fmt.Printf("error: %d%s", resp.StatusCode, sbody)
 	} else if !c.Dump && len(body) > 0 {
-		var out string
-		if pretty {
-			var jbody interface{}
-			err = json.Unmarshal(body, &jbody)
-			if err != nil {
-				out = string(body)
-			} else {
-				var b []byte
-				b, err = json.MarshalIndent(jbody, "", "    ")
-				if err == nil {
-					out = string(b)
-				} else {
-					out = string(body)
-				}
-			}
-		} else {
-			out = string(body)
+		out, err := FormatOutput(body, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to format body: %s", err)
+			os.Exit(-1)
 		}
 		fmt.Print(out)
 	}
@@ -73,6 +85,208 @@ func HandleResponse(c *Client, resp *http.Response, pretty bool) {
 	os.Exit(exitStatus)
 }
 
+// FormatOutput renders the given JSON body according to the given options. Bodies that are not
+// valid JSON are returned as is unless a selection or a format other than JSON is requested.
+func FormatOutput(body []byte, opts *OutputOptions) (string, error) {
+	if opts == nil {
+		opts = &OutputOptions{}
+	}
+	format := opts.Format
+	if format == "" {
+		format = "json"
+	}
+	switch format {
+	case "json", "yaml", "table":
+	default:
+		return "", fmt.Errorf("unsupported output format %#v, must be one of json, yaml or table", format)
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		if format == "json" && opts.Select == "" {
+			return string(body), nil
+		}
+		return "", fmt.Errorf("body is not valid JSON: %s", err)
+	}
+	if opts.Select != "" {
+		var err error
+		if v, err = selectPath(v, opts.Select); err != nil {
+			return "", err
+		}
+	}
+	switch format {
+	case "yaml":
+		b, err := yaml.Marshal(v)
+		return string(b), err
+	case "table":
+		return formatTable(v, opts.Columns), nil
+	}
+	if opts.Pretty {
+		b, err := json.MarshalIndent(v, "", "    ")
+		return string(b), err
+	}
+	if opts.Select == "" {
+		return string(body), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// selectPath returns the values of v selected by the given jq-like path. The path consists of
+// field names (".name"), array indices (".[0]" or ".items[0]") and array iterators (".items[]").
+// The result is an array if the path contains iterators.
+func selectPath(v interface{}, path string) (interface{}, error) {
+	if path == "." {
+		return v, nil
+	}
+	if !strings.HasPrefix(path, ".") {
+		return nil, fmt.Errorf("invalid selection %#v, must start with '.'", path)
+	}
+	vals, iterates := []interface{}{v}, false
+	rest := path[1:]
+	for rest != "" {
+		var next []interface{}
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid selection %#v, missing ']'", path)
+			}
+			idx := rest[1:end]
+			rest = rest[end+1:]
+			for _, val := range vals {
+				ary, ok := val.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot index %s with %#v", jsonKind(val), "["+idx+"]")
+				}
+				if idx == "" {
+					next = append(next, ary...)
+					continue
+				}
+				i, err := strconv.Atoi(idx)
+				if err != nil {
+					return nil, fmt.Errorf("invalid array index %#v", idx)
+				}
+				if i < 0 {
+					i += len(ary)
+				}
+				if i < 0 || i >= len(ary) {
+					next = append(next, nil)
+					continue
+				}
+				next = append(next, ary[i])
+			}
+			if idx == "" {
+				iterates = true
+			}
+		case rest[0] == '.':
+			rest = rest[1:]
+			continue
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			for _, val := range vals {
+				if val == nil {
+					next = append(next, nil)
+					continue
+				}
+				m, ok := val.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot select field %#v of %s", name, jsonKind(val))
+				}
+				next = append(next, m[name])
+			}
+		}
+		vals = next
+	}
+	if iterates {
+		if vals == nil {
+			vals = []interface{}{}
+		}
+		return vals, nil
+	}
+	return vals[0], nil
+}
+
+// formatTable renders the given value as a table with one row per object. Arrays are rendered
+// with one row per element, other values with a single row.
+func formatTable(v interface{}, columns []string) string {
+	rows, ok := v.([]interface{})
+	if !ok {
+		rows = []interface{}{v}
+	}
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, r := range rows {
+			if m, ok := r.(map[string]interface{}); ok {
+				for k := range m {
+					if !seen[k] {
+						seen[k] = true
+						columns = append(columns, k)
+					}
+				}
+			}
+		}
+		sort.Strings(columns)
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	if len(columns) == 0 {
+		for _, r := range rows {
+			fmt.Fprintln(w, tableCell(r))
+		}
+		w.Flush()
+		return buf.String()
+	}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, r := range rows {
+		m, _ := r.(map[string]interface{})
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = tableCell(m[c])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// tableCell returns the table representation of the given value.
+func tableCell(v interface{}) string {
+	switch actual := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return actual
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// jsonKind returns the name of the JSON type of the given value used in error messages.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	return "number"
+}
+
 // WSWrite sends STDIN lines to a websocket server.
 func WSWrite(ws *websocket.Conn) {
 	scanner := bufio.NewScanner(os.Stdin)
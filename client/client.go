@@ -21,6 +21,14 @@ type (
 		Do(context.Context, *http.Request) (*http.Response, error)
 	}
 
+	// DoerFunc is the type definition of the Doer.Do method. It implements Doer.
+	DoerFunc func(context.Context, *http.Request) (*http.Response, error)
+
+	// Middleware wraps a Doer to run code around the requests sent by the client, e.g. to
+	// log requests or record metrics. Use ContextEndpoint and ContextPayload to retrieve the
+	// action being called.
+	Middleware func(Doer) Doer
+
 	// Client is the common client data structure for all goa service clients.
 	Client struct {
 		// Doer is the underlying http client.
@@ -31,8 +39,17 @@ type (
 		Host string
 		// UserAgent is the user agent set in requests made by the client.
 		UserAgent string
+		// RequestIDHeader is the name of the header that carries the request ID of the
+		// context given to Do if any, "X-Request-Id" if empty.
+		RequestIDHeader string
 		// Dump indicates whether to dump request response.
 		Dump bool
+		// Signers sign all the requests made by the client once fully built, right before
+		// they are sent.
+		Signers []Signer
+		// Middleware lists the middleware wrapping the underlying Doer, the first middleware
+		// is the outermost one.
+		Middleware []Middleware
 	}
 )
 
@@ -47,37 +64,67 @@ func New(c Doer) *Client {
 
 // HTTPClientDoer turns a stdlib http.Client into a Doer. Use it to enable to call New() with an http.Client.
 func HTTPClientDoer(hc *http.Client) Doer {
-	return doFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
+	return DoerFunc(func(_ context.Context, req *http.Request) (*http.Response, error) {
 		return hc.Do(req)
 	})
 }
 
-// doFunc is the type definition of the Doer.Do method. It implements Doer.
-type doFunc func(context.Context, *http.Request) (*http.Response, error)
+// NoRedirectDoer turns a stdlib http.Client into a Doer that does not follow redirects. Redirect
+// responses are returned to the caller which may retrieve the redirect target using the response
+// Location method. The given client is not modified.
+func NoRedirectDoer(hc *http.Client) Doer {
+	c := *hc
+	c.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return HTTPClientDoer(&c)
+}
 
 // Do implements Doer.Do
-func (f doFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+func (f DoerFunc) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	return f(ctx, req)
 }
 
+// Use appends middleware to the client, middleware run in the order they are added.
+func (c *Client) Use(m ...Middleware) {
+	c.Middleware = append(c.Middleware, m...)
+}
+
 // Do wraps the underlying http client Do method and adds logging.
 // The logger should be in the context.
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	// TODO: setting the request ID should be done via client middleware. For now only set it if the
-	// caller provided one in the ctx.
+	// Only set the request ID header if the caller provided one in the ctx, e.g. the ID of the
+	// request being handled set by the RequestID middleware of the goa middleware package.
 	if ctxreqid := ContextRequestID(ctx); ctxreqid != "" {
-		req.Header.Set("X-Request-Id", ctxreqid)
+		header := c.RequestIDHeader
+		if header == "" {
+			header = "X-Request-Id"
+		}
+		req.Header.Set(header, ctxreqid)
 	}
 	if c.UserAgent != "" {
 		req.Header.Set("User-Agent", c.UserAgent)
 	}
+	req, err := c.Route(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range c.Signers {
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+	}
 	startedAt := time.Now()
 	ctx, id := ContextWithRequestID(ctx)
 	goa.LogInfo(ctx, "started", "id", id, req.Method, req.URL.String())
 	if c.Dump {
 		c.dumpRequest(ctx, req)
 	}
-	resp, err := c.Doer.Do(ctx, req)
+	doer := c.Doer
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		doer = c.Middleware[i](doer)
+	}
+	resp, err := doer.Do(ctx, req)
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return nil, err
@@ -89,6 +136,17 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	return resp, err
 }
 
+// Route selects the host of the request if the client Doer is a Router such as Balancer and
+// returns req unchanged otherwise. The requests must be routed before they are signed so that the
+// signatures cover the URL of the requests actually sent, Do routes the requests before running
+// the client signers and the generated code before running the security scheme signers.
+func (c *Client) Route(req *http.Request) (*http.Request, error) {
+	if r, ok := c.Doer.(Router); ok {
+		return r.Route(req)
+	}
+	return req, nil
+}
+
 // Dump request if needed.
 func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
 	reqBody, err := dumpReqBody(req)
@@ -97,7 +155,8 @@ func (c *Client) dumpRequest(ctx context.Context, req *http.Request) {
 	}
 	goa.LogInfo(ctx, "request headers", headersToSlice(req.Header)...)
 	if reqBody != nil {
-		goa.LogInfo(ctx, "request", "body", string(reqBody))
+		body := goa.RedactBody(reqBody, req.Header.Get("Content-Type"), ContextPayload(ctx))
+		goa.LogInfo(ctx, "request", "body", string(body))
 	}
 }
 
@@ -106,7 +165,8 @@ func (c *Client) dumpResponse(ctx context.Context, resp *http.Response) {
 	respBody, _ := dumpRespBody(resp)
 	goa.LogInfo(ctx, "response headers", headersToSlice(resp.Header)...)
 	if respBody != nil {
-		goa.LogInfo(ctx, "response", "body", string(respBody))
+		body := goa.RedactBody(respBody, resp.Header.Get("Content-Type"), nil)
+		goa.LogInfo(ctx, "response", "body", string(body))
 	}
 }
 
@@ -116,7 +176,9 @@ func headersToSlice(header http.Header) []interface{} {
 	i := 0
 	for k, v := range header {
 		res[i] = k
-		if len(v) == 1 {
+		if goa.IsSensitive(k) {
+			res[i+1] = goa.RedactedValue
+		} else if len(v) == 1 {
 			res[i+1] = v[0]
 		} else {
 			res[i+1] = v
@@ -221,8 +283,25 @@ func shortID() string {
 // It is private to avoid possible collisions with keys used by other packages.
 type clientKey int
 
-// ReqIDKey is the context key used to store the request ID value.
-const reqIDKey clientKey = 1
+const (
+	// ReqIDKey is the context key used to store the request ID value.
+	reqIDKey clientKey = iota + 1
+	// endpointKey is the context key used to store the resource and action names.
+	endpointKey
+	// payloadKey is the context key used to store the request payload.
+	payloadKey
+	// routedHostKey is the context key used to store the host selected by Balancer.Route.
+	routedHostKey
+)
+
+// signerKey is the type of the context keys used to store the signers of the security schemes,
+// the key value is the name of the scheme.
+type signerKey string
+
+// endpoint holds the names stored in the context by ContextWithEndpoint.
+type endpoint struct {
+	resource, action string
+}
 
 // ContextRequestID extracts the Request ID from the context.
 func ContextRequestID(ctx context.Context) string {
@@ -249,3 +328,43 @@ func ContextWithRequestID(ctx context.Context) (context.Context, string) {
 func SetContextRequestID(ctx context.Context, reqID string) context.Context {
 	return context.WithValue(ctx, reqIDKey, reqID)
 }
+
+// ContextWithEndpoint returns a context holding the names of the resource and action being called
+// and the request payload if any. Generated clients call it before sending requests so that
+// middleware can retrieve these values with ContextEndpoint and ContextPayload.
+func ContextWithEndpoint(ctx context.Context, resource, action string, payload interface{}) context.Context {
+	ctx = context.WithValue(ctx, endpointKey, endpoint{resource, action})
+	if payload != nil {
+		ctx = context.WithValue(ctx, payloadKey, payload)
+	}
+	return ctx
+}
+
+// ContextEndpoint extracts the names of the resource and action being called from the context.
+func ContextEndpoint(ctx context.Context) (resource, action string) {
+	if e, ok := ctx.Value(endpointKey).(endpoint); ok {
+		return e.resource, e.action
+	}
+	return "", ""
+}
+
+// ContextPayload extracts the payload of the request being sent from the context, the value has
+// the type of the action payload generated in the client package, e.g. *CreateBottlePayload.
+func ContextPayload(ctx context.Context) interface{} {
+	return ctx.Value(payloadKey)
+}
+
+// ContextWithSigner returns a context holding the signer used to sign the requests made with the
+// context for the security scheme with the given name. The signer overrides the signer of the
+// scheme set on the client so that a single client may send requests on behalf of different
+// callers. Generated clients define typed helpers that wrap this function for each scheme.
+func ContextWithSigner(ctx context.Context, scheme string, signer Signer) context.Context {
+	return context.WithValue(ctx, signerKey(scheme), signer)
+}
+
+// ContextSigner extracts the signer of the security scheme with the given name from the context,
+// nil if there is none.
+func ContextSigner(ctx context.Context, scheme string) Signer {
+	s, _ := ctx.Value(signerKey(scheme)).(Signer)
+	return s
+}
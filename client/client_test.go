@@ -1,9 +1,22 @@
 package client_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/goadesign/goa/client"
+	"golang.org/x/net/websocket"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -45,4 +58,902 @@ var _ = Describe("client", func() {
 			})
 		})
 	})
+
+	Context("ContextWithSigner", func() {
+		It("stores the signer of the scheme", func() {
+			signer := &client.BasicSigner{Username: "user", Password: "pass"}
+			ctx := client.ContextWithSigner(context.Background(), "basic", signer)
+			Expect(client.ContextSigner(ctx, "basic")).To(BeIdenticalTo(signer))
+			Expect(client.ContextSigner(ctx, "jwt")).To(BeNil())
+			Expect(client.ContextSigner(context.Background(), "basic")).To(BeNil())
+		})
+	})
+
+	Context("NoRedirectDoer", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/target" {
+					w.WriteHeader(200)
+					return
+				}
+				http.Redirect(w, r, "/target", http.StatusFound)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("returns the redirect response", func() {
+			req, err := http.NewRequest("GET", server.URL+"/source", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := client.NoRedirectDoer(http.DefaultClient).Do(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusFound))
+			loc, err := resp.Location()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loc.Path).To(Equal("/target"))
+		})
+	})
+
+	Context("NewDoer", func() {
+		var (
+			server *httptest.Server
+			dials  int
+		)
+
+		BeforeEach(func() {
+			dials = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					time.Sleep(200 * time.Millisecond)
+				}
+				w.WriteHeader(200)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("uses the custom dialer", func() {
+			dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials++
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			}
+			req, err := http.NewRequest("GET", server.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := client.NewDoer(client.Dialer(dial), client.MaxIdleConnsPerHost(1)).Do(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(dials).To(Equal(1))
+		})
+
+		It("enforces the request timeout", func() {
+			req, err := http.NewRequest("GET", server.URL+"/slow", nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.NewDoer(client.RequestTimeout(50*time.Millisecond)).Do(context.Background(), req)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with middleware", func() {
+		var (
+			server *httptest.Server
+			calls  []string
+		)
+
+		BeforeEach(func() {
+			calls = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(200)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		mw := func(name string) client.Middleware {
+			return func(d client.Doer) client.Doer {
+				return client.DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+					res, act := client.ContextEndpoint(ctx)
+					calls = append(calls, fmt.Sprintf("%s %s#%s %v", name, res, act, client.ContextPayload(ctx)))
+					return d.Do(ctx, req)
+				})
+			}
+		}
+
+		It("runs the middleware in order with the endpoint", func() {
+			c := client.New(nil)
+			c.Use(mw("first"), mw("second"))
+			req, err := http.NewRequest("GET", server.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			ctx := client.ContextWithEndpoint(context.Background(), "bottle", "create", "payload")
+			resp, err := c.Do(ctx, req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(200))
+			Expect(calls).To(Equal([]string{"first bottle#create payload", "second bottle#create payload"}))
+		})
+	})
+
+	Context("TraceMiddleware", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(10 * time.Millisecond)
+				w.WriteHeader(200)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("reports the request timings", func() {
+			var timings []*client.RequestTimings
+			tracer := client.TracerFunc(func(_ context.Context, _ *http.Request, t *client.RequestTimings, err error) {
+				Expect(err).ToNot(HaveOccurred())
+				timings = append(timings, t)
+			})
+			c := client.New(nil)
+			c.Use(client.TraceMiddleware(tracer))
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequest("GET", server.URL, nil)
+				Expect(err).ToNot(HaveOccurred())
+				resp, err := c.Do(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+			}
+			Expect(timings).To(HaveLen(2))
+			Expect(timings[0].Connect).To(BeNumerically(">", 0))
+			Expect(timings[0].TimeToFirstByte).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(timings[0].Total).To(BeNumerically(">=", timings[0].TimeToFirstByte))
+			Expect(timings[1].Reused).To(BeTrue())
+			Expect(timings[1].Connect).To(BeZero())
+		})
+	})
+
+	Context("MetricsMiddleware", func() {
+		var (
+			server *httptest.Server
+			meter  *recordingMeter
+		)
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/missing":
+					w.WriteHeader(404)
+				case "/broken":
+					w.WriteHeader(500)
+				default:
+					w.WriteHeader(200)
+				}
+			}))
+			meter = &recordingMeter{}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		do := func(ctx context.Context, path string) {
+			c := client.New(nil)
+			c.Use(client.MetricsMiddleware(meter))
+			req, err := http.NewRequest("GET", server.URL+path, nil)
+			Expect(err).ToNot(HaveOccurred())
+			ctx = client.ContextWithEndpoint(ctx, "bottle", "show", nil)
+			if resp, err := c.Do(ctx, req.WithContext(ctx)); err == nil {
+				resp.Body.Close()
+			}
+		}
+
+		It("records the calls and their duration per endpoint", func() {
+			do(context.Background(), "/")
+			Expect(meter.names).To(Equal([]string{client.MetricCalls, client.MetricDuration}))
+			Expect(meter.values[0]).To(Equal(1.0))
+			Expect(meter.values[1]).To(BeNumerically(">", 0))
+			Expect(meter.labels[0]).To(Equal(map[string]string{
+				"resource": "bottle", "action": "show", "method": "GET", "status": "200", "error_class": "",
+			}))
+		})
+
+		It("labels the calls with their error class", func() {
+			do(context.Background(), "/missing")
+			do(context.Background(), "/broken")
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			do(ctx, "/")
+			Expect(meter.labels[0]["error_class"]).To(Equal(client.ErrorClassClient))
+			Expect(meter.labels[2]["error_class"]).To(Equal(client.ErrorClassServer))
+			Expect(meter.labels[4]["error_class"]).To(Equal(client.ErrorClassCanceled))
+			Expect(meter.labels[4]["status"]).To(BeEmpty())
+		})
+	})
+
+	Context("Balancer", func() {
+		var (
+			servers []*httptest.Server
+			hits    []int
+			hosts   []*client.Host
+		)
+
+		BeforeEach(func() {
+			servers, hits = nil, make([]int, 3)
+			for i := 0; i < 3; i++ {
+				i := i
+				servers = append(servers, httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					hits[i]++
+					if i == 2 {
+						w.WriteHeader(503)
+						return
+					}
+					w.WriteHeader(200)
+				})))
+			}
+			var err error
+			hosts, err = client.ParseHosts(servers[0].URL, servers[1].URL, servers[2].URL)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			for _, s := range servers {
+				s.Close()
+			}
+		})
+
+		send := func(b *client.Balancer, n int) {
+			for i := 0; i < n; i++ {
+				req, err := http.NewRequest("GET", "http://unused/path", nil)
+				Expect(err).ToNot(HaveOccurred())
+				resp, err := b.Do(context.Background(), req)
+				Expect(err).ToNot(HaveOccurred())
+				resp.Body.Close()
+			}
+		}
+
+		It("distributes the requests in round-robin and ejects failing hosts", func() {
+			b := client.NewBalancer(nil, nil, hosts...)
+			b.MaxFailures = 2
+			send(b, 12)
+			Expect(hits[2]).To(Equal(2))
+			Expect(hits[0]).To(Equal(5))
+			Expect(hits[1]).To(Equal(5))
+		})
+
+		It("routes the requests before the client signs them", func() {
+			var signed, received []string
+			var lock sync.Mutex
+			prefixed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				received = append(received, "http://"+r.Host+r.URL.Path)
+				lock.Unlock()
+			}))
+			defer prefixed.Close()
+			prefixedHosts, err := client.ParseHosts(prefixed.URL + "/v1")
+			Expect(err).ToNot(HaveOccurred())
+			c := client.New(client.NewBalancer(nil, nil, prefixedHosts...))
+			c.Signers = append(c.Signers, signerFunc(func(req *http.Request) error {
+				signed = append(signed, req.URL.String())
+				return nil
+			}))
+			req, err := http.NewRequest("GET", "http://unused/path", nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := c.Do(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			resp.Body.Close()
+			Expect(signed).To(Equal([]string{prefixed.URL + "/v1/path"}))
+			Expect(received).To(Equal(signed))
+		})
+
+		It("honors the host weights", func() {
+			hosts[0].Weight = 1000
+			b := client.NewBalancer(nil, client.Weighted(), hosts[0], hosts[1])
+			send(b, 20)
+			Expect(hits[0]).To(BeNumerically(">", 15))
+		})
+	})
+
+	Context("Hedge", func() {
+		var (
+			server *httptest.Server
+			lock   sync.Mutex
+			calls  int
+		)
+
+		BeforeEach(func() {
+			calls = 0
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				calls++
+				n := calls
+				lock.Unlock()
+				if n == 1 {
+					// The first request is slow.
+					select {
+					case <-r.Context().Done():
+					case <-time.After(200 * time.Millisecond):
+					}
+				}
+				w.Write([]byte(fmt.Sprintf("%d", n)))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		do := func(method string) (string, int) {
+			c := client.New(nil)
+			c.Use(client.Hedge(20 * time.Millisecond))
+			req, err := http.NewRequest(method, server.URL, nil)
+			Expect(err).ToNot(HaveOccurred())
+			resp, err := c.Do(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			lock.Lock()
+			defer lock.Unlock()
+			return string(b), calls
+		}
+
+		It("returns the response of the hedged request", func() {
+			body, n := do("GET")
+			Expect(body).To(Equal("2"))
+			Expect(n).To(Equal(2))
+		})
+
+		It("does not hedge non idempotent requests", func() {
+			body, n := do("POST")
+			Expect(body).To(Equal("1"))
+			Expect(n).To(Equal(1))
+		})
+	})
+
+	Context("Cache", func() {
+		var (
+			server  *httptest.Server
+			c       *client.Client
+			header  http.Header
+			status  int
+			calls   int
+			lastReq *http.Request
+			lastSt  int
+		)
+
+		BeforeEach(func() {
+			calls = 0
+			status = http.StatusOK
+			header = http.Header{}
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				lastReq = r
+				for k, v := range header {
+					w.Header()[k] = v
+				}
+				if etag := header.Get("ETag"); etag != "" && r.Header.Get("If-None-Match") == etag {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(fmt.Sprintf("%d", calls)))
+			}))
+			c = client.New(nil)
+			c.Use(client.Cache(client.NewLRUCacheStore(10)))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		do := func(method, path string, hdr ...string) string {
+			req, err := http.NewRequest(method, server.URL+path, nil)
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < len(hdr); i += 2 {
+				req.Header.Set(hdr[i], hdr[i+1])
+			}
+			resp, err := c.Do(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			b, err := ioutil.ReadAll(resp.Body)
+			Expect(err).ToNot(HaveOccurred())
+			lastSt = resp.StatusCode
+			return string(b)
+		}
+
+		It("serves fresh responses from the cache", func() {
+			header.Set("Cache-Control", "max-age=60")
+			Expect(do("GET", "/")).To(Equal("1"))
+			Expect(do("GET", "/")).To(Equal("1"))
+			Expect(lastSt).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(1))
+			Expect(do("GET", "/other")).To(Equal("2"))
+		})
+
+		It("does not cache responses without freshness or validator", func() {
+			do("GET", "/")
+			do("GET", "/")
+			Expect(calls).To(Equal(2))
+		})
+
+		It("does not store no-store responses", func() {
+			header.Set("Cache-Control", "no-store, max-age=60")
+			do("GET", "/")
+			do("GET", "/")
+			Expect(calls).To(Equal(2))
+		})
+
+		It("revalidates stale responses with their ETag", func() {
+			header.Set("Cache-Control", "max-age=0")
+			header.Set("ETag", `"v1"`)
+			Expect(do("GET", "/")).To(Equal("1"))
+			Expect(do("GET", "/")).To(Equal("1"))
+			Expect(lastSt).To(Equal(http.StatusOK))
+			Expect(calls).To(Equal(2))
+			Expect(lastReq.Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		})
+
+		It("honors the request no-cache directive", func() {
+			header.Set("Cache-Control", "max-age=60")
+			do("GET", "/")
+			Expect(do("GET", "/", "Cache-Control", "no-cache")).To(Equal("2"))
+			Expect(calls).To(Equal(2))
+		})
+
+		It("does not share responses across Vary and Authorization header values", func() {
+			header.Set("Cache-Control", "max-age=60")
+			header.Set("Vary", "Accept")
+			Expect(do("GET", "/", "Accept", "application/json", "Authorization", "a")).To(Equal("1"))
+			Expect(do("GET", "/", "Accept", "application/json", "Authorization", "a")).To(Equal("1"))
+			Expect(do("GET", "/", "Accept", "application/xml", "Authorization", "a")).To(Equal("2"))
+			Expect(do("GET", "/", "Accept", "application/xml", "Authorization", "b")).To(Equal("3"))
+		})
+
+		It("evicts the cached response on unsafe requests", func() {
+			header.Set("Cache-Control", "max-age=60")
+			do("GET", "/")
+			do("POST", "/")
+			Expect(do("GET", "/")).To(Equal("3"))
+		})
+	})
+
+	Context("Poll", func() {
+		var opts = &client.PollOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+		It("calls the function until it is done", func() {
+			calls := 0
+			err := client.Poll(context.Background(), opts, func(context.Context) (bool, error) {
+				calls++
+				return calls == 3, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(calls).To(Equal(3))
+		})
+
+		It("returns the function error", func() {
+			err := client.Poll(context.Background(), opts, func(context.Context) (bool, error) {
+				return false, errors.New("boom")
+			})
+			Expect(err).To(MatchError("boom"))
+		})
+
+		It("stops when the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := client.Poll(ctx, opts, func(context.Context) (bool, error) {
+				return false, nil
+			})
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+	})
+
+	Context("Upload", func() {
+		var opts = &client.UploadOptions{ChunkSize: 4, Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+		var received bytes.Buffer
+		var patches, failures int
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			received.Reset()
+			received.WriteString("he")
+			patches, failures = 0, 1
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/uploads/abc" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.Method == "PATCH" {
+					patches++
+					if r.Header.Get("Upload-Offset") != fmt.Sprint(received.Len()) {
+						w.WriteHeader(http.StatusConflict)
+						return
+					}
+					if failures > 0 {
+						failures--
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					body, _ := ioutil.ReadAll(r.Body)
+					received.Write(body)
+				}
+				w.Header().Set("Upload-Offset", fmt.Sprint(received.Len()))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("resumes the upload and retries the failed chunks", func() {
+			c := client.HTTPClientDoer(http.DefaultClient)
+			content := strings.NewReader("hello world")
+			err := client.Upload(context.Background(), c, server.URL+"/uploads/abc", content, content.Size(), opts)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(received.String()).To(Equal("hello world"))
+			Expect(patches).To(Equal(4))
+		})
+
+		It("does not retry client errors", func() {
+			c := client.HTTPClientDoer(http.DefaultClient)
+			content := strings.NewReader("hello world")
+			err := client.Upload(context.Background(), c, server.URL+"/uploads/unknown", content, content.Size(), opts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.(*client.ResponseError).Status).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("Stream", func() {
+		var opts *client.StreamOptions
+		var resumes []string
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			resumes = nil
+			opts = &client.StreamOptions{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+			server = httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+				resumes = append(resumes, ws.Request().Header.Get("Last-Event-ID"))
+				if ws.Request().URL.Path == "/hold" {
+					ioutil.ReadAll(ws)
+					return
+				}
+				websocket.Message.Send(ws, "event")
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		dial := func(path string) func(context.Context) (*websocket.Conn, error) {
+			return func(ctx context.Context) (*websocket.Conn, error) {
+				u := "ws" + strings.TrimPrefix(server.URL, "http") + path
+				cfg, err := websocket.NewConfig(u, u)
+				if err != nil {
+					return nil, err
+				}
+				client.ResumeStream(ctx, cfg)
+				return websocket.DialConfig(cfg)
+			}
+		}
+
+		It("reconnects with the resume token until the handler is done", func() {
+			opts.Resume = func(cfg *websocket.Config) { cfg.Header.Set("Last-Event-ID", "42") }
+			received := 0
+			err := client.Stream(context.Background(), opts, dial("/"), func(ws *websocket.Conn) error {
+				var msg string
+				if err := websocket.Message.Receive(ws, &msg); err != nil {
+					return err
+				}
+				received++
+				if received < 3 {
+					return errors.New("disconnected")
+				}
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resumes).To(Equal([]string{"", "42", "42"}))
+		})
+
+		It("gives up after the maximum number of attempts", func() {
+			opts.MaxAttempts = 3
+			attempts := 0
+			err := client.Stream(context.Background(), opts, func(context.Context) (*websocket.Conn, error) {
+				attempts++
+				return nil, errors.New("refused")
+			}, nil)
+			Expect(err).To(MatchError("refused"))
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("closes the connection when the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			err := client.Stream(ctx, opts, dial("/hold"), func(ws *websocket.Conn) error {
+				var msg string
+				return websocket.Message.Receive(ws, &msg)
+			})
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+	})
+
+	Context("FormatOutput", func() {
+		const body = `{"items":[{"id":1,"name":"foo"},{"id":2,"name":"bar","tags":["a"]}],"count":2}`
+
+		var opts *client.OutputOptions
+		var out string
+		var err error
+
+		BeforeEach(func() {
+			opts = &client.OutputOptions{}
+		})
+
+		JustBeforeEach(func() {
+			out, err = client.FormatOutput([]byte(body), opts)
+		})
+
+		It("returns the body as is by default", func() {
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(Equal(body))
+		})
+
+		Context("with a selection", func() {
+			BeforeEach(func() {
+				opts.Select = ".items[].name"
+			})
+
+			It("renders the selected fields", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(`["foo","bar"]`))
+			})
+		})
+
+		Context("with a selection of an array element", func() {
+			BeforeEach(func() {
+				opts.Select = ".items[-1].tags[0]"
+			})
+
+			It("renders the selected element", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal(`"a"`))
+			})
+		})
+
+		Context("with an invalid selection", func() {
+			BeforeEach(func() {
+				opts.Select = ".count.value"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("with the yaml format", func() {
+			BeforeEach(func() {
+				opts.Format = "yaml"
+				opts.Select = ".items[0]"
+			})
+
+			It("renders YAML", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal("id: 1\nname: foo\n"))
+			})
+		})
+
+		Context("with the table format", func() {
+			BeforeEach(func() {
+				opts.Format = "table"
+				opts.Select = ".items"
+			})
+
+			It("renders one row per element", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(out).To(Equal("ID  NAME  TAGS\n1   foo   \n2   bar   [\"a\"]\n"))
+			})
+
+			Context("and columns", func() {
+				BeforeEach(func() {
+					opts.Columns = []string{"name"}
+				})
+
+				It("renders the given columns", func() {
+					Expect(err).ToNot(HaveOccurred())
+					Expect(out).To(Equal("NAME\nfoo\nbar\n"))
+				})
+			})
+		})
+
+		Context("with an unknown format", func() {
+			BeforeEach(func() {
+				opts.Format = "xml"
+			})
+
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Context("DecodeBatch", func() {
+		It("decodes the item results", func() {
+			resp := &http.Response{StatusCode: 207, Body: ioutil.NopCloser(strings.NewReader(`[{"status":201,"body":{"id":1}},{"status":400}]`))}
+			results, err := client.DecodeBatch(resp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Status).To(Equal(201))
+			Expect(string(results[0].Body)).To(MatchJSON(`{"id":1}`))
+			Expect(results[1].Status).To(Equal(400))
+		})
+
+		It("returns a response error if the batch failed", func() {
+			resp := &http.Response{StatusCode: 400, Body: ioutil.NopCloser(strings.NewReader(`{"detail":"empty batch"}`))}
+			_, err := client.DecodeBatch(resp)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("400 Bad Request: empty batch"))
+		})
+	})
+
+	Context("DecodeError", func() {
+		var errNotFound = errors.New("NotFound")
+		var resp *http.Response
+		var err error
+
+		BeforeEach(func() {
+			resp = &http.Response{StatusCode: 404, Body: ioutil.NopCloser(strings.NewReader(`{"code":"not_found","detail":"no bottle"}`))}
+		})
+
+		JustBeforeEach(func() {
+			err = client.DecodeError(resp, map[int]error{404: errNotFound})
+		})
+
+		It("wraps the error registered for the status code", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, errNotFound)).To(BeTrue())
+			Expect(err.Error()).To(Equal("NotFound: no bottle"))
+			var re *client.ResponseError
+			Expect(errors.As(err, &re)).To(BeTrue())
+			Expect(re.Status).To(Equal(404))
+			Expect(re.ErrorResponse().Code).To(Equal("not_found"))
+		})
+
+		Context("with a status code with no registered error", func() {
+			BeforeEach(func() {
+				resp.StatusCode = 500
+				resp.Body = ioutil.NopCloser(strings.NewReader("oops"))
+			})
+
+			It("returns a response error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, errNotFound)).To(BeFalse())
+				Expect(err.Error()).To(Equal("500 Internal Server Error: oops"))
+			})
+		})
+
+		Context("with a successful response", func() {
+			BeforeEach(func() {
+				resp.StatusCode = 200
+			})
+
+			It("returns nil", func() {
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("PartReader", func() {
+		var resp *http.Response
+
+		BeforeEach(func() {
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", `inline; name="item"`)
+			h.Set("Content-Type", "application/json")
+			p, _ := w.CreatePart(h)
+			p.Write([]byte(`{"name":"foo"}`))
+			h = make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", `inline; name="image"`)
+			h.Set("Content-Type", "image/png")
+			p, _ = w.CreatePart(h)
+			p.Write([]byte("png"))
+			w.Close()
+			resp = &http.Response{
+				Header: http.Header{"Content-Type": {"multipart/mixed; boundary=" + w.Boundary()}},
+				Body:   ioutil.NopCloser(&body),
+			}
+		})
+
+		It("iterates over the parts", func() {
+			r, err := client.NewPartReader(resp)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.Next()).To(BeTrue())
+			Expect(r.Name()).To(Equal("item"))
+			var v map[string]string
+			Expect(r.Decode(&v)).To(Succeed())
+			Expect(v).To(Equal(map[string]string{"name": "foo"}))
+			Expect(r.Next()).To(BeTrue())
+			Expect(r.Name()).To(Equal("image"))
+			Expect(r.ContentType()).To(Equal("image/png"))
+			b, err := r.Bytes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("png"))
+			Expect(r.Next()).To(BeFalse())
+			Expect(r.Err()).ToNot(HaveOccurred())
+		})
+
+		It("rejects responses that are not multipart", func() {
+			resp.Header.Set("Content-Type", "application/json")
+			_, err := client.NewPartReader(resp)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Load", func() {
+		var calls map[string]int
+		var mu sync.Mutex
+
+		target := func(name string, status int) *client.LoadTarget {
+			return &client.LoadTarget{Name: name, Call: func(context.Context) (*http.Response, error) {
+				mu.Lock()
+				calls[name]++
+				mu.Unlock()
+				return &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader("ok"))}, nil
+			}}
+		}
+
+		BeforeEach(func() {
+			calls = make(map[string]int)
+		})
+
+		It("sends the requests to the targets in turn", func() {
+			opts := &client.LoadOptions{Requests: 5, Concurrency: 1}
+			reports := client.Load(context.Background(), opts, target("show", 200), target("create", 500))
+			Expect(calls).To(Equal(map[string]int{"show": 3, "create": 2}))
+			Expect(reports).To(HaveLen(2))
+			Expect(reports[0].Name).To(Equal("show"))
+			Expect(reports[0].Requests).To(Equal(3))
+			Expect(reports[0].Errors).To(Equal(0))
+			Expect(reports[1].Requests).To(Equal(2))
+			Expect(reports[1].Errors).To(Equal(2))
+			Expect(reports[0].Min).To(BeNumerically("<=", reports[0].P50))
+			Expect(reports[0].P99).To(BeNumerically("<=", reports[0].Max))
+		})
+
+		It("stops sending requests after the duration", func() {
+			opts := &client.LoadOptions{RPS: 100, Concurrency: 2, Duration: 50 * time.Millisecond}
+			reports := client.Load(context.Background(), opts, target("show", 200))
+			Expect(reports[0].Requests).To(BeNumerically(">", 0))
+			Expect(reports[0].Requests).To(BeNumerically("<=", 6))
+		})
+	})
 })
+
+// recordingMeter is a client.Meter that records the metrics it receives.
+type recordingMeter struct {
+	names  []string
+	values []float64
+	labels []map[string]string
+}
+
+func (m *recordingMeter) Add(_ context.Context, name string, incr int64, labels map[string]string) {
+	m.names = append(m.names, name)
+	m.values = append(m.values, float64(incr))
+	m.labels = append(m.labels, labels)
+}
+
+func (m *recordingMeter) Record(_ context.Context, name string, value float64, labels map[string]string) {
+	m.names = append(m.names, name)
+	m.values = append(m.values, value)
+	m.labels = append(m.labels, labels)
+}
+
+// signerFunc is a client.Signer implemented by a function.
+type signerFunc func(*http.Request) error
+
+func (f signerFunc) Sign(req *http.Request) error { return f(req) }
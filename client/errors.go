@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// ResponseError is the error returned by DecodeError for responses whose status code indicates a
+// failure. It wraps the error registered for the response status code if any so that callers may
+// use errors.Is to test for specific responses, e.g. errors.Is(err, client.ErrNotFound) given the
+// sentinel errors declared by the generated client package.
+type ResponseError struct {
+	// Status is the response status code.
+	Status int
+	// Body is the response body.
+	Body []byte
+	// Err is the error registered for the response status code, nil if there is none.
+	Err error
+}
+
+// DecodeError returns a *ResponseError if the status code of the given response is 400 or more,
+// nil otherwise. The body of the response is read and closed in the former case. sentinels
+// contains the errors wrapped by the returned error indexed by response status code.
+func DecodeError(resp *http.Response, sentinels map[int]error) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read body: %s", err)
+	}
+	return &ResponseError{Status: resp.StatusCode, Body: body, Err: sentinels[resp.StatusCode]}
+}
+
+// Error returns the error message.
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	if e.Err != nil {
+		msg = e.Err.Error()
+	}
+	if r := e.ErrorResponse(); r != nil && r.Detail != "" {
+		return msg + ": " + r.Detail
+	}
+	if len(e.Body) > 0 {
+		return msg + ": " + string(e.Body)
+	}
+	return msg
+}
+
+// Unwrap returns the error registered for the response status code.
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorResponse returns the goa error response contained in the body, nil if the body is not
+// one.
+func (e *ResponseError) ErrorResponse() *goa.ErrorResponse {
+	var r goa.ErrorResponse
+	if err := json.Unmarshal(e.Body, &r); err != nil || (r.Code == "" && r.Detail == "") {
+		return nil
+	}
+	return &r
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// hedgeResult is the outcome of one of the requests sent by the hedge middleware.
+type hedgeResult struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// cancelBody cancels the request context of the winning request once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context.
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Hedge returns a client middleware that sends a second identical request if the first one has
+// not completed after delay and returns the first successful response, the other request is
+// cancelled. A response is successful if it does not have a 5xx status. Hedging only applies to
+// the idempotent methods (GET, HEAD, OPTIONS, PUT and DELETE) and to requests whose body can be
+// sent again, other requests are sent once.
+//
+//    c := client.New(nil)
+//    c.Use(goaclient.Hedge(50 * time.Millisecond))
+func Hedge(delay time.Duration) Middleware {
+	return func(d Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			if !hedgeable(req) {
+				return d.Do(ctx, req)
+			}
+			var (
+				results = make(chan *hedgeResult, 2)
+				cancels []context.CancelFunc
+			)
+			send := func(r *http.Request) {
+				rctx, cancel := context.WithCancel(req.Context())
+				i := len(cancels)
+				cancels = append(cancels, cancel)
+				go func() {
+					resp, err := d.Do(ctx, r.WithContext(rctx))
+					results <- &hedgeResult{index: i, resp: resp, err: err, cancel: cancel}
+				}()
+			}
+			send(req)
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			pending := 1
+			for {
+				select {
+				case <-timer.C:
+					r, err := cloneRequest(req)
+					if err != nil {
+						continue
+					}
+					pending++
+					send(r)
+				case res := <-results:
+					pending--
+					success := res.err == nil && res.resp.StatusCode < 500
+					if !success && pending > 0 {
+						discard(res)
+						continue
+					}
+					if pending > 0 {
+						for i, cancel := range cancels {
+							if i != res.index {
+								cancel()
+							}
+						}
+						go func() { discard(<-results) }()
+					}
+					if res.err != nil {
+						res.cancel()
+						return nil, res.err
+					}
+					res.resp.Body = &cancelBody{ReadCloser: res.resp.Body, cancel: res.cancel}
+					return res.resp, nil
+				}
+			}
+		})
+	}
+}
+
+// hedgeable returns true if the request can be sent twice.
+func hedgeable(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// cloneRequest returns a copy of req with a new body.
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// discard cancels the request of a response that is not returned and releases its body.
+func discard(res *hedgeResult) {
+	res.cancel()
+	if res.resp != nil {
+		res.resp.Body.Close()
+	}
+}
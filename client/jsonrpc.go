@@ -0,0 +1,65 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/goadesign/goa"
+)
+
+// NewJSONRPCRequest creates the request that calls the given JSON-RPC 2.0 method of the endpoint
+// at the given URL with the given named parameters. The "payload" parameter if any contains the
+// action payload.
+func NewJSONRPCRequest(ctx context.Context, u, method string, params map[string]interface{}) (*http.Request, error) {
+	reqID := ContextRequestID(ctx)
+	if reqID == "" {
+		reqID = shortID()
+	}
+	id, err := json.Marshal(reqID)
+	if err != nil {
+		return nil, err
+	}
+	r := goa.JSONRPCRequest{JSONRPC: "2.0", Method: method, ID: id}
+	if len(params) > 0 {
+		if r.Params, err = json.Marshal(params); err != nil {
+			return nil, fmt.Errorf("failed to encode params: %s", err)
+		}
+	}
+	body, err := json.Marshal(&r)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(ctx), nil
+}
+
+// JSONRPCResult reads the JSON-RPC 2.0 response resp and returns a response whose body is the
+// call result so that it can be decoded with the same functions as the responses of the HTTP
+// endpoints. It returns a *goa.JSONRPCError if the call failed, the error data contains the body
+// of the action error response. resp is returned as is if its status is not 200.
+func JSONRPCResult(resp *http.Response) (*http.Response, error) {
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var r goa.JSONRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON-RPC response: %s", err)
+	}
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	res := *resp
+	res.Header = http.Header{"Content-Type": {"application/json"}}
+	res.Body = ioutil.NopCloser(bytes.NewReader(r.Result))
+	res.ContentLength = int64(len(r.Result))
+	return &res, nil
+}
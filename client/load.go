@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// LoadTarget is an endpoint exercised by Load.
+	LoadTarget struct {
+		// Name identifies the endpoint in the reports.
+		Name string
+		// Call makes one request to the endpoint.
+		Call func(context.Context) (*http.Response, error)
+	}
+
+	// LoadOptions configures the rate and the length of the runs of Load.
+	LoadOptions struct {
+		// RPS is the number of requests sent per second across all the targets, the requests
+		// are sent as fast as the workers allow if zero.
+		RPS float64
+		// Concurrency is the number of requests in flight at any time, it defaults to 1.
+		Concurrency int
+		// Duration is the time after which no more requests are sent, it defaults to 10
+		// seconds.
+		Duration time.Duration
+		// Requests is the total number of requests sent across all the targets if not zero.
+		Requests int
+	}
+
+	// LoadReport summarizes the latencies of the requests made to one target.
+	LoadReport struct {
+		// Name is the name of the target.
+		Name string
+		// Requests is the number of requests made to the target.
+		Requests int
+		// Errors is the number of requests that failed or got a response with a status
+		// code of 400 or more.
+		Errors int
+		// Min, P50, P90, P99 and Max are the latency percentiles of the requests.
+		Min, P50, P90, P99, Max time.Duration
+	}
+)
+
+// Load sends requests to the given targets in turn at the rate configured by opts and returns a
+// report per target in the same order. opts may be nil in which case the defaults are used. The
+// response bodies are read entirely so that the latencies include their transfer. Load returns
+// once the duration elapses, the number of requests is reached or ctx is done and all the
+// requests in flight have completed.
+//
+//    reports := goaclient.Load(ctx, &goaclient.LoadOptions{RPS: 50, Concurrency: 4}, targets...)
+//    for _, r := range reports {
+//        fmt.Println(r)
+//    }
+func Load(ctx context.Context, opts *LoadOptions, targets ...*LoadTarget) []*LoadReport {
+	if len(targets) == 0 {
+		return nil
+	}
+	concurrency, duration := 1, 10*time.Second
+	var rps float64
+	var total int
+	if opts != nil {
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+		if opts.Duration > 0 {
+			duration = opts.Duration
+		}
+		rps, total = opts.RPS, opts.Requests
+	}
+
+	schedule, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		if rps > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for i := 0; total <= 0 || i < total; i++ {
+			if tick != nil {
+				select {
+				case <-schedule.Done():
+					return
+				case <-tick:
+				}
+			}
+			select {
+			case <-schedule.Done():
+				return
+			case jobs <- i % len(targets):
+			}
+		}
+	}()
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies = make([][]time.Duration, len(targets))
+		errs      = make([]int, len(targets))
+	)
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				failed := true
+				if resp, err := targets[i].Call(ctx); err == nil {
+					io.Copy(ioutil.Discard, resp.Body)
+					resp.Body.Close()
+					failed = resp.StatusCode >= 400
+				}
+				d := time.Since(start)
+				mu.Lock()
+				latencies[i] = append(latencies[i], d)
+				if failed {
+					errs[i]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	reports := make([]*LoadReport, len(targets))
+	for i, t := range targets {
+		reports[i] = newLoadReport(t.Name, latencies[i], errs[i])
+	}
+	return reports
+}
+
+// String returns a one line summary of the report.
+func (r *LoadReport) String() string {
+	return fmt.Sprintf("%s: %d requests, %d errors, min %s, p50 %s, p90 %s, p99 %s, max %s",
+		r.Name, r.Requests, r.Errors, r.Min, r.P50, r.P90, r.P99, r.Max)
+}
+
+// newLoadReport computes the percentiles of the given latencies.
+func newLoadReport(name string, latencies []time.Duration, errs int) *LoadReport {
+	r := &LoadReport{Name: name, Requests: len(latencies), Errors: errs}
+	if len(latencies) == 0 {
+		return r
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(math.Ceil(p*float64(len(latencies))))-1]
+	}
+	r.Min, r.Max = latencies[0], latencies[len(latencies)-1]
+	r.P50, r.P90, r.P99 = percentile(0.5), percentile(0.9), percentile(0.99)
+	return r
+}
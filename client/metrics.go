@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// MetricCalls is the name of the counter incremented by MetricsMiddleware for each request.
+	MetricCalls = "goa.client.calls"
+	// MetricDuration is the name of the histogram that records the duration of the requests
+	// in seconds.
+	MetricDuration = "goa.client.duration"
+)
+
+const (
+	// ErrorClassClient labels the requests that received a 4xx response.
+	ErrorClassClient = "client_error"
+	// ErrorClassServer labels the requests that received a 5xx response.
+	ErrorClassServer = "server_error"
+	// ErrorClassTimeout labels the requests that timed out or whose context deadline passed.
+	ErrorClassTimeout = "timeout"
+	// ErrorClassCanceled labels the requests whose context was canceled.
+	ErrorClassCanceled = "canceled"
+	// ErrorClassTransport labels the requests that failed without receiving a response.
+	ErrorClassTransport = "transport"
+)
+
+type (
+	// Meter receives the metrics recorded by MetricsMiddleware. Its methods mirror the
+	// synchronous instruments of OpenTelemetry so that the metrics may be exported by an
+	// adapter that forwards Add to an Int64Counter and Record to a Float64Histogram created
+	// with the given names, converting the labels to attributes.
+	Meter interface {
+		// Add adds incr to the counter with the given name.
+		Add(ctx context.Context, name string, incr int64, labels map[string]string)
+		// Record records value in the histogram with the given name.
+		Record(ctx context.Context, name string, value float64, labels map[string]string)
+	}
+)
+
+// MetricsMiddleware returns a client middleware that records the number and the duration of the
+// requests in m under the names MetricCalls and MetricDuration. The metrics are labeled with the
+// resource and action names of the endpoint (see ContextEndpoint), the response status code and
+// the error class which is empty for successful requests and one of the ErrorClass constants
+// otherwise. This mirrors the per endpoint metrics recorded on the server side.
+func MetricsMiddleware(m Meter) Middleware {
+	return func(d Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := d.Do(ctx, req)
+			resource, action := ContextEndpoint(ctx)
+			labels := map[string]string{
+				"resource":    resource,
+				"action":      action,
+				"method":      req.Method,
+				"status":      "",
+				"error_class": errorClass(ctx, req, resp, err),
+			}
+			if resp != nil {
+				labels["status"] = strconv.Itoa(resp.StatusCode)
+			}
+			m.Add(ctx, MetricCalls, 1, labels)
+			m.Record(ctx, MetricDuration, time.Since(start).Seconds(), labels)
+			return resp, err
+		})
+	}
+}
+
+// errorClass returns the error class of a request given its outcome. The request context may
+// differ from ctx when the caller set it with http.Request.WithContext.
+func errorClass(ctx context.Context, req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		for _, c := range []context.Context{req.Context(), ctx} {
+			switch c.Err() {
+			case context.Canceled:
+				return ErrorClassCanceled
+			case context.DeadlineExceeded:
+				return ErrorClassTimeout
+			}
+		}
+		if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassTransport
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		return ErrorClassServer
+	case resp.StatusCode >= 400:
+		return ErrorClassClient
+	}
+	return ""
+}
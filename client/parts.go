@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// PartReader iterates over the parts of a multipart/mixed response as they are received. The
+// generated clients wrap it in iterators that decode the parts defined in the design.
+type PartReader struct {
+	resp *http.Response
+	mr   *multipart.Reader
+	part *multipart.Part
+	err  error
+}
+
+// NewPartReader returns a reader of the parts of the given response. It returns an error if the
+// response is not a multipart response.
+func NewPartReader(resp *http.Response) (*PartReader, error) {
+	mt, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart response content type: %s", err)
+	}
+	if !strings.HasPrefix(mt, "multipart/") {
+		return nil, fmt.Errorf("unexpected content type %#v, expected multipart/mixed", mt)
+	}
+	return &PartReader{resp: resp, mr: multipart.NewReader(resp.Body, params["boundary"])}, nil
+}
+
+// Next advances to the next part. It returns false once all the parts have been read or if
+// reading the response fails, Err returns the error in the latter case.
+func (r *PartReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	p, err := r.mr.NextPart()
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.part = nil
+		return false
+	}
+	r.part = p
+	return true
+}
+
+// Name returns the name of the current part.
+func (r *PartReader) Name() string {
+	if r.part == nil {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(r.part.Header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return params["name"]
+}
+
+// ContentType returns the content type of the current part.
+func (r *PartReader) ContentType() string {
+	if r.part == nil {
+		return ""
+	}
+	return r.part.Header.Get("Content-Type")
+}
+
+// Decode decodes the JSON content of the current part into v.
+func (r *PartReader) Decode(v interface{}) error {
+	if r.part == nil {
+		return fmt.Errorf("no current part")
+	}
+	return json.NewDecoder(r.part).Decode(v)
+}
+
+// Bytes returns the content of the current part.
+func (r *PartReader) Bytes() ([]byte, error) {
+	if r.part == nil {
+		return nil, fmt.Errorf("no current part")
+	}
+	return ioutil.ReadAll(r.part)
+}
+
+// Err returns the error that stopped the iteration if any.
+func (r *PartReader) Err() error {
+	return r.err
+}
+
+// Close closes the response body.
+func (r *PartReader) Close() error {
+	return r.resp.Body.Close()
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// PollOptions configures the backoff used by Poll.
+type PollOptions struct {
+	// Interval is the delay before the second attempt, it defaults to one second.
+	Interval time.Duration
+	// MaxInterval caps the delay between two attempts, it defaults to 30 seconds.
+	MaxInterval time.Duration
+	// Multiplier is the factor applied to the delay after each attempt, it defaults to 2.
+	Multiplier float64
+}
+
+// Poll calls fn until it returns true or an error, waiting between two calls with an exponential
+// backoff configured by opts. opts may be nil in which case the defaults are used. Poll returns
+// the error returned by fn or the context error if ctx is done before fn completes.
+//
+//    err := goaclient.Poll(ctx, nil, func(ctx context.Context) (bool, error) {
+//        job, err := c.ShowJob(ctx, path)
+//        ...
+//    })
+func Poll(ctx context.Context, opts *PollOptions, fn func(context.Context) (bool, error)) error {
+	interval, max, mult := time.Second, 30*time.Second, 2.0
+	if opts != nil {
+		if opts.Interval > 0 {
+			interval = opts.Interval
+		}
+		if opts.MaxInterval > 0 {
+			max = opts.MaxInterval
+		}
+		if opts.Multiplier >= 1 {
+			mult = opts.Multiplier
+		}
+	}
+	if interval > max {
+		interval = max
+	}
+	for {
+		done, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		interval = time.Duration(float64(interval) * mult)
+		if interval > max {
+			interval = max
+		}
+	}
+}
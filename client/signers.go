@@ -50,6 +50,29 @@ type (
 		TokenSource TokenSource
 	}
 
+	// CSRFSigner echoes the CSRF token issued by the service in the request header as required
+	// by the double submit cookie CSRF protection.
+	CSRFSigner struct {
+		// Jar is the cookie jar of the HTTP client, the signer reads the token cookie from
+		// it. Jar is ignored if Token is not empty.
+		Jar http.CookieJar
+		// Token is a token value sent both as cookie and as header if not empty.
+		Token string
+		// CookieName is the name of the token cookie, defaults to "csrf_token".
+		CookieName string
+		// HeaderName is the name of the header that echoes the token, defaults to
+		// "X-CSRF-Token".
+		HeaderName string
+	}
+
+	// CookieSigner sends the session cookie issued by a service using cookie security.
+	CookieSigner struct {
+		// Name is the name of the session cookie, defaults to "session".
+		Name string
+		// Value is the value of the session cookie as issued by the service.
+		Value string
+	}
+
 	// Token is the interface to an OAuth2 token implementation.
 	// It can be implemented with https://godoc.org/golang.org/x/oauth2#Token.
 	Token interface {
@@ -89,16 +112,17 @@ func (s *BasicSigner) Sign(req *http.Request) error {
 	return nil
 }
 
-// Sign adds the API key header to the request.
+// Sign adds the API key header to the request. The signer is not modified so that it may be
+// shared by concurrent requests.
 func (s *APIKeySigner) Sign(req *http.Request) error {
-	if s.KeyName == "" {
-		s.KeyName = "Authorization"
-	}
-	if s.Format == "" {
-		s.Format = "Bearer %s"
-	}
 	name := s.KeyName
+	if name == "" {
+		name = "Authorization"
+	}
 	format := s.Format
+	if format == "" {
+		format = "Bearer %s"
+	}
 	val := fmt.Sprintf(format, s.KeyValue)
 	if s.SignQuery && val != "" {
 		query := req.URL.Query()
@@ -120,6 +144,46 @@ func (s *OAuth2Signer) Sign(req *http.Request) error {
 	return signFromSource(s.TokenSource, req)
 }
 
+// Sign adds the CSRF token header and, if the token is static, the CSRF token cookie.
+func (s *CSRFSigner) Sign(req *http.Request) error {
+	cookieName := s.CookieName
+	if cookieName == "" {
+		cookieName = "csrf_token"
+	}
+	headerName := s.HeaderName
+	if headerName == "" {
+		headerName = "X-CSRF-Token"
+	}
+	token := s.Token
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: cookieName, Value: token})
+	} else if s.Jar != nil {
+		for _, c := range s.Jar.Cookies(req.URL) {
+			if c.Name == cookieName {
+				token = c.Value
+				break
+			}
+		}
+	}
+	if token != "" {
+		req.Header.Set(headerName, token)
+	}
+	return nil
+}
+
+// Sign adds the session cookie to the request.
+func (s *CookieSigner) Sign(req *http.Request) error {
+	if s.Value == "" {
+		return nil
+	}
+	name := s.Name
+	if name == "" {
+		name = "session"
+	}
+	req.AddCookie(&http.Cookie{Name: name, Value: s.Value})
+	return nil
+}
+
 // signFromSource generates a token using the given source and uses it to sign the request.
 func signFromSource(source TokenSource, req *http.Request) error {
 	token, err := source.Token()
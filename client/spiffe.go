@@ -0,0 +1,84 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// X509Source is the source of the X.509 SVID of the workload and of the trust bundle of its
+// trust domain, typically backed by the SPIFFE Workload API. Both methods are called for each
+// TLS handshake so that the rotated SVIDs and bundles are used as soon as they are issued.
+type X509Source interface {
+	// Certificate returns the current X.509 SVID of the workload and its private key.
+	Certificate() (*tls.Certificate, error)
+	// Roots returns the trust bundle used to verify the certificate of the service.
+	Roots() (*x509.CertPool, error)
+}
+
+// SPIFFE sets up mutual TLS using the X.509 SVIDs returned by source so that the requests are
+// authenticated by services using the SPIFFESecurity scheme. The certificate of the service is
+// verified against the trust bundle returned by source and must hold the SPIFFE ID serverID, any
+// SPIFFE ID is accepted if serverID is empty.
+func SPIFFE(source X509Source, serverID string) TransportOption {
+	return func(c *transportConfig) {
+		c.transport.TLSClientConfig = &tls.Config{
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return source.Certificate()
+			},
+			// The SVIDs do not hold DNS names, the chain and the SPIFFE ID of the service are
+			// verified by VerifyPeerCertificate instead.
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+				return verifySVID(source, serverID, raw)
+			},
+		}
+	}
+}
+
+// verifySVID verifies the certificate chain presented by the service and checks that its leaf
+// holds the expected SPIFFE ID.
+func verifySVID(source X509Source, serverID string, raw [][]byte) error {
+	if len(raw) == 0 {
+		return errors.New("service presented no certificate")
+	}
+	certs := make([]*x509.Certificate, len(raw))
+	for i, r := range raw {
+		cert, err := x509.ParseCertificate(r)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+	roots, err := source.Roots()
+	if err != nil {
+		return err
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return err
+	}
+	var id *url.URL
+	for _, u := range certs[0].URIs {
+		if u.Scheme == "spiffe" {
+			id = u
+			break
+		}
+	}
+	if id == nil {
+		return errors.New("service certificate does not hold a SPIFFE ID")
+	}
+	if serverID != "" && id.String() != serverID {
+		return fmt.Errorf("unexpected service SPIFFE ID %s, expected %s", id, serverID)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// StreamOptions configures the reconnection of the streams run by Stream.
+type StreamOptions struct {
+	// Interval is the delay before the first reconnection attempt, it defaults to one second.
+	Interval time.Duration
+	// MaxInterval caps the delay between two reconnection attempts, it defaults to 30 seconds.
+	MaxInterval time.Duration
+	// Multiplier is the factor applied to the delay after each failed attempt, it defaults to 2.
+	Multiplier float64
+	// Jitter is the fraction of the delay that is randomized so that clients disconnected at the
+	// same time do not reconnect at the same time, it defaults to 0.2.
+	Jitter float64
+	// MaxAttempts is the number of consecutive failed connection attempts after which Stream
+	// gives up and returns the last error. There is no limit if zero.
+	MaxAttempts int
+	// Resume is called with the configuration of each reconnection so that it may carry the
+	// token the server uses to resume the stream, e.g. a header set to the identifier of the
+	// last message received.
+	Resume func(cfg *websocket.Config)
+}
+
+// resumeKey is the private type used to store the resume hook in the dial context.
+type resumeKey struct{}
+
+// Stream establishes a connection with dial and runs handle with it, it reconnects with a jittered
+// exponential backoff each time the connection fails or handle returns an error. Stream returns
+// nil once handle returns nil, the context error once ctx is done or the last dial error after
+// opts.MaxAttempts consecutive failed attempts. The connection is closed when ctx is done so that
+// handle returns on shutdown. opts may be nil in which case the defaults are used.
+//
+// The context given to dial on reconnections holds opts.Resume, the generated clients apply it to
+// the connection configuration with ResumeStream.
+func Stream(ctx context.Context, opts *StreamOptions, dial func(context.Context) (*websocket.Conn, error), handle func(*websocket.Conn) error) error {
+	if opts == nil {
+		opts = &StreamOptions{}
+	}
+	interval, max, mult, jitter := time.Second, 30*time.Second, 2.0, 0.2
+	if opts.Interval > 0 {
+		interval = opts.Interval
+	}
+	if opts.MaxInterval > 0 {
+		max = opts.MaxInterval
+	}
+	if opts.Multiplier >= 1 {
+		mult = opts.Multiplier
+	}
+	if opts.Jitter > 0 && opts.Jitter <= 1 {
+		jitter = opts.Jitter
+	}
+	if interval > max {
+		interval = max
+	}
+	delay := interval
+	dialCtx := ctx
+	for failed := 0; ; {
+		ws, err := dial(dialCtx)
+		if err == nil {
+			failed = 0
+			delay = interval
+			err = run(ctx, ws, handle)
+			if err == nil {
+				return nil
+			}
+		} else {
+			failed++
+			if opts.MaxAttempts > 0 && failed >= opts.MaxAttempts {
+				return err
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		wait := delay - time.Duration(rand.Float64()*jitter*float64(delay))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if failed > 0 {
+			delay = time.Duration(float64(delay) * mult)
+			if delay > max {
+				delay = max
+			}
+		}
+		if opts.Resume != nil {
+			dialCtx = context.WithValue(ctx, resumeKey{}, opts.Resume)
+		}
+	}
+}
+
+// ResumeStream applies the resume hook of the reconnection made with ctx by Stream to the
+// configuration of the connection. It does nothing on the first connection.
+func ResumeStream(ctx context.Context, cfg *websocket.Config) {
+	if resume, ok := ctx.Value(resumeKey{}).(func(*websocket.Config)); ok {
+		resume(cfg)
+	}
+}
+
+// run runs handle with ws and closes ws once handle returns or ctx is done.
+func run(ctx context.Context, ws *websocket.Conn, handle func(*websocket.Conn) error) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+	defer ws.Close()
+	return handle(ws)
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type (
+	// Tracer receives the timings of the requests sent by a client, see TraceMiddleware.
+	Tracer interface {
+		// TraceRequest is called once the response headers are received or the request
+		// failed. ctx is the request context which holds the endpoint, see ContextEndpoint.
+		TraceRequest(ctx context.Context, req *http.Request, timings *RequestTimings, err error)
+	}
+
+	// TracerFunc is a function that implements Tracer.
+	TracerFunc func(ctx context.Context, req *http.Request, timings *RequestTimings, err error)
+
+	// RequestTimings lists the durations of the steps of a request. The steps that did not
+	// happen, e.g. the DNS lookup when the connection is reused, have a zero duration.
+	RequestTimings struct {
+		// DNS is the duration of the host name lookup.
+		DNS time.Duration
+		// Connect is the duration of the TCP connection establishment.
+		Connect time.Duration
+		// TLSHandshake is the duration of the TLS handshake.
+		TLSHandshake time.Duration
+		// TimeToFirstByte is the duration between the start of the request and the first
+		// byte of the response.
+		TimeToFirstByte time.Duration
+		// Total is the duration between the start of the request and the reception of the
+		// response headers.
+		Total time.Duration
+		// Reused is true if the request was sent on a connection kept alive.
+		Reused bool
+	}
+)
+
+// TraceRequest calls f.
+func (f TracerFunc) TraceRequest(ctx context.Context, req *http.Request, timings *RequestTimings, err error) {
+	f(ctx, req, timings, err)
+}
+
+// TraceMiddleware returns a client middleware that instruments the requests with
+// net/http/httptrace hooks and reports the timings to t.
+//
+//    c := client.New(nil)
+//    c.Use(goaclient.TraceMiddleware(goaclient.TracerFunc(
+//        func(ctx context.Context, req *http.Request, t *goaclient.RequestTimings, err error) {
+//            log.Printf("%s %s: dns %s, connect %s, ttfb %s", req.Method, req.URL, t.DNS, t.Connect, t.TimeToFirstByte)
+//        })))
+func TraceMiddleware(t Tracer) Middleware {
+	return func(d Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *http.Request) (*http.Response, error) {
+			rec := &traceRecorder{start: time.Now()}
+			req = req.WithContext(httptrace.WithClientTrace(req.Context(), rec.clientTrace()))
+			resp, err := d.Do(ctx, req)
+			t.TraceRequest(ctx, req, rec.timings(), err)
+			return resp, err
+		})
+	}
+}
+
+// traceRecorder records the times reported by the httptrace hooks. The hooks may be called
+// concurrently when dialing several addresses.
+type traceRecorder struct {
+	sync.Mutex
+	start, dnsStart, connectStart, tlsStart, firstByte time.Time
+	dns, connect, tls                                  time.Duration
+	reused                                             bool
+}
+
+func (r *traceRecorder) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { r.set(&r.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { r.done(&r.dns, &r.dnsStart) },
+		ConnectStart: func(string, string) {
+			r.Lock()
+			defer r.Unlock()
+			if r.connectStart.IsZero() {
+				r.connectStart = time.Now()
+			}
+		},
+		ConnectDone:          func(string, string, error) { r.done(&r.connect, &r.connectStart) },
+		TLSHandshakeStart:    func() { r.set(&r.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { r.done(&r.tls, &r.tlsStart) },
+		GotConn:              func(info httptrace.GotConnInfo) { r.Lock(); r.reused = info.Reused; r.Unlock() },
+		GotFirstResponseByte: func() { r.set(&r.firstByte) },
+	}
+}
+
+func (r *traceRecorder) set(t *time.Time) {
+	r.Lock()
+	*t = time.Now()
+	r.Unlock()
+}
+
+func (r *traceRecorder) done(d *time.Duration, start *time.Time) {
+	r.Lock()
+	defer r.Unlock()
+	if *d == 0 && !start.IsZero() {
+		*d = time.Since(*start)
+	}
+}
+
+func (r *traceRecorder) timings() *RequestTimings {
+	r.Lock()
+	defer r.Unlock()
+	t := &RequestTimings{
+		DNS:          r.dns,
+		Connect:      r.connect,
+		TLSHandshake: r.tls,
+		Total:        time.Since(r.start),
+		Reused:       r.reused,
+	}
+	if !r.firstByte.IsZero() {
+		t.TimeToFirstByte = r.firstByte.Sub(r.start)
+	}
+	return t
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type (
+	// TransportOption configures the HTTP transport and client built by NewDoer.
+	TransportOption func(*transportConfig)
+
+	// DialFunc is the signature of the functions used to open network connections.
+	DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// transportConfig holds the settings applied by the transport options.
+	transportConfig struct {
+		transport *http.Transport
+		dialer    *net.Dialer
+		dial      DialFunc
+		timeout   time.Duration
+	}
+)
+
+// NewDoer returns a Doer that sends requests using a HTTP transport configured with the given
+// options. The transport defaults mirror http.DefaultTransport.
+//
+//    c := client.New(goaclient.NewDoer(
+//        goaclient.MaxIdleConnsPerHost(10),
+//        goaclient.RequestTimeout(5 * time.Second),
+//    ))
+func NewDoer(opts ...TransportOption) Doer {
+	conf := &transportConfig{
+		transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		dialer: &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	if conf.dial != nil {
+		conf.transport.DialContext = conf.dial
+	} else {
+		conf.transport.DialContext = conf.dialer.DialContext
+	}
+	return HTTPClientDoer(&http.Client{Transport: conf.transport, Timeout: conf.timeout})
+}
+
+// MaxIdleConns sets the maximum number of idle connections kept across all hosts, zero means no
+// limit.
+func MaxIdleConns(n int) TransportOption {
+	return func(c *transportConfig) { c.transport.MaxIdleConns = n }
+}
+
+// MaxIdleConnsPerHost sets the maximum number of idle connections kept per host.
+func MaxIdleConnsPerHost(n int) TransportOption {
+	return func(c *transportConfig) { c.transport.MaxIdleConnsPerHost = n }
+}
+
+// MaxConnsPerHost limits the total number of connections per host including the connections in
+// use, zero means no limit.
+func MaxConnsPerHost(n int) TransportOption {
+	return func(c *transportConfig) { c.transport.MaxConnsPerHost = n }
+}
+
+// IdleConnTimeout sets the time an idle connection is kept in the pool before being closed.
+func IdleConnTimeout(d time.Duration) TransportOption {
+	return func(c *transportConfig) { c.transport.IdleConnTimeout = d }
+}
+
+// KeepAlive sets the TCP keep-alive period of the connections, a negative value disables
+// keep-alives. Use DisableKeepAlives to prevent connection reuse altogether.
+func KeepAlive(d time.Duration) TransportOption {
+	return func(c *transportConfig) { c.dialer.KeepAlive = d }
+}
+
+// DisableKeepAlives closes the connections after each request.
+func DisableKeepAlives() TransportOption {
+	return func(c *transportConfig) { c.transport.DisableKeepAlives = true }
+}
+
+// DialTimeout sets the maximum time spent establishing a connection.
+func DialTimeout(d time.Duration) TransportOption {
+	return func(c *transportConfig) { c.dialer.Timeout = d }
+}
+
+// RequestTimeout sets the time limit of each request attempt, it includes connecting, following
+// redirects and reading the response body. Zero means no timeout.
+func RequestTimeout(d time.Duration) TransportOption {
+	return func(c *transportConfig) { c.timeout = d }
+}
+
+// Proxy sets the function that returns the proxy used for a given request, nil disables proxies.
+// The default uses the proxy configured in the environment.
+func Proxy(proxy func(*http.Request) (*url.URL, error)) TransportOption {
+	return func(c *transportConfig) { c.transport.Proxy = proxy }
+}
+
+// ProxyURL sends all requests through the proxy with the given URL.
+func ProxyURL(u *url.URL) TransportOption {
+	return Proxy(http.ProxyURL(u))
+}
+
+// Dialer sets the function used to open connections, it overrides KeepAlive and DialTimeout.
+func Dialer(dial DialFunc) TransportOption {
+	return func(c *transportConfig) { c.dial = dial }
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// UploadOptions configures the chunks and the retries of Upload.
+type UploadOptions struct {
+	// ChunkSize is the maximum number of bytes sent in a single request, it defaults to 4 MiB.
+	ChunkSize int64
+	// Interval is the delay before the first retry, it defaults to one second.
+	Interval time.Duration
+	// MaxInterval caps the delay between two retries, it defaults to 30 seconds.
+	MaxInterval time.Duration
+	// Multiplier is the factor applied to the delay after each failed request, it defaults
+	// to 2.
+	Multiplier float64
+	// MaxAttempts is the number of consecutive failed requests after which Upload gives up and
+	// returns the last error, it defaults to 5.
+	MaxAttempts int
+}
+
+// Upload sends the size bytes of content read from r to the upload offset endpoints located at u
+// following the tus resumable upload protocol. Upload first retrieves the number of bytes already
+// received by the server so that it resumes previous transfers, then sends the remaining content
+// in chunks. It retries with an exponential backoff configured by opts when a request fails
+// because of a network error, a 409 or a 5xx response, retrieving the offset from the server
+// again before sending the next chunk. opts may be nil in which case the defaults are used.
+// Upload returns nil once the server has received all the content, the context error if ctx is
+// done first and the last error after opts.MaxAttempts consecutive failed requests or if the
+// server responds with another error.
+func Upload(ctx context.Context, doer Doer, u string, r io.ReaderAt, size int64, opts *UploadOptions) error {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+	chunk, interval, max, mult, attempts := int64(4<<20), time.Second, 30*time.Second, 2.0, 5
+	if opts.ChunkSize > 0 {
+		chunk = opts.ChunkSize
+	}
+	if opts.Interval > 0 {
+		interval = opts.Interval
+	}
+	if opts.MaxInterval > 0 {
+		max = opts.MaxInterval
+	}
+	if opts.Multiplier >= 1 {
+		mult = opts.Multiplier
+	}
+	if opts.MaxAttempts > 0 {
+		attempts = opts.MaxAttempts
+	}
+	if interval > max {
+		interval = max
+	}
+	var (
+		offset int64
+		synced bool
+		delay  = interval
+	)
+	for failed := 0; ; {
+		var err error
+		if !synced {
+			offset, err = uploadOffset(ctx, doer, u)
+			synced = err == nil
+		}
+		if err == nil {
+			if offset >= size {
+				return nil
+			}
+			n := chunk
+			if size-offset < n {
+				n = size - offset
+			}
+			var next int64
+			if next, err = uploadChunk(ctx, doer, u, io.NewSectionReader(r, offset, n), offset, n); err == nil {
+				offset, failed, delay = next, 0, interval
+				continue
+			}
+			synced = false
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !retryUpload(err) {
+			return err
+		}
+		failed++
+		if failed >= attempts {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = time.Duration(float64(delay) * mult)
+		if delay > max {
+			delay = max
+		}
+	}
+}
+
+// uploadOffset makes a HEAD request to the upload offset endpoint located at u and returns the
+// number of bytes received by the server.
+func uploadOffset(ctx context.Context, doer Doer, u string) (int64, error) {
+	req, err := http.NewRequest("HEAD", u, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", goa.TusResumable)
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	if err := DecodeError(resp, nil); err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return parseUploadOffset(resp)
+}
+
+// uploadChunk makes a PATCH request that sends the n bytes read from body at the given offset to
+// the upload offset endpoint located at u and returns the new offset.
+func uploadChunk(ctx context.Context, doer Doer, u string, body io.Reader, offset, n int64) (int64, error) {
+	req, err := http.NewRequest("PATCH", u, body)
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = n
+	req.Header.Set("Content-Type", goa.UploadContentType)
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("Tus-Resumable", goa.TusResumable)
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	if err := DecodeError(resp, nil); err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return parseUploadOffset(resp)
+}
+
+// parseUploadOffset returns the value of the Upload-Offset header of resp.
+func parseUploadOffset(resp *http.Response) (int64, error) {
+	v := resp.Header.Get("Upload-Offset")
+	offset, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Upload-Offset header %q", v)
+	}
+	return offset, nil
+}
+
+// retryUpload returns true if the upload request that failed with err may be retried after
+// retrieving the offset from the server again.
+func retryUpload(err error) bool {
+	if rerr, ok := err.(*ResponseError); ok {
+		return rerr.Status == http.StatusConflict || rerr.Status >= 500
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package goa
+
+import "reflect"
+
+// visit identifies a pointer already walked by FillNilCollections.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// FillNilCollections replaces the nil slices and maps reachable from v with empty ones so that they
+// encode as [] and {} rather than null. The values are modified in place: v must be a pointer for
+// a nil top-level slice or map to be replaced. The byte slices are left untouched as they encode
+// as strings. The generated code uses FillNilCollections when the design sets NilCollections to
+// "empty".
+func FillNilCollections(v interface{}) {
+	fillNilCollections(reflect.ValueOf(v), make(map[visit]bool))
+}
+
+// fillNilCollections replaces the nil slices and maps reachable from v, the seen pointers are not
+// walked again so that cyclic values terminate.
+func fillNilCollections(v reflect.Value, seen map[visit]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		key := visit{v.Pointer(), v.Type()}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		fillNilCollections(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		fillNilCollections(e, seen)
+		if v.CanSet() {
+			v.Set(e)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue // unexported
+			}
+			fillNilCollections(v.Field(i), seen)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillNilCollections(v.Index(i), seen)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		if v.IsNil() {
+			if v.CanSet() {
+				v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			fillNilCollections(v.Index(i), seen)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			if v.CanSet() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			return
+		}
+		for _, k := range v.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(k))
+			fillNilCollections(e, seen)
+			v.SetMapIndex(k, e)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FillNilCollections", func() {
+	type bottle struct {
+		Name     string            `json:"name"`
+		Labels   map[string]string `json:"labels"`
+		Tags     []string          `json:"tags"`
+		Data     []byte            `json:"data"`
+		Vintages map[string][]int  `json:"vintages"`
+		Related  []*bottle         `json:"related"`
+		Extra    interface{}       `json:"extra"`
+		Next     *bottle           `json:"next"`
+	}
+
+	encode := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("replaces the nil slices and maps with empty ones", func() {
+		b := &bottle{
+			Name:     "merlot",
+			Vintages: map[string][]int{"a": nil},
+			Related:  []*bottle{{Name: "syrah"}},
+		}
+		goa.FillNilCollections(b)
+		Ω(encode(b)).Should(Equal(`{"name":"merlot","labels":{},"tags":[],"data":null,"vintages":{"a":[]},"related":[{"name":"syrah","labels":{},"tags":[],"data":null,"vintages":{},"related":[],"extra":null,"next":null}],"extra":null,"next":null}`))
+	})
+
+	It("replaces nil top-level collections given a pointer", func() {
+		var bottles []*bottle
+		goa.FillNilCollections(&bottles)
+		Ω(encode(bottles)).Should(Equal("[]"))
+	})
+
+	It("walks the values held in interfaces", func() {
+		b := &bottle{Extra: map[string]interface{}{"tags": []string(nil)}}
+		goa.FillNilCollections(b)
+		Ω(encode(b.Extra)).Should(Equal(`{"tags":[]}`))
+	})
+
+	It("terminates with cyclic values", func() {
+		b := &bottle{Name: "merlot"}
+		b.Next = b
+		goa.FillNilCollections(b)
+		Ω(b.Tags).ShouldNot(BeNil())
+	})
+})
@@ -1,6 +1,9 @@
 package goa
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -19,6 +22,11 @@ const (
 	logContextKey
 	errKey
 	securityScopesKey
+	reqInfoKey
+	routeKey
+	errorEncoderKey
+	tenantKey
+	proxyErrKey
 )
 
 type (
@@ -31,6 +39,9 @@ type (
 		// Params contains the raw values for the parameters defined in the design including
 		// path parameters, query string parameters and header parameters.
 		Params url.Values
+
+		// loadBody decodes the request body deferred by LazyMuxHandler.
+		loadBody func() error
 	}
 
 	// ResponseData provides access to the underlying HTTP response.
@@ -45,6 +56,23 @@ type (
 		Status int
 		// Length is the response body length.
 		Length int
+
+		// hijacked is true if the connection was taken over by the handler.
+		hijacked bool
+	}
+
+	// RequestInfo describes the transport level details of the request being handled. It is
+	// available to the action implementations via ContextRequestInfo.
+	RequestInfo struct {
+		// RemoteAddr is the network address that sent the request, see http.Request.
+		RemoteAddr string
+		// UserAgent is the value of the request User-Agent header.
+		UserAgent string
+		// Route is the method and path pattern of the route that matched the request, e.g.
+		// "GET /bottles/:id". It is empty if the request was not routed by the service mux.
+		Route string
+		// Header gives access to the raw request headers.
+		Header http.Header
 	}
 
 	// key is the type used to store internal values in the context.
@@ -62,6 +90,13 @@ func NewContext(ctx context.Context, rw http.ResponseWriter, req *http.Request,
 	response := &ResponseData{ResponseWriter: rw}
 	ctx = context.WithValue(ctx, respKey, response)
 	ctx = context.WithValue(ctx, reqKey, request)
+	if req != nil {
+		info := &RequestInfo{RemoteAddr: req.RemoteAddr, UserAgent: req.UserAgent(), Header: req.Header}
+		if r, ok := req.Context().Value(routeKey).(string); ok {
+			info.Route = r
+		}
+		ctx = context.WithValue(ctx, reqInfoKey, info)
+	}
 
 	return ctx
 }
@@ -92,6 +127,11 @@ func WithError(ctx context.Context, err error) context.Context {
 	return context.WithValue(ctx, errKey, err)
 }
 
+// WithTenant creates a context with the given tenant identifier.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
 // ContextController extracts the controller name from the given context.
 func ContextController(ctx context.Context) string {
 	if c := ctx.Value(ctrlKey); c != nil {
@@ -116,6 +156,14 @@ func ContextRequest(ctx context.Context) *RequestData {
 	return nil
 }
 
+// ContextRequestInfo extracts the transport details of the request from the given context.
+func ContextRequestInfo(ctx context.Context) *RequestInfo {
+	if r := ctx.Value(reqInfoKey); r != nil {
+		return r.(*RequestInfo)
+	}
+	return nil
+}
+
 // ContextResponse extracts the response data from the given context.
 func ContextResponse(ctx context.Context) *ResponseData {
 	if r := ctx.Value(respKey); r != nil {
@@ -140,6 +188,25 @@ func ContextError(ctx context.Context) error {
 	return nil
 }
 
+// ContextTenant extracts the tenant identifier from the given context, the empty string if there
+// is none. The contexts of the actions of the resources that define a Tenant in their design hold
+// the identifier read from the request.
+func ContextTenant(ctx context.Context) string {
+	if t := ctx.Value(tenantKey); t != nil {
+		return t.(string)
+	}
+	return ""
+}
+
+// ContextErrorEncoder extracts the error encoder of the controller action from the given context,
+// nil if the controller does not override the encoding of the action errors.
+func ContextErrorEncoder(ctx context.Context) ErrorEncoder {
+	if enc := ctx.Value(errorEncoderKey); enc != nil {
+		return enc.(ErrorEncoder)
+	}
+	return nil
+}
+
 // SwitchWriter overrides the underlying response writer. It returns the response
 // writer that was previously set.
 func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter {
@@ -148,6 +215,32 @@ func (r *ResponseData) SwitchWriter(rw http.ResponseWriter) http.ResponseWriter
 	return rwo
 }
 
+// Flush sends the buffered data to the client if the underlying writer implements
+// http.Flusher, it does nothing otherwise.
+func (r *ResponseData) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection if the underlying writer implements
+// http.Hijacker. The response is considered written once hijacked.
+func (r *ResponseData) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	conn, rw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	r.hijacked = true
+	if r.Status == 0 {
+		r.Status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, nil
+}
+
 // Written returns true if the response was written, false otherwise.
 func (r *ResponseData) Written() bool {
 	return r.Status != 0
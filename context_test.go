@@ -2,6 +2,7 @@ package goa_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 
 	"context"
@@ -55,4 +56,52 @@ var _ = Describe("ResponseData", func() {
 			Ω(data.Status).Should(Equal(status))
 		})
 	})
+
+	Context("Hijack", func() {
+		It("returns an error if the writer does not support hijacking", func() {
+			_, _, err := data.Hijack()
+			Ω(err).Should(HaveOccurred())
+			Ω(data.Written()).Should(BeFalse())
+		})
+
+		It("hijacks the connection of the underlying writer", func() {
+			var hijacked bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := goa.NewContext(context.Background(), w, r, nil)
+				resp := goa.ContextResponse(ctx)
+				conn, _, err := resp.Hijack()
+				if err == nil {
+					hijacked = resp.Written()
+					conn.Close()
+				}
+			}))
+			defer server.Close()
+			http.Get(server.URL)
+			Ω(hijacked).Should(BeTrue())
+		})
+	})
+
+	Context("RequestInfo", func() {
+		It("describes the request", func() {
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header = http.Header{"User-Agent": []string{"test/1.0"}, "X-Foo": []string{"bar"}}
+			info := goa.ContextRequestInfo(goa.NewContext(context.Background(), rw, req, params))
+			Ω(info).ShouldNot(BeNil())
+			Ω(info.RemoteAddr).Should(Equal("10.0.0.1:1234"))
+			Ω(info.UserAgent).Should(Equal("test/1.0"))
+			Ω(info.Header.Get("X-Foo")).Should(Equal("bar"))
+			Ω(info.Route).Should(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("ContextTenant", func() {
+	It("returns the tenant identifier stored in the context", func() {
+		ctx := goa.WithTenant(context.Background(), "acme")
+		Ω(goa.ContextTenant(ctx)).Should(Equal("acme"))
+	})
+
+	It("returns the empty string if there is none", func() {
+		Ω(goa.ContextTenant(context.Background())).Should(BeEmpty())
+	})
 })
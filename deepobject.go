@@ -0,0 +1,260 @@
+package goa
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// DecodeDeepObject sets the fields of the struct pointed to by v from the query string values
+// whose keys use the deepObject style: the given name followed by the JSON name of the field in
+// brackets, nested structs and maps add one bracketed segment per level, e.g.
+// filter[price][min]=10. Slices are read from repeated keys, time.Time and other types that
+// implement encoding.TextUnmarshaler from their text form. DecodeDeepObject returns false if no
+// key starts with the name and an error if a value cannot be parsed into the type of its field.
+func DecodeDeepObject(values url.Values, name string, v interface{}) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return false, fmt.Errorf("goa: DecodeDeepObject requires a non nil pointer, got %T", v)
+	}
+	if !hasDeepKey(values, name) {
+		return false, nil
+	}
+	return true, decodeDeep(values, name, rv.Elem())
+}
+
+// EncodeDeepObject adds the fields of v, a struct or a pointer to a struct, to values using the
+// deepObject style read by DecodeDeepObject. The nil fields are skipped.
+func EncodeDeepObject(values url.Values, name string, v interface{}) {
+	encodeDeep(values, name, reflect.ValueOf(v))
+}
+
+// decodeDeep sets v from the values whose keys are key or start with key followed by brackets.
+func decodeDeep(values url.Values, key string, v reflect.Value) error {
+	t := v.Type()
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		if raws := values[key]; len(raws) > 0 {
+			return parseDeepValue(key, raws[0], v)
+		}
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		if !hasDeepKey(values, key) {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return decodeDeep(values, key, v.Elem())
+	case reflect.Struct:
+		var err error
+		for i := 0; i < t.NumField(); i++ {
+			n := deepFieldName(t.Field(i))
+			if n == "" {
+				continue
+			}
+			if e := decodeDeep(values, key+"["+n+"]", v.Field(i)); e != nil {
+				err = MergeErrors(err, e)
+			}
+		}
+		return err
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil
+		}
+		var err error
+		prefix := key + "["
+		seen := make(map[string]bool)
+		for k := range values {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			rest := k[len(prefix):]
+			i := strings.Index(rest, "]")
+			if i < 0 || seen[rest[:i]] {
+				continue
+			}
+			mk := rest[:i]
+			seen[mk] = true
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(t))
+			}
+			ev := reflect.New(t.Elem()).Elem()
+			if e := decodeDeep(values, prefix+mk+"]", ev); e != nil {
+				err = MergeErrors(err, e)
+				continue
+			}
+			v.SetMapIndex(reflect.ValueOf(mk).Convert(t.Key()), ev)
+		}
+		return err
+	case reflect.Slice:
+		raws, ok := values[key]
+		if !ok {
+			return nil
+		}
+		s := reflect.MakeSlice(t, len(raws), len(raws))
+		var err error
+		for i, raw := range raws {
+			if e := parseDeepValue(key, raw, s.Index(i)); e != nil {
+				err = MergeErrors(err, e)
+			}
+		}
+		if err == nil {
+			v.Set(s)
+		}
+		return err
+	}
+	if raws := values[key]; len(raws) > 0 {
+		return parseDeepValue(key, raws[0], v)
+	}
+	return nil
+}
+
+// parseDeepValue parses raw into v, a primitive value, a pointer to a primitive value or a value
+// whose pointer implements encoding.TextUnmarshaler.
+func parseDeepValue(key, raw string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(v.Type().Elem())
+		if err := parseDeepValue(key, raw, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return InvalidParamTypeError(key, raw, v.Type().Name())
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return InvalidParamTypeError(key, raw, "boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, bitSize(v))
+		if err != nil {
+			return InvalidParamTypeError(key, raw, "integer")
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, bitSize(v))
+		if err != nil {
+			return InvalidParamTypeError(key, raw, "integer")
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, bitSize(v))
+		if err != nil {
+			return InvalidParamTypeError(key, raw, "number")
+		}
+		v.SetFloat(f)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(raw))
+	default:
+		return InvalidParamTypeError(key, raw, v.Type().String())
+	}
+	return nil
+}
+
+// encodeDeep adds the value of v to values under key, the fields of structs and the entries of
+// maps are added under key followed by their name in brackets.
+func encodeDeep(values url.Values, key string, v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return
+	}
+	if v.Type().Implements(textMarshalerType) {
+		if b, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			values.Add(key, string(b))
+		}
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		encodeDeep(values, key, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if n := deepFieldName(t.Field(i)); n != "" {
+				encodeDeep(values, key+"["+n+"]", v.Field(i))
+			}
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, k := range keys {
+			encodeDeep(values, key+"["+k.String()+"]", v.MapIndex(k))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			encodeDeep(values, key, v.Index(i))
+		}
+	case reflect.String:
+		values.Add(key, v.String())
+	case reflect.Bool:
+		values.Add(key, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(key, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(key, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(key, strconv.FormatFloat(v.Float(), 'f', -1, bitSize(v)))
+	}
+}
+
+// hasDeepKey returns true if values has the given key or a key that starts with it followed by
+// a bracket.
+func hasDeepKey(values url.Values, key string) bool {
+	if _, ok := values[key]; ok {
+		return true
+	}
+	prefix := key + "["
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// deepFieldName returns the name of the struct field in the query string keys: its JSON name.
+// It returns an empty string for unexported fields and fields excluded from the JSON encoding.
+func deepFieldName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if n := strings.Split(tag, ",")[0]; n != "" {
+		return n
+	}
+	return f.Name
+}
+
+// bitSize returns the bit size of the numeric value v.
+func bitSize(v reflect.Value) int {
+	return v.Type().Bits()
+}
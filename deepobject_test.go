@@ -0,0 +1,95 @@
+package goa_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("deep objects", func() {
+	type price struct {
+		Min *float64 `json:"min,omitempty"`
+		Max *float64 `json:"max,omitempty"`
+	}
+	type filter struct {
+		Name    *string           `json:"name,omitempty"`
+		Vintage int               `json:"vintage"`
+		Tags    []string          `json:"tags,omitempty"`
+		Price   *price            `json:"price,omitempty"`
+		Since   *time.Time        `json:"since,omitempty"`
+		Labels  map[string]string `json:"labels,omitempty"`
+	}
+
+	Context("DecodeDeepObject", func() {
+		It("decodes the nested values", func() {
+			values := url.Values{
+				"filter[name]":          {"merlot"},
+				"filter[vintage]":       {"2015"},
+				"filter[tags]":          {"red", "dry"},
+				"filter[price][min]":    {"10.5"},
+				"filter[since]":         {"2020-01-02T03:04:05Z"},
+				"filter[labels][color]": {"red"},
+				"other":                 {"ignored"},
+			}
+			var f filter
+			ok, err := goa.DecodeDeepObject(values, "filter", &f)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ok).Should(BeTrue())
+			Ω(*f.Name).Should(Equal("merlot"))
+			Ω(f.Vintage).Should(Equal(2015))
+			Ω(f.Tags).Should(Equal([]string{"red", "dry"}))
+			Ω(f.Price).ShouldNot(BeNil())
+			Ω(*f.Price.Min).Should(Equal(10.5))
+			Ω(f.Price.Max).Should(BeNil())
+			Ω(f.Since.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).Should(BeTrue())
+			Ω(f.Labels).Should(Equal(map[string]string{"color": "red"}))
+		})
+
+		It("returns false when no value matches", func() {
+			var f filter
+			ok, err := goa.DecodeDeepObject(url.Values{"other": {"x"}}, "filter", &f)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ok).Should(BeFalse())
+		})
+
+		It("reports the invalid values", func() {
+			var f filter
+			_, err := goa.DecodeDeepObject(url.Values{"filter[vintage]": {"old"}}, "filter", &f)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("filter[vintage]"))
+		})
+	})
+
+	Context("EncodeDeepObject", func() {
+		It("encodes the values read by DecodeDeepObject", func() {
+			name, min := "merlot", 10.5
+			since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			in := &filter{
+				Name:    &name,
+				Vintage: 2015,
+				Tags:    []string{"red", "dry"},
+				Price:   &price{Min: &min},
+				Since:   &since,
+				Labels:  map[string]string{"color": "red"},
+			}
+			values := make(url.Values)
+			goa.EncodeDeepObject(values, "filter", in)
+			Ω(values).Should(Equal(url.Values{
+				"filter[name]":          {"merlot"},
+				"filter[vintage]":       {"2015"},
+				"filter[tags]":          {"red", "dry"},
+				"filter[price][min]":    {"10.5"},
+				"filter[since]":         {"2020-01-02T03:04:05Z"},
+				"filter[labels][color]": {"red"},
+			}))
+			var out filter
+			_, err := goa.DecodeDeepObject(values, "filter", &out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(out.Vintage).Should(Equal(in.Vintage))
+			Ω(*out.Price.Min).Should(Equal(min))
+		})
+	})
+})
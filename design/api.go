@@ -63,6 +63,39 @@ const (
 	HTTPVersionNotSupported = "HTTPVersionNotSupported"
 )
 
+// List of the query parameter serialization styles, see ParamSeparator.
+const (
+	// FormStyle serializes arrays as repeated key=value pairs when exploded (default), e.g.
+	// "id=1&id=2", or as comma separated values otherwise, e.g. "id=1,2".
+	FormStyle = "form"
+	// PipeDelimitedStyle serializes arrays as pipe separated values, e.g. "id=1|2".
+	PipeDelimitedStyle = "pipeDelimited"
+	// SpaceDelimitedStyle serializes arrays as space separated values, e.g. "id=1%202".
+	SpaceDelimitedStyle = "spaceDelimited"
+)
+
+// List of the encodings of the nil slices and maps, see NilCollections.
+const (
+	// NilCollectionsNull encodes the nil slices and maps as JSON null.
+	NilCollectionsNull = "null"
+	// NilCollectionsEmpty encodes the nil slices and maps as empty JSON arrays and objects.
+	NilCollectionsEmpty = "empty"
+)
+
+// List of the formats of the request IDs created by the generated service, see RequestIDFormat.
+const (
+	// RequestIDUUIDv7 creates version 7 UUIDs.
+	RequestIDUUIDv7 = "uuidv7"
+	// RequestIDULID creates ULIDs.
+	RequestIDULID = "ulid"
+	// RequestIDSnowflake creates snowflake IDs.
+	RequestIDSnowflake = "snowflake"
+)
+
+// DefaultMaxRecursionDepth is the maximum number of levels a recursive user type or media type is
+// expanded to in the generated examples when the design does not use MaxRecursionDepth.
+const DefaultMaxRecursionDepth = 2
+
 var (
 	// Design being built by DSL.
 	Design *APIDefinition
@@ -98,6 +131,7 @@ var (
 		"application/x-cbor":    "github.com/goadesign/goa/encoding/cbor",
 		"application/msgpack":   "github.com/goadesign/goa/encoding/msgpack",
 		"application/x-msgpack": "github.com/goadesign/goa/encoding/msgpack",
+		"application/x-binary":  "github.com/goadesign/goa/encoding/binary",
 	}
 
 	// KnownEncoderFunctions contains the list of encoding encoder and decoder functions known
@@ -113,6 +147,7 @@ var (
 		"application/x-cbor":    {"NewEncoder", "NewDecoder"},
 		"application/msgpack":   {"NewEncoder", "NewDecoder"},
 		"application/x-msgpack": {"NewEncoder", "NewDecoder"},
+		"application/x-binary":  {"NewEncoder", "NewDecoder"},
 	}
 
 	// JSONContentTypes list the Content-Type header values that cause goa to encode or decode
@@ -179,12 +214,88 @@ var (
 			Description: "a meta object containing non-standard meta-information about the error.",
 			Example:     map[string]interface{}{"timestamp": 1458609066},
 		},
+		"fields": &AttributeDefinition{
+			Type: &Array{ElemType: &AttributeDefinition{Type: Object{
+				"pointer": &AttributeDefinition{
+					Type:        String,
+					Description: "the RFC 6901 JSON pointer to the invalid field.",
+					Example:     "/items/0/name",
+				},
+				"detail": &AttributeDefinition{
+					Type:        String,
+					Description: "a human-readable explanation of the field error.",
+					Example:     "attribute \"name\" of raw.items[*] is missing and required",
+				},
+			}}},
+			Description: "the list of the request fields that failed to validate.",
+		},
 	}
 
 	errorMediaView = &ViewDefinition{
 		AttributeDefinition: &AttributeDefinition{Type: errorMediaType},
 		Name:                "default",
 	}
+
+	// JobMediaIdentifier is the media type identifier used for the status of the jobs started
+	// by asynchronous actions.
+	JobMediaIdentifier = "application/vnd.goa.job"
+
+	// DefaultJobsPath is the default path prefix of the job status endpoint, see JobsPath.
+	DefaultJobsPath = "/jobs"
+
+	// JobMedia is the built-in media type for the status of the jobs started by asynchronous
+	// actions, see the Async DSL.
+	JobMedia = &MediaTypeDefinition{
+		UserTypeDefinition: &UserTypeDefinition{
+			AttributeDefinition: &AttributeDefinition{
+				Type:        jobMediaType,
+				Description: "Asynchronous job status media type",
+				Validation:  &dslengine.ValidationDefinition{Required: []string{"id", "status"}},
+				Example: map[string]interface{}{
+					"id":     "7TFRXCVA",
+					"status": "pending",
+					"href":   "/jobs/7TFRXCVA",
+				},
+			},
+			TypeName: "GoaJob",
+		},
+		Identifier: JobMediaIdentifier,
+		Views:      map[string]*ViewDefinition{"default": jobMediaView},
+	}
+
+	jobMediaType = Object{
+		"id": &AttributeDefinition{
+			Type:        String,
+			Description: "the job identifier.",
+			Example:     "7TFRXCVA",
+		},
+		"status": &AttributeDefinition{
+			Type:        String,
+			Description: "the job status.",
+			Validation: &dslengine.ValidationDefinition{
+				Values: []interface{}{"pending", "running", "succeeded", "failed"},
+			},
+			Example: "pending",
+		},
+		"href": &AttributeDefinition{
+			Type:        String,
+			Description: "the path of the job status endpoint.",
+			Example:     "/jobs/7TFRXCVA",
+		},
+		"result": &AttributeDefinition{
+			Type:        Any,
+			Description: "the result of the job once it succeeded.",
+		},
+		"error": &AttributeDefinition{
+			Type:        String,
+			Description: "the reason of the failure once the job failed.",
+		},
+	}
+
+	jobMediaView = &ViewDefinition{
+		AttributeDefinition: &AttributeDefinition{Type: jobMediaType},
+		Name:                "default",
+	}
 )
 
 func init() {
@@ -200,6 +311,7 @@ func init() {
 		{MIMETypes: GobContentTypes, PackagePath: goa, Function: "NewGobDecoder"},
 	}
 	errorMediaView.Parent = ErrorMedia
+	jobMediaView.Parent = JobMedia
 }
 
 // CanonicalIdentifier returns the media type identifier sans suffix
@@ -120,3 +120,39 @@ var _ = Describe("MediaTypeRoot", func() {
 		Ω(sets[0][1]).Should(Equal(root["bar"]))
 	})
 })
+
+var _ = Describe("SelectResources", func() {
+	var api *design.APIDefinition
+
+	BeforeEach(func() {
+		api = &design.APIDefinition{
+			Name: "cellar",
+			Resources: map[string]*design.ResourceDefinition{
+				"users":   {Name: "users"},
+				"billing": {Name: "billing"},
+				"bottles": {Name: "bottles"},
+			},
+		}
+	})
+
+	It("returns the API with the selected resources only", func() {
+		selected, err := api.SelectResources([]string{"users", "billing"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(selected.Name).Should(Equal("cellar"))
+		Ω(selected.Resources).Should(HaveLen(2))
+		Ω(selected.Resources).Should(HaveKey("users"))
+		Ω(selected.Resources).Should(HaveKey("billing"))
+		Ω(api.Resources).Should(HaveLen(3))
+	})
+
+	It("returns the API itself without selection", func() {
+		selected, err := api.SelectResources(nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(selected).Should(BeIdenticalTo(api))
+	})
+
+	It("fails with an unknown resource", func() {
+		_, err := api.SelectResources([]string{"wines"})
+		Ω(err).Should(MatchError("API cellar does not define the resource wines"))
+	})
+})
@@ -2,6 +2,7 @@ package apidsl
 
 import (
 	"fmt"
+	"time"
 	"unicode"
 
 	"github.com/goadesign/goa/design"
@@ -54,6 +55,27 @@ func Files(path, filename string, dsls ...func()) {
 	}
 }
 
+// SignedURL used in: Files
+//
+// SignedURL requires the requests made to the file server to use time-limited URLs signed with
+// the key set in middleware.DefaultURLSigningKey. The generated app package defines one function
+// per signed file server that creates such URLs, for example:
+//
+//    Files("/downloads/*filepath", "/www/downloads", func() {
+//        SignedURL()
+//    })
+//
+// generates the function SignBottleDownloadsURL(key []byte, filepath string, expires time.Time)
+// for the "bottle" resource. Requests made to URLs that are not signed or that expired are
+// rejected with status 403.
+func SignedURL() {
+	if fs, ok := dslengine.CurrentDefinition().(*design.FileServerDefinition); ok {
+		fs.Signed = true
+		return
+	}
+	dslengine.IncompatibleDSL()
+}
+
 // Action used in: Resource
 //
 // Action implements the action definition DSL. Action definitions describe specific API endpoints
@@ -489,6 +511,398 @@ func MultipartForm() {
 	}
 }
 
+// Proxy can be used in: Action
+//
+// Proxy declares the action as a passthrough to an upstream service. The generated handler
+// validates the request parameters, headers and payload as usual and then forwards the request to
+// the upstream URL, streaming the response back to the client. The controller interface does not
+// include proxied actions. This makes it possible to describe legacy endpoints in the design and to
+// migrate them one at a time by removing the Proxy DSL once the action is implemented:
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        Params(func() {
+//            Param("id", Integer)
+//        })
+//        Proxy("http://legacy.example.com")
+//        Response(OK)
+//    })
+//
+// The request path is appended to the path of the upstream URL.
+func Proxy(upstream string) {
+	if a, ok := actionDefinition(); ok {
+		a.ProxyURL = upstream
+	}
+}
+
+// Async can be used in: Action
+//
+// Async declares the action as asynchronous: the action starts a job and responds right away with
+// the 202 status code. Unless the action defines the Accepted response explicitly, the response
+// body is the job status rendered with the built-in "application/vnd.goa.job" media type and its
+// Location header contains the path of the job status endpoint. The generated action context has
+// an AcceptedJob method that sends this response given the job identifier. The generated
+// application code also includes a JobStore interface and a MountJobStatus function that mounts
+// the job status endpoint under the path given by JobsPath, "/jobs" by default. The generated
+// client includes a WaitJob method that polls the endpoint until the job completes:
+//
+//    Action("export", func() {
+//        Routing(POST("/exports"))
+//        Payload(ExportPayload)
+//        Async()
+//    })
+func Async() {
+	if a, ok := actionDefinition(); ok {
+		a.Async = true
+	}
+}
+
+// Resumable can be used in: Action
+//
+// Resumable declares that the action creates uploads whose content is transferred in chunks
+// following the tus resumable upload protocol. The action request carries the total size of the
+// upload in the required Upload-Length header and has no payload. Unless the action defines the
+// Created response explicitly, the response has the 201 status code and its Location header
+// contains the path of the upload offset endpoints. The generated action context has a
+// CreatedUpload method that sends this response given the upload identifier.
+//
+// The generated application code includes a function that mounts the offset endpoints for each
+// resumable action: the HEAD endpoint reports how many bytes were received and the PATCH endpoint
+// appends a chunk. The endpoints use the path of the first action route followed by the uploadID
+// wildcard and rely on a goa.UploadStore to keep track of the offsets and to store the chunks.
+// The generated client includes a ResumeUpload method that sends the content in chunks and
+// resumes from the offset reported by the server after failures:
+//
+//    Action("create", func() {
+//        Routing(POST("/uploads"))
+//        Resumable()
+//    })
+func Resumable() {
+	if a, ok := actionDefinition(); ok {
+		a.Resumable = true
+	}
+}
+
+// Batch can be used in: Action
+//
+// Batch declares a batch endpoint for the action that handles the POST requests sent to the given
+// path. The path is relative to the resource base path like the action routes. The request body
+// of the endpoint is a JSON array of action payloads and the generated code invokes the action
+// handler once per payload. The response has the 207 status code and its body lists the status
+// code and body of the response to each payload in order so that some payloads may fail while
+// the others succeed. The params of the action must all be wildcards of the batch path, their
+// values apply to all the payloads. The generated client includes a method that sends the batch
+// requests.
+//
+//    Action("create", func() {
+//        Routing(POST("/:accountID/bottles"))
+//        Params(func() {
+//            Param("accountID", Integer)
+//        })
+//        Payload(BottlePayload)
+//        Batch("/:accountID/bottles/batch")
+//        Response(Created)
+//    })
+func Batch(path string) {
+	if a, ok := actionDefinition(); ok {
+		a.BatchRoute = &design.RouteDefinition{Verb: "POST", Path: path, Parent: a}
+	}
+}
+
+// Cache can be used in: Action
+//
+// Cache marks the action as cacheable. The generated code wraps the action handler with the
+// response caching middleware of the goa middleware package: successful responses are stored for
+// the duration given by ttl and subsequent requests with the same cache key are served from the
+// store without running the action. The cache key is made of the request method, path and Accept
+// header and of the values of the params and payload attributes whose names are given as
+// arguments. The request query string is used in place of the params when no names are given.
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        Params(func() {
+//            Param("id", Integer)
+//            Param("view", String)
+//        })
+//        Cache(5*time.Minute, "id", "view")
+//        Response(OK)
+//    })
+//
+// The cache key does not include the request credentials so actions whose responses depend on the
+// authenticated user should not be cached unless one of the keys identifies the user.
+func Cache(ttl time.Duration, keys ...string) {
+	if a, ok := actionDefinition(); ok {
+		if ttl <= 0 {
+			dslengine.ReportError("invalid cache TTL %s, must be positive", ttl)
+			return
+		}
+		a.CacheTTL = ttl
+		a.CacheKeys = keys
+	}
+}
+
+// Coalesce can be used in: Action
+//
+// Coalesce marks the action requests as coalescable. The generated code wraps the action handler
+// with the request coalescing middleware of the goa middleware package: while a request is being
+// handled the concurrent requests with the same method, path, query string and Accept header wait
+// for it to complete and receive a copy of its response instead of running the action. This
+// protects the backends of expensive read actions from bursts of identical requests, e.g. when a
+// cache entry expires. Coalesce is typically combined with Cache:
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        Coalesce()
+//        Cache(time.Minute)
+//        Response(OK)
+//    })
+//
+// Only actions whose routes all use the GET method can be coalesced. As with Cache the requests
+// credentials are not part of the key so actions whose responses depend on the authenticated user
+// should not be coalesced.
+func Coalesce() {
+	if a, ok := actionDefinition(); ok {
+		a.Coalesced = true
+	}
+}
+
+// Event can be used in: Action
+//
+// Event marks the action as an event that is published to and consumed from a message broker
+// topic in addition to being exposed over HTTP. The generated client includes a function that
+// publishes the action payload to the topic and the generated application a function that
+// subscribes to the topics of all the events and dispatches the messages to the action handlers.
+// The broker is abstracted by the goa.Publisher and goa.Subscriber interfaces. The topic name
+// defaults to the resource and action names separated with a dot, e.g. "bottle.created".
+//
+//    Action("created", func() {
+//        Routing(POST("/created"))
+//        Payload(BottlePayload)
+//        Event("cellar.bottles")
+//        Response(Accepted)
+//    })
+//
+// Events do not return a response to the publisher, the action response status is only used to
+// acknowledge the message: the consumer returns an error for statuses of 400 and above.
+func Event(topic ...string) {
+	if a, ok := actionDefinition(); ok {
+		if len(topic) > 1 {
+			dslengine.ReportError("too many arguments given to Event")
+			return
+		}
+		if len(topic) == 1 {
+			if topic[0] == "" {
+				dslengine.ReportError("event topic cannot be empty")
+				return
+			}
+			a.Topic = topic[0]
+			return
+		}
+		a.Topic = a.Parent.Name + "." + a.Name
+	}
+}
+
+// ViewParam can be used in: Action
+//
+// ViewParam lets clients select the view used to render the action response with the query string
+// parameter of the given name. The parameter is defined as a string if the action does not define
+// it, its default value is the "default" view and its value is validated against the names of the
+// views of the response media types. The generated action context RequestedView method returns
+// the name of the requested view:
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        ViewParam("view") // GET /bottles/1?view=tiny
+//        Response(OK, BottleMedia)
+//    })
+//
+// Only the responses that do not define a view explicitly are rendered with the requested view.
+func ViewParam(name string) {
+	if a, ok := actionDefinition(); ok {
+		if name == "" {
+			dslengine.ReportError("view param name cannot be empty")
+			return
+		}
+		a.ViewParam = name
+	}
+}
+
+// ViewHeader can be used in: Action
+//
+// ViewHeader works like ViewParam but selects the view used to render the action response with
+// the request header of the given name:
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        ViewHeader("X-View")
+//        Response(OK, BottleMedia)
+//    })
+func ViewHeader(name string) {
+	if a, ok := actionDefinition(); ok {
+		if name == "" {
+			dslengine.ReportError("view header name cannot be empty")
+			return
+		}
+		a.ViewHeader = name
+	}
+}
+
+// FieldMask can be used in: Action
+//
+// FieldMask lets clients request a subset of the fields of the action response media type with
+// the query string parameter of the given name, "fields" by default, JSON:API sparse fieldset
+// style. The parameter lists the names of the fields separated with commas and its values are
+// validated against the names of the top level attributes of the response media types. The
+// generated response helpers only render the requested fields when the response is encoded in
+// JSON, all the fields are rendered if the parameter is not set:
+//
+//    Action("list", func() {
+//        Routing(GET(""))
+//        FieldMask() // GET /bottles?fields=id,name
+//        Response(OK, CollectionOf(BottleMedia))
+//    })
+//
+// The elements of collections are pruned individually. Error responses are never pruned.
+func FieldMask(name ...string) {
+	if a, ok := actionDefinition(); ok {
+		if len(name) > 1 {
+			dslengine.ReportError("too many arguments given to FieldMask")
+			return
+		}
+		a.FieldMask = "fields"
+		if len(name) == 1 {
+			if name[0] == "" {
+				dslengine.ReportError("field mask param name cannot be empty")
+				return
+			}
+			a.FieldMask = name[0]
+		}
+	}
+}
+
+// OperationID can be used in: Action, Route
+//
+// OperationID sets the identifier of the action in the generated API documents, e.g. the OpenAPI
+// operationId many client generators derive method names from. It defaults to
+// "resource#action". When used in an action with multiple routes the routes after the first get
+// the identifier suffixed with their position (e.g. "listBottles2") unless they set their own:
+//
+//    Action("list", func() {
+//        OperationID("listBottles")
+//        Routing(
+//            GET(""),
+//            GET("/all", func() {
+//                OperationID("listAllBottles")
+//            }),
+//        )
+//        Response(OK)
+//    })
+//
+// Operation IDs must be unique across the API.
+func OperationID(id string) {
+	if id == "" {
+		dslengine.ReportError("operation ID cannot be empty")
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.OperationID = id
+	case *design.RouteDefinition:
+		def.OperationID = id
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Summary can be used in: Action, Route
+//
+// Summary sets the short summary of the action in the generated API documents. It takes
+// precedence over the "swagger:summary" metadata and defaults to "action resource". A summary
+// set in a route applies to that route only:
+//
+//    Action("show", func() {
+//        Summary("Retrieve a bottle")
+//        Routing(GET("/:id"))
+//        Response(OK)
+//    })
+func Summary(summary string) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.Summary = summary
+	case *design.RouteDefinition:
+		def.Summary = summary
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// ResponseDescription can be used in: Route
+//
+// ResponseDescription overrides the description of the action response with the given name in the
+// API documents generated for the route. The response keeps its own description (which defaults
+// to the HTTP status text) for the other routes of the action:
+//
+//    Action("show", func() {
+//        Routing(
+//            GET("/:id"),
+//            GET("/latest", func() {
+//                ResponseDescription("OK", "The latest bottle")
+//            }),
+//        )
+//        Response(OK, BottleMedia)
+//    })
+func ResponseDescription(name, description string) {
+	if r, ok := routeDefinition(); ok {
+		if r.ResponseDescriptions == nil {
+			r.ResponseDescriptions = make(map[string]string)
+		}
+		r.ResponseDescriptions[name] = description
+	}
+}
+
+// HTTPExample can be used in: Action, Route
+//
+// HTTPExample defines a pair of request and response examples for the given content type. request
+// is the example request body and body the example body of the action response with the given
+// name, either may be nil. Examples set in a route override the action examples with the same
+// content type and response for that route. The generated API documents list the response bodies
+// in the response examples and the request bodies in the "x-examples" extension of the payload,
+// the values of the sensitive attributes of the payload and response media type are masked:
+//
+//    Action("create", func() {
+//        Routing(POST(""))
+//        Payload(BottlePayload)
+//        HTTPExample("application/json", map[string]interface{}{"name": "Number 8"},
+//            "Created", map[string]interface{}{"id": 1, "name": "Number 8"})
+//        HTTPExample("application/xml", "<bottle><name>Number 8</name></bottle>",
+//            "Created", "<bottle><id>1</id><name>Number 8</name></bottle>")
+//        Response(Created, BottleMedia)
+//    })
+func HTTPExample(contentType string, request interface{}, response string, body interface{}) {
+	if contentType == "" {
+		dslengine.ReportError("example content type cannot be empty")
+		return
+	}
+	if response == "" {
+		dslengine.ReportError("example response cannot be empty")
+		return
+	}
+	ex := &design.HTTPExampleDefinition{
+		ContentType: contentType,
+		Request:     request,
+		Response:    response,
+		Body:        body,
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		def.HTTPExamples = append(def.HTTPExamples, ex)
+	case *design.RouteDefinition:
+		def.HTTPExamples = append(def.HTTPExamples, ex)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
 // newAttribute creates a new attribute definition using the media type with the given identifier
 // as base type.
 func newAttribute(baseMT string) *design.AttributeDefinition {
@@ -2,6 +2,7 @@ package apidsl_test
 
 import (
 	"strconv"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -112,6 +113,487 @@ var _ = Describe("Action", func() {
 		})
 	})
 
+	Context("with styled array params", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET(""))
+				Params(func() {
+					Param("ids", ArrayOf(Integer), func() {
+						Style(FormStyle, false)
+					})
+					Param("tags", ArrayOf(String), func() {
+						Style(PipeDelimitedStyle)
+					})
+					Param("names", ArrayOf(String), func() {
+						Style(SpaceDelimitedStyle)
+					})
+					Param("all", ArrayOf(String))
+				})
+			}
+		})
+
+		It("sets the params separators", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			params := action.Params.Type.ToObject()
+			Ω(params["ids"].ParamSeparator()).Should(Equal(","))
+			Ω(params["tags"].ParamSeparator()).Should(Equal("|"))
+			Ω(params["names"].ParamSeparator()).Should(Equal(" "))
+			Ω(params["all"].ParamSeparator()).Should(BeEmpty())
+		})
+
+		Context("using a style on a non array param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET(""))
+					Params(func() {
+						Param("id", Integer, func() {
+							Style(PipeDelimitedStyle)
+						})
+					})
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a catch-all route", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/files/*path"))
+			}
+		})
+
+		It("produces a valid action with the catch-all param", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.Routes[0].CatchAll()).Should(Equal("path"))
+		})
+
+		Context("in the middle of the path", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/files/*path/meta"))
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+				Ω(action.Routes[0].CatchAll()).Should(BeEmpty())
+			})
+		})
+
+		Context("using a non string param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/files/*path"))
+					Params(func() {
+						Param("path", Integer)
+					})
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a proxy", func() {
+		const upstream = "http://legacy.example.com"
+
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Routing(GET("/:id"))
+				Proxy(upstream)
+			}
+		})
+
+		It("produces a valid action with the proxy URL set", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action).ShouldNot(BeNil())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.ProxyURL).Should(Equal(upstream))
+		})
+
+		Context("using a relative URL", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/:id"))
+					Proxy("/legacy")
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a cache", func() {
+		var keys []string
+
+		BeforeEach(func() {
+			name = "foo"
+			keys = []string{"id"}
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Cache(time.Minute, keys...)
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("produces a cacheable action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.CacheTTL).Should(Equal(time.Minute))
+			Ω(action.CacheKeys).Should(Equal([]string{"id"}))
+		})
+
+		Context("using a key that is not a param", func() {
+			BeforeEach(func() {
+				keys = []string{"unknown"}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a coalesced action", func() {
+		var verb func(string, ...func()) *RouteDefinition
+
+		BeforeEach(func() {
+			name = "foo"
+			verb = GET
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(verb("/:id"))
+					Coalesce()
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("produces a coalesced action", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Coalesced).Should(BeTrue())
+		})
+
+		Context("using a route that is not a GET", func() {
+			BeforeEach(func() {
+				verb = POST
+			})
+
+			It("produces an invalid action", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("only GET actions can be coalesced"))
+			})
+		})
+	})
+
+	Context("with an event", func() {
+		var topic []string
+		var params func()
+
+		BeforeEach(func() {
+			name = "created"
+			topic = nil
+			params = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(POST("/created"))
+					if params != nil {
+						Params(params)
+					}
+					Payload(func() {
+						Attribute("name", String)
+					})
+					Event(topic...)
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("uses the resource and action names as topic", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.Validate()).ShouldNot(HaveOccurred())
+			Ω(action.Topic).Should(Equal("res.created"))
+		})
+
+		Context("with a topic", func() {
+			BeforeEach(func() {
+				topic = []string{"cellar.bottles"}
+			})
+
+			It("sets the topic", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(action.Topic).Should(Equal("cellar.bottles"))
+			})
+		})
+
+		Context("with an empty topic", func() {
+			BeforeEach(func() {
+				topic = []string{""}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with params", func() {
+			BeforeEach(func() {
+				params = func() {
+					Param("id", Integer)
+				}
+			})
+
+			It("produces an invalid action", func() {
+				Ω(action.Validate()).Should(HaveOccurred())
+			})
+		})
+	})
+
+	Context("with a field mask", func() {
+		var args []string
+
+		BeforeEach(func() {
+			name = "list"
+			args = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET(""))
+					FieldMask(args...)
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("uses the fields param", func() {
+			Ω(action.FieldMask).Should(Equal("fields"))
+		})
+
+		Context("with a param name", func() {
+			BeforeEach(func() {
+				args = []string{"only"}
+			})
+
+			It("sets the param name", func() {
+				Ω(action.FieldMask).Should(Equal("only"))
+			})
+		})
+
+		Context("with too many arguments", func() {
+			BeforeEach(func() {
+				args = []string{"a", "b"}
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("too many arguments given to FieldMask"))
+			})
+		})
+	})
+
+	Context("with operation IDs and summaries", func() {
+		var actionID, routeID string
+
+		BeforeEach(func() {
+			name = "list"
+			actionID = "list"
+			routeID = "listAll"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					OperationID(actionID)
+					Summary("List things")
+					Routing(
+						GET(""),
+						GET("/all", func() {
+							OperationID(routeID)
+							Summary("List all things")
+							ResponseDescription("OK", "All the things")
+						}),
+					)
+					Response(OK)
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("sets the action and route fields", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.OperationID).Should(Equal("list"))
+			Ω(action.Summary).Should(Equal("List things"))
+			Ω(action.Routes).Should(HaveLen(2))
+			Ω(action.Routes[0].OperationID).Should(BeEmpty())
+			Ω(action.Routes[1].OperationID).Should(Equal("listAll"))
+			Ω(action.Routes[1].Summary).Should(Equal("List all things"))
+			Ω(action.Routes[1].ResponseDescriptions).Should(Equal(map[string]string{"OK": "All the things"}))
+		})
+
+		Context("with a duplicate operation ID", func() {
+			BeforeEach(func() {
+				routeID = "list"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`operation ID "list" is already used`))
+			})
+		})
+
+		Context("with an empty operation ID", func() {
+			BeforeEach(func() {
+				actionID = ""
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("operation ID cannot be empty"))
+			})
+		})
+	})
+
+	Context("with HTTP examples", func() {
+		var response string
+
+		BeforeEach(func() {
+			name = "create"
+			response = "Created"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(
+						POST(""),
+						POST("/all", func() {
+							HTTPExample("application/json", map[string]interface{}{"name": "all"}, response, nil)
+						}),
+					)
+					Payload(func() { Attribute("name", String) })
+					HTTPExample("application/json", map[string]interface{}{"name": "foo"}, response, map[string]interface{}{"id": 1})
+					Response(Created)
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("sets the action and route examples", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.HTTPExamples).Should(HaveLen(1))
+			Ω(action.HTTPExamples[0].ContentType).Should(Equal("application/json"))
+			Ω(action.HTTPExamples[0].Body).Should(Equal(map[string]interface{}{"id": 1}))
+			Ω(action.Routes[0].AllHTTPExamples()).Should(Equal(action.HTTPExamples))
+			all := action.Routes[1].AllHTTPExamples()
+			Ω(all).Should(HaveLen(1))
+			Ω(all[0].Request).Should(Equal(map[string]interface{}{"name": "all"}))
+		})
+
+		Context("with an unknown response", func() {
+			BeforeEach(func() {
+				response = "OK"
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("example set for unknown response OK"))
+			})
+		})
+	})
+
+	Context("with a view header", func() {
+		var header string
+
+		BeforeEach(func() {
+			name = "show"
+			header = "X-View"
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			MediaType("application/vnd.view", func() {
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+				View("tiny", func() {
+					Attribute("id")
+				})
+			})
+			Resource("res", func() {
+				Action(name, func() {
+					Routing(GET("/:id"))
+					ViewHeader(header)
+					Response(OK, "application/vnd.view")
+				})
+			})
+			dslengine.Run()
+			action = Design.Resources["res"].Actions[name]
+		})
+
+		It("defines the view header", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(action.ViewHeader).Should(Equal("X-View"))
+			Ω(action.Headers).ShouldNot(BeNil())
+			Ω(action.Headers.Type.ToObject()).Should(HaveKey("X-View"))
+			Ω(action.Headers.Type.ToObject()["X-View"].Validation.Values).Should(Equal([]interface{}{"default", "tiny"}))
+		})
+
+		Context("with an empty name", func() {
+			BeforeEach(func() {
+				header = ""
+			})
+
+			It("reports an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+	})
+
 	Context("with a name and DSL defining a description, route, headers, payload and responses", func() {
 		const typeName = "typeName"
 		const description = "description"
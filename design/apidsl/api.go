@@ -99,7 +99,7 @@ func Version(ver string) {
 	}
 }
 
-// Description can be used in: API, Resource, Action, MediaType, Attribute, Response or ResponseTemplate
+// Description can be used in: API, Resource, Action, MediaType, Attribute, Response, ResponseTemplate, Server or SwaggerTag
 //
 // Description sets the definition description.
 func Description(d string) {
@@ -122,6 +122,10 @@ func Description(d string) {
 		def.Description = d
 	case *design.SecuritySchemeDefinition:
 		def.Description = d
+	case *design.TagDefinition:
+		def.Description = d
+	case *design.ServerDefinition:
+		def.Description = d
 	default:
 		dslengine.IncompatibleDSL()
 	}
@@ -251,21 +255,100 @@ func TermsOfService(terms string) {
 // Regular expression used to validate RFC1035 hostnames*/
 var hostnameRegex = regexp.MustCompile(`^[[:alnum:]][[:alnum:]\-]{0,61}[[:alnum:]]|[[:alpha:]]$`)
 
-// Host used in: API
+// Host used in: API, Server
 //
-// Host sets the API hostname.
+// Host sets the API hostname. The host of a server may contain variables of the form {name}
+// defined with Variable.
 func Host(host string) {
 	if !hostnameRegex.MatchString(host) {
 		dslengine.ReportError(`invalid hostname value "%s"`, host)
 		return
 	}
 
-	if a, ok := apiDefinition(); ok {
-		a.Host = host
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.Host = host
+	case *design.ServerDefinition:
+		def.Host = host
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// Server can be used in: API
+//
+// Server defines a host serving the API in a given environment. The generated client package
+// exposes the servers and a constructor that configures the client for one of them by name. The
+// Swagger specification uses the first server host if the API does not define one and lists all
+// the servers in the "x-servers" extension of the info object. Example:
+//
+//    API("cellar", func() {
+//        Server("dev", func() {
+//            Description("Development server")
+//            Host("localhost:8080")
+//            Scheme("http")
+//        })
+//        Server("prod", func() {
+//            Host("{region}.cellar.goa.design")
+//            Scheme("https")
+//            Variable("region", "us-east-1", "us-east-1", "eu-west-1")
+//        })
+//    })
+func Server(name string, dsl func()) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	server := &design.ServerDefinition{Name: name}
+	if !dslengine.Execute(dsl, server) {
+		return
+	}
+	a.Servers = append(a.Servers, server)
+}
+
+// Variable can be used in: Server
+//
+// Variable defines a variable of the server host. The first argument is the name of the variable
+// as it appears in the host, the second its default value and the optional remaining arguments
+// the list of allowed values.
+func Variable(name, defaultValue string, values ...string) {
+	s, ok := dslengine.CurrentDefinition().(*design.ServerDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	s.Variables = append(s.Variables, &design.ServerVariableDefinition{
+		Name:    name,
+		Default: defaultValue,
+		Values:  values,
+	})
+}
+
+// HealthCheck can be used in: Server
+//
+// HealthCheck defines the request path of the server health check endpoint. The generated app
+// package mounts an endpoint that responds to the GET requests sent to that path with a 200 status
+// code and the Kubernetes manifests generated with goagen main --deploy use it for the liveness and
+// readiness probes of the service containers. Example:
+//
+//    Server("prod", func() {
+//        Host("cellar.goa.design")
+//        HealthCheck("/healthz")
+//    })
+func HealthCheck(path string) {
+	s, ok := dslengine.CurrentDefinition().(*design.ServerDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if !strings.HasPrefix(path, "/") {
+		dslengine.ReportError(`invalid health check path "%s", must start with "/"`, path)
+		return
 	}
+	s.HealthCheck = path
 }
 
-// Scheme can be used in: API, Resource, Action
+// Scheme can be used in: API, Server, Resource, Action
 //
 // Scheme sets the API URL schemes.
 func Scheme(vals ...string) {
@@ -283,6 +366,8 @@ func Scheme(vals ...string) {
 	switch def := dslengine.CurrentDefinition().(type) {
 	case *design.APIDefinition:
 		def.Schemes = append(def.Schemes, vals...)
+	case *design.ServerDefinition:
+		def.Schemes = append(def.Schemes, vals...)
 	case *design.ResourceDefinition:
 		def.Schemes = append(def.Schemes, vals...)
 	case *design.ActionDefinition:
@@ -317,7 +402,7 @@ func License(dsl func()) {
 	}
 }
 
-// Docs can be used in: API, Action, Files
+// Docs can be used in: API, Action, Files, SwaggerTag
 //
 // Docs provides external documentation pointers.
 func Docs(dsl func()) {
@@ -333,6 +418,8 @@ func Docs(dsl func()) {
 		def.Docs = docs
 	case *design.FileServerDefinition:
 		def.Docs = docs
+	case *design.TagDefinition:
+		def.Docs = docs
 	default:
 		dslengine.IncompatibleDSL()
 	}
@@ -566,6 +653,55 @@ func setupResponseTemplate(a *design.APIDefinition, name string, p interface{})
 	}
 }
 
+// ErrorClass can be used in: API
+//
+// ErrorClass defines a reusable class of errors identified by name, e.g. "not_found", "conflict"
+// or "rate_limited". The class defines an API response with the same name which uses the given
+// status code and renders the error with the ErrorMedia media type, the optional DSL may override
+// these or add a description or headers. Actions reference the class by name with Response:
+//
+//	var _ = API("cellar", func() {
+//		ErrorClass("rate_limited", 429, func() {
+//			Description("Too many requests")
+//			Headers(func() {
+//				Header("Retry-After", Integer)
+//			})
+//		})
+//	})
+//
+//	var _ = Resource("bottle", func() {
+//		Action("show", func() {
+//			Response("rate_limited")
+//		})
+//	})
+//
+// The generated app package defines a goa.ErrorClass for each class (ErrRateLimited in the
+// example above) so that controllers return errors that the ErrorHandler middleware renders with
+// the class status code.
+func ErrorClass(name string, status int, dsl ...func()) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	if a.Responses == nil {
+		a.Responses = make(map[string]*design.ResponseDefinition)
+	}
+	if _, ok := a.Responses[name]; ok {
+		dslengine.ReportError("multiple definitions for response template %s", name)
+		return
+	}
+	if _, ok := a.ResponseTemplates[name]; ok {
+		dslengine.ReportError("multiple definitions for response template %s", name)
+		return
+	}
+	r := &design.ResponseDefinition{Name: name, Status: status, MediaType: design.ErrorMediaIdentifier}
+	if len(dsl) > 0 && !dslengine.Execute(dsl[0], r) {
+		return
+	}
+	a.Responses[name] = r
+	a.ErrorClasses = append(a.ErrorClasses, name)
+}
+
 // Title used in: API
 //
 // Title sets the API title used by generated documentation, JSON Hyper-schema, code comments etc.
@@ -575,32 +711,196 @@ func Title(val string) {
 	}
 }
 
-// Trait can be used in: API
+// MaxRecursionDepth can be used in: API
 //
-// Trait defines an API trait. A trait encapsulates arbitrary DSL that gets executed wherever the
-// trait is called via the UseTrait function.
-func Trait(name string, val ...func()) {
+// MaxRecursionDepth sets the maximum number of levels a recursive user type or media type is
+// expanded to in the examples generated for the documentation, the JSON and Swagger schemas and
+// the random values used by the generated tests and benchmarks. Attributes that would exceed the
+// depth are omitted from the examples. The schemas always refer to user types and media types with
+// "$ref" so that recursive types never get inlined. The default is 2.
+//
+//	API("cellar", func() {
+//		MaxRecursionDepth(1)
+//	})
+func MaxRecursionDepth(depth int) {
 	if a, ok := apiDefinition(); ok {
-		if len(val) < 1 {
-			dslengine.ReportError("missing trait DSL for %s", name)
-			return
-		} else if len(val) > 1 {
-			dslengine.ReportError("too many arguments given to Trait")
-			return
-		}
-		if _, ok := design.Design.Traits[name]; ok {
-			dslengine.ReportError("multiple definitions for trait %s%s", name, design.Design.Context())
+		if depth < 1 {
+			dslengine.ReportError("invalid maximum recursion depth %d, must be at least 1", depth)
 			return
 		}
-		trait := &dslengine.TraitDefinition{Name: name, DSLFunc: val[0]}
-		if a.Traits == nil {
-			a.Traits = make(map[string]*dslengine.TraitDefinition)
-		}
-		a.Traits[name] = trait
+		a.MaxRecursionDepth = depth
+	}
+}
+
+// ExampleSeed can be used in: API
+//
+// ExampleSeed sets the seed of the random generator used to produce the examples of the
+// attributes that do not define one explicitly. The same seed always produces the same examples so
+// that the generated documentation is stable. The default seed is the API name.
+//
+//	API("cellar", func() {
+//		ExampleSeed("cellar-2016")
+//	})
+func ExampleSeed(seed string) {
+	if a, ok := apiDefinition(); ok {
+		a.ExampleSeed = seed
+	}
+}
+
+// RealisticExamples can be used in: API
+//
+// RealisticExamples derives the generated examples of the string attributes from their names so
+// that the published documentation and the mock servers show realistic but fake data rather than
+// random words. For example the attributes named "email", "phone", "first_name", "address", "city"
+// or "zip_code" get a fake email address, phone number, first name, street address, city or
+// postal code. The last words of the name are considered so that "billing_email" or "homePhone"
+// match too. Attributes that define an example, an example generator, an enum, a format or a
+// pattern are not affected, the formats already produce realistic values.
+//
+//	API("cellar", func() {
+//		RealisticExamples()
+//	})
+func RealisticExamples() {
+	if a, ok := apiDefinition(); ok {
+		a.RealisticExamples = true
+	}
+}
+
+// JSONRPC can be used in: API
+//
+// JSONRPC exposes all the actions of the API on a single JSON-RPC 2.0 endpoint that handles POST
+// requests sent to the given path. The name of the method of an action is made of the resource
+// and action names separated with a dot, e.g. "bottle.show". The method parameters are the
+// action parameters indexed by name, the "payload" parameter contains the action payload.
+// The generated application code includes a MountJSONRPC function that mounts the endpoint and
+// the generated client a method per action that calls it. The actions that use websockets or
+// multipart payloads are not exposed.
+//
+//	API("cellar", func() {
+//		JSONRPC("/rpc")
+//	})
+func JSONRPC(path string) {
+	if a, ok := apiDefinition(); ok {
+		a.JSONRPCPath = path
 	}
 }
 
-// UseTrait can be used in: Resource, Action, Type, MediaType, Attribute
+// JobsPath can be used in: API
+//
+// JobsPath sets the path prefix of the status endpoint of the jobs started by the asynchronous
+// actions, see Async. The endpoint handles the GET requests sent to the prefix followed by the job
+// identifier. The default is "/jobs".
+//
+//	API("cellar", func() {
+//		JobsPath("/operations")
+//	})
+func JobsPath(path string) {
+	if !strings.HasPrefix(path, "/") {
+		dslengine.ReportError("jobs path must start with a slash, got %q", path)
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.JobsPath = path
+	}
+}
+
+// NilCollections can be used in: API
+//
+// NilCollections sets how the nil slices and maps of the request and response bodies are encoded
+// so that all the endpoints behave the same. The value is one of:
+//
+//	"null":  the nil slices and maps encode as null, including the top-level collections.
+//	"empty": the nil slices and maps encode as [] and {}. The nil collections of the payloads
+//	         decoded by the service once validated and of the responses decoded by the client
+//	         are also replaced with empty collections so that they never see nil.
+//
+// By default the top-level collections encode as [] and the nested ones as null.
+//
+//	API("cellar", func() {
+//		NilCollections("empty")
+//	})
+func NilCollections(encoding string) {
+	if encoding != design.NilCollectionsNull && encoding != design.NilCollectionsEmpty {
+		dslengine.ReportError("invalid nil collections encoding %q, must be %q or %q",
+			encoding, design.NilCollectionsNull, design.NilCollectionsEmpty)
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.NilCollections = encoding
+	}
+}
+
+// RequestIDFormat can be used in: API
+//
+// RequestIDFormat sets the format of the IDs created by the request ID middleware of the generated
+// service for the requests that do not carry one in the X-Request-Id header. The value is one of:
+//
+//	"uuidv7":    version 7 UUIDs that sort by creation time.
+//	"ulid":      Universally Unique Lexicographically Sortable Identifiers.
+//	"snowflake": 64-bit integers made of a timestamp, a node number and a sequence number.
+//
+// By default the IDs are made of a random process prefix and of a counter. The request ID is
+// propagated to the requests made by the generated clients with the request context whatever the
+// format.
+//
+//	API("cellar", func() {
+//		RequestIDFormat("ulid")
+//	})
+func RequestIDFormat(format string) {
+	switch format {
+	case design.RequestIDUUIDv7, design.RequestIDULID, design.RequestIDSnowflake:
+	default:
+		dslengine.ReportError("invalid request ID format %q, must be %q, %q or %q",
+			format, design.RequestIDUUIDv7, design.RequestIDULID, design.RequestIDSnowflake)
+		return
+	}
+	if a, ok := apiDefinition(); ok {
+		a.RequestIDFormat = format
+	}
+}
+
+// Trait can be used in: API, top-level
+//
+// Trait defines an API trait. A trait encapsulates arbitrary DSL that gets executed wherever the
+// trait is called via the UseTrait function. Traits defined at the top level can be shared by the
+// designs of several APIs and grouped with the definitions they use, for example:
+//
+//	var Paginated = Trait("paginated", func() {
+//		Params(func() {
+//			Param("page", Integer, "Page number", func() { Minimum(1) })
+//			Param("per_page", Integer, "Page size", func() { Maximum(100) })
+//		})
+//		Response(BadRequest, ErrorMedia)
+//	})
+func Trait(name string, val ...func()) *dslengine.TraitDefinition {
+	var a *design.APIDefinition
+	if dslengine.IsTopLevelDefinition() {
+		a = design.Design
+	} else if api, ok := apiDefinition(); ok {
+		a = api
+	} else {
+		return nil
+	}
+	if len(val) < 1 {
+		dslengine.ReportError("missing trait DSL for %s", name)
+		return nil
+	} else if len(val) > 1 {
+		dslengine.ReportError("too many arguments given to Trait")
+		return nil
+	}
+	if _, ok := a.Traits[name]; ok {
+		dslengine.ReportError("multiple definitions for trait %s%s", name, a.Context())
+		return nil
+	}
+	trait := &dslengine.TraitDefinition{Name: name, DSLFunc: val[0]}
+	if a.Traits == nil {
+		a.Traits = make(map[string]*dslengine.TraitDefinition)
+	}
+	a.Traits[name] = trait
+	return trait
+}
+
+// UseTrait can be used in: Resource, Action, Type, MediaType, Attribute, Response
 //
 // UseTrait executes the API trait with the given name. An API level DSL trait must be
 // defined first. UseTrait takes a variable number of trait names.
@@ -614,8 +914,12 @@ func UseTrait(names ...string) {
 		def = typedDef
 	case *design.AttributeDefinition:
 		def = typedDef
+	case *design.UserTypeDefinition:
+		def = typedDef
 	case *design.MediaTypeDefinition:
 		def = typedDef
+	case *design.ResponseDefinition:
+		def = typedDef
 	default:
 		dslengine.IncompatibleDSL()
 	}
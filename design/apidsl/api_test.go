@@ -56,6 +56,82 @@ var _ = Describe("API", func() {
 		})
 	})
 
+	Context("with a server using an undefined host variable", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Server("prod", func() {
+					Host("{region}.example.com")
+				})
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`undefined variable "region"`))
+		})
+	})
+
+	Context("with a server variable default value that is not allowed", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Server("prod", func() {
+					Host("{region}.example.com")
+					Variable("region", "ap", "us", "eu")
+				})
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("is not one of the allowed values"))
+		})
+	})
+
+	Context("with a JSON-RPC path containing a wildcard", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				JSONRPC("/rpc/:id")
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot contain wildcards"))
+		})
+	})
+
+	Context("with an error class defined twice", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				ErrorClass("conflict", 409)
+				ErrorClass("conflict", 412)
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring("multiple definitions for response template conflict"))
+		})
+	})
+
+	Context("with an invalid maximum recursion depth", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				MaxRecursionDepth(0)
+			}
+		})
+
+		It("returns an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(Design.RecursionDepth()).Should(Equal(DefaultMaxRecursionDepth))
+		})
+	})
+
 	Context("with valid DSL", func() {
 		JustBeforeEach(func() {
 			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
@@ -90,6 +166,44 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with a maximum recursion depth", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					MaxRecursionDepth(3)
+				}
+			})
+
+			It("sets the API maximum recursion depth", func() {
+				Ω(Design.MaxRecursionDepth).Should(Equal(3))
+				Ω(Design.RecursionDepth()).Should(Equal(3))
+			})
+		})
+
+		Context("with an example seed", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					ExampleSeed("seed")
+				}
+			})
+
+			It("seeds the example random generator", func() {
+				Ω(Design.ExampleSeed).Should(Equal("seed"))
+				Ω(Design.RandomGenerator().Int()).Should(Equal(NewRandomGenerator("seed").Int()))
+			})
+		})
+
+		Context("with a JSON-RPC endpoint", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					JSONRPC("/rpc")
+				}
+			})
+
+			It("sets the JSON-RPC path", func() {
+				Ω(Design.JSONRPCPath).Should(Equal("/rpc"))
+			})
+		})
+
 		Context("with a version", func() {
 			const version = "2.0"
 
@@ -163,6 +277,38 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with servers", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Server("dev", func() {
+						Description("Development")
+						Host("localhost:8080")
+						HealthCheck("/healthz")
+					})
+					Server("prod", func() {
+						Host("{region}.example.com")
+						Scheme("https")
+						Variable("region", "us", "us", "eu")
+					})
+				}
+			})
+
+			It("sets the API servers", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Servers).Should(Equal([]*ServerDefinition{
+					{Name: "dev", Description: "Development", Host: "localhost:8080", HealthCheck: "/healthz"},
+					{
+						Name:      "prod",
+						Host:      "{region}.example.com",
+						Schemes:   []string{"https"},
+						Variables: []*ServerVariableDefinition{{Name: "region", Default: "us", Values: []string{"us", "eu"}}},
+					},
+				}))
+				Ω(Design.Servers[1].ResolveHost(nil)).Should(Equal("us.example.com"))
+				Ω(Design.Servers[1].ResolveHost(map[string]string{"region": "eu"})).Should(Equal("eu.example.com"))
+			})
+		})
+
 		Context("with Consumes", func() {
 			const consumesMT = "application/json"
 
@@ -340,6 +486,31 @@ var _ = Describe("API", func() {
 			})
 		})
 
+		Context("with ErrorClasses", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					ErrorClass("rate_limited", 429, func() {
+						Description("Too many requests")
+					})
+					ErrorClass("conflict", 409)
+				}
+			})
+
+			It("sets the API error responses", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.ErrorClasses).Should(Equal([]string{"rate_limited", "conflict"}))
+				Ω(Design.Responses).Should(HaveKey("rate_limited"))
+				expected := ResponseDefinition{
+					Name:        "rate_limited",
+					Description: "Too many requests",
+					Status:      429,
+					MediaType:   ErrorMediaIdentifier,
+				}
+				Ω(*Design.Responses["rate_limited"]).Should(Equal(expected))
+				Ω(Design.Responses["conflict"].Status).Should(Equal(409))
+			})
+		})
+
 		Context("with Traits", func() {
 			const traitName = "Authenticated"
 
@@ -448,6 +619,90 @@ var _ = Describe("API", func() {
 				Ω(o).Should(HaveKey("baz"))
 			})
 		})
+
+		Context("using top-level Traits", func() {
+			var trait *dslengine.TraitDefinition
+
+			BeforeEach(func() {
+				name = "foo"
+				trait = Trait("paginated", func() {
+					Attribute("page", Integer)
+				})
+				Trait("cached", func() {
+					Headers(func() {
+						Header("Cache-Control")
+					})
+				})
+			})
+
+			JustBeforeEach(func() {
+				Type("Query", func() {
+					UseTrait("paginated")
+					Attribute("q", String)
+				})
+				Resource("res", func() {
+					Action("list", func() {
+						Routing(GET("/"))
+						Response(OK, func() {
+							UseTrait("cached")
+						})
+					})
+				})
+				dslengine.Run()
+			})
+
+			It("runs the traits", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(trait).ShouldNot(BeNil())
+				Ω(Design.Traits).Should(HaveKeyWithValue("paginated", trait))
+				Ω(Design.Types["Query"].Type.ToObject()).Should(HaveKey("page"))
+				Ω(Design.Types["Query"].Type.ToObject()).Should(HaveKey("q"))
+				resp := Design.Resources["res"].Actions["list"].Responses["OK"]
+				Ω(resp.Headers.Type.ToObject()).Should(HaveKey("Cache-Control"))
+			})
+		})
+	})
+
+})
+
+var _ = Describe("NilCollections", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
 	})
 
+	It("sets the encoding of the nil collections", func() {
+		API("cellar", func() {
+			NilCollections("empty")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.NilCollections).Should(Equal(NilCollectionsEmpty))
+	})
+
+	It("rejects unknown encodings", func() {
+		API("cellar", func() {
+			NilCollections("omit")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("RequestIDFormat", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("sets the format of the request IDs", func() {
+		API("cellar", func() {
+			RequestIDFormat("ulid")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.RequestIDFormat).Should(Equal(RequestIDULID))
+	})
+
+	It("rejects unknown formats", func() {
+		API("cellar", func() {
+			RequestIDFormat("uuidv4")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
 })
@@ -0,0 +1,91 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Async", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("adds the job status response", func() {
+		API("async", func() {})
+		Resource("report", func() {
+			Action("export", func() {
+				Routing(POST("/reports"))
+				Async()
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		a := Design.Resources["report"].Actions["export"]
+		Ω(a.Async).Should(BeTrue())
+		Ω(a.Responses).Should(HaveKey(Accepted))
+		resp := a.Responses[Accepted]
+		Ω(resp.Status).Should(Equal(202))
+		Ω(resp.MediaType).Should(Equal(JobMediaIdentifier))
+		Ω(resp.LocationAttribute).Should(Equal("href"))
+		Ω(resp.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		Ω(Design.MediaTypeWithIdentifier(JobMediaIdentifier)).Should(Equal(JobMedia))
+		Ω(Design.JobsPath).Should(Equal(DefaultJobsPath))
+		Ω(Design.JobStatusPath()).Should(Equal("/jobs/:jobID"))
+	})
+
+	It("keeps an explicit Accepted response", func() {
+		Resource("report", func() {
+			Action("export", func() {
+				Routing(POST("/reports"))
+				Async()
+				Response(Accepted, func() { Description("queued") })
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		resp := Design.Resources["report"].Actions["export"].Responses[Accepted]
+		Ω(resp.Description).Should(Equal("queued"))
+		Ω(resp.MediaType).Should(BeEmpty())
+	})
+
+	It("does not add the job media type to synchronous APIs", func() {
+		Resource("report", func() {
+			Action("show", func() {
+				Routing(GET("/reports/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.HasAsyncActions()).Should(BeFalse())
+		Ω(Design.JobsPath).Should(BeEmpty())
+		Ω(Design.MediaTypeWithIdentifier(JobMediaIdentifier)).Should(BeNil())
+	})
+})
+
+var _ = Describe("JobsPath", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("sets the job status endpoint path prefix", func() {
+		API("async", func() {
+			JobsPath("/operations")
+		})
+		Resource("report", func() {
+			Action("export", func() {
+				Routing(POST("/reports"))
+				Async()
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.JobStatusPath()).Should(Equal("/operations/:jobID"))
+	})
+
+	It("requires a leading slash", func() {
+		API("async", func() {
+			JobsPath("operations")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
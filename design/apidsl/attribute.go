@@ -80,6 +80,32 @@ import (
 //
 //	Attribute(name string)			// dataType is String
 func Attribute(name string, args ...interface{}) {
+	attribute(name, args...)
+}
+
+// Field can be used in: Type, Attribute, Attributes, Payload
+//
+// Field is Attribute with an additional first argument that sets the field number used when
+// the attribute is serialized with protocol buffers, e.g. by the gRPC transport. Field numbers
+// must be unique within a type and no other field may reuse a number once it has been assigned
+// so that the messages remain compatible across versions of the design. Valid field numbers
+// range from 1 to 536,870,911 excluding the block 19,000 to 19,999 reserved by protocol buffers.
+//
+//	var Bottle = Type("bottle", func() {
+//		Field(1, "name", String)
+//		Field(2, "vintage", Integer, func() {
+//			Minimum(1900)
+//		})
+//	})
+func Field(tag int, name string, args ...interface{}) {
+	if att := attribute(name, args...); att != nil {
+		att.FieldNumber = tag
+	}
+}
+
+// attribute implements Attribute and returns the attribute definition it created, nil if the
+// DSL is invalid.
+func attribute(name string, args ...interface{}) *design.AttributeDefinition {
 	var parent *design.AttributeDefinition
 
 	switch def := dslengine.CurrentDefinition().(type) {
@@ -109,7 +135,7 @@ func Attribute(name string, args ...interface{}) {
 		}
 		if _, ok := parent.Type.(design.Object); !ok {
 			dslengine.ReportError("can't define child attributes on attribute of type %s", parent.Type.Name())
-			return
+			return nil
 		}
 
 		baseAttr := attributeFromRef(name, parent.Reference)
@@ -131,12 +157,27 @@ func Attribute(name string, args ...interface{}) {
 		if dsl != nil {
 			dslengine.Execute(dsl, baseAttr)
 		}
+		decimalAttribute(baseAttr)
 		if baseAttr.Type == nil {
 			// DSL did not contain an "Attribute" declaration
 			baseAttr.Type = design.String
 		}
-		parent.Type.(design.Object)[name] = baseAttr
+		obj := parent.Type.(design.Object)
+		if existing, ok := obj[name]; ok {
+			baseAttr.Position = existing.Position
+		} else {
+			baseAttr.Position = 0
+			for _, att := range obj {
+				if att.Position > baseAttr.Position {
+					baseAttr.Position = att.Position
+				}
+			}
+			baseAttr.Position++
+		}
+		obj[name] = baseAttr
+		return baseAttr
 	}
+	return nil
 }
 
 // attributeFromRef returns a base attribute given a reference data type.
@@ -275,6 +316,44 @@ func Param(name string, args ...interface{}) {
 	Attribute(name, args...)
 }
 
+// Style can be used in: Param
+//
+// Style sets the serialization style of an array query string parameter. The style is one of
+// design.FormStyle (default), design.PipeDelimitedStyle or design.SpaceDelimitedStyle. The optional
+// explode argument applies to the form style: the values are sent as repeated key=value pairs when
+// true (default) and as a single comma separated value otherwise. The generated clients encode
+// the values accordingly and the generated controllers accept both the repeated and the
+// delimited forms. Example:
+//
+//    Params(func() {
+//        Param("ids", ArrayOf(Integer), func() {
+//            Style(FormStyle, false) // ?ids=1,2,3
+//        })
+//        Param("tags", ArrayOf(String), func() {
+//            Style(PipeDelimitedStyle) // ?tags=a|b|c
+//        })
+//    })
+func Style(style string, explode ...bool) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	switch style {
+	case design.FormStyle, design.PipeDelimitedStyle, design.SpaceDelimitedStyle:
+	default:
+		dslengine.ReportError(`invalid style %#v, must be one of %#v, %#v or %#v`, style,
+			design.FormStyle, design.PipeDelimitedStyle, design.SpaceDelimitedStyle)
+		return
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	a.Metadata["param:style"] = []string{style}
+	if len(explode) > 0 {
+		a.Metadata["param:explode"] = []string{strconv.FormatBool(explode[0])}
+	}
+}
+
 // Default can be used in: Attribute
 //
 // Default sets the default value for an attribute.
@@ -310,13 +389,46 @@ func Default(def interface{}) {
 //		Attribute("price", String) //If no Example() is provided, goa generates one that fits your specification
 //	})
 //
+// Example also accepts a name followed by the value to define named examples, it may be called
+// multiple times to list several examples. The named examples appear in the "x-examples"
+// extension of the generated JSON schema and Swagger definitions and in the help of the generated
+// CLI tool. The first named example is also used as the attribute example:
+//
+//	Attribute("vintage", Integer, func() {
+//		Example("recent", 2015)
+//		Example("old", 1962)
+//	})
+//
 // If you do not want an auto-generated example for an attribute, add NoExample() to it.
-func Example(exp interface{}) {
-	if a, ok := attributeDefinition(); ok {
-		if pass := a.SetExample(exp); !pass {
+func Example(args ...interface{}) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return
+	}
+	switch len(args) {
+	case 1:
+		if pass := a.SetExample(args[0]); !pass {
 			dslengine.ReportError("example value %#v is incompatible with attribute of type %s",
-				exp, a.Type.Name())
+				args[0], a.Type.Name())
+		}
+	case 2:
+		summary, ok := args[0].(string)
+		if !ok {
+			dslengine.InvalidArgError("string", args[0])
+			return
 		}
+		for _, ex := range a.Examples {
+			if ex.Summary == summary {
+				dslengine.ReportError("example %#v is defined twice", summary)
+				return
+			}
+		}
+		if pass := a.AddExample(summary, args[1]); !pass {
+			dslengine.ReportError("example %#v value %#v is incompatible with attribute of type %s",
+				summary, args[1], a.Type.Name())
+		}
+	default:
+		dslengine.ReportError("invalid number of arguments in call to Example")
 	}
 }
 
@@ -335,10 +447,147 @@ func NoExample() {
 	}
 }
 
+// ExampleGenerator can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// ExampleGenerator sets the function used to produce the example of an attribute that does not
+// define one with Example. The function is given the random generator of the API so that the
+// example remains deterministic. Examples for a given format can be customized for all the
+// attributes at once with design.RegisterFormatExample.
+//
+//	Attribute("name", String, func() {
+//		ExampleGenerator(func(r *design.RandomGenerator) interface{} {
+//			return r.Name()
+//		})
+//	})
+func ExampleGenerator(fn design.ExampleFunc) {
+	if a, ok := attributeDefinition(); ok {
+		if fn == nil {
+			dslengine.ReportError("example generator cannot be nil")
+			return
+		}
+		a.ExampleGenerator = fn
+	}
+}
+
+// Sensitive can be used in: Attribute, Header, Param
+//
+// Sensitive marks the attribute as containing sensitive data such as passwords or tokens. The
+// values of sensitive attributes are redacted by the LogRequest middleware and by the client
+// request and response dumps. The attributes of payloads and types are only redacted from the
+// values of the types that define them, the params and headers are redacted by name. The examples
+// of sensitive attributes are masked in the generated Swagger and JSON schema documents.
+//
+//    Attribute("password", String, func() {
+//        Sensitive()
+//    })
+//
+func Sensitive() {
+	if a, ok := attributeDefinition(); ok {
+		a.Sensitive = true
+	}
+}
+
+// ReadOnly can be used in: Attribute
+//
+// ReadOnly marks the attribute as only appearing in responses so that the same type can describe
+// both the request payload and the response media type, e.g. with Reference. The generated code
+// ignores read-only attributes when decoding request payloads and the Swagger and JSON schema
+// documents set their "readOnly" property.
+//
+//	Attribute("id", Integer, func() {
+//		ReadOnly()
+//	})
+func ReadOnly() {
+	if a, ok := attributeDefinition(); ok {
+		a.ReadOnly = true
+	}
+}
+
+// WriteOnly can be used in: Attribute
+//
+// WriteOnly marks the attribute as only appearing in requests, e.g. a password. The generated
+// media types never render write-only attributes and the Swagger and JSON schema documents set
+// their "x-writeOnly" extension.
+func WriteOnly() {
+	if a, ok := attributeDefinition(); ok {
+		a.WriteOnly = true
+	}
+}
+
+// Key can be used in: Attribute, Param, Header
+//
+// Key sets the name of the attribute in the request and response bodies, query strings and
+// headers so that it may differ from the attribute name used in the design. The generated Go
+// field name is still computed from the attribute name (see the struct:field:name metadata to
+// override it). Path parameters cannot set a key, the route wildcards name them.
+//
+//	Attribute("createdAt", DateTime, func() {
+//		Key("created_at")
+//	})
+//
+//	Headers(func() {
+//		Header("requestID", String, func() {
+//			Key("X-Request-Id")
+//		})
+//	})
+func Key(key string) {
+	if key == "" {
+		dslengine.ReportError("key cannot be empty")
+		return
+	}
+	if a, ok := attributeDefinition(); ok {
+		a.Key = key
+	}
+}
+
+// Sanitize can be used in: Attribute, Header, Param, ArrayOf
+//
+// Sanitize normalizes the value of a string attribute when decoding requests, before the
+// validations run, so that the controllers receive trimmed or lower cased values without having
+// to normalize them in every action. The sanitizers are applied in order. The built-in sanitizers
+// are "trim", "lowercase", "uppercase" and "collapse" (which also collapses the internal runs of
+// white space), see the goa.Sanitize* constants. Custom sanitizers are Go functions registered
+// with goa.RegisterSanitizer by the service.
+//
+//	Attribute("email", String, func() {
+//		Sanitize("trim", "lowercase")
+//		Format("email")
+//	})
+//
+//	Param("tags", ArrayOf(String, func() {
+//		Sanitize("slug") // registered with goa.RegisterSanitizer("slug", slugify)
+//	}))
+func Sanitize(names ...string) {
+	if len(names) == 0 {
+		dslengine.ReportError("missing sanitizer names")
+		return
+	}
+	for _, n := range names {
+		if n == "" {
+			dslengine.ReportError("sanitizer name cannot be empty")
+			return
+		}
+	}
+	if a, ok := attributeDefinition(); ok {
+		a.Sanitizers = append(a.Sanitizers, names...)
+	}
+}
+
 // Enum can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // Enum adds a "enum" validation to the attribute.
 // See http://json-schema.org/latest/json-schema-validation.html#anchor76.
+//
+// goagen generates a named Go type for the string, integer and number attributes of types,
+// media types and payloads that use Enum. The type is named after the parent type and the
+// attribute and comes with one constant per value and a IsValid method, e.g.:
+//
+//    type BottleColor string
+//
+//    const (
+//        BottleColorRed   BottleColor = "red"
+//        BottleColorWhite BottleColor = "white"
+//    )
 func Enum(val ...interface{}) {
 	if a, ok := attributeDefinition(); ok {
 		ok := true
@@ -378,6 +627,7 @@ func Enum(val ...interface{}) {
 var SupportedValidationFormats = []string{
 	"cidr",
 	"date-time",
+	"decimal",
 	"email",
 	"hostname",
 	"ipv4",
@@ -397,6 +647,8 @@ var SupportedValidationFormats = []string{
 //
 // "date-time": RFC3339 date time
 //
+// "decimal": decimal number, e.g. "-12.50", see also the Decimal type
+//
 // "email": RFC5322 email address
 //
 // "hostname": RFC1035 internet host name
@@ -468,19 +720,8 @@ func Minimum(val interface{}) {
 		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
 			incompatibleAttributeType("minimum", a.Type.Name(), "an integer or a number")
 		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
+			f, ok := numberValue(val)
+			if !ok {
 				return
 			}
 			if a.Validation == nil {
@@ -500,19 +741,8 @@ func Maximum(val interface{}) {
 		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
 			incompatibleAttributeType("maximum", a.Type.Name(), "an integer or a number")
 		} else {
-			var f float64
-			switch v := val.(type) {
-			case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-				f = reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float()
-			case string:
-				var err error
-				f, err = strconv.ParseFloat(v, 64)
-				if err != nil {
-					dslengine.ReportError("invalid number value %#v", v)
-					return
-				}
-			default:
-				dslengine.ReportError("invalid number value %#v", v)
+			f, ok := numberValue(val)
+			if !ok {
 				return
 			}
 			if a.Validation == nil {
@@ -523,6 +753,76 @@ func Maximum(val interface{}) {
 	}
 }
 
+// ExclusiveMinimum can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// ExclusiveMinimum adds an exclusive minimum validation to the attribute: values must be strictly
+// greater than val. The generated OpenAPI specification describes the validation with the
+// "minimum" and "exclusiveMinimum" fields.
+func ExclusiveMinimum(val interface{}) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+			incompatibleAttributeType("exclusive minimum", a.Type.Name(), "an integer or a number")
+		} else {
+			f, ok := numberValue(val)
+			if !ok {
+				return
+			}
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.ExclusiveMinimum = &f
+		}
+	}
+}
+
+// ExclusiveMaximum can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// ExclusiveMaximum adds an exclusive maximum validation to the attribute: values must be strictly
+// less than val. The generated OpenAPI specification describes the validation with the "maximum"
+// and "exclusiveMaximum" fields.
+func ExclusiveMaximum(val interface{}) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+			incompatibleAttributeType("exclusive maximum", a.Type.Name(), "an integer or a number")
+		} else {
+			f, ok := numberValue(val)
+			if !ok {
+				return
+			}
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.ExclusiveMaximum = &f
+		}
+	}
+}
+
+// MultipleOf can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// MultipleOf adds a "multipleOf" validation to the attribute: values must be a multiple of val
+// which must be strictly greater than 0.
+// See http://json-schema.org/latest/json-schema-validation.html#anchor14.
+func MultipleOf(val interface{}) {
+	if a, ok := attributeDefinition(); ok {
+		if a.Type != nil && a.Type.Kind() != design.IntegerKind && a.Type.Kind() != design.NumberKind {
+			incompatibleAttributeType("multiple of", a.Type.Name(), "an integer or a number")
+		} else {
+			f, ok := numberValue(val)
+			if !ok {
+				return
+			}
+			if f <= 0 {
+				dslengine.ReportError("invalid multiple of value %#v, must be greater than 0", val)
+				return
+			}
+			if a.Validation == nil {
+				a.Validation = &dslengine.ValidationDefinition{}
+			}
+			a.Validation.MultipleOf = &f
+		}
+	}
+}
+
 // MinLength can be used in: Attribute, Header, Param, HashOf, ArrayOf
 //
 // MinLength adds a "minItems" validation to the attribute.
@@ -584,6 +884,73 @@ func Required(names ...string) {
 	}
 }
 
+// RequiredTogether can be used in: Attributes, Headers, Payload, Type, Params
+//
+// RequiredTogether adds a validation that requires the given attributes to be either all set or
+// all missing, e.g.:
+//
+//	Payload(func() {
+//		Attribute("start", DateTime)
+//		Attribute("end", DateTime)
+//		RequiredTogether("start", "end")
+//	})
+//
+// The groups are described in the JSON schema and swagger specification "dependencies".
+func RequiredTogether(names ...string) {
+	if v := fieldGroupValidation("required together", names); v != nil {
+		v.RequiredTogether = append(v.RequiredTogether, names)
+	}
+}
+
+// MutuallyExclusive can be used in: Attributes, Headers, Payload, Type, Params
+//
+// MutuallyExclusive adds a validation that allows at most one of the given attributes to be set.
+// The attributes may not be required or have a default value.
+func MutuallyExclusive(names ...string) {
+	if v := fieldGroupValidation("mutually exclusive", names); v != nil {
+		v.MutuallyExclusive = append(v.MutuallyExclusive, names)
+	}
+}
+
+// AtLeastOneOf can be used in: Attributes, Headers, Payload, Type, Params
+//
+// AtLeastOneOf adds a validation that requires at least one of the given attributes to be set.
+func AtLeastOneOf(names ...string) {
+	if v := fieldGroupValidation("at least one of", names); v != nil {
+		v.AtLeastOneOf = append(v.AtLeastOneOf, names)
+	}
+}
+
+// fieldGroupValidation returns the validation of the object attribute being defined that the
+// given group of attribute names is added to. It reports an error and returns nil if the DSL is
+// not used in an object attribute or if the group names less than two attributes.
+func fieldGroupValidation(validation string, names []string) *dslengine.ValidationDefinition {
+	var at *design.AttributeDefinition
+
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.AttributeDefinition:
+		at = def
+	case *design.MediaTypeDefinition:
+		at = def.AttributeDefinition
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if at.Type != nil && at.Type.Kind() != design.ObjectKind {
+		incompatibleAttributeType(validation, at.Type.Name(), "an object")
+		return nil
+	}
+	if len(names) < 2 {
+		dslengine.ReportError("invalid %s validation definition: at least two attribute names are required", validation)
+		return nil
+	}
+	if at.Validation == nil {
+		at.Validation = &dslengine.ValidationDefinition{}
+	}
+	return at.Validation
+}
+
 // incompatibleAttributeType reports an error for validations defined on
 // incompatible attributes (e.g. max value on string).
 func incompatibleAttributeType(validation, actual, expected string) {
@@ -591,6 +958,25 @@ func incompatibleAttributeType(validation, actual, expected string) {
 		validation, expected, actual)
 }
 
+// numberValue returns the float64 value of the given number or string, it reports an error and
+// returns false if val is not a number.
+func numberValue(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float32, float64, int, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return reflect.ValueOf(v).Convert(reflect.TypeOf(float64(0.0))).Float(), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			dslengine.ReportError("invalid number value %#v", v)
+			return 0, false
+		}
+		return f, true
+	default:
+		dslengine.ReportError("invalid number value %#v", v)
+		return 0, false
+	}
+}
+
 // qualifiedTypeName returns the qualified type name for the given data type.
 // This is useful in reporting types in error messages.
 // (e.g) array<string>, hash<string, string>, hash<string, array<int>>
@@ -124,6 +124,28 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with sibling attributes", func() {
+		BeforeEach(func() {
+			name = "foo"
+		})
+
+		JustBeforeEach(func() {
+			Type("siblings", func() {
+				Attribute("b")
+				Attribute("a")
+				Attribute("c")
+			})
+			dslengine.Run()
+		})
+
+		It("records the order of definition", func() {
+			o := Design.Types["siblings"].Type.ToObject()
+			Ω(o["b"].Position).Should(Equal(1))
+			Ω(o["a"].Position).Should(Equal(2))
+			Ω(o["c"].Position).Should(Equal(3))
+		})
+	})
+
 	Context("with a name and datatype", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -141,6 +163,63 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name and a DSL marking the attribute as sensitive", func() {
+		BeforeEach(func() {
+			name = "password"
+			dsl = func() { Sensitive() }
+		})
+
+		It("produces a sensitive attribute", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Sensitive).Should(BeTrue())
+			Ω(parent.SensitiveFields()).Should(Equal([]string{name}))
+			Ω(Design.SensitiveNames()).Should(BeEmpty())
+		})
+	})
+
+	Context("with a name and a DSL marking the attribute as read-only", func() {
+		BeforeEach(func() {
+			name = "id"
+			dsl = func() { ReadOnly() }
+		})
+
+		It("produces a read-only attribute", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].ReadOnly).Should(BeTrue())
+			Ω(o[name].WriteOnly).Should(BeFalse())
+		})
+	})
+
+	Context("with a name and a DSL marking the attribute as write-only", func() {
+		BeforeEach(func() {
+			name = "password"
+			dsl = func() { WriteOnly() }
+		})
+
+		It("produces a write-only attribute", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].WriteOnly).Should(BeTrue())
+			Ω(o[name].ReadOnly).Should(BeFalse())
+		})
+	})
+
+	Context("with a name and a DSL setting the key", func() {
+		BeforeEach(func() {
+			name = "createdAt"
+			dsl = func() { Key("created_at") }
+		})
+
+		It("produces an attribute with a key", func() {
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Key).Should(Equal("created_at"))
+			Ω(o[name].AttributeKey(name)).Should(Equal("created_at"))
+		})
+	})
+
 	Context("with a name and uuid datatype", func() {
 		BeforeEach(func() {
 			name = "foo"
@@ -274,6 +353,116 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 
+	Context("with a name, type string and a DSL defining sanitizers", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { Sanitize("trim"); Sanitize("lowercase", "slug") }
+		})
+
+		It("records the sanitizers in order", func() {
+			o := parent.Type.ToObject()
+			Ω(o).Should(HaveKey(name))
+			Ω(o[name].Sanitizers).Should(Equal([]string{"trim", "lowercase", "slug"}))
+		})
+	})
+
+	Context("with a name, type number and a DSL defining exclusive bounds", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Number
+			dsl = func() { ExclusiveMinimum(0); ExclusiveMaximum("10.5"); MultipleOf(0.5) }
+		})
+
+		It("produces an attribute with the validations", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			o := parent.Type.(Object)
+			Ω(o).Should(HaveKey(name))
+			val := o[name].Validation
+			Ω(val).ShouldNot(BeNil())
+			Ω(val.Minimum).Should(BeNil())
+			Ω(*val.ExclusiveMinimum).Should(Equal(0.0))
+			Ω(*val.ExclusiveMaximum).Should(Equal(10.5))
+			Ω(*val.MultipleOf).Should(Equal(0.5))
+		})
+	})
+
+	Context("with a DSL defining a multiple of validation that is not positive", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = Integer
+			dsl = func() { MultipleOf(0) }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a DSL defining an exclusive minimum on a string", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dataType = String
+			dsl = func() { ExclusiveMinimum(1) }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a DSL defining named examples", func() {
+		BeforeEach(func() {
+			name = "vintage"
+			dataType = Integer
+			dsl = func() {
+				Example("recent", 2015)
+				Example("old", 1962)
+			}
+		})
+
+		It("records the examples in order", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			att := parent.Type.(Object)[name]
+			Ω(att.Examples).Should(HaveLen(2))
+			Ω(att.Examples[0].Summary).Should(Equal("recent"))
+			Ω(att.Examples[0].Value).Should(Equal(2015))
+			Ω(att.Examples[1].Summary).Should(Equal("old"))
+			Ω(att.Examples[1].Value).Should(Equal(1962))
+		})
+
+		It("uses the first example as attribute example", func() {
+			Ω(parent.Type.(Object)[name].Example).Should(Equal(2015))
+		})
+	})
+
+	Context("with a DSL defining the same named example twice", func() {
+		BeforeEach(func() {
+			name = "vintage"
+			dataType = Integer
+			dsl = func() {
+				Example("recent", 2015)
+				Example("recent", 2016)
+			}
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with a DSL defining a named example of the wrong type", func() {
+		BeforeEach(func() {
+			name = "vintage"
+			dataType = Integer
+			dsl = func() { Example("recent", "2015") }
+		})
+
+		It("reports an error", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a name and type uuid", func() {
 		BeforeEach(func() {
 			name = "birthdate"
@@ -381,3 +570,66 @@ var _ = Describe("Attribute", func() {
 		})
 	})
 })
+
+var _ = Describe("Field", func() {
+	var dsl func()
+	var ut *UserTypeDefinition
+	var err error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		ut = Type("type", dsl)
+		err = dslengine.Run()
+	})
+
+	Context("with field numbers", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Field(1, "foo", String)
+				Field(2, "bar", Integer, func() {
+					Minimum(1)
+				})
+			}
+		})
+
+		It("sets the attribute field numbers", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			o := ut.ToObject()
+			Ω(o["foo"].FieldNumber).Should(Equal(1))
+			Ω(o["foo"].Type).Should(Equal(String))
+			Ω(o["bar"].FieldNumber).Should(Equal(2))
+			Ω(o["bar"].Validation.Minimum).ShouldNot(BeNil())
+		})
+	})
+
+	Context("with duplicate field numbers", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Field(1, "foo", String)
+				Field(1, "bar", String)
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("field number 1 is used by both bar and foo"))
+		})
+	})
+
+	Context("with a reserved field number", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				Field(19000, "foo", String)
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("invalid field number 19000 for field foo"))
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Audit can be used in: Resource, Action
+//
+// Audit marks the actions of the resource or the action as auditable. The code generated for
+// auditable actions emits an audit event after each call with the caller identity taken from the
+// security claims, a digest of the request payload and the outcome of the call. The events are
+// sent to the sink set in middleware.DefaultAuditSink:
+//
+//    var _ = Resource("bottle", func() {
+//        Action("delete", func() {
+//            Audit()
+//            Routing(DELETE("/:id"))
+//            Response(NoContent)
+//        })
+//    })
+func Audit() {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Audited = true
+	case *design.ActionDefinition:
+		def.Audited = true
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
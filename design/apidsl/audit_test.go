@@ -0,0 +1,47 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("marks the actions as audited", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+			Action("delete", func() {
+				Routing(DELETE("/:id"))
+				Audit()
+				Response(NoContent)
+			})
+		})
+		Resource("account", func() {
+			Audit()
+			Action("update", func() {
+				Routing(PUT("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].Audited).Should(BeFalse())
+		Ω(Design.Resources["bottle"].Actions["delete"].Audited).Should(BeTrue())
+		Ω(Design.Resources["account"].Actions["update"].Audited).Should(BeTrue())
+	})
+
+	It("cannot be used in the API", func() {
+		API("audited", func() {
+			Audit()
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,53 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// MaxConcurrency can be used in: Resource, Action
+//
+// MaxConcurrency limits the number of requests handled concurrently by the action or by each
+// action of the resource. The optional timeout is the maximum duration the requests that exceed
+// the limit wait for a slot, they are rejected right away by default.
+//
+// The generated code wraps the action handlers with a middleware that responds with status code
+// 503 to the requests that cannot get a slot in time, protecting the expensive endpoints without
+// requiring a proxy or a rate limiter in front of the service:
+//
+//    var _ = Resource("report", func() {
+//        Action("export", func() {
+//            MaxConcurrency(4, 2*time.Second)
+//        })
+//    })
+func MaxConcurrency(n int, timeout ...time.Duration) {
+	if len(timeout) > 1 {
+		dslengine.ReportError("too many arguments given to MaxConcurrency")
+		return
+	}
+	var set func(*design.ConcurrencyDefinition)
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		set = func(c *design.ConcurrencyDefinition) { def.Concurrency = c }
+	case *design.ActionDefinition:
+		set = func(c *design.ConcurrencyDefinition) { def.Concurrency = c }
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	if n < 1 {
+		dslengine.ReportError("invalid concurrency limit %d, must be at least 1", n)
+		return
+	}
+	c := &design.ConcurrencyDefinition{Limit: n}
+	if len(timeout) > 0 {
+		if timeout[0] < 0 {
+			dslengine.ReportError("invalid concurrency timeout %s, must be positive", timeout[0])
+			return
+		}
+		c.Timeout = timeout[0]
+	}
+	set(c)
+}
@@ -0,0 +1,50 @@
+package apidsl_test
+
+import (
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MaxConcurrency", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("limits the concurrency of the resource actions", func() {
+		Resource("report", func() {
+			MaxConcurrency(4, 2*time.Second)
+			Action("export", func() {
+				Routing(GET("/export"))
+				Response(NoContent)
+			})
+			Action("show", func() {
+				Routing(GET("/:id"))
+				MaxConcurrency(16)
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		actions := Design.Resources["report"].Actions
+		Ω(actions["export"].Concurrency).Should(Equal(&ConcurrencyDefinition{Limit: 4, Timeout: 2 * time.Second}))
+		Ω(actions["show"].Concurrency).Should(Equal(&ConcurrencyDefinition{Limit: 16}))
+	})
+
+	It("rejects invalid limits", func() {
+		Resource("report", func() {
+			MaxConcurrency(0)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects negative timeouts", func() {
+		Resource("report", func() {
+			MaxConcurrency(1, -time.Second)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
@@ -62,6 +62,16 @@ func docsDefinition() (*design.DocsDefinition, bool) {
 	return a, ok
 }
 
+// errorEnvelopeDefinition returns true and current context if it is an ErrorEnvelopeDefinition,
+// nil and false otherwise.
+func errorEnvelopeDefinition() (*design.ErrorEnvelopeDefinition, bool) {
+	e, ok := dslengine.CurrentDefinition().(*design.ErrorEnvelopeDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return e, ok
+}
+
 // mediaTypeDefinition returns true and current context if it is a MediaTypeDefinition,
 // nil and false otherwise.
 func mediaTypeDefinition() (*design.MediaTypeDefinition, bool) {
@@ -122,6 +132,16 @@ func actionDefinition() (*design.ActionDefinition, bool) {
 	return a, ok
 }
 
+// routeDefinition returns true and current context if it is a RouteDefinition,
+// nil and false otherwise.
+func routeDefinition() (*design.RouteDefinition, bool) {
+	r, ok := dslengine.CurrentDefinition().(*design.RouteDefinition)
+	if !ok {
+		dslengine.IncompatibleDSL()
+	}
+	return r, ok
+}
+
 // responseDefinition returns true and current context if it is a ResponseDefinition,
 // nil and false otherwise.
 func responseDefinition() (*design.ResponseDefinition, bool) {
@@ -0,0 +1,140 @@
+package apidsl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+const (
+	// precisionMetadata is the metadata key that records the precision of a decimal
+	// attribute until its DSL has run.
+	precisionMetadata = "decimal:precision"
+	// scaleMetadata is the metadata key that records the scale of a decimal attribute until
+	// its DSL has run.
+	scaleMetadata = "decimal:scale"
+)
+
+// Precision can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// Precision sets the maximum number of digits of a Decimal attribute, the digits of the
+// fractional part included. The generated code validates the values with a pattern, see Scale:
+//
+//	Attribute("amount", Decimal, func() {
+//		Precision(12) // at most 10 digits in the integer part
+//		Scale(2)      // and 2 in the fractional part
+//	})
+func Precision(digits int) {
+	if a, ok := decimalDefinition("precision"); ok {
+		if digits < 1 {
+			dslengine.ReportError("invalid precision %d, must be at least 1", digits)
+			return
+		}
+		a.Metadata[precisionMetadata] = []string{strconv.Itoa(digits)}
+	}
+}
+
+// Scale can be used in: Attribute, Header, Param, HashOf, ArrayOf
+//
+// Scale sets the maximum number of digits of the fractional part of a Decimal attribute. A
+// scale of 0 only accepts integers. The scale cannot exceed the precision of the attribute, see
+// Precision.
+func Scale(digits int) {
+	if a, ok := decimalDefinition("scale"); ok {
+		if digits < 0 {
+			dslengine.ReportError("invalid scale %d, must be positive", digits)
+			return
+		}
+		a.Metadata[scaleMetadata] = []string{strconv.Itoa(digits)}
+	}
+}
+
+// DecimalType can be used in: API
+//
+// DecimalType sets the Go type of the struct fields generated for the Decimal attributes of the
+// user types, media types and payloads. The optional second argument is the import path of the
+// package that defines the type. The type must encode to and decode from a JSON string, the
+// generated code does not validate the values of the fields as the decoding takes care of it.
+// The fields are strings by default, the fields of the attributes with a default value as well as
+// the parameters and headers are always strings:
+//
+//	API("billing", func() {
+//		DecimalType("decimal.Decimal", "github.com/shopspring/decimal")
+//	})
+func DecimalType(typeName string, importPath ...string) {
+	if a, ok := apiDefinition(); ok {
+		if len(importPath) > 1 {
+			dslengine.ReportError("too many arguments given to DecimalType")
+			return
+		}
+		a.DecimalType = append([]string{typeName}, importPath...)
+	}
+}
+
+// decimalDefinition returns the current attribute definition if it is a Decimal attribute and
+// reports an error mentioning the given validation otherwise.
+func decimalDefinition(validation string) (*design.AttributeDefinition, bool) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return nil, false
+	}
+	if a.Type != design.Decimal {
+		typeName := "unknown"
+		if a.Type != nil {
+			typeName = a.Type.Name()
+		}
+		incompatibleAttributeType(validation, typeName, "a decimal")
+		return nil, false
+	}
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	return a, true
+}
+
+// decimalAttribute turns the given attribute into a string attribute with the "decimal" format
+// if it was defined with the Decimal type. The precision and scale of the attribute if any are
+// enforced with a pattern validation.
+func decimalAttribute(att *design.AttributeDefinition) {
+	if att.Type != design.Decimal {
+		return
+	}
+	precision, hasPrecision := decimalDigits(att, precisionMetadata)
+	scale, hasScale := decimalDigits(att, scaleMetadata)
+	att.Type = design.String
+	if att.Validation == nil {
+		att.Validation = &dslengine.ValidationDefinition{}
+	}
+	att.Validation.Format = "decimal"
+	switch {
+	case hasPrecision && scale > precision:
+		dslengine.ReportError("scale %d cannot exceed precision %d", scale, precision)
+	case hasPrecision && scale == precision:
+		att.Validation.Pattern = fmt.Sprintf(`^-?0(\.[0-9]{1,%d})?$`, scale)
+	case hasPrecision && scale == 0:
+		att.Validation.Pattern = fmt.Sprintf(`^-?[0-9]{1,%d}$`, precision)
+	case hasPrecision:
+		att.Validation.Pattern = fmt.Sprintf(`^-?[0-9]{1,%d}(\.[0-9]{1,%d})?$`, precision-scale, scale)
+	case hasScale && scale == 0:
+		att.Validation.Pattern = `^-?[0-9]+$`
+	case hasScale:
+		att.Validation.Pattern = fmt.Sprintf(`^-?[0-9]+(\.[0-9]{1,%d})?$`, scale)
+	}
+}
+
+// decimalDigits returns the number of digits recorded under the given metadata key and removes
+// the key from the attribute metadata.
+func decimalDigits(att *design.AttributeDefinition, key string) (int, bool) {
+	val, ok := att.Metadata[key]
+	if !ok {
+		return 0, false
+	}
+	delete(att.Metadata, key)
+	if len(att.Metadata) == 0 {
+		att.Metadata = nil
+	}
+	digits, _ := strconv.Atoi(val[0])
+	return digits, true
+}
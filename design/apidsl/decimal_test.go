@@ -0,0 +1,119 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decimal", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("defines string attributes with the decimal format", func() {
+		Type("invoice", func() {
+			Attribute("total", Decimal, func() {
+				Precision(12)
+				Scale(2)
+			})
+			Attribute("rate", Decimal, func() {
+				Scale(4)
+			})
+			Attribute("count", Decimal, func() {
+				Precision(6)
+			})
+			Attribute("amount", Decimal)
+			Attribute("lines", ArrayOf(Decimal))
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		o := Design.Types["invoice"].Type.ToObject()
+		total := o["total"]
+		Ω(total.Type).Should(Equal(String))
+		Ω(total.Validation.Format).Should(Equal("decimal"))
+		Ω(total.Validation.Pattern).Should(Equal(`^-?[0-9]{1,10}(\.[0-9]{1,2})?$`))
+		Ω(total.Metadata).Should(BeEmpty())
+		Ω(o["rate"].Validation.Pattern).Should(Equal(`^-?[0-9]+(\.[0-9]{1,4})?$`))
+		Ω(o["count"].Validation.Pattern).Should(Equal(`^-?[0-9]{1,6}$`))
+		Ω(o["amount"].Validation.Format).Should(Equal("decimal"))
+		Ω(o["amount"].Validation.Pattern).Should(BeEmpty())
+		Ω(o["lines"].Type.ToArray().ElemType.IsDecimal()).Should(BeTrue())
+	})
+
+	It("accepts string defaults", func() {
+		Type("invoice", func() {
+			Attribute("total", Decimal, func() {
+				Default("0.00")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Types["invoice"].Type.ToObject()["total"].DefaultValue).Should(Equal("0.00"))
+	})
+
+	It("rejects float defaults", func() {
+		Type("invoice", func() {
+			Attribute("total", Decimal, func() {
+				Default(0.1)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects a scale greater than the precision", func() {
+		Type("invoice", func() {
+			Attribute("total", Decimal, func() {
+				Precision(2)
+				Scale(4)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects precisions on non decimal attributes", func() {
+		Type("invoice", func() {
+			Attribute("total", Number, func() {
+				Precision(2)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	Context("with a Go decimal type", func() {
+		BeforeEach(func() {
+			API("billing", func() {
+				DecimalType("decimal.Decimal", "github.com/shopspring/decimal")
+			})
+		})
+
+		It("sets the type of the struct fields", func() {
+			Type("invoice", func() {
+				Attribute("total", Decimal)
+				Attribute("discount", Decimal, func() {
+					Default("0")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			o := Design.Types["invoice"].Type.ToObject()
+			Ω(o["total"].Metadata["struct:field:type"]).Should(Equal([]string{"decimal.Decimal", "github.com/shopspring/decimal"}))
+			Ω(o["discount"].Metadata).ShouldNot(HaveKey("struct:field:type"))
+		})
+
+		It("keeps the params strings", func() {
+			Resource("invoice", func() {
+				Action("list", func() {
+					Routing(GET(""))
+					Params(func() {
+						Param("min", Decimal)
+					})
+					Response(NoContent)
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			min := Design.Resources["invoice"].Actions["list"].Params.Type.ToObject()["min"]
+			Ω(min.IsDecimal()).Should(BeTrue())
+			Ω(min.Metadata).ShouldNot(HaveKey("struct:field:type"))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Deprecated can be used in: Resource, Action
+//
+// Deprecated marks the actions of the resource or the action as deprecated since the given date
+// and optionally schedules their retirement on the sunset date. The dates use the YYYY-MM-DD or
+// the RFC 3339 format.
+//
+// The generated code sets the Deprecation header (RFC 9745) and, when a sunset date is given,
+// the Sunset header (RFC 8594) of the responses. The generated CLI prints a warning before
+// sending the requests and the Swagger specification marks the operations as deprecated and
+// documents the dates with the x-deprecated-since and x-sunset extensions:
+//
+//    var _ = Resource("bottle", func() {
+//        Action("legacy", func() {
+//            Deprecated("2026-01-15", "2026-07-01")
+//        })
+//    })
+func Deprecated(since string, sunset ...string) {
+	if len(sunset) > 1 {
+		dslengine.ReportError("too many arguments given to Deprecated")
+		return
+	}
+	var set func(*design.DeprecationDefinition)
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		set = func(d *design.DeprecationDefinition) { def.Deprecation = d }
+	case *design.ActionDefinition:
+		set = func(d *design.DeprecationDefinition) { def.Deprecation = d }
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	d := &design.DeprecationDefinition{}
+	var err error
+	if d.Since, err = parseDate(since); err != nil {
+		dslengine.ReportError("invalid deprecation date %#v: %s", since, err)
+		return
+	}
+	if len(sunset) > 0 {
+		if d.Sunset, err = parseDate(sunset[0]); err != nil {
+			dslengine.ReportError("invalid sunset date %#v: %s", sunset[0], err)
+			return
+		}
+	}
+	set(d)
+}
+
+// parseDate parses a date using the YYYY-MM-DD or the RFC 3339 format.
+func parseDate(date string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, date)
+}
@@ -0,0 +1,58 @@
+package apidsl_test
+
+import (
+	"time"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deprecated", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("deprecates the resource actions", func() {
+		Resource("bottle", func() {
+			Deprecated("2026-01-15", "2026-07-01T12:00:00Z")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		d := Design.Resources["bottle"].Actions["show"].Deprecation
+		Ω(d).ShouldNot(BeNil())
+		Ω(d.Since).Should(Equal(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)))
+		Ω(d.Sunset).Should(Equal(time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)))
+	})
+
+	It("does not require a sunset date", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Deprecated("2026-01-15")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].Deprecation.Sunset.IsZero()).Should(BeTrue())
+	})
+
+	It("rejects invalid dates", func() {
+		Resource("bottle", func() {
+			Deprecated("January 15")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects a sunset before the deprecation", func() {
+		Resource("bottle", func() {
+			Deprecated("2026-07-01", "2026-01-15")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
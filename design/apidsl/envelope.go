@@ -0,0 +1,72 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// ErrorEnvelope can be used in: API
+//
+// ErrorEnvelope defines the layout of the JSON error responses written by the service. The
+// default layout is the one of goa.ErrorResponse: an object with the "id", "code", "status",
+// "detail", "meta" and "fields" fields. The DSL may wrap the error object in a field of the
+// response with WrapError, rename or omit fields with ErrorField and add fields with constant
+// values with ErrorConstant:
+//
+//	var _ = API("cellar", func() {
+//		ErrorEnvelope(func() {
+//			WrapError("error")               // {"error":{...}}
+//			ErrorField("detail", "message")  // "message" instead of "detail"
+//			ErrorField("id", "-")            // no "id" field
+//			ErrorConstant("api_version", "2")
+//		})
+//	})
+//
+// The generated initService function sets the service error encoder with the corresponding
+// goa.ErrorEnvelope unless the service already has one.
+func ErrorEnvelope(dsl func()) {
+	a, ok := apiDefinition()
+	if !ok {
+		return
+	}
+	env := &design.ErrorEnvelopeDefinition{}
+	if !dslengine.Execute(dsl, env) {
+		return
+	}
+	a.ErrorEnvelope = env
+}
+
+// WrapError can be used in: ErrorEnvelope
+//
+// WrapError sets the name of the response field that contains the error object.
+func WrapError(name string) {
+	if e, ok := errorEnvelopeDefinition(); ok {
+		e.Wrap = name
+	}
+}
+
+// ErrorField can be used in: ErrorEnvelope
+//
+// ErrorField sets the name used in the error object for the goa error field with the given name,
+// one of "id", "code", "status", "detail", "meta" or "fields". The name "-" omits the field.
+func ErrorField(name, key string) {
+	if e, ok := errorEnvelopeDefinition(); ok {
+		if e.Fields == nil {
+			e.Fields = make(map[string]string)
+		}
+		e.Fields[name] = key
+	}
+}
+
+// ErrorConstant can be used in: ErrorEnvelope
+//
+// ErrorConstant adds a field with the given constant value to the error object. The value must
+// be a string, a boolean or a number.
+func ErrorConstant(key string, value interface{}) {
+	if e, ok := errorEnvelopeDefinition(); ok {
+		if e.Static == nil {
+			e.Static = make(map[string]interface{})
+		}
+		e.Static[key] = value
+	}
+}
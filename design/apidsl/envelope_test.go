@@ -0,0 +1,84 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorEnvelope", func() {
+	var dsl func()
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		dsl = nil
+	})
+
+	JustBeforeEach(func() {
+		API("test", func() {
+			ErrorEnvelope(dsl)
+		})
+		dslengine.Run()
+	})
+
+	Context("with a valid envelope", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				WrapError("error")
+				ErrorField("detail", "message")
+				ErrorField("id", "-")
+				ErrorConstant("api_version", "2")
+			}
+		})
+
+		It("sets the API error envelope", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			env := Design.ErrorEnvelope
+			Ω(env).ShouldNot(BeNil())
+			Ω(env.Wrap).Should(Equal("error"))
+			Ω(env.Fields).Should(Equal(map[string]string{"detail": "message", "id": "-"}))
+			Ω(env.Static).Should(Equal(map[string]interface{}{"api_version": "2"}))
+		})
+	})
+
+	Context("with an unknown field", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				ErrorField("message", "msg")
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`invalid error field "message"`))
+		})
+	})
+
+	Context("with conflicting field names", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				ErrorField("detail", "code")
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`both use the name "code"`))
+		})
+	})
+
+	Context("with a static field conflicting with an error field", func() {
+		BeforeEach(func() {
+			dsl = func() {
+				ErrorConstant("status", "failed")
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`static error field "status" conflicts with error field "status"`))
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Flag can be used in: Resource, Action
+//
+// Flag gates the actions of the resource or the action behind the runtime feature flag with the
+// given name. The generated code asks the flag provider set in middleware.DefaultFlagProvider
+// whether the flag is enabled before handling each request, the requests made while it is
+// disabled get a 404 response as if the endpoint did not exist. The optional status can be set
+// to 403 to forbid the access instead. The route table lists the flag of each route:
+//
+//    var _ = Resource("bottle", func() {
+//        Action("rate", func() {
+//            Flag("bottle-ratings")
+//        })
+//        Action("share", func() {
+//            Flag("bottle-sharing", 403)
+//        })
+//    })
+func Flag(name string, status ...int) {
+	if len(status) > 1 {
+		dslengine.ReportError("too many arguments given to Flag")
+		return
+	}
+	f := &design.FlagDefinition{Name: name, Status: 404}
+	if len(status) > 0 {
+		f.Status = status[0]
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Flag = f
+	case *design.ActionDefinition:
+		def.Flag = f
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
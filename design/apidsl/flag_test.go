@@ -0,0 +1,56 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Flag", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("gates the resource actions", func() {
+		Resource("bottle", func() {
+			Flag("bottles")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		f := Design.Resources["bottle"].Actions["show"].Flag
+		Ω(f).ShouldNot(BeNil())
+		Ω(f.Name).Should(Equal("bottles"))
+		Ω(f.Status).Should(Equal(404))
+	})
+
+	It("sets the status of the disabled action responses", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Flag("bottles", 403)
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].Flag.Status).Should(Equal(403))
+	})
+
+	It("rejects other statuses", func() {
+		Resource("bottle", func() {
+			Flag("bottles", 500)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects empty names", func() {
+		Resource("bottle", func() {
+			Flag("")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
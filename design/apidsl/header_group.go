@@ -0,0 +1,64 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// HeaderGroup can be used in: Action, Response
+//
+// HeaderGroup maps a group of related headers to a user type. Each attribute of the type is read
+// from or written to the header whose name is the given prefix followed by the attribute name
+// (or the name set with Key). The attributes must be booleans, integers, numbers, strings or
+// date times.
+//
+// When used in an action the generated context exposes the decoded request headers in a field
+// named after the group. When used in a response the generated context defines a method that
+// sets the response headers from a value of the type. The generated app and client packages
+// also include Get<Group>Headers and Set<Group>Headers functions that map the headers to and
+// from the type. Example:
+//
+//    var RateLimitInfo = Type("RateLimitInfo", func() {
+//        Attribute("limit", Integer)
+//        Attribute("remaining", Integer)
+//        Attribute("reset", DateTime)
+//        Required("limit", "remaining")
+//    })
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        Response(OK, func() {
+//            HeaderGroup("rate_limit", "X-RateLimit-", RateLimitInfo) // X-Ratelimit-Limit, ...
+//        })
+//    })
+//
+// The groups that share the same name across the API must use the same prefix and type.
+func HeaderGroup(name, prefix string, ut interface{}) {
+	var t *design.UserTypeDefinition
+	switch actual := ut.(type) {
+	case *design.UserTypeDefinition:
+		t = actual
+	case *design.MediaTypeDefinition:
+		t = actual.UserTypeDefinition
+	case string:
+		var ok bool
+		if t, ok = design.Design.Types[actual]; !ok {
+			dslengine.ReportError("unknown header group type %s", actual)
+			return
+		}
+	default:
+		dslengine.ReportError("invalid header group type %#v, must be a user type", ut)
+		return
+	}
+	g := &design.HeaderGroupDefinition{Name: name, Prefix: prefix, Type: t}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		g.Parent = def
+		def.HeaderGroups = append(def.HeaderGroups, g)
+	case *design.ResponseDefinition:
+		g.Parent = def
+		def.HeaderGroups = append(def.HeaderGroups, g)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
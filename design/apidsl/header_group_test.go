@@ -0,0 +1,101 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HeaderGroup", func() {
+	var rateLimit *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		rateLimit = Type("RateLimitInfo", func() {
+			Attribute("limit", Integer)
+			Attribute("remaining", Integer)
+			Required("limit")
+		})
+	})
+
+	It("maps the request and response headers to the type", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				HeaderGroup("quota", "X-Quota-", "RateLimitInfo")
+				Response(OK, func() {
+					HeaderGroup("rate_limit", "X-RateLimit-", rateLimit)
+				})
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		show := Design.Resources["bottle"].Actions["show"]
+		Ω(show.HeaderGroups).Should(HaveLen(1))
+		Ω(show.HeaderGroups[0].Type).Should(Equal(rateLimit))
+		Ω(show.HeaderGroups[0].HeaderKey("limit")).Should(Equal("X-Quota-Limit"))
+		groups := show.Responses["OK"].HeaderGroups
+		Ω(groups).Should(HaveLen(1))
+		Ω(groups[0].Name).Should(Equal("rate_limit"))
+		Ω(groups[0].HeaderKey("remaining")).Should(Equal("X-Ratelimit-Remaining"))
+		Ω(Design.HeaderGroups()).Should(HaveLen(2))
+	})
+
+	It("rejects groups defining headers that are already defined", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Headers(func() {
+					Header("X-Quota-Limit", Integer)
+				})
+				HeaderGroup("quota", "X-Quota-", rateLimit)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("header X-Quota-Limit is also defined by the headers"))
+	})
+
+	It("rejects types with non primitive attributes", func() {
+		Type("Limits", func() {
+			Attribute("values", ArrayOf(Integer))
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				HeaderGroup("limits", "X-Limits-", "Limits")
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("header group attributes must be booleans"))
+	})
+
+	It("rejects groups with the same name and a different prefix", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				HeaderGroup("quota", "X-Quota-", rateLimit)
+				Response(NoContent)
+			})
+			Action("list", func() {
+				Routing(GET(""))
+				HeaderGroup("quota", "X-Limit-", rateLimit)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("is defined with a different prefix or type"))
+	})
+
+	It("cannot be used in a resource", func() {
+		Resource("bottle", func() {
+			HeaderGroup("quota", "X-Quota-", rateLimit)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,91 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// LogRequests can be used in: API, Resource, Action
+//
+// LogRequests enables the request logging middleware generated for the actions of the API, of the
+// resource or for the action. The middleware writes one structured log entry per request with the
+// route, the response status, the latency and the request and response body sizes. The values of
+// the params marked as Sensitive are omitted from the logs. The optional argument sets the
+// percentage of the requests that are logged, 100 by default. Resource and action level
+// LogRequests calls override the sampling rate of the parent, a rate of 0 disables the logs:
+//
+//    var _ = API("cellar", func() {
+//        LogRequests(10) // Log 10% of the requests
+//    })
+//
+//    var _ = Resource("bottle", func() {
+//        Action("create", func() {
+//            LogRequests() // Log all the requests
+//        })
+//        Action("health", func() {
+//            LogRequests(0) // Do not log health checks
+//        })
+//    })
+//
+// The log entries are written with the service logger, use one of the adapters of the logging
+// package such as goaslog to produce structured logs.
+func LogRequests(percent ...int) {
+	if len(percent) > 1 {
+		dslengine.ReportError("too many arguments given to LogRequests")
+		return
+	}
+	rate := 100
+	if len(percent) == 1 {
+		rate = percent[0]
+	}
+	if rate < 0 || rate > 100 {
+		dslengine.ReportError("invalid request log sampling rate %d, must be between 0 and 100", rate)
+		return
+	}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.LogSampling = &rate
+	case *design.ResourceDefinition:
+		def.LogSampling = &rate
+	case *design.ActionDefinition:
+		def.LogSampling = &rate
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// ClientClosedStatus can be used in: API, Resource, Action
+//
+// ClientClosedStatus chooses how the requests whose client closed the connection before the
+// response was written are reported. By default the generated handlers stop encoding the response
+// and the error returned by the controller is logged as an uncaught error. With
+// ClientClosedStatus the requests are logged with the 499 (client closed request) status
+// popularized by nginx and the error is logged at the info level. The optional argument disables
+// the behavior defined by the parent when false:
+//
+//    var _ = API("cellar", func() {
+//        ClientClosedStatus()
+//    })
+//
+//    var _ = Resource("bottle", func() {
+//        Action("export", func() {
+//            ClientClosedStatus(false) // Report canceled exports as errors
+//        })
+//    })
+func ClientClosedStatus(enabled ...bool) {
+	if len(enabled) > 1 {
+		dslengine.ReportError("too many arguments given to ClientClosedStatus")
+		return
+	}
+	val := len(enabled) == 0 || enabled[0]
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition:
+		def.ClientClosedStatus = &val
+	case *design.ResourceDefinition:
+		def.ClientClosedStatus = &val
+	case *design.ActionDefinition:
+		def.ClientClosedStatus = &val
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -0,0 +1,99 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogRequests", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("inherits the sampling rate of the API and resource", func() {
+		API("logged", func() {
+			LogRequests(10)
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				LogRequests()
+				Response(NoContent)
+			})
+		})
+		Resource("health", func() {
+			LogRequests(0)
+			Action("check", func() {
+				Routing(GET("/health"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(*Design.Resources["bottle"].Actions["show"].LogSampling).Should(Equal(10))
+		Ω(*Design.Resources["bottle"].Actions["create"].LogSampling).Should(Equal(100))
+		Ω(*Design.Resources["health"].Actions["check"].LogSampling).Should(Equal(0))
+	})
+
+	It("does not log requests by default", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].LogSampling).Should(BeNil())
+	})
+
+	It("rejects invalid sampling rates", func() {
+		API("logged", func() {
+			LogRequests(120)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+		Ω(dslengine.Errors.Error()).Should(ContainSubstring("invalid request log sampling rate 120"))
+	})
+})
+
+var _ = Describe("ClientClosedStatus", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("inherits the setting of the API and resource", func() {
+		API("logged", func() {
+			ClientClosedStatus()
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+			Action("export", func() {
+				Routing(GET("/export"))
+				ClientClosedStatus(false)
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(*Design.Resources["bottle"].Actions["show"].ClientClosedStatus).Should(BeTrue())
+		Ω(*Design.Resources["bottle"].Actions["export"].ClientClosedStatus).Should(BeFalse())
+	})
+
+	It("is not set by default", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].ClientClosedStatus).Should(BeNil())
+	})
+})
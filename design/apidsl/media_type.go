@@ -219,6 +219,29 @@ func ContentType(typ string) {
 	}
 }
 
+// Diff can be used in: MediaType
+//
+// Diff generates a function for each view of the media type that returns the paths of the fields
+// whose values differ between two instances. The paths use the attribute keys, nested fields are
+// separated with dots and array indices or hash keys are enclosed in brackets, e.g. "items[0].sku".
+// This is useful to record audit trails or to compute what an update actually modified:
+//
+//	var BottleMedia = MediaType("application/vnd.goa.example.bottle", func() {
+//		Diff()	// Generates DiffGoaExampleBottle(a, b *GoaExampleBottle) []string
+//		Attributes(func() {
+//			Attribute("name", String)
+//		})
+//		View("default", func() {
+//			Attribute("name")
+//		})
+//	})
+//
+func Diff() {
+	if mt, ok := mediaTypeDefinition(); ok {
+		mt.Diff = true
+	}
+}
+
 // View can be used in: MediaType, Response
 //
 // View adds a new view to a media type. A view has a name and lists attributes that are
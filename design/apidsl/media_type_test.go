@@ -89,6 +89,27 @@ var _ = Describe("MediaType", func() {
 		})
 	})
 
+	Context("with diff functions", func() {
+		const attName = "att"
+
+		BeforeEach(func() {
+			name = "application/foo"
+			dslFunc = func() {
+				Diff()
+				Attributes(func() {
+					Attribute(attName)
+				})
+				View("default", func() { Attribute(attName) })
+			}
+		})
+
+		It("enables the generation of the diff functions", func() {
+			Ω(mt).ShouldNot(BeNil())
+			Ω(mt.Validate()).ShouldNot(HaveOccurred())
+			Ω(mt.Diff).Should(BeTrue())
+		})
+	})
+
 	Context("with a description", func() {
 		const description = "desc"
 
@@ -408,6 +429,33 @@ var _ = Describe("Example", func() {
 			Ω(attr.Example).Should(Equal(0))
 		})
 
+		It("uses the example generators", func() {
+			mt := MediaType("application/vnd.example+json", func() {
+				Attributes(func() {
+					Attribute("name", String, func() {
+						ExampleGenerator(func(r *RandomGenerator) interface{} {
+							return "generated"
+						})
+					})
+					Attribute("explicit", String, func() {
+						Example("explicit")
+						ExampleGenerator(func(r *RandomGenerator) interface{} {
+							return "generated"
+						})
+					})
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+
+			Ω(mt.Type.ToObject()["name"].Example).Should(Equal("generated"))
+			Ω(mt.Type.ToObject()["explicit"].Example).Should(Equal("explicit"))
+		})
+
 		It("produces a media type with HashOf examples", func() {
 			ut := Type("example", func() {
 				Attribute("test1", Integer)
@@ -27,6 +27,31 @@ import (
 //        Metadata("struct:field:type", "json.RawMessage", "encoding/json")
 //        Metadata("struct:field:type", "mypackage.MyType", "github.com/me/mypackage")
 //
+// `struct:field:value`: generates the field of a required boolean, integer, number, string or
+// date time attribute as a value instead of a pointer in the private struct used to decode
+// request bodies. The field records whether the attribute is present in the body so that missing
+// attributes are still reported, this saves one allocation per field for large payloads.
+// Attributes with a default value or an enum validation are not affected. Applicable to
+// attributes and to types, in which case it applies to all the attributes of the type.
+//
+//        Metadata("struct:field:value")
+//
+// `struct:field:order`: sets the order of the fields of the generated Go structs. The fields are
+// generated in the order the attributes are defined in the design by default ("design"), "name"
+// sorts them by attribute name and "alignment" sorts them by decreasing alignment to minimize the
+// padding of the structs, the fields with the same alignment keep the design order.
+// Applicable to types, media types and API, in which case it applies to all the types.
+//
+//        Metadata("struct:field:order", "alignment")
+//
+// `struct:pkg:path`: sets the import path of the Go package that defines the type, typically the
+// package generated from a shared design imported by several APIs. goagen references the type
+// from that package instead of generating it. The second optional value sets the package name
+// used in the generated code and defaults to the last element of the import path.
+// Applicable to types defined with Type only.
+//
+//        Metadata("struct:pkg:path", "github.com/acme/shared/app", "shared")
+//
 // `struct:tag:xxx`: sets the struct field tag xxx on generated Go structs.  Overrides tags that
 // goagen would otherwise set.  If the metadata value is a slice then the strings are joined with
 // the space character as separator.
@@ -35,6 +60,15 @@ import (
 //        Metadata("struct:tag:json", "myName,omitempty")
 //        Metadata("struct:tag:xml", "myName,attr")
 //
+// `struct:tag:extra:xxx`: adds the struct field tag xxx to the tags generated by goagen or set
+// with `struct:tag:xxx` instead of replacing them. This makes it possible to reuse the generated
+// types with packages that rely on struct tags such as ORMs or validators. The metadata values
+// are joined with the comma character as separator.
+// Applicable to attributes only.
+//
+//        Metadata("struct:tag:extra:db", "my_name")
+//        Metadata("struct:tag:extra:bson", "myName", "omitempty")
+//
 // `swagger:generate`: specifies whether Swagger specification should be generated. Defaults to
 // true.
 // Applicable to resources, actions and file servers.
@@ -54,6 +88,41 @@ import (
 //        Metadata("swagger:tag:Backend:url", "http://example.com")
 //        Metadata("swagger:tag:Backend:url:desc", "See more docs here")
 //
+// `http:raw`: mounts the action handler without decoding the request body or limiting its length
+// so that the action reads the request and writes the response directly using the context
+// Request and ResponseWriter fields, e.g. to stream data or to upgrade the connection. The
+// ResponseWriter implements http.Flusher and http.Hijacker when the underlying writer does.
+// Actions using this key cannot define a payload.
+// Applicable to actions.
+//
+//        Metadata("http:raw")
+//
+// `http:body:lazy`: defers the decoding and validation of the request body until the middleware
+// has run so that middleware such as the security handlers may reject requests without reading
+// their bodies. The request data Payload field is thus not initialized when the middleware runs.
+// The body of the actions secured with a HMACSecurity scheme is always decoded lazily.
+// Applicable to resources and actions.
+//
+//        Metadata("http:body:lazy")
+//
+// `gateway:rate-limit`: sets the rate limits enforced by the API gateway configuration generated
+// with "goagen gateway". Each value has the form N/unit where unit is one of second, minute, hour,
+// day, month or year. Applicable to API (limits the whole API), resources and actions.
+//
+//        Metadata("gateway:rate-limit", "10/second", "1000/hour")
+//
+// `cli:group`: sets the group listing the commands in the generated CLI tool help, see Group.
+// Applicable to resources and actions.
+//
+//        Metadata("cli:group", "Cellar")
+//
+// `client:payload:flatten`: makes the generated client methods take the value of the single
+// primitive attribute of the action payload instead of the payload struct. The methods build the
+// payload from the value. Ignored for payloads that define more than one attribute.
+// Applicable to actions.
+//
+//        Metadata("client:payload:flatten")
+//
 // `swagger:extension:xxx`: sets the Swagger extensions xxx. It can have any valid JSON format value.
 // Applicable to
 // api as within the info and tag object,
@@ -61,12 +130,17 @@ import (
 // action as within the path-item object,
 // route as within the operation object,
 // param as within the parameter object,
-// response as within the response object
-// and security as within the security-scheme object.
+// response as within the response object,
+// security as within the security-scheme object
+// and type, media type and attribute as within the schema object.
 // See https://github.com/OAI/OpenAPI-Specification/blob/master/guidelines/EXTENSIONS.md.
 //
 //        Metadata("swagger:extension:x-api", `{"foo":"bar"}`)
 //
+// `openapi:extension:xxx`: same as `swagger:extension:xxx`.
+//
+//        Metadata("openapi:extension:x-amazon-apigateway-integration", `{"type":"http_proxy"}`)
+//
 // The special key names listed above may be used as follows:
 //
 //        var Account = Type("Account", func() {
@@ -111,3 +185,63 @@ func Metadata(name string, value ...string) {
 		dslengine.IncompatibleDSL()
 	}
 }
+
+// SwaggerTag can be used in: API, Resource, Action
+//
+// SwaggerTag associates the resource or action with a documentation tag. The Swagger generator
+// lists the tag in the operations of the resource or action and in the specification tags
+// together with its description and external documentation. SwaggerTag is a shorthand for the
+// "swagger:tag:xxx" metadata, not to be confused with the response Tag function:
+//
+//        Resource("bottle", func() {
+//                SwaggerTag("cellar", func() {
+//                        Description("Operations on the wine cellar")
+//                        Docs(func() {
+//                                Description("Cellar guide")
+//                                URL("https://example.com/cellar")
+//                        })
+//                })
+//        })
+func SwaggerTag(name string, dsl ...func()) {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *design.ResourceDefinition, *design.ActionDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	tag := &design.TagDefinition{Name: name}
+	if len(dsl) > 0 && !dslengine.Execute(dsl[0], tag) {
+		return
+	}
+	key := "swagger:tag:" + name
+	Metadata(key)
+	if tag.Description != "" {
+		Metadata(key+":desc", tag.Description)
+	}
+	if tag.Docs != nil {
+		if tag.Docs.URL != "" {
+			Metadata(key+":url", tag.Docs.URL)
+		}
+		if tag.Docs.Description != "" {
+			Metadata(key+":url:desc", tag.Docs.Description)
+		}
+	}
+}
+
+// Group can be used in: Resource, Action
+//
+// Group sets the name of the group that lists the resource or action commands in the help output
+// of the generated CLI tool. Actions inherit the group of their resource. Group is a shorthand for
+// the "cli:group" metadata:
+//
+//        Resource("bottle", func() {
+//                Group("Cellar")
+//        })
+func Group(name string) {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition, *design.ActionDefinition:
+		Metadata("cli:group", name)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -120,6 +120,46 @@ var _ = Describe("Metadata", func() {
 
 	})
 
+	Context("with SwaggerTag and Group declarations", func() {
+		JustBeforeEach(func() {
+			rd = Resource("Example Resource", func() {
+				SwaggerTag("cellar", func() {
+					Description("Cellar operations")
+					Docs(func() {
+						Description("Cellar guide")
+						URL("http://example.com/cellar")
+					})
+				})
+				Group("Cellar")
+				Action("Example Action", func() {
+					SwaggerTag("admin")
+					Group("Admin")
+					Routing(GET("/"))
+				})
+			})
+
+			dslengine.Run()
+		})
+
+		It("sets the resource metadata", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(rd.Metadata).To(Equal(dslengine.MetadataDefinition{
+				"swagger:tag:cellar":          nil,
+				"swagger:tag:cellar:desc":     {"Cellar operations"},
+				"swagger:tag:cellar:url":      {"http://example.com/cellar"},
+				"swagger:tag:cellar:url:desc": {"Cellar guide"},
+				"cli:group":                   {"Cellar"},
+			}))
+		})
+
+		It("sets the action metadata", func() {
+			Ω(rd.Actions["Example Action"].Metadata).To(Equal(dslengine.MetadataDefinition{
+				"swagger:tag:admin": nil,
+				"cli:group":         {"Admin"},
+			}))
+		})
+	})
+
 	Context("with no Metadata declaration", func() {
 		JustBeforeEach(func() {
 			api = API("Example API", func() {})
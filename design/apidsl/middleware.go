@@ -0,0 +1,39 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Use can be used in: Resource, Action
+//
+// Use applies the middleware with the given names to the actions of the resource or to the
+// action. The generated Mount function of the resource controller accepts the implementations
+// of the middleware used by the resource and its actions and applies them in the order they are
+// declared, the resource middleware first. The middleware run once the request passed the CORS,
+// feature flag and security checks. The route table lists the middleware of each route:
+//
+//    var _ = Resource("bottle", func() {
+//        Use("tracing")
+//        Action("rate", func() {
+//            Use("rateLimit", "audit")
+//        })
+//    })
+//
+// The application then mounts the controller with:
+//
+//    app.MountBottleController(service, ctrl, app.BottleMiddleware{
+//        Tracing:   tracing.New(),
+//        RateLimit: ratelimit.New(100),
+//        Audit:     audit.New(log),
+//    })
+func Use(names ...string) {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Middleware = append(def.Middleware, names...)
+	case *design.ActionDefinition:
+		def.Middleware = append(def.Middleware, names...)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -0,0 +1,54 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Use", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("applies the resource middleware before the action middleware", func() {
+		Resource("bottle", func() {
+			Use("tracing")
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Use("rateLimit", "tracing", "audit")
+				Response(NoContent)
+			})
+			Action("list", func() {
+				Routing(GET(""))
+				Use("cache")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		res := Design.Resources["bottle"]
+		Ω(res.Actions["show"].Middleware).Should(Equal([]string{"tracing", "rateLimit", "audit"}))
+		Ω(res.Actions["list"].Middleware).Should(Equal([]string{"tracing", "cache"}))
+		Ω(res.AllMiddleware()).Should(Equal([]string{"tracing", "cache", "rateLimit", "audit"}))
+	})
+
+	It("rejects invalid names", func() {
+		Resource("bottle", func() {
+			Use("rate limit")
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects duplicates", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Use("audit", "audit")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
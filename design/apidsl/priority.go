@@ -0,0 +1,65 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Priority can be used in: Resource, Action
+//
+// Priority binds the priority level of the requests made to the actions of the resource or to the
+// action to the given header. thresholds maps each priority level to the service load above which
+// the requests with that level are rejected, the load is a number between 0 and 1 so that a
+// threshold of 1 or more means the requests are never rejected. The header is a string whose
+// values are the priority levels, the optional DSL may describe it and set the level of the
+// requests that do not set the header with Default. The requests that set no level and no default
+// get the lowest level.
+//
+// The generated code wraps the action handlers with a middleware that reads the load from
+// middleware.DefaultLoadSignal and responds with status code 503 and a Retry-After header to the
+// requests whose level threshold is exceeded:
+//
+//    var _ = Resource("bottle", func() {
+//        Priority("X-Priority", map[string]float64{"low": 0.7, "normal": 0.9, "critical": 1}, func() {
+//            Default("normal")
+//        })
+//    })
+func Priority(header string, thresholds map[string]float64, dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Priority")
+		return
+	}
+	if header == "" {
+		dslengine.ReportError("priority header cannot be empty")
+		return
+	}
+	if len(thresholds) == 0 {
+		dslengine.ReportError("priority must define at least one level")
+		return
+	}
+	var set func(*design.PriorityDefinition)
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		set = func(p *design.PriorityDefinition) { def.Priority = p }
+	case *design.ActionDefinition:
+		set = func(p *design.PriorityDefinition) { def.Priority = p }
+	default:
+		dslengine.IncompatibleDSL()
+		return
+	}
+	p := &design.PriorityDefinition{Header: header, Thresholds: thresholds}
+	levels := p.PriorityLevels()
+	values := make([]interface{}, len(levels))
+	for i, l := range levels {
+		values[i] = l
+	}
+	p.Attribute = &design.AttributeDefinition{
+		Type:        design.String,
+		Description: "Priority level of the request",
+		Validation:  &dslengine.ValidationDefinition{Values: values},
+	}
+	if len(dsl) > 0 && !dslengine.Execute(dsl[0], p.Attribute) {
+		return
+	}
+	set(p)
+}
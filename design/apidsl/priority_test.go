@@ -0,0 +1,70 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priority", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("binds the priority of the resource actions to a header", func() {
+		Resource("bottle", func() {
+			Priority("X-Priority", map[string]float64{"low": 0.7, "normal": 0.9, "critical": 1}, func() {
+				Default("normal")
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		p := Design.Resources["bottle"].Actions["create"].Priority
+		Ω(p).ShouldNot(BeNil())
+		Ω(p.PriorityLevels()).Should(Equal([]string{"low", "normal", "critical"}))
+		Ω(p.DefaultLevel()).Should(Equal("normal"))
+		header := Design.Resources["bottle"].Actions["create"].Headers.Type.ToObject()["X-Priority"]
+		Ω(header).ShouldNot(BeNil())
+		Ω(header.Validation.Values).Should(Equal([]interface{}{"low", "normal", "critical"}))
+	})
+
+	It("defaults to the lowest level", func() {
+		Resource("bottle", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Priority("X-Priority", map[string]float64{"normal": 0.9, "low": 0.5})
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["create"].Priority.DefaultLevel()).Should(Equal("low"))
+	})
+
+	It("rejects a default that is not a level", func() {
+		Resource("bottle", func() {
+			Priority("X-Priority", map[string]float64{"low": 0.5}, func() {
+				Default("high")
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects negative thresholds", func() {
+		Resource("bottle", func() {
+			Priority("X-Priority", map[string]float64{"low": -1})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("cannot be used in the API", func() {
+		API("prioritized", func() {
+			Priority("X-Priority", map[string]float64{"low": 0.5})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
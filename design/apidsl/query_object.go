@@ -0,0 +1,53 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// QueryObject can be used in: Action
+//
+// QueryObject binds a user type to the query string of the action requests as a whole instead of
+// declaring each attribute with Param. Each attribute of the type is read from the parameter
+// whose name is the object name followed by the attribute name in brackets, nested objects and
+// hashes add one bracketed segment per level and arrays use repeated parameters (the OpenAPI
+// deepObject style). Example:
+//
+//    var BottleFilter = Type("BottleFilter", func() {
+//        Attribute("color", String)
+//        Attribute("vintage", Integer)
+//        Attribute("price", func() {
+//            Attribute("min", Number)
+//            Attribute("max", Number)
+//        })
+//    })
+//
+//    Action("list", func() {
+//        Routing(GET(""))
+//        QueryObject("filter", BottleFilter) // ?filter[color]=red&filter[price][min]=10
+//    })
+//
+// The generated context exposes the decoded and validated object in a field named after it, nil
+// if the request sets none of its parameters. The generated client method accepts the object as
+// its last argument and encodes it in the query string.
+func QueryObject(name string, ut interface{}) {
+	var t *design.UserTypeDefinition
+	switch actual := ut.(type) {
+	case *design.UserTypeDefinition:
+		t = actual
+	case *design.MediaTypeDefinition:
+		t = actual.UserTypeDefinition
+	case string:
+		var ok bool
+		if t, ok = design.Design.Types[actual]; !ok {
+			dslengine.ReportError("unknown query object type %s", actual)
+			return
+		}
+	default:
+		dslengine.ReportError("invalid query object type %#v, must be a user type", ut)
+		return
+	}
+	if a, ok := actionDefinition(); ok {
+		a.QueryObjects = append(a.QueryObjects, &design.QueryObjectDefinition{Name: name, Type: t, Parent: a})
+	}
+}
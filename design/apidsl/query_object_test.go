@@ -0,0 +1,90 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueryObject", func() {
+	var filter *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		filter = Type("BottleFilter", func() {
+			Attribute("color", String)
+			Attribute("price", func() {
+				Attribute("min", Number)
+				Attribute("max", Number)
+			})
+		})
+	})
+
+	It("binds the type to the query string", func() {
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				QueryObject("filter", filter)
+				QueryObject("paging", "BottleFilter")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		list := Design.Resources["bottle"].Actions["list"]
+		Ω(list.QueryObjects).Should(HaveLen(2))
+		Ω(list.QueryObjects[0].Name).Should(Equal("filter"))
+		Ω(list.QueryObjects[0].Type).Should(Equal(filter))
+		Ω(list.QueryObjects[1].Type).Should(Equal(filter))
+		Ω(list.QueryObjects[0].Parent).Should(Equal(list))
+	})
+
+	It("rejects objects clashing with params", func() {
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				Params(func() {
+					Param("filter", String)
+				})
+				QueryObject("filter", filter)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("query object name clashes with the param"))
+	})
+
+	It("rejects objects defined more than once", func() {
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				QueryObject("filter", filter)
+				QueryObject("filter", filter)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("query object is defined more than once"))
+	})
+
+	It("rejects unknown types", func() {
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET(""))
+				QueryObject("filter", "Unknown")
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("cannot be used in a resource", func() {
+		Resource("bottle", func() {
+			QueryObject("filter", filter)
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
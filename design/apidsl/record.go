@@ -0,0 +1,34 @@
+package apidsl
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Record can be used in: Resource, Action
+//
+// Record marks the requests made to the actions of the resource or to the action as recordable.
+// The code generated for recordable actions sends the request and the response of each call to
+// the sink set in middleware.DefaultRecordSink, nothing is recorded if the sink is not set. The
+// values of the params, headers and attributes marked as sensitive are redacted, see Sensitive.
+//
+// The generated client package includes a Replay method that re-issues the recorded requests
+// with the client signers, which helps reproducing the calls that led to a regression:
+//
+//    var _ = Resource("bottle", func() {
+//        Action("update", func() {
+//            Record()
+//            Routing(PATCH("/:id"))
+//            Response(NoContent)
+//        })
+//    })
+func Record() {
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ResourceDefinition:
+		def.Recorded = true
+	case *design.ActionDefinition:
+		def.Recorded = true
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
@@ -0,0 +1,48 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Record", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("marks the actions as recorded", func() {
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(NoContent)
+			})
+			Action("update", func() {
+				Routing(PATCH("/:id"))
+				Record()
+				Response(NoContent)
+			})
+		})
+		Resource("account", func() {
+			Record()
+			Action("update", func() {
+				Routing(PUT("/:id"))
+				Response(NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		Ω(Design.Resources["bottle"].Actions["show"].Recorded).Should(BeFalse())
+		Ω(Design.Resources["bottle"].Actions["update"].Recorded).Should(BeTrue())
+		Ω(Design.Resources["account"].Actions["update"].Recorded).Should(BeTrue())
+		Ω(Design.HasRecordedActions()).Should(BeTrue())
+	})
+
+	It("cannot be used in the API", func() {
+		API("recorded", func() {
+			Record()
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
@@ -127,3 +127,70 @@ func CanonicalActionName(a string) {
 		r.CanonicalActionName = a
 	}
 }
+
+// CSRF can be used in: Resource
+//
+// CSRF protects the resource actions against cross-site request forgery using the double submit
+// cookie technique. The generated code wraps the action handlers with the CSRF middleware of the
+// goa middleware package: the middleware issues a random token via the "csrf_token" cookie and
+// requires requests made with unsafe HTTP methods to echo the token in the "X-CSRF-Token" header.
+// The generated JavaScript and Go clients echo the token automatically.
+//
+// CSRF accepts an optional path relative to the resource base path. goagen mounts an endpoint
+// that issues new tokens on GET requests made to that path, for example:
+//
+//    Resource("account", func() {
+//        BasePath("/accounts")
+//        CSRF("/csrf")          // GET /accounts/csrf issues a new token
+//    })
+func CSRF(tokenPath ...string) {
+	if len(tokenPath) > 1 {
+		dslengine.ReportError("too many arguments, CSRF accepts at most one token path")
+		return
+	}
+	if r, ok := resourceDefinition(); ok {
+		r.CSRF = true
+		if len(tokenPath) > 0 {
+			r.CSRFTokenPath = tokenPath[0]
+		}
+	}
+}
+
+// Tenant can be used in: Resource
+//
+// Tenant binds the identifier of the tenant the requests are made on behalf of to the given path
+// parameter or header for all the resource actions. The identifier is read from the path
+// parameter if the action routes capture a wildcard with that name, from the header with that
+// name otherwise, in which case the header is required. The identifier is a string, the optional
+// DSL may describe and validate it. The generated action contexts store the identifier in their
+// context, controllers retrieve it with goa.ContextTenant:
+//
+//    Resource("bottle", func() {
+//        BasePath("/tenants/:tenantID/bottles")
+//        Tenant("tenantID")                     // Read from the path
+//    })
+//
+//    Resource("account", func() {
+//        Tenant("X-Tenant-ID", func() {         // Read from the X-Tenant-ID header
+//            Pattern("^[a-z0-9-]+$")
+//        })
+//    })
+func Tenant(name string, dsl ...func()) {
+	if len(dsl) > 1 {
+		dslengine.ReportError("too many arguments given to Tenant")
+		return
+	}
+	if name == "" {
+		dslengine.ReportError("tenant name cannot be empty")
+		return
+	}
+	r, ok := resourceDefinition()
+	if !ok {
+		return
+	}
+	att := &design.AttributeDefinition{Type: design.String, Description: "Tenant identifier"}
+	if len(dsl) > 0 && !dslengine.Execute(dsl[0], att) {
+		return
+	}
+	r.Tenant = &design.TenantDefinition{Name: name, Attribute: att}
+}
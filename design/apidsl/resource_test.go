@@ -133,6 +133,72 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Context("with CSRF protection", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				BasePath("/foos")
+				CSRF("/csrf")
+			}
+		})
+
+		It("enables CSRF protection and sets the token path", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+			Ω(res.CSRF).Should(BeTrue())
+			Ω(res.CSRFTokenPath).Should(Equal("/csrf"))
+			Ω(res.CSRFTokenFullPath()).Should(Equal("/foos/csrf"))
+		})
+	})
+
+	Context("with signed files", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Files("/downloads/*filepath", "/www/downloads", func() {
+					SignedURL()
+				})
+			}
+		})
+
+		It("requires signed URLs", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(res.FileServers).Should(HaveLen(1))
+			Ω(res.FileServers[0].Signed).Should(BeTrue())
+		})
+	})
+
+	Context("with a tenant", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Tenant("tenantID", func() {
+					Pattern("^[a-z]+$")
+				})
+				Action("list", func() {
+					Routing(GET("/tenants/:tenantID/foos"))
+					Response(NoContent)
+				})
+				Action("show", func() {
+					Routing(GET("/foos/:id"))
+					Response(NoContent)
+				})
+			}
+		})
+
+		It("binds the tenant identifier to the path params or headers of the actions", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			list := res.Actions["list"]
+			Ω(list.TenantInPath()).Should(BeTrue())
+			Ω(list.Params.Type.ToObject()).Should(HaveKey("tenantID"))
+			Ω(list.Params.Type.ToObject()["tenantID"].Validation.Pattern).Should(Equal("^[a-z]+$"))
+			show := res.Actions["show"]
+			Ω(show.TenantInPath()).Should(BeFalse())
+			Ω(show.Headers.Type.ToObject()).Should(HaveKey("tenantID"))
+			Ω(show.Headers.IsRequired("tenantID")).Should(BeTrue())
+		})
+	})
+
 	Context("with a canonical action that does not exist", func() {
 		const can = "can"
 
@@ -1,6 +1,8 @@
 package apidsl
 
 import (
+	"net/http"
+
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/dslengine"
 )
@@ -83,7 +85,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
+			if resp.Status == 200 && resp.MediaType == "" && !resp.NoBody {
 				resp.MediaType = def.Parent.MediaType
 				resp.ViewName = def.Parent.DefaultViewName
 			}
@@ -100,7 +102,7 @@ func Response(name string, paramsAndDSL ...interface{}) {
 			return
 		}
 		if resp := executeResponseDSL(name, paramsAndDSL...); resp != nil {
-			if resp.Status == 200 && resp.MediaType == "" {
+			if resp.Status == 200 && resp.MediaType == "" && !resp.NoBody {
 				resp.MediaType = def.MediaType
 				resp.ViewName = def.DefaultViewName
 			}
@@ -122,6 +124,201 @@ func Status(status int) {
 	}
 }
 
+// NoBody can be used in: Response
+//
+// NoBody indicates that the response has no body. The response does not inherit the default
+// media type of the resource so that a successful response may consist of headers only even
+// when the resource defines a media type:
+//
+//    Resource("bottle", func() {
+//        DefaultMedia(BottleMedia)
+//        Action("exists", func() {
+//            Routing(HEAD("/:id"))
+//            Response(OK, func() {
+//                NoBody()
+//                Headers(func() {
+//                    Header("ETag")
+//                })
+//            })
+//        })
+//    })
+//
+// goagen generates a context method that only writes the status and headers and clients that do
+// not attempt to decode the response body. Responses with status NoContent or NotModified never
+// have a body and do not need to use NoBody.
+func NoBody() {
+	if r, ok := responseDefinition(); ok {
+		r.NoBody = true
+		r.MediaType = ""
+		r.ViewName = ""
+	}
+}
+
+// Part can be used in: Response
+//
+// Part adds a part to a multipart/mixed response whose parts are written and flushed one at a
+// time, for example the results of a batch followed by binary content. The second argument is
+// either the type of the JSON encoded content of the part, a media type is rendered with its
+// default view, or the content type of a binary part:
+//
+//    Response(OK, func() {
+//        Part("bottle", BottleMedia)
+//        Part("label", "image/png")
+//    })
+//
+// goagen generates a context method that returns a writer with a method per part and a client
+// method that returns an iterator over the decoded parts.
+func Part(name string, typeOrMedia interface{}) {
+	r, ok := responseDefinition()
+	if !ok {
+		return
+	}
+	p := &design.PartDefinition{Name: name, Parent: r}
+	switch t := typeOrMedia.(type) {
+	case string:
+		p.MediaType = t
+	case design.DataType:
+		p.Type = t
+	default:
+		dslengine.ReportError("invalid part type %#v, must be a data type or a content type", typeOrMedia)
+		return
+	}
+	r.MediaType = "multipart/mixed"
+	r.ViewName = ""
+	r.Parts = append(r.Parts, p)
+}
+
+// Tag can be used in: Response
+//
+// Tag associates the response with a value of an attribute of the response media type. goagen
+// generates a Respond method on the action context which sends the response whose tag matches
+// the value of the attribute. This makes it possible for an action to send different success
+// responses for the same media type, for example:
+//
+//    Action("show", func() {
+//        Routing(GET("/:id"))
+//        Response(OK, Job, func() {
+//            Tag("state", "done")
+//        })
+//        Response(Accepted, Job, func() {
+//            Tag("state", "pending")
+//        })
+//        Response(PartialContent, Job) // Sent when state has any other value
+//    })
+//
+// The attribute must be a string. All the tagged responses of an action must use the same
+// attribute, media type and view. A response with the same media type and view but no tag is
+// sent if no tag matches.
+func Tag(name, value string) {
+	if r, ok := responseDefinition(); ok {
+		r.TagAttribute = name
+		r.TagValue = value
+	}
+}
+
+// Redirect can be used in: Response
+//
+// Redirect indicates that the response redirects the client to another URL. The response status
+// must be a 3xx status. goagen generates a context method that accepts the redirect target URL
+// and sets the Location header with it, for example:
+//
+//    Action("legacy", func() {
+//        Routing(GET("/legacy/:id"))
+//        Response(MovedPermanently, func() {
+//            Redirect()
+//        })
+//    })
+//
+// makes it possible for the controller to write:
+//
+//    return ctx.MovedPermanently("/bottles/" + ctx.ID)
+//
+// Use Location instead to set the Location header from an attribute of the response media type.
+func Redirect() {
+	if r, ok := responseDefinition(); ok {
+		r.Redirect = true
+		addLocationHeader(r)
+	}
+}
+
+// Location can be used in: Response
+//
+// Location sets the Location header of the response using the value of the given attribute of
+// the response media type. The response status must be Created or a 3xx status, for example:
+//
+//    Response(SeeOther, Job, func() {
+//        Location("href")
+//    })
+//
+// The attribute must be a string.
+func Location(name string) {
+	if r, ok := responseDefinition(); ok {
+		r.LocationAttribute = name
+		addLocationHeader(r)
+	}
+}
+
+// Trailer can be used in: Response
+//
+// Trailer sends the value of the given attribute of the response media type in a HTTP trailer
+// after the response body. The optional second argument sets the name of the trailer, it defaults
+// to the canonical form of the attribute name. Trailers suit values computed while the body is
+// written such as checksums or record counts, for example:
+//
+//    Response(OK, ExportMedia, func() {
+//        Trailer("checksum", "X-Checksum")
+//        Trailer("count", "X-Record-Count")
+//    })
+//
+// The attributes must be booleans, integers, numbers, strings or date times. The generated
+// response method declares the trailers in the Trailer header and sets them once the body is
+// sent, the generated client package provides a function per response that reads the trailers
+// into the decoded media type once the body has been consumed.
+func Trailer(attribute string, name ...string) {
+	if len(name) > 1 {
+		dslengine.ReportError("too many arguments given to Trailer")
+		return
+	}
+	r, ok := responseDefinition()
+	if !ok {
+		return
+	}
+	n := http.CanonicalHeaderKey(attribute)
+	if len(name) > 0 {
+		n = http.CanonicalHeaderKey(name[0])
+	}
+	if r.Trailers == nil {
+		r.Trailers = make(map[string]string)
+	}
+	r.Trailers[n] = attribute
+	if r.Headers == nil {
+		r.Headers = &design.AttributeDefinition{Type: design.Object{}}
+	}
+	if headers := r.Headers.Type.ToObject(); headers != nil {
+		if _, ok := headers["Trailer"]; !ok {
+			headers["Trailer"] = &design.AttributeDefinition{
+				Type:        design.String,
+				Description: "Names of the trailers sent after the response body",
+			}
+		}
+	}
+}
+
+// addLocationHeader adds the Location header to the response header definitions so that it
+// appears in the generated documentation.
+func addLocationHeader(r *design.ResponseDefinition) {
+	if r.Headers == nil {
+		r.Headers = &design.AttributeDefinition{Type: design.Object{}}
+	}
+	headers := r.Headers.Type.ToObject()
+	if headers == nil {
+		return
+	}
+	if _, ok := headers["Location"]; !ok {
+		headers["Location"] = &design.AttributeDefinition{Type: design.String}
+	}
+}
+
 func executeResponseDSL(name string, paramsAndDSL ...interface{}) *design.ResponseDefinition {
 	var params []string
 	var dsl func()
@@ -131,6 +131,133 @@ var _ = Describe("Response", func() {
 		})
 	})
 
+	Context("with a tag", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(202)
+				Tag("state", "pending")
+			}
+		})
+
+		It("sets the tag attribute and value", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.TagAttribute).Should(Equal("state"))
+			Ω(res.TagValue).Should(Equal("pending"))
+		})
+	})
+
+	Context("with a redirect", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(302)
+				Redirect()
+			}
+		})
+
+		It("sets the redirect flag and the Location header", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Redirect).Should(BeTrue())
+			Ω(res.Headers).ShouldNot(BeNil())
+			Ω(res.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		})
+	})
+
+	Context("with no body", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(200)
+				NoBody()
+			}
+		})
+
+		It("sets the no body flag", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.NoBody).Should(BeTrue())
+			Ω(res.MediaType).Should(BeEmpty())
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with parts", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(200)
+				Part("total", Integer)
+				Part("label", "image/png")
+			}
+		})
+
+		It("sets the parts and the multipart media type", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.MediaType).Should(Equal("multipart/mixed"))
+			Ω(res.Parts).Should(HaveLen(2))
+			Ω(res.Parts[0].Name).Should(Equal("total"))
+			Ω(res.Parts[0].Type).Should(Equal(Integer))
+			Ω(res.Parts[1].MediaType).Should(Equal("image/png"))
+			Ω(res.Validate()).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with parts defined twice", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(200)
+				Part("total", Integer)
+				Part("total", "text/plain")
+			}
+		})
+
+		It("fails validation", func() {
+			Ω(res).ShouldNot(BeNil())
+			err := res.Validate()
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("part is defined more than once"))
+		})
+	})
+
+	Context("with a location attribute", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(201)
+				Location("href")
+			}
+		})
+
+		It("sets the location attribute and the Location header", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Redirect).Should(BeFalse())
+			Ω(res.LocationAttribute).Should(Equal("href"))
+			Ω(res.Headers.Type.ToObject()).Should(HaveKey("Location"))
+		})
+	})
+
+	Context("with trailers", func() {
+		BeforeEach(func() {
+			name = "foo"
+			dsl = func() {
+				Status(200)
+				Trailer("checksum", "x-checksum")
+				Trailer("count")
+			}
+		})
+
+		It("maps the attributes to the trailers and adds the Trailer header", func() {
+			Ω(res).ShouldNot(BeNil())
+			Ω(res.Trailers).Should(Equal(map[string]string{"X-Checksum": "checksum", "Count": "count"}))
+			Ω(res.Headers.Type.ToObject()).Should(HaveKey("Trailer"))
+		})
+
+		It("requires a media type", func() {
+			Ω(res.Validate()).Should(HaveOccurred())
+		})
+	})
+
 	Context("with a status and media type", func() {
 		const status = 201
 		const mediaType = "mt"
@@ -198,3 +325,41 @@ var _ = Describe("Response", func() {
 	})
 
 })
+
+var _ = Describe("NoBody", func() {
+	var res *ResponseDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		mt := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name")
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			DefaultMedia(mt)
+			Action("exists", func() {
+				Routing(HEAD("/:id"))
+				Response(OK, func() {
+					NoBody()
+					Headers(func() {
+						Header("ETag")
+					})
+				})
+			})
+		})
+		dslengine.Run()
+		res = Design.Resources["bottle"].Actions["exists"].Responses[OK]
+	})
+
+	It("does not inherit the resource default media type", func() {
+		Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		Ω(res.NoBody).Should(BeTrue())
+		Ω(res.MediaType).Should(BeEmpty())
+		Ω(res.Type).Should(BeNil())
+		Ω(res.Headers.Type.ToObject()).Should(HaveKey("ETag"))
+	})
+})
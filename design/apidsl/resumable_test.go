@@ -0,0 +1,87 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resumable", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("adds the upload length header and the created response", func() {
+		API("uploads", func() {})
+		Resource("upload", func() {
+			BasePath("/accounts/:accountID")
+			Action("create", func() {
+				Routing(POST("/uploads"))
+				Resumable()
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		a := Design.Resources["upload"].Actions["create"]
+		Ω(a.Resumable).Should(BeTrue())
+		Ω(Design.HasResumableActions()).Should(BeTrue())
+		Ω(a.UploadPath()).Should(Equal("/accounts/:accountID/uploads/:uploadID"))
+		Ω(a.Headers.Type.ToObject()).Should(HaveKey("Upload-Length"))
+		Ω(a.Headers.IsRequired("Upload-Length")).Should(BeTrue())
+		Ω(a.Responses).Should(HaveKey(Created))
+		resp := a.Responses[Created]
+		Ω(resp.Status).Should(Equal(201))
+		Ω(resp.MediaType).Should(BeEmpty())
+		Ω(resp.Headers.Type.ToObject()).Should(HaveKey("Location"))
+	})
+
+	It("keeps an explicit Created response", func() {
+		Resource("upload", func() {
+			Action("create", func() {
+				Routing(POST("/uploads"))
+				Resumable()
+				Response(Created, func() { Description("upload created") })
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		resp := Design.Resources["upload"].Actions["create"].Responses[Created]
+		Ω(resp.Description).Should(Equal("upload created"))
+	})
+
+	It("requires a POST route", func() {
+		Resource("upload", func() {
+			Action("create", func() {
+				Routing(PUT("/uploads"))
+				Resumable()
+			})
+		})
+		Ω(dslengine.Run()).Should(MatchError(ContainSubstring("must use the POST method")))
+	})
+
+	It("rejects payloads", func() {
+		Resource("upload", func() {
+			Action("create", func() {
+				Routing(POST("/uploads"))
+				Payload(func() {
+					Attribute("name")
+				})
+				Resumable()
+			})
+		})
+		Ω(dslengine.Run()).Should(MatchError(ContainSubstring("cannot define a payload")))
+	})
+
+	It("reports conflicts with the upload offset endpoints", func() {
+		Resource("upload", func() {
+			Action("create", func() {
+				Routing(POST("/uploads"))
+				Resumable()
+			})
+			Action("append", func() {
+				Routing(PATCH("/uploads/:uploadID"))
+			})
+		})
+		Ω(dslengine.Run()).Should(MatchError(ContainSubstring(`route PATCH "/uploads/:uploadID" conflicts`)))
+	})
+})
@@ -0,0 +1,83 @@
+package apidsl
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// SchemaVersion can be used in: Action, Response
+//
+// SchemaVersion declares a revision of the schema of the request payload (when used in an action)
+// or of the response body (when used in a response) served by the same route. Requests select
+// the revision with the header set by SchemaVersionHeader or, by default, with the version
+// parameter of the Content-Type header for the payload and of the Accept header for the
+// response, e.g. "application/json; version=1". Requests that do not select a declared revision
+// use the action payload and response media type.
+//
+// The generated controller decodes and validates the payload revisions into their own type
+// and converts them into the action payload, the generated context converts the response media
+// type into the requested revision before sending it. The conversion functions are generated in
+// the app package as variables that copy the attributes with the same name by default and may
+// be replaced to map renamed or restructured attributes. Example:
+//
+//    var BottlePayloadV1 = Type("BottlePayloadV1", func() {
+//        Attribute("name", String)
+//        Attribute("colour", String) // Renamed to color in the current revision
+//    })
+//
+//    Action("create", func() {
+//        Routing(POST(""))
+//        Payload(BottlePayload)
+//        SchemaVersion("1", BottlePayloadV1)
+//        Response(OK, func() {
+//            Media(BottleMedia)
+//            SchemaVersion("1", BottleV1)
+//        })
+//    })
+func SchemaVersion(version string, ut interface{}) {
+	var t *design.UserTypeDefinition
+	switch actual := ut.(type) {
+	case *design.UserTypeDefinition:
+		t = actual
+	case *design.MediaTypeDefinition:
+		t = actual.UserTypeDefinition
+	case string:
+		var ok bool
+		if t, ok = design.Design.Types[actual]; !ok {
+			dslengine.ReportError("unknown schema version type %s", actual)
+			return
+		}
+	default:
+		dslengine.ReportError("invalid schema version type %#v, must be a user type", ut)
+		return
+	}
+	v := &design.SchemaVersionDefinition{Version: version, Type: t}
+	switch def := dslengine.CurrentDefinition().(type) {
+	case *design.ActionDefinition:
+		v.Parent = def
+		def.PayloadVersions = append(def.PayloadVersions, v)
+	case *design.ResponseDefinition:
+		v.Parent = def
+		def.Versions = append(def.Versions, v)
+	default:
+		dslengine.IncompatibleDSL()
+	}
+}
+
+// SchemaVersionHeader can be used in: Action
+//
+// SchemaVersionHeader sets the name of the request header that selects the schema revision of the
+// payload and of the response bodies declared with SchemaVersion. The version parameter of the
+// Content-Type and Accept headers is used when the request does not set the header:
+//
+//    Action("create", func() {
+//        SchemaVersionHeader("X-Schema-Version")
+//        SchemaVersion("1", BottlePayloadV1)
+//    })
+func SchemaVersionHeader(name string) {
+	if a, ok := actionDefinition(); ok {
+		a.SchemaVersionHeader = http.CanonicalHeaderKey(name)
+	}
+}
@@ -0,0 +1,102 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SchemaVersion", func() {
+	var payloadV1, bottleV1 *UserTypeDefinition
+	var bottle *MediaTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		payloadV1 = Type("BottlePayloadV1", func() {
+			Attribute("colour", String)
+		})
+		bottleV1 = Type("BottleV1", func() {
+			Attribute("colour", String)
+		})
+		bottle = MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("color", String)
+			})
+			View("default", func() {
+				Attribute("color")
+			})
+		})
+	})
+
+	It("declares the payload and response revisions", func() {
+		Resource("bottle", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Attribute("color", String)
+				})
+				SchemaVersionHeader("x-schema-version")
+				SchemaVersion("1", payloadV1)
+				Response(OK, func() {
+					Media(bottle)
+					SchemaVersion("1", "BottleV1")
+				})
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		create := Design.Resources["bottle"].Actions["create"]
+		Ω(create.SchemaVersionHeader).Should(Equal("X-Schema-Version"))
+		Ω(create.PayloadVersions).Should(HaveLen(1))
+		Ω(create.PayloadVersions[0].Version).Should(Equal("1"))
+		Ω(create.PayloadVersions[0].Type).Should(Equal(payloadV1))
+		versions := create.Responses["OK"].Versions
+		Ω(versions).Should(HaveLen(1))
+		Ω(versions[0].Type).Should(Equal(bottleV1))
+	})
+
+	It("rejects payload revisions without a payload", func() {
+		Resource("bottle", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				SchemaVersion("1", payloadV1)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("SchemaVersion may only be used in actions with an object payload"))
+	})
+
+	It("rejects versions defined more than once", func() {
+		Resource("bottle", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Payload(func() {
+					Attribute("color", String)
+				})
+				SchemaVersion("1", payloadV1)
+				SchemaVersion("1", payloadV1)
+				Response(NoContent)
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("schema version is defined more than once"))
+	})
+
+	It("rejects response revisions without a media type", func() {
+		Resource("bottle", func() {
+			Action("create", func() {
+				Routing(POST(""))
+				Response(Accepted, func() {
+					SchemaVersion("1", bottleV1)
+				})
+			})
+		})
+		err := dslengine.Run()
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("SchemaVersion may only be used in responses with an object media type"))
+	})
+})
@@ -264,7 +264,154 @@ func JWTSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
 	return def
 }
 
-// Scope can be used in: Security, JWTSecurity, OAuth2Security
+// HMACSecurity is a top level DSL.
+// HMACSecurity defines a security scheme where clients sign requests with a HMAC-SHA256 of the
+// canonical request computed using a shared secret, similarly to AWS Signature Version 4. The
+// generated Go client signs requests with the goa client HMACSigner and the service verifies the
+// signatures with the middleware implemented in the goa middleware/security/hmacauth package.
+//
+// Since HMAC signatures are not part of the Swagger specification the scheme is described as an
+// "apiKey" scheme in the generated Swagger.
+//
+// Example:
+//
+//    HMACSecurity("hmac", func() {
+//        Description("Requests must be signed with your API secret")
+//        Header("Authorization")
+//    })
+//
+func HMACSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.HMACSecurityKind,
+		Type:       "apiKey",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
+
+// CookieSecurity is a top level DSL.
+// CookieSecurity defines a security scheme where requests are authenticated with a session cookie
+// issued by the service. The value of the cookie is signed with a HMAC-SHA256 and optionally
+// encrypted with AES-GCM using the keys returned by the key provider given to the middleware
+// implemented in the goa middleware/security/cookieauth package. The name of the cookie defaults
+// to "session".
+//
+// Session defines the type of the content of the session, the generated code includes a
+// middleware that decodes the session into the corresponding Go type and helpers that store it in
+// the response Set-Cookie header.
+//
+// Since cookie sessions are not part of the Swagger specification the scheme is described as an
+// "apiKey" scheme using the Cookie header in the generated Swagger.
+//
+// Example:
+//
+//    CookieSecurity("session", func() {
+//        Description("Browser sessions")
+//        Cookie("sid")
+//        Session(SessionData)
+//        Encrypted()
+//    })
+//
+func CookieSecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.CookieSecurityKind,
+		Type:       "apiKey",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
+
+// SPIFFESecurity is a top level DSL.
+// SPIFFESecurity defines a mutual TLS security scheme where clients authenticate with the X.509
+// SVID issued to their workload by SPIFFE, e.g. by the SPIRE agent. The service authorizes the
+// requests using the SPIFFE ID found in the URI SAN of the client certificate: TrustDomain
+// defines the trust domain the IDs must belong to and Identity maps the IDs of the authorized
+// workloads to the scopes they are granted. The requests of workloads of the trust domain that
+// are not listed are granted no scope.
+//
+// The generated code includes the middleware implemented in the goa middleware/security/spiffeauth
+// package and the generated client includes a constructor that sets up mutual TLS using an X.509
+// SVID source.
+//
+// Since mutual TLS is not part of the Swagger specification the scheme is described as an
+// "apiKey" scheme using the X-Forwarded-Client-Cert header set by the proxies that terminate
+// mutual TLS, the trust domain and identities are listed in its "x-spiffe" extension.
+//
+// Example:
+//
+//    SPIFFESecurity("workload", func() {
+//        Description("Service to service authentication")
+//        TrustDomain("example.org")
+//        Scope("bottle:read", "Read bottles")
+//        Scope("bottle:write", "Write bottles")
+//        Identity("spiffe://example.org/ns/prod/sa/frontend", "bottle:read")
+//        Identity("spiffe://example.org/ns/prod/sa/cellar", "bottle:read", "bottle:write")
+//    })
+//
+func SPIFFESecurity(name string, dsl ...func()) *design.SecuritySchemeDefinition {
+	switch dslengine.CurrentDefinition().(type) {
+	case *design.APIDefinition, *dslengine.TopLevelDefinition:
+	default:
+		dslengine.IncompatibleDSL()
+		return nil
+	}
+
+	if securitySchemeRedefined(name) {
+		return nil
+	}
+
+	def := &design.SecuritySchemeDefinition{
+		SchemeName: name,
+		Kind:       design.SPIFFESecurityKind,
+		Type:       "apiKey",
+	}
+
+	if len(dsl) != 0 {
+		def.DSLFunc = dsl[0]
+	}
+
+	design.Design.SecuritySchemes = append(design.Design.SecuritySchemes, def)
+
+	return def
+}
+
+// Scope can be used in: Security, JWTSecurity, OAuth2Security, SPIFFESecurity
 //
 // Scope defines an authorization scope. Used within SecurityScheme, a description may be provided
 // explaining what the scope means. Within a Security block, only a scope is needed.
@@ -297,7 +444,8 @@ func Scope(name string, desc ...string) {
 // inHeader is called by `Header()`, see documentation there.
 func inHeader(headerName string) {
 	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
-		if current.Kind == design.APIKeySecurityKind || current.Kind == design.JWTSecurityKind {
+		switch current.Kind {
+		case design.APIKeySecurityKind, design.JWTSecurityKind, design.HMACSecurityKind:
 			if current.In != "" {
 				dslengine.ReportError("'In' previously defined through Header or Query")
 				return
@@ -329,6 +477,91 @@ func Query(parameterName string) {
 	dslengine.IncompatibleDSL()
 }
 
+// Cookie can be used in: CookieSecurity
+//
+// Cookie defines the name of the cookie that holds the session.
+func Cookie(name string) {
+	if current, ok := cookieSecurityDefinition(); ok {
+		if current.In != "" {
+			dslengine.ReportError("cookie previously defined")
+			return
+		}
+		current.In = "cookie"
+		current.Name = name
+	}
+}
+
+// Session can be used in: CookieSecurity
+//
+// Session defines the type of the content of the session cookie. The type must be an object,
+// typically a user type or a media type.
+func Session(t design.DataType) {
+	if current, ok := cookieSecurityDefinition(); ok {
+		current.SessionType = t
+	}
+}
+
+// Encrypted can be used in: CookieSecurity
+//
+// Encrypted makes the content of the session cookie encrypted in addition to being signed so that
+// clients cannot read it.
+func Encrypted() {
+	if current, ok := cookieSecurityDefinition(); ok {
+		current.Encrypted = true
+	}
+}
+
+// TrustDomain can be used in: SPIFFESecurity
+//
+// TrustDomain defines the SPIFFE trust domain of the workloads authorized by the scheme, e.g.
+// "example.org".
+func TrustDomain(domain string) {
+	if current, ok := spiffeSecurityDefinition(); ok {
+		current.TrustDomain = domain
+	}
+}
+
+// Identity can be used in: SPIFFESecurity
+//
+// Identity authorizes the workload with the given SPIFFE ID and grants it the given scopes. The
+// scopes must be defined with Scope in the same scheme.
+func Identity(id string, scopes ...string) {
+	if current, ok := spiffeSecurityDefinition(); ok {
+		if _, ok := current.Identities[id]; ok {
+			dslengine.ReportError("identity %s is defined twice", id)
+			return
+		}
+		if current.Identities == nil {
+			current.Identities = make(map[string][]string)
+		}
+		current.Identities[id] = scopes
+	}
+}
+
+// spiffeSecurityDefinition returns the current definition if it is a SPIFFESecurity definition,
+// it reports an incompatible DSL error otherwise.
+func spiffeSecurityDefinition() (*design.SecuritySchemeDefinition, bool) {
+	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
+		if current.Kind == design.SPIFFESecurityKind {
+			return current, true
+		}
+	}
+	dslengine.IncompatibleDSL()
+	return nil, false
+}
+
+// cookieSecurityDefinition returns the current definition if it is a CookieSecurity definition,
+// it reports an incompatible DSL error otherwise.
+func cookieSecurityDefinition() (*design.SecuritySchemeDefinition, bool) {
+	if current, ok := dslengine.CurrentDefinition().(*design.SecuritySchemeDefinition); ok {
+		if current.Kind == design.CookieSecurityKind {
+			return current, true
+		}
+	}
+	dslengine.IncompatibleDSL()
+	return nil, false
+}
+
 // AccessCodeFlow can be used in: OAuth2Security
 //
 // AccessCodeFlow defines an "access code" OAuth2 flow.  Use within an OAuth2Security definition.
@@ -71,6 +71,132 @@ var _ = Describe("Security", func() {
 		Ω(Design.SecuritySchemes[3].Scopes).Should(HaveLen(2))
 	})
 
+	Context("with HMAC security", func() {
+		It("defaults the signature header to Authorization", func() {
+			API("secure", func() {
+				HMACSecurity("hmac", func() {
+					Description("desc")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(1))
+			Ω(Design.SecuritySchemes[0].Kind).Should(Equal(HMACSecurityKind))
+			Ω(Design.SecuritySchemes[0].Type).Should(Equal("apiKey"))
+			Ω(Design.SecuritySchemes[0].In).Should(Equal("header"))
+			Ω(Design.SecuritySchemes[0].Name).Should(Equal("Authorization"))
+		})
+
+		It("accepts a custom signature header", func() {
+			API("secure", func() {
+				HMACSecurity("hmac", func() {
+					Header("X-Signature")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes[0].Name).Should(Equal("X-Signature"))
+		})
+	})
+
+	Context("with cookie security", func() {
+		It("defaults the cookie name", func() {
+			API("secure", func() {
+				CookieSecurity("session")
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(1))
+			Ω(Design.SecuritySchemes[0].Kind).Should(Equal(CookieSecurityKind))
+			Ω(Design.SecuritySchemes[0].Type).Should(Equal("apiKey"))
+			Ω(Design.SecuritySchemes[0].In).Should(Equal("cookie"))
+			Ω(Design.SecuritySchemes[0].Name).Should(Equal("session"))
+		})
+
+		It("sets the cookie, the session type and the encryption", func() {
+			session := Type("SessionData", func() {
+				Attribute("user_id", Integer)
+			})
+			API("secure", func() {
+				CookieSecurity("session", func() {
+					Cookie("sid")
+					Session(session)
+					Encrypted()
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes[0].Name).Should(Equal("sid"))
+			Ω(Design.SecuritySchemes[0].SessionType).Should(Equal(session))
+			Ω(Design.SecuritySchemes[0].Encrypted).Should(BeTrue())
+		})
+
+		It("rejects a session type that is not an object", func() {
+			API("secure", func() {
+				CookieSecurity("session", func() {
+					Session(String)
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
+		It("rejects Cookie in other schemes", func() {
+			API("secure", func() {
+				APIKeySecurity("key", func() {
+					Cookie("sid")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
+	Context("with SPIFFE security", func() {
+		It("maps the identities to the scopes", func() {
+			API("secure", func() {
+				SPIFFESecurity("workload", func() {
+					TrustDomain("example.org")
+					Scope("bottle:read")
+					Scope("bottle:write")
+					Identity("spiffe://example.org/ns/prod/sa/frontend", "bottle:read")
+					Identity("spiffe://example.org/ns/prod/sa/cellar", "bottle:read", "bottle:write")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			Ω(Design.SecuritySchemes).Should(HaveLen(1))
+			Ω(Design.SecuritySchemes[0].Kind).Should(Equal(SPIFFESecurityKind))
+			Ω(Design.SecuritySchemes[0].TrustDomain).Should(Equal("example.org"))
+			Ω(Design.SecuritySchemes[0].Identities).Should(Equal(map[string][]string{
+				"spiffe://example.org/ns/prod/sa/frontend": {"bottle:read"},
+				"spiffe://example.org/ns/prod/sa/cellar":   {"bottle:read", "bottle:write"},
+			}))
+		})
+
+		It("rejects identities of other trust domains", func() {
+			API("secure", func() {
+				SPIFFESecurity("workload", func() {
+					TrustDomain("example.org")
+					Identity("spiffe://other.org/ns/prod/sa/frontend")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+
+		It("rejects undefined scopes", func() {
+			API("secure", func() {
+				SPIFFESecurity("workload", func() {
+					TrustDomain("example.org")
+					Identity("spiffe://example.org/ns/prod/sa/frontend", "bottle:read")
+				})
+			})
+			dslengine.Run()
+			Ω(dslengine.Errors).Should(HaveOccurred())
+		})
+	})
+
 	Context("with basic security", func() {
 		It("should fail because of duplicate In declaration", func() {
 			API("", func() {
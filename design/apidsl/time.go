@@ -0,0 +1,77 @@
+package apidsl
+
+import (
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// TimeFormats can be used in: Attribute, Header, Param
+//
+// TimeFormats lists the formats accepted when decoding a DateTime attribute. The formats are
+// RFC3339, UnixSeconds, UnixMillis or Go time layouts such as "2006-01-02". The generated code
+// tries the formats in order. The unix formats accept JSON numbers as well as strings in request
+// and response bodies:
+//
+//	Attribute("created_at", DateTime, func() {
+//		TimeFormats(RFC3339, UnixSeconds, UnixMillis)
+//	})
+//
+// The attributes of the request and response bodies honor the formats when they are defined
+// directly in a user type, a media type or a payload and when the body is encoded in JSON.
+func TimeFormats(formats ...string) {
+	if a, ok := timeDefinition("time formats"); ok {
+		if len(formats) == 0 {
+			dslengine.ReportError("missing time format")
+			return
+		}
+		a.SetTimeFormats(formats...)
+	}
+}
+
+// TimeOutput can be used in: Attribute
+//
+// TimeOutput sets the format used to encode a DateTime attribute and optionally the IANA name of
+// the time zone the values are converted to, e.g. "UTC" or "Europe/Paris". The format is one of
+// the values accepted by TimeFormats, the attribute also accepts it when decoding. The values
+// are encoded in the first format given to TimeFormats in their own time zone by default:
+//
+//	Attribute("due_at", DateTime, func() {
+//		TimeOutput(RFC3339, "UTC")
+//	})
+func TimeOutput(format string, zone ...string) {
+	if a, ok := timeDefinition("time output"); ok {
+		if len(zone) > 1 {
+			dslengine.ReportError("too many arguments given to TimeOutput")
+			return
+		}
+		var z string
+		if len(zone) == 1 {
+			z = zone[0]
+			if _, err := time.LoadLocation(z); err != nil {
+				dslengine.ReportError("invalid time zone %#v: %s", z, err)
+				return
+			}
+		}
+		a.SetTimeOutput(format, z)
+	}
+}
+
+// timeDefinition returns the current attribute definition if it is a DateTime attribute and
+// reports an error mentioning the given setting otherwise.
+func timeDefinition(setting string) (*design.AttributeDefinition, bool) {
+	a, ok := attributeDefinition()
+	if !ok {
+		return nil, false
+	}
+	if a.Type != design.DateTime {
+		typeName := "unknown"
+		if a.Type != nil {
+			typeName = a.Type.Name()
+		}
+		incompatibleAttributeType(setting, typeName, "a date time")
+		return nil, false
+	}
+	return a, true
+}
@@ -0,0 +1,67 @@
+package apidsl_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeFormats", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+	})
+
+	It("records the time formats and output", func() {
+		Type("event", func() {
+			Attribute("at", DateTime, func() {
+				TimeFormats(RFC3339, UnixSeconds)
+				TimeOutput(UnixMillis, "UTC")
+			})
+			Attribute("day", DateTime, func() {
+				TimeFormats("2006-01-02")
+			})
+			Attribute("created_at", DateTime)
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		o := Design.Types["event"].Type.ToObject()
+		Ω(o["at"].HasTimeEncoding()).Should(BeTrue())
+		Ω(o["at"].TimeFormats()).Should(Equal([]string{RFC3339, UnixSeconds, UnixMillis}))
+		format, zone := o["at"].TimeOutput()
+		Ω(format).Should(Equal(UnixMillis))
+		Ω(zone).Should(Equal("UTC"))
+		format, zone = o["day"].TimeOutput()
+		Ω(format).Should(Equal("2006-01-02"))
+		Ω(zone).Should(BeEmpty())
+		Ω(o["created_at"].HasTimeEncoding()).Should(BeFalse())
+		Ω(o["created_at"].TimeFormats()).Should(BeNil())
+	})
+
+	It("rejects missing formats", func() {
+		Type("event", func() {
+			Attribute("at", DateTime, func() {
+				TimeFormats()
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects non date time attributes", func() {
+		Type("event", func() {
+			Attribute("at", String, func() {
+				TimeFormats(UnixSeconds)
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+
+	It("rejects unknown time zones", func() {
+		Type("event", func() {
+			Attribute("at", DateTime, func() {
+				TimeOutput(RFC3339, "Nowhere/Land")
+			})
+		})
+		Ω(dslengine.Run()).Should(HaveOccurred())
+	})
+})
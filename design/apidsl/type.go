@@ -106,6 +106,7 @@ func ArrayOf(v interface{}, dsl ...func()) *design.Array {
 	if len(dsl) == 1 {
 		dslengine.Execute(dsl[0], &at)
 	}
+	decimalAttribute(&at)
 	return &design.Array{ElemType: &at}
 }
 
@@ -166,6 +167,8 @@ func HashOf(k, v interface{}, dsls ...func()) *design.Hash {
 			dslengine.Execute(dsls[1], &vat)
 		}
 	}
+	decimalAttribute(&kat)
+	decimalAttribute(&vat)
 	return &design.Hash{KeyType: &kat, ElemType: &vat}
 }
 
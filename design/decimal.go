@@ -0,0 +1,48 @@
+package design
+
+import (
+	"regexp"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// decimalRegex is the regular expression used to validate the default values and enums of the
+// Decimal attributes.
+var decimalRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// IsDecimal returns true if the attribute was defined with the Decimal type.
+func (a *AttributeDefinition) IsDecimal() bool {
+	return a.Type == String && a.Validation != nil && a.Validation.Format == "decimal"
+}
+
+// finalizeDecimals sets the "struct:field:type" metadata of the decimal attributes of att to the
+// Go type defined with the DecimalType DSL if any. The attributes with a default value keep the
+// string type so that the generated code can assign it. finalizeDecimals does not recurse into
+// user types which are finalized on their own.
+func finalizeDecimals(att *AttributeDefinition) {
+	if len(Design.DecimalType) == 0 || att == nil {
+		return
+	}
+	switch actual := att.Type.(type) {
+	case Primitive:
+		if !att.IsDecimal() || att.DefaultValue != nil {
+			return
+		}
+		if _, ok := att.Metadata["struct:field:type"]; ok {
+			return
+		}
+		if att.Metadata == nil {
+			att.Metadata = make(dslengine.MetadataDefinition)
+		}
+		att.Metadata["struct:field:type"] = Design.DecimalType
+	case Object:
+		for _, catt := range actual {
+			finalizeDecimals(catt)
+		}
+	case *Array:
+		finalizeDecimals(actual.ElemType)
+	case *Hash:
+		finalizeDecimals(actual.KeyType)
+		finalizeDecimals(actual.ElemType)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dimfeld/httppath"
 	"github.com/goadesign/goa/dslengine"
@@ -27,6 +28,8 @@ type (
 		Host string
 		// Schemes is the supported API URL schemes
 		Schemes []string
+		// Servers lists the hosts serving the API in the different environments
+		Servers []*ServerDefinition
 		// BasePath is the common base path to all API endpoints
 		BasePath string
 		// Params define the common path parameters to all API endpoints
@@ -57,6 +60,9 @@ type (
 		Responses map[string]*ResponseDefinition
 		// Response template factories available to all API actions indexed by name
 		ResponseTemplates map[string]*ResponseTemplateDefinition
+		// ErrorClasses lists the names of the responses defined with ErrorClass in order of
+		// definition.
+		ErrorClasses []string
 		// Built-in responses
 		DefaultResponses map[string]*ResponseDefinition
 		// Built-in response templates
@@ -72,8 +78,47 @@ type (
 		// resources and actions, unless overridden by Resource or
 		// Action-level Security() calls.
 		Security *SecurityDefinition
+		// LogSampling is the percentage of the requests logged by the generated request
+		// logging middleware, unless overridden by Resource or Action-level LogRequests()
+		// calls. Requests are not logged if nil.
+		LogSampling *int
+		// ClientClosedStatus is true if the requests canceled by their client are logged with
+		// the 499 status rather than as errors, unless overridden by Resource or
+		// Action-level ClientClosedStatus() calls.
+		ClientClosedStatus *bool
 		// NoExamples indicates whether to bypass automatic example generation.
 		NoExamples bool
+		// ExampleSeed is the seed of the random generator used to generate the examples, the
+		// API name is used if empty.
+		ExampleSeed string
+		// RealisticExamples indicates whether the generated examples of string attributes are
+		// derived from the attribute names, e.g. a fake email address for "email".
+		RealisticExamples bool
+		// JSONRPCPath is the path of the JSON-RPC 2.0 endpoint that exposes the API
+		// actions, empty if there is none.
+		JSONRPCPath string
+		// JobsPath is the path prefix of the status endpoint of the jobs started by the
+		// asynchronous actions, see Async.
+		JobsPath string
+		// NilCollections is the encoding of the nil slices and maps of the request and
+		// response bodies, NilCollectionsNull or NilCollectionsEmpty, see NilCollections.
+		// If empty the generated code encodes the nil top-level collections as empty arrays
+		// and the nested ones as null.
+		NilCollections string
+		// RequestIDFormat is the format of the IDs created by the generated service for the
+		// requests that do not carry one, see RequestIDFormat. The default format is used if
+		// empty.
+		RequestIDFormat string
+		// MaxRecursionDepth is the maximum number of levels a recursive user type or media
+		// type is expanded to in the generated examples, see RecursionDepth.
+		MaxRecursionDepth int
+		// DecimalType is the Go type name followed by the optional import path of the
+		// struct fields generated for the Decimal attributes of the user types, media types
+		// and payloads, see DecimalType. The fields are strings if empty.
+		DecimalType []string
+		// ErrorEnvelope describes the layout of the error responses if it differs from the
+		// default goa error layout, see ErrorEnvelope.
+		ErrorEnvelope *ErrorEnvelopeDefinition
 
 		// rand is the random generator used to generate examples.
 		rand *RandomGenerator
@@ -97,6 +142,18 @@ type (
 		URL string `json:"url,omitempty"`
 	}
 
+	// ErrorEnvelopeDefinition describes the layout of the JSON error responses.
+	ErrorEnvelopeDefinition struct {
+		// Wrap is the name of the response field that contains the error object, the error
+		// object is the response body if empty.
+		Wrap string
+		// Fields maps the names of the goa error fields to the names used in the error
+		// object, "-" omits the field.
+		Fields map[string]string
+		// Static contains the fields added to the error object with constant values.
+		Static map[string]interface{}
+	}
+
 	// DocsDefinition points to external documentation.
 	DocsDefinition struct {
 		// Description of documentation.
@@ -105,6 +162,95 @@ type (
 		URL string `json:"url,omitempty"`
 	}
 
+	// TenantDefinition binds the tenant identifier of the resource actions to a request
+	// header or path parameter, see Tenant.
+	TenantDefinition struct {
+		// Name is the name of the header or of the path parameter.
+		Name string
+		// Attribute defines the validations and documentation of the identifier.
+		Attribute *AttributeDefinition
+	}
+
+	// PriorityDefinition binds the priority level of the resource or action requests to a
+	// request header, the generated code sheds the low priority requests when the service is
+	// overloaded, see Priority.
+	PriorityDefinition struct {
+		// Header is the name of the header that carries the priority level.
+		Header string
+		// Thresholds maps the priority levels to the service load above which the requests
+		// with that level are rejected.
+		Thresholds map[string]float64
+		// Attribute defines the validations and documentation of the header.
+		Attribute *AttributeDefinition
+	}
+
+	// DeprecationDefinition schedules the retirement of the resource or action endpoints, the
+	// generated code sets the Deprecation and Sunset response headers, see Deprecated.
+	DeprecationDefinition struct {
+		// Since is the date the endpoints were deprecated on.
+		Since time.Time
+		// Sunset is the date the endpoints are expected to stop responding on, zero if the
+		// retirement is not scheduled yet.
+		Sunset time.Time
+	}
+
+	// FlagDefinition gates the resource or action endpoints behind a runtime feature flag, see
+	// Flag.
+	FlagDefinition struct {
+		// Name is the name of the feature flag given to the flag provider.
+		Name string
+		// Status is the status code of the responses sent while the flag is disabled, 404
+		// hides the endpoints and 403 forbids their access.
+		Status int
+	}
+
+	// ConcurrencyDefinition limits the number of requests handled concurrently by the resource
+	// or action endpoints, see MaxConcurrency.
+	ConcurrencyDefinition struct {
+		// Limit is the maximum number of requests handled concurrently by each action.
+		Limit int
+		// Timeout is the maximum duration the requests that exceed the limit wait for a
+		// slot before being rejected, zero if they are rejected right away.
+		Timeout time.Duration
+	}
+
+	// TagDefinition describes a tag used to group the API resources and actions in the
+	// documentation.
+	TagDefinition struct {
+		// Name of tag.
+		Name string
+		// Description of tag.
+		Description string
+		// Docs points to the tag external documentation.
+		Docs *DocsDefinition
+	}
+
+	// ServerDefinition describes a host serving the API in a given environment, e.g. "staging".
+	ServerDefinition struct {
+		// Name of server, e.g. "prod".
+		Name string
+		// Description of server.
+		Description string
+		// Host is the server hostname, it may contain variables of the form {name}.
+		Host string
+		// Schemes is the list of URL schemes supported by the server.
+		Schemes []string
+		// Variables lists the variables used in the host.
+		Variables []*ServerVariableDefinition
+		// HealthCheck is the request path of the server health check endpoint if any.
+		HealthCheck string
+	}
+
+	// ServerVariableDefinition describes a variable of a server host.
+	ServerVariableDefinition struct {
+		// Name of variable.
+		Name string
+		// Default is the value used when none is provided.
+		Default string
+		// Values lists the allowed values if any.
+		Values []string
+	}
+
 	// ResourceDefinition describes a REST resource.
 	// It defines both a media type and a set of actions that can be executed through HTTP
 	// requests.
@@ -144,6 +290,37 @@ type (
 		// Security defines security requirements for the Resource,
 		// for actions that don't define one themselves.
 		Security *SecurityDefinition
+		// LogSampling is the percentage of the requests logged by the generated request
+		// logging middleware for actions that don't define one themselves.
+		LogSampling *int
+		// ClientClosedStatus is true if the requests canceled by their client are logged with
+		// the 499 status for actions that don't define it themselves.
+		ClientClosedStatus *bool
+		// Audited is true if the resource actions emit audit events, see Audit.
+		Audited bool
+		// Recorded is true if the resource action requests and responses are recorded, see
+		// Record.
+		Recorded bool
+		// Tenant binds the tenant identifier of the resource actions if any.
+		Tenant *TenantDefinition
+		// Priority binds the priority level of the resource action requests if any.
+		Priority *PriorityDefinition
+		// Deprecation schedules the retirement of the resource actions if any.
+		Deprecation *DeprecationDefinition
+		// Flag gates the resource actions behind a feature flag if any.
+		Flag *FlagDefinition
+		// Middleware lists the names of the middleware applied to the resource actions in
+		// order, see Use.
+		Middleware []string
+		// Concurrency limits the number of requests handled concurrently by each resource
+		// action if any.
+		Concurrency *ConcurrencyDefinition
+		// CSRF is true if the resource actions are protected against cross-site request
+		// forgery.
+		CSRF bool
+		// CSRFTokenPath is the path of the endpoint that issues CSRF tokens relative to
+		// the resource base path if any.
+		CSRFTokenPath string
 	}
 
 	// CORSDefinition contains the definition for a specific origin CORS policy.
@@ -204,6 +381,85 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Standard is true if the response definition comes from the goa default responses
 		Standard bool
+		// TagAttribute is the name of the media type attribute whose value selects the
+		// response at runtime if any.
+		TagAttribute string
+		// TagValue is the value of TagAttribute that selects the response.
+		TagValue string
+		// Redirect is true if the response redirects the client to the URL given in the
+		// Location header.
+		Redirect bool
+		// LocationAttribute is the name of the media type attribute whose value is used to
+		// set the Location header if any.
+		LocationAttribute string
+		// NoBody is true if the response has no body, in which case it does not inherit the
+		// media type of its resource.
+		NoBody bool
+		// Parts lists the parts of the multipart/mixed responses, see Part.
+		Parts []*PartDefinition
+		// HeaderGroups lists the groups of response headers mapped to user types, see
+		// HeaderGroup.
+		HeaderGroups []*HeaderGroupDefinition
+		// Trailers maps the names of the HTTP trailers sent after the response body to the
+		// media type attributes that hold their values, see Trailer.
+		Trailers map[string]string
+		// Versions lists the schema revisions of the response body that clients may request,
+		// see SchemaVersion.
+		Versions []*SchemaVersionDefinition
+	}
+
+	// PartDefinition describes a part of a multipart/mixed response.
+	PartDefinition struct {
+		// Name of the part, set in the Content-Disposition header of the part.
+		Name string
+		// Type of the JSON encoded content of the part, nil for binary parts.
+		Type DataType
+		// MediaType is the content type of the binary parts.
+		MediaType string
+		// Parent is the response that defines the part.
+		Parent *ResponseDefinition
+	}
+
+	// HeaderGroupDefinition maps a group of related headers to the attributes of a user type.
+	// Each attribute is read from and written to the header whose name is the group prefix
+	// followed by the attribute name.
+	HeaderGroupDefinition struct {
+		// Name of the group, used to name the generated fields and functions.
+		Name string
+		// Prefix of the names of the headers, e.g. "X-RateLimit-".
+		Prefix string
+		// Type is the user type the headers are mapped to.
+		Type *UserTypeDefinition
+		// Parent is the action or response that defines the group.
+		Parent dslengine.Definition
+	}
+
+	// QueryObjectDefinition binds a user type to the query string of the action requests. Each
+	// attribute is read from and written to the parameter whose name is the object name followed
+	// by the attribute name in brackets, e.g. "filter[name]", nested objects add one bracketed
+	// segment per level.
+	QueryObjectDefinition struct {
+		// Name of the object, prefix of the query string parameter names.
+		Name string
+		// Type is the user type bound to the query string.
+		Type *UserTypeDefinition
+		// Parent is the action that defines the object.
+		Parent *ActionDefinition
+	}
+
+	// SchemaVersionDefinition describes a revision of the schema of a request payload or of a
+	// response body. The revision is selected by the version header of the action or by the
+	// version parameter of the request media types and converted to or from the type used by
+	// the action.
+	SchemaVersionDefinition struct {
+		// Version is the value of the header or media type parameter that selects the
+		// revision, e.g. "1".
+		Version string
+		// Type is the type of the body in this revision.
+		Type *UserTypeDefinition
+		// Parent is the action (payload revisions) or response (response revisions) that
+		// defines the revision.
+		Parent dslengine.Definition
 	}
 
 	// ResponseTemplateDefinition defines a response template.
@@ -249,10 +505,92 @@ type (
 		PayloadMultipart bool
 		// Request headers that need to be made available to action
 		Headers *AttributeDefinition
+		// HeaderGroups lists the groups of request headers mapped to user types, see
+		// HeaderGroup.
+		HeaderGroups []*HeaderGroupDefinition
+		// QueryObjects lists the user types bound to the query string, see QueryObject.
+		QueryObjects []*QueryObjectDefinition
+		// PayloadVersions lists the schema revisions of the payload accepted by the action,
+		// see SchemaVersion.
+		PayloadVersions []*SchemaVersionDefinition
+		// SchemaVersionHeader is the name of the request header that selects the schema
+		// revision of the payload and of the response bodies, the version parameter of the
+		// Content-Type and Accept headers is used if empty.
+		SchemaVersionHeader string
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the action
 		Security *SecurityDefinition
+		// LogSampling is the percentage of the action requests logged by the generated
+		// request logging middleware, requests are not logged if nil or zero.
+		LogSampling *int
+		// ClientClosedStatus is true if the action requests canceled by their client are
+		// logged with the 499 status rather than as errors.
+		ClientClosedStatus *bool
+		// Audited is true if the generated code emits an audit event after each call to the
+		// action.
+		Audited bool
+		// Recorded is true if the generated code records the action requests and responses
+		// for replay.
+		Recorded bool
+		// Async is true if the action starts a job and responds with the 202 status code
+		// and the location of the job status endpoint, see JobMedia.
+		Async bool
+		// Resumable is true if the action creates uploads whose content is sent in chunks
+		// to the companion offset endpoints so that interrupted transfers may resume, see
+		// UploadPath.
+		Resumable bool
+		// BatchRoute is the route of the batch endpoint that accepts an array of payloads
+		// and fans out to the action, nil if the action has no batch endpoint.
+		BatchRoute *RouteDefinition
+		// Tenant binds the tenant identifier of the action if any, inherited from the
+		// resource.
+		Tenant *TenantDefinition
+		// Priority binds the priority level of the action requests if any, inherited from
+		// the resource.
+		Priority *PriorityDefinition
+		// Deprecation schedules the retirement of the action if any, inherited from the
+		// resource.
+		Deprecation *DeprecationDefinition
+		// Flag gates the action behind a feature flag if any, inherited from the resource.
+		Flag *FlagDefinition
+		// Middleware lists the names of the middleware applied to the action in order, the
+		// middleware of the resource come first.
+		Middleware []string
+		// Concurrency limits the number of requests handled concurrently by the action if
+		// any, inherited from the resource.
+		Concurrency *ConcurrencyDefinition
+		// ProxyURL is the URL of the upstream service requests are forwarded to if the
+		// action is a proxy, empty otherwise.
+		ProxyURL string
+		// CacheTTL is the duration the action responses are cached for, zero if the action
+		// is not cacheable.
+		CacheTTL time.Duration
+		// CacheKeys lists the names of the params and payload attributes that make up the
+		// cache key.
+		CacheKeys []string
+		// Coalesced is true if the concurrent identical requests made to the action are
+		// served by a single call to the action, see Coalesce.
+		Coalesced bool
+		// Topic is the name of the message broker topic the action payload is published to
+		// and consumed from if the action is an event, empty otherwise.
+		Topic string
+		// ViewParam is the name of the query string parameter that selects the view used to
+		// render the response media type if any.
+		ViewParam string
+		// ViewHeader is the name of the request header that selects the view used to render
+		// the response media type if any.
+		ViewHeader string
+		// FieldMask is the name of the query string parameter that lists the response media
+		// type fields rendered in the response if any.
+		FieldMask string
+		// OperationID is the identifier of the action used in generated API documents, e.g.
+		// the OpenAPI operationId. A default is computed by the generators if empty.
+		OperationID string
+		// Summary is the short summary of the action used in generated API documents.
+		Summary string
+		// HTTPExamples lists the request and response examples of the action.
+		HTTPExamples []*HTTPExampleDefinition
 	}
 
 	// FileServerDefinition defines an endpoint that servers static assets.
@@ -271,6 +609,9 @@ type (
 		Metadata dslengine.MetadataDefinition
 		// Security defines security requirements for the file server.
 		Security *SecurityDefinition
+		// Signed is true if the requests must be made to time-limited signed URLs, see
+		// SignedURL.
+		Signed bool
 	}
 
 	// LinkDefinition defines a media type link, it specifies a URL to a related resource.
@@ -309,6 +650,16 @@ type (
 		Parent *ActionDefinition
 		// Metadata is a list of key/value pairs
 		Metadata dslengine.MetadataDefinition
+		// OperationID overrides the parent action operation ID for this route.
+		OperationID string
+		// Summary overrides the parent action summary for this route.
+		Summary string
+		// ResponseDescriptions overrides the descriptions of the parent action responses
+		// for this route indexed by response name.
+		ResponseDescriptions map[string]string
+		// HTTPExamples lists the request and response examples of the route, they override
+		// the examples of the parent action with the same content type and response.
+		HTTPExamples []*HTTPExampleDefinition
 	}
 
 	// AttributeDefinition defines a JSON object member with optional description, default
@@ -328,8 +679,31 @@ type (
 		DefaultValue interface{}
 		// Optional member example value
 		Example interface{}
+		// Examples lists the named examples of the attribute in order of definition.
+		Examples []*ExampleDefinition
+		// ExampleGenerator generates the example of the attribute when Example is not set.
+		ExampleGenerator ExampleFunc
 		// Optional view used to render Attribute (only applies to media type attributes).
 		View string
+		// Sensitive is true if the attribute value must not appear in logs or documentation.
+		Sensitive bool
+		// ReadOnly is true if the attribute only appears in responses, it is ignored when
+		// decoding request payloads.
+		ReadOnly bool
+		// WriteOnly is true if the attribute only appears in requests, it is omitted from the
+		// rendered media types.
+		WriteOnly bool
+		// FieldNumber is the protocol buffers field number of the attribute, zero if not set.
+		FieldNumber int
+		// Position is the rank of the attribute among the child attributes of its parent in
+		// the order of definition, zero if the attribute was not defined with the DSL.
+		Position int
+		// Key is the name of the attribute in the request and response bodies, query strings
+		// and headers if different from the attribute name.
+		Key string
+		// Sanitizers lists the names of the sanitizers applied in order to the string value
+		// of the attribute when decoding requests, before the validations run.
+		Sanitizers []string
 		// NonZeroAttributes lists the names of the child attributes that cannot have a
 		// zero value (and thus whose presence does not need to be validated).
 		NonZeroAttributes map[string]bool
@@ -337,6 +711,26 @@ type (
 		DSLFunc func()
 	}
 
+	// ExampleDefinition is a named example of an attribute value.
+	ExampleDefinition struct {
+		// Summary is the name of the example.
+		Summary string
+		// Value is the example value.
+		Value interface{}
+	}
+
+	// HTTPExampleDefinition is a pair of request and response examples for a content type.
+	HTTPExampleDefinition struct {
+		// ContentType is the content type of the request and response bodies.
+		ContentType string
+		// Request is the example request body, nil if the request has no body.
+		Request interface{}
+		// Response is the name of the action response, e.g. "OK".
+		Response string
+		// Body is the example response body, nil if the response has no body.
+		Body interface{}
+	}
+
 	// ContainerDefinition defines a generic container definition that contains attributes.
 	// This makes it possible for plugins to use attributes in their own data structures.
 	ContainerDefinition interface {
@@ -590,20 +984,44 @@ func (a *APIDefinition) IterateResponses(it ResponseIterator) error {
 	return nil
 }
 
-// RandomGenerator is seeded after the API name. It's used to generate examples.
+// RecursionDepth returns the maximum number of levels a recursive user type or media type is
+// expanded to in the generated examples, DefaultMaxRecursionDepth unless set with the
+// MaxRecursionDepth DSL.
+func (a *APIDefinition) RecursionDepth() int {
+	if a == nil || a.MaxRecursionDepth < 1 {
+		return DefaultMaxRecursionDepth
+	}
+	return a.MaxRecursionDepth
+}
+
+// RandomGenerator returns the random generator used to generate examples. It is seeded after
+// ExampleSeed if set, after the API name otherwise.
 func (a *APIDefinition) RandomGenerator() *RandomGenerator {
 	if a.rand == nil {
-		a.rand = NewRandomGenerator(a.Name)
+		seed := a.ExampleSeed
+		if seed == "" {
+			seed = a.Name
+		}
+		a.rand = NewRandomGenerator(seed)
 	}
 	return a.rand
 }
 
+// SetRandomGenerator sets the random generator used to generate the examples, this makes it
+// possible for tools to use their own seed regardless of the design.
+func (a *APIDefinition) SetRandomGenerator(r *RandomGenerator) {
+	a.rand = r
+}
+
 // MediaTypeWithIdentifier returns the media type with a matching
 // media type identifier. Two media type identifiers match if their
 // values sans suffix match. So for example "application/vnd.foo+xml",
 // "application/vnd.foo+json" and "application/vnd.foo" all match.
 func (a *APIDefinition) MediaTypeWithIdentifier(id string) *MediaTypeDefinition {
 	canonicalID := CanonicalIdentifier(id)
+	if mt, ok := a.MediaTypes[canonicalID]; ok {
+		return mt
+	}
 	for _, mt := range a.MediaTypes {
 		if canonicalID == CanonicalIdentifier(mt.Identifier) {
 			return mt
@@ -651,13 +1069,108 @@ func (a *APIDefinition) IterateResources(it ResourceIterator) error {
 	return nil
 }
 
+// SelectResources returns a copy of the API definition that only contains the resources with the
+// given names so that the generators may produce the code of a subset of the API. It returns the
+// API definition itself if names is empty and an error if the API does not define one of the
+// resources.
+func (a *APIDefinition) SelectResources(names []string) (*APIDefinition, error) {
+	if len(names) == 0 {
+		return a, nil
+	}
+	res := make(map[string]*ResourceDefinition, len(names))
+	for _, n := range names {
+		r, ok := a.Resources[n]
+		if !ok {
+			return nil, fmt.Errorf("API %s does not define the resource %s", a.Name, n)
+		}
+		res[n] = r
+	}
+	api := *a
+	api.Resources = res
+	return &api, nil
+}
+
+// HasSPIFFESecurity returns true if the API defines a SPIFFE security scheme.
+func (a *APIDefinition) HasSPIFFESecurity() bool {
+	for _, s := range a.SecuritySchemes {
+		if s.Kind == SPIFFESecurityKind {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCSRF returns true if any of the API resources is protected against cross-site request
+// forgery.
+func (a *APIDefinition) HasCSRF() bool {
+	for _, r := range a.Resources {
+		if r.CSRF {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthChecks returns the distinct health check paths of the API servers in the order the
+// servers are defined.
+func (a *APIDefinition) HealthChecks() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, s := range a.Servers {
+		if s.HealthCheck == "" || seen[s.HealthCheck] {
+			continue
+		}
+		seen[s.HealthCheck] = true
+		paths = append(paths, s.HealthCheck)
+	}
+	return paths
+}
+
+// SensitiveNames returns the sorted names of the params and headers marked as sensitive in the API
+// design. The sensitive attributes of the payloads and media types are scoped to their types, see
+// AttributeDefinition.SensitiveFields.
+func (a *APIDefinition) SensitiveNames() []string {
+	names := make(map[string]bool)
+	collect := func(att *AttributeDefinition) {
+		if att == nil || att.Type == nil || !att.Type.IsObject() {
+			return
+		}
+		for n, child := range att.Type.ToObject() {
+			if child.Sensitive {
+				names[n] = true
+			}
+		}
+	}
+	collect(a.Params)
+	for _, r := range a.Resources {
+		collect(r.Params)
+		collect(r.Headers)
+		for _, act := range r.Actions {
+			collect(act.Params)
+			collect(act.Headers)
+			for _, resp := range act.Responses {
+				collect(resp.Headers)
+			}
+		}
+	}
+	res := make([]string, len(names))
+	i := 0
+	for n := range names {
+		res[i] = n
+		i++
+	}
+	sort.Strings(res)
+	return res
+}
+
 // DSL returns the initialization DSL.
 func (a *APIDefinition) DSL() func() {
 	return a.DSLFunc
 }
 
 // Finalize sets the Consumes and Produces fields to the defaults if empty.
-// Also it records built-in media types that are used by the user design.
+// Also it records built-in media types that are used by the user design and sets the Go type
+// of the decimal attributes of the user types.
 func (a *APIDefinition) Finalize() {
 	if len(a.Consumes) == 0 {
 		a.Consumes = DefaultDecoders
@@ -665,6 +1178,19 @@ func (a *APIDefinition) Finalize() {
 	if len(a.Produces) == 0 {
 		a.Produces = DefaultEncoders
 	}
+	a.IterateUserTypes(func(u *UserTypeDefinition) error {
+		finalizeDecimals(u.AttributeDefinition)
+		return nil
+	})
+	if a.HasAsyncActions() {
+		if a.JobsPath == "" {
+			a.JobsPath = DefaultJobsPath
+		}
+		if a.MediaTypes == nil {
+			a.MediaTypes = make(map[string]*MediaTypeDefinition)
+		}
+		a.MediaTypes[CanonicalIdentifier(JobMediaIdentifier)] = JobMedia
+	}
 	a.IterateResources(func(r *ResourceDefinition) error {
 		returnsError := func(resp *ResponseDefinition) bool {
 			if resp.MediaType == ErrorMediaIdentifier {
@@ -697,6 +1223,76 @@ func (a *APIDefinition) Finalize() {
 	})
 }
 
+// HasAsyncActions returns true if at least one action of the API is asynchronous.
+func (a *APIDefinition) HasAsyncActions() bool {
+	for _, r := range a.Resources {
+		for _, action := range r.Actions {
+			if action.Async {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasResumableActions returns true if at least one action of the API creates resumable uploads.
+func (a *APIDefinition) HasResumableActions() bool {
+	for _, r := range a.Resources {
+		for _, action := range r.Actions {
+			if action.Resumable {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasRecordedActions returns true if at least one action of the API records its requests, see
+// Record.
+func (a *APIDefinition) HasRecordedActions() bool {
+	for _, r := range a.Resources {
+		for _, action := range r.Actions {
+			if action.Recorded {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HeaderGroups returns the header groups defined by the actions and responses of the API sorted
+// by name. The groups that share the same name are only returned once.
+func (a *APIDefinition) HeaderGroups() []*HeaderGroupDefinition {
+	index := make(map[string]*HeaderGroupDefinition)
+	add := func(groups []*HeaderGroupDefinition) {
+		for _, g := range groups {
+			if _, ok := index[g.Name]; !ok {
+				index[g.Name] = g
+			}
+		}
+	}
+	for _, r := range a.Resources {
+		for _, action := range r.Actions {
+			add(action.HeaderGroups)
+			for _, resp := range action.Responses {
+				add(resp.HeaderGroups)
+			}
+		}
+	}
+	groups := make([]*HeaderGroupDefinition, 0, len(index))
+	for _, g := range index {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// JobStatusPath returns the route path of the job status endpoint, the job identifier is
+// captured by the "jobID" wildcard.
+func (a *APIDefinition) JobStatusPath() string {
+	return path.Join(a.JobsPath, ":jobID")
+}
+
 // NewResourceDefinition creates a resource definition but does not
 // execute the DSL.
 func NewResourceDefinition(name string, dsl func()) *ResourceDefinition {
@@ -812,6 +1408,18 @@ func (r *ResourceDefinition) FullPath() string {
 	return httppath.Clean(path.Join(basePath, r.BasePath))
 }
 
+// CSRFTokenFullPath returns the full path of the endpoint that issues CSRF tokens, the empty
+// string if the resource does not define one.
+func (r *ResourceDefinition) CSRFTokenFullPath() string {
+	if !r.CSRF || r.CSRFTokenPath == "" {
+		return ""
+	}
+	if strings.HasPrefix(r.CSRFTokenPath, "//") {
+		return httppath.Clean(r.CSRFTokenPath[1:])
+	}
+	return httppath.Clean(path.Join(r.FullPath(), r.CSRFTokenPath))
+}
+
 // Parent returns the parent resource if any, nil otherwise.
 func (r *ResourceDefinition) Parent() *ResourceDefinition {
 	if r.ParentName != "" {
@@ -846,11 +1454,45 @@ func (r *ResourceDefinition) AllOrigins() []*CORSDefinition {
 	return cors
 }
 
+// AllMiddleware returns the names of the middleware applied to the resource or to any of its
+// actions, the resource middleware first followed by the action middleware in action name order.
+func (r *ResourceDefinition) AllMiddleware() []string {
+	names := r.Middleware
+	r.IterateActions(func(a *ActionDefinition) error {
+		names = mergeMiddleware(names, a.Middleware)
+		return nil
+	})
+	return names
+}
+
+// mergeMiddleware returns the middleware names listed in first followed by the names listed in
+// second that are not in first.
+func mergeMiddleware(first, second []string) []string {
+	res := append([]string(nil), first...)
+	for _, n := range second {
+		found := false
+		for _, e := range res {
+			if e == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
 // PreflightPaths returns the paths that should handle OPTIONS requests.
 func (r *ResourceDefinition) PreflightPaths() []string {
 	var paths []string
 	r.IterateActions(func(a *ActionDefinition) error {
-		for _, r := range a.Routes {
+		routes := a.Routes
+		if a.BatchRoute != nil {
+			routes = append(routes[:len(routes):len(routes)], a.BatchRoute)
+		}
+		for _, r := range routes {
 			if r.Verb == "OPTIONS" {
 				continue
 			}
@@ -1060,6 +1702,88 @@ func (a *AttributeDefinition) IsPrimitivePointer(attName string) bool {
 	return false
 }
 
+// AttributeKey returns the name of the attribute in the request and response bodies, query
+// strings and headers given the name of the attribute in its parent: the key set with the Key
+// DSL if any, the name otherwise.
+func (a *AttributeDefinition) AttributeKey(name string) string {
+	if a.Key != "" {
+		return a.Key
+	}
+	return name
+}
+
+// SensitiveFields returns the sorted keys of the attributes of the type of a marked as sensitive.
+// The keys of the attributes of inline objects are prefixed with the key of their parent and a
+// dot, e.g. "credentials.password". The attributes of the user types and media types referenced by
+// a are not listed, they are scoped to these types.
+func (a *AttributeDefinition) SensitiveFields() []string {
+	var fields []string
+	var collect func(string, *AttributeDefinition, bool)
+	collect = func(prefix string, att *AttributeDefinition, root bool) {
+		if att == nil || att.Type == nil {
+			return
+		}
+		if !root {
+			switch att.Type.(type) {
+			case *UserTypeDefinition, *MediaTypeDefinition:
+				return
+			}
+		}
+		switch {
+		case att.Type.IsObject():
+			for n, child := range att.Type.ToObject() {
+				key := prefix + child.AttributeKey(n)
+				if child.Sensitive {
+					fields = append(fields, key)
+					continue
+				}
+				collect(key+".", child, false)
+			}
+		case att.Type.IsArray():
+			collect(prefix, att.Type.ToArray().ElemType, false)
+		case att.Type.IsHash():
+			collect(prefix, att.Type.ToHash().ElemType, false)
+		}
+	}
+	collect("", a, true)
+	sort.Strings(fields)
+	return fields
+}
+
+// IsValueField returns true if the field generated for the given attribute in the private
+// struct used to decode request bodies holds the value together with its presence instead of a
+// pointer to it as requested with the "struct:field:value" metadata. Only the required boolean,
+// integer, number, string and date time attributes without default value or enum validation use
+// value fields. The target attribute must be an object.
+func (a *AttributeDefinition) IsValueField(attName string) bool {
+	if !a.Type.IsObject() {
+		panic("checking value field on non-object") // bug
+	}
+	att := a.Type.ToObject()[attName]
+	if att == nil || !att.Type.IsPrimitive() || att.ReadOnly {
+		return false
+	}
+	switch att.Type.Kind() {
+	case BooleanKind, IntegerKind, NumberKind, StringKind, DateTimeKind:
+	default:
+		return false
+	}
+	if !a.IsRequired(attName) || a.HasDefaultValue(attName) {
+		return false
+	}
+	if att.Validation != nil && len(att.Validation.Values) > 0 {
+		return false
+	}
+	if _, ok := att.Metadata["struct:field:type"]; ok {
+		return false
+	}
+	_, ok := att.Metadata["struct:field:value"]
+	if !ok {
+		_, ok = a.Metadata["struct:field:value"]
+	}
+	return ok
+}
+
 // IsInterface returns true if the field generated for the given attribute has
 // an interface type that should not be referenced as a "*interface{}" pointer.
 // The target attribute must be an object.
@@ -1086,6 +1810,26 @@ func (a *AttributeDefinition) IsFile(attName string) bool {
 	return att.Type.Kind() == FileKind
 }
 
+// ParamSeparator returns the separator of the values of the array query string parameter
+// defined by a as specified with the "param:style" and "param:explode" metadata. It returns an
+// empty string if each value is sent in its own key=value pair.
+func (a *AttributeDefinition) ParamSeparator() string {
+	style := FormStyle
+	if s := a.Metadata["param:style"]; len(s) > 0 {
+		style = s[0]
+	}
+	switch style {
+	case PipeDelimitedStyle:
+		return "|"
+	case SpaceDelimitedStyle:
+		return " "
+	}
+	if e := a.Metadata["param:explode"]; len(e) > 0 && e[0] == "false" {
+		return ","
+	}
+	return ""
+}
+
 // SetExample sets the custom example. SetExample also handles the case when the user doesn't
 // want any example or any auto-generated example.
 func (a *AttributeDefinition) SetExample(example interface{}) bool {
@@ -1100,6 +1844,20 @@ func (a *AttributeDefinition) SetExample(example interface{}) bool {
 	return false
 }
 
+// AddExample adds a named example to the attribute. The first named example also becomes the
+// attribute example unless one is already set. AddExample returns false if the value is not
+// compatible with the attribute type.
+func (a *AttributeDefinition) AddExample(summary string, example interface{}) bool {
+	if a.Type != nil && !a.Type.IsCompatible(example) {
+		return false
+	}
+	a.Examples = append(a.Examples, &ExampleDefinition{Summary: summary, Value: example})
+	if a.Example == nil {
+		a.Example = example
+	}
+	return true
+}
+
 // GenerateExample returns the value of the Example field if not nil. Otherwise it traverses the
 // attribute type and recursively generates an example. The result is saved in the Example field.
 func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []string) interface{} {
@@ -1109,6 +1867,10 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 	if Design.NoExamples {
 		return nil
 	}
+	if a.ExampleGenerator != nil {
+		a.Example = a.ExampleGenerator(rand)
+		return a.Example
+	}
 
 	// Avoid infinite loops
 	var key string
@@ -1124,8 +1886,7 @@ func (a *AttributeDefinition) GenerateExample(rand *RandomGenerator, seen []stri
 				count++
 			}
 		}
-		if count > 1 {
-			// Only go a couple of levels deep
+		if count >= Design.RecursionDepth() {
 			return nil
 		}
 		seen = append(seen, key)
@@ -1209,8 +1970,11 @@ func (a *AttributeDefinition) objectExample(rand *RandomGenerator, seen []string
 	res := make(map[string]interface{})
 	for _, n := range keys {
 		att := aObj[n]
+		if att.Example == nil {
+			att.Example = att.realisticExample(n, rand)
+		}
 		if ex := att.GenerateExample(rand, seen); ex != nil {
-			res[n] = ex
+			res[att.AttributeKey(n)] = ex
 		}
 	}
 	if len(res) > 0 {
@@ -1305,6 +2069,30 @@ func (a *AttributeDefinition) inheritRecursive(parent *AttributeDefinition, seen
 			if att.Example == nil {
 				att.Example = patt.Example
 			}
+			if att.Examples == nil {
+				att.Examples = patt.Examples
+			}
+			if att.ExampleGenerator == nil {
+				att.ExampleGenerator = patt.ExampleGenerator
+			}
+			if patt.Sensitive {
+				att.Sensitive = true
+			}
+			if patt.ReadOnly {
+				att.ReadOnly = true
+			}
+			if patt.WriteOnly {
+				att.WriteOnly = true
+			}
+			if att.FieldNumber == 0 {
+				att.FieldNumber = patt.FieldNumber
+			}
+			if att.Key == "" {
+				att.Key = patt.Key
+			}
+			if att.Sanitizers == nil {
+				att.Sanitizers = patt.Sanitizers
+			}
 		}
 	}
 }
@@ -1340,11 +2128,40 @@ func (l *LicenseDefinition) Context() string {
 	return "unnamed license"
 }
 
+// Context returns the generic definition name used in error messages.
+func (e *ErrorEnvelopeDefinition) Context() string {
+	return fmt.Sprintf("error envelope of %s", Design.Name)
+}
+
 // Context returns the generic definition name used in error messages.
 func (d *DocsDefinition) Context() string {
 	return fmt.Sprintf("documentation for %s", Design.Name)
 }
 
+// Context returns the generic definition name used in error messages.
+func (t *TagDefinition) Context() string {
+	return fmt.Sprintf("tag %#v", t.Name)
+}
+
+// Context returns the generic definition name used in error messages.
+func (s *ServerDefinition) Context() string {
+	return fmt.Sprintf("server %#v", s.Name)
+}
+
+// ResolveHost returns the server host where the variables are replaced with the given values or
+// their default values if vars has no value for them.
+func (s *ServerDefinition) ResolveHost(vars map[string]string) string {
+	host := s.Host
+	for _, v := range s.Variables {
+		val, ok := vars[v.Name]
+		if !ok {
+			val = v.Default
+		}
+		host = strings.Replace(host, "{"+v.Name+"}", val, -1)
+	}
+	return host
+}
+
 // Context returns the generic definition name used in error messages.
 func (t *UserTypeDefinition) Context() string {
 	if t.TypeName != "" {
@@ -1372,9 +2189,58 @@ func (r *ResponseDefinition) Context() string {
 	return prefix + suffix
 }
 
+// Context returns the generic definition name used in error messages.
+func (p *PartDefinition) Context() string {
+	suffix := ""
+	if p.Parent != nil {
+		suffix = " of " + p.Parent.Context()
+	}
+	return fmt.Sprintf("part %#v%s", p.Name, suffix)
+}
+
+// Context returns the generic definition name used in error messages.
+func (g *HeaderGroupDefinition) Context() string {
+	suffix := ""
+	if g.Parent != nil {
+		suffix = " of " + g.Parent.Context()
+	}
+	return fmt.Sprintf("header group %#v%s", g.Name, suffix)
+}
+
+// Context returns the generic definition name used in error messages.
+func (q *QueryObjectDefinition) Context() string {
+	suffix := ""
+	if q.Parent != nil {
+		suffix = " of " + q.Parent.Context()
+	}
+	return fmt.Sprintf("query object %#v%s", q.Name, suffix)
+}
+
+// Context returns the generic definition name used in error messages.
+func (v *SchemaVersionDefinition) Context() string {
+	suffix := ""
+	if v.Parent != nil {
+		suffix = " of " + v.Parent.Context()
+	}
+	return fmt.Sprintf("schema version %#v%s", v.Version, suffix)
+}
+
+// HeaderKey returns the canonical name of the header mapped to the attribute of the group type
+// with the given name.
+func (g *HeaderGroupDefinition) HeaderKey(attName string) string {
+	key := attName
+	if att, ok := g.Type.ToObject()[attName]; ok {
+		key = att.AttributeKey(attName)
+	}
+	return http.CanonicalHeaderKey(g.Prefix + key)
+}
+
 // Finalize sets the response media type from its type if the type is a media type and no media
 // type is already specified.
 func (r *ResponseDefinition) Finalize() {
+	if r.NoBody {
+		return
+	}
 	if r.Type == nil {
 		return
 	}
@@ -1391,15 +2257,41 @@ func (r *ResponseDefinition) Finalize() {
 // Dup returns a copy of the response definition.
 func (r *ResponseDefinition) Dup() *ResponseDefinition {
 	res := ResponseDefinition{
-		Name:        r.Name,
-		Status:      r.Status,
-		Description: r.Description,
-		MediaType:   r.MediaType,
-		ViewName:    r.ViewName,
+		Name:              r.Name,
+		Status:            r.Status,
+		Description:       r.Description,
+		MediaType:         r.MediaType,
+		ViewName:          r.ViewName,
+		TagAttribute:      r.TagAttribute,
+		TagValue:          r.TagValue,
+		Redirect:          r.Redirect,
+		LocationAttribute: r.LocationAttribute,
+		NoBody:            r.NoBody,
 	}
 	if r.Headers != nil {
 		res.Headers = DupAtt(r.Headers)
 	}
+	for _, p := range r.Parts {
+		dp := *p
+		dp.Parent = &res
+		res.Parts = append(res.Parts, &dp)
+	}
+	for _, g := range r.HeaderGroups {
+		dg := *g
+		dg.Parent = &res
+		res.HeaderGroups = append(res.HeaderGroups, &dg)
+	}
+	if r.Trailers != nil {
+		res.Trailers = make(map[string]string, len(r.Trailers))
+		for n, att := range r.Trailers {
+			res.Trailers[n] = att
+		}
+	}
+	for _, v := range r.Versions {
+		dv := *v
+		dv.Parent = &res
+		res.Versions = append(res.Versions, &dv)
+	}
 	return &res
 }
 
@@ -1417,10 +2309,26 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 	if r.Description == "" {
 		r.Description = other.Description
 	}
-	if r.MediaType == "" {
+	if r.MediaType == "" && !r.NoBody {
 		r.MediaType = other.MediaType
 		r.ViewName = other.ViewName
 	}
+	if r.TagAttribute == "" {
+		r.TagAttribute = other.TagAttribute
+		r.TagValue = other.TagValue
+	}
+	if !r.Redirect {
+		r.Redirect = other.Redirect
+	}
+	if r.LocationAttribute == "" {
+		r.LocationAttribute = other.LocationAttribute
+	}
+	if r.Trailers == nil && other.Trailers != nil {
+		r.Trailers = make(map[string]string, len(other.Trailers))
+		for n, att := range other.Trailers {
+			r.Trailers[n] = att
+		}
+	}
 	if other.Headers != nil {
 		otherHeaders := other.Headers.Type.ToObject()
 		if len(otherHeaders) > 0 {
@@ -1435,6 +2343,27 @@ func (r *ResponseDefinition) Merge(other *ResponseDefinition) {
 			}
 		}
 	}
+	for _, g := range other.HeaderGroups {
+		found := false
+		for _, rg := range r.HeaderGroups {
+			if rg.Name == g.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dg := *g
+			dg.Parent = r
+			r.HeaderGroups = append(r.HeaderGroups, &dg)
+		}
+	}
+	if len(r.Versions) == 0 {
+		for _, v := range other.Versions {
+			dv := *v
+			dv.Parent = r
+			r.Versions = append(r.Versions, &dv)
+		}
+	}
 }
 
 // Context returns the generic definition name used in error messages.
@@ -1557,7 +2486,60 @@ func (a *ActionDefinition) WebSocket() bool {
 	return true
 }
 
-// Finalize inherits security scheme and action responses from parent and top level design.
+// TenantInPath returns true if the action binds a tenant identifier captured by one of its routes,
+// false if the identifier is read from a header or if the action binds none.
+func (a *ActionDefinition) TenantInPath() bool {
+	if a.Tenant == nil {
+		return false
+	}
+	for _, r := range a.Routes {
+		for _, wc := range r.Params() {
+			if wc == a.Tenant.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UploadPath returns the route path of the endpoints that report the offset of the uploads created
+// by a resumable action and that receive their chunks. The path is the full path of the first
+// action route followed by the "uploadID" wildcard.
+func (a *ActionDefinition) UploadPath() string {
+	if len(a.Routes) == 0 {
+		return ""
+	}
+	return path.Join(a.Routes[0].FullPath(), ":uploadID")
+}
+
+// IsRaw returns true if the action handler is given the raw request, see the "http:raw"
+// metadata.
+func (a *ActionDefinition) IsRaw() bool {
+	_, ok := a.Metadata["http:raw"]
+	return ok
+}
+
+// IsLazyBody returns true if the decoding of the action request body is deferred until the
+// action handler runs, see the "http:body:lazy" metadata. The body of the actions secured with a
+// HMAC scheme is always decoded lazily so that the security middleware can read the raw body to
+// verify its signature.
+func (a *ActionDefinition) IsLazyBody() bool {
+	if _, ok := a.Metadata["http:body:lazy"]; ok {
+		return true
+	}
+	if a.Security != nil && a.Security.Scheme != nil && a.Security.Scheme.Kind == HMACSecurityKind {
+		return true
+	}
+	if a.Parent != nil {
+		if _, ok := a.Parent.Metadata["http:body:lazy"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Finalize inherits security scheme, request log sampling and action responses from parent and
+// top level design.
 func (a *ActionDefinition) Finalize() {
 	// Inherit security scheme
 	if a.Security == nil {
@@ -1571,15 +2553,104 @@ func (a *ActionDefinition) Finalize() {
 		a.Security = nil
 	}
 
+	// Inherit request log sampling
+	if a.LogSampling == nil {
+		a.LogSampling = a.Parent.LogSampling
+		if a.LogSampling == nil {
+			a.LogSampling = Design.LogSampling
+		}
+	}
+
+	// Inherit client closed request logging
+	if a.ClientClosedStatus == nil {
+		a.ClientClosedStatus = a.Parent.ClientClosedStatus
+		if a.ClientClosedStatus == nil {
+			a.ClientClosedStatus = Design.ClientClosedStatus
+		}
+	}
+
+	// Inherit auditing
+	if a.Parent.Audited {
+		a.Audited = true
+	}
+
+	// Inherit recording
+	if a.Parent.Recorded {
+		a.Recorded = true
+	}
+
 	if a.Payload != nil {
 		a.Payload.Finalize()
 	}
 
+	// Inherit tenant binding
+	if a.Tenant == nil {
+		a.Tenant = a.Parent.Tenant
+	}
+
+	// Inherit priority binding
+	if a.Priority == nil {
+		a.Priority = a.Parent.Priority
+	}
+
+	// Inherit deprecation
+	if a.Deprecation == nil {
+		a.Deprecation = a.Parent.Deprecation
+	}
+
+	// Inherit feature flag
+	if a.Flag == nil {
+		a.Flag = a.Parent.Flag
+	}
+
+	// Inherit concurrency limit
+	if a.Concurrency == nil {
+		a.Concurrency = a.Parent.Concurrency
+	}
+
+	// Inherit middleware
+	if len(a.Parent.Middleware) > 0 {
+		a.Middleware = mergeMiddleware(a.Parent.Middleware, a.Middleware)
+	}
+
+	a.initAsync()
+	a.initResumable()
 	a.mergeResponses()
+	a.initTenant()
+	a.initPriority()
 	a.initImplicitParams()
+	a.initViewSelector()
+	a.initFieldMask()
 	a.initQueryParams()
 }
 
+// ResponseViews returns the sorted names of the views of the action response media types that may
+// be selected at runtime, that is of the responses that do not define a view.
+func (a *ActionDefinition) ResponseViews() []string {
+	seen := make(map[string]bool)
+	var views []string
+	for _, r := range a.Responses {
+		if r.ViewName != "" {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		for n := range mt.Views {
+			if !seen[n] {
+				seen[n] = true
+				views = append(views, n)
+			}
+		}
+	}
+	sort.Strings(views)
+	return views
+}
+
 // UserTypes returns all the user types used by the action payload and parameters.
 func (a *ActionDefinition) UserTypes() map[string]*UserTypeDefinition {
 	types := make(map[string]*UserTypeDefinition)
@@ -1662,6 +2733,140 @@ func (a *ActionDefinition) mergeResponses() {
 	}
 }
 
+// initAsync adds the 202 response of asynchronous actions unless defined explicitly. The response
+// body is the job status rendered with JobMedia and the Location header is set from its href
+// attribute which points to the job status endpoint.
+func (a *ActionDefinition) initAsync() {
+	if !a.Async {
+		return
+	}
+	if _, ok := a.Responses[Accepted]; ok {
+		return
+	}
+	if a.Responses == nil {
+		a.Responses = make(map[string]*ResponseDefinition)
+	}
+	a.Responses[Accepted] = &ResponseDefinition{
+		Name:      Accepted,
+		Status:    202,
+		MediaType: JobMediaIdentifier,
+		Headers: &AttributeDefinition{
+			Type: Object{"Location": &AttributeDefinition{
+				Type:        String,
+				Description: "Path of the job status endpoint",
+			}},
+			Validation: &dslengine.ValidationDefinition{Required: []string{"Location"}},
+		},
+		LocationAttribute: "href",
+		Parent:            a,
+	}
+}
+
+// initResumable adds the required Upload-Length header that gives the total size of the upload
+// to resumable actions as well as the 201 response unless defined explicitly. The Location header
+// of the response contains the path of the upload offset endpoints, see UploadPath.
+func (a *ActionDefinition) initResumable() {
+	if !a.Resumable {
+		return
+	}
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	min := 0.0
+	a.Headers.Type.ToObject()["Upload-Length"] = &AttributeDefinition{
+		Type:        Integer,
+		Description: "Total size of the upload in bytes",
+		Validation:  &dslengine.ValidationDefinition{Minimum: &min},
+	}
+	if a.Headers.Validation == nil {
+		a.Headers.Validation = &dslengine.ValidationDefinition{}
+	}
+	a.Headers.Validation.AddRequired([]string{"Upload-Length"})
+	if _, ok := a.Responses[Created]; ok {
+		return
+	}
+	if a.Responses == nil {
+		a.Responses = make(map[string]*ResponseDefinition)
+	}
+	a.Responses[Created] = &ResponseDefinition{
+		Name:   Created,
+		Status: 201,
+		Headers: &AttributeDefinition{
+			Type: Object{"Location": &AttributeDefinition{
+				Type:        String,
+				Description: "Path of the upload offset endpoints",
+			}},
+			Validation: &dslengine.ValidationDefinition{Required: []string{"Location"}},
+		},
+		Parent: a,
+	}
+}
+
+// initTenant adds the tenant identifier to the action path parameters if one of the action routes
+// captures it, to the required action headers otherwise.
+func (a *ActionDefinition) initTenant() {
+	t := a.Tenant
+	if t == nil {
+		return
+	}
+	if a.TenantInPath() {
+		if a.Params == nil {
+			a.Params = &AttributeDefinition{Type: Object{}}
+		}
+		a.Params.Type.ToObject()[t.Name] = DupAtt(t.Attribute)
+		return
+	}
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	a.Headers.Type.ToObject()[t.Name] = DupAtt(t.Attribute)
+	if a.Headers.Validation == nil {
+		a.Headers.Validation = &dslengine.ValidationDefinition{}
+	}
+	a.Headers.Validation.AddRequired([]string{t.Name})
+}
+
+// initPriority adds the priority header to the action headers.
+func (a *ActionDefinition) initPriority() {
+	p := a.Priority
+	if p == nil {
+		return
+	}
+	if a.Headers == nil {
+		a.Headers = &AttributeDefinition{Type: Object{}}
+	}
+	a.Headers.Type.ToObject()[p.Header] = DupAtt(p.Attribute)
+}
+
+// PriorityLevels returns the priority levels sorted by increasing threshold, that is from the
+// first shed to the last shed.
+func (p *PriorityDefinition) PriorityLevels() []string {
+	levels := make([]string, 0, len(p.Thresholds))
+	for l := range p.Thresholds {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		ti, tj := p.Thresholds[levels[i]], p.Thresholds[levels[j]]
+		if ti == tj {
+			return levels[i] < levels[j]
+		}
+		return ti < tj
+	})
+	return levels
+}
+
+// DefaultLevel returns the priority level of the requests that do not set the header, the
+// default value of the header attribute if any, the first shed level otherwise.
+func (p *PriorityDefinition) DefaultLevel() string {
+	if l, ok := p.Attribute.DefaultValue.(string); ok {
+		return l
+	}
+	if levels := p.PriorityLevels(); len(levels) > 0 {
+		return levels[0]
+	}
+	return ""
+}
+
 // initImplicitParams creates params for path segments that don't have one.
 func (a *ActionDefinition) initImplicitParams() {
 	for _, ro := range a.Routes {
@@ -1702,12 +2907,133 @@ func (a *ActionDefinition) initImplicitParams() {
 	}
 }
 
+// ResponseFields returns the sorted keys of the top level attributes of the action response media
+// types that may be selected with the field mask param. Write-only attributes are never rendered
+// and thus excluded.
+func (a *ActionDefinition) ResponseFields() []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, r := range a.Responses {
+		if r.Status >= 400 {
+			continue
+		}
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			continue
+		}
+		for n, v := range mt.Views {
+			if r.ViewName != "" && n != r.ViewName {
+				continue
+			}
+			for n, att := range v.Type.ToObject() {
+				if f := att.AttributeKey(n); !seen[f] && !att.WriteOnly {
+					seen[f] = true
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// initFieldMask defines the field mask param if the action has one. The param is an array of
+// strings serialized as a comma separated list whose values are validated against the response
+// fields.
+func (a *ActionDefinition) initFieldMask() {
+	if a.FieldMask == "" {
+		return
+	}
+	if a.Params == nil {
+		a.Params = &AttributeDefinition{Type: Object{}}
+	}
+	if _, ok := a.Params.Type.ToObject()[a.FieldMask]; ok {
+		return
+	}
+	fields := a.ResponseFields()
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = f
+	}
+	elem := &AttributeDefinition{
+		Type:       String,
+		Validation: &dslengine.ValidationDefinition{Values: values},
+	}
+	a.Params.Type.ToObject()[a.FieldMask] = &AttributeDefinition{
+		Type:        &Array{ElemType: elem},
+		Description: "Response fields to render, all if empty",
+		Metadata: dslengine.MetadataDefinition{
+			"param:style":   []string{FormStyle},
+			"param:explode": []string{"false"},
+		},
+	}
+}
+
+// initViewSelector defines the param or header that selects the response view if the action has
+// one. The attribute defaults to the default view and is validated against the response views.
+func (a *ActionDefinition) initViewSelector() {
+	var (
+		parent *AttributeDefinition
+		name   string
+	)
+	switch {
+	case a.ViewParam != "":
+		if a.Params == nil {
+			a.Params = &AttributeDefinition{Type: Object{}}
+		}
+		parent, name = a.Params, a.ViewParam
+	case a.ViewHeader != "":
+		if a.Headers == nil {
+			a.Headers = &AttributeDefinition{Type: Object{}}
+		}
+		parent, name = a.Headers, a.ViewHeader
+	default:
+		return
+	}
+	views := a.ResponseViews()
+	att, ok := parent.Type.ToObject()[name]
+	if !ok {
+		att = &AttributeDefinition{Type: String, Description: "View used to render the response"}
+		parent.Type.ToObject()[name] = att
+	}
+	if att.DefaultValue == nil {
+		for _, v := range views {
+			if v == DefaultView {
+				att.DefaultValue = DefaultView
+				break
+			}
+		}
+	}
+	if att.Validation == nil {
+		att.Validation = &dslengine.ValidationDefinition{}
+	}
+	if att.Validation.Values == nil {
+		att.Validation.Values = make([]interface{}, len(views))
+		for i, v := range views {
+			att.Validation.Values[i] = v
+		}
+	}
+}
+
 // initQueryParams extract the query parameters from the action params.
 func (a *ActionDefinition) initQueryParams() {
 	// 3. Compute QueryParams from Params and set all path params as non zero attributes
 	if params := a.AllParams(); params != nil {
+		// The query params share the attributes of the params, only the object listing them and
+		// the required names are copied as path params get removed from them.
 		queryParams := DupAtt(params)
-		queryParams.Type = Dup(queryParams.Type)
+		obj := params.Type.ToObject()
+		queryObj := make(Object, len(obj))
+		for n, att := range obj {
+			queryObj[n] = att
+		}
+		queryParams.Type = queryObj
+		if queryParams.Validation != nil {
+			queryParams.Validation.Required = append([]string(nil), queryParams.Validation.Required...)
+		}
 		if a.Params == nil {
 			a.Params = &AttributeDefinition{Type: Object{}}
 		}
@@ -1820,6 +3146,9 @@ func (v *ViewDefinition) Context() string {
 
 // Context returns the generic definition name used in error messages.
 func (r *RouteDefinition) Context() string {
+	if r.Parent == nil {
+		return fmt.Sprintf(`route %s "%s"`, r.Verb, r.Path)
+	}
 	return fmt.Sprintf(`route %s "%s" of %s`, r.Verb, r.Path, r.Parent.Context())
 }
 
@@ -1829,6 +3158,18 @@ func (r *RouteDefinition) Params() []string {
 	return ExtractWildcards(r.FullPath())
 }
 
+// CatchAll returns the name of the parameter that captures the remainder of the request path
+// including slashes, e.g. "path" for the route "GET /files/*path". It returns an empty string if the
+// route has no catch-all parameter.
+func (r *RouteDefinition) CatchAll() string {
+	full := r.FullPath()
+	i := strings.LastIndex(full, "/*")
+	if i < 0 || strings.Contains(full[i+2:], "/") {
+		return ""
+	}
+	return full[i+2:]
+}
+
 // FullPath returns the action full path computed by concatenating the API and resource base paths
 // with the action specific path.
 func (r *RouteDefinition) FullPath() string {
@@ -1849,6 +3190,28 @@ func (r *RouteDefinition) FullPath() string {
 	return httppath.Clean(joinedPath)
 }
 
+// AllHTTPExamples returns the request and response examples of the route merged with the examples
+// of the parent action, the route examples override the action examples with the same content type
+// and response.
+func (r *RouteDefinition) AllHTTPExamples() []*HTTPExampleDefinition {
+	var examples []*HTTPExampleDefinition
+	if r.Parent != nil {
+		for _, ex := range r.Parent.HTTPExamples {
+			overridden := false
+			for _, rex := range r.HTTPExamples {
+				if rex.ContentType == ex.ContentType && rex.Response == ex.Response {
+					overridden = true
+					break
+				}
+			}
+			if !overridden {
+				examples = append(examples, ex)
+			}
+		}
+	}
+	return append(examples, r.HTTPExamples...)
+}
+
 // IsAbsolute returns true if the action path should not be concatenated to the resource and API
 // base paths.
 func (r *RouteDefinition) IsAbsolute() bool {
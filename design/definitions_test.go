@@ -124,6 +124,62 @@ var _ = Describe("IsRequired", func() {
 	})
 })
 
+var _ = Describe("IsLazyBody", func() {
+	var action *design.ActionDefinition
+
+	BeforeEach(func() {
+		action = &design.ActionDefinition{Parent: &design.ResourceDefinition{}}
+	})
+
+	It("returns false by default", func() {
+		Ω(action.IsLazyBody()).Should(BeFalse())
+	})
+
+	It("returns true for actions with the http:body:lazy metadata", func() {
+		action.Metadata = dslengine.MetadataDefinition{"http:body:lazy": nil}
+		Ω(action.IsLazyBody()).Should(BeTrue())
+	})
+
+	It("returns true for actions secured with a HMAC scheme", func() {
+		action.Security = &design.SecurityDefinition{
+			Scheme: &design.SecuritySchemeDefinition{Kind: design.HMACSecurityKind},
+		}
+		Ω(action.IsLazyBody()).Should(BeTrue())
+	})
+})
+
+var _ = Describe("SensitiveFields", func() {
+	var att *design.AttributeDefinition
+
+	BeforeEach(func() {
+		credentials := &design.UserTypeDefinition{
+			TypeName: "Credentials",
+			AttributeDefinition: &design.AttributeDefinition{Type: design.Object{
+				"password": &design.AttributeDefinition{Type: design.String, Sensitive: true},
+			}},
+		}
+		att = &design.AttributeDefinition{Type: design.Object{
+			"login":  &design.AttributeDefinition{Type: design.String},
+			"secret": &design.AttributeDefinition{Type: design.String, Sensitive: true, Key: "x-secret"},
+			"options": &design.AttributeDefinition{Type: &design.Array{
+				ElemType: &design.AttributeDefinition{Type: design.Object{
+					"pin": &design.AttributeDefinition{Type: design.String, Sensitive: true},
+				}},
+			}},
+			"credentials": &design.AttributeDefinition{Type: credentials},
+		}}
+	})
+
+	It("lists the keys of the sensitive attributes of the type and of its inline objects", func() {
+		Ω(att.SensitiveFields()).Should(Equal([]string{"options.pin", "x-secret"}))
+	})
+
+	It("lists the sensitive attributes of the root user type", func() {
+		credentials := att.Type.ToObject()["credentials"]
+		Ω(credentials.SensitiveFields()).Should(Equal([]string{"password"}))
+	})
+})
+
 var _ = Describe("IterateHeaders", func() {
 	It("works when Parent.Headers is nil", func() {
 		// create a Resource with no headers, Action with one header
@@ -50,6 +50,15 @@ func (d *dupper) DupAttribute(att *AttributeDefinition) *AttributeDefinition {
 		View:              att.View,
 		DSLFunc:           att.DSLFunc,
 		Example:           att.Example,
+		Examples:          att.Examples,
+		ExampleGenerator:  att.ExampleGenerator,
+		Sensitive:         att.Sensitive,
+		ReadOnly:          att.ReadOnly,
+		WriteOnly:         att.WriteOnly,
+		FieldNumber:       att.FieldNumber,
+		Position:          att.Position,
+		Key:               att.Key,
+		Sanitizers:        att.Sanitizers,
 	}
 	return &dup
 }
@@ -91,6 +100,7 @@ func (d *dupper) DupType(t DataType) DataType {
 			Links:      actual.Links,
 			Views:      actual.Views,
 			Resource:   actual.Resource,
+			Diff:       actual.Diff,
 		}
 		d.dmts[actual.Identifier] = m
 		m.UserTypeDefinition = d.DupUserType(actual.UserTypeDefinition)
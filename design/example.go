@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"strings"
 	"time"
-
-	regen "github.com/zach-klippenstein/goregen"
+	"unicode"
+	"unicode/utf8"
 )
 
 // exampleGenerator generates a random example based on the given validations on the definition.
@@ -54,7 +55,8 @@ func (eg *exampleGenerator) Generate(seen []string) interface{} {
 		if hasMinMax {
 			if example == nil {
 				example = eg.generateValidatedMinMaxValueExample()
-			} else if !eg.checkMinMaxValueValidation(example) {
+			}
+			if !eg.checkMinMaxValueValidation(example) {
 				continue
 			}
 		}
@@ -149,6 +151,18 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 		return nil
 	}
 	format := eg.a.Validation.Format
+	if fn, ok := formatExamples[format]; ok {
+		return fn(eg.r)
+	}
+	if format == "decimal" {
+		// the pattern enforces the precision and scale if any
+		if eg.hasPatternValidation() {
+			if res, err := eg.r.regexp(eg.a.Validation.Pattern); err == nil {
+				return res
+			}
+		}
+		return eg.r.Decimal()
+	}
 	if res, ok := map[string]interface{}{
 		"email":     eg.r.faker.Email(),
 		"hostname":  eg.r.faker.DomainName() + "." + eg.r.faker.DomainSuffix(),
@@ -158,7 +172,7 @@ func (eg *exampleGenerator) generateFormatExample() interface{} {
 		"ip":        eg.r.faker.IPv4Address().String(),
 		"uri":       eg.r.faker.URL(),
 		"mac": func() string {
-			res, err := regen.Generate(`([0-9A-F]{2}-){5}[0-9A-F]{2}`)
+			res, err := eg.r.regexp(`([0-9A-F]{2}-){5}[0-9A-F]{2}`)
 			if err != nil {
 				return "12-34-56-78-9A-BC"
 			}
@@ -199,7 +213,7 @@ func (eg *exampleGenerator) generateValidatedPatternExample() interface{} {
 		return false
 	}
 	pattern := eg.a.Validation.Pattern
-	example, err := regen.Generate(pattern)
+	example, err := eg.r.regexp(pattern)
 	if err != nil {
 		return eg.r.faker.Name()
 	}
@@ -210,28 +224,37 @@ func (eg *exampleGenerator) hasMinMaxValidation() bool {
 	if eg.a.Validation == nil {
 		return false
 	}
-	return eg.a.Validation.Minimum != nil || eg.a.Validation.Maximum != nil
+	v := eg.a.Validation
+	return v.Minimum != nil || v.Maximum != nil ||
+		v.ExclusiveMinimum != nil || v.ExclusiveMaximum != nil || v.MultipleOf != nil
 }
 
 func (eg *exampleGenerator) checkMinMaxValueValidation(example interface{}) bool {
 	if !eg.hasMinMaxValidation() {
 		return true
 	}
-	valid := true
-	if min := eg.a.Validation.Minimum; min != nil {
-		if v, ok := example.(int); ok && float64(v) < *min {
-			valid = false
-		} else if v, ok := example.(float64); ok && v < *min {
-			valid = false
-		}
+	var f float64
+	switch v := example.(type) {
+	case int:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return true
 	}
-	if !valid {
-		return false
+	if min, exclusive := eg.a.Validation.LowerBound(); min != nil {
+		if f < *min || exclusive && f == *min {
+			return false
+		}
 	}
-	if max := eg.a.Validation.Maximum; max != nil {
-		if v, ok := example.(int); ok && float64(v) > *max {
+	if max, exclusive := eg.a.Validation.UpperBound(); max != nil {
+		if f > *max || exclusive && f == *max {
 			return false
-		} else if v, ok := example.(float64); ok && v > *max {
+		}
+	}
+	if m := eg.a.Validation.MultipleOf; m != nil {
+		q := f / *m
+		if math.Abs(q-math.Round(q)) > 1e-9*math.Max(1, math.Abs(q)) {
 			return false
 		}
 	}
@@ -242,12 +265,57 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	if !eg.hasMinMaxValidation() {
 		return nil
 	}
+	example := eg.generateMinMaxValue()
+	m := eg.a.Validation.MultipleOf
+	if m == nil {
+		return example
+	}
+	// round up to the next multiple, the caller checks the result against the bounds.
+	switch v := example.(type) {
+	case int:
+		if *m == math.Trunc(*m) {
+			return int(math.Ceil(float64(v) / *m) * *m)
+		}
+		return v
+	case float64:
+		return math.Ceil(v / *m) * *m
+	}
+	return example
+}
+
+func (eg *exampleGenerator) generateMinMaxValue() interface{} {
 	min, max := math.Inf(1), math.Inf(-1)
-	if eg.a.Validation.Minimum != nil {
-		min = *eg.a.Validation.Minimum
+	isInt := eg.a.Type.Kind() == IntegerKind
+	lower, minExcl := eg.a.Validation.LowerBound()
+	if lower != nil {
+		min = *lower
+		if minExcl && isInt {
+			min, minExcl = math.Floor(min)+1, false
+		}
+	}
+	upper, maxExcl := eg.a.Validation.UpperBound()
+	if upper != nil {
+		max = *upper
+		if maxExcl && isInt {
+			max, maxExcl = math.Ceil(max)-1, false
+		}
+	}
+	if math.IsInf(min, 1) && math.IsInf(max, -1) {
+		return eg.a.Type.GenerateExample(eg.r, nil)
 	}
-	if eg.a.Validation.Maximum != nil {
-		max = *eg.a.Validation.Maximum
+	// exclusive number bounds: pick a value in (min, min+span] or [max-span, max).
+	span := func(bound float64) float64 {
+		s := math.Max(math.Abs(bound), 1)
+		if m := eg.a.Validation.MultipleOf; m != nil {
+			s = math.Max(s, *m)
+		}
+		return s
+	}
+	if minExcl && math.IsInf(max, -1) {
+		return min + (1-eg.r.Float64())*span(min)
+	}
+	if maxExcl && math.IsInf(min, 1) {
+		return max - (1-eg.r.Float64())*span(max)
 	}
 	if math.IsInf(min, 1) {
 		if eg.a.Type.Kind() == IntegerKind {
@@ -278,3 +346,99 @@ func (eg *exampleGenerator) generateValidatedMinMaxValueExample() interface{} {
 	}
 	panic("Validation: Min > Max")
 }
+
+// nameExamples contains the functions that produce the realistic examples of the string attributes
+// indexed by the lower case words that end the attribute names, see RealisticExamples.
+var nameExamples = map[string]func(r *RandomGenerator) string{
+	"email":        (*RandomGenerator).Email,
+	"emailaddress": (*RandomGenerator).Email,
+	"phone":        (*RandomGenerator).PhoneNumber,
+	"phonenumber":  (*RandomGenerator).PhoneNumber,
+	"mobile":       (*RandomGenerator).PhoneNumber,
+	"fax":          (*RandomGenerator).PhoneNumber,
+	"firstname":    (*RandomGenerator).FirstName,
+	"givenname":    (*RandomGenerator).FirstName,
+	"lastname":     (*RandomGenerator).LastName,
+	"surname":      (*RandomGenerator).LastName,
+	"familyname":   (*RandomGenerator).LastName,
+	"fullname":     (*RandomGenerator).Name,
+	"username":     (*RandomGenerator).UserName,
+	"login":        (*RandomGenerator).UserName,
+	"address":      (*RandomGenerator).StreetAddress,
+	"street":       (*RandomGenerator).StreetAddress,
+	"city":         (*RandomGenerator).City,
+	"zip":          (*RandomGenerator).PostCode,
+	"zipcode":      (*RandomGenerator).PostCode,
+	"postcode":     (*RandomGenerator).PostCode,
+	"postalcode":   (*RandomGenerator).PostCode,
+	"country":      (*RandomGenerator).Country,
+	"company":      (*RandomGenerator).CompanyName,
+	"companyname":  (*RandomGenerator).CompanyName,
+	"organization": (*RandomGenerator).CompanyName,
+	"url":          (*RandomGenerator).URL,
+	"website":      (*RandomGenerator).URL,
+	"homepage":     (*RandomGenerator).URL,
+}
+
+// realisticExample returns an example of the string attribute with the given name derived from the
+// name if the API uses RealisticExamples, nil if the attribute already has an example, if its
+// validations constrain the value or if its name is not recognized.
+func (a *AttributeDefinition) realisticExample(name string, r *RandomGenerator) interface{} {
+	if !Design.RealisticExamples || Design.NoExamples {
+		return nil
+	}
+	if a.Example != nil || a.ExampleGenerator != nil || a.Type.Kind() != StringKind {
+		return nil
+	}
+	if v := a.Validation; v != nil && (len(v.Values) > 0 || v.Format != "" || v.Pattern != "") {
+		return nil
+	}
+	words := nameWords(name)
+	for i := range words {
+		fn, ok := nameExamples[strings.Join(words[i:], "")]
+		if !ok {
+			continue
+		}
+		ex := fn(r)
+		if v := a.Validation; v != nil {
+			if v.MinLength != nil && utf8.RuneCountInString(ex) < *v.MinLength {
+				return nil
+			}
+			if v.MaxLength != nil && utf8.RuneCountInString(ex) > *v.MaxLength {
+				return nil
+			}
+		}
+		return ex
+	}
+	return nil
+}
+
+// nameWords splits the given attribute name into lower case words, e.g. "billing_email" and
+// "billingEmail" both produce "billing" and "email".
+func nameWords(name string) []string {
+	var (
+		words []string
+		cur   []rune
+		prev  rune
+	)
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			prev = c
+			continue
+		}
+		if unicode.IsUpper(c) && len(cur) > 0 && !unicode.IsUpper(prev) {
+			words = append(words, string(cur))
+			cur = nil
+		}
+		cur = append(cur, unicode.ToLower(c))
+		prev = c
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
@@ -0,0 +1,117 @@
+package design_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Finalize query params", func() {
+	var action *ActionDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		Resource("foo", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+					Param("q", String)
+					Required("id", "q")
+				})
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		action = Design.Resources["foo"].Actions["show"]
+	})
+
+	It("shares the param attributes", func() {
+		Ω(action.QueryParams.Type.ToObject()).Should(HaveLen(1))
+		Ω(action.QueryParams.Type.ToObject()["q"]).Should(BeIdenticalTo(action.Params.Type.ToObject()["q"]))
+	})
+
+	It("leaves the required params untouched", func() {
+		Ω(action.Params.Validation.Required).Should(Equal([]string{"id", "q"}))
+		Ω(action.QueryParams.Validation.Required).Should(Equal([]string{"q"}))
+	})
+})
+
+// largeDesign defines n media types, each with a collection of the previous one, and a resource
+// exposing them so that running the DSL exercises the finalization and projection of all of them.
+func largeDesign(n int) {
+	var prev *MediaTypeDefinition
+	for i := 0; i < n; i++ {
+		child := prev
+		prev = MediaType(fmt.Sprintf("application/vnd.bench.%d", i), func() {
+			Attributes(func() {
+				Attribute("id", Integer, func() { Minimum(1) })
+				Attribute("name", String, func() { MinLength(2) })
+				Attribute("tags", ArrayOf(String))
+				if child != nil {
+					Attribute("children", CollectionOf(child))
+				}
+				Required("id", "name")
+			})
+			View("default", func() {
+				Attribute("id")
+				Attribute("name")
+				Attribute("tags")
+				if child != nil {
+					Attribute("children", func() { View("tiny") })
+				}
+			})
+			View("tiny", func() {
+				Attribute("id")
+			})
+		})
+		mt := prev
+		Resource(fmt.Sprintf("bench%d", i), func() {
+			BasePath(fmt.Sprintf("/bench%d", i))
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Params(func() {
+					Param("id", Integer)
+					Param("view", String)
+				})
+				Response(OK, mt)
+			})
+		})
+	}
+}
+
+// BenchmarkRunLargeDesign measures the memory used to evaluate a design defining many media types.
+func BenchmarkRunLargeDesign(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dslengine.Reset()
+		largeDesign(500)
+		if err := dslengine.Run(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProjectLargeDesign measures the memory used to project the media types of a design
+// defining many media types.
+func BenchmarkProjectLargeDesign(b *testing.B) {
+	dslengine.Reset()
+	largeDesign(500)
+	if err := dslengine.Run(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProjectedMediaTypes = make(map[string]*MediaTypeDefinition)
+		for _, mt := range Design.MediaTypes {
+			if _, _, err := mt.Project(DefaultView); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/manveru/faker"
 	"github.com/satori/go.uuid"
+	regen "github.com/zach-klippenstein/goregen"
 )
 
 // RandomGenerator generates consistent random values of different types given a seed.
@@ -20,6 +21,22 @@ type RandomGenerator struct {
 	rand  *rand.Rand
 }
 
+// ExampleFunc is the signature of the functions that generate examples, see
+// RegisterFormatExample and the ExampleGenerator DSL.
+type ExampleFunc func(r *RandomGenerator) interface{}
+
+// formatExamples contains the example generators registered with RegisterFormatExample indexed
+// by format.
+var formatExamples = make(map[string]ExampleFunc)
+
+// RegisterFormatExample registers the function used to generate the examples of the string
+// attributes with the given format validation, e.g. "email". It overrides the built-in generator
+// of the format. fn should use r to produce its random values to keep the examples consistent
+// across runs.
+func RegisterFormatExample(format string, fn ExampleFunc) {
+	formatExamples[format] = fn
+}
+
 // NewRandomGenerator returns a random value generator seeded from the given string value.
 func NewRandomGenerator(seed string) *RandomGenerator {
 	hasher := md5.New()
@@ -59,9 +76,78 @@ func (r *RandomGenerator) DateTime() time.Time {
 	return time.Unix(unix, 0).UTC()
 }
 
-// UUID produces a random UUID.
+// Intn produces a random integer in [0,n).
+func (r *RandomGenerator) Intn(n int) int {
+	return r.rand.Intn(n)
+}
+
+// Name produces a random person name.
+func (r *RandomGenerator) Name() string {
+	return r.faker.Name()
+}
+
+// Email produces a random email address.
+func (r *RandomGenerator) Email() string {
+	return r.faker.Email()
+}
+
+// FirstName produces a random first name.
+func (r *RandomGenerator) FirstName() string {
+	return r.faker.FirstName()
+}
+
+// LastName produces a random last name.
+func (r *RandomGenerator) LastName() string {
+	return r.faker.LastName()
+}
+
+// UserName produces a random user name.
+func (r *RandomGenerator) UserName() string {
+	return r.faker.UserName()
+}
+
+// PhoneNumber produces a random phone number.
+func (r *RandomGenerator) PhoneNumber() string {
+	return r.faker.PhoneNumber()
+}
+
+// StreetAddress produces a random street address.
+func (r *RandomGenerator) StreetAddress() string {
+	return r.faker.StreetAddress()
+}
+
+// City produces a random city name.
+func (r *RandomGenerator) City() string {
+	return r.faker.City()
+}
+
+// PostCode produces a random postal code.
+func (r *RandomGenerator) PostCode() string {
+	return r.faker.PostCode()
+}
+
+// Country produces a random country name.
+func (r *RandomGenerator) Country() string {
+	return r.faker.Country()
+}
+
+// CompanyName produces a random company name.
+func (r *RandomGenerator) CompanyName() string {
+	return r.faker.CompanyName()
+}
+
+// URL produces a random URL.
+func (r *RandomGenerator) URL() string {
+	return r.faker.URL()
+}
+
+// UUID produces a random version 4 UUID.
 func (r *RandomGenerator) UUID() uuid.UUID {
-	return uuid.Must(uuid.NewV4())
+	var u uuid.UUID
+	r.rand.Read(u[:])
+	u.SetVersion(uuid.V4)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u
 }
 
 // Bool produces a random boolean.
@@ -74,7 +160,21 @@ func (r *RandomGenerator) Float64() float64 {
 	return r.rand.Float64()
 }
 
+// Decimal produces a random decimal number with two fraction digits encoded as a string.
+func (r *RandomGenerator) Decimal() string {
+	return fmt.Sprintf("%d.%02d", r.rand.Intn(10000), r.rand.Intn(100))
+}
+
 // File produces a random file.
 func (r *RandomGenerator) File() string {
 	return fmt.Sprintf("%sjpg", r.faker.Sentence(1, false))
 }
+
+// regexp produces a random string that matches the given regular expression.
+func (r *RandomGenerator) regexp(pattern string) (string, error) {
+	g, err := regen.NewGenerator(pattern, &regen.GeneratorArgs{RngSource: r.rand})
+	if err != nil {
+		return "", err
+	}
+	return g.Generate(), nil
+}
@@ -22,6 +22,15 @@ const (
 	JWTSecurityKind
 	// NoSecurityKind means to have no security for this endpoint.
 	NoSecurityKind
+	// HMACSecurityKind means an "apiKey" security type where the key is a signature of the
+	// request computed with a shared secret.
+	HMACSecurityKind
+	// CookieSecurityKind means an "apiKey" security type where the key is a session cookie
+	// signed and optionally encrypted by the service.
+	CookieSecurityKind
+	// SPIFFESecurityKind means a mutual TLS security type where clients are authenticated with
+	// the SPIFFE ID of their X.509 SVID.
+	SPIFFESecurityKind
 )
 
 // SecurityDefinition defines security requirements for an Action
@@ -69,6 +78,15 @@ type SecuritySchemeDefinition struct {
 	TokenURL string `json:"token_url,omitempty"`
 	// AuthorizationURL holds URL for retrieving authorization codes with oauth2
 	AuthorizationURL string `json:"authorization_url,omitempty"`
+	// SessionType is the type of the content of the session cookie of cookie schemes.
+	SessionType DataType `json:"-"`
+	// Encrypted is true if the content of the session cookie of cookie schemes is encrypted.
+	Encrypted bool `json:"encrypted,omitempty"`
+	// TrustDomain is the SPIFFE trust domain of the workloads authorized by SPIFFE schemes.
+	TrustDomain string `json:"trust_domain,omitempty"`
+	// Identities maps the SPIFFE IDs of the workloads authorized by SPIFFE schemes to the
+	// scopes they are granted.
+	Identities map[string][]string `json:"identities,omitempty"`
 	// Metadata is a list of key/value pairs
 	Metadata dslengine.MetadataDefinition
 }
@@ -90,12 +108,39 @@ func (s *SecuritySchemeDefinition) Context() string {
 		dslFunc = "APIKeySecurity"
 	case JWTSecurityKind:
 		dslFunc = "JWTSecurity"
+	case HMACSecurityKind:
+		dslFunc = "HMACSecurity"
+	case CookieSecurityKind:
+		dslFunc = "CookieSecurity"
+	case SPIFFESecurityKind:
+		dslFunc = "SPIFFESecurity"
 	}
 	return dslFunc
 }
 
-// Validate ensures that TokenURL and AuthorizationURL are valid URLs.
+// Validate ensures that TokenURL and AuthorizationURL are valid URLs, that the session type of
+// cookie schemes is an object and that the identities of SPIFFE schemes are SPIFFE IDs of the
+// scheme trust domain granted scopes defined by the scheme.
 func (s *SecuritySchemeDefinition) Validate() error {
+	if s.SessionType != nil && !s.SessionType.IsObject() {
+		return fmt.Errorf("session type of %s must be an object", s.SchemeName)
+	}
+	if s.Kind == SPIFFESecurityKind {
+		if s.TrustDomain == "" {
+			return fmt.Errorf("trust domain of %s is missing", s.SchemeName)
+		}
+		for id, scopes := range s.Identities {
+			u, err := url.Parse(id)
+			if err != nil || u.Scheme != "spiffe" || u.Host != s.TrustDomain {
+				return fmt.Errorf("invalid identity %#v of %s: must be a SPIFFE ID of trust domain %s", id, s.SchemeName, s.TrustDomain)
+			}
+			for _, scope := range scopes {
+				if _, ok := s.Scopes[scope]; !ok {
+					return fmt.Errorf("scope %#v granted to identity %#v is not defined by %s", scope, id, s.SchemeName)
+				}
+			}
+		}
+	}
 	_, err := url.Parse(s.TokenURL)
 	if err != nil {
 		return fmt.Errorf("invalid token URL %#v: %s", s.TokenURL, err)
@@ -107,8 +152,18 @@ func (s *SecuritySchemeDefinition) Validate() error {
 	return nil
 }
 
-// Finalize makes the TokenURL and AuthorizationURL complete if needed.
+// Finalize makes the TokenURL and AuthorizationURL complete if needed. It also defaults the
+// header used by HMAC schemes to "Authorization" and the cookie used by cookie schemes to
+// "session".
 func (s *SecuritySchemeDefinition) Finalize() {
+	if s.Kind == HMACSecurityKind && s.In == "" {
+		s.In = "header"
+		s.Name = "Authorization"
+	}
+	if s.Kind == CookieSecurityKind && s.In == "" {
+		s.In = "cookie"
+		s.Name = "session"
+	}
 	tu, _ := url.Parse(s.TokenURL)         // validated in Validate
 	au, _ := url.Parse(s.AuthorizationURL) // validated in Validate
 	tokenOK := s.TokenURL == "" || tu.IsAbs()
@@ -0,0 +1,82 @@
+package design
+
+import "github.com/goadesign/goa/dslengine"
+
+// The time formats below may be given to the TimeFormats and TimeOutput DSLs besides Go time
+// layouts such as "2006-01-02".
+const (
+	// RFC3339 is the RFC3339 encoding, the default encoding of the DateTime attributes.
+	RFC3339 = "rfc3339"
+	// UnixSeconds is the number of seconds elapsed since January 1, 1970 UTC.
+	UnixSeconds = "unix"
+	// UnixMillis is the number of milliseconds elapsed since January 1, 1970 UTC.
+	UnixMillis = "unixmilli"
+)
+
+const (
+	// timeFormatsMetadata is the metadata key that lists the formats accepted when decoding a
+	// DateTime attribute.
+	timeFormatsMetadata = "time:formats"
+	// timeOutputMetadata is the metadata key that records the format and the zone used to
+	// encode a DateTime attribute.
+	timeOutputMetadata = "time:output"
+)
+
+// SetTimeFormats sets the formats accepted when decoding the DateTime attribute.
+func (a *AttributeDefinition) SetTimeFormats(formats ...string) {
+	a.setTimeMetadata(timeFormatsMetadata, formats)
+}
+
+// SetTimeOutput sets the format and the IANA zone name used to encode the DateTime attribute,
+// zone may be empty to keep the time zone of the values.
+func (a *AttributeDefinition) SetTimeOutput(format, zone string) {
+	a.setTimeMetadata(timeOutputMetadata, []string{format, zone})
+}
+
+// TimeFormats returns the formats accepted when decoding the DateTime attribute, nil if the
+// attribute only accepts RFC3339 values. The output format is always accepted.
+func (a *AttributeDefinition) TimeFormats() []string {
+	formats := a.Metadata[timeFormatsMetadata]
+	output, ok := a.Metadata[timeOutputMetadata]
+	if !ok {
+		return formats
+	}
+	if len(formats) == 0 {
+		formats = []string{RFC3339}
+	}
+	for _, f := range formats {
+		if f == output[0] {
+			return formats
+		}
+	}
+	return append(append([]string{}, formats...), output[0])
+}
+
+// TimeOutput returns the format and the zone used to encode the DateTime attribute. The format
+// defaults to the first accepted format and the zone to the empty string which keeps the time
+// zone of the values.
+func (a *AttributeDefinition) TimeOutput() (format, zone string) {
+	if output, ok := a.Metadata[timeOutputMetadata]; ok {
+		return output[0], output[1]
+	}
+	if formats := a.Metadata[timeFormatsMetadata]; len(formats) > 0 {
+		return formats[0], ""
+	}
+	return RFC3339, ""
+}
+
+// HasTimeEncoding returns true if the design overrides the default RFC3339 encoding of the
+// DateTime attribute.
+func (a *AttributeDefinition) HasTimeEncoding() bool {
+	_, formats := a.Metadata[timeFormatsMetadata]
+	_, output := a.Metadata[timeOutputMetadata]
+	return a.Type == DateTime && (formats || output)
+}
+
+// setTimeMetadata records the given time encoding metadata.
+func (a *AttributeDefinition) setTimeMetadata(key string, vals []string) {
+	if a.Metadata == nil {
+		a.Metadata = make(dslengine.MetadataDefinition)
+	}
+	a.Metadata[key] = vals
+}
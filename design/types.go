@@ -135,6 +135,9 @@ type (
 		Views map[string]*ViewDefinition
 		// Resource this media type is the canonical representation for if any
 		Resource *ResourceDefinition
+		// Diff is true if the generated code includes functions listing the fields that
+		// differ between two instances of the media type views.
+		Diff bool
 	}
 )
 
@@ -165,6 +168,8 @@ const (
 	MediaTypeKind
 	// FileKind represents a file.
 	FileKind
+	// DecimalKind represents a decimal number encoded as a JSON string.
+	DecimalKind
 )
 
 const (
@@ -193,6 +198,11 @@ const (
 
 	// File is the type for a file. This type can only be used in a multipart definition.
 	File = Primitive(FileKind)
+
+	// Decimal is the type for a decimal number encoded as a JSON string, e.g. "12.50". This
+	// type can only be used to define attributes, the attributes are strings with the
+	// "decimal" format validation, see the Precision, Scale and DecimalType DSLs.
+	Decimal = Primitive(DecimalKind)
 )
 
 // DataType implementation
@@ -209,7 +219,7 @@ func (p Primitive) Name() string {
 		return "integer"
 	case Number:
 		return "number"
-	case String, DateTime, UUID:
+	case String, DateTime, UUID, Decimal:
 		return "string"
 	case Any:
 		return "any"
@@ -247,7 +257,7 @@ func (p Primitive) ToHash() *Hash { return nil }
 // CanHaveDefault returns whether the primitive can have a default value.
 func (p Primitive) CanHaveDefault() (ok bool) {
 	switch p {
-	case Boolean, Integer, Number, String, DateTime:
+	case Boolean, Integer, Number, String, DateTime, Decimal:
 		ok = true
 	}
 	return
@@ -255,7 +265,7 @@ func (p Primitive) CanHaveDefault() (ok bool) {
 
 // IsCompatible returns true if val is compatible with p.
 func (p Primitive) IsCompatible(val interface{}) bool {
-	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Any {
+	if p != Boolean && p != Integer && p != Number && p != String && p != DateTime && p != UUID && p != Decimal && p != Any {
 		panic("unknown primitive type") // bug
 	}
 	if p == Any {
@@ -280,6 +290,9 @@ func (p Primitive) IsCompatible(val interface{}) bool {
 			_, err := uuid.FromString(val.(string))
 			return err == nil
 		}
+		if p == Decimal {
+			return decimalRegex.MatchString(val.(string))
+		}
 	}
 	return false
 }
@@ -306,6 +319,8 @@ func (p Primitive) GenerateExample(r *RandomGenerator, seen []string) interface{
 		return anyPrimitive[r.Int()%len(anyPrimitive)].GenerateExample(r, seen)
 	case File:
 		return r.File()
+	case Decimal:
+		return r.Decimal()
 	default:
 		panic("unknown primitive type") // bug
 	}
@@ -446,7 +461,11 @@ func (o Object) GenerateExample(r *RandomGenerator, seen []string) interface{} {
 	res := make(map[string]interface{})
 	for _, n := range keys {
 		att := o[n]
-		res[n] = att.Type.GenerateExample(r, seen)
+		if ex := att.realisticExample(n, r); ex != nil {
+			res[att.AttributeKey(n)] = ex
+			continue
+		}
+		res[att.AttributeKey(n)] = att.Type.GenerateExample(r, seen)
 	}
 	return res
 }
@@ -602,26 +621,43 @@ func UserTypes(dt DataType) map[string]*UserTypeDefinition {
 
 // HasFile returns true if the underlying type has any file attributes.
 func HasFile(dt DataType) bool {
+	return hasFile(dt, make(map[string]bool))
+}
+
+// hasFile implements HasFile, seen records the user types already traversed to support
+// recursive types.
+func hasFile(dt DataType, seen map[string]bool) bool {
 	if dt == nil {
 		return false
 	}
+	if ut, ok := dt.(*UserTypeDefinition); ok {
+		if seen[ut.TypeName] {
+			return false
+		}
+		seen[ut.TypeName] = true
+	} else if mt, ok := dt.(*MediaTypeDefinition); ok {
+		if seen[mt.TypeName] {
+			return false
+		}
+		seen[mt.TypeName] = true
+	}
 	switch {
 	case dt.IsPrimitive():
 		return dt.Kind() == FileKind
 	case dt.IsArray():
-		if HasFile(dt.ToArray().ElemType.Type) {
+		if hasFile(dt.ToArray().ElemType.Type, seen) {
 			return true
 		}
 	case dt.IsHash():
-		if HasFile(dt.ToHash().KeyType.Type) {
+		if hasFile(dt.ToHash().KeyType.Type, seen) {
 			return true
 		}
-		if HasFile(dt.ToHash().ElemType.Type) {
+		if hasFile(dt.ToHash().ElemType.Type, seen) {
 			return true
 		}
 	case dt.IsObject():
 		for _, att := range dt.ToObject() {
-			if HasFile(att.Type) {
+			if hasFile(att.Type, seen) {
 				return true
 			}
 		}
@@ -710,13 +746,14 @@ func (u *UserTypeDefinition) IsCompatible(val interface{}) bool {
 	return u.Type == nil || u.Type.IsCompatible(val)
 }
 
-// Finalize merges base type attributes.
+// Finalize merges base type attributes and sets the Go type of the decimal attributes.
 func (u *UserTypeDefinition) Finalize() {
 	if u.Reference != nil {
 		if bat := u.AttributeDefinition; bat != nil {
 			u.AttributeDefinition.Inherit(bat)
 		}
 	}
+	finalizeDecimals(u.AttributeDefinition)
 
 	u.GenerateExample(Design.RandomGenerator(), nil)
 }
@@ -843,11 +880,12 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 
 	p = &MediaTypeDefinition{
 		Identifier: m.projectIdentifier(view),
+		Diff:       m.Diff,
 		UserTypeDefinition: &UserTypeDefinition{
 			TypeName: m.projectTypeName(view),
 			AttributeDefinition: &AttributeDefinition{
 				Description: desc,
-				Type:        Dup(v.Type),
+				Type:        projectedType(v.Type),
 				Validation:  val,
 			},
 		},
@@ -911,14 +949,26 @@ func (m *MediaTypeDefinition) projectSingle(view, canonical string) (p *MediaTyp
 					// Force example to be generated again
 					// since set of attributes has changed
 					at.Example = nil
+					at.Examples = nil
 				}
 				projectedObj[n] = at
+			} else {
+				projectedObj[n] = DupAtt(viewObj[n])
 			}
 		}
 	}
 	return
 }
 
+// projectedType returns the type of the media type projected using a view of the given type. The
+// attributes of object types are all set by Project so only the object is allocated.
+func projectedType(t DataType) DataType {
+	if obj := t.ToObject(); obj != nil {
+		return make(Object, len(obj))
+	}
+	return Dup(t)
+}
+
 func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefinition, *UserTypeDefinition, error) {
 	// Project the collection element media type
 	e := m.ToArray().ElemType.Type.(*MediaTypeDefinition) // validation checked this cast would work
@@ -931,6 +981,7 @@ func (m *MediaTypeDefinition) projectCollection(view string) (*MediaTypeDefiniti
 	desc := m.TypeName + " is the media type for an array of " + e.TypeName + " (" + view + " view)"
 	p := &MediaTypeDefinition{
 		Identifier: m.projectIdentifier(view),
+		Diff:       m.Diff || e.Diff,
 		UserTypeDefinition: &UserTypeDefinition{
 			AttributeDefinition: &AttributeDefinition{
 				Description: desc,
@@ -2,6 +2,7 @@ package design_test
 
 import (
 	"errors"
+	"fmt"
 	"mime"
 	"sync"
 
@@ -602,4 +603,120 @@ var _ = Describe("GenerateExample", func() {
 			Ω(h.GenerateExample(rand, nil)).Should(BeAssignableToTypeOf(map[string]string{"foo": "bar"}))
 		})
 	})
+
+	Context("Given a recursive type", func() {
+		var depth int
+		var example interface{}
+		BeforeEach(func() {
+			depth = 0
+		})
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				if depth > 0 {
+					MaxRecursionDepth(depth)
+				}
+			})
+			Type("Node", func() {
+				Attribute("name", String)
+				Attribute("parent", "Node")
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			example = Design.Types["Node"].GenerateExample(NewRandomGenerator("foo"), nil)
+		})
+		It("nests the type twice by default", func() {
+			parent := example.(map[string]interface{})["parent"]
+			Ω(parent).Should(HaveKey("parent"))
+			Ω(parent.(map[string]interface{})["parent"]).ShouldNot(HaveKey("parent"))
+			Ω(HasFile(Design.Types["Node"])).Should(BeFalse())
+		})
+
+		Context("with a maximum recursion depth", func() {
+			BeforeEach(func() {
+				depth = 1
+			})
+			It("nests the type up to the given depth", func() {
+				Ω(example).Should(HaveKey("parent"))
+				Ω(example.(map[string]interface{})["parent"]).ShouldNot(HaveKey("parent"))
+			})
+		})
+	})
+
+	Context("Given an integer with exclusive bounds and a multiple of validation", func() {
+		var att *AttributeDefinition
+		BeforeEach(func() {
+			min, max, m := 0.0, 10.0, 3.0
+			att = &AttributeDefinition{
+				Type: Integer,
+				Validation: &dslengine.ValidationDefinition{
+					ExclusiveMinimum: &min,
+					ExclusiveMaximum: &max,
+					MultipleOf:       &m,
+				},
+			}
+		})
+		It("generates a valid example", func() {
+			rand := NewRandomGenerator("foo")
+			for i := 0; i < 10; i++ {
+				Ω([]interface{}{3, 6, 9}).Should(ContainElement(att.GenerateExample(rand, nil)))
+			}
+		})
+	})
+
+	Context("Given a format with a registered example generator", func() {
+		var att *AttributeDefinition
+		BeforeEach(func() {
+			RegisterFormatExample("test-format", func(r *RandomGenerator) interface{} {
+				return fmt.Sprintf("test-%d", r.Intn(10))
+			})
+			att = &AttributeDefinition{
+				Type:       String,
+				Validation: &dslengine.ValidationDefinition{Format: "test-format"},
+			}
+		})
+		It("uses the registered generator", func() {
+			example := att.GenerateExample(NewRandomGenerator("foo"), nil)
+			Ω(example).Should(MatchRegexp(`^test-\d$`))
+			Ω(att.GenerateExample(NewRandomGenerator("foo"), nil)).Should(Equal(example))
+		})
+	})
+
+	Context("Given an API with realistic examples", func() {
+		var example map[string]interface{}
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			API("test", func() {
+				RealisticExamples()
+			})
+			Type("Contact", func() {
+				Attribute("billing_email", String)
+				Attribute("homePhone", String)
+				Attribute("first_name", String)
+				Attribute("zip_code", String)
+				Attribute("filename", String)
+				Attribute("city", String, func() {
+					Enum("Paris", "Tokyo")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			example = Design.Types["Contact"].GenerateExample(NewRandomGenerator("foo"), nil).(map[string]interface{})
+		})
+		It("derives the examples from the attribute names", func() {
+			Ω(example["billing_email"]).Should(ContainSubstring("@"))
+			Ω(example["homePhone"]).Should(MatchRegexp(`\d`))
+			Ω(example["first_name"]).ShouldNot(ContainSubstring(" "))
+			Ω(example["zip_code"]).Should(MatchRegexp(`\d`))
+			Ω(example["filename"]).ShouldNot(BeEmpty())
+			Ω([]interface{}{"Paris", "Tokyo"}).Should(ContainElement(example["city"]))
+		})
+	})
+})
+
+var _ = Describe("RandomGenerator", func() {
+	It("generates the same UUIDs given the same seed", func() {
+		u := NewRandomGenerator("foo").UUID()
+		Ω(NewRandomGenerator("foo").UUID()).Should(Equal(u))
+		Ω(NewRandomGenerator("bar").UUID()).ShouldNot(Equal(u))
+		Ω(u.Version()).Should(BeEquivalentTo(4))
+	})
 })
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/build"
 	"mime"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -14,6 +15,9 @@ import (
 	"github.com/goadesign/goa/dslengine"
 )
 
+// MaxFieldNumber is the largest protocol buffers field number, see the Field DSL.
+const MaxFieldNumber = 1<<29 - 1
+
 type routeInfo struct {
 	Key       string
 	Resource  *ResourceDefinition
@@ -85,8 +89,23 @@ func (a *APIDefinition) Validate() error {
 	a.validateLicense(verr)
 	a.validateDocs(verr)
 	a.validateOrigins(verr)
+	a.validateServers(verr)
+	a.validateJSONRPC(verr)
+	a.validateErrorEnvelope(verr)
+	a.validateHeaderGroups(verr)
 
 	var allRoutes []*routeInfo
+	operationIDs := make(map[string]dslengine.Definition)
+	checkOperationID := func(def dslengine.Definition, id string) {
+		if id == "" {
+			return
+		}
+		if other, ok := operationIDs[id]; ok {
+			verr.Add(def, "operation ID %#v is already used by %s", id, other.Context())
+			return
+		}
+		operationIDs[id] = def
+	}
 	a.IterateResources(func(r *ResourceDefinition) error {
 		verr.Merge(r.Validate())
 		r.IterateActions(func(ac *ActionDefinition) error {
@@ -95,7 +114,9 @@ func (a *APIDefinition) Validate() error {
 					verr.Add(ac, "invalid action docs URL value: %s", err)
 				}
 			}
+			checkOperationID(ac, ac.OperationID)
 			for _, ro := range ac.Routes {
+				checkOperationID(ro, ro.OperationID)
 				if ro.IsAbsolute() {
 					continue
 				}
@@ -118,6 +139,7 @@ func (a *APIDefinition) Validate() error {
 	})
 
 	a.validateRoutes(verr, allRoutes)
+	a.validateRouteConflicts(verr)
 
 	a.IterateMediaTypes(func(mt *MediaTypeDefinition) error {
 		verr.Merge(mt.Validate())
@@ -178,6 +200,66 @@ func (a *APIDefinition) validateRoutes(verr *dslengine.ValidationErrors, routes
 	}
 }
 
+// mountedRoute is a verb and path registered with the service mux by an action or a file server.
+type mountedRoute struct {
+	Verb string
+	Path string
+	// Pattern is the path where the wildcard names are removed, e.g. "/bottles/:".
+	Pattern string
+	Def     dslengine.Definition
+}
+
+// validateRouteConflicts reports the action and file server routes that use the same verb and
+// path pattern. The service mux cannot register such routes and panics when the controllers are
+// mounted.
+func (a *APIDefinition) validateRouteConflicts(verr *dslengine.ValidationErrors) {
+	var routes []*mountedRoute
+	add := func(verb, path string, def dslengine.Definition) {
+		pattern := WildcardRegex.ReplaceAllStringFunc(path, func(w string) string { return w[:2] })
+		routes = append(routes, &mountedRoute{Verb: verb, Path: path, Pattern: pattern, Def: def})
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		r.IterateActions(func(ac *ActionDefinition) error {
+			for _, ro := range ac.Routes {
+				add(ro.Verb, ro.FullPath(), ac)
+			}
+			if ac.BatchRoute != nil {
+				add(ac.BatchRoute.Verb, ac.BatchRoute.FullPath(), ac)
+			}
+			if ac.Resumable && len(ac.Routes) > 0 {
+				add("HEAD", ac.UploadPath(), ac)
+				add("PATCH", ac.UploadPath(), ac)
+			}
+			return nil
+		})
+		return r.IterateFileServers(func(fs *FileServerDefinition) error {
+			p := fs.RequestPath
+			if !strings.HasPrefix(p, "/") {
+				p = "/" + p
+			}
+			add("GET", p, fs)
+			return nil
+		})
+	})
+	seen := make(map[string]*mountedRoute)
+	for _, ro := range routes {
+		key := ro.Verb + " " + ro.Pattern
+		other, ok := seen[key]
+		if !ok {
+			seen[key] = ro
+			continue
+		}
+		_, action := ro.Def.(*ActionDefinition)
+		_, otherAction := other.Def.(*ActionDefinition)
+		if action && otherAction && ro.Path != other.Path {
+			// Action routes using different wildcard names are reported by validateRoutes.
+			continue
+		}
+		verr.Add(ro.Def, `route %s "%s" conflicts with route %s "%s" of %s`,
+			ro.Verb, ro.Path, other.Verb, other.Path, other.Def.Context())
+	}
+}
+
 func (a *APIDefinition) validateContact(verr *dslengine.ValidationErrors) {
 	if a.Contact != nil && a.Contact.URL != "" {
 		if _, err := url.ParseRequestURI(a.Contact.URL); err != nil {
@@ -202,6 +284,128 @@ func (a *APIDefinition) validateDocs(verr *dslengine.ValidationErrors) {
 	}
 }
 
+// validateJSONRPC makes sure the path of the JSON-RPC endpoint is absolute and has no wildcard.
+func (a *APIDefinition) validateJSONRPC(verr *dslengine.ValidationErrors) {
+	if a.JSONRPCPath == "" {
+		return
+	}
+	if !strings.HasPrefix(a.JSONRPCPath, "/") {
+		verr.Add(a, "invalid JSON-RPC path %#v, must start with /", a.JSONRPCPath)
+	}
+	if len(ExtractWildcards(a.JSONRPCPath)) > 0 {
+		verr.Add(a, "invalid JSON-RPC path %#v, cannot contain wildcards", a.JSONRPCPath)
+	}
+}
+
+// validateHeaderGroups makes sure the header groups with the same name use the same prefix and
+// type across the API as the generated functions are named after the groups.
+func (a *APIDefinition) validateHeaderGroups(verr *dslengine.ValidationErrors) {
+	groups := make(map[string]*HeaderGroupDefinition)
+	check := func(gs []*HeaderGroupDefinition) {
+		for _, g := range gs {
+			other, ok := groups[g.Name]
+			if !ok {
+				groups[g.Name] = g
+				continue
+			}
+			if other.Prefix != g.Prefix || other.Type != g.Type {
+				verr.Add(g, "header group %#v is defined with a different prefix or type by %s", g.Name, other.Parent.Context())
+			}
+		}
+	}
+	a.IterateResources(func(r *ResourceDefinition) error {
+		return r.IterateActions(func(action *ActionDefinition) error {
+			check(action.HeaderGroups)
+			return action.IterateResponses(func(resp *ResponseDefinition) error {
+				check(resp.HeaderGroups)
+				return nil
+			})
+		})
+	})
+}
+
+// errorFields lists the names of the fields of the goa error responses.
+var errorFields = []string{"id", "code", "status", "detail", "meta", "fields"}
+
+func (a *APIDefinition) validateErrorEnvelope(verr *dslengine.ValidationErrors) {
+	env := a.ErrorEnvelope
+	if env == nil {
+		return
+	}
+	keys := make(map[string]string)
+	for name := range env.Fields {
+		found := false
+		for _, f := range errorFields {
+			if f == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			verr.Add(env, "invalid error field %#v, must be one of %s", name, strings.Join(errorFields, ", "))
+		}
+	}
+	for _, name := range errorFields {
+		key := name
+		if k, ok := env.Fields[name]; ok {
+			key = k
+		}
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			verr.Add(env, "error field %#v cannot be renamed to the empty string", name)
+			continue
+		}
+		if other, ok := keys[key]; ok {
+			verr.Add(env, "error fields %#v and %#v both use the name %#v", other, name, key)
+			continue
+		}
+		keys[key] = name
+	}
+	for key, val := range env.Static {
+		if name, ok := keys[key]; ok {
+			verr.Add(env, "static error field %#v conflicts with error field %#v", key, name)
+		}
+		switch val.(type) {
+		case string, bool, int, float64:
+		default:
+			verr.Add(env, "invalid value %#v for static error field %#v, must be a string, a boolean or a number", val, key)
+		}
+	}
+}
+
+// serverVariableRegex matches the variables of a server host.
+var serverVariableRegex = regexp.MustCompile(`{([^{}]+)}`)
+
+func (a *APIDefinition) validateServers(verr *dslengine.ValidationErrors) {
+	names := make(map[string]bool)
+	for _, s := range a.Servers {
+		if names[s.Name] {
+			verr.Add(s, "server %#v is defined more than once", s.Name)
+		}
+		names[s.Name] = true
+		if s.Host == "" {
+			verr.Add(s, "server must define a host")
+		}
+		vars := make(map[string]bool)
+		for _, v := range s.Variables {
+			vars[v.Name] = true
+			if !strings.Contains(s.Host, "{"+v.Name+"}") {
+				verr.Add(s, "variable %#v is not used in host %#v", v.Name, s.Host)
+			}
+			if len(v.Values) > 0 && !containsString(v.Values, v.Default) {
+				verr.Add(s, "default value %#v of variable %#v is not one of the allowed values", v.Default, v.Name)
+			}
+		}
+		for _, m := range serverVariableRegex.FindAllStringSubmatch(s.Host, -1) {
+			if !vars[m[1]] {
+				verr.Add(s, "host %#v uses undefined variable %#v", s.Host, m[1])
+			}
+		}
+	}
+}
+
 func (a *APIDefinition) validateOrigins(verr *dslengine.ValidationErrors) {
 	for _, origin := range a.Origins {
 		verr.Merge(origin.Validate())
@@ -228,6 +432,74 @@ func (r *ResourceDefinition) Validate() *dslengine.ValidationErrors {
 	for _, origin := range r.Origins {
 		verr.Merge(origin.Validate())
 	}
+	if r.CSRFTokenPath != "" && !strings.HasPrefix(r.CSRFTokenPath, "/") {
+		verr.Add(r, "invalid CSRF token path %#v, must start with /", r.CSRFTokenPath)
+	}
+	if r.Priority != nil {
+		verr.Merge(r.Priority.Validate(r))
+	}
+	if r.Deprecation != nil {
+		verr.Merge(r.Deprecation.Validate(r))
+	}
+	if r.Flag != nil {
+		verr.Merge(r.Flag.Validate(r))
+	}
+	verr.Merge(validateMiddleware(r, r.Middleware))
+	return verr.AsError()
+}
+
+// middlewareNameRegex matches the valid middleware names, see Use.
+var middlewareNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// validateMiddleware checks that the middleware names are valid and that they are not listed
+// twice.
+func validateMiddleware(parent dslengine.Definition, names []string) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	seen := make(map[string]bool, len(names))
+	for _, n := range names {
+		if !middlewareNameRegex.MatchString(n) {
+			verr.Add(parent, "invalid middleware name %#v, must start with a letter and contain only letters, digits, dashes and underscores", n)
+			continue
+		}
+		if seen[n] {
+			verr.Add(parent, "middleware %#v is used more than once", n)
+		}
+		seen[n] = true
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the sunset date, if any, is after the deprecation date.
+func (d *DeprecationDefinition) Validate(parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if !d.Sunset.IsZero() && !d.Sunset.After(d.Since) {
+		verr.Add(parent, "sunset date %s must be after deprecation date %s", d.Sunset.Format("2006-01-02"), d.Since.Format("2006-01-02"))
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the flag has a name and that the status is 403 or 404.
+func (f *FlagDefinition) Validate(parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if f.Name == "" {
+		verr.Add(parent, "feature flag name cannot be empty")
+	}
+	if f.Status != 403 && f.Status != 404 {
+		verr.Add(parent, "invalid feature flag status %d, must be 403 or 404", f.Status)
+	}
+	return verr.AsError()
+}
+
+// Validate checks that the priority thresholds are not negative and that the header attribute is
+// valid.
+func (p *PriorityDefinition) Validate(parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	for _, l := range p.PriorityLevels() {
+		if p.Thresholds[l] < 0 {
+			verr.Add(parent, "threshold of priority level %s cannot be negative", l)
+		}
+	}
+	verr.Merge(p.Attribute.Validate("priority header "+p.Header, parent))
 	return verr.AsError()
 }
 
@@ -329,6 +601,40 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 	if len(a.Routes) == 0 {
 		verr.Add(a, "No route defined for action")
 	}
+	for _, ro := range a.Routes {
+		for n := range ro.ResponseDescriptions {
+			if _, ok := a.Responses[n]; ok {
+				continue
+			}
+			if a.Parent != nil {
+				if _, ok := a.Parent.Responses[n]; ok {
+					continue
+				}
+			}
+			verr.Add(ro, "response description set for unknown response %s", n)
+		}
+		for _, ex := range ro.AllHTTPExamples() {
+			if _, ok := a.Responses[ex.Response]; ok {
+				continue
+			}
+			if a.Parent != nil {
+				if _, ok := a.Parent.Responses[ex.Response]; ok {
+					continue
+				}
+			}
+			verr.Add(ro, "example set for unknown response %s", ex.Response)
+		}
+		full := ro.FullPath()
+		if i := strings.Index(full, "/*"); i >= 0 && strings.Contains(full[i+2:], "/") {
+			verr.Add(ro, "catch-all parameter must be the last segment of path %s", full)
+			continue
+		}
+		if n := ro.CatchAll(); n != "" && a.Params != nil {
+			if p, ok := a.Params.Type.ToObject()[n]; ok && p.Type.Kind() != StringKind {
+				verr.Add(ro, "catch-all parameter %s must be a string", n)
+			}
+		}
+	}
 	for i, r := range a.Responses {
 		for j, r2 := range a.Responses {
 			if i != j && r.Status == r2.Status {
@@ -341,17 +647,107 @@ func (a *ActionDefinition) Validate() *dslengine.ValidationErrors {
 		}
 	}
 	verr.Merge(a.ValidateParams())
+	verr.Merge(a.validateResponseTags())
+	verr.Merge(a.validateViewSelector())
+	verr.Merge(a.validateFieldMask())
+	verr.Merge(validateHeaderGroups(a, a.HeaderGroups, a.Headers))
+	verr.Merge(a.validateQueryObjects())
+	verr.Merge(a.validatePayloadVersions())
 	if a.Payload != nil {
 		verr.Merge(a.Payload.Validate("action payload", a))
 		if HasFile(a.Payload.Type) && a.PayloadMultipart != true {
 			verr.Add(a, "Payload %s contains an invalid type, action payloads cannot contain a file", a.Payload.TypeName)
 		}
+		if a.PayloadMultipart && a.Payload.IsObject() {
+			a.Payload.ToObject().IterateAttributes(func(n string, _ *AttributeDefinition) error {
+				if a.Payload.IsValueField(n) {
+					verr.Add(a, "multipart payload attribute %s cannot use the struct:field:value metadata", n)
+				}
+				return nil
+			})
+		}
 	}
 	if a.Parent == nil {
 		verr.Add(a, "missing parent resource")
 	}
+	if a.IsRaw() {
+		if a.Payload != nil {
+			verr.Add(a, "raw actions cannot define a payload, read the request body from the context instead")
+		}
+		if a.ProxyURL != "" {
+			verr.Add(a, "raw actions cannot be proxied")
+		}
+	}
+	if a.CacheTTL > 0 {
+		if a.IsRaw() {
+			verr.Add(a, "raw actions cannot be cached")
+		}
+		for _, k := range a.CacheKeys {
+			if a.Params != nil {
+				if _, ok := a.Params.Type.ToObject()[k]; ok {
+					continue
+				}
+			}
+			if a.Payload != nil && a.Payload.IsObject() {
+				if _, ok := a.Payload.ToObject()[k]; ok {
+					if a.IsLazyBody() {
+						verr.Add(a, "cache key %s is a payload attribute, the payload of actions with lazy body decoding is not available to the cache", k)
+					}
+					continue
+				}
+			}
+			verr.Add(a, "cache key %s is neither a param nor a payload attribute", k)
+		}
+	}
+	if a.Coalesced {
+		for _, r := range a.Routes {
+			if r.Verb != "GET" {
+				verr.Add(a, "only GET actions can be coalesced, route %s %s uses another method", r.Verb, r.FullPath())
+			}
+		}
+		if a.WebSocket() {
+			verr.Add(a, "websocket actions cannot be coalesced")
+		}
+	}
+	if a.Topic != "" {
+		if a.IsRaw() || a.ProxyURL != "" || a.PayloadMultipart || a.WebSocket() {
+			verr.Add(a, "events cannot be raw, proxied, websocket or multipart actions")
+		}
+		if a.Params != nil && len(a.Params.Type.ToObject()) > 0 {
+			verr.Add(a, "events cannot define params, the payload carries the event data")
+		}
+	}
+	if a.BatchRoute != nil {
+		verr.Merge(a.validateBatch())
+	}
+	if a.Resumable {
+		verr.Merge(a.validateResumable())
+	}
+	if a.Priority != nil {
+		verr.Merge(a.Priority.Validate(a))
+	}
+	if a.Deprecation != nil {
+		verr.Merge(a.Deprecation.Validate(a))
+	}
+	if a.Flag != nil {
+		verr.Merge(a.Flag.Validate(a))
+	}
+	verr.Merge(validateMiddleware(a, a.Middleware))
+	if a.ProxyURL != "" {
+		if u, err := url.Parse(a.ProxyURL); err != nil {
+			verr.Add(a, "invalid proxy URL %#v: %s", a.ProxyURL, err)
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			verr.Add(a, "invalid proxy URL %#v, must be an absolute http or https URL", a.ProxyURL)
+		}
+		if a.PayloadMultipart {
+			verr.Add(a, "proxied actions cannot use multipart payloads")
+		}
+	}
 	if a.Params != nil {
 		for n, p := range a.Params.Type.ToObject() {
+			if _, ok := p.Metadata["param:style"]; ok && !p.Type.IsArray() {
+				verr.Add(a, "Param %s defines a style but is not an array", n)
+			}
 			if p.Type.IsPrimitive() {
 				if HasFile(p.Type) {
 					verr.Add(a, "Param %s has an invalid type, action params cannot be a file", n)
@@ -437,15 +833,199 @@ func (a *ActionDefinition) ValidateParams() *dslengine.ValidationErrors {
 		} else if p.Type.Kind() == HashKind {
 			verr.Add(a, `parameter %s cannot be a hash, only action payloads may be of type hash`, n)
 		}
+		for _, wc := range wcs {
+			if p.Key != "" && wc == n {
+				verr.Add(a, "path parameter %s cannot set a key, the route wildcard names it", n)
+				break
+			}
+		}
 		ctx := fmt.Sprintf("parameter %s", n)
 		verr.Merge(p.Validate(ctx, a))
 	}
+	if ok {
+		verr.Merge(a.Params.validateKeys("", a))
+	}
 	for _, resp := range a.Responses {
 		verr.Merge(resp.Validate())
 	}
 	return verr.AsError()
 }
 
+// validateResponseTags makes sure the tagged responses of the action refer to a string attribute
+// of a common media type and view.
+func (a *ActionDefinition) validateResponseTags() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	names := make([]string, 0, len(a.Responses))
+	for n, r := range a.Responses {
+		if r.TagAttribute != "" {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	var (
+		first   *ResponseDefinition
+		firstMT *MediaTypeDefinition
+	)
+	values := make(map[string]string)
+	for _, n := range names {
+		r := a.Responses[n]
+		mt, ok := r.Type.(*MediaTypeDefinition)
+		if !ok {
+			mt = Design.MediaTypeWithIdentifier(r.MediaType)
+		}
+		if mt == nil {
+			verr.Add(r, "tagged responses must use a media type defined in the design")
+			continue
+		}
+		view := r.ViewName
+		if view == "" {
+			view = DefaultView
+		}
+		var att *AttributeDefinition
+		if v, ok := mt.Views[view]; ok {
+			att = v.Type.ToObject()[r.TagAttribute]
+		}
+		if att == nil {
+			verr.Add(r, "tag attribute %#v is not rendered by view %#v of media type %s", r.TagAttribute, view, mt.Identifier)
+		} else if att.Type.Kind() != StringKind {
+			verr.Add(r, "tag attribute %#v must be a string", r.TagAttribute)
+		}
+		if first == nil {
+			first, firstMT = r, mt
+		} else if r.TagAttribute != first.TagAttribute || mt != firstMT || r.ViewName != first.ViewName {
+			verr.Add(r, "tagged responses %s and %s must use the same attribute, media type and view", first.Name, r.Name)
+		}
+		if other, ok := values[r.TagValue]; ok {
+			verr.Add(r, "tag value %#v is already used by response %s", r.TagValue, other)
+		}
+		values[r.TagValue] = r.Name
+	}
+	return verr.AsError()
+}
+
+// validateViewSelector makes sure the param or header that selects the response view is a string
+// and that at least one response may be rendered with more than one view.
+func (a *ActionDefinition) validateViewSelector() *dslengine.ValidationErrors {
+	if a.ViewParam == "" && a.ViewHeader == "" {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if a.ViewParam != "" && a.ViewHeader != "" {
+		verr.Add(a, "the response view cannot be selected by both param %s and header %s", a.ViewParam, a.ViewHeader)
+	}
+	if len(a.ResponseViews()) < 2 {
+		verr.Add(a, "the response view is selected by the request but no response media type defines more than one view")
+	}
+	check := func(kind string, atts *AttributeDefinition, name string) {
+		if name == "" || atts == nil {
+			return
+		}
+		if att, ok := atts.Type.ToObject()[name]; ok && att.Type.Kind() != StringKind {
+			verr.Add(a, "view %s %s must be a string", kind, name)
+		}
+	}
+	check("param", a.Params, a.ViewParam)
+	check("header", a.Headers, a.ViewHeader)
+	return verr.AsError()
+}
+
+// validateFieldMask makes sure the field mask param is an array of strings and that the action
+// responses have fields to select.
+func (a *ActionDefinition) validateFieldMask() *dslengine.ValidationErrors {
+	if a.FieldMask == "" {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if len(a.ResponseFields()) == 0 {
+		verr.Add(a, "field mask %s defined but no response media type is an object", a.FieldMask)
+	}
+	if a.Params != nil {
+		if p, ok := a.Params.Type.ToObject()[a.FieldMask]; ok {
+			if !p.Type.IsArray() || p.Type.ToArray().ElemType.Type.Kind() != StringKind {
+				verr.Add(a, "field mask param %s must be an array of strings", a.FieldMask)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
+// validateResumable checks that the action can create resumable uploads: the upload content is
+// sent to the offset endpoints so the action must be a POST action without payload.
+func (a *ActionDefinition) validateResumable() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if len(a.Routes) == 0 {
+		verr.Add(a, "resumable actions must define a route")
+		return verr.AsError()
+	}
+	if a.Routes[0].Verb != "POST" {
+		verr.Add(a, "the first route of resumable actions must use the POST method, got %s", a.Routes[0].Verb)
+	}
+	for _, wc := range ExtractWildcards(a.Routes[0].FullPath()) {
+		if wc == "uploadID" {
+			verr.Add(a, "the route of resumable actions cannot define the uploadID wildcard used by the upload offset endpoints")
+		}
+	}
+	if a.Payload != nil {
+		verr.Add(a, "resumable actions cannot define a payload, the upload content is sent to the upload offset endpoints")
+	}
+	if a.IsRaw() || a.ProxyURL != "" || a.WebSocket() || a.Async {
+		verr.Add(a, "resumable actions cannot be raw, proxied, websocket or asynchronous actions")
+	}
+	return verr.AsError()
+}
+
+// validateBatch checks that the batch endpoint of the action can fan out to the action: the action
+// must accept a JSON payload and all its params must be wildcards of the batch path.
+func (a *ActionDefinition) validateBatch() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	full := a.BatchRoute.FullPath()
+	if a.Payload == nil {
+		verr.Add(a, "batch path %s defined but the action has no payload", full)
+	}
+	if a.PayloadMultipart || a.WebSocket() {
+		verr.Add(a, "batch endpoints cannot be used with multipart or websocket actions")
+	}
+	if a.Headers != nil && len(a.Headers.Type.ToObject()) > 0 {
+		verr.Add(a, "batch actions cannot define headers")
+	}
+	if len(a.Routes) == 0 {
+		return verr.AsError()
+	}
+	route := a.Routes[0]
+	contains := func(names []string, n string) bool {
+		for _, name := range names {
+			if name == n {
+				return true
+			}
+		}
+		return false
+	}
+	wcs := ExtractWildcards(full)
+	routeWcs := ExtractWildcards(route.FullPath())
+	for _, wc := range routeWcs {
+		if !contains(wcs, wc) {
+			verr.Add(a, "batch path %s must define the wildcard %s of the action route", full, wc)
+		}
+	}
+	if a.Params != nil {
+		a.Params.Type.ToObject().IterateAttributes(func(n string, _ *AttributeDefinition) error {
+			if !contains(wcs, n) && !contains(routeWcs, n) {
+				verr.Add(a, "param %s must be a wildcard of the batch path %s", n, full)
+			}
+			return nil
+		})
+	}
+	for _, wc := range wcs {
+		if contains(routeWcs, wc) {
+			continue
+		}
+		if a.Params == nil || a.Params.Type.ToObject()[wc] == nil {
+			verr.Add(a, "wildcard %s of the batch path %s is not an action param", wc, full)
+		}
+	}
+	return verr.AsError()
+}
+
 // validated keeps track of validated attributes to handle cyclical definitions.
 var validated = make(map[*AttributeDefinition]bool)
 
@@ -466,6 +1046,9 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	if ctx != "" {
 		ctx += " - "
 	}
+	if a.Type == Decimal {
+		verr.Add(parent, "%sthe Decimal type can only be used to define attributes", ctx)
+	}
 	// If both Default and Enum are given, make sure the Default value is one of Enum values.
 	// TODO: We only do the default value and enum check just for primitive types.
 	// Issue 388 (https://github.com/goadesign/goa/issues/388) will address this for other types.
@@ -481,6 +1064,12 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 			verr.Add(parent, "%sdefault value %#v is not one of the accepted values: %#v", ctx, a.DefaultValue, a.Validation.Values)
 		}
 	}
+	if a.ReadOnly && a.WriteOnly {
+		verr.Add(parent, "%sattribute cannot be both read-only and write-only", ctx)
+	}
+	if len(a.Sanitizers) > 0 && a.Type.Kind() != StringKind {
+		verr.Add(parent, "%ssanitizers can only be applied to string attributes", ctx)
+	}
 	o := a.Type.ToObject()
 	if o != nil {
 		for _, n := range a.AllRequired() {
@@ -495,6 +1084,9 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 				verr.Add(parent, `%srequired field "%s" does not exist`, ctx, n)
 			}
 		}
+		verr.Merge(a.validateFieldNumbers(ctx, parent))
+		verr.Merge(a.validateKeys(ctx, parent))
+		verr.Merge(a.validateFieldGroups(ctx, parent))
 		for n, att := range o {
 			ctx = fmt.Sprintf("field %s", n)
 			verr.Merge(att.Validate(ctx, parent))
@@ -509,6 +1101,92 @@ func (a *AttributeDefinition) Validate(ctx string, parent dslengine.Definition)
 	return verr.AsError()
 }
 
+// validateFieldNumbers makes sure the field numbers of the child attributes are valid protocol
+// buffers field numbers and that no two child attributes use the same number.
+func (a *AttributeDefinition) validateFieldNumbers(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	o := a.Type.ToObject()
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	used := make(map[int]string)
+	for _, n := range names {
+		num := o[n].FieldNumber
+		if num == 0 {
+			continue
+		}
+		if num < 0 || num > MaxFieldNumber || (num >= 19000 && num <= 19999) {
+			verr.Add(parent, "%sinvalid field number %d for field %s", ctx, num, n)
+			continue
+		}
+		if other, ok := used[num]; ok {
+			verr.Add(parent, "%sfield number %d is used by both %s and %s", ctx, num, other, n)
+			continue
+		}
+		used[num] = n
+	}
+	return verr.AsError()
+}
+
+// validateKeys makes sure no two child attributes use the same key, see the Key DSL.
+func (a *AttributeDefinition) validateKeys(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	o := a.Type.ToObject()
+	names := make([]string, 0, len(o))
+	for n := range o {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	used := make(map[string]string)
+	for _, n := range names {
+		key := o[n].AttributeKey(n)
+		if other, ok := used[key]; ok {
+			verr.Add(parent, "%skey %#v is used by both %s and %s", ctx, key, other, n)
+			continue
+		}
+		used[key] = n
+	}
+	return verr.AsError()
+}
+
+// validateFieldGroups makes sure the RequiredTogether, MutuallyExclusive and AtLeastOneOf groups
+// name existing child attributes and that mutually exclusive attributes are neither required nor
+// have a default value.
+func (a *AttributeDefinition) validateFieldGroups(ctx string, parent dslengine.Definition) *dslengine.ValidationErrors {
+	if a.Validation == nil {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	o := a.Type.ToObject()
+	check := func(rule string, groups [][]string) {
+		for _, g := range groups {
+			for _, n := range g {
+				if _, ok := o[n]; !ok {
+					verr.Add(parent, `%s%s field "%s" does not exist`, ctx, rule, n)
+				}
+			}
+		}
+	}
+	check("required together", a.Validation.RequiredTogether)
+	check("mutually exclusive", a.Validation.MutuallyExclusive)
+	check("at least one of", a.Validation.AtLeastOneOf)
+	for _, g := range a.Validation.MutuallyExclusive {
+		for _, n := range g {
+			if _, ok := o[n]; !ok {
+				continue
+			}
+			if a.IsRequired(n) {
+				verr.Add(parent, `%smutually exclusive field "%s" cannot be required`, ctx, n)
+			} else if a.HasDefaultValue(n) {
+				verr.Add(parent, `%smutually exclusive field "%s" cannot have a default value`, ctx, n)
+			}
+		}
+	}
+	return verr.AsError()
+}
+
 // Validate checks that the response definition is consistent: its status is set and the media
 // type definition if any is valid.
 func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
@@ -519,6 +1197,262 @@ func (r *ResponseDefinition) Validate() *dslengine.ValidationErrors {
 	if r.Status == 0 {
 		verr.Add(r, "response status not defined")
 	}
+	if r.Type != nil || r.MediaType != "" {
+		if r.NoBody {
+			verr.Add(r, "responses with no body cannot define a type or a media type")
+		} else if r.Status == 204 || r.Status == 304 {
+			verr.Add(r, "responses with status %d cannot have a body, remove the type and media type", r.Status)
+		}
+	}
+	verr.Merge(r.validateLocation())
+	verr.Merge(r.validateTrailers())
+	verr.Merge(r.validateVersions())
+	verr.Merge(r.validateParts())
+	verr.Merge(validateHeaderGroups(r, r.HeaderGroups, r.Headers))
+	return verr.AsError()
+}
+
+// validateHeaderGroups makes sure the header groups of an action or a response have unique
+// names, map to objects with primitive attributes and do not define the same header twice.
+func validateHeaderGroups(def dslengine.Definition, groups []*HeaderGroupDefinition, headers *AttributeDefinition) *dslengine.ValidationErrors {
+	if len(groups) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	keys := make(map[string]string)
+	if headers != nil {
+		for n, att := range headers.Type.ToObject() {
+			keys[http.CanonicalHeaderKey(att.AttributeKey(n))] = "the headers"
+		}
+	}
+	names := make(map[string]bool)
+	for _, g := range groups {
+		if g.Name == "" {
+			verr.Add(def, "header group name cannot be empty")
+			continue
+		}
+		if names[g.Name] {
+			verr.Add(g, "header group is defined more than once")
+			continue
+		}
+		names[g.Name] = true
+		if g.Prefix == "" {
+			verr.Add(g, "header group prefix cannot be empty")
+		}
+		if g.Type == nil || !g.Type.IsObject() {
+			verr.Add(g, "header group type must be an object")
+			continue
+		}
+		g.Type.ToObject().IterateAttributes(func(n string, att *AttributeDefinition) error {
+			switch att.Type.Kind() {
+			case BooleanKind, IntegerKind, NumberKind, StringKind, DateTimeKind:
+			default:
+				verr.Add(g, "attribute %s has an invalid type, header group attributes must be booleans, integers, numbers, strings or date times", n)
+				return nil
+			}
+			key := g.HeaderKey(n)
+			if other, ok := keys[key]; ok {
+				verr.Add(g, "header %s is also defined by %s", key, other)
+				return nil
+			}
+			keys[key] = g.Context()
+			return nil
+		})
+	}
+	return verr
+}
+
+// validateQueryObjects makes sure the query objects of the action have unique names that do not
+// clash with the action params and map to objects.
+func (a *ActionDefinition) validateQueryObjects() *dslengine.ValidationErrors {
+	if len(a.QueryObjects) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	var params Object
+	if a.Params != nil {
+		params = a.Params.Type.ToObject()
+	}
+	names := make(map[string]bool)
+	for _, q := range a.QueryObjects {
+		if q.Name == "" {
+			verr.Add(a, "query object name cannot be empty")
+			continue
+		}
+		if names[q.Name] {
+			verr.Add(q, "query object is defined more than once")
+			continue
+		}
+		names[q.Name] = true
+		if _, ok := params[q.Name]; ok {
+			verr.Add(q, "query object name clashes with the param of the same name")
+		}
+		if q.Type == nil || !q.Type.IsObject() {
+			verr.Add(q, "query object type must be an object")
+			continue
+		}
+		if HasFile(q.Type) {
+			verr.Add(q, "query object type cannot contain files")
+		}
+	}
+	return verr.AsError()
+}
+
+// validateParts makes sure the parts of multipart/mixed responses have unique names and a type
+// or a content type.
+func (r *ResponseDefinition) validateParts() *dslengine.ValidationErrors {
+	if len(r.Parts) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if r.Type != nil {
+		verr.Add(r, "responses with parts cannot define a type")
+	}
+	if r.MediaType != "multipart/mixed" {
+		verr.Add(r, "responses with parts must use the multipart/mixed media type, got %#v", r.MediaType)
+	}
+	names := make(map[string]bool)
+	for _, p := range r.Parts {
+		if p.Name == "" {
+			verr.Add(p, "part name cannot be empty")
+		} else if names[p.Name] {
+			verr.Add(p, "part is defined more than once")
+		}
+		names[p.Name] = true
+		if p.Type == nil && p.MediaType == "" {
+			verr.Add(p, "part must define a type or a content type")
+		}
+	}
+	return verr.AsError()
+}
+
+// validateLocation makes sure redirect responses use a 3xx status and that the attribute used to
+// set the Location header is a string attribute of the response media type.
+func (r *ResponseDefinition) validateLocation() *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	if !r.Redirect && r.LocationAttribute == "" {
+		return nil
+	}
+	redirect := r.Status >= 300 && r.Status < 400
+	if r.Status != 0 && r.Redirect && !redirect {
+		verr.Add(r, "redirect responses must use a 3xx status")
+	}
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if r.LocationAttribute == "" {
+		if mt != nil {
+			verr.Add(r, "redirect responses with a media type must use Location to set the Location header")
+		}
+		return verr.AsError()
+	}
+	if r.Status != 0 && !redirect && r.Status != 201 {
+		verr.Add(r, "Location may only be used in Created or 3xx responses")
+	}
+	if mt == nil {
+		verr.Add(r, "Location may only be used in responses with a media type defined in the design")
+		return verr.AsError()
+	}
+	att := mt.Type.ToObject()[r.LocationAttribute]
+	if att == nil {
+		verr.Add(r, "Location attribute %#v is not an attribute of media type %s", r.LocationAttribute, mt.Identifier)
+	} else if att.Type.Kind() != StringKind {
+		verr.Add(r, "Location attribute %#v must be a string", r.LocationAttribute)
+	}
+	return verr.AsError()
+}
+
+// validateTrailers makes sure the attributes mapped to the trailers are primitive attributes of
+// the response media type.
+func (r *ResponseDefinition) validateTrailers() *dslengine.ValidationErrors {
+	if len(r.Trailers) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil {
+		verr.Add(r, "Trailer may only be used in responses with a media type defined in the design")
+		return verr.AsError()
+	}
+	for n, name := range r.Trailers {
+		if name == "" {
+			verr.Add(r, "trailer %s attribute name cannot be empty", n)
+			continue
+		}
+		att := mt.Type.ToObject()[name]
+		if att == nil {
+			verr.Add(r, "trailer %s attribute %#v is not an attribute of media type %s", n, name, mt.Identifier)
+			continue
+		}
+		switch att.Type.Kind() {
+		case BooleanKind, IntegerKind, NumberKind, StringKind, DateTimeKind:
+		default:
+			verr.Add(r, "trailer %s attribute %#v must be a boolean, an integer, a number, a string or a date time", n, name)
+		}
+	}
+	return verr.AsError()
+}
+
+// validateVersions makes sure the schema revisions of the response body apply to a media type
+// and use distinct versions.
+func (r *ResponseDefinition) validateVersions() *dslengine.ValidationErrors {
+	if len(r.Versions) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	mt, ok := r.Type.(*MediaTypeDefinition)
+	if !ok {
+		mt = Design.MediaTypeWithIdentifier(r.MediaType)
+	}
+	if mt == nil || !mt.IsObject() {
+		verr.Add(r, "SchemaVersion may only be used in responses with an object media type defined in the design")
+	}
+	verr.Merge(validateSchemaVersions(r.Versions))
+	return verr.AsError()
+}
+
+// validatePayloadVersions makes sure the schema revisions of the payload apply to an object
+// payload that is not multipart and use distinct versions.
+func (a *ActionDefinition) validatePayloadVersions() *dslengine.ValidationErrors {
+	if len(a.PayloadVersions) == 0 {
+		return nil
+	}
+	verr := new(dslengine.ValidationErrors)
+	if a.Payload == nil || !a.Payload.IsObject() || a.PayloadMultipart {
+		verr.Add(a, "SchemaVersion may only be used in actions with an object payload that is not multipart")
+	}
+	for _, v := range a.PayloadVersions {
+		if v.Type != nil && Design.Types[v.Type.TypeName] != v.Type {
+			verr.Add(v, "payload schema version type must be a type defined with Type")
+		}
+	}
+	verr.Merge(validateSchemaVersions(a.PayloadVersions))
+	return verr.AsError()
+}
+
+// validateSchemaVersions makes sure the given schema revisions have unique non empty versions and
+// object types.
+func validateSchemaVersions(versions []*SchemaVersionDefinition) *dslengine.ValidationErrors {
+	verr := new(dslengine.ValidationErrors)
+	seen := make(map[string]bool)
+	for _, v := range versions {
+		if v.Version == "" {
+			verr.Add(v.Parent, "schema version cannot be empty")
+			continue
+		}
+		if seen[v.Version] {
+			verr.Add(v, "schema version is defined more than once")
+			continue
+		}
+		seen[v.Version] = true
+		if v.Type == nil || !v.Type.IsObject() {
+			verr.Add(v, "schema version type must be an object")
+		}
+	}
 	return verr.AsError()
 }
 
@@ -646,3 +1580,13 @@ func (v *ViewDefinition) Validate() *dslengine.ValidationErrors {
 	verr.Merge(v.AttributeDefinition.Validate("", v))
 	return verr.AsError()
 }
+
+// containsString returns true if vals contains val.
+func containsString(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	. "github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
@@ -291,6 +292,103 @@ var _ = Describe("Validation", func() {
 				Ω(Design.Types["bar"].Validation.Required).Should(Equal([]string{attName}))
 			})
 		})
+
+		Context("with sanitizers on a non-string attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, Integer, func() {
+						Sanitize("trim")
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("sanitizers can only be applied to string attributes"))
+			})
+		})
+
+		Context("with a read-only and write-only attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String, func() {
+						ReadOnly()
+						WriteOnly()
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot be both read-only and write-only"))
+			})
+		})
+
+		Context("with field group validations", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String)
+					Attribute("start", DateTime)
+					Attribute("end", DateTime)
+					RequiredTogether("start", "end")
+					MutuallyExclusive(attName, "start")
+					AtLeastOneOf(attName, "end")
+				}
+			})
+
+			It("records the validations", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				val := Design.Types["bar"].Validation
+				Ω(val).ShouldNot(BeNil())
+				Ω(val.RequiredTogether).Should(Equal([][]string{{"start", "end"}}))
+				Ω(val.MutuallyExclusive).Should(Equal([][]string{{attName, "start"}}))
+				Ω(val.AtLeastOneOf).Should(Equal([][]string{{attName, "end"}}))
+			})
+		})
+
+		Context("with a field group naming an unknown attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String)
+					RequiredTogether(attName, "foo")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`required together field "foo" does not exist`))
+			})
+		})
+
+		Context("with a field group with a single attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String)
+					AtLeastOneOf(attName)
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a required mutually exclusive attribute", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Attribute(attName, String)
+					Attribute("foo", String, func() { Default("foo") })
+					Required(attName)
+					MutuallyExclusive(attName, "foo")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`mutually exclusive field "attName" cannot be required`))
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`mutually exclusive field "foo" cannot have a default value`))
+			})
+		})
 	})
 
 	Context("actions with different http methods", func() {
@@ -312,6 +410,64 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Context("with conflicting routes", func() {
+		It("reports actions of different resources using the same route", func() {
+			dslengine.Reset()
+
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/things/:id"))
+				})
+			})
+			Resource("two", func() {
+				Action("second", func() {
+					Routing(GET("/things/:id"))
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(
+				`route GET "/things/:id" conflicts with route GET "/things/:id" of resource "one" action "first"`))
+		})
+
+		It("reports a file server using the route of an action", func() {
+			dslengine.Reset()
+
+			Resource("one", func() {
+				Files("/things/*filepath", "/tmp")
+				Action("first", func() {
+					Routing(GET("/things/*path"))
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).Should(HaveOccurred())
+			Ω(dslengine.Errors.Error()).Should(ContainSubstring(`route GET "/things/*filepath" conflicts with route GET "/things/*path"`))
+		})
+
+		It("accepts the same path with different methods", func() {
+			dslengine.Reset()
+
+			Resource("one", func() {
+				Action("first", func() {
+					Routing(GET("/things/:id"))
+				})
+			})
+			Resource("two", func() {
+				Action("second", func() {
+					Routing(PUT("/things/:id"))
+				})
+			})
+
+			dslengine.Run()
+
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+		})
+	})
+
 	Context("with an action", func() {
 		var dsl func()
 
@@ -342,6 +498,94 @@ var _ = Describe("Validation", func() {
 			})
 		})
 
+		Context("which is raw and has a payload", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Metadata("http:raw")
+					Payload(func() {
+						Attribute("name", String)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": raw actions cannot define a payload, read the request body from the context instead`,
+				))
+			})
+		})
+
+		Context("which has a lazy body and a payload cache key", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Metadata("http:body:lazy")
+					Payload(func() {
+						Attribute("name", String)
+					})
+					Cache(time.Minute, "name")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": cache key name is a payload attribute, the payload of actions with lazy body decoding is not available to the cache`,
+				))
+			})
+		})
+
+		Context("which has a multipart payload with value fields", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Payload(func() {
+						Metadata("struct:field:value")
+						Attribute("name", String)
+						Required("name")
+					})
+					MultipartForm()
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": multipart payload attribute name cannot use the struct:field:value metadata`,
+				))
+			})
+		})
+
+		Context("which has a payload with duplicate keys", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Payload(func() {
+						Attribute("createdAt", String, func() { Key("created") })
+						Attribute("created", String)
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`type "BarFooPayload": action payload - key "created" is used by both created and createdAt`,
+				))
+			})
+		})
+
+		Context("which has a path param with a key", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Routing(GET("/buz/:id"))
+					Params(func() {
+						Param("id", Integer, func() { Key("ID") })
+					})
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors.Error()).Should(Equal(
+					`resource "foo" action "bar": path parameter id cannot set a key, the route wildcard names it`,
+				))
+			})
+		})
+
 		Context("which has a file array type param", func() {
 			BeforeEach(func() {
 				dsl = func() {
@@ -419,6 +663,366 @@ var _ = Describe("Validation", func() {
 		})
 	})
 
+	Context("with an action with tagged responses", func() {
+		var tagType DataType
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			job := MediaType("application/vnd.goa.job", func() {
+				TypeName("job")
+				Attributes(func() {
+					Attribute("state", tagType)
+				})
+				View("default", func() {
+					Attribute("state")
+				})
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					Response(OK, job, func() {
+						Tag("state", "done")
+					})
+					Response(Accepted, job, func() {
+						Tag("state", "pending")
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("using a string attribute", func() {
+			BeforeEach(func() {
+				tagType = String
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("using a non string attribute", func() {
+			BeforeEach(func() {
+				tagType = Integer
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`tag attribute "state" must be a string`))
+			})
+		})
+	})
+
+	Context("with an action selecting the response view", func() {
+		var views []string
+		var header bool
+
+		BeforeEach(func() {
+			views = []string{"default", "tiny"}
+			header = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.goa.bottle", func() {
+				TypeName("bottle")
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+				})
+				for _, v := range views {
+					View(v, func() {
+						Attribute("id")
+					})
+				}
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					ViewParam("view")
+					if header {
+						ViewHeader("X-View")
+					}
+					Response(OK, bottle)
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error and defines the view param", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			view := Design.Resources["foo"].Actions["bar"].Params.Type.ToObject()["view"]
+			Ω(view).ShouldNot(BeNil())
+			Ω(view.Type).Should(Equal(String))
+			Ω(view.DefaultValue).Should(Equal("default"))
+			Ω(view.Validation.Values).Should(Equal([]interface{}{"default", "tiny"}))
+		})
+
+		Context("with a param and a header", func() {
+			BeforeEach(func() {
+				header = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("cannot be selected by both param view and header X-View"))
+			})
+		})
+
+		Context("with a single view", func() {
+			BeforeEach(func() {
+				views = []string{"default"}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("no response media type defines more than one view"))
+			})
+		})
+	})
+
+	Context("with an action with a field mask", func() {
+		var noContent bool
+
+		BeforeEach(func() {
+			noContent = false
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			bottle := MediaType("application/vnd.goa.bottle", func() {
+				TypeName("bottle")
+				Attributes(func() {
+					Attribute("id", Integer)
+					Attribute("name", String)
+					Attribute("secret", String, func() { WriteOnly() })
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+					Attribute("secret")
+				})
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					FieldMask()
+					if noContent {
+						Response(NoContent)
+					} else {
+						Response(OK, bottle)
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		It("produces no error and defines the field mask param", func() {
+			Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			fields := Design.Resources["foo"].Actions["bar"].Params.Type.ToObject()["fields"]
+			Ω(fields).ShouldNot(BeNil())
+			Ω(fields.Type.IsArray()).Should(BeTrue())
+			Ω(fields.Type.ToArray().ElemType.Validation.Values).Should(Equal([]interface{}{"id", "name"}))
+			Ω(fields.ParamSeparator()).Should(Equal(","))
+		})
+
+		Context("with no response media type", func() {
+			BeforeEach(func() {
+				noContent = true
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("field mask fields defined but no response media type is an object"))
+			})
+		})
+	})
+
+	Context("with an action with a batch endpoint", func() {
+		var dsl func()
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(POST("/accounts/:accountID/bars"))
+					dsl()
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("whose path defines the action params", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() { Param("accountID", Integer) })
+					Payload(func() { Attribute("name", String) })
+					Batch("/accounts/:accountID/bars/batch")
+				}
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+				Ω(Design.Resources["foo"].Actions["bar"].BatchRoute.FullPath()).Should(Equal("/accounts/:accountID/bars/batch"))
+			})
+		})
+
+		Context("with no payload", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Batch("/accounts/:accountID/bars/batch")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("batch path /accounts/:accountID/bars/batch defined but the action has no payload"))
+			})
+		})
+
+		Context("whose path misses a wildcard of the action route", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Payload(func() { Attribute("name", String) })
+					Batch("/bars/batch")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("batch path /bars/batch must define the wildcard accountID of the action route"))
+			})
+		})
+
+		Context("with a query string param", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Params(func() { Param("dryRun", Boolean) })
+					Payload(func() { Attribute("name", String) })
+					Batch("/accounts/:accountID/bars/batch")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("param dryRun must be a wildcard of the batch path /accounts/:accountID/bars/batch"))
+			})
+		})
+
+		Context("whose path conflicts with the action route", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Payload(func() { Attribute("name", String) })
+					Batch("/accounts/:accountID/bars")
+				}
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring(`route POST "/accounts/:accountID/bars" conflicts with route POST "/accounts/:accountID/bars"`))
+			})
+		})
+	})
+
+	Context("with an action with a response with no body", func() {
+		var status int
+		var dsl func()
+
+		BeforeEach(func() {
+			dsl = nil
+		})
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			mt := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("name")
+				})
+				View("default", func() {
+					Attribute("name")
+				})
+			})
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					if dsl != nil {
+						Response("Empty", mt, func() {
+							Status(status)
+							dsl()
+						})
+					} else {
+						Response("Empty", mt, func() {
+							Status(status)
+						})
+					}
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("using a 204 status", func() {
+			BeforeEach(func() {
+				status = 204
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("responses with status 204 cannot have a body"))
+			})
+		})
+
+		Context("using NoBody", func() {
+			BeforeEach(func() {
+				status = 200
+				dsl = NoBody
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("responses with no body cannot define a type or a media type"))
+			})
+		})
+	})
+
+	Context("with an action with a redirect response", func() {
+		var status int
+
+		JustBeforeEach(func() {
+			dslengine.Reset()
+			Resource("foo", func() {
+				Action("bar", func() {
+					Routing(GET("/buz"))
+					Response("Moved", func() {
+						Status(status)
+						Redirect()
+					})
+				})
+			})
+			dslengine.Run()
+		})
+
+		Context("using a 3xx status", func() {
+			BeforeEach(func() {
+				status = 301
+			})
+
+			It("produces no error", func() {
+				Ω(dslengine.Errors).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("using a non 3xx status", func() {
+			BeforeEach(func() {
+				status = 200
+			})
+
+			It("produces an error", func() {
+				Ω(dslengine.Errors).Should(HaveOccurred())
+				Ω(dslengine.Errors.Error()).Should(ContainSubstring("redirect responses must use a 3xx status"))
+			})
+		})
+	})
+
 	Describe("EncoderDefinition", func() {
 		var (
 			enc           *EncodingDefinition
@@ -0,0 +1,146 @@
+package design
+
+import (
+	"sort"
+
+	"github.com/goadesign/goa/dslengine"
+)
+
+// Visitor is implemented by the tools that inspect a design such as custom generators,
+// documentation generators or policy checks. Visit is called by Walk for each definition
+// encountered. If the returned visitor w is not nil Walk visits each of the children of the
+// definition with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(def dslengine.Definition) (w Visitor)
+}
+
+// Walk traverses the design definitions in depth-first order: it starts by calling v.Visit(def);
+// def must not be nil. If the visitor w returned by v.Visit(def) is not nil, Walk is invoked
+// recursively with visitor w for each of the children of def, followed by a call of w.Visit(nil).
+//
+// The children of an API are its servers, user types, media types, response templates, security
+// schemes and resources. The children of a resource are its params, headers, responses, actions
+// and file servers. The children of an action are its routes, params, headers, payload and
+// responses. The children of a media type are its attribute, views and links. The children of an
+// attribute are the attributes of its object, array or hash type, the user types and media types
+// it refers to are not traversed as they are visited once as children of the API.
+// Children are visited in a stable order: maps are traversed in alphabetical order of their keys.
+// Walk does not modify the definitions and should only be called once the DSL has run.
+func Walk(v Visitor, def dslengine.Definition) {
+	if v = v.Visit(def); v == nil {
+		return
+	}
+	switch d := def.(type) {
+	case *APIDefinition:
+		for _, s := range d.Servers {
+			Walk(v, s)
+		}
+		d.IterateUserTypes(func(u *UserTypeDefinition) error {
+			Walk(v, u)
+			return nil
+		})
+		d.IterateMediaTypes(func(m *MediaTypeDefinition) error {
+			Walk(v, m)
+			return nil
+		})
+		walkResponses(v, d.Responses)
+		for _, s := range d.SecuritySchemes {
+			Walk(v, s)
+		}
+		d.IterateResources(func(r *ResourceDefinition) error {
+			Walk(v, r)
+			return nil
+		})
+	case *ResourceDefinition:
+		walkAttribute(v, d.Params)
+		walkAttribute(v, d.Headers)
+		walkResponses(v, d.Responses)
+		d.IterateActions(func(a *ActionDefinition) error {
+			Walk(v, a)
+			return nil
+		})
+		d.IterateFileServers(func(f *FileServerDefinition) error {
+			Walk(v, f)
+			return nil
+		})
+	case *ActionDefinition:
+		for _, r := range d.Routes {
+			Walk(v, r)
+		}
+		walkAttribute(v, d.Params)
+		walkAttribute(v, d.Headers)
+		if d.Payload != nil {
+			Walk(v, d.Payload)
+		}
+		walkResponses(v, d.Responses)
+	case *ResponseDefinition:
+		walkAttribute(v, d.Headers)
+	case *MediaTypeDefinition:
+		walkAttribute(v, d.AttributeDefinition)
+		d.IterateViews(func(view *ViewDefinition) error {
+			Walk(v, view)
+			return nil
+		})
+		names := make([]string, 0, len(d.Links))
+		for n := range d.Links {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			Walk(v, d.Links[n])
+		}
+	case *UserTypeDefinition:
+		walkAttribute(v, d.AttributeDefinition)
+	case *AttributeDefinition:
+		switch actual := d.Type.(type) {
+		case *Array:
+			walkAttribute(v, actual.ElemType)
+		case *Hash:
+			walkAttribute(v, actual.KeyType)
+			walkAttribute(v, actual.ElemType)
+		case Object:
+			actual.IterateAttributes(func(_ string, att *AttributeDefinition) error {
+				walkAttribute(v, att)
+				return nil
+			})
+		}
+	}
+	v.Visit(nil)
+}
+
+// Inspect traverses the design definitions in depth-first order: it starts by calling
+// f(def); def must not be nil. If f returns true, Inspect invokes f recursively for each of the
+// children of def, followed by a call of f(nil).
+func Inspect(def dslengine.Definition, f func(dslengine.Definition) bool) {
+	Walk(inspector(f), def)
+}
+
+// inspector implements Visitor with a function.
+type inspector func(dslengine.Definition) bool
+
+// Visit calls the inspector function.
+func (f inspector) Visit(def dslengine.Definition) Visitor {
+	if f(def) {
+		return f
+	}
+	return nil
+}
+
+// walkAttribute walks the given attribute if not nil.
+func walkAttribute(v Visitor, att *AttributeDefinition) {
+	if att != nil {
+		Walk(v, att)
+	}
+}
+
+// walkResponses walks the given responses in alphabetical order of their names.
+func walkResponses(v Visitor, responses map[string]*ResponseDefinition) {
+	names := make([]string, 0, len(responses))
+	for n := range responses {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		Walk(v, responses[n])
+	}
+}
@@ -0,0 +1,125 @@
+package design_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// visitor records the contexts of the definitions it visits.
+type visitor struct {
+	visited []string
+	skip    string
+}
+
+func (v *visitor) Visit(def dslengine.Definition) Visitor {
+	if def == nil {
+		return nil
+	}
+	if _, ok := def.(*AttributeDefinition); ok {
+		return v
+	}
+	v.visited = append(v.visited, def.Context())
+	if def.Context() == v.skip {
+		return nil
+	}
+	return v
+}
+
+var _ = Describe("Walk", func() {
+	var v *visitor
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		v = &visitor{}
+		API("walked", func() {})
+		Type("Bar", func() {
+			Attribute("name", String)
+		})
+		MediaType("application/vnd.foo", func() {
+			Attributes(func() {
+				Attribute("name", String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("foo", func() {
+			Action("show", func() {
+				Routing(GET("/:id"))
+				Response(OK, "application/vnd.foo")
+			})
+			Action("create", func() {
+				Routing(POST(""))
+				Payload("Bar")
+				Response(Created)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		Walk(v, Design)
+	})
+
+	It("visits the definitions in order", func() {
+		Ω(v.visited).Should(Equal([]string{
+			`API "walked"`,
+			`type "Bar"`,
+			`type "Foo"`,
+			`view "default" of type "Foo"`,
+			`resource "foo"`,
+			`resource "foo" action "create"`,
+			`route POST "" of resource "foo" action "create"`,
+			`type "CreateFooPayload"`,
+			`response "Created" of resource "foo" action "create"`,
+			`resource "foo" action "show"`,
+			`route GET "/:id" of resource "foo" action "show"`,
+			`response "OK" of resource "foo" action "show"`,
+		}))
+	})
+
+	Context("with a visitor that skips a definition", func() {
+		BeforeEach(func() {
+			v.skip = `resource "foo"`
+		})
+
+		It("does not visit its children", func() {
+			Ω(v.visited).Should(HaveLen(5))
+			Ω(v.visited[4]).Should(Equal(`resource "foo"`))
+		})
+	})
+})
+
+var _ = Describe("Inspect", func() {
+	var names []string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		names = nil
+		Type("Bar", func() {
+			Attribute("name", String)
+			Attribute("tags", ArrayOf(String))
+			Attribute("baz", "Baz")
+		})
+		Type("Baz", func() {
+			Attribute("id", Integer)
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		Inspect(Design.Types["Bar"], func(def dslengine.Definition) bool {
+			if att, ok := def.(*AttributeDefinition); ok {
+				names = append(names, att.Type.Name())
+			}
+			return true
+		})
+	})
+
+	It("visits the attributes of the type without traversing the referenced types", func() {
+		Ω(names).Should(Equal([]string{"object", "object", "string", "array", "string"}))
+	})
+})
@@ -0,0 +1,117 @@
+package goa
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timeType is the reflect type of time.Time values which are compared with Equal.
+var timeType = reflect.TypeOf(time.Time{})
+
+// Diff returns the paths of the fields whose values differ between a and b. a and b must be of
+// the same type, typically a media type struct generated for a design that uses the Diff DSL.
+// The paths use the names given by the "json" struct tags: nested fields are separated with
+// dots and array indices and hash keys are enclosed in brackets, e.g. "items[0].sku" or
+// "labels[color]". A field that is nil in only one of the values is reported as a whole, the
+// empty path denotes a and b themselves. Diff returns nil if a and b are equal.
+func Diff(a, b interface{}) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		if va.IsValid() != vb.IsValid() {
+			return []string{""}
+		}
+		return nil
+	}
+	if va.Type() != vb.Type() {
+		panic(fmt.Sprintf("goa: cannot diff values of types %s and %s", va.Type(), vb.Type())) // bug
+	}
+	var paths []string
+	diffValues(va, vb, "", &paths)
+	return paths
+}
+
+// diffValues appends the paths of the fields that differ between a and b to paths. path is the
+// path of a and b.
+func diffValues(a, b reflect.Value, path string, paths *[]string) {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		ea, eb := a.Elem(), b.Elem()
+		if ea.Type() != eb.Type() {
+			*paths = append(*paths, path)
+			return
+		}
+		diffValues(ea, eb, path, paths)
+	case reflect.Struct:
+		if a.Type() == timeType {
+			if !a.Interface().(time.Time).Equal(b.Interface().(time.Time)) {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := f.Name
+			if tag := f.Tag.Get("json"); tag != "" {
+				if tag == "-" {
+					continue
+				}
+				if n := strings.Split(tag, ",")[0]; n != "" {
+					name = n
+				}
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValues(a.Field(i), b.Field(i), name, paths)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() || a.Kind() == reflect.Slice && a.IsNil() != b.IsNil() {
+			*paths = append(*paths, path)
+			return
+		}
+		for i := 0; i < a.Len(); i++ {
+			diffValues(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i), paths)
+		}
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() {
+			*paths = append(*paths, path)
+			return
+		}
+		keys := make(map[string]reflect.Value, a.Len()+b.Len())
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		names := make([]string, 0, len(keys))
+		for n := range keys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			k := keys[n]
+			p := fmt.Sprintf("%s[%s]", path, n)
+			ea, eb := a.MapIndex(k), b.MapIndex(k)
+			if !ea.IsValid() || !eb.IsValid() {
+				*paths = append(*paths, p)
+				continue
+			}
+			diffValues(ea, eb, p, paths)
+		}
+	default:
+		if a.Interface() != b.Interface() {
+			*paths = append(*paths, path)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package goa_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	type item struct {
+		SKU string `form:"sku" json:"sku" xml:"sku"`
+	}
+	type bottle struct {
+		Name   *string           `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"`
+		Rating int               `form:"rating" json:"rating" xml:"rating"`
+		At     time.Time         `form:"at" json:"at" xml:"at"`
+		Items  []*item           `form:"items,omitempty" json:"items,omitempty" xml:"items,omitempty"`
+		Labels map[string]string `form:"labels,omitempty" json:"labels,omitempty" xml:"labels,omitempty"`
+		Origin *item             `form:"origin,omitempty" json:"origin,omitempty" xml:"origin,omitempty"`
+	}
+
+	name := func(n string) *string { return &n }
+	at := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var a, b *bottle
+
+	BeforeEach(func() {
+		a = &bottle{
+			Name:   name("a"),
+			Rating: 1,
+			At:     at,
+			Items:  []*item{{SKU: "x"}, {SKU: "y"}},
+			Labels: map[string]string{"color": "red"},
+		}
+		b = &bottle{
+			Name:   name("a"),
+			Rating: 1,
+			At:     at.In(time.FixedZone("PST", -8*3600)),
+			Items:  []*item{{SKU: "x"}, {SKU: "y"}},
+			Labels: map[string]string{"color": "red"},
+		}
+	})
+
+	It("returns nil for equal values", func() {
+		Ω(goa.Diff(a, b)).Should(BeNil())
+		Ω(goa.Diff(nil, nil)).Should(BeNil())
+	})
+
+	It("returns the paths of the modified fields", func() {
+		b.Name = name("b")
+		b.Items[1].SKU = "z"
+		b.Labels["color"] = "white"
+		b.Labels["size"] = "large"
+		b.Origin = &item{SKU: "o"}
+		Ω(goa.Diff(a, b)).Should(Equal([]string{
+			"name",
+			"items[1].sku",
+			"labels[color]",
+			"labels[size]",
+			"origin",
+		}))
+	})
+
+	It("reports arrays whose lengths differ as a whole", func() {
+		b.Items = b.Items[:1]
+		Ω(goa.Diff(a, b)).Should(Equal([]string{"items"}))
+	})
+
+	It("reports nil values", func() {
+		Ω(goa.Diff(a, (*bottle)(nil))).Should(Equal([]string{""}))
+	})
+})
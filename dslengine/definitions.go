@@ -1,6 +1,9 @@
 package dslengine
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type (
 
@@ -94,6 +97,15 @@ type (
 		// Maximum represents a maximum value validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor17.
 		Maximum *float64
+		// ExclusiveMinimum represents an exclusive minimum value validation, values must be
+		// strictly greater than ExclusiveMinimum.
+		ExclusiveMinimum *float64
+		// ExclusiveMaximum represents an exclusive maximum value validation, values must be
+		// strictly less than ExclusiveMaximum.
+		ExclusiveMaximum *float64
+		// MultipleOf represents a multiple of validation as described at
+		// http://json-schema.org/latest/json-schema-validation.html#anchor14.
+		MultipleOf *float64
 		// MinLength represents an minimum length validation as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor29.
 		MinLength *int
@@ -103,6 +115,15 @@ type (
 		// Required list the required fields of object attributes as described at
 		// http://json-schema.org/latest/json-schema-validation.html#anchor61.
 		Required []string
+		// RequiredTogether lists the groups of fields of object attributes that must be
+		// either all set or all missing.
+		RequiredTogether [][]string
+		// MutuallyExclusive lists the groups of fields of object attributes of which at most
+		// one may be set.
+		MutuallyExclusive [][]string
+		// AtLeastOneOf lists the groups of fields of object attributes of which at least one
+		// must be set.
+		AtLeastOneOf [][]string
 	}
 )
 
@@ -141,6 +162,15 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 	if v.Maximum == nil || (other.Maximum != nil && *v.Maximum < *other.Maximum) {
 		v.Maximum = other.Maximum
 	}
+	if v.ExclusiveMinimum == nil || (other.ExclusiveMinimum != nil && *v.ExclusiveMinimum > *other.ExclusiveMinimum) {
+		v.ExclusiveMinimum = other.ExclusiveMinimum
+	}
+	if v.ExclusiveMaximum == nil || (other.ExclusiveMaximum != nil && *v.ExclusiveMaximum < *other.ExclusiveMaximum) {
+		v.ExclusiveMaximum = other.ExclusiveMaximum
+	}
+	if v.MultipleOf == nil {
+		v.MultipleOf = other.MultipleOf
+	}
 	if v.MinLength == nil || (other.MinLength != nil && *v.MinLength > *other.MinLength) {
 		v.MinLength = other.MinLength
 	}
@@ -148,6 +178,32 @@ func (v *ValidationDefinition) Merge(other *ValidationDefinition) {
 		v.MaxLength = other.MaxLength
 	}
 	v.AddRequired(other.Required)
+	v.RequiredTogether = addGroups(v.RequiredTogether, other.RequiredTogether)
+	v.MutuallyExclusive = addGroups(v.MutuallyExclusive, other.MutuallyExclusive)
+	v.AtLeastOneOf = addGroups(v.AtLeastOneOf, other.AtLeastOneOf)
+}
+
+// HasFieldGroups returns true if the validation defines any RequiredTogether, MutuallyExclusive
+// or AtLeastOneOf group.
+func (v *ValidationDefinition) HasFieldGroups() bool {
+	return len(v.RequiredTogether) > 0 || len(v.MutuallyExclusive) > 0 || len(v.AtLeastOneOf) > 0
+}
+
+// addGroups appends the field groups of other that are not in groups yet.
+func addGroups(groups, other [][]string) [][]string {
+	for _, g := range other {
+		found := false
+		for _, gg := range groups {
+			if strings.Join(g, ",") == strings.Join(gg, ",") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, g)
+		}
+	}
+	return groups
 }
 
 // AddRequired merges the required fields from other into v
@@ -177,19 +233,49 @@ func (v *ValidationDefinition) HasRequiredOnly() bool {
 	if (v.Minimum != nil) || (v.Maximum != nil) || (v.MaxLength != nil) {
 		return false
 	}
+	if (v.ExclusiveMinimum != nil) || (v.ExclusiveMaximum != nil) || (v.MultipleOf != nil) {
+		return false
+	}
+	if v.HasFieldGroups() {
+		return false
+	}
 	return true
 }
 
+// LowerBound returns the most restrictive of the Minimum and ExclusiveMinimum validations and
+// whether it is exclusive, nil if there is none.
+func (v *ValidationDefinition) LowerBound() (*float64, bool) {
+	if v.ExclusiveMinimum != nil && (v.Minimum == nil || *v.ExclusiveMinimum >= *v.Minimum) {
+		return v.ExclusiveMinimum, true
+	}
+	return v.Minimum, false
+}
+
+// UpperBound returns the most restrictive of the Maximum and ExclusiveMaximum validations and
+// whether it is exclusive, nil if there is none.
+func (v *ValidationDefinition) UpperBound() (*float64, bool) {
+	if v.ExclusiveMaximum != nil && (v.Maximum == nil || *v.ExclusiveMaximum <= *v.Maximum) {
+		return v.ExclusiveMaximum, true
+	}
+	return v.Maximum, false
+}
+
 // Dup makes a shallow dup of the validation.
 func (v *ValidationDefinition) Dup() *ValidationDefinition {
 	return &ValidationDefinition{
-		Values:    v.Values,
-		Format:    v.Format,
-		Pattern:   v.Pattern,
-		Minimum:   v.Minimum,
-		Maximum:   v.Maximum,
-		MinLength: v.MinLength,
-		MaxLength: v.MaxLength,
-		Required:  v.Required,
+		Values:            v.Values,
+		Format:            v.Format,
+		Pattern:           v.Pattern,
+		Minimum:           v.Minimum,
+		Maximum:           v.Maximum,
+		ExclusiveMinimum:  v.ExclusiveMinimum,
+		ExclusiveMaximum:  v.ExclusiveMaximum,
+		MultipleOf:        v.MultipleOf,
+		MinLength:         v.MinLength,
+		MaxLength:         v.MaxLength,
+		Required:          v.Required,
+		RequiredTogether:  v.RequiredTogether,
+		MutuallyExclusive: v.MutuallyExclusive,
+		AtLeastOneOf:      v.AtLeastOneOf,
 	}
 }
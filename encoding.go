@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"mime"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 )
@@ -54,6 +56,7 @@ type (
 	encoderPool struct {
 		fn   EncoderFunc
 		pool *sync.Pool
+		json bool // true if fn is NewJSONEncoder
 	}
 
 	// HTTPDecoder is a Decoder that decodes HTTP request or response bodies given a set of
@@ -70,6 +73,9 @@ type (
 	}
 )
 
+// jsonEncoderPointer identifies NewJSONEncoder among the registered encoder functions.
+var jsonEncoderPointer = reflect.ValueOf(EncoderFunc(NewJSONEncoder)).Pointer()
+
 // NewJSONEncoder is an adapter for the encoding package JSON encoder.
 func NewJSONEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
 
@@ -116,6 +122,13 @@ func (decoder *HTTPDecoder) Decode(v interface{}, body io.Reader, contentType st
 		}
 	}
 	p = decoder.pools[contentType]
+	if p == nil {
+		// Use the decoder of the structured syntax suffix if any, e.g. application/json
+		// for application/vnd.api+json.
+		if base := suffixType(contentType); base != "" {
+			p = decoder.pools[base]
+		}
+	}
 	if p == nil {
 		p = decoder.pools["*/*"]
 	}
@@ -143,6 +156,21 @@ func (decoder *HTTPDecoder) Register(f DecoderFunc, contentTypes ...string) {
 	}
 }
 
+// RegisterMissing sets the decoder used for the specified content types that do not have a
+// decoder yet. Generated code uses it so that decoders registered prior to mounting the
+// controllers take precedence over the design ones.
+func (decoder *HTTPDecoder) RegisterMissing(f DecoderFunc, contentTypes ...string) {
+	var missing []string
+	for _, contentType := range contentTypes {
+		if _, ok := decoder.pools[canonicalMediaType(contentType)]; !ok {
+			missing = append(missing, contentType)
+		}
+	}
+	if len(missing) > 0 {
+		decoder.Register(f, missing...)
+	}
+}
+
 // newDecodePool checks to see if the DecoderFunc returns reusable decoders and if so, creates a
 // pool.
 func newDecodePool(f DecoderFunc) *decoderPool {
@@ -189,20 +217,10 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	if accept == "" {
 		accept = "*/*"
 	}
-	var contentType string
-	for _, t := range encoder.contentTypes {
-		if accept == "*/*" || accept == t {
-			contentType = accept
-			break
-		}
-	}
+	p, contentType := encoder.pool(accept)
 	defer MeasureSince([]string{"goa", "encode", contentType}, now)
-	p := encoder.pools[contentType]
-	if p == nil && contentType != "*/*" {
-		p = encoder.pools["*/*"]
-	}
 	if p == nil {
-		return fmt.Errorf("No encoder registered for %s and no default encoder", contentType)
+		return fmt.Errorf("No encoder registered for %s and no default encoder", accept)
 	}
 
 	// the encoderPool will handle whether or not a pool is actually in use
@@ -215,6 +233,17 @@ func (encoder *HTTPEncoder) Encode(v interface{}, resp io.Writer, accept string)
 	return nil
 }
 
+// IsJSON returns true if the encoder negotiated for the given Accept header value is the one
+// created by NewJSONEncoder. The generated response helpers use it to write the JSON encoding of
+// primitive values directly.
+func (encoder *HTTPEncoder) IsJSON(accept string) bool {
+	if accept == "" {
+		accept = "*/*"
+	}
+	p, _ := encoder.pool(accept)
+	return p != nil && p.json
+}
+
 // Register sets a specific encoder to be used for the specified content types. If an encoder is
 // already registered, it is overwritten.
 func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
@@ -234,6 +263,82 @@ func (encoder *HTTPEncoder) Register(f EncoderFunc, contentTypes ...string) {
 	}
 }
 
+// RegisterMissing sets the encoder used for the specified content types that do not have an
+// encoder yet. Generated code uses it so that encoders registered prior to mounting the
+// controllers take precedence over the design ones.
+func (encoder *HTTPEncoder) RegisterMissing(f EncoderFunc, contentTypes ...string) {
+	var missing []string
+	for _, contentType := range contentTypes {
+		if _, ok := encoder.pools[canonicalMediaType(contentType)]; !ok {
+			missing = append(missing, contentType)
+		}
+	}
+	if len(missing) > 0 {
+		encoder.Register(f, missing...)
+	}
+}
+
+// contentType returns the registered content type that best matches the given Accept header
+// value. The media ranges are considered in order, a media range with a structured syntax suffix
+// matches the encoder of the suffix, e.g. application/json for application/vnd.api+json. It
+// returns the empty string if there is no match.
+func (encoder *HTTPEncoder) contentType(accept string) string {
+	for _, r := range strings.Split(accept, ",") {
+		t := canonicalMediaType(strings.TrimSpace(r))
+		if t == "*/*" {
+			return t
+		}
+		if _, ok := encoder.pools[t]; ok {
+			return t
+		}
+		if base := suffixType(t); base != "" {
+			if _, ok := encoder.pools[base]; ok {
+				return base
+			}
+		}
+	}
+	return ""
+}
+
+// pool returns the encoder pool and content type negotiated for the given Accept header value. It
+// falls back to the default encoder if no registered content type matches.
+func (encoder *HTTPEncoder) pool(accept string) (*encoderPool, string) {
+	contentType := encoder.contentType(accept)
+	p := encoder.pools[contentType]
+	if p == nil && contentType != "*/*" {
+		p = encoder.pools["*/*"]
+	}
+	return p, contentType
+}
+
+// canonicalMediaType returns the media type of the given content type without parameters.
+func canonicalMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return contentType
+}
+
+// isJSONContentType returns true if the given request content type denotes a JSON document. The
+// empty content type defaults to JSON.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType := canonicalMediaType(contentType)
+	return mediaType == "application/json" || suffixType(mediaType) == "application/json"
+}
+
+// suffixType returns the media type of the structured syntax suffix of the given media type,
+// e.g. application/json for application/vnd.api+json, or the empty string if there is none.
+func suffixType(mediaType string) string {
+	i := strings.LastIndex(mediaType, "+")
+	if i < 0 || i == len(mediaType)-1 {
+		return ""
+	}
+	return "application/" + mediaType[i+1:]
+}
+
 // newEncodePool checks to see if the EncoderFactory returns reusable encoders and if so, creates
 // a pool.
 func newEncodePool(f EncoderFunc) *encoderPool {
@@ -241,7 +346,7 @@ func newEncodePool(f EncoderFunc) *encoderPool {
 	e := f(nil)
 	re, ok := e.(ResettableEncoder)
 
-	p := &encoderPool{fn: f}
+	p := &encoderPool{fn: f, json: reflect.ValueOf(f).Pointer() == jsonEncoderPointer}
 
 	// if the encoder can be reset, create a pool and put the typed encoder in
 	if ok {
@@ -0,0 +1,78 @@
+package binary
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// Enforce that Decoder and Encoder satisfy goa.ResettableDecoder and goa.ResettableEncoder
+	// at compile time
+	_ goa.ResettableDecoder = (*Decoder)(nil)
+	_ goa.ResettableEncoder = (*Encoder)(nil)
+)
+
+type (
+	// Decoder decodes values that implement encoding.BinaryUnmarshaler from the reader
+	// content.
+	Decoder struct {
+		r io.Reader
+	}
+
+	// Encoder writes the binary representation of the values that implement
+	// encoding.BinaryMarshaler.
+	Encoder struct {
+		w io.Writer
+	}
+)
+
+// NewDecoder returns a binary decoder.
+func NewDecoder(r io.Reader) goa.Decoder {
+	return &Decoder{r: r}
+}
+
+// NewEncoder returns a binary encoder.
+func NewEncoder(w io.Writer) goa.Encoder {
+	return &Encoder{w: w}
+}
+
+// Decode reads the whole reader content and gives it to the UnmarshalBinary method of v.
+func (d *Decoder) Decode(v interface{}) error {
+	u, ok := v.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("binary: cannot decode into %T, type does not implement encoding.BinaryUnmarshaler", v)
+	}
+	b, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalBinary(b)
+}
+
+// Reset sets the reader the decoder reads from.
+func (d *Decoder) Reset(r io.Reader) {
+	d.r = r
+}
+
+// Encode writes the bytes returned by the MarshalBinary method of v.
+func (e *Encoder) Encode(v interface{}) error {
+	m, ok := v.(encoding.BinaryMarshaler)
+	if !ok {
+		return fmt.Errorf("binary: cannot encode %T, type does not implement encoding.BinaryMarshaler", v)
+	}
+	b, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+// Reset sets the writer the encoder writes to.
+func (e *Encoder) Reset(w io.Writer) {
+	e.w = w
+}
@@ -0,0 +1,13 @@
+package binary_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBinaryEncoding(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Binary Encoding Suite")
+}
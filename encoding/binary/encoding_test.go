@@ -0,0 +1,65 @@
+package binary_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/goadesign/goa/encoding/binary"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// point implements encoding.BinaryMarshaler and encoding.BinaryUnmarshaler.
+type point struct {
+	X, Y byte
+}
+
+func (p *point) MarshalBinary() ([]byte, error) {
+	return []byte{p.X, p.Y}, nil
+}
+
+func (p *point) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("invalid point")
+	}
+	p.X, p.Y = data[0], data[1]
+	return nil
+}
+
+var _ = Describe("BinaryEncoding", func() {
+	It("encodes values implementing encoding.BinaryMarshaler", func() {
+		var b bytes.Buffer
+		Ω(binary.NewEncoder(&b).Encode(&point{X: 1, Y: 2})).ShouldNot(HaveOccurred())
+		Ω(b.Bytes()).Should(Equal([]byte{1, 2}))
+	})
+
+	It("decodes values implementing encoding.BinaryUnmarshaler", func() {
+		var p point
+		Ω(binary.NewDecoder(bytes.NewReader([]byte{3, 4})).Decode(&p)).ShouldNot(HaveOccurred())
+		Ω(p).Should(Equal(point{X: 3, Y: 4}))
+	})
+
+	It("resets the reader and writer", func() {
+		var b bytes.Buffer
+		enc := binary.NewEncoder(nil).(*binary.Encoder)
+		enc.Reset(&b)
+		Ω(enc.Encode(&point{X: 5, Y: 6})).ShouldNot(HaveOccurred())
+		dec := binary.NewDecoder(nil).(*binary.Decoder)
+		dec.Reset(&b)
+		var p point
+		Ω(dec.Decode(&p)).ShouldNot(HaveOccurred())
+		Ω(p).Should(Equal(point{X: 5, Y: 6}))
+	})
+
+	It("fails with other values", func() {
+		var b bytes.Buffer
+		Ω(binary.NewEncoder(&b).Encode("foo")).Should(HaveOccurred())
+		var s string
+		Ω(binary.NewDecoder(&b).Decode(&s)).Should(HaveOccurred())
+	})
+
+	It("returns the unmarshaler errors", func() {
+		var p point
+		Ω(binary.NewDecoder(bytes.NewReader([]byte{1})).Decode(&p)).Should(MatchError("invalid point"))
+	})
+})
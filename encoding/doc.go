@@ -18,6 +18,15 @@ The built-in encoder and decoder media types are:
 	- application/msgpack and application/x-msgpack
 	- application/binc and application/x-binc
 	- application/cbor and application/x-cbor
+	- application/x-binary
+
+The application/x-binary encoding uses the encoding.BinaryMarshaler and encoding.BinaryUnmarshaler
+implementations of the encoded and decoded values. It makes it possible to plug any binary format
+in, typically for internal service to service traffic:
+
+	func (mt *Bottle) MarshalBinary() ([]byte, error) {
+		// ...
+	}
 
 External encoders and decoders can also be specified via the DSL:
 
@@ -0,0 +1,113 @@
+package goa_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// tagDecoder is a decoder that records the decoder that ran in the decoded value.
+type tagDecoder string
+
+func (d tagDecoder) Decode(v interface{}) error {
+	*(v.(*string)) = string(d)
+	return nil
+}
+
+var _ = Describe("HTTPDecoder", func() {
+	var decoder *goa.HTTPDecoder
+	var decoded string
+
+	newTagDecoder := func(tag string) goa.DecoderFunc {
+		return func(io.Reader) goa.Decoder { return tagDecoder(tag) }
+	}
+
+	BeforeEach(func() {
+		decoder = goa.NewHTTPDecoder()
+		decoded = ""
+		decoder.Register(newTagDecoder("json"), "application/json")
+	})
+
+	It("uses the decoder registered for the content type", func() {
+		decoder.Register(newTagDecoder("jsonapi"), "application/vnd.api+json")
+		Ω(decoder.Decode(&decoded, nil, "application/vnd.api+json; charset=utf-8")).ShouldNot(HaveOccurred())
+		Ω(decoded).Should(Equal("jsonapi"))
+	})
+
+	It("falls back to the decoder of the structured syntax suffix", func() {
+		Ω(decoder.Decode(&decoded, nil, "application/vnd.api+json")).ShouldNot(HaveOccurred())
+		Ω(decoded).Should(Equal("json"))
+	})
+
+	Context("with RegisterMissing", func() {
+		It("keeps the decoders already registered", func() {
+			decoder.RegisterMissing(newTagDecoder("design"), "application/json", "application/xml")
+			Ω(decoder.Decode(&decoded, nil, "application/json")).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal("json"))
+			Ω(decoder.Decode(&decoded, nil, "application/xml")).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal("design"))
+		})
+	})
+})
+
+var _ = Describe("HTTPEncoder", func() {
+	var encoder *goa.HTTPEncoder
+	var buf *bytes.Buffer
+	val := map[string]string{"foo": "bar"}
+
+	BeforeEach(func() {
+		encoder = goa.NewHTTPEncoder()
+		buf = new(bytes.Buffer)
+		encoder.Register(goa.NewJSONEncoder, "application/json")
+		encoder.Register(goa.NewXMLEncoder, "application/xml")
+	})
+
+	It("uses the first media range of the Accept header that has an encoder", func() {
+		Ω(encoder.Encode(val, buf, "text/html, application/json;q=0.9, application/xml;q=0.8")).ShouldNot(HaveOccurred())
+		var decoded map[string]string
+		Ω(json.Unmarshal(buf.Bytes(), &decoded)).ShouldNot(HaveOccurred())
+		Ω(decoded).Should(Equal(val))
+	})
+
+	It("falls back to the encoder of the structured syntax suffix", func() {
+		Ω(encoder.Encode(val, buf, "application/vnd.api+json")).ShouldNot(HaveOccurred())
+		Ω(buf.String()).Should(HavePrefix("{"))
+	})
+
+	It("fails when no encoder matches and there is no default encoder", func() {
+		Ω(encoder.Encode(val, buf, "text/html")).Should(HaveOccurred())
+	})
+
+	Context("with RegisterMissing", func() {
+		It("keeps the encoders already registered", func() {
+			encoder.RegisterMissing(goa.NewGobEncoder, "application/json", "application/gob")
+			Ω(encoder.Encode(val, buf, "application/json")).ShouldNot(HaveOccurred())
+			Ω(buf.String()).Should(HavePrefix("{"))
+		})
+	})
+
+	Context("IsJSON", func() {
+		It("reports whether the negotiated encoder is the JSON encoder", func() {
+			Ω(encoder.IsJSON("application/json")).Should(BeTrue())
+			Ω(encoder.IsJSON("application/vnd.api+json")).Should(BeTrue())
+			Ω(encoder.IsJSON("application/xml, application/json")).Should(BeFalse())
+			Ω(encoder.IsJSON("text/html")).Should(BeFalse())
+			Ω(encoder.IsJSON("")).Should(BeFalse())
+		})
+
+		It("uses the default encoder", func() {
+			encoder.Register(goa.NewJSONEncoder, "*/*")
+			Ω(encoder.IsJSON("")).Should(BeTrue())
+			Ω(encoder.IsJSON("text/html")).Should(BeTrue())
+		})
+
+		It("is false for other JSON encoders", func() {
+			encoder.Register(func(w io.Writer) goa.Encoder { return json.NewEncoder(w) }, "application/json")
+			Ω(encoder.IsJSON("application/json")).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package goa
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorEnvelope describes the layout of the JSON error responses when it differs from the layout
+// of ErrorResponse. goagen generates the envelope defined with the ErrorEnvelope DSL and sets the
+// service error encoder with it.
+type ErrorEnvelope struct {
+	// Wrap is the name of the response field that contains the error object, the error object
+	// is the response body if empty.
+	Wrap string
+	// Fields maps the JSON names of the ErrorResponse fields ("id", "code", "status",
+	// "detail", "meta" and "fields") to the names used in the error object, "-" omits the
+	// field.
+	Fields map[string]string
+	// Static contains the fields added to the error object with constant values.
+	Static map[string]interface{}
+}
+
+// Encoder returns an error encoder that writes the error responses using the layout of the
+// envelope. The errors that are not ErrorResponse values are written as is.
+func (env *ErrorEnvelope) Encoder(service *Service) ErrorEncoder {
+	return func(ctx context.Context, status int, err error) error {
+		e, ok := err.(*ErrorResponse)
+		if !ok {
+			if _, ok := err.(ServiceError); ok {
+				return service.Send(ctx, status, err)
+			}
+			return service.Send(ctx, status, err.Error())
+		}
+		return service.Send(ctx, status, env.Render(service.localize(ctx, e)))
+	}
+}
+
+// Render returns the representation of the given error using the layout of the envelope.
+func (env *ErrorEnvelope) Render(e *ErrorResponse) map[string]interface{} {
+	obj := make(map[string]interface{}, len(env.Static)+6)
+	for k, v := range env.Static {
+		obj[k] = v
+	}
+	set := func(name string, v interface{}) {
+		if key := env.key(name); key != "-" {
+			obj[key] = v
+		}
+	}
+	set("id", e.ID)
+	set("code", e.Code)
+	set("status", e.Status)
+	set("detail", e.Detail)
+	if len(e.Meta) > 0 {
+		set("meta", e.Meta)
+	}
+	if len(e.Fields) > 0 {
+		set("fields", e.Fields)
+	}
+	if env.Wrap == "" {
+		return obj
+	}
+	return map[string]interface{}{env.Wrap: obj}
+}
+
+// Parse decodes an error response body written using the layout of the envelope. Clients may
+// use it to decode the bodies of the error responses of services that define an envelope.
+func (env *ErrorEnvelope) Parse(body []byte) (*ErrorResponse, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+	if env.Wrap != "" {
+		raw, ok := obj[env.Wrap]
+		if !ok {
+			return nil, fmt.Errorf("missing %#v field in error response", env.Wrap)
+		}
+		obj = nil
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, err
+		}
+	}
+	fields := make(map[string]json.RawMessage, len(obj))
+	for _, name := range []string{"id", "code", "status", "detail", "meta", "fields"} {
+		if key := env.key(name); key != "-" {
+			if v, ok := obj[key]; ok {
+				fields[name] = v
+			}
+		}
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var e ErrorResponse
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// key returns the name used in the error object for the ErrorResponse field with the given JSON
+// name.
+func (env *ErrorEnvelope) key(name string) string {
+	if k, ok := env.Fields[name]; ok {
+		return k
+	}
+	return name
+}
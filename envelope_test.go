@@ -0,0 +1,80 @@
+package goa_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorEnvelope", func() {
+	var env *goa.ErrorEnvelope
+
+	BeforeEach(func() {
+		env = &goa.ErrorEnvelope{
+			Wrap:   "error",
+			Fields: map[string]string{"detail": "message", "id": "-"},
+			Static: map[string]interface{}{"api_version": "2"},
+		}
+	})
+
+	Describe("Render", func() {
+		It("uses the envelope layout", func() {
+			e := &goa.ErrorResponse{ID: "abc", Code: "not_found", Status: 404, Detail: "no bottle"}
+			Ω(env.Render(e)).Should(Equal(map[string]interface{}{
+				"error": map[string]interface{}{
+					"api_version": "2",
+					"code":        "not_found",
+					"status":      404,
+					"message":     "no bottle",
+				},
+			}))
+		})
+	})
+
+	Describe("Parse", func() {
+		It("decodes the error", func() {
+			e, err := env.Parse([]byte(`{"error":{"api_version":"2","code":"not_found","status":404,"message":"no bottle"}}`))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(e.Code).Should(Equal("not_found"))
+			Ω(e.Status).Should(Equal(404))
+			Ω(e.Detail).Should(Equal("no bottle"))
+			Ω(e.ID).Should(BeEmpty())
+		})
+
+		It("fails if the wrapping field is missing", func() {
+			_, err := env.Parse([]byte(`{"code":"not_found"}`))
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Encoder", func() {
+		var s *goa.Service
+		var rw *TestResponseWriter
+		var ctx context.Context
+
+		BeforeEach(func() {
+			s = goa.New("test")
+			s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+			r, err := http.NewRequest("GET", "/bottles/1", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(context.Background(), rw, r, nil)
+		})
+
+		It("writes the errors using the envelope layout", func() {
+			e := goa.ErrNotFound("no bottle").(*goa.ErrorResponse)
+			Ω(env.Encoder(s)(ctx, 404, e)).ShouldNot(HaveOccurred())
+			Ω(rw.Status).Should(Equal(404))
+			Ω(string(rw.Body)).Should(MatchJSON(`{"error":{"api_version":"2","code":"not_found","status":404,"message":"no bottle"}}`))
+		})
+
+		It("writes other errors as is", func() {
+			Ω(env.Encoder(s)(ctx, 500, errors.New("boom"))).ShouldNot(HaveOccurred())
+			Ω(string(rw.Body)).Should(MatchJSON(`"boom"`))
+		})
+	})
+})
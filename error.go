@@ -17,7 +17,9 @@ interface is leveraged by the error handler middleware to produce the error resp
 The code generated by goagen calls the helper functions exposed in this file when it encounters
 invalid data (wrong type, validation errors etc.) such as InvalidParamTypeError,
 InvalidAttributeTypeError etc. These methods return errors that get merged with any previously
-encountered error via the Error Merge method. The helper functions are error classes stored in
+encountered error via the Error Merge method. The errors produced by the validation of payload
+and media type fields list the RFC 6901 JSON pointers of the invalid fields so that clients may
+report all the violations of a request at once. The helper functions are error classes stored in
 global variable. This means your code can override their values to produce arbitrary error
 responses.
 
@@ -30,10 +32,13 @@ middleware) also generate an internal error response.
 package goa
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 )
 
@@ -75,6 +80,14 @@ var (
 
 	// ErrInternal is the class of error used for uncaught errors.
 	ErrInternal = NewErrorClass("internal", 500)
+
+	// ErrBadGateway is the error returned to the requests forwarded by a proxy handler when
+	// the upstream service cannot be reached or fails to respond.
+	ErrBadGateway = NewErrorClass("bad_gateway", 502)
+
+	// ErrServiceUnavailable is the error returned to the requests rejected because the service
+	// is overloaded.
+	ErrServiceUnavailable = NewErrorClass("service_unavailable", 503)
 )
 
 type (
@@ -88,6 +101,12 @@ type (
 	// and are returned to the client.
 	ErrorClass func(message interface{}, keyvals ...interface{}) error
 
+	// ErrorEncoder writes the response of an error returned by a request handler. It is used
+	// by the ErrorHandler middleware in place of the service encoder, status is the response
+	// status and err the error to render: a ServiceError or, in verbose mode, any other
+	// error returned by the handler.
+	ErrorEncoder func(ctx context.Context, status int, err error) error
+
 	// ServiceError is the interface implemented by all errors created using a ErrorClass
 	// function.
 	ServiceError interface {
@@ -122,6 +141,27 @@ type (
 		Detail string `json:"detail" xml:"detail" form:"detail"`
 		// Meta contains additional key/value pairs useful to clients.
 		Meta map[string]interface{} `json:"meta,omitempty" xml:"meta,omitempty" form:"meta,omitempty"`
+		// Fields lists the validation errors of the individual fields that caused the error.
+		Fields []*FieldError `json:"fields,omitempty" xml:"fields,omitempty" form:"fields,omitempty"`
+
+		// messages are the translatable messages that make up Detail.
+		messages []*message
+		// cause is the error that caused the error if any, e.g. the decoding error of an
+		// invalid request body.
+		cause error
+	}
+
+	// FieldError describes a field that failed to validate.
+	FieldError struct {
+		// Pointer is the RFC 6901 JSON pointer to the invalid field relative to the validated
+		// value, e.g. "/items/0/name". It is empty if the error applies to the whole value,
+		// for example to a param or a header.
+		Pointer string `json:"pointer" xml:"pointer" form:"pointer"`
+		// Detail describes the error.
+		Detail string `json:"detail" xml:"detail" form:"detail"`
+
+		// message is the translatable message of Detail.
+		message *message
 	}
 )
 
@@ -159,40 +199,100 @@ func NewErrorClass(code string, status int) ErrorClass {
 
 // MissingPayloadError is the error produced when a request is missing a required payload.
 func MissingPayloadError() error {
-	return ErrInvalidRequest("missing required payload")
+	m := newMessage(MsgMissingPayload)
+	return withMessage(ErrInvalidRequest(m.detail), m)
 }
 
 // InvalidParamTypeError is the error produced when the type of a parameter does not match the type
 // defined in the design.
 func InvalidParamTypeError(name string, val interface{}, expected string) error {
-	msg := fmt.Sprintf("invalid value %#v for parameter %#v, must be a %s", val, name, expected)
-	return ErrInvalidRequest(msg, "param", name, "value", val, "expected", expected)
+	m := newMessage(MsgInvalidParamType, val, name, expected)
+	return withMessage(ErrInvalidRequest(m.detail, "param", name, "value", val, "expected", expected), m)
+}
+
+// InvalidBodyError is the error produced when a request body fails to be decoded, err is the error
+// returned by the decoder. JSON type mismatches identify the invalid field with its JSON pointer,
+// the expected type and the received value, JSON syntax errors the offset of the error. The
+// decoder error is available via errors.As or errors.Unwrap.
+func InvalidBodyError(contentType string, err error) error {
+	var m *message
+	var e error
+	switch actual := err.(type) {
+	case *json.UnmarshalTypeError:
+		ctx := "payload"
+		if actual.Field != "" {
+			ctx += "." + actual.Field
+		}
+		expected := jsonTypeName(actual.Type)
+		m = newMessage(MsgInvalidBodyType, ctx, expected, actual.Value)
+		e = ErrInvalidEncoding(m.detail, "attribute", ctx, "value", actual.Value, "expected", expected, "offset", actual.Offset)
+		e = withField(withMessage(e, m), contextPointer(ctx), m)
+	case *json.SyntaxError:
+		m = newMessage(MsgInvalidBodySyntax, contentType, actual.Error(), actual.Offset)
+		e = withMessage(ErrInvalidEncoding(m.detail, "offset", actual.Offset), m)
+	default:
+		m = newMessage(MsgInvalidBody, contentType, err.Error())
+		e = withMessage(ErrInvalidEncoding(m.detail), m)
+	}
+	e.(*ErrorResponse).cause = err
+	return e
 }
 
 // MissingParamError is the error produced for requests that are missing path or querystring
 // parameters.
 func MissingParamError(name string) error {
-	msg := fmt.Sprintf("missing required parameter %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	m := newMessage(MsgMissingParam, name)
+	return withMessage(ErrInvalidRequest(m.detail, "name", name), m)
 }
 
 // InvalidAttributeTypeError is the error produced when the type of payload field does not match
 // the type defined in the design.
 func InvalidAttributeTypeError(ctx string, val interface{}, expected string) error {
-	msg := fmt.Sprintf("type of %s must be %s but got value %#v", ctx, expected, val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", expected)
+	m := newMessage(MsgInvalidAttributeType, ctx, expected, val)
+	return withMessage(ErrInvalidRequest(m.detail, "attribute", ctx, "value", val, "expected", expected), m)
 }
 
 // MissingAttributeError is the error produced when a request payload is missing a required field.
 func MissingAttributeError(ctx, name string) error {
-	msg := fmt.Sprintf("attribute %#v of %s is missing and required", name, ctx)
-	return ErrInvalidRequest(msg, "attribute", name, "parent", ctx)
+	m := newMessage(MsgMissingAttribute, name, ctx)
+	err := ErrInvalidRequest(m.detail, "attribute", name, "parent", ctx)
+	return withField(withMessage(err, m), contextPointer(ctx)+"/"+escapeToken(name), m)
+}
+
+// RequiredTogetherError is the error produced when a request payload sets some but not all of the
+// attributes that must be set together.
+func RequiredTogetherError(ctx string, names []string) error {
+	return fieldGroupError(MsgRequiredTogether, ctx, names)
+}
+
+// MutuallyExclusiveError is the error produced when a request payload sets more than one of the
+// given mutually exclusive attributes.
+func MutuallyExclusiveError(ctx string, names []string) error {
+	return fieldGroupError(MsgMutuallyExclusive, ctx, names)
+}
+
+// AtLeastOneOfError is the error produced when a request payload sets none of the given
+// attributes.
+func AtLeastOneOfError(ctx string, names []string) error {
+	return fieldGroupError(MsgAtLeastOneOf, ctx, names)
+}
+
+// fieldGroupError creates the error with the given message ID for the group of attributes of the
+// value described by ctx.
+func fieldGroupError(id, ctx string, names []string) error {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%#v", n)
+	}
+	m := newMessage(id, strings.Join(quoted, ", "), ctx)
+	err := ErrInvalidRequest(m.detail, "attributes", names, "parent", ctx)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // MissingHeaderError is the error produced when a request is missing a required header.
 func MissingHeaderError(name string) error {
-	msg := fmt.Sprintf("missing required HTTP header %#v", name)
-	return ErrInvalidRequest(msg, "name", name)
+	m := newMessage(MsgMissingHeader, name)
+	return withMessage(ErrInvalidRequest(m.detail, "name", name), m)
 }
 
 // InvalidEnumValueError is the error produced when the value of a parameter or payload field does
@@ -202,62 +302,88 @@ func InvalidEnumValueError(ctx string, val interface{}, allowed []interface{}) e
 	for i, a := range allowed {
 		elems[i] = fmt.Sprintf("%#v", a)
 	}
-	msg := fmt.Sprintf("value of %s must be one of %s but got value %#v", ctx, strings.Join(elems, ", "), val)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	m := newMessage(MsgInvalidEnumValue, ctx, strings.Join(elems, ", "), val)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", val, "expected", strings.Join(elems, ", "))
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // InvalidFormatError is the error produced when the value of a parameter or payload field does not
 // match the format validation defined in the design.
 func InvalidFormatError(ctx, target string, format Format, formatError error) error {
-	msg := fmt.Sprintf("%s must be formatted as a %s but got value %#v, %s", ctx, format, target, formatError.Error())
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	m := newMessage(MsgInvalidFormat, ctx, format, target, formatError.Error())
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "expected", format, "error", formatError.Error())
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // InvalidPatternError is the error produced when the value of a parameter or payload field does
 // not match the pattern validation defined in the design.
 func InvalidPatternError(ctx, target string, pattern string) error {
-	msg := fmt.Sprintf("%s must match the regexp %#v but got value %#v", ctx, pattern, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "regexp", pattern)
+	m := newMessage(MsgInvalidPattern, ctx, pattern, target)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "regexp", pattern)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // InvalidRangeError is the error produced when the value of a parameter or payload field does
 // not match the range validation defined in the design. value may be a int or a float64.
 func InvalidRangeError(ctx string, target interface{}, value interface{}, min bool) error {
-	comp := "greater than or equal to"
+	comp, id := "greater than or equal to", MsgInvalidRangeMin
+	if !min {
+		comp, id = "less than or equal to", MsgInvalidRangeMax
+	}
+	m := newMessage(id, ctx, value, target)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
+}
+
+// InvalidExclusiveRangeError is the error produced when the value of a parameter or payload field
+// does not match the exclusive range validation defined in the design. value may be a int or a
+// float64.
+func InvalidExclusiveRangeError(ctx string, target interface{}, value interface{}, min bool) error {
+	comp, id := "greater than", MsgInvalidExclusiveRangeMin
 	if !min {
-		comp = "less than or equal to"
+		comp, id = "less than", MsgInvalidExclusiveRangeMax
 	}
-	msg := fmt.Sprintf("%s must be %s %v but got value %#v", ctx, comp, value, target)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	m := newMessage(id, ctx, value, target)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "comp", comp, "expected", value)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
+}
+
+// InvalidMultipleOfError is the error produced when the value of a parameter or payload field is
+// not a multiple of the value defined in the design. value may be a int or a float64.
+func InvalidMultipleOfError(ctx string, target interface{}, value interface{}) error {
+	m := newMessage(MsgInvalidMultipleOf, ctx, value, target)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "comp", "multiple of", "expected", value)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // InvalidLengthError is the error produced when the value of a parameter or payload field does
 // not match the length validation defined in the design.
 func InvalidLengthError(ctx string, target interface{}, ln, value int, min bool) error {
-	comp := "greater than or equal to"
+	comp, id := "greater than or equal to", MsgInvalidLengthMin
 	if !min {
-		comp = "less than or equal to"
+		comp, id = "less than or equal to", MsgInvalidLengthMax
 	}
-	msg := fmt.Sprintf("length of %s must be %s %d but got value %#v (len=%d)", ctx, comp, value, target, ln)
-	return ErrInvalidRequest(msg, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	m := newMessage(id, ctx, value, target, ln)
+	err := ErrInvalidRequest(m.detail, "attribute", ctx, "value", target, "len", ln, "comp", comp, "expected", value)
+	return withField(withMessage(err, m), contextPointer(ctx), m)
 }
 
 // NoAuthMiddleware is the error produced when goa is unable to lookup a auth middleware for a
 // security scheme defined in the design.
 func NoAuthMiddleware(schemeName string) error {
-	msg := fmt.Sprintf("Auth middleware for security scheme %s is not mounted", schemeName)
-	return ErrNoAuthMiddleware(msg, "scheme", schemeName)
+	m := newMessage(MsgNoAuthMiddleware, schemeName)
+	return withMessage(ErrNoAuthMiddleware(m.detail, "scheme", schemeName), m)
 }
 
 // MethodNotAllowedError is the error produced to requests that match the path of a registered
 // handler but not the HTTP method.
 func MethodNotAllowedError(method string, allowed []string) error {
-	var plural string
+	id := MsgMethodNotAllowed
 	if len(allowed) > 1 {
-		plural = " one of"
+		id = MsgMethodNotAllowedMultiple
 	}
-	msg := fmt.Sprintf("Method %s must be%s %s", method, plural, strings.Join(allowed, ", "))
-	return ErrMethodNotAllowed(msg, "method", method, "allowed", strings.Join(allowed, ", "))
+	m := newMessage(id, method, strings.Join(allowed, ", "))
+	return withMessage(ErrMethodNotAllowed(m.detail, "method", method, "allowed", strings.Join(allowed, ", ")), m)
 }
 
 // Error returns the error occurrence details.
@@ -272,6 +398,10 @@ func (e *ErrorResponse) Error() string {
 // ResponseStatus is the status used to build responses.
 func (e *ErrorResponse) ResponseStatus() int { return e.Status }
 
+// Unwrap returns the error that caused e if any, e.g. the decoding error of an invalid request
+// body.
+func (e *ErrorResponse) Unwrap() error { return e.cause }
+
 // Token is the unique error occurrence identifier.
 func (e *ErrorResponse) Token() string { return e.ID }
 
@@ -322,7 +452,11 @@ func MergeErrors(err, other error) error {
 		e.Status = 400
 		e.Code = "bad_request"
 	}
+	if len(e.messages) > 0 || len(o.messages) > 0 {
+		e.messages = append(e.detailMessages(), o.detailMessages()...)
+	}
 	e.Detail = e.Detail + "; " + o.Detail
+	e.Fields = append(e.Fields, o.Fields...)
 
 	if e.Meta == nil && len(o.Meta) > 0 {
 		e.Meta = make(map[string]interface{})
@@ -333,6 +467,90 @@ func MergeErrors(err, other error) error {
 	return e
 }
 
+// NestError prefixes the JSON pointers of the field errors of err with the given reference
+// tokens and returns err. String tokens are escaped as described in RFC 6901, other tokens such
+// as array indices are formatted with fmt.Sprint. The generated validation code uses NestError
+// to compute the pointers of the fields of nested types and of array and map elements.
+func NestError(err error, tokens ...interface{}) error {
+	e, ok := err.(*ErrorResponse)
+	if !ok || len(e.Fields) == 0 {
+		return err
+	}
+	var prefix string
+	for _, t := range tokens {
+		if s, ok := t.(string); ok {
+			prefix += "/" + escapeToken(s)
+		} else {
+			prefix += "/" + escapeToken(fmt.Sprint(t))
+		}
+	}
+	for _, f := range e.Fields {
+		f.Pointer = prefix + f.Pointer
+	}
+	return e
+}
+
+// withField records the invalid field in err if it was created by an error class, it returns
+// err unchanged otherwise.
+func withField(err error, pointer string, m *message) error {
+	if e, ok := err.(*ErrorResponse); ok {
+		e.Fields = append(e.Fields, &FieldError{Pointer: pointer, Detail: m.detail, message: m})
+	}
+	return err
+}
+
+// contextPointer returns the JSON pointer of the value described by the validation context
+// built by the generated code, e.g. "/address/city" for "raw.address.city". The first element
+// of the context names the validated value. Array and map elements ("[*]") are validated on
+// their own so the pointer is relative to the innermost element, NestError adds the prefix.
+func contextPointer(ctx string) string {
+	if i := strings.LastIndex(ctx, "[*]"); i >= 0 {
+		ctx = ctx[i+3:]
+	} else if i := strings.Index(ctx, "."); i >= 0 {
+		ctx = ctx[i:]
+	} else {
+		return ""
+	}
+	var pointer string
+	for _, token := range strings.Split(ctx, ".") {
+		if token != "" {
+			pointer += "/" + escapeToken(token)
+		}
+	}
+	return pointer
+}
+
+// jsonTypeName returns the name of the JSON type of values that decode into t, e.g. "integer" for
+// int fields.
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "value"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	return t.String()
+}
+
+// escapeToken escapes a JSON pointer reference token as described in RFC 6901.
+func escapeToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
 func asServiceError(err error) ServiceError {
 	e, ok := err.(ServiceError)
 	if !ok {
@@ -104,6 +104,97 @@ var _ = Describe("MissingAttributeError", func() {
 		Ω(err.Detail).Should(ContainSubstring(ctx))
 		Ω(err.Detail).Should(ContainSubstring(name))
 	})
+
+	It("records the pointer to the missing field", func() {
+		err := MissingAttributeError("raw.address", "zip/code").(*ErrorResponse)
+		Ω(err.Fields).Should(HaveLen(1))
+		Ω(err.Fields[0].Pointer).Should(Equal("/address/zip~1code"))
+		Ω(err.Fields[0].Detail).Should(Equal(err.Detail))
+	})
+})
+
+var _ = Describe("InvalidBodyError", func() {
+	decode := func(body string) error {
+		var v struct {
+			Item struct {
+				Count int `json:"count"`
+			} `json:"item"`
+		}
+		return json.Unmarshal([]byte(body), &v)
+	}
+
+	It("identifies the field of type errors", func() {
+		cause := decode(`{"item":{"count":"two"}}`)
+		err := InvalidBodyError("application/json", cause).(*ErrorResponse)
+		Ω(err.Code).Should(Equal("invalid_encoding"))
+		Ω(err.Status).Should(Equal(400))
+		Ω(err.Detail).Should(Equal("type of payload.item.count must be integer but got string"))
+		Ω(err.Meta).Should(HaveKeyWithValue("attribute", "payload.item.count"))
+		Ω(err.Meta).Should(HaveKeyWithValue("expected", "integer"))
+		Ω(err.Meta).Should(HaveKeyWithValue("value", "string"))
+		Ω(err.Fields).Should(HaveLen(1))
+		Ω(err.Fields[0].Pointer).Should(Equal("/item/count"))
+	})
+
+	It("reports the offset of syntax errors", func() {
+		cause := decode(`{"item":`)
+		err := InvalidBodyError("application/json", cause).(*ErrorResponse)
+		Ω(err.Code).Should(Equal("invalid_encoding"))
+		Ω(err.Detail).Should(HavePrefix(`failed to decode request body with content type "application/json": `))
+		Ω(err.Meta).Should(HaveKey("offset"))
+	})
+
+	It("wraps the decoding error", func() {
+		cause := decode(`{"item":{"count":"two"}}`)
+		err := InvalidBodyError("application/json", cause)
+		var typeErr *json.UnmarshalTypeError
+		Ω(errors.As(err, &typeErr)).Should(BeTrue())
+		Ω(typeErr.Field).Should(Equal("item.count"))
+		other := errors.New("boom")
+		Ω(errors.Unwrap(InvalidBodyError("application/xml", other))).Should(Equal(other))
+	})
+})
+
+var _ = Describe("field group errors", func() {
+	names := []string{"start", "end"}
+
+	It("creates http errors that describe the group", func() {
+		err := RequiredTogetherError("raw.range", names).(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`attributes "start", "end" of raw.range must be either all set or all missing`))
+		Ω(err.Status).Should(Equal(400))
+		err = MutuallyExclusiveError("raw", names).(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`at most one of the attributes "start", "end" of raw may be set`))
+		err = AtLeastOneOfError("raw", names).(*ErrorResponse)
+		Ω(err.Detail).Should(Equal(`at least one of the attributes "start", "end" of raw is required`))
+	})
+
+	It("records the pointer to the parent object", func() {
+		err := RequiredTogetherError("raw.range", names).(*ErrorResponse)
+		Ω(err.Fields).Should(HaveLen(1))
+		Ω(err.Fields[0].Pointer).Should(Equal("/range"))
+		Ω(err.Fields[0].Detail).Should(Equal(err.Detail))
+	})
+})
+
+var _ = Describe("NestError", func() {
+	It("prefixes the pointers of the field errors", func() {
+		err := MergeErrors(
+			InvalidPatternError("raw.items[*].name", "x", "^a"),
+			MissingAttributeError("raw.items[*]", "id"),
+		)
+		err = NestError(err, "items", 2)
+		fields := err.(*ErrorResponse).Fields
+		Ω(fields).Should(HaveLen(2))
+		Ω(fields[0].Pointer).Should(Equal("/items/2/name"))
+		Ω(fields[0].Detail).Should(Equal(InvalidPatternError("raw.items[*].name", "x", "^a").(*ErrorResponse).Detail))
+		Ω(fields[1].Pointer).Should(Equal("/items/2/id"))
+		Ω(fields[1].Detail).Should(Equal(MissingAttributeError("raw.items[*]", "id").(*ErrorResponse).Detail))
+	})
+
+	It("leaves errors with no field errors unchanged", func() {
+		err := ErrBadRequest("bad")
+		Ω(NestError(err, "foo")).Should(Equal(err))
+	})
 })
 
 var _ = Describe("MissingHeaderError", func() {
@@ -269,6 +360,32 @@ var _ = Describe("InvalidRangeError", func() {
 	})
 })
 
+var _ = Describe("InvalidExclusiveRangeError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidExclusiveRangeError("ctx", 0, 0, true)
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal("ctx must be greater than 0 but got value 0"))
+		Ω(err.Meta["comp"]).Should(Equal("greater than"))
+	})
+
+	It("uses the maximum message", func() {
+		err := InvalidExclusiveRangeError("ctx", 1.5, 1.5, false).(*ErrorResponse)
+		Ω(err.Detail).Should(Equal("ctx must be less than 1.5 but got value 1.5"))
+	})
+})
+
+var _ = Describe("InvalidMultipleOfError", func() {
+	It("creates a http error", func() {
+		valErr := InvalidMultipleOfError("ctx.count", 7, 3)
+		Ω(valErr).Should(BeAssignableToTypeOf(&ErrorResponse{}))
+		err := valErr.(*ErrorResponse)
+		Ω(err.Detail).Should(Equal("ctx.count must be a multiple of 3 but got value 7"))
+		Ω(err.Fields).Should(HaveLen(1))
+		Ω(err.Fields[0].Pointer).Should(Equal("/count"))
+	})
+})
+
 var _ = Describe("InvalidLengthError", func() {
 	const ctx = "ctx"
 	const value = 42
@@ -601,3 +718,56 @@ var _ = Describe("Merge", func() {
 	})
 
 })
+
+var _ = Describe("Localize", func() {
+	var cat Catalog
+	var err error
+
+	BeforeEach(func() {
+		cat = Catalog{
+			"fr": {
+				MsgMissingAttribute: "l'attribut %#v de %s est obligatoire",
+				MsgInvalidRangeMin:  "%s doit être supérieur ou égal à %[2]v",
+			},
+		}
+		err = MergeErrors(MissingAttributeError("request", "name"), InvalidRangeError("request.count", 1, 2, true))
+	})
+
+	It("translates the detail and the field errors", func() {
+		e := err.(*ErrorResponse).Localize(cat, "es", "fr-CA")
+		Ω(e.Detail).Should(Equal(`l'attribut "name" de request est obligatoire; request.count doit être supérieur ou égal à 2`))
+		Ω(e.Fields).Should(HaveLen(2))
+		Ω(e.Fields[0].Detail).Should(Equal(`l'attribut "name" de request est obligatoire`))
+		Ω(e.Fields[1].Pointer).Should(Equal("/count"))
+		Ω(e.Fields[1].Detail).Should(Equal("request.count doit être supérieur ou égal à 2"))
+	})
+
+	It("does not modify the error", func() {
+		detail := err.(*ErrorResponse).Detail
+		err.(*ErrorResponse).Localize(cat, "fr")
+		Ω(err.(*ErrorResponse).Detail).Should(Equal(detail))
+	})
+
+	It("returns the error if the languages are not supported", func() {
+		e := err.(*ErrorResponse)
+		Ω(e.Localize(cat, "es")).Should(BeIdenticalTo(e))
+	})
+
+	Context("with an error not created by a helper function", func() {
+		BeforeEach(func() {
+			err = MergeErrors(ErrBadRequest("custom"), MissingAttributeError("request", "name"))
+		})
+
+		It("keeps its detail", func() {
+			e := err.(*ErrorResponse).Localize(cat, "fr")
+			Ω(e.Detail).Should(Equal(`custom; l'attribut "name" de request est obligatoire`))
+		})
+	})
+})
+
+var _ = Describe("AcceptedLanguages", func() {
+	It("sorts the languages by quality", func() {
+		langs := AcceptedLanguages("en;q=0.5, fr-CA, *;q=0.1, de;q=0.8, es;q=0")
+		Ω(langs).Should(Equal([]string{"fr-CA", "de", "en"}))
+	})
+})
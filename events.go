@@ -0,0 +1,96 @@
+package goa
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type (
+	// Message is a message sent through a message broker.
+	Message struct {
+		// Topic is the name of the topic the message is published to.
+		Topic string
+		// Key is used by the brokers that support it to assign the message to a partition.
+		Key string
+		// Header contains the message metadata, e.g. "Content-Type".
+		Header map[string]string
+		// Body is the encoded message payload.
+		Body []byte
+	}
+
+	// Publisher is the interface implemented by the message broker clients that publish the
+	// events, see the Event DSL.
+	Publisher interface {
+		// Publish sends the message to the broker.
+		Publish(ctx context.Context, msg *Message) error
+	}
+
+	// Subscriber is the interface implemented by the message broker clients that consume the
+	// events, see the Event DSL.
+	Subscriber interface {
+		// Subscribe registers the handler invoked for each message published to topic. The
+		// consumers that use the same group share the messages of the topic. An error
+		// returned by the handler means the message was not processed, how it is handled
+		// (e.g. retried) depends on the broker.
+		Subscribe(ctx context.Context, topic, group string, handler func(context.Context, *Message) error) error
+	}
+
+	// EventMethod describes an action that consumes the messages of a topic. This type is
+	// intended for the generated code.
+	EventMethod struct {
+		// Topic is the name of the topic.
+		Topic string
+		// Method is the HTTP method of the action route.
+		Method string
+		// Path is the action route path as given to the service mux.
+		Path string
+	}
+)
+
+// ConsumeEvents subscribes to the topics of the given events and dispatches the messages to the
+// handlers mounted on the service mux for the corresponding action routes. The message body is
+// used as request body and the message header as request headers so that the payloads are
+// decoded and validated as they are for HTTP requests. The handler of a message returns an error
+// if the action response status is 400 or above.
+// This function is intended for the generated code, see the Event DSL.
+func (service *Service) ConsumeEvents(ctx context.Context, sub Subscriber, group string, events []*EventMethod) error {
+	for _, e := range events {
+		e := e
+		handler := func(ctx context.Context, msg *Message) error {
+			return service.consumeEvent(ctx, e, msg)
+		}
+		if err := sub.Subscribe(ctx, e.Topic, group, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consumeEvent invokes the handler of the action mounted for the given event with the given
+// message.
+func (service *Service) consumeEvent(ctx context.Context, e *EventMethod, msg *Message) error {
+	handle := service.Mux.Lookup(e.Method, e.Path)
+	if handle == nil {
+		return fmt.Errorf("no handler mounted for event %s (%s %s)", e.Topic, e.Method, e.Path)
+	}
+	req, err := http.NewRequest(e.Method, e.Path, bytes.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range msg.Header {
+		req.Header.Set(k, v)
+	}
+	if req.Header.Get("Content-Type") == "" && len(msg.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rw := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	handle(rw, req, make(url.Values))
+	if rw.status >= 400 {
+		return fmt.Errorf("event %s: %d %s: %s", e.Topic, rw.status, http.StatusText(rw.status), bytes.TrimSpace(rw.body.Bytes()))
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package goa_test
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// subscriber is a goa.Subscriber that records the handlers indexed by topic.
+type subscriber struct {
+	group    string
+	handlers map[string]func(context.Context, *goa.Message) error
+}
+
+func (s *subscriber) Subscribe(ctx context.Context, topic, group string, handler func(context.Context, *goa.Message) error) error {
+	s.group = group
+	s.handlers[topic] = handler
+	return nil
+}
+
+var _ = Describe("ConsumeEvents", func() {
+	var s *goa.Service
+	var sub *subscriber
+	var payload interface{}
+	var msg *goa.Message
+	var err error
+
+	BeforeEach(func() {
+		payload = nil
+		s = goa.New("test")
+		s.Decoder.Register(goa.NewJSONDecoder, "*/*")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		ctrl := s.NewController("bottle")
+		notify := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			payload = goa.ContextRequest(ctx).Payload
+			if payload.(map[string]interface{})["name"] == "" {
+				return s.Send(ctx, 400, goa.ErrBadRequest("missing name"))
+			}
+			rw.WriteHeader(202)
+			return nil
+		}
+		unm := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+			var payload map[string]interface{}
+			if err := service.DecodeRequest(req, &payload); err != nil {
+				return err
+			}
+			goa.ContextRequest(ctx).Payload = payload
+			return nil
+		}
+		s.Mux.Handle("POST", "/bottles/notify", ctrl.MuxHandler("notify", notify, unm))
+		sub = &subscriber{handlers: make(map[string]func(context.Context, *goa.Message) error)}
+		Ω(s.ConsumeEvents(context.Background(), sub, "workers", []*goa.EventMethod{
+			{Topic: "bottle.notify", Method: "POST", Path: "/bottles/notify"},
+			{Topic: "bottle.missing", Method: "POST", Path: "/bottles/missing"},
+		})).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		err = sub.handlers[msg.Topic](context.Background(), msg)
+	})
+
+	Context("with a message", func() {
+		BeforeEach(func() {
+			msg = &goa.Message{Topic: "bottle.notify", Body: []byte(`{"name":"foo"}`)}
+		})
+
+		It("subscribes to the topics", func() {
+			Ω(sub.group).Should(Equal("workers"))
+			Ω(sub.handlers).Should(HaveLen(2))
+		})
+
+		It("dispatches the message to the action handler", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(payload).Should(Equal(map[string]interface{}{"name": "foo"}))
+		})
+	})
+
+	Context("with a message the action rejects", func() {
+		BeforeEach(func() {
+			msg = &goa.Message{Topic: "bottle.notify", Body: []byte(`{"name":""}`)}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("400"))
+			Ω(err.Error()).Should(ContainSubstring("missing name"))
+		})
+	})
+
+	Context("with a topic whose action is not mounted", func() {
+		BeforeEach(func() {
+			msg = &goa.Message{Topic: "bottle.missing"}
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("no handler mounted"))
+		})
+	})
+})
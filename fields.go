@@ -0,0 +1,199 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// The field types below hold the values of the required primitive attributes of the private
+// structs generated for request bodies whose design sets the "struct:field:value" metadata. They
+// record whether the attribute is present in the decoded body so that the generated validation
+// code can report missing attributes without allocating a pointer for each field. A JSON null
+// leaves the field unset.
+type (
+	// BoolField is a boolean field of a private struct.
+	BoolField struct {
+		// Value is the decoded value.
+		Value bool
+		// Set is true if the value is present in the decoded body.
+		Set bool
+	}
+
+	// IntField is an integer field of a private struct.
+	IntField struct {
+		// Value is the decoded value.
+		Value int
+		// Set is true if the value is present in the decoded body.
+		Set bool
+	}
+
+	// FloatField is a number field of a private struct.
+	FloatField struct {
+		// Value is the decoded value.
+		Value float64
+		// Set is true if the value is present in the decoded body.
+		Set bool
+	}
+
+	// StringField is a string field of a private struct.
+	StringField struct {
+		// Value is the decoded value.
+		Value string
+		// Set is true if the value is present in the decoded body.
+		Set bool
+	}
+
+	// TimeField is a date time field of a private struct.
+	TimeField struct {
+		// Value is the decoded value.
+		Value time.Time
+		// Set is true if the value is present in the decoded body.
+		Set bool
+	}
+)
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *BoolField) UnmarshalJSON(data []byte) error {
+	switch {
+	case string(data) == "null":
+		return nil
+	case string(data) == "true":
+		f.Value = true
+	case string(data) == "false":
+		f.Value = false
+	default:
+		return fieldTypeError(data, f.Value)
+	}
+	f.Set = true
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *BoolField) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseBool(string(text))
+	if err != nil {
+		return err
+	}
+	f.Value, f.Set = v, true
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *IntField) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	v, err := strconv.Atoi(string(data))
+	if err != nil {
+		return fieldTypeError(data, f.Value)
+	}
+	f.Value, f.Set = v, true
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *IntField) UnmarshalText(text []byte) error {
+	v, err := strconv.Atoi(string(text))
+	if err != nil {
+		return err
+	}
+	f.Value, f.Set = v, true
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *FloatField) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return fieldTypeError(data, f.Value)
+	}
+	v, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fieldTypeError(data, f.Value)
+	}
+	f.Value, f.Set = v, true
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *FloatField) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	f.Value, f.Set = v, true
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *StringField) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' {
+		return fieldTypeError(data, f.Value)
+	}
+	if bytes.IndexByte(data, '\\') < 0 {
+		f.Value = string(data[1 : len(data)-1])
+	} else if err := json.Unmarshal(data, &f.Value); err != nil {
+		return err
+	}
+	f.Set = true
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *StringField) UnmarshalText(text []byte) error {
+	f.Value, f.Set = string(text), true
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (f *TimeField) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) == 0 || data[0] != '"' {
+		return fieldTypeError(data, f.Value)
+	}
+	if err := f.Value.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	f.Set = true
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *TimeField) UnmarshalText(text []byte) error {
+	if err := f.Value.UnmarshalText(text); err != nil {
+		return err
+	}
+	f.Set = true
+	return nil
+}
+
+// fieldTypeError returns the error produced when the JSON value data cannot be decoded into a
+// field holding values like v. The JSON decoder adds the path to the field.
+func fieldTypeError(data []byte, v interface{}) error {
+	var kind string
+	switch {
+	case len(data) == 0:
+		kind = "value"
+	case data[0] == '"':
+		kind = "string"
+	case data[0] == '{':
+		kind = "object"
+	case data[0] == '[':
+		kind = "array"
+	case data[0] == 't' || data[0] == 'f':
+		kind = "bool"
+	default:
+		kind = "number " + string(data)
+	}
+	return &json.UnmarshalTypeError{Value: kind, Type: reflect.TypeOf(v)}
+}
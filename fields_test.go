@@ -0,0 +1,64 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("value fields", func() {
+	type payload struct {
+		Flag  goa.BoolField   `json:"flag,omitempty" xml:"flag,omitempty"`
+		Count goa.IntField    `json:"count,omitempty" xml:"count,omitempty"`
+		Ratio goa.FloatField  `json:"ratio,omitempty" xml:"ratio,omitempty"`
+		Name  goa.StringField `json:"name,omitempty" xml:"name,omitempty"`
+		At    goa.TimeField   `json:"at,omitempty" xml:"at,omitempty"`
+	}
+
+	var p payload
+	var decodeErr error
+
+	decode := func(body string) {
+		p = payload{}
+		decodeErr = json.Unmarshal([]byte(body), &p)
+	}
+
+	It("records the decoded values", func() {
+		decode(`{"flag":false,"count":0,"ratio":1.5,"name":"a\"b","at":"2020-01-02T03:04:05Z"}`)
+		Ω(decodeErr).ShouldNot(HaveOccurred())
+		Ω(p.Flag).Should(Equal(goa.BoolField{Value: false, Set: true}))
+		Ω(p.Count).Should(Equal(goa.IntField{Value: 0, Set: true}))
+		Ω(p.Ratio).Should(Equal(goa.FloatField{Value: 1.5, Set: true}))
+		Ω(p.Name).Should(Equal(goa.StringField{Value: `a"b`, Set: true}))
+		Ω(p.At.Set).Should(BeTrue())
+		Ω(p.At.Value.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).Should(BeTrue())
+	})
+
+	It("leaves missing and null values unset", func() {
+		decode(`{"count":null}`)
+		Ω(decodeErr).ShouldNot(HaveOccurred())
+		Ω(p).Should(Equal(payload{}))
+	})
+
+	It("reports type mismatches", func() {
+		decode(`{"count":"1"}`)
+		Ω(decodeErr).Should(HaveOccurred())
+		typeErr, ok := decodeErr.(*json.UnmarshalTypeError)
+		Ω(ok).Should(BeTrue())
+		Ω(typeErr.Value).Should(Equal("string"))
+		Ω(typeErr.Type.Kind().String()).Should(Equal("int"))
+	})
+
+	It("decodes text values", func() {
+		err := xml.Unmarshal([]byte(`<payload><flag>true</flag><count>3</count><name>n</name></payload>`), &p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Flag).Should(Equal(goa.BoolField{Value: true, Set: true}))
+		Ω(p.Count).Should(Equal(goa.IntField{Value: 3, Set: true}))
+		Ω(p.Name).Should(Equal(goa.StringField{Value: "n", Set: true}))
+		Ω(p.Ratio.Set).Should(BeFalse())
+	})
+})
@@ -0,0 +1,172 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+// enumT generates the definition of the Go type of an attribute that defines an Enum validation.
+var enumT = template.Must(template.New("enum").Parse(enumTmpl))
+
+// GoEnumTypeName returns the name of the Go type generated for the given attribute if it is a
+// field of a user type, media type or action payload with an Enum validation and a string,
+// integer or number type, the empty string otherwise. The name is made of the name of the type
+// that defines the field followed by the field name, e.g. "BottleColor". The projected media
+// types share the types generated for the fields of the media type they are projected from.
+func GoEnumTypeName(att *design.AttributeDefinition) string {
+	if !isEnum(att) || design.Design == nil {
+		return ""
+	}
+	var name string
+	find := func(typeName string, ut *design.UserTypeDefinition) bool {
+		if ut == nil || ut.AttributeDefinition == nil {
+			return false
+		}
+		for n, catt := range ut.ToObject() {
+			if catt == att {
+				name = Goify(typeName, true) + Goify(n, true)
+				return true
+			}
+		}
+		return false
+	}
+	for _, ut := range design.Design.Types {
+		if find(ut.TypeName, ut) {
+			if _, pkg := TypePackage(ut); pkg != "" {
+				name = pkg + "." + name
+			}
+			return name
+		}
+	}
+	for _, mt := range design.Design.MediaTypes {
+		if find(mt.TypeName, mt.UserTypeDefinition) {
+			return name
+		}
+	}
+	for _, mt := range design.ProjectedMediaTypes {
+		if base := projectedFrom(mt); base != nil && find(base.TypeName, mt.UserTypeDefinition) {
+			return name
+		}
+	}
+	for _, r := range design.Design.Resources {
+		for _, a := range r.Actions {
+			if a.Payload != nil && find(a.Payload.TypeName, a.Payload) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// GoEnumTypeDefs returns the Go code that defines the types of the fields of the given data
+// structure computed by GoEnumTypeName, their constants and IsValid methods.
+func GoEnumTypeDefs(ds design.DataStructure) string {
+	def := ds.Definition()
+	obj := def.Type.ToObject()
+	var parent string
+	if ut, ok := ds.(*design.UserTypeDefinition); ok {
+		parent = ut.TypeName
+	} else if mt, ok := ds.(*design.MediaTypeDefinition); ok {
+		parent = mt.TypeName
+	}
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, n := range names {
+		att := obj[n]
+		typeName := GoEnumTypeName(att)
+		if typeName == "" || strings.Contains(typeName, ".") {
+			// No type or type defined in another package.
+			continue
+		}
+		values := make([]string, len(att.Validation.Values))
+		for i, v := range att.Validation.Values {
+			values[i] = fmt.Sprintf("%#v", v)
+		}
+		data := map[string]interface{}{
+			"name":      typeName,
+			"field":     n,
+			"parent":    parent,
+			"native":    GoNativeType(att.Type),
+			"constants": enumConstants(typeName, att.Validation.Values),
+			"values":    values,
+		}
+		buf.WriteString(RunTemplate(enumT, data))
+	}
+	return buf.String()
+}
+
+// isEnum returns true if a Go type is generated for the given attribute when it is a field of a
+// user type, media type or action payload.
+func isEnum(att *design.AttributeDefinition) bool {
+	if att == nil || att.Validation == nil || len(att.Validation.Values) == 0 {
+		return false
+	}
+	if _, ok := att.Metadata["struct:field:type"]; ok {
+		return false
+	}
+	p, ok := att.Type.(design.Primitive)
+	if !ok {
+		return false
+	}
+	switch p.Kind() {
+	case design.StringKind, design.IntegerKind, design.NumberKind:
+		return true
+	}
+	return false
+}
+
+// projectedFrom returns the media type that mt was projected from.
+func projectedFrom(mt *design.MediaTypeDefinition) *design.MediaTypeDefinition {
+	base, params, err := mime.ParseMediaType(mt.Identifier)
+	if err != nil {
+		return nil
+	}
+	delete(params, "view")
+	return design.Design.MediaTypeWithIdentifier(mime.FormatMediaType(base, params))
+}
+
+// enumConstants returns the names of the constants of the enum type with the given name and
+// values. The names are suffixed with the position of the value if it does not produce a unique
+// identifier.
+func enumConstants(typeName string, values []interface{}) []string {
+	names := make([]string, len(values))
+	seen := make(map[string]bool, len(values))
+	for i, v := range values {
+		n := typeName + Goify(fmt.Sprint(v), true)
+		if n == typeName || seen[n] {
+			n = fmt.Sprintf("%s%d", typeName, i+1)
+		}
+		seen[n] = true
+		names[i] = n
+	}
+	return names
+}
+
+const enumTmpl = `// {{ .name }} enumerates the values of the {{ .field }} attribute of {{ .parent }}.
+type {{ .name }} {{ .native }}
+
+// {{ .name }} values.
+const (
+{{ range $i, $c := .constants }}	{{ $c }} {{ $.name }} = {{ index $.values $i }}
+{{ end }})
+
+// IsValid returns true if e is one of the {{ .name }} values.
+func (e {{ .name }}) IsValid() bool {
+	switch e {
+	case {{ range $i, $c := .constants }}{{ if $i }}, {{ end }}{{ $c }}{{ end }}:
+		return true
+	}
+	return false
+}
+
+`
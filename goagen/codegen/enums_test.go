@@ -0,0 +1,103 @@
+package codegen_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("enum types", func() {
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		ut = Type("Bottle", func() {
+			Attribute("color", String, func() {
+				Enum("red", "rosé wine")
+			})
+			Attribute("year", Integer, func() {
+				Enum(2015, 2016)
+			})
+			Attribute("name", String)
+			Attribute("origin", func() {
+				Attribute("country", String, func() {
+					Enum("fr", "us")
+				})
+			})
+			Required("year")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	Describe("GoEnumTypeName", func() {
+		It("names the enum fields after the type and the field", func() {
+			obj := ut.ToObject()
+			Ω(codegen.GoEnumTypeName(obj["color"])).Should(Equal("BottleColor"))
+			Ω(codegen.GoEnumTypeName(obj["year"])).Should(Equal("BottleYear"))
+		})
+
+		It("ignores the fields without enum and the nested fields", func() {
+			obj := ut.ToObject()
+			Ω(codegen.GoEnumTypeName(obj["name"])).Should(BeEmpty())
+			Ω(codegen.GoEnumTypeName(obj["origin"].Type.ToObject()["country"])).Should(BeEmpty())
+		})
+	})
+
+	Describe("GoTypeDef", func() {
+		It("uses the enum types", func() {
+			Ω(codegen.GoTypeDef(ut, 0, false, false)).Should(Equal(`struct {
+	Color *BottleColor
+	Year BottleYear
+	Name *string
+	Origin *struct {
+		Country *string
+	}
+}`))
+		})
+	})
+
+	Describe("GoEnumTypeDefs", func() {
+		It("defines the enum types", func() {
+			Ω(codegen.GoEnumTypeDefs(ut)).Should(Equal(`// BottleColor enumerates the values of the color attribute of Bottle.
+type BottleColor string
+
+// BottleColor values.
+const (
+	BottleColorRed BottleColor = "red"
+	BottleColorRoséWine BottleColor = "rosé wine"
+)
+
+// IsValid returns true if e is one of the BottleColor values.
+func (e BottleColor) IsValid() bool {
+	switch e {
+	case BottleColorRed, BottleColorRoséWine:
+		return true
+	}
+	return false
+}
+
+// BottleYear enumerates the values of the year attribute of Bottle.
+type BottleYear int
+
+// BottleYear values.
+const (
+	BottleYear2015 BottleYear = 2015
+	BottleYear2016 BottleYear = 2016
+)
+
+// IsValid returns true if e is one of the BottleYear values.
+func (e BottleYear) IsValid() bool {
+	switch e {
+	case BottleYear2015, BottleYear2016:
+		return true
+	}
+	return false
+}
+
+`))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"sort"
+
+	"github.com/goadesign/goa/design"
+)
+
+const (
+	// FieldOrderDesign generates the struct fields in the order the attributes are defined in
+	// the design, the default.
+	FieldOrderDesign = "design"
+	// FieldOrderName generates the struct fields sorted by attribute name.
+	FieldOrderName = "name"
+	// FieldOrderAlignment generates the struct fields sorted by decreasing alignment so that
+	// the structs need as little padding as possible, the fields with the same alignment
+	// keep the design order.
+	FieldOrderAlignment = "alignment"
+)
+
+// structField describes a field of a generated struct.
+type structField struct {
+	name    string
+	att     *design.AttributeDefinition
+	typedef string
+}
+
+// fieldOrder returns the order of the fields of the struct generated for def set with the
+// "struct:field:order" metadata on def or on the API, FieldOrderDesign if there is none.
+func fieldOrder(def *design.AttributeDefinition) string {
+	if o, ok := def.Metadata["struct:field:order"]; ok && len(o) > 0 {
+		return o[0]
+	}
+	if design.Design != nil {
+		if o, ok := design.Design.Metadata["struct:field:order"]; ok && len(o) > 0 {
+			return o[0]
+		}
+	}
+	return FieldOrderDesign
+}
+
+// sortFields sorts the given fields in the given order. The fields of the attributes that were
+// not defined with the DSL come first in the design order, sorted by name, so that the order is
+// always deterministic.
+func sortFields(fields []*structField, order string) {
+	byName := func(i, j int) bool { return fields[i].name < fields[j].name }
+	if order == FieldOrderName {
+		sort.Slice(fields, byName)
+		return
+	}
+	byPosition := func(i, j int) bool {
+		if pi, pj := fields[i].att.Position, fields[j].att.Position; pi != pj {
+			return pi < pj
+		}
+		return byName(i, j)
+	}
+	if order != FieldOrderAlignment {
+		sort.Slice(fields, byPosition)
+		return
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if ai, aj := fieldAlignment(fields[i].typedef), fieldAlignment(fields[j].typedef); ai != aj {
+			return ai > aj
+		}
+		return byPosition(i, j)
+	})
+}
+
+// fieldAlignment returns the alignment in bytes of the Go type with the given definition on 64
+// bit platforms. The types whose alignment cannot be determined are assumed to be word aligned.
+func fieldAlignment(typedef string) int {
+	switch typedef {
+	case "bool", "goa.BoolField", "uuid.UUID", "byte", "int8", "uint8":
+		return 1
+	case "int16", "uint16":
+		return 2
+	case "int32", "uint32", "float32", "rune":
+		return 4
+	}
+	return 8
+}
@@ -70,6 +70,7 @@ func (f *Finalizer) recurse(root, att *design.AttributeDefinition, target string
 					"catt":       catt,
 					"depth":      depth,
 					"isDatetime": catt.Type == design.DateTime,
+					"enumType":   GoEnumTypeName(catt),
 					"defaultVal": PrintVal(catt.Type, catt.DefaultValue),
 				}
 				if !first {
@@ -163,7 +164,7 @@ func PrintVal(t design.DataType, val interface{}) string {
 
 const (
 	assignmentTmpl = `{{ if .catt.Type.IsPrimitive }}{{ $defaultName := (print "default" (goify .field true)) }}{{/*
-*/}}{{ tabs .depth }}var {{ $defaultName }}{{if .isDatetime}}, _{{end}} = {{ .defaultVal }}
+*/}}{{ tabs .depth }}var {{ $defaultName }}{{ if .enumType }} {{ .enumType }}{{ end }}{{if .isDatetime}}, _{{end}} = {{ .defaultVal }}
 {{ tabs .depth }}if {{ .target }}.{{ goify .field true }} == nil {
 {{ tabs .depth }}	{{ .target }}.{{ goify .field true }} = &{{ $defaultName }}
 }{{ else }}{{ tabs .depth }}if {{ .target }}.{{ goify .field true }} == nil {
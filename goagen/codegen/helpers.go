@@ -26,6 +26,16 @@ func CheckVersion(ver string) error {
 	return nil
 }
 
+// SelectedAPI returns the API definition restricted to the resources listed in the comma
+// separated value of the --service flag given to the generators, the complete API definition if
+// the value is empty.
+func SelectedAPI(services string) (*design.APIDefinition, error) {
+	if services == "" {
+		return design.Design, nil
+	}
+	return design.Design.SelectResources(strings.Split(services, ","))
+}
+
 // CommandLine return the command used to run this process.
 func CommandLine() string {
 	// We don't use the full path to the tool so that running goagen multiple times doesn't
@@ -31,7 +31,7 @@ func (s *ImportSpec) Code() string {
 }
 
 // AttributeImports will construct a new ImportsSpec slice from an existing slice and add in imports specified in
-// struct:field:type Metadata tags.
+// struct:field:type and struct:pkg:path Metadata tags.
 func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, seen []*design.AttributeDefinition) []*ImportSpec {
 
 	for _, a := range seen {
@@ -51,6 +51,9 @@ func AttributeImports(att *design.AttributeDefinition, imports []*ImportSpec, se
 
 	switch t := att.Type.(type) {
 	case *design.UserTypeDefinition:
+		if path, name := TypePackage(t); path != "" {
+			imports = appendImports(imports, []*ImportSpec{NewImport(name, path)})
+		}
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
 	case *design.MediaTypeDefinition:
 		return appendImports(imports, AttributeImports(t.AttributeDefinition, imports, seen))
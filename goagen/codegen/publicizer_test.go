@@ -53,6 +53,25 @@ var _ = Describe("Struct publicize code generation", func() {
 				Ω(publication).Should(Equal(objectPublicizeCode))
 			})
 		})
+		Context("given an object with value fields", func() {
+			BeforeEach(func() {
+				att = &design.AttributeDefinition{
+					Type: design.Object{
+						"count": &design.AttributeDefinition{Type: design.Integer},
+						"label": &design.AttributeDefinition{Type: design.String},
+						"name":  &design.AttributeDefinition{Type: design.String},
+					},
+					Metadata: dslengine.MetadataDefinition{"struct:field:value": nil},
+					Validation: &dslengine.ValidationDefinition{
+						Required: []string{"count", "name"},
+					},
+				}
+			})
+			It("copies the values", func() {
+				publication := codegen.RecursivePublicizer(att, "source", "target", 0)
+				Ω(publication).Should(Equal(valueFieldsPublicizeCode))
+			})
+		})
 		Context("given a user type", func() {
 			BeforeEach(func() {
 				att = &design.AttributeDefinition{
@@ -191,6 +210,12 @@ if source.Foo != nil {
 	target.Foo = source.Foo
 }`
 
+	valueFieldsPublicizeCode = `target.Count = source.Count.Value
+if source.Label != nil {
+	target.Label = source.Label
+}
+target.Name = source.Name.Value`
+
 	arrayPublicizeCode = `target = make([]*TheUserType, len(source))
 for i0, elem0 := range source {
 	target[i0] = elem0.Publicize()
@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+// Sanitizer is the code generator for the 'Sanitize' type methods.
+type Sanitizer struct {
+	// visiting records the user types being generated to stop on recursive types.
+	visiting map[*design.UserTypeDefinition]bool
+}
+
+// NewSanitizer instantiates a sanitize code generator.
+func NewSanitizer() *Sanitizer {
+	return &Sanitizer{visiting: make(map[*design.UserTypeDefinition]bool)}
+}
+
+// Code produces Go code that applies the sanitizers defined on the string fields of the given
+// attribute recursively. target is the name of the variable holding the value of the private
+// type generated for the attribute, that is the type with pointer fields used to decode request
+// bodies. Code returns the empty string if no field defines sanitizers. The fields of hashes and
+// the nested occurrences of recursive types are not sanitized.
+func (s *Sanitizer) Code(att *design.AttributeDefinition, target string, depth int) string {
+	o := att.Type.ToObject()
+	if o == nil {
+		return ""
+	}
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+		if s.visiting[ut] {
+			return ""
+		}
+		s.visiting[ut] = true
+		defer delete(s.visiting, ut)
+	} else if mt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+		if s.visiting[mt.UserTypeDefinition] {
+			return ""
+		}
+		s.visiting[mt.UserTypeDefinition] = true
+		defer delete(s.visiting, mt.UserTypeDefinition)
+	}
+	var lines []string
+	o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+		if catt.ReadOnly {
+			return nil
+		}
+		field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+		if code := s.fieldCode(att, n, catt, field, depth); code != "" {
+			lines = append(lines, code)
+		}
+		return nil
+	})
+	return strings.Join(lines, "\n")
+}
+
+// fieldCode produces the code that sanitizes the value of the field n of parent stored in target.
+func (s *Sanitizer) fieldCode(parent *design.AttributeDefinition, n string, att *design.AttributeDefinition, target string, depth int) string {
+	tabs := Tabs(depth)
+	switch {
+	case att.Type.Kind() == design.StringKind:
+		if len(att.Sanitizers) == 0 {
+			return ""
+		}
+		if parent.IsValueField(n) {
+			val := target + ".Value"
+			return fmt.Sprintf("%s%s = %s", tabs, val, sanitizeExpr(att, val, ""))
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s\t*%s = %s\n%s}",
+			tabs, target, tabs, target, sanitizeExpr(att, "*"+target, GoEnumTypeName(att)), tabs)
+	case att.Type.IsObject():
+		code := s.Code(att, target, depth+1)
+		if code == "" {
+			return ""
+		}
+		return fmt.Sprintf("%sif %s != nil {\n%s\n%s}", tabs, target, code, tabs)
+	case att.Type.IsArray():
+		elem := att.Type.ToArray().ElemType
+		if elem.Type.Kind() == design.StringKind {
+			if len(elem.Sanitizers) == 0 {
+				return ""
+			}
+			e := target + "[i]"
+			return fmt.Sprintf("%sfor i := range %s {\n%s\t%s = %s\n%s}",
+				tabs, target, tabs, e, sanitizeExpr(elem, e, ""), tabs)
+		}
+		if !elem.Type.IsObject() {
+			return ""
+		}
+		code := s.Code(elem, "e", depth+2)
+		if code == "" {
+			return ""
+		}
+		return fmt.Sprintf("%sfor _, e := range %s {\n%s\tif e != nil {\n%s\n%s\t}\n%s}",
+			tabs, target, tabs, code, tabs, tabs)
+	}
+	return ""
+}
+
+// sanitizeExpr returns the expression that sanitizes the string value val of the given attribute.
+// enum is the name of the Go type of val if it is an enum type.
+func sanitizeExpr(att *design.AttributeDefinition, val, enum string) string {
+	var args bytes.Buffer
+	for _, n := range att.Sanitizers {
+		fmt.Fprintf(&args, ", %q", n)
+	}
+	if enum != "" {
+		return fmt.Sprintf("%s(goa.Sanitize(string(%s)%s))", enum, val, args.String())
+	}
+	return fmt.Sprintf("goa.Sanitize(%s%s)", val, args.String())
+}
@@ -0,0 +1,93 @@
+package codegen_test
+
+import (
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Struct sanitize code generation", func() {
+	var (
+		att       *design.AttributeDefinition
+		sanitizer *codegen.Sanitizer
+	)
+
+	BeforeEach(func() {
+		sanitizer = codegen.NewSanitizer()
+	})
+
+	Context("given an object without sanitizers", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: design.Object{"foo": &design.AttributeDefinition{Type: design.String}},
+			}
+		})
+
+		It("produces no code", func() {
+			Ω(sanitizer.Code(att, "ut", 1)).Should(BeEmpty())
+		})
+	})
+
+	Context("given an object with sanitized string fields", func() {
+		BeforeEach(func() {
+			att = &design.AttributeDefinition{
+				Type: design.Object{
+					"name": &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim", "lowercase"}},
+					"note": &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"collapse"}},
+					"tags": &design.AttributeDefinition{Type: &design.Array{
+						ElemType: &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim"}},
+					}},
+					"child": &design.AttributeDefinition{Type: design.Object{
+						"name": &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim"}},
+					}},
+				},
+				Validation: &dslengine.ValidationDefinition{Required: []string{"note"}},
+			}
+		})
+
+		It("sanitizes the fields", func() {
+			Ω(sanitizer.Code(att, "ut", 1)).Should(Equal(sanitizeCode))
+		})
+	})
+
+	Context("given a recursive user type", func() {
+		BeforeEach(func() {
+			node := &design.UserTypeDefinition{TypeName: "Node"}
+			node.AttributeDefinition = &design.AttributeDefinition{
+				Type: design.Object{
+					"name":   &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim"}},
+					"parent": &design.AttributeDefinition{Type: node},
+				},
+			}
+			att = node.AttributeDefinition
+		})
+
+		It("sanitizes the nested values once", func() {
+			Ω(sanitizer.Code(&design.AttributeDefinition{Type: att.Type.ToObject()["parent"].Type}, "ut", 1)).
+				Should(Equal(recursiveSanitizeCode))
+		})
+	})
+})
+
+const (
+	sanitizeCode = `	if ut.Child != nil {
+		if ut.Child.Name != nil {
+			*ut.Child.Name = goa.Sanitize(*ut.Child.Name, "trim")
+		}
+	}
+	if ut.Name != nil {
+		*ut.Name = goa.Sanitize(*ut.Name, "trim", "lowercase")
+	}
+	if ut.Note != nil {
+		*ut.Note = goa.Sanitize(*ut.Note, "collapse")
+	}
+	for i := range ut.Tags {
+		ut.Tags[i] = goa.Sanitize(ut.Tags[i], "trim")
+	}`
+
+	recursiveSanitizeCode = `	if ut.Name != nil {
+		*ut.Name = goa.Sanitize(*ut.Name, "trim")
+	}`
+)
@@ -0,0 +1,93 @@
+package codegen
+
+import "github.com/goadesign/goa/design"
+
+// SensitiveType describes a generated Go type whose values may contain sensitive attributes.
+type SensitiveType struct {
+	// GoType is the name of the generated Go type.
+	GoType string
+	// Identifier is the identifier of the media type view if the type is one.
+	Identifier string
+	// Fields lists the keys of the sensitive attributes of the type, see
+	// design.AttributeDefinition.SensitiveFields.
+	Fields []string
+}
+
+// SensitiveTypes returns the user types, media type views and action payloads of the given API
+// that define sensitive attributes, and the media type views whose values may contain sensitive
+// attributes of the types they reference. The generated code registers them with
+// goa.RegisterSensitiveFields and goa.RegisterSensitiveMediaType.
+func SensitiveTypes(api *design.APIDefinition) []*SensitiveType {
+	var types []*SensitiveType
+	seen := make(map[string]bool)
+	add := func(goType, identifier string, att *design.AttributeDefinition) {
+		if seen[goType] {
+			return
+		}
+		seen[goType] = true
+		fields := att.SensitiveFields()
+		if len(fields) == 0 && (identifier == "" || !hasSensitive(att, make(map[*design.AttributeDefinition]bool))) {
+			return
+		}
+		types = append(types, &SensitiveType{GoType: goType, Identifier: identifier, Fields: fields})
+	}
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.Type.IsObject() || mt.Type.IsArray()) {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			add(GoTypeName(p, p.AllRequired(), 0, false), p.Identifier, p.AttributeDefinition)
+			return nil
+		})
+	})
+	api.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		goType := GoTypeName(t, t.AllRequired(), 0, false)
+		if path, _ := TypePackage(t); path != "" {
+			// The types of other packages register their own sensitive attributes.
+			seen[goType] = true
+			return nil
+		}
+		add(goType, "", t.AttributeDefinition)
+		return nil
+	})
+	api.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				add(GoTypeName(a.Payload, nil, 0, false), "", a.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
+	return types
+}
+
+// hasSensitive returns true if the given attribute or any attribute it contains, including the
+// attributes of the types it references, is sensitive.
+func hasSensitive(att *design.AttributeDefinition, seen map[*design.AttributeDefinition]bool) bool {
+	if att == nil || seen[att] {
+		return false
+	}
+	seen[att] = true
+	if att.Sensitive {
+		return true
+	}
+	switch {
+	case att.Type == nil:
+		return false
+	case att.Type.IsObject():
+		for _, child := range att.Type.ToObject() {
+			if hasSensitive(child, seen) {
+				return true
+			}
+		}
+	case att.Type.IsArray():
+		return hasSensitive(att.Type.ToArray().ElemType, seen)
+	case att.Type.IsHash():
+		return hasSensitive(att.Type.ToHash().ElemType, seen)
+	}
+	return false
+}
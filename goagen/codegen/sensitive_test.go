@@ -0,0 +1,47 @@
+package codegen_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SensitiveTypes", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		credentials := Type("Credentials", func() {
+			Attribute("login", String)
+			Attribute("password", String, func() { Sensitive() })
+		})
+		Type("Login", func() {
+			Attribute("password", String)
+		})
+		MediaType("application/vnd.account+json", func() {
+			Attributes(func() {
+				Attribute("name", String)
+				Attribute("credentials", credentials)
+			})
+			View("default", func() {
+				Attribute("name")
+				Attribute("credentials")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	It("lists the types that define sensitive attributes and the media types that contain them", func() {
+		types := codegen.SensitiveTypes(Design)
+		Ω(types).Should(HaveLen(2))
+		Ω(*types[0]).Should(Equal(codegen.SensitiveType{
+			GoType:     "Account",
+			Identifier: "application/vnd.account+json; view=default",
+		}))
+		Ω(*types[1]).Should(Equal(codegen.SensitiveType{
+			GoType: "Credentials",
+			Fields: []string{"password"},
+		}))
+	})
+})
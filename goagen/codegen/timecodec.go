@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+// timeCodecTmpl is the template used to generate the JSON methods of the types whose date time
+// attributes override the default RFC3339 encoding.
+var timeCodecTmpl = template.Must(template.New("timeCodec").Parse(timeCodecT))
+
+// TimeCodec returns the MarshalJSON and UnmarshalJSON methods of the Go struct with the given
+// name generated for att. The methods encode and decode the date time fields with the formats
+// defined in the design by the TimeFormats and TimeOutput DSLs. It returns the empty string if
+// none of the child attributes of att override the default RFC3339 encoding. Only UnmarshalJSON
+// is generated for private structs, private and response are the arguments given to GoTypeDef.
+func TimeCodec(att *design.AttributeDefinition, typeName string, private, response bool) string {
+	o := att.Type.ToObject()
+	if o == nil {
+		return ""
+	}
+	var fields []map[string]interface{}
+	o.IterateAttributes(func(n string, catt *design.AttributeDefinition) error {
+		if !catt.HasTimeEncoding() {
+			return nil
+		}
+		if _, ok := catt.Metadata["struct:field:type"]; ok {
+			return nil
+		}
+		if (private && catt.ReadOnly) || (response && catt.WriteOnly) {
+			// The fields have "-" tags.
+			return nil
+		}
+		key := JSONKey(n, catt)
+		if key == "-" {
+			return nil
+		}
+		value := private && att.IsValueField(n)
+		output, zone := catt.TimeOutput()
+		fields = append(fields, map[string]interface{}{
+			"Field":   GoifyAtt(catt, n, true),
+			"Key":     key,
+			"Pointer": !value && (private || att.IsPrimitivePointer(n)),
+			"Value":   value,
+			"Formats": catt.TimeFormats(),
+			"Output":  output,
+			"Zone":    zone,
+		})
+		return nil
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return RunTemplate(timeCodecTmpl, map[string]interface{}{
+		"Name":    typeName,
+		"Private": private,
+		"Fields":  fields,
+	})
+}
+
+const timeCodecT = `{{ if not .Private }}
+// MarshalJSON encodes the {{ .Name }} instance to JSON using the time formats defined in the
+// design for the date time fields.
+func (ut {{ .Name }}) MarshalJSON() ([]byte, error) {
+	type alias {{ .Name }}
+	aux := struct {
+		alias
+{{ range .Fields }}		{{ .Field }} json.RawMessage ` + "`" + `json:"{{ .Key }}{{ if .Pointer }},omitempty{{ end }}"` + "`" + `
+{{ end }}	}{alias: alias(ut)}
+{{ range .Fields }}{{ if .Pointer }}	if ut.{{ .Field }} != nil {
+		aux.{{ .Field }} = goa.EncodeJSONTime(*ut.{{ .Field }}, {{ printf "%q" .Output }}, {{ printf "%q" .Zone }})
+	}
+{{ else }}	aux.{{ .Field }} = goa.EncodeJSONTime(ut.{{ .Field }}, {{ printf "%q" .Output }}, {{ printf "%q" .Zone }})
+{{ end }}{{ end }}	return json.Marshal(aux)
+}
+{{ end }}
+// UnmarshalJSON decodes the {{ .Name }} instance from JSON accepting the time formats defined in
+// the design for the date time fields.
+func (ut *{{ .Name }}) UnmarshalJSON(data []byte) error {
+	type alias {{ .Name }}
+	aux := struct {
+		*alias
+{{ range .Fields }}		{{ .Field }} json.RawMessage ` + "`" + `json:"{{ .Key }}"` + "`" + `
+{{ end }}	}{alias: (*alias)(ut)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+{{ range .Fields }}	if aux.{{ .Field }} != nil && string(aux.{{ .Field }}) != "null" {
+		t, err := goa.DecodeJSONTime(aux.{{ .Field }}{{ range .Formats }}, {{ printf "%q" . }}{{ end }})
+		if err != nil {
+			return goa.InvalidAttributeTypeError({{ printf "%q" .Key }}, string(aux.{{ .Field }}), "datetime")
+		}
+		ut.{{ .Field }} = {{ if .Value }}goa.TimeField{Value: t, Set: true}{{ else if .Pointer }}&t{{ else }}t{{ end }}
+	}
+{{ end }}	return nil
+}
+`
@@ -0,0 +1,48 @@
+package codegen_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TimeCodec", func() {
+	var ut *UserTypeDefinition
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		ut = Type("Event", func() {
+			Attribute("at", DateTime, func() {
+				TimeFormats(RFC3339, UnixSeconds)
+				TimeOutput(UnixSeconds, "UTC")
+			})
+			Attribute("created_at", DateTime)
+			Required("at")
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	It("generates the JSON methods of the public type", func() {
+		code := codegen.TimeCodec(ut.AttributeDefinition, "Event", false, false)
+		Ω(code).Should(ContainSubstring("func (ut Event) MarshalJSON() ([]byte, error) {"))
+		Ω(code).Should(ContainSubstring(`aux.At = goa.EncodeJSONTime(ut.At, "unix", "UTC")`))
+		Ω(code).Should(ContainSubstring("func (ut *Event) UnmarshalJSON(data []byte) error {"))
+		Ω(code).Should(ContainSubstring(`t, err := goa.DecodeJSONTime(aux.At, "rfc3339", "unix")`))
+		Ω(code).Should(ContainSubstring("ut.At = t\n"))
+		Ω(code).ShouldNot(ContainSubstring("CreatedAt"))
+	})
+
+	It("only generates UnmarshalJSON for the private type", func() {
+		code := codegen.TimeCodec(ut.AttributeDefinition, "event", true, false)
+		Ω(code).ShouldNot(ContainSubstring("MarshalJSON() ([]byte, error)"))
+		Ω(code).Should(ContainSubstring("ut.At = &t\n"))
+	})
+
+	It("generates nothing without time encodings", func() {
+		code := codegen.TimeCodec(&AttributeDefinition{Type: Object{"created_at": {Type: DateTime}}}, "Event", false, false)
+		Ω(code).Should(BeEmpty())
+	})
+})
@@ -41,6 +41,7 @@ func init() {
 		"transformHash":      transformHash,
 		"transformObject":    transformObject,
 		"typeName":           typeName,
+		"convertEnum":        convertEnum,
 	}
 	if transformT, err = template.New("transform").Funcs(fn).Parse(transformTmpl); err != nil {
 		panic(err) // bug
@@ -62,8 +63,18 @@ func init() {
 // line is never indented.
 // jsonTags controls whether to produce json tags.
 // private controls whether the field is a pointer or not. All fields in the struct are
-//   pointers for a private struct.
+//   pointers for a private struct except the value fields of the required primitive attributes
+//   with the "struct:field:value" metadata (see design.AttributeDefinition.IsValueField).
+// The fields of the read-only attributes of private structs and of the write-only attributes of
+// media types have "-" tags so that they are ignored when decoding requests and when rendering
+// responses respectively.
 func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string {
+	_, response := ds.(*design.MediaTypeDefinition)
+	return goTypeDef(ds, tabs, jsonTags, private, response)
+}
+
+// goTypeDef implements GoTypeDef, response is true when defining a media type.
+func goTypeDef(ds design.DataStructure, tabs int, jsonTags, private, response bool) string {
 	def := ds.Definition()
 	if tname, ok := def.Metadata["struct:field:type"]; ok {
 		if len(tname) > 0 {
@@ -75,23 +86,23 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 	case design.Primitive:
 		return GoTypeName(t, nil, tabs, private)
 	case *design.Array:
-		d := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
+		d := goTypeDef(actual.ElemType, tabs, jsonTags, private, response)
 		if actual.ElemType.Type.IsObject() {
 			d = "*" + d
 		}
 		return "[]" + d
 	case *design.Hash:
-		keyDef := GoTypeDef(actual.KeyType, tabs, jsonTags, private)
+		keyDef := goTypeDef(actual.KeyType, tabs, jsonTags, private, response)
 		if actual.KeyType.Type.IsObject() {
 			keyDef = "*" + keyDef
 		}
-		elemDef := GoTypeDef(actual.ElemType, tabs, jsonTags, private)
+		elemDef := goTypeDef(actual.ElemType, tabs, jsonTags, private, response)
 		if actual.ElemType.Type.IsObject() {
 			elemDef = "*" + elemDef
 		}
 		return fmt.Sprintf("map[%s]%s", keyDef, elemDef)
 	case design.Object:
-		return goTypeDefObject(actual, def, tabs, jsonTags, private)
+		return goTypeDefObject(actual, def, tabs, jsonTags, private, response)
 	case *design.UserTypeDefinition:
 		return GoTypeName(actual, actual.AllRequired(), tabs, private)
 	case *design.MediaTypeDefinition:
@@ -101,30 +112,44 @@ func GoTypeDef(ds design.DataStructure, tabs int, jsonTags, private bool) string
 	}
 }
 
+// valueFieldTypes lists the types of the value fields of private structs indexed by attribute
+// type kind, see design.AttributeDefinition.IsValueField.
+var valueFieldTypes = map[design.Kind]string{
+	design.BooleanKind:  "goa.BoolField",
+	design.IntegerKind:  "goa.IntField",
+	design.NumberKind:   "goa.FloatField",
+	design.StringKind:   "goa.StringField",
+	design.DateTimeKind: "goa.TimeField",
+}
+
 // goTypeDefObject returns the Go code that defines a Go struct.
-func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs int, jsonTags, private bool) string {
+func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs int, jsonTags, private, response bool) string {
 	var buffer bytes.Buffer
 	buffer.WriteString("struct {\n")
-	keys := make([]string, len(obj))
-	i := 0
-	for n := range obj {
-		keys[i] = n
-		i++
-	}
-	sort.Strings(keys)
-	for _, name := range keys {
-		WriteTabs(&buffer, tabs+1)
-		field := obj[name]
-		typedef := GoTypeDef(field, tabs+1, jsonTags, private)
-		if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
+	fields := make([]*structField, 0, len(obj))
+	for name, field := range obj {
+		typedef := goTypeDef(field, tabs+1, jsonTags, private, response)
+		if enum := GoEnumTypeName(field); enum != "" {
+			typedef = enum
+		}
+		if private && def.IsValueField(name) {
+			typedef = valueFieldTypes[field.Type.Kind()]
+		} else if (private && field.Type.IsPrimitive() && !def.IsInterface(name)) || field.Type.IsObject() || def.IsPrimitivePointer(name) {
 			typedef = "*" + typedef
 		}
+		fields = append(fields, &structField{name: name, att: field, typedef: typedef})
+	}
+	sortFields(fields, fieldOrder(def))
+	for _, f := range fields {
+		name, field, typedef := f.name, f.att, f.typedef
+		WriteTabs(&buffer, tabs+1)
 		fname := GoifyAtt(field, name, true)
 		var tags string
 		if jsonTags {
-			tags = attributeTags(def, field, name, private)
+			ignored := (private && field.ReadOnly) || (response && field.WriteOnly)
+			tags = attributeTags(def, field, name, private, ignored)
 		}
-		desc := obj[name].Description
+		desc := field.Description
 		if desc != "" {
 			desc = strings.Replace(desc, "\n", "\n\t// ", -1)
 			desc = fmt.Sprintf("// %s\n\t", desc)
@@ -136,8 +161,9 @@ func goTypeDefObject(obj design.Object, def *design.AttributeDefinition, tabs in
 	return buffer.String()
 }
 
-// attributeTags computes the struct field tags.
-func attributeTags(parent, att *design.AttributeDefinition, name string, private bool) string {
+// attributeTags computes the struct field tags. The default tags of ignored fields omit the field
+// from the encoded and decoded documents.
+func attributeTags(parent, att *design.AttributeDefinition, name string, private, ignored bool) string {
 	var elems []string
 	keys := make([]string, len(att.Metadata))
 	i := 0
@@ -146,23 +172,50 @@ func attributeTags(parent, att *design.AttributeDefinition, name string, private
 		i++
 	}
 	sort.Strings(keys)
+	var extras []string
 	for _, key := range keys {
 		val := att.Metadata[key]
+		if strings.HasPrefix(key, "struct:tag:extra:") {
+			extras = append(extras, fmt.Sprintf("%s:\"%s\"", key[17:], strings.Join(val, ",")))
+			continue
+		}
 		if strings.HasPrefix(key, "struct:tag:") {
 			name := key[11:]
 			value := strings.Join(val, ",")
 			elems = append(elems, fmt.Sprintf("%s:\"%s\"", name, value))
 		}
 	}
-	if len(elems) > 0 {
-		return " `" + strings.Join(elems, " ") + "`"
+	if len(elems) == 0 && ignored {
+		elems = []string{`form:"-" json:"-" xml:"-"`}
 	}
-	// Default algorithm
-	var omit string
-	if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
-		omit = ",omitempty"
+	if len(elems) == 0 {
+		// Default algorithm
+		var omit string
+		if private || (!parent.IsRequired(name) && !parent.HasDefaultValue(name)) {
+			omit = ",omitempty"
+		}
+		key := att.AttributeKey(name)
+		elems = []string{fmt.Sprintf("form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"", key, omit, key, omit, key, omit)}
 	}
-	return fmt.Sprintf(" `form:\"%s%s\" json:\"%s%s\" xml:\"%s%s\"`", name, omit, name, omit, name, omit)
+	return " `" + strings.Join(append(elems, extras...), " ") + "`"
+}
+
+// JSONKey returns the JSON key of the field generated for the given object attribute, see the
+// struct:tag metadata.
+func JSONKey(name string, att *design.AttributeDefinition) string {
+	if tag, ok := att.Metadata["struct:tag:json"]; ok && len(tag) > 0 {
+		if tag[0] != "" {
+			return tag[0]
+		}
+		return GoifyAtt(att, name, true)
+	}
+	for key := range att.Metadata {
+		if strings.HasPrefix(key, "struct:tag:") && !strings.HasPrefix(key, "struct:tag:extra:") {
+			// No JSON tag, encoding/json uses the field name.
+			return GoifyAtt(att, name, true)
+		}
+	}
+	return att.AttributeKey(name)
 }
 
 // GoTypeRef returns the Go code that refers to the Go type which matches the given data type
@@ -211,6 +264,9 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 			GoTypeRef(actual.ElemType.Type, actual.ElemType.AllRequired(), tabs+1, private),
 		)
 	case *design.UserTypeDefinition:
+		if _, pkg := TypePackage(actual); pkg != "" && !private {
+			return pkg + "." + Goify(actual.TypeName, true)
+		}
 		return Goify(actual.TypeName, !private)
 	case *design.MediaTypeDefinition:
 		if actual.IsError() {
@@ -222,6 +278,30 @@ func GoTypeName(t design.DataType, required []string, tabs int, private bool) st
 	}
 }
 
+// TypePackage returns the import path and the name of the Go package that defines the given user
+// type as set with the "struct:pkg:path" metadata, empty strings if the type is generated. The
+// package name is the optional second metadata value and defaults to the last element of the
+// import path. The metadata only applies to the types defined with Type, not to the payload types
+// built from them.
+func TypePackage(ut *design.UserTypeDefinition) (path, name string) {
+	if ut == nil || ut.AttributeDefinition == nil || design.Design == nil {
+		return "", ""
+	}
+	if t, ok := design.Design.Types[ut.TypeName]; !ok || t != ut {
+		return "", ""
+	}
+	vals, ok := ut.Metadata["struct:pkg:path"]
+	if !ok || len(vals) == 0 || vals[0] == "" {
+		return "", ""
+	}
+	path = vals[0]
+	if len(vals) > 1 {
+		return path, vals[1]
+	}
+	elems := strings.Split(path, "/")
+	return path, elems[len(elems)-1]
+}
+
 // GoNativeType returns the Go built-in type from which instances of t can be initialized.
 func GoNativeType(t design.DataType) string {
 	switch actual := t.(type) {
@@ -441,6 +521,7 @@ var Reserved = map[string]bool{
 	"byte":       true,
 	"complex128": true,
 	"complex64":  true,
+	"error":      true,
 	"float32":    true,
 	"float64":    true,
 	"int":        true,
@@ -516,7 +597,7 @@ func GoTypeTransform(source, target *design.UserTypeDefinition, targetPkg, funcN
 		if !target.IsObject() {
 			return "", fmt.Errorf("source is an object but target type is %s", target.Type.Name())
 		}
-		impl, err = transformObject(source.ToObject(), target.ToObject(), targetPkg, target.TypeName, "source", "target", 1)
+		impl, err = transformObject(source.ToObject(), target.AttributeDefinition, targetPkg, target.TypeName, "source", "target", 1)
 	case source.IsArray():
 		if !target.IsArray() {
 			return "", fmt.Errorf("source is an array but target type is %s", target.Type.Name())
@@ -591,13 +672,14 @@ func transformAttribute(source, target *design.AttributeDefinition, targetPkg, s
 	case source.Type.IsHash():
 		return transformHash(source.Type.ToHash(), target.Type.ToHash(), targetPkg, sctx, tctx, depth)
 	case source.Type.IsObject():
-		return transformObject(source.Type.ToObject(), target.Type.ToObject(), targetPkg, typeName(target), sctx, tctx, depth)
+		return transformObject(source.Type.ToObject(), target, targetPkg, typeName(target), sctx, tctx, depth)
 	default:
 		return fmt.Sprintf("%s%s = %s\n", Tabs(depth), tctx, sctx), nil
 	}
 }
 
-func transformObject(source, target design.Object, targetPkg, targetType, sctx, tctx string, depth int) (string, error) {
+func transformObject(source design.Object, targetDef *design.AttributeDefinition, targetPkg, targetType, sctx, tctx string, depth int) (string, error) {
+	target := targetDef.Type.ToObject()
 	attributeMap, err := computeMapping(source, target, sctx, tctx)
 	if err != nil {
 		return "", err
@@ -619,6 +701,7 @@ func transformObject(source, target design.Object, targetPkg, targetType, sctx,
 		"AttributeMap": attributeMap,
 		"Source":       source,
 		"Target":       target,
+		"TargetDef":    targetDef,
 		"TargetPkg":    targetPkg,
 		"TargetType":   targetType,
 		"SourceCtx":    sctx,
@@ -701,6 +784,22 @@ func computeMapping(source, target design.Object, sctx, tctx string) (map[string
 	return attributeMap, nil
 }
 
+// convertEnum returns the Go code that converts expr to the enum type generated for the field
+// with the given name of parent if any, expr otherwise.
+func convertEnum(parent *design.AttributeDefinition, name, pkg, expr string) string {
+	enum := GoEnumTypeName(parent.Type.ToObject()[name])
+	if enum == "" {
+		return expr
+	}
+	if pkg != "" && !strings.Contains(enum, ".") {
+		enum = pkg + "." + enum
+	}
+	if parent.IsPrimitivePointer(name) {
+		return fmt.Sprintf("(*%s)(%s)", enum, expr)
+	}
+	return fmt.Sprintf("%s(%s)", enum, expr)
+}
+
 // toSlice returns Go code that represents the given slice.
 func toSlice(val []interface{}) string {
 	elems := make([]string, len(val))
@@ -727,12 +826,12 @@ const transformTmpl = `func {{ .Name }}(source {{ gotyperef .Source nil 0 false
 
 const transformObjectTmpl = `{{ tabs .Depth }}{{ .TargetCtx }} = new({{ if .TargetPkg }}{{ .TargetPkg }}.{{ end }}{{ if .TargetType }}{{ .TargetType }}{{ else }}{{ gotyperef .Target.Type .Target.AllRequired 1 false }}{{ end }})
 {{ range $source, $target := .AttributeMap }}{{/*
-*/}}{{ $sourceAtt := index $.Source $source }}{{ $targetAtt := index $.Target $target }}{{/*
+*/}}{{ $sourceAtt := index $.Source $source }}{{ $targetAtt := index $.Target $target }}{{ $targetName := $target }}{{/*
 */}}{{ $source := goify $source true }}{{ $target := goify $target true }}{{/*
 */}}{{     if $sourceAtt.Type.IsArray }}{{ transformArray  $sourceAtt.Type.ToArray  $targetAtt.Type.ToArray  $.TargetPkg (printf "%s.%s" $.SourceCtx $source) (printf "%s.%s" $.TargetCtx $target) $.Depth }}{{/*
 */}}{{ else if $sourceAtt.Type.IsHash }}{{  transformHash   $sourceAtt.Type.ToHash   $targetAtt.Type.ToHash   $.TargetPkg (printf "%s.%s" $.SourceCtx $source) (printf "%s.%s" $.TargetCtx $target) $.Depth }}{{/*
-*/}}{{ else if $sourceAtt.Type.IsObject }}{{ transformObject $sourceAtt.Type.ToObject $targetAtt.Type.ToObject $.TargetPkg (typeName $targetAtt) (printf "%s.%s" $.SourceCtx $source) (printf "%s.%s" $.TargetCtx $target) $.Depth }}{{/*
-*/}}{{ else }}{{ tabs $.Depth }}{{ $.TargetCtx }}.{{ $target }} = {{ $.SourceCtx }}.{{ $source }}
+*/}}{{ else if $sourceAtt.Type.IsObject }}{{ transformObject $sourceAtt.Type.ToObject $targetAtt $.TargetPkg (typeName $targetAtt) (printf "%s.%s" $.SourceCtx $source) (printf "%s.%s" $.TargetCtx $target) $.Depth }}{{/*
+*/}}{{ else }}{{ tabs $.Depth }}{{ $.TargetCtx }}.{{ $target }} = {{ convertEnum $.TargetDef $targetName $.TargetPkg (printf "%s.%s" $.SourceCtx $source) }}
 {{ end }}{{ end }}`
 
 const transformArrayTmpl = `{{ tabs .Depth }}{{ .TargetCtx}} = make([]{{ gotyperef .Target.ElemType.Type nil 0 false }}, len({{ .SourceCtx }}))
@@ -176,6 +176,117 @@ var _ = Describe("code generation", func() {
 	})
 
 	Describe("GoTypeDef", func() {
+		Context("given attributes defined with the DSL", func() {
+			var ut *UserTypeDefinition
+
+			BeforeEach(func() {
+				ut = &UserTypeDefinition{
+					TypeName: "Bottle",
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"sparkly": &AttributeDefinition{Type: Boolean, Position: 1},
+							"vintage": &AttributeDefinition{Type: Integer, Position: 2},
+							"name":    &AttributeDefinition{Type: String, Position: 3},
+							"extra":   &AttributeDefinition{Type: String},
+						},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"name", "sparkly", "vintage"}},
+					},
+				}
+			})
+
+			It("generates the fields in design order", func() {
+				Ω(codegen.GoTypeDef(ut, 0, false, false)).Should(Equal("struct {\n" +
+					"	Extra *string\n" +
+					"	Sparkly bool\n" +
+					"	Vintage int\n" +
+					"	Name string\n" +
+					"}"))
+			})
+
+			It("sorts the fields by name", func() {
+				ut.Metadata = dslengine.MetadataDefinition{"struct:field:order": {"name"}}
+				Ω(codegen.GoTypeDef(ut, 0, false, false)).Should(Equal("struct {\n" +
+					"	Extra *string\n" +
+					"	Name string\n" +
+					"	Sparkly bool\n" +
+					"	Vintage int\n" +
+					"}"))
+			})
+
+			It("sorts the fields by alignment", func() {
+				ut.Metadata = dslengine.MetadataDefinition{"struct:field:order": {"alignment"}}
+				Ω(codegen.GoTypeDef(ut, 0, false, false)).Should(Equal("struct {\n" +
+					"	Extra *string\n" +
+					"	Vintage int\n" +
+					"	Name string\n" +
+					"	Sparkly bool\n" +
+					"}"))
+			})
+		})
+
+		Context("given read-only and write-only attributes", func() {
+			var ut *UserTypeDefinition
+
+			BeforeEach(func() {
+				ut = &UserTypeDefinition{
+					TypeName: "Account",
+					AttributeDefinition: &AttributeDefinition{Type: Object{
+						"id":       &AttributeDefinition{Type: Integer, ReadOnly: true},
+						"password": &AttributeDefinition{Type: String, WriteOnly: true},
+					}},
+				}
+			})
+
+			It("ignores the read-only fields of private structs", func() {
+				expected := "struct {\n" +
+					"	ID *int `form:\"-\" json:\"-\" xml:\"-\"`\n" +
+					"	Password *string `form:\"password,omitempty\" json:\"password,omitempty\" xml:\"password,omitempty\"`\n" +
+					"}"
+				Ω(codegen.GoTypeDef(ut, 0, true, true)).Should(Equal(expected))
+			})
+
+			It("generates value fields for the required primitives with the value metadata", func() {
+				vt := &UserTypeDefinition{
+					TypeName: "Line",
+					AttributeDefinition: &AttributeDefinition{
+						Type: Object{
+							"at":    &AttributeDefinition{Type: DateTime},
+							"count": &AttributeDefinition{Type: Integer},
+							"note":  &AttributeDefinition{Type: String},
+							"sku":   &AttributeDefinition{Type: String},
+						},
+						Metadata:   dslengine.MetadataDefinition{"struct:field:value": nil},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"at", "count", "sku"}},
+					},
+				}
+				expected := "struct {\n" +
+					"	At goa.TimeField `form:\"at,omitempty\" json:\"at,omitempty\" xml:\"at,omitempty\"`\n" +
+					"	Count goa.IntField `form:\"count,omitempty\" json:\"count,omitempty\" xml:\"count,omitempty\"`\n" +
+					"	Note *string `form:\"note,omitempty\" json:\"note,omitempty\" xml:\"note,omitempty\"`\n" +
+					"	Sku goa.StringField `form:\"sku,omitempty\" json:\"sku,omitempty\" xml:\"sku,omitempty\"`\n" +
+					"}"
+				Ω(codegen.GoTypeDef(vt, 0, true, true)).Should(Equal(expected))
+				Ω(codegen.GoTypeDef(vt, 0, true, false)).ShouldNot(ContainSubstring("goa."))
+			})
+
+			It("keeps all the fields of public structs", func() {
+				expected := "struct {\n" +
+					"	ID *int `form:\"id,omitempty\" json:\"id,omitempty\" xml:\"id,omitempty\"`\n" +
+					"	Password *string `form:\"password,omitempty\" json:\"password,omitempty\" xml:\"password,omitempty\"`\n" +
+					"}"
+				Ω(codegen.GoTypeDef(ut, 0, true, false)).Should(Equal(expected))
+			})
+
+			It("ignores the write-only fields of media types", func() {
+				mt := &MediaTypeDefinition{UserTypeDefinition: ut, Identifier: "application/vnd.account"}
+				expected := "struct {\n" +
+					"	ID *int `form:\"id,omitempty\" json:\"id,omitempty\" xml:\"id,omitempty\"`\n" +
+					"	Password *string `form:\"-\" json:\"-\" xml:\"-\"`\n" +
+					"}"
+				Ω(codegen.GoTypeDef(mt, 0, true, false)).Should(Equal(expected))
+			})
+		})
+
 		Context("given an attribute definition with fields", func() {
 			var att *AttributeDefinition
 			var object Object
@@ -259,6 +370,26 @@ var _ = Describe("code generation", func() {
 					})
 				})
 
+				Context("using extra struct tags metadata", func() {
+					BeforeEach(func() {
+						object["foo"].Metadata = dslengine.MetadataDefinition{
+							"struct:tag:extra:db":   []string{"foo_id"},
+							"struct:tag:extra:bson": []string{"foo", "omitempty"},
+						}
+					})
+
+					It("adds the struct tags to the default tags", func() {
+						expected := "struct {\n" +
+							"	Bar *string `form:\"bar,omitempty\" json:\"bar,omitempty\" xml:\"bar,omitempty\"`\n" +
+							"	Baz *time.Time `form:\"baz,omitempty\" json:\"baz,omitempty\" xml:\"baz,omitempty\"`\n" +
+							"	Foo *int `form:\"foo,omitempty\" json:\"foo,omitempty\" xml:\"foo,omitempty\" bson:\"foo,omitempty\" db:\"foo_id\"`\n" +
+							"	Qux *uuid.UUID `form:\"qux,omitempty\" json:\"qux,omitempty\" xml:\"qux,omitempty\"`\n" +
+							"	Quz interface{} `form:\"quz,omitempty\" json:\"quz,omitempty\" xml:\"quz,omitempty\"`\n" +
+							"}"
+						Ω(st).Should(Equal(expected))
+					})
+				})
+
 				Context("using struct field type metadata", func() {
 					BeforeEach(func() {
 						object["foo"].Metadata = dslengine.MetadataDefinition{
@@ -625,6 +756,45 @@ var _ = Describe("GoTypeTransform", func() {
 		})
 	})
 
+	Context("transforming objects with enum attributes", func() {
+		BeforeEach(func() {
+			source = Type("Source", func() {
+				Attribute("color", String, func() {
+					Enum("red", "white")
+				})
+				Attribute("level", Integer, func() {
+					Enum(1, 2)
+				})
+				Required("level")
+			})
+			target = Type("Target", func() {
+				Attribute("color", String, func() {
+					Enum("red", "white")
+				})
+				Attribute("level", Integer, func() {
+					Enum(1, 2)
+				})
+				Required("level")
+			})
+			targetPkg = "app"
+			funcName = "Transform"
+		})
+
+		AfterEach(func() {
+			targetPkg = ""
+		})
+
+		It("converts the values to the target enum types", func() {
+			Ω(transform).Should(Equal(`func Transform(source *Source) (target *app.Target) {
+	target = new(app.Target)
+	target.Color = (*app.TargetColor)(source.Color)
+	target.Level = app.TargetLevel(source.Level)
+	return
+}
+`))
+		})
+	})
+
 	Context("transforming objects with array attributes", func() {
 		const attName = "att"
 		BeforeEach(func() {
@@ -775,3 +945,66 @@ var _ = Describe("GoTypeDesc", func() {
 		})
 	})
 })
+
+var _ = Describe("TypePackage", func() {
+	var ut *UserTypeDefinition
+	var metadata dslengine.MetadataDefinition
+	var api *APIDefinition
+
+	BeforeEach(func() {
+		metadata = nil
+		api = Design
+	})
+
+	AfterEach(func() {
+		Design = api
+	})
+
+	JustBeforeEach(func() {
+		ut = &UserTypeDefinition{
+			TypeName: "Money",
+			AttributeDefinition: &AttributeDefinition{
+				Type:     Object{"amount": &AttributeDefinition{Type: Integer}},
+				Metadata: metadata,
+			},
+		}
+		Design = &APIDefinition{Types: map[string]*UserTypeDefinition{"Money": ut}}
+	})
+
+	It("generates the type", func() {
+		path, name := codegen.TypePackage(ut)
+		Ω(path).Should(BeEmpty())
+		Ω(name).Should(BeEmpty())
+		Ω(codegen.GoTypeRef(ut, nil, 0, false)).Should(Equal("*Money"))
+	})
+
+	Context("with a package path", func() {
+		BeforeEach(func() {
+			metadata = dslengine.MetadataDefinition{"struct:pkg:path": {"github.com/acme/types"}}
+		})
+
+		It("references the type from the package", func() {
+			path, name := codegen.TypePackage(ut)
+			Ω(path).Should(Equal("github.com/acme/types"))
+			Ω(name).Should(Equal("types"))
+			Ω(codegen.GoTypeRef(ut, nil, 0, false)).Should(Equal("*types.Money"))
+			Ω(codegen.GoTypeRef(ut, nil, 0, true)).Should(Equal("*money"))
+		})
+
+		It("imports the package", func() {
+			imports := codegen.AttributeImports(&AttributeDefinition{Type: ut}, nil, nil)
+			Ω(imports).Should(HaveLen(1))
+			Ω(imports[0].Code()).Should(Equal(`types "github.com/acme/types"`))
+		})
+
+		Context("and a package name", func() {
+			BeforeEach(func() {
+				metadata["struct:pkg:path"] = append(metadata["struct:pkg:path"], "shared")
+			})
+
+			It("uses the package name", func() {
+				Ω(codegen.GoTypeRef(ut, nil, 0, false)).Should(Equal("*shared.Money"))
+			})
+		})
+	})
+})
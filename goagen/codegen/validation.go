@@ -16,8 +16,10 @@ var (
 	formatValT   *template.Template
 	patternValT  *template.Template
 	minMaxValT   *template.Template
+	multipleValT *template.Template
 	lengthValT   *template.Template
 	requiredValT *template.Template
+	groupValT    *template.Template
 )
 
 //  init instantiates the templates.
@@ -43,12 +45,18 @@ func init() {
 	if minMaxValT, err = template.New("minMax").Funcs(fm).Parse(minMaxValTmpl); err != nil {
 		panic(err)
 	}
+	if multipleValT, err = template.New("multiple").Funcs(fm).Parse(multipleValTmpl); err != nil {
+		panic(err)
+	}
 	if lengthValT, err = template.New("length").Funcs(fm).Parse(lengthValTmpl); err != nil {
 		panic(err)
 	}
 	if requiredValT, err = template.New("required").Funcs(fm).Parse(requiredValTmpl); err != nil {
 		panic(err)
 	}
+	if groupValT, err = template.New("group").Funcs(fm).Parse(groupValTmpl); err != nil {
+		panic(err)
+	}
 }
 
 // Validator is the code generator for the 'Validate' type methods.
@@ -114,16 +122,16 @@ func (v *Validator) arrayValCode(att *design.AttributeDefinition, nonzero, requi
 		buf.WriteString(validation)
 		first = false
 	}
-	val := v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
+	val := v.Code(a.ElemType, true, false, false, "e", context+"[*]", depth+2, false)
 	if val != "" {
 		switch a.ElemType.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			val = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
+				"depth":  depth + 3,
 				"target": "e",
 			})
-			val = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), val, Tabs(depth+1))
+			val = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+2), val, Tabs(depth+2))
 		}
 		data := map[string]interface{}{
 			"elemType":   a.ElemType,
@@ -132,6 +140,7 @@ func (v *Validator) arrayValCode(att *design.AttributeDefinition, nonzero, requi
 			"depth":      1,
 			"private":    private,
 			"validation": val,
+			"pointer":    pointerArgs(context),
 		}
 		validation = RunTemplate(v.arrayValT, data)
 		if !first {
@@ -159,28 +168,28 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 		buf.WriteString(validation)
 		first = false
 	}
-	keyVal := v.Code(h.KeyType, true, false, false, "k", context+"[*]", depth+1, false)
+	keyVal := v.Code(h.KeyType, true, false, false, "k", context+"[*]", depth+2, false)
 	if keyVal != "" {
 		switch h.KeyType.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			keyVal = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
+				"depth":  depth + 3,
 				"target": "k",
 			})
-			keyVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), keyVal, Tabs(depth+1))
+			keyVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+2), keyVal, Tabs(depth+2))
 		}
 	}
-	elemVal := v.Code(h.ElemType, true, false, false, "e", context+"[*]", depth+1, false)
+	elemVal := v.Code(h.ElemType, true, false, false, "e", context+"[*]", depth+2, false)
 	if elemVal != "" {
 		switch h.ElemType.Type.(type) {
 		case *design.UserTypeDefinition, *design.MediaTypeDefinition:
 			// For user and media types, call the Validate method
 			elemVal = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth + 2,
+				"depth":  depth + 3,
 				"target": "e",
 			})
-			elemVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+1), elemVal, Tabs(depth+1))
+			elemVal = fmt.Sprintf("%sif e != nil {\n%s\n%s}", Tabs(depth+2), elemVal, Tabs(depth+2))
 		}
 	}
 	if keyVal != "" || elemVal != "" {
@@ -189,6 +198,7 @@ func (v *Validator) hashValCode(att *design.AttributeDefinition, nonzero, requir
 			"target":         target,
 			"keyValidation":  keyVal,
 			"elemValidation": elemVal,
+			"pointer":        pointerArgs(context),
 		}
 		validation = RunTemplate(v.hashValT, data)
 		if !first {
@@ -256,6 +266,22 @@ func (v *Validator) recurse(att *design.AttributeDefinition, nonzero, required,
 }
 
 func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, target, context string, depth int, private bool) string {
+	if private && catt.ReadOnly {
+		// Read-only fields are not decoded from requests.
+		return ""
+	}
+	if _, ok := catt.Metadata["struct:field:type"]; ok {
+		// Skip validation generation for attributes with custom types
+		return ""
+	}
+	if private && att.IsValueField(n) {
+		field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+		validation := ValidationChecker(catt, false, true, false, field+".Value", fmt.Sprintf("%s.%s", context, catt.AttributeKey(n)), depth+1, false)
+		if validation == "" {
+			return ""
+		}
+		return fmt.Sprintf("%sif %s.Set {\n%s\n%s}", Tabs(depth), field, validation, Tabs(depth))
+	}
 	var validation string
 	if ds, ok := catt.Type.(design.DataStructure); ok {
 		// We need to check empirically whether there are validations to be
@@ -291,8 +317,9 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 		})
 		if hasValidations {
 			validation = RunTemplate(v.userValT, map[string]interface{}{
-				"depth":  depth,
-				"target": fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
+				"depth":   depth,
+				"target":  fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
+				"pointer": pointerArgs(context + "." + n),
 			})
 		}
 	} else {
@@ -306,7 +333,7 @@ func (v *Validator) recurseAttribute(att, catt *design.AttributeDefinition, n, t
 			att.IsRequired(n),
 			att.HasDefaultValue(n),
 			fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true)),
-			fmt.Sprintf("%s.%s", context, n),
+			fmt.Sprintf("%s.%s", context, catt.AttributeKey(n)),
 			dp,
 			private,
 		).String()
@@ -361,6 +388,11 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			res = append(res, val)
 		}
 	}
+	if att.Type.Kind() == design.StringKind && GoEnumTypeName(att) != "" {
+		// The remaining validations call functions that accept a string.
+		data["targetVal"] = fmt.Sprintf("string(%s)", data["targetVal"])
+		data["nonzero"] = false
+	}
 	if format := validation.Format; format != "" {
 		data["format"] = format
 		if val := RunTemplate(formatValT, data); val != "" {
@@ -373,30 +405,43 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 			res = append(res, val)
 		}
 	}
-	if min := validation.Minimum; min != nil {
+	if min, exclusive := validation.LowerBound(); min != nil {
 		if att.Type == design.Integer {
 			data["min"] = renderInteger(*min)
 		} else {
 			data["min"] = fmt.Sprintf("%f", *min)
 		}
 		data["isMin"] = true
+		data["exclusive"] = exclusive
 		delete(data, "max")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
 		}
 	}
-	if max := validation.Maximum; max != nil {
+	if max, exclusive := validation.UpperBound(); max != nil {
 		if att.Type == design.Integer {
 			data["max"] = renderInteger(*max)
 		} else {
 			data["max"] = fmt.Sprintf("%f", *max)
 		}
 		data["isMin"] = false
+		data["exclusive"] = exclusive
 		delete(data, "min")
 		if val := RunTemplate(minMaxValT, data); val != "" {
 			res = append(res, val)
 		}
 	}
+	if multiple := validation.MultipleOf; multiple != nil {
+		data["modulo"] = att.Type == design.Integer && *multiple == math.Trunc(*multiple)
+		if data["modulo"].(bool) {
+			data["multiple"] = renderInteger(*multiple)
+		} else {
+			data["multiple"] = fmt.Sprintf("%f", *multiple)
+		}
+		if val := RunTemplate(multipleValT, data); val != "" {
+			res = append(res, val)
+		}
+	}
 	if minLength := validation.MinLength; minLength != nil {
 		data["minLength"] = minLength
 		data["isMinLength"] = true
@@ -415,14 +460,118 @@ func validationsCode(att *design.AttributeDefinition, data map[string]interface{
 	}
 	if required := validation.Required; len(required) > 0 {
 		var val string
-		for i, r := range required {
-			if i > 0 {
+		o := att.Type.ToObject()
+		for _, r := range required {
+			if catt, ok := o[r]; ok && catt.ReadOnly && data["private"].(bool) {
+				continue
+			}
+			if val != "" {
 				val += "\n"
 			}
 			data["required"] = r
 			val += RunTemplate(requiredValT, data)
 		}
-		res = append(res, val)
+		if val != "" {
+			res = append(res, val)
+		}
+	}
+	if validation.HasFieldGroups() {
+		res = append(res, fieldGroupsCode(att, data)...)
+	}
+	return
+}
+
+// fieldGroupsCode produces the code that runs the RequiredTogether, MutuallyExclusive and
+// AtLeastOneOf validations of the given object attribute.
+func fieldGroupsCode(att *design.AttributeDefinition, data map[string]interface{}) (res []string) {
+	o := att.Type.ToObject()
+	if o == nil {
+		return nil
+	}
+	target, private := data["target"].(string), data["private"].(bool)
+
+	// fields returns the conditions that test whether the fields of the group are set and
+	// missing and the number of fields that cannot be missing, i.e. non-pointer primitives.
+	fields := func(group []string) (set, missing []string, always int) {
+		for _, n := range group {
+			catt, ok := o[n]
+			if !ok {
+				continue
+			}
+			if catt.Type.IsPrimitive() && !att.IsInterface(n) && !private && !att.IsPrimitivePointer(n) {
+				always++
+				continue
+			}
+			field := fmt.Sprintf("%s.%s", target, GoifyAtt(catt, n, true))
+			if private && att.IsValueField(n) {
+				set = append(set, field+".Set")
+				missing = append(missing, "!"+field+".Set")
+				continue
+			}
+			set = append(set, field+" != nil")
+			missing = append(missing, field+" == nil")
+		}
+		return
+	}
+	run := func(cond, fn string, group []string) {
+		if cond == "" {
+			return
+		}
+		data["condition"] = cond
+		data["error"] = fn
+		keys := make([]string, len(group))
+		for i, n := range group {
+			keys[i] = n
+			if catt, ok := o[n]; ok {
+				keys[i] = catt.AttributeKey(n)
+			}
+		}
+		data["names"] = fmt.Sprintf("%#v", keys)
+		res = append(res, RunTemplate(groupValT, data))
+	}
+
+	for _, g := range att.Validation.RequiredTogether {
+		set, missing, always := fields(g)
+		var cond string
+		switch {
+		case len(set) == 0:
+		case always > 0:
+			cond = strings.Join(missing, " || ")
+		default:
+			cond = fmt.Sprintf("(%s) && (%s)", strings.Join(set, " || "), strings.Join(missing, " || "))
+		}
+		run(cond, "RequiredTogetherError", g)
+	}
+	for _, g := range att.Validation.MutuallyExclusive {
+		set, _, always := fields(g)
+		var cond string
+		switch {
+		case len(set) == 0 || always > 1:
+		case always == 1:
+			cond = strings.Join(set, " || ")
+		default:
+			var pairs []string
+			for i := range set {
+				for j := i + 1; j < len(set); j++ {
+					pairs = append(pairs, set[i]+" && "+set[j])
+				}
+			}
+			if len(pairs) > 1 {
+				for i, p := range pairs {
+					pairs[i] = "(" + p + ")"
+				}
+			}
+			cond = strings.Join(pairs, " || ")
+		}
+		run(cond, "MutuallyExclusiveError", g)
+	}
+	for _, g := range att.Validation.AtLeastOneOf {
+		_, missing, always := fields(g)
+		var cond string
+		if always == 0 {
+			cond = strings.Join(missing, " && ")
+		}
+		run(cond, "AtLeastOneOfError", g)
 	}
 	return
 }
@@ -439,6 +588,27 @@ func renderInteger(f float64) string {
 	return fmt.Sprintf("%d", int64(f))
 }
 
+// pointerArgs returns the NestError arguments that prefix the JSON pointers of the errors of the
+// nested value described by context, e.g. `"address"` for "raw.address". The pointers of the
+// errors produced inside array and map elements are relative to the innermost element, see
+// goa.NestError.
+func pointerArgs(context string) string {
+	if i := strings.LastIndex(context, "[*]"); i >= 0 {
+		context = context[i+3:]
+	} else if i := strings.Index(context, "."); i >= 0 {
+		context = context[i:]
+	} else {
+		return ""
+	}
+	var args []string
+	for _, token := range strings.Split(context, ".") {
+		if token != "" {
+			args = append(args, fmt.Sprintf("%q", token))
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
 // oneof produces code that compares target with each element of vals and ORs
 // the result, e.g. "target == 1 || target == 2".
 func oneof(target string, vals []interface{}) string {
@@ -474,23 +644,40 @@ func constant(formatName string) string {
 		return "goa.FormatRegexp"
 	case "rfc1123":
 		return "goa.FormatRFC1123"
+	case "decimal":
+		return "goa.FormatDecimal"
 	}
 	panic("unknown format") // bug
 }
 
 const (
-	arrayValTmpl = `{{ tabs .depth }}for _, e := range {{ .target }} {
+	arrayValTmpl = `{{ tabs .depth }}for i, e := range {{ .target }} {
+{{ tabs .depth }}	if err2 := func() (err error) {
 {{ .validation }}
+{{ tabs .depth }}		return
+{{ tabs .depth }}	}(); err2 != nil {
+{{ tabs .depth }}		err = goa.MergeErrors(err, goa.NestError(err2, {{ with .pointer }}{{ . }}, {{ end }}i))
+{{ tabs .depth }}	}
 {{ tabs .depth }}}`
 
-	hashValTmpl = `{{ tabs .depth }}for {{ if .keyValidation }}k{{ else }}_{{ end }}, {{ if .elemValidation }}e{{ else }}_{{ end }} := range {{ .target }} {
+	hashValTmpl = `{{ tabs .depth }}for k, {{ if .elemValidation }}e{{ else }}_{{ end }} := range {{ .target }} {
 {{- if .keyValidation }}
-{{ .keyValidation }}{{ end }}{{ if .elemValidation }}
-{{ .elemValidation }}{{ end }}
+{{ tabs .depth }}	if err2 := func() (err error) {
+{{ .keyValidation }}
+{{ tabs .depth }}		return
+{{ tabs .depth }}	}(); err2 != nil {
+{{ tabs .depth }}		err = goa.MergeErrors(err, goa.NestError(err2, {{ with .pointer }}{{ . }}, {{ end }}k))
+{{ tabs .depth }}	}{{ end }}{{ if .elemValidation }}
+{{ tabs .depth }}	if err2 := func() (err error) {
+{{ .elemValidation }}
+{{ tabs .depth }}		return
+{{ tabs .depth }}	}(); err2 != nil {
+{{ tabs .depth }}		err = goa.MergeErrors(err, goa.NestError(err2, {{ with .pointer }}{{ . }}, {{ end }}k))
+{{ tabs .depth }}	}{{ end }}
 {{ tabs .depth }}}`
 
 	userValTmpl = `{{ tabs .depth }}if err2 := {{ .target }}.Validate(); err2 != nil {
-{{ tabs .depth }}	err = goa.MergeErrors(err, err2)
+{{ tabs .depth }}	err = goa.MergeErrors(err, {{ if .pointer }}goa.NestError(err2, {{ .pointer }}){{ else }}err2{{ end }})
 {{ tabs .depth }}}`
 
 	enumValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
@@ -516,8 +703,15 @@ const (
 
 	minMaxValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
 */}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
-{{ end }}{{ tabs .depth }}	if {{ .targetVal }} {{ if .isMin }}<{{ else }}>{{ end }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }} {
-{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidRangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
+{{ end }}{{ tabs .depth }}	if {{ .targetVal }} {{ if .isMin }}<{{ else }}>{{ end }}{{ if .exclusive }}={{ end }} {{ if .isMin }}{{ .min }}{{ else }}{{ .max }}{{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.Invalid{{ if .exclusive }}Exclusive{{ end }}RangeError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ if .isMin }}{{ .min }}, true{{ else }}{{ .max }}, false{{ end }}))
+{{ if .isPointer }}{{ tabs $depth }}}
+{{ end }}{{ tabs .depth }}}`
+
+	multipleValTmpl = `{{ $depth := or (and .isPointer (add .depth 1)) .depth }}{{/*
+*/}}{{ if .isPointer }}{{ tabs .depth }}if {{ .target }} != nil {
+{{ end }}{{ tabs .depth }}	if {{ if .modulo }}{{ .targetVal }}%{{ .multiple }} != 0{{ else }}!goa.ValidateMultipleOf(float64({{ .targetVal }}), {{ .multiple }}){{ end }} {
+{{ tabs $depth }}	err = goa.MergeErrors(err, goa.InvalidMultipleOfError(` + "`" + `{{ .context }}` + "`" + `, {{ .targetVal }}, {{ .multiple }}))
 {{ if .isPointer }}{{ tabs $depth }}}
 {{ end }}{{ tabs .depth }}}`
 
@@ -530,9 +724,15 @@ const (
 {{ end }}{{ tabs .depth }}}`
 
 	requiredValTmpl = `{{ $att := index $.attribute.Type.ToObject .required }}{{/*
-*/}}{{ if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{  .required  }}"))
+*/}}{{ if and $.private ($.attribute.IsValueField .required) }}{{ tabs $.depth }}if !{{ $.target }}.{{ goifyAtt $att .required true }}.Set {
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ $att.AttributeKey .required }}"))
+{{ tabs $.depth }}}{{ else if and (not $.private) (eq $att.Type.Kind 4) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == "" {
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ $att.AttributeKey .required }}"))
 {{ tabs $.depth }}}{{ else if or $.private (not $att.Type.IsPrimitive) }}{{ tabs $.depth }}if {{ $.target }}.{{ goifyAtt $att .required true }} == nil {
-{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ .required }}"))
+{{ tabs $.depth }}	err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`" + `{{ $.context }}` + "`" + `, "{{ $att.AttributeKey .required }}"))
 {{ tabs $.depth }}}{{ end }}`
+
+	groupValTmpl = `{{ tabs .depth }}if {{ .condition }} {
+{{ tabs .depth }}	err = goa.MergeErrors(err, goa.{{ .error }}(` + "`" + `{{ .context }}` + "`" + `, {{ .names }}))
+{{ tabs .depth }}}`
 )
@@ -17,6 +17,40 @@ var _ = Describe("validation code generation", func() {
 	})
 
 	Describe("ValidationChecker", func() {
+		Context("given a private struct with read-only attributes", func() {
+			It("does not validate the read-only fields", func() {
+				min := 3
+				att := &design.AttributeDefinition{
+					Type: design.Object{
+						"id":   &design.AttributeDefinition{Type: design.String, ReadOnly: true, Validation: &dslengine.ValidationDefinition{MinLength: &min}},
+						"name": &design.AttributeDefinition{Type: design.String},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"id", "name"}},
+				}
+				code := codegen.NewValidator().Code(att, false, false, false, "val", "context", 1, true)
+				Ω(code).Should(Equal(readOnlyValCode))
+			})
+		})
+
+		Context("given a private struct with value fields", func() {
+			It("checks the presence of the values", func() {
+				min := 3
+				att := &design.AttributeDefinition{
+					Type: design.Object{
+						"name": &design.AttributeDefinition{Type: design.String, Validation: &dslengine.ValidationDefinition{MinLength: &min}},
+						"note": &design.AttributeDefinition{Type: design.String},
+					},
+					Metadata: dslengine.MetadataDefinition{"struct:field:value": nil},
+					Validation: &dslengine.ValidationDefinition{
+						Required:         []string{"name"},
+						RequiredTogether: [][]string{{"name", "note"}},
+					},
+				}
+				code := codegen.NewValidator().Code(att, false, false, false, "val", "context", 1, true)
+				Ω(code).Should(Equal(valueFieldsValCode))
+			})
+		})
+
 		Context("given an attribute definition and validations", func() {
 			var att *design.AttributeDefinition
 			var attType design.DataType
@@ -46,6 +80,27 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of field groups", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"start": &design.AttributeDefinition{Type: design.String},
+						"end":   &design.AttributeDefinition{Type: design.String},
+						"id":    &design.AttributeDefinition{Type: design.Integer},
+						"tags":  &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+					}
+					validation = &dslengine.ValidationDefinition{
+						Required:          []string{"id"},
+						RequiredTogether:  [][]string{{"start", "end"}, {"id", "tags"}},
+						MutuallyExclusive: [][]string{{"start", "end", "tags"}},
+						AtLeastOneOf:      [][]string{{"start", "tags"}, {"id", "end"}},
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(fieldGroupsValCode))
+				})
+			})
+
 			Context("of pattern", func() {
 				BeforeEach(func() {
 					attType = design.String
@@ -101,6 +156,49 @@ var _ = Describe("validation code generation", func() {
 				})
 			})
 
+			Context("of exclusive min value 0 and min value -1", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					min, excl := -1.0, 0.0
+					validation = &dslengine.ValidationDefinition{
+						Minimum:          &min,
+						ExclusiveMinimum: &excl,
+					}
+				})
+
+				It("produces the validation go code of the most restrictive bound", func() {
+					Ω(code).Should(Equal(exclusiveMinValCode))
+				})
+			})
+
+			Context("of integer multiple of 3", func() {
+				BeforeEach(func() {
+					attType = design.Integer
+					m := 3.0
+					validation = &dslengine.ValidationDefinition{
+						MultipleOf: &m,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(integerMultipleValCode))
+				})
+			})
+
+			Context("of number multiple of 0.5", func() {
+				BeforeEach(func() {
+					attType = design.Number
+					m := 0.5
+					validation = &dslengine.ValidationDefinition{
+						MultipleOf: &m,
+					}
+				})
+
+				It("produces the validation go code", func() {
+					Ω(code).Should(Equal(numberMultipleValCode))
+				})
+			})
+
 			Context("of array min length 1", func() {
 				BeforeEach(func() {
 					attType = &design.Array{
@@ -349,6 +447,23 @@ var _ = Describe("validation code generation", func() {
 					Ω(code).Should(BeEmpty())
 				})
 			})
+
+			Context("with a custom type metadata on a child attribute", func() {
+				BeforeEach(func() {
+					attType = design.Object{
+						"amount": &design.AttributeDefinition{
+							Type:       design.String,
+							Validation: &dslengine.ValidationDefinition{Format: "decimal"},
+							Metadata:   map[string][]string{"struct:field:type": {"decimal.Decimal"}},
+						},
+					}
+					validation = nil
+				})
+
+				It("does not produce validation code for the child", func() {
+					Ω(code).Should(BeEmpty())
+				})
+			})
 		})
 	})
 })
@@ -384,36 +499,79 @@ const (
 		}
 	}`
 
+	exclusiveMinValCode = `	if val != nil {
+		if *val <= 0.000000 {
+			err = goa.MergeErrors(err, goa.InvalidExclusiveRangeError(` + "`" + `context` + "`" + `, *val, 0.000000, true))
+		}
+	}`
+
+	integerMultipleValCode = `	if val != nil {
+		if *val%3 != 0 {
+			err = goa.MergeErrors(err, goa.InvalidMultipleOfError(` + "`" + `context` + "`" + `, *val, 3))
+		}
+	}`
+
+	numberMultipleValCode = `	if val != nil {
+		if !goa.ValidateMultipleOf(float64(*val), 0.500000) {
+			err = goa.MergeErrors(err, goa.InvalidMultipleOfError(` + "`" + `context` + "`" + `, *val, 0.500000))
+		}
+	}`
+
 	arrayMinLengthValCode = `	if val != nil {
 		if len(val) < 1 {
 			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`" + `context` + "`" + `, val, len(val), 1, true))
 		}
 	}`
 
-	arrayElementsValCode = `	for _, e := range val {
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+	arrayElementsValCode = `	for i, e := range val {
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, i))
 		}
 	}`
 
 	hashKeyElemValCode = `	for k, e := range val {
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, k); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, k, ` + "`" + `.*` + "`" + `))
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, k); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, k, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, k))
 		}
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, k))
 		}
 	}`
 
 	hashKeyValCode = `	for k, _ := range val {
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, k); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, k, ` + "`" + `.*` + "`" + `))
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, k); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, k, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, k))
 		}
 	}`
 
-	hashElemValCode = `	for _, e := range val {
-		if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
-			err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+	hashElemValCode = `	for k, e := range val {
+		if err2 := func() (err error) {
+			if ok := goa.ValidatePattern(` + "`" + `.*` + "`" + `, e); !ok {
+				err = goa.MergeErrors(err, goa.InvalidPatternError(` + "`" + `context[*]` + "`" + `, e, ` + "`" + `.*` + "`" + `))
+			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, k))
 		}
 	}`
 
@@ -431,6 +589,35 @@ const (
 		}
 	}`
 
+	readOnlyValCode = `	if val.Name == nil {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "name"))
+	}`
+
+	valueFieldsValCode = `	if !val.Name.Set {
+		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "name"))
+	}
+	if (val.Name.Set || val.Note != nil) && (!val.Name.Set || val.Note == nil) {
+		err = goa.MergeErrors(err, goa.RequiredTogetherError(` + "`context`" + `, []string{"name", "note"}))
+	}
+	if val.Name.Set {
+			if utf8.RuneCountInString(val.Name.Value) < 3 {
+			err = goa.MergeErrors(err, goa.InvalidLengthError(` + "`context.name`" + `, val.Name.Value, utf8.RuneCountInString(val.Name.Value), 3, true))
+		}
+	}`
+
+	fieldGroupsValCode = `	if (val.Start != nil || val.End != nil) && (val.Start == nil || val.End == nil) {
+		err = goa.MergeErrors(err, goa.RequiredTogetherError(` + "`context`" + `, []string{"start", "end"}))
+	}
+	if val.Tags == nil {
+		err = goa.MergeErrors(err, goa.RequiredTogetherError(` + "`context`" + `, []string{"id", "tags"}))
+	}
+	if (val.Start != nil && val.End != nil) || (val.Start != nil && val.Tags != nil) || (val.End != nil && val.Tags != nil) {
+		err = goa.MergeErrors(err, goa.MutuallyExclusiveError(` + "`context`" + `, []string{"start", "end", "tags"}))
+	}
+	if val.Start == nil && val.Tags == nil {
+		err = goa.MergeErrors(err, goa.AtLeastOneOfError(` + "`context`" + `, []string{"start", "tags"}))
+	}`
+
 	embeddedRequiredValCode = `	if val.Foo == nil {
 		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
 	}
@@ -462,11 +649,16 @@ const (
 		err = goa.MergeErrors(err, goa.MissingAttributeError(` + "`context`" + `, "foo"))
 	}`
 
-	utRequiredCode = `	for _, e := range val.Foo {
-		if e != nil {
-			if err2 := e.Validate(); err2 != nil {
-				err = goa.MergeErrors(err, err2)
+	utRequiredCode = `	for i, e := range val.Foo {
+		if err2 := func() (err error) {
+			if e != nil {
+				if err2 := e.Validate(); err2 != nil {
+					err = goa.MergeErrors(err, err2)
+				}
 			}
+			return
+		}(); err2 != nil {
+			err = goa.MergeErrors(err, goa.NestError(err2, "foo", i))
 		}
 	}`
 )
@@ -62,6 +62,7 @@ var (
 		"goify":               Goify,
 		"goifyatt":            GoifyAtt,
 		"gonative":            GoNativeType,
+		"goenumtypedefs":      GoEnumTypeDefs,
 		"gotypedef":           GoTypeDef,
 		"gotypename":          GoTypeName,
 		"gotypedesc":          GoTypeDesc,
@@ -70,6 +71,7 @@ var (
 		"recursivePublicizer": RecursivePublicizer,
 		"tabs":                Tabs,
 		"tempvar":             Tempvar,
+		"timeCodec":           TimeCodec,
 		"title":               strings.Title,
 		"toLower":             strings.ToLower,
 		"validationChecker":   ValidationChecker,
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goadesign/goa/goagen/gen_model"
+)
+
+// diff compares the design models stored in the files given as arguments and prints the
+// changes. It returns an error if any of the changes is breaking.
+func diff(args []string, jsonOutput bool) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: goagen diff OLD NEW")
+	}
+	before, err := genmodel.Load(args[0])
+	if err != nil {
+		return err
+	}
+	after, err := genmodel.Load(args[1])
+	if err != nil {
+		return err
+	}
+	changes := genmodel.Diff(before, after)
+	breaking := genmodel.Breaking(changes)
+	if jsonOutput {
+		if changes == nil {
+			changes = []*genmodel.Change{}
+		}
+		js, err := json.MarshalIndent(map[string]interface{}{
+			"breaking": len(breaking) > 0,
+			"changes":  changes,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(js))
+	} else {
+		for _, c := range changes {
+			prefix := "    "
+			if c.Breaking {
+				prefix = "[!] "
+			}
+			fmt.Println(prefix + c.String())
+		}
+	}
+	if len(breaking) > 0 {
+		return fmt.Errorf("%d breaking change(s)", len(breaking))
+	}
+	return nil
+}
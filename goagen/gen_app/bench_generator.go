@@ -0,0 +1,254 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// BenchmarkData contains the data needed to render the encoding benchmarks.
+	BenchmarkData struct {
+		Payloads   []*PayloadBenchmark
+		MediaTypes []*MediaTypeBenchmark
+		Contexts   []*ContextBenchmark
+	}
+
+	// PayloadBenchmark describes the benchmark of a request body decoder.
+	PayloadBenchmark struct {
+		Unmarshal string
+		Verb      string
+		Body      string
+	}
+
+	// MediaTypeBenchmark describes the benchmark of a response body encoder.
+	MediaTypeBenchmark struct {
+		TypeName string
+		Type     string
+		Body     string
+	}
+
+	// ContextBenchmark describes the benchmark of a context factory parsing request parameters.
+	ContextBenchmark struct {
+		ResourceName string
+		ActionName   string
+		Context      string
+		Verb         string
+		Params       []*BenchmarkValues
+		Headers      []*BenchmarkValues
+	}
+
+	// BenchmarkValues contains the raw values of a request parameter or header.
+	BenchmarkValues struct {
+		Name   string
+		Values []string
+	}
+)
+
+// generateBenchmarks generates the benchmarks that measure the decoding of the request bodies and
+// parameters and the encoding of the response bodies using the design examples.
+func (g *Generator) generateBenchmarks() (err error) {
+	data := &BenchmarkData{}
+	if g.consumesJSON() {
+		g.API.IterateResources(func(res *design.ResourceDefinition) error {
+			return res.IterateActions(func(a *design.ActionDefinition) error {
+				if a.Payload == nil || a.PayloadMultipart || a.WebSocket() || len(a.Routes) == 0 {
+					return nil
+				}
+				js, err := json.Marshal(a.Payload.GenerateExample(g.API.RandomGenerator(), nil))
+				if err != nil {
+					return nil
+				}
+				data.Payloads = append(data.Payloads, &PayloadBenchmark{
+					Unmarshal: fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true)),
+					Verb:      a.Routes[0].Verb,
+					Body:      string(js),
+				})
+				return nil
+			})
+		})
+	}
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || (!mt.Type.IsObject() && !mt.Type.IsArray()) {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			js, err := json.Marshal(p.GenerateExample(g.API.RandomGenerator(), nil))
+			if err != nil {
+				return nil
+			}
+			name := codegen.GoTypeName(p, nil, 0, false)
+			typ := name
+			if p.IsObject() {
+				typ = "*" + name
+			}
+			data.MediaTypes = append(data.MediaTypes, &MediaTypeBenchmark{TypeName: name, Type: typ, Body: string(js)})
+			return nil
+		})
+	})
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Params == nil || a.WebSocket() || len(a.Routes) == 0 {
+				return nil
+			}
+			data.Contexts = append(data.Contexts, &ContextBenchmark{
+				ResourceName: res.Name,
+				ActionName:   a.Name,
+				Context:      fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true)),
+				Verb:         a.Routes[0].Verb,
+				Params:       g.benchmarkValues(a.Params),
+				Headers:      g.benchmarkValues(a.Headers),
+			})
+			return nil
+		})
+	})
+	if len(data.Payloads) == 0 && len(data.MediaTypes) == 0 && len(data.Contexts) == 0 {
+		return nil
+	}
+	benchFile := filepath.Join(g.OutDir, "benchmarks_test.go")
+	file, err := codegen.SourceFileFor(benchFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Encoding Benchmarks", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, benchFile)
+	tmpl := template.Must(template.New("bench").Funcs(codegen.DefaultFuncMap).Parse(benchT))
+	return tmpl.Execute(file, data)
+}
+
+// benchmarkValues returns the raw values of the example of each attribute of the given params or
+// headers sorted by name. The elements of the array query string parameters that define a
+// separator are joined so that the benchmarks exercise the splitting.
+func (g *Generator) benchmarkValues(att *design.AttributeDefinition) []*BenchmarkValues {
+	if att == nil {
+		return nil
+	}
+	obj := att.Type.ToObject()
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	res := make([]*BenchmarkValues, 0, len(names))
+	for _, n := range names {
+		a := obj[n]
+		ex := a.GenerateExample(g.API.RandomGenerator(), nil)
+		var vals []string
+		if v := reflect.ValueOf(ex); ex != nil && v.Kind() == reflect.Slice {
+			for i := 0; i < v.Len(); i++ {
+				vals = append(vals, fuzzSeed(v.Index(i).Interface()))
+			}
+			if sep := a.ParamSeparator(); sep != "" && len(vals) > 0 {
+				vals = []string{strings.Join(vals, sep)}
+			}
+		} else if ex != nil {
+			vals = []string{fuzzSeed(ex)}
+		}
+		if len(vals) > 0 {
+			res = append(res, &BenchmarkValues{Name: n, Values: vals})
+		}
+	}
+	return res
+}
+
+// consumesJSON returns true if the API request bodies may be encoded with JSON, the format of the
+// generated examples.
+func (g *Generator) consumesJSON() bool {
+	if len(g.API.Consumes) == 0 {
+		return true
+	}
+	for _, enc := range g.API.Consumes {
+		for _, mt := range enc.MIMETypes {
+			if mt == "application/json" || strings.HasSuffix(mt, "+json") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+const benchT = `{{ range .Payloads }}
+// Benchmark{{ goify .Unmarshal true }} measures the decoding and validation of the request body.
+func Benchmark{{ goify .Unmarshal true }}(b *testing.B) {
+	service := goa.New("bench")
+	initService(service)
+	body := []byte({{ printf "%q" .Body }})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		req, _ := http.NewRequest({{ printf "%q" .Verb }}, "/", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, url.Values{})
+		if err := {{ .Unmarshal }}(ctx, service, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{ end }}{{ range .MediaTypes }}
+// BenchmarkEncode{{ .TypeName }} measures the encoding of the {{ .TypeName }} response body.
+func BenchmarkEncode{{ .TypeName }}(b *testing.B) {
+	service := goa.New("bench")
+	initService(service)
+	var v {{ .Type }}
+	if err := json.Unmarshal([]byte({{ printf "%q" .Body }}), &v); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := service.Encoder.Encode(v, ioutil.Discard, "application/json"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{ end }}{{ range .Contexts }}
+// BenchmarkNew{{ .Context }} measures the parsing and validation of the {{ .ResourceName }}
+// {{ .ActionName }} request parameters.
+func BenchmarkNew{{ .Context }}(b *testing.B) {
+	service := goa.New("bench")
+	req, _ := http.NewRequest({{ printf "%q" .Verb }}, "/", nil)
+{{ range .Headers }}{{ $name := .Name }}{{ range .Values }}	req.Header.Add({{ printf "%q" $name }}, {{ printf "%q" . }})
+{{ end }}{{ end }}	params := url.Values{
+{{ range .Params }}		{{ printf "%q" .Name }}: {{ printf "%#v" .Values }},
+{{ end }}	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, params)
+		if _, err := New{{ .Context }}(ctx, req, service); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+{{ end }}`
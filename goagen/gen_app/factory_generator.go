@@ -0,0 +1,148 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FactoryData describes a function of the generated factory package that builds a value of a
+// payload, media type or user type.
+type FactoryData struct {
+	// TypeName is the name of the Go type in the app package.
+	TypeName string
+	// Pointer is true if the factory returns a pointer to the type, i.e. the type is an object.
+	Pointer bool
+	// Example is the JSON encoded example the values are decoded from.
+	Example string
+}
+
+// generateFactories generates the factory package: one function per payload, media type view and
+// user type that returns a fully populated value built from the design examples. The examples
+// honor the validations, enums and formats defined in the design so that the values are valid.
+func (g *Generator) generateFactories() (err error) {
+	factories := make(map[string]*FactoryData)
+	add := func(name string, att *design.AttributeDefinition) {
+		if _, ok := factories[name]; ok {
+			return
+		}
+		if !att.Type.IsObject() && !att.Type.IsArray() {
+			return
+		}
+		if design.HasFile(att.Type) {
+			return
+		}
+		ex := att.GenerateExample(g.API.RandomGenerator(), nil)
+		if ex == nil {
+			return
+		}
+		js, err := json.Marshal(ex)
+		if err != nil {
+			return
+		}
+		factories[name] = &FactoryData{TypeName: name, Pointer: att.Type.IsObject(), Example: string(js)}
+	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil || a.PayloadMultipart {
+				return nil
+			}
+			if path, _ := codegen.TypePackage(a.Payload); path == "" {
+				add(codegen.GoTypeName(a.Payload, nil, 1, false), a.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			add(codegen.GoTypeName(p, nil, 0, false), p.AttributeDefinition)
+			return nil
+		})
+	})
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if path, _ := codegen.TypePackage(ut); path == "" {
+			add(codegen.GoTypeName(ut, nil, 0, false), ut.AttributeDefinition)
+		}
+		return nil
+	})
+	if len(factories) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(factories))
+	for n := range factories {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	data := make([]*FactoryData, len(names))
+	for i, n := range names {
+		data[i] = factories[n]
+	}
+
+	appPkg, err := codegen.PackagePath(g.OutDir)
+	if err != nil {
+		return err
+	}
+	outDir := filepath.Join(g.OutDir, "factory")
+	if err = os.RemoveAll(outDir); err != nil {
+		return err
+	}
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, outDir)
+	factoryFile := filepath.Join(outDir, "factory.go")
+	file, err := codegen.SourceFileFor(factoryFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Fake Data Factories", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport(appPkg),
+	}
+	if err = file.WriteHeader(title, "factory", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, factoryFile)
+	tmpl := template.Must(template.New("factory").Funcs(codegen.DefaultFuncMap).Parse(factoryT))
+	return tmpl.Execute(file, map[string]interface{}{"AppPkg": g.Target, "Factories": data})
+}
+
+const factoryT = `{{ $pkg := .AppPkg }}// decode decodes the JSON encoded example into v. The examples are generated from the design and
+// always decode.
+func decode(example string, v interface{}) {
+	if err := json.Unmarshal([]byte(example), v); err != nil {
+		panic(err)
+	}
+}
+{{ range .Factories }}
+// New{{ .TypeName }} returns a fully populated {{ .TypeName }} built from the design examples. The
+// mutators are applied in order to the new value before it is returned.
+func New{{ .TypeName }}(mutators ...func(*{{ $pkg }}.{{ .TypeName }})) {{ if .Pointer }}*{{ end }}{{ $pkg }}.{{ .TypeName }} {
+	var v {{ $pkg }}.{{ .TypeName }}
+	decode({{ printf "%q" .Example }}, &v)
+	for _, m := range mutators {
+		m(&v)
+	}
+	return {{ if .Pointer }}&{{ end }}v
+}
+{{ end }}`
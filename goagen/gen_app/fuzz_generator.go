@@ -0,0 +1,174 @@
+package genapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// FuzzTarget contains the data needed to render the fuzz targets of a single action.
+type FuzzTarget struct {
+	ResourceName string
+	ActionName   string
+	Context      string
+	Verb         string
+	Unmarshal    string
+	ContentType  string
+	PayloadSeed  string
+	Params       []*FuzzParam
+}
+
+// FuzzParam describes a request parameter exercised by a fuzz target.
+type FuzzParam struct {
+	Name string
+	Seed string
+}
+
+// generateFuzz generates the fuzz targets that exercise the request decoding and validation code
+// of all the actions. The targets are written in a test file of the app package guarded by a go1.18
+// build constraint.
+func (g *Generator) generateFuzz() (err error) {
+	var targets []*FuzzTarget
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || len(a.Routes) == 0 {
+				return nil
+			}
+			if t := g.fuzzTarget(res, a); t != nil {
+				targets = append(targets, t)
+			}
+			return nil
+		})
+	})
+	if len(targets) == 0 {
+		return nil
+	}
+	fuzzFile := filepath.Join(g.OutDir, "fuzz_test.go")
+	file, err := codegen.SourceFileFor(fuzzFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	if _, err = file.Write([]byte("//go:build go1.18\n// +build go1.18\n\n")); err != nil {
+		return err
+	}
+	title := fmt.Sprintf("%s: Request Decoder Fuzz Targets", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, fuzzFile)
+	tmpl := template.Must(template.New("fuzz").Funcs(codegen.DefaultFuncMap).Parse(fuzzT))
+	return tmpl.Execute(file, targets)
+}
+
+// fuzzTarget builds the fuzz target data for the given action, nil if there is nothing to fuzz.
+func (g *Generator) fuzzTarget(res *design.ResourceDefinition, a *design.ActionDefinition) *FuzzTarget {
+	t := &FuzzTarget{
+		ResourceName: res.Name,
+		ActionName:   a.Name,
+		Context:      fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true)),
+		Verb:         a.Routes[0].Verb,
+	}
+	if a.Payload != nil && !a.PayloadMultipart {
+		t.Unmarshal = fmt.Sprintf("unmarshal%s%sPayload", codegen.Goify(a.Name, true), codegen.Goify(res.Name, true))
+		t.ContentType = "application/json"
+		if len(g.API.Consumes) > 0 && len(g.API.Consumes[0].MIMETypes) > 0 {
+			t.ContentType = g.API.Consumes[0].MIMETypes[0]
+		}
+		if js, err := json.Marshal(a.Payload.GenerateExample(g.API.RandomGenerator(), nil)); err == nil {
+			t.PayloadSeed = string(js)
+		}
+	}
+	if a.Params != nil {
+		params := a.Params.Type.ToObject()
+		names := make([]string, 0, len(params))
+		for n := range params {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			t.Params = append(t.Params, &FuzzParam{
+				Name: n,
+				Seed: fuzzSeed(params[n].GenerateExample(g.API.RandomGenerator(), nil)),
+			})
+		}
+	}
+	if t.Unmarshal == "" && len(t.Params) == 0 {
+		return nil
+	}
+	return t
+}
+
+// fuzzSeed returns the string representation of a parameter example value.
+func fuzzSeed(ex interface{}) string {
+	switch actual := ex.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return actual.Format(time.RFC3339)
+	}
+	if v := reflect.ValueOf(ex); v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return ""
+		}
+		return fuzzSeed(v.Index(0).Interface())
+	}
+	return fmt.Sprintf("%v", ex)
+}
+
+const fuzzT = `{{ range . }}{{ if .Unmarshal }}
+// Fuzz{{ goify .Unmarshal true }} exercises the decoding and validation of the {{ .ResourceName }}
+// {{ .ActionName }} request body.
+func Fuzz{{ goify .Unmarshal true }}(f *testing.F) {
+{{ if .PayloadSeed }}	f.Add([]byte({{ printf "%q" .PayloadSeed }}))
+{{ end }}	f.Fuzz(func(t *testing.T, body []byte) {
+		service := goa.New("fuzz")
+		initService(service)
+		req, err := http.NewRequest({{ printf "%q" .Verb }}, "/", bytes.NewReader(body))
+		if err != nil {
+			t.Skip()
+		}
+		req.Header.Set("Content-Type", {{ printf "%q" .ContentType }})
+		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, url.Values{})
+		{{ .Unmarshal }}(ctx, service, req)
+	})
+}
+{{ end }}{{ if .Params }}
+// FuzzNew{{ .Context }} exercises the parsing and validation of the {{ .ResourceName }}
+// {{ .ActionName }} request parameters.
+func FuzzNew{{ .Context }}(f *testing.F) {
+	f.Add({{ range $i, $p := .Params }}{{ if $i }}, {{ end }}{{ printf "%q" $p.Seed }}{{ end }})
+	f.Fuzz(func(t *testing.T{{ range $i, $p := .Params }}, p{{ $i }} string{{ end }}) {
+		service := goa.New("fuzz")
+		req, err := http.NewRequest({{ printf "%q" .Verb }}, "/", nil)
+		if err != nil {
+			t.Skip()
+		}
+		params := url.Values{}
+{{ range $i, $p := .Params }}		params.Set({{ printf "%q" $p.Name }}, p{{ $i }})
+{{ end }}		ctx := goa.NewContext(context.Background(), httptest.NewRecorder(), req, params)
+		New{{ .Context }}(ctx, req, service)
+	})
+}
+{{ end }}{{ end }}`
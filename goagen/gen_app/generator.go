@@ -1,18 +1,23 @@
 package genapp
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"time"
 
 	"github.com/goadesign/goa/design"
 	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_schema"
 	"github.com/goadesign/goa/goagen/utils"
 )
 
-//NewGenerator returns an initialized instance of an Application Generator
+// NewGenerator returns an initialized instance of an Application Generator
 func NewGenerator(options ...Option) *Generator {
 	g := &Generator{}
 	g.validator = codegen.NewValidator()
@@ -30,6 +35,7 @@ type Generator struct {
 	OutDir    string                // Path to output directory
 	Target    string                // Name of generated package
 	NoTest    bool                  // Whether to skip test generation
+	DI        string                // Dependency injection framework to generate the providers for, "wire" or "fx"
 	genfiles  []string              // Generated files
 	validator *codegen.Validator    // Validation code generator
 }
@@ -37,8 +43,8 @@ type Generator struct {
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, toolDir, target, ver string
-		notest, notool, regen        bool
+		outDir, toolDir, target, ver, services, di string
+		notest, notool, regen                      bool
 	)
 
 	set := flag.NewFlagSet("app", flag.PanicOnError)
@@ -51,6 +57,13 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("force", false, "")
+	set.Bool("stubs", false, "")
+	set.Bool("deploy", false, "")
+	set.Bool("http3", false, "")
+	set.String("app-pkg", "", "")
+	set.StringVar(&services, "service", "", "")
+	set.StringVar(&di, "di", "", "")
+	set.Bool("split", false, "")
 	set.Parse(os.Args[1:])
 	outDir = filepath.Join(outDir, target)
 
@@ -58,8 +71,13 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
+	api, err := codegen.SelectedAPI(services)
+	if err != nil {
+		return nil, err
+	}
+
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, API: design.Design, validator: codegen.NewValidator()}
+	g := &Generator{OutDir: outDir, Target: target, NoTest: notest, DI: di, API: api, validator: codegen.NewValidator()}
 
 	return g.Generate()
 }
@@ -69,6 +87,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.API == nil {
 		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
 	}
+	if g.DI != "" && g.DI != "wire" && g.DI != "fx" {
+		return nil, fmt.Errorf("unsupported dependency injection framework %#v, must be wire or fx", g.DI)
+	}
 
 	go utils.Catch(nil, func() { g.Cleanup() })
 
@@ -98,16 +119,64 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err := g.generateHrefs(); err != nil {
 		return nil, err
 	}
+	if err := g.generateJSONRPC(); err != nil {
+		return nil, err
+	}
+	if err := g.generateHealthChecks(); err != nil {
+		return nil, err
+	}
+	if err := g.generateJobs(); err != nil {
+		return nil, err
+	}
+	if err := g.generateUploads(); err != nil {
+		return nil, err
+	}
+	if err := g.generateErrorClasses(); err != nil {
+		return nil, err
+	}
+	if err := g.generateEvents(); err != nil {
+		return nil, err
+	}
+	if err := g.generateRoutes(); err != nil {
+		return nil, err
+	}
+	if err := g.generateHeaders(); err != nil {
+		return nil, err
+	}
+	if err := g.generateHeaderGroups(); err != nil {
+		return nil, err
+	}
+	if err := g.generateSchemaVersions(); err != nil {
+		return nil, err
+	}
 	if err := g.generateMediaTypes(); err != nil {
 		return nil, err
 	}
 	if err := g.generateUserTypes(); err != nil {
 		return nil, err
 	}
+	if err := g.generateValidationRules(); err != nil {
+		return nil, err
+	}
+	if err := g.generateTypes(); err != nil {
+		return nil, err
+	}
+	if err := g.generateProviders(); err != nil {
+		return nil, err
+	}
+	if err := g.generateFactories(); err != nil {
+		return nil, err
+	}
 	if !g.NoTest {
 		if err := g.generateResourceTest(); err != nil {
 			return nil, err
 		}
+		if err := g.generateFuzz(); err != nil {
+			return nil, err
+		}
+		if err := g.generateBenchmarks(); err != nil {
+			return nil, err
+		}
 	}
 
 	return g.genfiles, nil
@@ -144,8 +213,12 @@ func (g *Generator) generateContexts() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application Contexts", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("math"),
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
@@ -157,7 +230,7 @@ func (g *Generator) generateContexts() (err error) {
 	g.API.IterateResources(func(r *design.ResourceDefinition) error {
 		return r.IterateActions(func(a *design.ActionDefinition) error {
 			if a.Payload != nil {
-				imports = codegen.AttributeImports(a.Payload.AttributeDefinition, imports, nil)
+				imports = codegen.AttributeImports(&design.AttributeDefinition{Type: a.Payload}, imports, nil)
 			}
 			return nil
 		})
@@ -207,7 +280,17 @@ func (g *Generator) generateContexts() (err error) {
 				API:          g.API,
 				DefaultPkg:   g.Target,
 				Security:     a.Security,
+				ViewParam:    a.ViewParam,
+				ViewHeader:   a.ViewHeader,
+				FieldMask:    a.FieldMask,
+				Tenant:       tenantField(a),
+				Async:        a.Async && a.Responses[design.Accepted].MediaType == design.JobMediaIdentifier,
+				Resumable:    a.Resumable && a.Responses[design.Created].MediaType == "",
+				HeaderGroups: BuildHeaderGroups(a.HeaderGroups),
+				QueryObjects: BuildQueryObjects(a.QueryObjects),
 			}
+			ctxData.SchemaVersionHeader = a.SchemaVersionHeader
+			ctxData.ResponseHeaderGroups = BuildHeaderGroups(responseHeaderGroups(a))
 			return ctxWr.Execute(&ctxData)
 		})
 	})
@@ -241,8 +324,10 @@ func (g *Generator) generateControllers() (err error) {
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/goadesign/goa/cors"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
 		codegen.SimpleImport("regexp"),
 		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("time"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
@@ -271,6 +356,14 @@ func (g *Generator) generateControllers() (err error) {
 	for _, packagePath := range packagePaths {
 		imports = append(imports, codegen.SimpleImport(packagePath))
 	}
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				imports = codegen.AttributeImports(&design.AttributeDefinition{Type: a.Payload}, imports, nil)
+			}
+			return nil
+		})
+	})
 	if err = ctlWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
 	}
@@ -295,6 +388,7 @@ func (g *Generator) generateControllers() (err error) {
 					RequestPath: rpath,
 					Metadata:    fs.Metadata,
 					Security:    fs.Security,
+					Signed:      fs.Signed,
 				})
 			}
 		}
@@ -303,6 +397,10 @@ func (g *Generator) generateControllers() (err error) {
 			Resource:       codegen.Goify(r.Name, true),
 			PreflightPaths: r.PreflightPaths(),
 			FileServers:    fileServers,
+			CSRF:           r.CSRF,
+			CSRFTokenPath:  r.CSRFTokenFullPath(),
+			SignedFiles:    signedFiles(r),
+			Middleware:     r.AllMiddleware(),
 		}
 		r.IterateActions(func(a *design.ActionDefinition) error {
 			context := fmt.Sprintf("%s%sContext", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true))
@@ -316,7 +414,30 @@ func (g *Generator) generateControllers() (err error) {
 				"Payload":          a.Payload,
 				"PayloadOptional":  a.PayloadOptional,
 				"PayloadMultipart": a.PayloadMultipart,
+				"PayloadLimits":    payloadLimits(a.Payload),
 				"Security":         a.Security,
+				"ProxyURL":         a.ProxyURL,
+				"Raw":              a.IsRaw(),
+				"LazyBody":         a.IsLazyBody() && a.Payload != nil,
+				"CacheTTL":         durationCode(a.CacheTTL),
+				"CacheKeys":        a.CacheKeys,
+				"Coalesced":        a.Coalesced,
+				"LogSampler":       logSamplerCode(a.LogSampling),
+				"Audited":          a.Audited,
+				"Recorded":         a.Recorded,
+				"ResourceName":     r.Name,
+				"BatchRoute":       a.BatchRoute,
+				"Priority":         a.Priority,
+				"Deprecation":      deprecationCode(a.Deprecation),
+				"Concurrency":      concurrencyCode(a.Concurrency),
+				"Flag":             a.Flag,
+				"Middleware":       middlewareFields(a.Middleware),
+				"ClientClosed":     a.ClientClosedStatus != nil && *a.ClientClosedStatus,
+				"FaultResponse":    faultResponse(a),
+			}
+			if len(a.PayloadVersions) > 0 {
+				action["PayloadVersions"] = payloadVersions(a)
+				action["SchemaVersionHeader"] = a.SchemaVersionHeader
 			}
 			data.Actions = append(data.Actions, action)
 			return nil
@@ -362,7 +483,10 @@ func (g *Generator) generateSecurity() (err error) {
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("errors"),
 		codegen.SimpleImport("context"),
+		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/security/cookieauth"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware/security/spiffeauth"),
 	}
 	if err = secWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
@@ -422,6 +546,674 @@ func (g *Generator) generateHrefs() (err error) {
 	return
 }
 
+// generateJSONRPC generates the code that mounts the JSON-RPC endpoint if the API defines one.
+func (g *Generator) generateJSONRPC() (err error) {
+	if g.API.JSONRPCPath == "" {
+		return nil
+	}
+
+	var (
+		rpcFile string
+		rpcWr   *JSONRPCWriter
+	)
+	{
+		rpcFile = filepath.Join(g.OutDir, "jsonrpc.go")
+		rpcWr, err = NewJSONRPCWriter(rpcFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		rpcWr.Close()
+		if err == nil {
+			err = rpcWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application JSON-RPC Endpoint", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = rpcWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, rpcFile)
+	var methods []*JSONRPCMethodData
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.PayloadMultipart || len(a.Routes) == 0 {
+				return nil
+			}
+			route := a.Routes[0]
+			m := &JSONRPCMethodData{
+				Name: r.Name + "." + a.Name,
+				Verb: route.Verb,
+				Path: route.FullPath(),
+			}
+			if a.QueryParams != nil {
+				for n, att := range a.QueryParams.Type.ToObject() {
+					if !att.Type.IsArray() {
+						continue
+					}
+					if sep := att.ParamSeparator(); sep != "" {
+						if m.Separators == nil {
+							m.Separators = make(map[string]string)
+						}
+						m.Separators[n] = sep
+					}
+				}
+			}
+			methods = append(methods, m)
+			return nil
+		})
+	})
+	err = rpcWr.Execute(g.API.JSONRPCPath, methods)
+	return
+}
+
+// generateHealthChecks generates the code that mounts the health check endpoints if the API
+// servers define any.
+func (g *Generator) generateHealthChecks() (err error) {
+	paths := g.API.HealthChecks()
+	if len(paths) == 0 {
+		return nil
+	}
+
+	var (
+		healthFile string
+		healthWr   *HealthWriter
+	)
+	{
+		healthFile = filepath.Join(g.OutDir, "health.go")
+		healthWr, err = NewHealthWriter(healthFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		healthWr.Close()
+		if err == nil {
+			err = healthWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Health Checks", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = healthWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, healthFile)
+	err = healthWr.Execute(paths)
+	return
+}
+
+// generateJobs generates the code that mounts the job status endpoint if the API defines
+// asynchronous actions.
+func (g *Generator) generateJobs() (err error) {
+	if !g.API.HasAsyncActions() {
+		return nil
+	}
+
+	var (
+		jobsFile string
+		jobsWr   *JobsWriter
+	)
+	{
+		jobsFile = filepath.Join(g.OutDir, "jobs.go")
+		jobsWr, err = NewJobsWriter(jobsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		jobsWr.Close()
+		if err == nil {
+			err = jobsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Job Status", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = jobsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, jobsFile)
+	err = jobsWr.Execute(g.API)
+	return
+}
+
+// generateProviders generates the providers of the dependency injection framework given with the
+// --di flag if any.
+func (g *Generator) generateProviders() (err error) {
+	if g.DI == "" {
+		return nil
+	}
+	var controllers []string
+	middleware := make(map[string]bool)
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		if len(r.Actions) > 0 || len(r.FileServers) > 0 {
+			name := codegen.Goify(r.Name, true)
+			controllers = append(controllers, name)
+			middleware[name] = len(r.AllMiddleware()) > 0
+		}
+		return nil
+	})
+
+	var (
+		provFile string
+		provWr   *ProvidersWriter
+	)
+	{
+		provFile = filepath.Join(g.OutDir, g.DI+".go")
+		provWr, err = NewProvidersWriter(provFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		provWr.Close()
+		if err == nil {
+			err = provWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Dependency Injection Providers", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if g.DI == "wire" {
+		imports = append(imports, codegen.SimpleImport("github.com/google/wire"))
+	} else {
+		imports = append(imports, codegen.SimpleImport("go.uber.org/fx"))
+	}
+	if err = provWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, provFile)
+	err = provWr.Execute(g.DI, g.API.Name, controllers, middleware)
+	return
+}
+
+// generateUploads generates the code that mounts the upload offset endpoints of the resumable
+// actions if the API defines any.
+func (g *Generator) generateUploads() (err error) {
+	if !g.API.HasResumableActions() {
+		return nil
+	}
+
+	var (
+		uploadsFile string
+		uploadsWr   *UploadsWriter
+	)
+	{
+		uploadsFile = filepath.Join(g.OutDir, "uploads.go")
+		uploadsWr, err = NewUploadsWriter(uploadsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		uploadsWr.Close()
+		if err == nil {
+			err = uploadsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Upload Offset Endpoints", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = uploadsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, uploadsFile)
+	var uploads []*UploadData
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Resumable {
+				uploads = append(uploads, &UploadData{
+					Name:         fmt.Sprintf("Mount%s%sUpload", codegen.Goify(a.Name, true), codegen.Goify(r.Name, true)),
+					ResourceName: r.Name,
+					ActionName:   a.Name,
+					Path:         a.UploadPath(),
+				})
+			}
+			return nil
+		})
+	})
+	err = uploadsWr.Execute(uploads)
+	return
+}
+
+// generateErrorClasses generates the error classes if the API defines any.
+func (g *Generator) generateErrorClasses() (err error) {
+	if len(g.API.ErrorClasses) == 0 {
+		return nil
+	}
+	classes := make([]*design.ResponseDefinition, len(g.API.ErrorClasses))
+	for i, n := range g.API.ErrorClasses {
+		classes[i] = g.API.Responses[n]
+	}
+
+	var (
+		errFile string
+		errWr   *ErrorClassesWriter
+	)
+	{
+		errFile = filepath.Join(g.OutDir, "errors.go")
+		errWr, err = NewErrorClassesWriter(errFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		errWr.Close()
+		if err == nil {
+			err = errWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Error Classes", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = errWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, errFile)
+	err = errWr.Execute(classes)
+	return
+}
+
+// generateEvents generates the code that consumes the events if the API defines any.
+func (g *Generator) generateEvents() (err error) {
+	var events []*EventData
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Topic == "" || len(a.Routes) == 0 {
+				return nil
+			}
+			events = append(events, &EventData{
+				Topic: a.Topic,
+				Verb:  a.Routes[0].Verb,
+				Path:  a.Routes[0].FullPath(),
+			})
+			return nil
+		})
+	})
+	if len(events) == 0 {
+		return nil
+	}
+
+	var (
+		evFile string
+		evWr   *EventsWriter
+	)
+	{
+		evFile = filepath.Join(g.OutDir, "events.go")
+		evWr, err = NewEventsWriter(evFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		evWr.Close()
+		if err == nil {
+			err = evWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Event Consumers", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = evWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, evFile)
+	err = evWr.Execute(events)
+	return
+}
+
+// generateRoutes generates the route table listing the routes of the resource actions and file
+// servers if the API defines any.
+func (g *Generator) generateRoutes() (err error) {
+	var routes []*RouteData
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		r.IterateActions(func(a *design.ActionDefinition) error {
+			for _, route := range a.Routes {
+				routes = append(routes, &RouteData{
+					Verb:       route.Verb,
+					Path:       route.FullPath(),
+					Resource:   r.Name,
+					Endpoint:   a.Name,
+					Security:   a.Security,
+					Flag:       flagName(a.Flag),
+					Middleware: a.Middleware,
+				})
+			}
+			if route := a.BatchRoute; route != nil {
+				routes = append(routes, &RouteData{
+					Verb:     route.Verb,
+					Path:     route.FullPath(),
+					Resource: r.Name,
+					Endpoint: a.Name + " batch",
+					Security: a.Security,
+					Flag:     flagName(a.Flag),
+				})
+			}
+			return nil
+		})
+		return r.IterateFileServers(func(f *design.FileServerDefinition) error {
+			routes = append(routes, &RouteData{
+				Verb:     "GET",
+				Path:     f.RequestPath,
+				Resource: r.Name,
+				Endpoint: "serve",
+				Security: f.Security,
+			})
+			return nil
+		})
+	})
+	if len(routes) == 0 {
+		return nil
+	}
+
+	var (
+		routesFile string
+		routesWr   *RoutesWriter
+	)
+	{
+		routesFile = filepath.Join(g.OutDir, "routes.go")
+		routesWr, err = NewRoutesWriter(routesFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		routesWr.Close()
+		if err == nil {
+			err = routesWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Route Table", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = routesWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, routesFile)
+	err = routesWr.Execute(g.API.Name, routes)
+	return
+}
+
+// generateHeaders generates the constants holding the names of the request and response headers
+// used by the API together with their typed accessors.
+func (g *Generator) generateHeaders() (err error) {
+	index := make(map[string]*HeaderData)
+	collect := func(headers *design.AttributeDefinition) {
+		if headers == nil {
+			return
+		}
+		for name, att := range headers.Type.ToObject() {
+			key := http.CanonicalHeaderKey(att.AttributeKey(name))
+			h, ok := index[key]
+			if !ok {
+				h = &HeaderData{Name: codegen.Goify(key, true), Key: key}
+				switch att.Type.Kind() {
+				case design.BooleanKind, design.IntegerKind, design.NumberKind, design.StringKind, design.DateTimeKind:
+					h.Type = att.Type
+				}
+				index[key] = h
+				continue
+			}
+			if h.Type != nil && h.Type != att.Type {
+				// Conflicting types, only generate the constant.
+				h.Type = nil
+			}
+		}
+	}
+	g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		collect(r.Headers)
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			collect(a.Headers)
+			return a.IterateResponses(func(resp *design.ResponseDefinition) error {
+				collect(resp.Headers)
+				return nil
+			})
+		})
+	})
+	for _, grp := range g.API.HeaderGroups() {
+		headers := make(design.Object)
+		for n, att := range grp.Type.ToObject() {
+			headers[n] = &design.AttributeDefinition{Type: att.Type, Key: grp.HeaderKey(n)}
+		}
+		collect(&design.AttributeDefinition{Type: headers})
+	}
+	if len(index) == 0 {
+		return nil
+	}
+	headers := make([]*HeaderData, 0, len(index))
+	for _, h := range index {
+		headers = append(headers, h)
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i].Name < headers[j].Name })
+
+	var (
+		headersFile string
+		headersWr   *HeadersWriter
+	)
+	{
+		headersFile = filepath.Join(g.OutDir, "headers.go")
+		headersWr, err = NewHeadersWriter(headersFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		headersWr.Close()
+		if err == nil {
+			err = headersWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Headers", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = headersWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, headersFile)
+	err = headersWr.Execute(headers)
+	return
+}
+
+// generateHeaderGroups generates the functions that map the header groups of the API to their
+// types. Nothing is generated if the API defines no header group.
+func (g *Generator) generateHeaderGroups() (err error) {
+	groups := g.API.HeaderGroups()
+	if len(groups) == 0 {
+		return nil
+	}
+	var (
+		groupsFile string
+		groupsWr   *HeaderGroupsWriter
+	)
+	{
+		groupsFile = filepath.Join(g.OutDir, "header_groups.go")
+		groupsWr, err = NewHeaderGroupsWriter(groupsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		groupsWr.Close()
+		if err == nil {
+			err = groupsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Header Groups", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = groupsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, groupsFile)
+	err = groupsWr.Execute(BuildHeaderGroups(groups))
+	return
+}
+
+// generateSchemaVersions generates the functions that convert the schema revisions of the
+// payloads and responses of the API.
+func (g *Generator) generateSchemaVersions() (err error) {
+	var versions []*SchemaVersionData
+	seen := make(map[string]bool)
+	add := func(vs []*SchemaVersionData) {
+		for _, v := range vs {
+			if !seen[v.Convert] {
+				seen[v.Convert] = true
+				versions = append(versions, v)
+			}
+		}
+	}
+	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
+		return r.IterateActions(func(a *design.ActionDefinition) error {
+			add(payloadVersions(a))
+			return a.IterateResponses(func(resp *design.ResponseDefinition) error {
+				if len(resp.Versions) == 0 {
+					return nil
+				}
+				mt, ok := resp.Type.(*design.MediaTypeDefinition)
+				if !ok {
+					mt = g.API.MediaTypeWithIdentifier(resp.MediaType)
+				}
+				if mt == nil {
+					return nil
+				}
+				views := []string{resp.ViewName}
+				if resp.ViewName == "" {
+					views = make([]string, 0, len(mt.Views))
+					for name := range mt.Views {
+						views = append(views, name)
+					}
+					sort.Strings(views)
+				}
+				for _, view := range views {
+					projected, _, err := mt.Project(view)
+					if err != nil {
+						return err
+					}
+					add(responseVersions(resp, projected))
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil || len(versions) == 0 {
+		return
+	}
+
+	var (
+		versionsFile string
+		versionsWr   *SchemaVersionsWriter
+	)
+	{
+		versionsFile = filepath.Join(g.OutDir, "schema_versions.go")
+		versionsWr, err = NewSchemaVersionsWriter(versionsFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		versionsWr.Close()
+		if err == nil {
+			err = versionsWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Schema Versions", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = versionsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, versionsFile)
+	err = versionsWr.Execute(versions)
+	return
+}
+
+// payloadVersions returns the data used to generate the code that decodes the schema revisions
+// of the action payload and converts them into the payload type.
+func payloadVersions(a *design.ActionDefinition) []*SchemaVersionData {
+	if len(a.PayloadVersions) == 0 || a.Payload == nil {
+		return nil
+	}
+	to := codegen.GoTypeName(a.Payload, nil, 1, false)
+	res := make([]*SchemaVersionData, len(a.PayloadVersions))
+	for i, v := range a.PayloadVersions {
+		from := codegen.GoTypeName(v.Type, v.Type.AllRequired(), 0, false)
+		res[i] = &SchemaVersionData{
+			Version: v.Version,
+			Type:    v.Type,
+			Convert: "Convert" + from + "To" + to,
+			From:    codegen.GoTypeRef(v.Type, v.Type.AllRequired(), 0, false),
+			To:      codegen.GoTypeRef(a.Payload, a.Payload.AllRequired(), 0, false),
+			ToName:  to,
+		}
+	}
+	return res
+}
+
+// responseVersions returns the data used to generate the code that converts the given projected
+// response media type into the schema revisions of the response.
+func responseVersions(r *design.ResponseDefinition, projected *design.MediaTypeDefinition) []*SchemaVersionData {
+	if len(r.Versions) == 0 {
+		return nil
+	}
+	from := codegen.GoTypeName(projected, projected.AllRequired(), 0, false)
+	res := make([]*SchemaVersionData, len(r.Versions))
+	for i, v := range r.Versions {
+		to := codegen.GoTypeName(v.Type, v.Type.AllRequired(), 0, false)
+		res[i] = &SchemaVersionData{
+			Version: v.Version,
+			Type:    v.Type,
+			Convert: "Convert" + from + "To" + to,
+			From:    codegen.GoTypeRef(projected, projected.AllRequired(), 0, false),
+			To:      codegen.GoTypeRef(v.Type, v.Type.AllRequired(), 0, false),
+			ToName:  to,
+		}
+	}
+	return res
+}
+
+// responseHeaderGroups returns the header groups defined by the responses of the action sorted
+// by name, the groups shared by multiple responses are only returned once.
+func responseHeaderGroups(a *design.ActionDefinition) []*design.HeaderGroupDefinition {
+	var groups []*design.HeaderGroupDefinition
+	seen := make(map[string]bool)
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		for _, g := range r.HeaderGroups {
+			if !seen[g.Name] {
+				seen[g.Name] = true
+				groups = append(groups, g)
+			}
+		}
+		return nil
+	})
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
 // generateMediaTypes iterates through the media types and generate the data structures and
 // marshaling code.
 func (g *Generator) generateMediaTypes() (err error) {
@@ -445,6 +1237,7 @@ func (g *Generator) generateMediaTypes() (err error) {
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -491,7 +1284,9 @@ func (g *Generator) generateUserTypes() (err error) {
 	}()
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("mime/multipart"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
@@ -499,7 +1294,7 @@ func (g *Generator) generateUserTypes() (err error) {
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
 	for _, v := range g.API.Types {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
+		imports = codegen.AttributeImports(&design.AttributeDefinition{Type: v}, imports, nil)
 	}
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
@@ -510,3 +1305,258 @@ func (g *Generator) generateUserTypes() (err error) {
 	})
 	return
 }
+
+// generateTypes generates the type registry that maps the names of the user types and media type
+// views to their Go types and JSON schemas.
+func (g *Generator) generateTypes() (err error) {
+	genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	schema := func(t design.DataType) string {
+		s := genschema.StandaloneSchema(g.API, t)
+		if s == nil {
+			return ""
+		}
+		b, err := s.JSON()
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	var types []*TypeData
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() || !(mt.Type.IsObject() || mt.Type.IsArray()) {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			types = append(types, &TypeData{
+				Name:       p.TypeName,
+				GoType:     codegen.GoTypeName(p, p.AllRequired(), 0, false),
+				Identifier: mt.Identifier,
+				View:       view.Name,
+				Schema:     schema(p),
+			})
+			return nil
+		})
+	})
+	g.API.IterateUserTypes(func(t *design.UserTypeDefinition) error {
+		types = append(types, &TypeData{
+			Name:   t.TypeName,
+			GoType: codegen.GoTypeName(t, t.AllRequired(), 0, false),
+			Schema: schema(t),
+		})
+		return nil
+	})
+	if len(types) == 0 {
+		return nil
+	}
+
+	var (
+		typesFile string
+		typesWr   *TypesWriter
+	)
+	{
+		typesFile = filepath.Join(g.OutDir, "types.go")
+		typesWr, err = NewTypesWriter(typesFile)
+		if err != nil {
+			return
+		}
+	}
+	defer func() {
+		typesWr.Close()
+		if err == nil {
+			err = typesWr.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Type Registry", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("reflect"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	for _, v := range g.API.Types {
+		imports = codegen.AttributeImports(&design.AttributeDefinition{Type: v}, imports, nil)
+	}
+	if err = typesWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, typesFile)
+	err = typesWr.Execute(g.API.Name, types)
+	return
+}
+
+// signedFiles returns the data used to generate the functions that sign the URLs of the resource
+// file servers that require signed URLs.
+func signedFiles(r *design.ResourceDefinition) []*SignedFileTemplateData {
+	var files []*SignedFileTemplateData
+	for _, fs := range r.FileServers {
+		if !fs.Signed {
+			continue
+		}
+		path := fs.RequestPath
+		var wildcard string
+		if m := design.WildcardRegex.FindStringSubmatch(path); m != nil {
+			path = design.WildcardRegex.ReplaceAllLiteralString(path, "") + "/"
+			wildcard = codegen.Goify(m[1], false)
+		}
+		files = append(files, &SignedFileTemplateData{
+			Func:     fmt.Sprintf("Sign%s%sURL", codegen.Goify(r.Name, true), codegen.Goify(path, true)),
+			Path:     path,
+			Wildcard: wildcard,
+		})
+	}
+	return files
+}
+
+// tenantField returns the name of the action context field that holds the tenant identifier,
+// the empty string if the action does not bind one.
+func tenantField(a *design.ActionDefinition) string {
+	if a.Tenant == nil {
+		return ""
+	}
+	return codegen.GoifyAtt(a.Tenant.Attribute, a.Tenant.Name, true)
+}
+
+// logSamplerCode returns the Go expression of the sampler given to the request logging
+// middleware for the given sampling rate, the empty string if the requests are not logged.
+func logSamplerCode(rate *int) string {
+	switch {
+	case rate == nil || *rate <= 0:
+		return ""
+	case *rate >= 100:
+		return "nil"
+	}
+	return fmt.Sprintf("middleware.NewFixedSampler(%d)", *rate)
+}
+
+// faultResponse returns the name of the context method that sends the internal server error
+// response of the action rendered with the error media type, the empty string if the action does
+// not define one.
+func faultResponse(a *design.ActionDefinition) string {
+	names := make([]string, 0, len(a.Responses))
+	for name := range a.Responses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resp := a.Responses[name]
+		if resp.Status != 500 || resp.MediaType != design.ErrorMediaIdentifier {
+			continue
+		}
+		if _, ok := resp.Type.(*design.MediaTypeDefinition); resp.Type != nil && !ok {
+			continue
+		}
+		if resp.ViewName == "" || resp.ViewName == design.DefaultView {
+			return codegen.Goify(resp.Name, true)
+		}
+	}
+	return ""
+}
+
+// deprecationCode returns the Go expressions of the deprecation and sunset dates given to the
+// middleware that sets the deprecation headers, nil if the action is not deprecated.
+func deprecationCode(d *design.DeprecationDefinition) map[string]string {
+	if d == nil {
+		return nil
+	}
+	return map[string]string{"Since": timeCode(d.Since), "Sunset": timeCode(d.Sunset)}
+}
+
+// middlewareFields returns the names of the fields of the resource middleware struct that hold
+// the given middleware in the order they wrap the action handler: the last declared first so
+// that the first declared runs first.
+func middlewareFields(names []string) []string {
+	fields := make([]string, len(names))
+	for i, n := range names {
+		fields[len(names)-1-i] = codegen.Goify(n, true)
+	}
+	return fields
+}
+
+// flagName returns the name of the feature flag, an empty string if f is nil.
+func flagName(f *design.FlagDefinition) string {
+	if f == nil {
+		return ""
+	}
+	return f.Name
+}
+
+// concurrencyCode returns the Go expressions of the arguments given to the concurrency limiting
+// middleware, nil if the action has no concurrency limit.
+func concurrencyCode(c *design.ConcurrencyDefinition) map[string]string {
+	if c == nil {
+		return nil
+	}
+	timeout := durationCode(c.Timeout)
+	if timeout == "" {
+		timeout = "0"
+	}
+	return map[string]string{"Limit": strconv.Itoa(c.Limit), "Timeout": timeout}
+}
+
+// errorEnvelopeCode returns the Go expression of the goa.ErrorEnvelope corresponding to the given
+// definition, the empty string if there is none.
+func errorEnvelopeCode(env *design.ErrorEnvelopeDefinition) string {
+	if env == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("&goa.ErrorEnvelope{\n")
+	if env.Wrap != "" {
+		fmt.Fprintf(&buf, "\t\tWrap: %q,\n", env.Wrap)
+	}
+	if len(env.Fields) > 0 {
+		names := make([]string, 0, len(env.Fields))
+		for n := range env.Fields {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		buf.WriteString("\t\tFields: map[string]string{\n")
+		for _, n := range names {
+			fmt.Fprintf(&buf, "\t\t\t%q: %q,\n", n, env.Fields[n])
+		}
+		buf.WriteString("\t\t},\n")
+	}
+	if len(env.Static) > 0 {
+		keys := make([]string, 0, len(env.Static))
+		for k := range env.Static {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteString("\t\tStatic: map[string]interface{}{\n")
+		for _, k := range keys {
+			fmt.Fprintf(&buf, "\t\t\t%q: %#v,\n", k, env.Static[k])
+		}
+		buf.WriteString("\t\t},\n")
+	}
+	buf.WriteString("\t}")
+	return buf.String()
+}
+
+// timeCode returns the Go expression of the given time.
+func timeCode(t time.Time) string {
+	if t.IsZero() {
+		return "time.Time{}"
+	}
+	t = t.UTC()
+	return fmt.Sprintf("time.Date(%d, time.%s, %d, %d, %d, %d, 0, time.UTC)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+// durationCode returns the Go expression of the given duration using the largest unit that
+// divides it, the empty string if the duration is zero.
+func durationCode(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	for _, u := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Hour, "Hour"}, {time.Minute, "Minute"}, {time.Second, "Second"}, {time.Millisecond, "Millisecond"}} {
+		if d%u.d == 0 {
+			return fmt.Sprintf("%d * time.%s", d/u.d, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
@@ -2,71 +2,1381 @@ package genapp_test
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_app"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
+		codegen.TempCount = 0
+	})
+
+	JustBeforeEach(func() {
+		design.GeneratedMediaTypes = make(design.MediaTypeRoot)
+		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+		files, genErr = genapp.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+		delete(codegen.Reserved, "app")
+	})
+
+	Context("with a dummy API", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:        "test api",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+			}
+		})
+
+		It("generates correct empty files", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(6))
+			isEmptySource := func(filename string) {
+				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", filename))
+				Ω(err).ShouldNot(HaveOccurred())
+				lines := strings.Split(string(contextsContent), "\n")
+				Ω(lines).ShouldNot(BeEmpty())
+				Ω(len(lines)).Should(BeNumerically(">", 1))
+			}
+			isEmptySource("contexts.go")
+			isEmptySource("controllers.go")
+			isEmptySource("hrefs.go")
+			isEmptySource("media_types.go")
+		})
+	})
+
+	Context("with a JSON-RPC endpoint", func() {
+		BeforeEach(func() {
+			o := design.Object{
+				"ids": &design.AttributeDefinition{
+					Type:     &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}},
+					Metadata: dslengine.MetadataDefinition{"param:style": {design.PipeDelimitedStyle}},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "test api",
+				JSONRPCPath: "/rpc",
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:        "list",
+								Routes:      []*design.RouteDefinition{{Verb: "GET", Path: "/foos"}},
+								QueryParams: &design.AttributeDefinition{Type: o},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("generates the code mounting the endpoint", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "jsonrpc.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "jsonrpc.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(jsonrpcCode))
+		})
+	})
+
+	Context("with servers defining health checks", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Servers: []*design.ServerDefinition{
+					{Name: "dev", Host: "localhost:8080", HealthCheck: "/healthz"},
+					{Name: "prod", Host: "example.com", HealthCheck: "/healthz"},
+					{Name: "legacy", Host: "legacy.example.com", HealthCheck: "/ping"},
+				},
+			}
+		})
+
+		It("generates the code mounting the health check endpoints", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "health.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "health.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(healthCode))
+		})
+	})
+
+	Context("with error classes", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Responses: map[string]*design.ResponseDefinition{
+					"not_found":    {Name: "not_found", Status: 404, MediaType: design.ErrorMediaIdentifier},
+					"rate_limited": {Name: "rate_limited", Status: 429, MediaType: design.ErrorMediaIdentifier},
+				},
+				ErrorClasses: []string{"rate_limited", "not_found"},
+			}
+		})
+
+		It("generates the error classes", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "errors.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "errors.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(errorClassesCode))
+		})
+	})
+
+	Context("with a media type generating diff functions", func() {
+		BeforeEach(func() {
+			att := &design.AttributeDefinition{
+				Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+			}
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: att,
+					TypeName:            "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+				Diff:       true,
+				Views: map[string]*design.ViewDefinition{
+					"default": {AttributeDefinition: att, Name: "default"},
+					"tiny":    {AttributeDefinition: att, Name: "tiny"},
+				},
+			}
+			mt.Views["default"].Parent = mt
+			mt.Views["tiny"].Parent = mt
+			design.Design = &design.APIDefinition{
+				Name:       "test api",
+				MediaTypes: map[string]*design.MediaTypeDefinition{"application/vnd.bottle": mt},
+			}
+		})
+
+		It("generates a diff function for each view", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "media_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`func DiffBottle(a, b *Bottle) []string {
+	return goa.Diff(a, b)
+}`))
+			Ω(string(content)).Should(ContainSubstring("func DiffBottleTiny(a, b *BottleTiny) []string {"))
+		})
+
+		It("generates a fake data factory for each view", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "factory", "factory.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("package factory"))
+			Ω(string(content)).Should(ContainSubstring("func NewBottle(mutators ...func(*app.Bottle)) *app.Bottle {"))
+			Ω(string(content)).Should(ContainSubstring("func NewBottleTiny(mutators ...func(*app.BottleTiny)) *app.BottleTiny {"))
+			Ω(string(content)).Should(MatchRegexp(`decode\("\{\\"name\\":\\"[^"]+\\"\}", &v\)`))
+		})
+	})
+
+	Context("with schema versions", func() {
+		BeforeEach(func() {
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"color": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "CreatePayload",
+			}
+			payloadV1 := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type:       design.Object{"colour": &design.AttributeDefinition{Type: design.String}},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"colour"}},
+				},
+				TypeName: "BottlePayloadV1",
+			}
+			bottleV1 := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"colour": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "BottleV1",
+			}
+			att := &design.AttributeDefinition{
+				Type: design.Object{"color": &design.AttributeDefinition{Type: design.String}},
+			}
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: att,
+					TypeName:            "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+				Views:      map[string]*design.ViewDefinition{"default": {AttributeDefinition: att, Name: "default"}},
+			}
+			mt.Views["default"].Parent = mt
+			design.Design = &design.APIDefinition{
+				Name:       "test api",
+				Types:      map[string]*design.UserTypeDefinition{"BottlePayloadV1": payloadV1, "BottleV1": bottleV1},
+				MediaTypes: map[string]*design.MediaTypeDefinition{"application/vnd.bottle": mt},
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:                "create",
+								Routes:              []*design.RouteDefinition{{Verb: "POST", Path: "/bottles"}},
+								Params:              &design.AttributeDefinition{Type: design.Object{}},
+								Payload:             payload,
+								SchemaVersionHeader: "X-Schema-Version",
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			createAct := bottleRes.Actions["create"]
+			createAct.Parent = bottleRes
+			createAct.Routes[0].Parent = createAct
+			createAct.PayloadVersions = []*design.SchemaVersionDefinition{{Version: "1", Type: payloadV1, Parent: createAct}}
+			resp := &design.ResponseDefinition{Name: "OK", Status: 200, Type: mt, Parent: createAct}
+			resp.Versions = []*design.SchemaVersionDefinition{{Version: "1", Type: bottleV1, Parent: resp}}
+			createAct.Responses = map[string]*design.ResponseDefinition{"OK": resp}
+		})
+
+		It("decodes the payload revisions and converts the response revisions", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "schema_versions.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("var ConvertBottlePayloadV1ToCreatePayload = func(v *BottlePayloadV1) (*CreatePayload, error) {"))
+			Ω(string(content)).Should(ContainSubstring("var ConvertBottleToBottleV1 = func(v *Bottle) (*BottleV1, error) {"))
+			Ω(string(content)).Should(ContainSubstring("goa.ConvertSchema(v, &res)"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`switch goa.RequestSchemaVersion(req, "X-Schema-Version") {`))
+			Ω(string(content)).Should(ContainSubstring("v := &bottlePayloadV1{}"))
+			Ω(string(content)).Should(ContainSubstring("payload, err := ConvertBottlePayloadV1ToCreatePayload(v.Publicize())"))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`switch goa.ResponseSchemaVersion(ctx.RequestData.Request, "X-Schema-Version") {`))
+			Ω(string(content)).Should(ContainSubstring("v, err := ConvertBottleToBottleV1(r)"))
+		})
+	})
+
+	Context("with validations", func() {
+		BeforeEach(func() {
+			min, max := 1.0, 10.0
+			maxLength := 20
+			bottle := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name": &design.AttributeDefinition{
+							Type:       design.String,
+							Validation: &dslengine.ValidationDefinition{Pattern: "^[a-z]+$", MaxLength: &maxLength},
+						},
+						"rating": &design.AttributeDefinition{
+							Type:       design.Integer,
+							Validation: &dslengine.ValidationDefinition{Minimum: &min, Maximum: &max},
+						},
+						"color": &design.AttributeDefinition{
+							Type:       design.String,
+							Validation: &dslengine.ValidationDefinition{Values: []interface{}{"red", "white"}},
+						},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+				},
+				TypeName: "Bottle",
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "test api",
+				Types: map[string]*design.UserTypeDefinition{"Bottle": bottle},
+			}
+		})
+
+		It("generates the validation rules", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "validation_rules.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("var ValidationRules = map[string]*goa.ValidationRules{"))
+			Ω(string(content)).Should(ContainSubstring(`Required: []string{"name"},`))
+			Ω(string(content)).Should(ContainSubstring(`&goa.AttributeRules{Type: "string", Enum: []interface{}{"red", "white"}},`))
+			Ω(string(content)).Should(ContainSubstring(`&goa.AttributeRules{Type: "string", Pattern: "^[a-z]+$", MaxLength: intRef(20)},`))
+			Ω(string(content)).Should(ContainSubstring(`&goa.AttributeRules{Type: "integer", Minimum: float64Ref(1), Maximum: float64Ref(10)},`))
+		})
+	})
+
+	Context("with an action defining sanitizers", func() {
+		BeforeEach(func() {
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"email": &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim", "lowercase"}},
+					},
+				},
+				TypeName: "SignupPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"user": {
+						Name: "user",
+						Actions: map[string]*design.ActionDefinition{
+							"signup": {
+								Name:    "signup",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/users"}},
+								Payload: payload,
+								Headers: &design.AttributeDefinition{Type: design.Object{
+									"X-Referrer": &design.AttributeDefinition{Type: design.String, Sanitizers: []string{"trim"}},
+								}},
+							},
+						},
+					},
+				},
+			}
+			userRes := design.Design.Resources["user"]
+			signupAct := userRes.Actions["signup"]
+			signupAct.Parent = userRes
+			signupAct.Routes[0].Parent = signupAct
+		})
+
+		It("sanitizes the payload and headers before validating them", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`rawXReferrer = goa.Sanitize(rawXReferrer, "trim")`))
+			Ω(string(content)).Should(ContainSubstring(`func (payload *signupPayload) Sanitize() {
+	if payload.Email != nil {
+		*payload.Email = goa.Sanitize(*payload.Email, "trim", "lowercase")
+	}
+}`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("payload.Sanitize()"))
+		})
+	})
+
+	Context("with nil collections encoded as empty", func() {
+		BeforeEach(func() {
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"tags": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+					},
+				},
+				TypeName: "TagPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:           "test api",
+				NilCollections: design.NilCollectionsEmpty,
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"tag": {
+								Name:    "tag",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/bottles/tags"}},
+								Payload: payload,
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			tagAct := bottleRes.Actions["tag"]
+			tagAct.Parent = bottleRes
+			tagAct.Routes[0].Parent = tagAct
+		})
+
+		It("fills the nil collections of the decoded payload", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`	goa.FillNilCollections(payload)
+	goa.ContextRequest(ctx).Payload = payload.Publicize()`))
+		})
+	})
+
+	Context("with sensitive attributes", func() {
+		BeforeEach(func() {
+			credentials := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"login":    &design.AttributeDefinition{Type: design.String},
+						"password": &design.AttributeDefinition{Type: design.String, Sensitive: true},
+					},
+				},
+				TypeName: "Credentials",
+			}
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"credentials": &design.AttributeDefinition{Type: credentials},
+						"pin":         &design.AttributeDefinition{Type: design.String, Sensitive: true},
+					},
+				},
+				TypeName: "LoginSessionPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "test api",
+				Types: map[string]*design.UserTypeDefinition{"Credentials": credentials},
+				Resources: map[string]*design.ResourceDefinition{
+					"session": {
+						Name: "session",
+						Actions: map[string]*design.ActionDefinition{
+							"login": {
+								Name:    "login",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/sessions"}},
+								Payload: payload,
+								Headers: &design.AttributeDefinition{Type: design.Object{
+									"X-Token": &design.AttributeDefinition{Type: design.String, Sensitive: true},
+								}},
+							},
+						},
+					},
+				},
+			}
+			sessionRes := design.Design.Resources["session"]
+			loginAct := sessionRes.Actions["login"]
+			loginAct.Parent = sessionRes
+			loginAct.Routes[0].Parent = loginAct
+		})
+
+		It("registers the sensitive attributes with the types that define them", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`goa.RegisterSensitive("X-Token")`))
+			Ω(string(content)).Should(ContainSubstring(`goa.RegisterSensitiveFields((*Credentials)(nil), "password")`))
+			Ω(string(content)).Should(ContainSubstring(`goa.RegisterSensitiveFields((*LoginSessionPayload)(nil), "pin")`))
+		})
+	})
+
+	Context("with an async action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:       "test api",
+				JobsPath:   "/operations",
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.JobMediaIdentifier: design.JobMedia},
+				Resources: map[string]*design.ResourceDefinition{
+					"report": {
+						Name: "report",
+						Actions: map[string]*design.ActionDefinition{
+							"export": {
+								Name:   "export",
+								Async:  true,
+								Routes: []*design.RouteDefinition{{Verb: "POST", Path: "/reports"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Responses: map[string]*design.ResponseDefinition{
+									design.Accepted: {
+										Name:              design.Accepted,
+										Status:            202,
+										MediaType:         design.JobMediaIdentifier,
+										LocationAttribute: "href",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			reportRes := design.Design.Resources["report"]
+			exportAct := reportRes.Actions["export"]
+			exportAct.Parent = reportRes
+			exportAct.Routes[0].Parent = exportAct
+			exportAct.Responses[design.Accepted].Parent = exportAct
+		})
+
+		It("generates the job status endpoint and the AcceptedJob method", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "jobs.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("type JobStore interface"))
+			Ω(string(content)).Should(ContainSubstring(`return "/operations/" + url.PathEscape(id)`))
+			Ω(string(content)).Should(ContainSubstring(`service.Mux.Handle("GET", "/operations/:jobID"`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (ctx *ExportReportContext) AcceptedJob(id string) error {"))
+		})
+	})
+
+	Context("with a resumable action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"upload": {
+						Name: "upload",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:      "create",
+								Resumable: true,
+								Routes:    []*design.RouteDefinition{{Verb: "POST", Path: "/uploads"}},
+								Params:    &design.AttributeDefinition{Type: design.Object{}},
+								Responses: map[string]*design.ResponseDefinition{
+									design.Created: {Name: design.Created, Status: 201},
+								},
+							},
+						},
+					},
+				},
+			}
+			uploadRes := design.Design.Resources["upload"]
+			createAct := uploadRes.Actions["create"]
+			createAct.Parent = uploadRes
+			createAct.Routes[0].Parent = createAct
+			createAct.Responses[design.Created].Parent = createAct
+		})
+
+		It("generates the upload offset endpoints and the CreatedUpload method", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "uploads.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func MountCreateUploadUpload(service *goa.Service, store goa.UploadStore) {"))
+			Ω(string(content)).Should(ContainSubstring(`service.MountUpload("/uploads/:uploadID", store)`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (ctx *CreateUploadContext) CreatedUpload(id string) error {"))
+			Ω(string(content)).Should(ContainSubstring(`ctx.ResponseData.Header().Set("Tus-Resumable", goa.TusResumable)`))
+		})
+	})
+
+	Context("with an action with a batch endpoint", func() {
+		BeforeEach(func() {
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "CreateBottlePayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:       "create",
+								Routes:     []*design.RouteDefinition{{Verb: "POST", Path: "/bottles"}},
+								BatchRoute: &design.RouteDefinition{Verb: "POST", Path: "/bottles/batch"},
+								Params:     &design.AttributeDefinition{Type: design.Object{}},
+								Payload:    payload,
+								Security: &design.SecurityDefinition{
+									Scheme: &design.SecuritySchemeDefinition{SchemeName: "jwt", Kind: design.JWTSecurityKind},
+									Scopes: []string{"bottle:write"},
+								},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			createAct := bottleRes.Actions["create"]
+			createAct.Parent = bottleRes
+			createAct.Routes[0].Parent = createAct
+			createAct.BatchRoute.Parent = createAct
+		})
+
+		It("mounts the batch endpoint", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`h = service.BatchHandler("POST", "/bottles")`))
+			Ω(string(content)).Should(ContainSubstring(`service.Mux.Handle("POST", "/bottles/batch", ctrl.MuxHandler("create batch", h, nil))`))
+		})
+
+		It("lists the action and batch routes in the route table", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "routes.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`{Method: "POST", Pattern: "/bottles", Service: "test api", Resource: "bottle", Endpoint: "create", Scheme: "jwt", Scopes: []string{"bottle:write"}},`))
+			Ω(string(content)).Should(ContainSubstring(`{Method: "POST", Pattern: "/bottles/batch", Service: "test api", Resource: "bottle", Endpoint: "create batch", Scheme: "jwt", Scopes: []string{"bottle:write"}},`))
+			Ω(string(content)).Should(ContainSubstring("func MountRoutes(service *goa.Service, path string) {"))
+		})
+	})
+
+	Context("with an action reporting client closed requests", func() {
+		BeforeEach(func() {
+			enabled := true
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"export": {
+								Name:               "export",
+								Routes:             []*design.RouteDefinition{{Verb: "GET", Path: "/export"}},
+								Params:             &design.AttributeDefinition{Type: design.Object{}},
+								ClientClosedStatus: &enabled,
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			exportAct := bottleRes.Actions["export"]
+			exportAct.Parent = bottleRes
+			exportAct.Routes[0].Parent = exportAct
+		})
+
+		It("wraps the handler to log the 499 status", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("h = goa.ClientClosedHandler(h)"))
+		})
+
+		It("generates the streaming helper of the context", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (ctx *ExportBottleContext) WriteChunk(b []byte) error {"))
+		})
+	})
+
+	Context("with a cookie security scheme", func() {
+		BeforeEach(func() {
+			sessionType := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"user_id": &design.AttributeDefinition{Type: design.Integer}},
+				},
+				TypeName: "SessionData",
+			}
+			scheme := &design.SecuritySchemeDefinition{
+				SchemeName:  "session",
+				Kind:        design.CookieSecurityKind,
+				Type:        "apiKey",
+				In:          "cookie",
+				Name:        "sid",
+				SessionType: sessionType,
+				Encrypted:   true,
+			}
+			design.Design = &design.APIDefinition{
+				Name:            "test api",
+				Types:           map[string]*design.UserTypeDefinition{"SessionData": sessionType},
+				SecuritySchemes: []*design.SecuritySchemeDefinition{scheme},
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:     "list",
+								Routes:   []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params:   &design.AttributeDefinition{Type: design.Object{}},
+								Security: &design.SecurityDefinition{Scheme: scheme},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("generates the typed session helpers", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "security.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func NewSessionSecurity() *goa.CookieSecurity {"))
+			Ω(string(content)).Should(ContainSubstring(`Name:      "sid",`))
+			Ω(string(content)).Should(ContainSubstring("Encrypted: true,"))
+			Ω(string(content)).Should(ContainSubstring("func NewSessionMiddleware(keys cookieauth.KeyProvider) goa.Middleware {"))
+			Ω(string(content)).Should(ContainSubstring("return &SessionData{}"))
+			Ω(string(content)).Should(ContainSubstring("func ContextSessionSession(ctx context.Context) *SessionData {"))
+			Ω(string(content)).Should(ContainSubstring("func SetSessionCookie(ctx context.Context, rw http.ResponseWriter, keys cookieauth.KeyProvider, s *SessionData, maxAge time.Duration) error {"))
+			Ω(string(content)).Should(ContainSubstring("func ClearSessionCookie(rw http.ResponseWriter) {"))
+		})
+	})
+
+	Context("with a SPIFFE security scheme", func() {
+		BeforeEach(func() {
+			scheme := &design.SecuritySchemeDefinition{
+				SchemeName:  "workload",
+				Kind:        design.SPIFFESecurityKind,
+				Type:        "apiKey",
+				TrustDomain: "example.org",
+				Scopes:      map[string]string{"bottle:read": "Read bottles"},
+				Identities:  map[string][]string{"spiffe://example.org/ns/prod/sa/frontend": {"bottle:read"}},
+			}
+			design.Design = &design.APIDefinition{
+				Name:            "test api",
+				SecuritySchemes: []*design.SecuritySchemeDefinition{scheme},
+			}
+		})
+
+		It("generates the scheme and the middleware constructor", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "security.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func NewWorkloadSecurity() *goa.SPIFFESecurity {"))
+			Ω(string(content)).Should(ContainSubstring(`TrustDomain: "example.org",`))
+			Ω(string(content)).Should(ContainSubstring(`"spiffe://example.org/ns/prod/sa/frontend": {"bottle:read"},`))
+			Ω(string(content)).Should(ContainSubstring("return spiffeauth.New(NewWorkloadSecurity())"))
+		})
+	})
+
+	Context("with an action with a priority", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Priority: &design.PriorityDefinition{
+									Header:     "X-Priority",
+									Thresholds: map[string]float64{"low": 0.7, "high": 1},
+									Attribute:  &design.AttributeDefinition{Type: design.String},
+								},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("wraps the action handler with the shedding middleware", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`h = middleware.Shed(middleware.DefaultLoadSignal, middleware.DefaultShedRetryAfter, "X-Priority", "low", map[string]float64{"low": 0.7, "high": 1})(h)`))
+		})
+	})
+
+	Context("with an action with a concurrency limit", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"report": {
+						Name: "report",
+						Actions: map[string]*design.ActionDefinition{
+							"export": {
+								Name:        "export",
+								Routes:      []*design.RouteDefinition{{Verb: "GET", Path: "/reports/export"}},
+								Params:      &design.AttributeDefinition{Type: design.Object{}},
+								Concurrency: &design.ConcurrencyDefinition{Limit: 4, Timeout: 2 * time.Second},
+							},
+						},
+					},
+				},
+			}
+			reportRes := design.Design.Resources["report"]
+			exportAct := reportRes.Actions["export"]
+			exportAct.Parent = reportRes
+			exportAct.Routes[0].Parent = exportAct
+		})
+
+		It("wraps the action handler with the concurrency limiting middleware", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("h = middleware.LimitConcurrency(4, 2*time.Second)(h)"))
+		})
+	})
+
+	Context("with a recorded action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"update": {
+								Name:     "update",
+								Routes:   []*design.RouteDefinition{{Verb: "PATCH", Path: "/bottles/:id"}},
+								Params:   &design.AttributeDefinition{Type: design.Object{}},
+								Recorded: true,
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			updateAct := bottleRes.Actions["update"]
+			updateAct.Parent = bottleRes
+			updateAct.Routes[0].Parent = updateAct
+		})
+
+		It("wraps the action handler with the recording middleware", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`h = middleware.Record(middleware.DefaultRecordSink, "bottle", "update")(h)`))
+		})
+	})
+
+	Context("with a proxied action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200},
+								},
+							},
+							"show": {
+								Name:     "show",
+								Routes:   []*design.RouteDefinition{{Verb: "GET", Path: "/bottles/:id"}},
+								Params:   &design.AttributeDefinition{Type: design.Object{"id": &design.AttributeDefinition{Type: design.String}}},
+								ProxyURL: "http://upstream.example.com",
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200},
+								},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			for _, a := range bottleRes.Actions {
+				a.Parent = bottleRes
+				a.Routes[0].Parent = a
+				a.Responses["OK"].Parent = a
+			}
+		})
+
+		It("does not generate test helpers for the proxied action", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "bottle_testing.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func ListBottleOK("))
+			Ω(string(content)).ShouldNot(ContainSubstring("ShowBottle"))
+		})
+
+		It("generates code that compiles", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(build(outDir)).Should(Succeed())
+		})
+	})
+
+	Context("with an error envelope", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				ErrorEnvelope: &design.ErrorEnvelopeDefinition{
+					Wrap:   "error",
+					Fields: map[string]string{"detail": "message"},
+					Static: map[string]interface{}{"api_version": "2"},
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles/:id"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			showAct := bottleRes.Actions["show"]
+			showAct.Parent = bottleRes
+			showAct.Routes[0].Parent = showAct
+		})
 
-	"github.com/goadesign/goa/design"
-	"github.com/goadesign/goa/dslengine"
-	"github.com/goadesign/goa/goagen/codegen"
-	"github.com/goadesign/goa/goagen/gen_app"
-	"github.com/goadesign/goa/version"
-	. "github.com/onsi/ginkgo"
-	. "github.com/onsi/gomega"
-)
+		It("sets the service error encoder with the envelope", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`Wrap: "error",`))
+			Ω(string(content)).Should(ContainSubstring(`"detail": "message",`))
+			Ω(string(content)).Should(ContainSubstring(`"api_version": "2",`))
+			Ω(string(content)).Should(ContainSubstring("service.ErrorEncoder = envelope.Encoder(service)"))
+		})
+	})
 
-var _ = Describe("Generate", func() {
-	var workspace *codegen.Workspace
-	var outDir string
-	var files []string
-	var genErr error
+	Context("with a dependency injection framework", func() {
+		var framework string
 
-	BeforeEach(func() {
-		var err error
-		workspace, err = codegen.NewWorkspace("test")
-		Ω(err).ShouldNot(HaveOccurred())
-		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
-		Ω(err).ShouldNot(HaveOccurred())
-		os.Args = []string{"goagen", "--out=" + outDir, "--design=foo", "--version=" + version.String()}
-		codegen.TempCount = 0
+		BeforeEach(func() {
+			framework = "wire"
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles/:id"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			showAct := bottleRes.Actions["show"]
+			showAct.Parent = bottleRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		JustBeforeEach(func() {
+			os.Args = append(os.Args, "--di="+framework)
+			files, genErr = genapp.Generate()
+		})
+
+		It("generates the wire provider set", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "wire.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`var ProviderSet = wire.NewSet(NewService, wire.Struct(new(Controllers), "*"), MountControllers, NewServer)`))
+			Ω(string(content)).Should(ContainSubstring("Bottle BottleController"))
+			Ω(string(content)).Should(ContainSubstring("MountBottleController(service, ctrls.Bottle)"))
+			Ω(string(content)).Should(ContainSubstring(`return goa.New("test api")`))
+		})
+
+		Context("using fx", func() {
+			BeforeEach(func() {
+				framework = "fx"
+			})
+
+			It("generates the fx module", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "fx.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("var Module = fx.Options(fx.Provide(NewService, MountControllers, NewServer))"))
+				Ω(string(content)).Should(ContainSubstring("fx.In"))
+			})
+		})
+
+		Context("using an unknown framework", func() {
+			BeforeEach(func() {
+				framework = "dig"
+			})
+
+			It("fails", func() {
+				Ω(genErr).Should(HaveOccurred())
+				Ω(genErr.Error()).Should(ContainSubstring(`unsupported dependency injection framework "dig"`))
+			})
+		})
 	})
 
-	JustBeforeEach(func() {
-		design.GeneratedMediaTypes = make(design.MediaTypeRoot)
-		design.ProjectedMediaTypes = make(design.MediaTypeRoot)
-		files, genErr = genapp.Generate()
+	Context("with a multipart/mixed response", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"batch": {
+								Name:   "batch",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles/batch"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			batchAct := bottleRes.Actions["batch"]
+			batchAct.Parent = bottleRes
+			batchAct.Routes[0].Parent = batchAct
+			resp := &design.ResponseDefinition{Name: "OK", Status: 200, MediaType: "multipart/mixed", Parent: batchAct}
+			resp.Parts = []*design.PartDefinition{
+				{Name: "total", Type: design.Integer, Parent: resp},
+				{Name: "label", MediaType: "image/png", Parent: resp},
+			}
+			batchAct.Responses = map[string]*design.ResponseDefinition{"OK": resp}
+		})
+
+		It("generates the parts writer", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func (ctx *BatchBottleContext) OK() *BatchBottleOKParts {"))
+			Ω(string(content)).Should(ContainSubstring("func (w *BatchBottleOKParts) WriteTotal(v int) error {"))
+			Ω(string(content)).Should(ContainSubstring(`return w.WritePart("label", "image/png", b)`))
+		})
 	})
 
-	AfterEach(func() {
-		workspace.Delete()
-		delete(codegen.Reserved, "app")
+	Context("with header groups", func() {
+		BeforeEach(func() {
+			rateLimit := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"limit":     {Type: design.Integer},
+						"remaining": {Type: design.Integer},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"limit"}},
+				},
+				TypeName: "RateLimitInfo",
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "test api",
+				Types: map[string]*design.UserTypeDefinition{"RateLimitInfo": rateLimit},
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+			listAct.HeaderGroups = []*design.HeaderGroupDefinition{
+				{Name: "quota", Prefix: "X-Quota-", Type: rateLimit, Parent: listAct},
+			}
+			resp := &design.ResponseDefinition{Name: "NoContent", Status: 204, Parent: listAct}
+			resp.HeaderGroups = []*design.HeaderGroupDefinition{
+				{Name: "rate_limit", Prefix: "X-RateLimit-", Type: rateLimit, Parent: resp},
+			}
+			listAct.Responses = map[string]*design.ResponseDefinition{"NoContent": resp}
+		})
+
+		It("maps the headers to the group types", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "header_groups.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("func GetQuotaHeaders(h http.Header) (*RateLimitInfo, error) {"))
+			Ω(string(content)).Should(ContainSubstring(`err = goa.MergeErrors(err, goa.MissingHeaderError("X-Quota-Limit"))`))
+			Ω(string(content)).Should(ContainSubstring(`h.Set("X-Ratelimit-Remaining", strconv.Itoa(*v.Remaining))`))
+			content, err = ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("Quota *RateLimitInfo"))
+			Ω(string(content)).Should(ContainSubstring("GetQuotaHeaders(req.Header)"))
+			Ω(string(content)).Should(ContainSubstring("func (ctx *ListBottleContext) SetRateLimitHeaders(v *RateLimitInfo) {"))
+		})
 	})
 
-	Context("with a dummy API", func() {
+	Context("with query objects", func() {
 		BeforeEach(func() {
+			filter := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"color": {Type: design.String},
+						"min":   {Type: design.Number},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"color"}},
+				},
+				TypeName: "BottleFilter",
+			}
 			design.Design = &design.APIDefinition{
-				Name:        "test api",
-				Title:       "dummy API with no resource",
-				Description: "I told you it's dummy",
+				Name:  "test api",
+				Types: map[string]*design.UserTypeDefinition{"BottleFilter": filter},
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+			listAct.QueryObjects = []*design.QueryObjectDefinition{
+				{Name: "filter", Type: filter, Parent: listAct},
 			}
 		})
 
-		It("generates correct empty files", func() {
+		It("decodes and validates the objects in the context", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(6))
-			isEmptySource := func(filename string) {
-				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", filename))
-				Ω(err).ShouldNot(HaveOccurred())
-				lines := strings.Split(string(contextsContent), "\n")
-				Ω(lines).ShouldNot(BeEmpty())
-				Ω(len(lines)).Should(BeNumerically(">", 1))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "contexts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("Filter *BottleFilter"))
+			Ω(string(content)).Should(ContainSubstring(`goa.DecodeDeepObject(req.Params, "filter", &v)`))
+			Ω(string(content)).Should(ContainSubstring("if err2 := v.Validate(); err2 != nil {"))
+			Ω(string(content)).Should(ContainSubstring("rctx.Filter = &v"))
+		})
+	})
+
+	Context("with actions using headers", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Headers: &design.AttributeDefinition{Type: design.Object{
+									"requestID": {Type: design.String, Key: "x-request-id"},
+									"X-Since":   {Type: design.DateTime},
+								}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:   "OK",
+										Status: 200,
+										Headers: &design.AttributeDefinition{Type: design.Object{
+											"Retry-After": {Type: design.Integer},
+											"X-Since":     {Type: design.String},
+										}},
+									},
+								},
+							},
+						},
+					},
+				},
 			}
-			isEmptySource("contexts.go")
-			isEmptySource("controllers.go")
-			isEmptySource("hrefs.go")
-			isEmptySource("media_types.go")
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+			listAct.Responses["OK"].Parent = listAct
+		})
+
+		It("generates the header name constants and typed accessors", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "headers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`HeaderXRequestID = "X-Request-Id"`))
+			Ω(string(content)).Should(ContainSubstring(`HeaderRetryAfter = "Retry-After"`))
+			Ω(string(content)).Should(ContainSubstring(`HeaderXSince = "X-Since"`))
+			Ω(string(content)).Should(ContainSubstring("func GetHeaderXRequestID(h http.Header) (string, error) {"))
+			Ω(string(content)).Should(ContainSubstring("func GetHeaderRetryAfter(h http.Header) (int, error) {"))
+			Ω(string(content)).Should(ContainSubstring("func SetHeaderRetryAfter(h http.Header, v int) {"))
+			Ω(string(content)).ShouldNot(ContainSubstring("func GetHeaderXSince"))
+		})
+	})
+
+	Context("with a deprecated action", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Deprecation: &design.DeprecationDefinition{
+									Since:  time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC),
+									Sunset: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+								},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("wraps the action handler with the deprecation middleware", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("h = middleware.Deprecated(time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC))(h)"))
+		})
+	})
+
+	Context("with an action gated behind a feature flag", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name: "bottle",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params: &design.AttributeDefinition{Type: design.Object{}},
+								Flag:   &design.FlagDefinition{Name: "bottles", Status: 403},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("wraps the action handler with the flag middleware", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`h = middleware.Flag(middleware.DefaultFlagProvider, "bottles", 403)(h)`))
+		})
+
+		It("lists the flag in the route table", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "routes.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`Endpoint: "list", Flag: "bottles"}`))
+		})
+	})
+
+	Context("with middleware declared in the design", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"bottle": {
+						Name:       "bottle",
+						Middleware: []string{"tracing"},
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:       "list",
+								Routes:     []*design.RouteDefinition{{Verb: "GET", Path: "/bottles"}},
+								Params:     &design.AttributeDefinition{Type: design.Object{}},
+								Middleware: []string{"tracing", "rate-limit"},
+							},
+						},
+					},
+				},
+			}
+			bottleRes := design.Design.Resources["bottle"]
+			listAct := bottleRes.Actions["list"]
+			listAct.Parent = bottleRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("mounts the controller with the middleware implementations", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`type BottleMiddleware struct {
+	// Tracing implements the "tracing" middleware.
+	Tracing goa.Middleware
+	// RateLimit implements the "rate-limit" middleware.
+	RateLimit goa.Middleware
+}`))
+			Ω(string(content)).Should(ContainSubstring("func MountBottleController(service *goa.Service, ctrl BottleController, mw BottleMiddleware) {"))
+			Ω(string(content)).Should(ContainSubstring(`	h = mw.RateLimit(h)
+	h = mw.Tracing(h)
+`))
+		})
+
+		It("lists the middleware in the route table", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "routes.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`Endpoint: "list", Middleware: []string{"tracing", "rate-limit"}}`))
+		})
+	})
+
+	Context("with a type defined in another package", func() {
+		BeforeEach(func() {
+			money := &design.UserTypeDefinition{
+				TypeName: "Money",
+				AttributeDefinition: &design.AttributeDefinition{
+					Type:     design.Object{"amount": &design.AttributeDefinition{Type: design.Integer}},
+					Metadata: dslengine.MetadataDefinition{"struct:pkg:path": {"github.com/acme/types"}},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:  "test api",
+				Types: map[string]*design.UserTypeDefinition{"Money": money},
+			}
+		})
+
+		It("references the type instead of generating it", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "user_types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"github.com/acme/types"`))
+			Ω(string(content)).Should(ContainSubstring("func (ut *money) Publicize() *types.Money {"))
+			Ω(string(content)).Should(ContainSubstring("func DecodeMoney(decoder *goa.HTTPDecoder, body io.Reader, contentType string) (*types.Money, error) {"))
+			Ω(string(content)).ShouldNot(ContainSubstring("type Money struct"))
+		})
+
+		It("registers the type in the type registry", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "types.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "types.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`"Money": {`))
+			Ω(string(content)).Should(ContainSubstring(`Service: "test api",`))
+			Ω(string(content)).Should(ContainSubstring("Type:    reflect.TypeOf((*types.Money)(nil)).Elem(),"))
+			Ω(string(content)).Should(ContainSubstring(`Schema:  json.RawMessage("{\"$schema\":\"http://json-schema.org/draft-04/hyper-schema\",\"title\":\"Money\"`))
+		})
+	})
+
+	Context("with an event", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "test api",
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"created": {
+								Name:   "created",
+								Routes: []*design.RouteDefinition{{Verb: "POST", Path: "/foos/created"}},
+								Topic:  "foo.created",
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createdAct := fooRes.Actions["created"]
+			createdAct.Parent = fooRes
+			createdAct.Routes[0].Parent = createdAct
+		})
+
+		It("generates the event consumers", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "app", "events.go")))
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "events.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(eventsCode))
 		})
 	})
 
@@ -191,7 +1501,7 @@ var _ = Describe("Generate", func() {
 
 			It("generates the corresponding code", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(8))
+				Ω(files).Should(HaveLen(11))
 
 				isSource("contexts.go", contextsCode)
 				isSource("controllers.go", controllersCode)
@@ -222,6 +1532,40 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("with a payload with maximum collection lengths", func() {
+			BeforeEach(func() {
+				maxItems, maxTags := 10, 5
+				tags := &design.AttributeDefinition{
+					Type:       &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}},
+					Validation: &dslengine.ValidationDefinition{MaxLength: &maxTags},
+					Metadata:   dslengine.MetadataDefinition{"struct:tag:json": []string{"labels", "omitempty"}},
+				}
+				item := &design.AttributeDefinition{Type: design.Object{"tags": tags}}
+				payload = &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"name": &design.AttributeDefinition{Type: design.String},
+							"items": &design.AttributeDefinition{
+								Type:       &design.Array{ElemType: item},
+								Validation: &dslengine.ValidationDefinition{MaxLength: &maxItems},
+							},
+						},
+					},
+					TypeName: "Order",
+				}
+				design.Design.Resources["Widget"].Actions["get"].Payload = payload
+				runCodeTemplates(map[string]string{"outDir": outDir, "design": "foo", "tmpDir": filepath.Base(outDir), "version": version.String()})
+			})
+
+			It("enforces the limits while decoding the request body", func() {
+				Ω(genErr).Should(BeNil())
+
+				contextsContent, err := ioutil.ReadFile(filepath.Join(outDir, "app", "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(contextsContent)).Should(ContainSubstring(controllersLimitedPayloadCode))
+			})
+		})
+
 		Context("with a optional payload", func() {
 			BeforeEach(func() {
 				elemType := &design.AttributeDefinition{Type: design.Integer}
@@ -355,6 +1699,20 @@ func NewGetWidgetContext(ctx context.Context, r *http.Request, service *goa.Serv
 	return &rctx, err
 }
 
+// WriteChunk writes b to the response and flushes it so that the client receives it right away. It
+// returns goa.ErrClientClosedRequest without writing once the client closed the connection, the
+// handlers that stream the response should stop encoding when it does.
+func (ctx *GetWidgetContext) WriteChunk(b []byte) error {
+	return goa.StreamWrite(ctx.Context, b)
+}
+
+// CanonicalHash returns a stable digest of the request computed from its method, path, params
+// sorted by name and value and from the canonical JSON encoding of its payload, see
+// goa.CanonicalHash.
+func (ctx *GetWidgetContext) CanonicalHash() (string, error) {
+	return goa.CanonicalHash(ctx.Request.Method, ctx.Request.URL.Path, ctx.Params, nil)
+}
+
 // OK sends a HTTP response with status code 200.
 func (ctx *GetWidgetContext) OK(r ID) error {
 	if ctx.ResponseData.Header().Get("Content-Type") == "" {
@@ -378,6 +1736,7 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"github.com/goadesign/goa"
 	"net/http"
 )
@@ -400,7 +1759,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -410,11 +1769,31 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		return ctrl.Get(rctx)
 	}
 	service.Mux.Handle("GET", "/:id", ctrl.MuxHandler("get", h, nil))
 	service.LogInfo("mount", "ctrl", "Widget", "action", "Get", "route", "GET /:id")
 }
+
+// SetWidgetErrorEncoder overrides the encoding of the errors returned by the given
+// Widget actions, by all the Widget actions if none is given. It panics if an
+// action does not belong to the Widget resource.
+func SetWidgetErrorEncoder(ctrl *goa.Controller, enc goa.ErrorEncoder, actions ...string) {
+	if len(actions) == 0 {
+		ctrl.ErrorEncoder = enc
+		return
+	}
+	for _, action := range actions {
+		switch action {
+		case "get":
+			ctrl.SetErrorEncoder(action, enc)
+		default:
+			panic(fmt.Sprintf("unknown Widget action %q", action))
+		}
+	}
+}
 `
 
 const hrefsCodeTmpl = `// Code generated by goagen {{.version}}, DO NOT EDIT.
@@ -454,13 +1833,26 @@ const mediaTypesCodeTmpl = `// Code generated by goagen {{ .version }}, DO NOT E
 package app
 `
 
+const controllersLimitedPayloadCode = `
+// unmarshalGetWidgetPayloadLimits lists the maximum lengths of the payload collections enforced while
+// decoding the request body.
+var unmarshalGetWidgetPayloadLimits = &goa.JSONLimits{Fields: map[string]*goa.JSONLimits{"items": &goa.JSONLimits{MaxLength: 10, Elem: &goa.JSONLimits{Fields: map[string]*goa.JSONLimits{"labels": &goa.JSONLimits{MaxLength: 5}}}}}}
+
+// unmarshalGetWidgetPayload unmarshals the request body into the context request data Payload field.
+func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *http.Request) error {
+	payload := &order{}
+	if err := service.DecodeLimitedRequest(req, payload, unmarshalGetWidgetPayloadLimits); err != nil {
+		return err
+	}
+`
+
 const controllersSlicePayloadCode = `
 // MountWidgetController "mounts" a Widget resource controller on the given service.
 func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -470,6 +1862,8 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.(Collection)
@@ -499,7 +1893,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -509,6 +1903,8 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.(Collection)
@@ -536,7 +1932,7 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -546,6 +1942,8 @@ func MountWidgetController(service *goa.Service, ctrl WidgetController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.(*Collection)
@@ -583,3 +1981,47 @@ func unmarshalGetWidgetPayload(ctx context.Context, service *goa.Service, req *h
 	return nil
 }
 `
+
+const errorClassesCode = `// ErrRateLimited creates the errors of the "rate_limited" class, they are rendered with
+// status code 429.
+var ErrRateLimited = goa.NewErrorClass("rate_limited", 429)
+
+// ErrNotFound creates the errors of the "not_found" class, they are rendered with
+// status code 404.
+var ErrNotFound = goa.NewErrorClass("not_found", 404)
+`
+
+const healthCode = `// MountHealthChecks mounts the health check endpoints of the API servers onto the service.
+func MountHealthChecks(service *goa.Service) {
+	service.MountHealthCheck("/healthz")
+	service.MountHealthCheck("/ping")
+}
+`
+
+const jsonrpcCode = `// MountJSONRPC mounts the JSON-RPC 2.0 endpoint onto the service at "/rpc".
+// The endpoint dispatches the calls to the handlers of the mounted controllers.
+func MountJSONRPC(service *goa.Service) {
+	service.MountJSONRPC("/rpc", []*goa.JSONRPCMethod{
+		{Name: "foo.list", Method: "GET", Path: "/foos", Separators: map[string]string{"ids": "|"}},
+	})
+	service.LogInfo("mount", "jsonrpc", "/rpc")
+}
+`
+
+const eventsCode = `func ConsumeEvents(ctx context.Context, service *goa.Service, sub goa.Subscriber, group string) error {
+	return service.ConsumeEvents(ctx, sub, group, []*goa.EventMethod{
+		{Topic: "foo.created", Method: "POST", Path: "/foos/created"},
+	})
+}
+`
+
+// build compiles the Go packages generated in dir.
+func build(dir string) error {
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", err, out)
+	}
+	return nil
+}
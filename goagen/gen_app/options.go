@@ -32,3 +32,10 @@ func NoTest(noTest bool) Option {
 		g.NoTest = noTest
 	}
 }
+
+//DI Dependency injection framework to generate the providers for, "wire" or "fx"
+func DI(framework string) Option {
+	return func(g *Generator) {
+		g.DI = framework
+	}
+}
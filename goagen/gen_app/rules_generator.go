@@ -0,0 +1,213 @@
+package genapp
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// RulesData describes an entry of the generated ValidationRules variable.
+type RulesData struct {
+	// TypeName is the name of the Go type in the app package.
+	TypeName string
+	// Code is the Go literal of the goa.ValidationRules value.
+	Code string
+}
+
+// generateValidationRules generates the ValidationRules variable that describes the validations
+// of the payloads, media type views and user types so that they are available at runtime.
+func (g *Generator) generateValidationRules() (err error) {
+	rules := make(map[string]string)
+	add := func(name string, att *design.AttributeDefinition) {
+		if _, ok := rules[name]; ok || !att.Type.IsObject() {
+			return
+		}
+		rules[name] = validationRulesCode(att)
+	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload == nil {
+				return nil
+			}
+			if path, _ := codegen.TypePackage(a.Payload); path == "" {
+				add(codegen.GoTypeName(a.Payload, nil, 1, false), a.Payload.AttributeDefinition)
+			}
+			return nil
+		})
+	})
+	g.API.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if mt.IsError() {
+			return nil
+		}
+		return mt.IterateViews(func(view *design.ViewDefinition) error {
+			p, _, err := mt.Project(view.Name)
+			if err != nil {
+				return err
+			}
+			add(codegen.GoTypeName(p, nil, 0, false), p.AttributeDefinition)
+			return nil
+		})
+	})
+	g.API.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if path, _ := codegen.TypePackage(ut); path == "" {
+			add(codegen.GoTypeName(ut, nil, 0, false), ut.AttributeDefinition)
+		}
+		return nil
+	})
+	if len(rules) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(rules))
+	for n := range rules {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	data := make([]*RulesData, len(names))
+	for i, n := range names {
+		data[i] = &RulesData{TypeName: n, Code: rules[n]}
+	}
+
+	rulesFile := filepath.Join(g.OutDir, "validation_rules.go")
+	file, err := codegen.SourceFileFor(rulesFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Application Validation Rules", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, rulesFile)
+	tmpl := template.Must(template.New("rules").Funcs(codegen.DefaultFuncMap).Parse(rulesT))
+	return tmpl.Execute(file, data)
+}
+
+// validationRulesCode returns the Go literal of the goa.ValidationRules value that describes the
+// validations of the given object attribute.
+func validationRulesCode(att *design.AttributeDefinition) string {
+	obj := att.Type.ToObject()
+	keys := func(names []string) []string {
+		res := make([]string, len(names))
+		for i, n := range names {
+			res[i] = n
+			if a, ok := obj[n]; ok {
+				res[i] = a.AttributeKey(n)
+			}
+		}
+		return res
+	}
+	var b bytes.Buffer
+	b.WriteString("&goa.ValidationRules{\n")
+	if v := att.Validation; v != nil {
+		if len(v.Required) > 0 {
+			fmt.Fprintf(&b, "Required: %#v,\n", keys(v.Required))
+		}
+		groups := func(field string, gs [][]string) {
+			if len(gs) == 0 {
+				return
+			}
+			fmt.Fprintf(&b, "%s: [][]string{\n", field)
+			for _, grp := range gs {
+				fmt.Fprintf(&b, "%#v,\n", keys(grp))
+			}
+			b.WriteString("},\n")
+		}
+		groups("RequiredTogether", v.RequiredTogether)
+		groups("MutuallyExclusive", v.MutuallyExclusive)
+		groups("AtLeastOneOf", v.AtLeastOneOf)
+	}
+	if len(obj) > 0 {
+		b.WriteString("Attributes: map[string]*goa.AttributeRules{\n")
+		obj.IterateAttributes(func(n string, a *design.AttributeDefinition) error {
+			fmt.Fprintf(&b, "%q: %s,\n", a.AttributeKey(n), attributeRulesCode(a))
+			return nil
+		})
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// attributeRulesCode returns the Go literal of the goa.AttributeRules value that describes the
+// validations of the given attribute. The attributes of user types and media types are described
+// by the rules listed under the name of their Go type.
+func attributeRulesCode(att *design.AttributeDefinition) string {
+	var b bytes.Buffer
+	typeName := att.Type.Name()
+	switch att.Type.(type) {
+	case *design.UserTypeDefinition, *design.MediaTypeDefinition:
+		typeName = codegen.GoTypeName(att.Type, nil, 0, false)
+	}
+	fmt.Fprintf(&b, "&goa.AttributeRules{Type: %q", typeName)
+	if v := att.Validation; v != nil {
+		if len(v.Values) > 0 {
+			b.WriteString(", Enum: []interface{}{")
+			for i, val := range v.Values {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprintf(&b, "%#v", val)
+			}
+			b.WriteString("}")
+		}
+		if v.Format != "" {
+			fmt.Fprintf(&b, ", Format: %q", v.Format)
+		}
+		if v.Pattern != "" {
+			fmt.Fprintf(&b, ", Pattern: %q", v.Pattern)
+		}
+		bound := func(field string, f *float64) {
+			if f != nil {
+				fmt.Fprintf(&b, ", %s: float64Ref(%s)", field, strconv.FormatFloat(*f, 'g', -1, 64))
+			}
+		}
+		bound("Minimum", v.Minimum)
+		bound("Maximum", v.Maximum)
+		bound("ExclusiveMinimum", v.ExclusiveMinimum)
+		bound("ExclusiveMaximum", v.ExclusiveMaximum)
+		bound("MultipleOf", v.MultipleOf)
+		if v.MinLength != nil {
+			fmt.Fprintf(&b, ", MinLength: intRef(%d)", *v.MinLength)
+		}
+		if v.MaxLength != nil {
+			fmt.Fprintf(&b, ", MaxLength: intRef(%d)", *v.MaxLength)
+		}
+	}
+	switch actual := att.Type.(type) {
+	case *design.Array:
+		fmt.Fprintf(&b, ", Elem: %s", attributeRulesCode(actual.ElemType))
+	case *design.Hash:
+		fmt.Fprintf(&b, ", Elem: %s", attributeRulesCode(actual.ElemType))
+	case design.Object:
+		fmt.Fprintf(&b, ", Object: %s", validationRulesCode(att))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+const rulesT = `// ValidationRules maps the names of the payload, media type and user types to the descriptions of
+// the validations performed by their Validate methods.
+var ValidationRules = map[string]*goa.ValidationRules{
+{{ range . }}	{{ printf "%q" .TypeName }}: {{ .Code }},
+{{ end }}}
+
+// float64Ref returns a pointer to v.
+func float64Ref(v float64) *float64 { return &v }
+
+// intRef returns a pointer to v.
+func intRef(v int) *int { return &v }
+`
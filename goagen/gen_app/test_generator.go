@@ -98,6 +98,14 @@ func (g *Generator) generateResourceTest() error {
 		codegen.SimpleImport("context"),
 		codegen.NewImport("uuid", "github.com/satori/go.uuid"),
 	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Payload != nil {
+				imports = codegen.AttributeImports(&design.AttributeDefinition{Type: a.Payload}, imports, nil)
+			}
+			return nil
+		})
+	})
 
 	return g.API.IterateResources(func(res *design.ResourceDefinition) (err error) {
 		filename := filepath.Join(outDir, codegen.SnakeCase(res.Name)+"_testing.go")
@@ -120,6 +128,10 @@ func (g *Generator) generateResourceTest() error {
 		var methods []*TestMethod
 
 		if err = res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.ProxyURL != "" {
+				// Proxied actions are not implemented by the controllers.
+				return nil
+			}
 			if err := action.IterateResponses(func(response *design.ResponseDefinition) error {
 				if response.Status == 101 { // SwitchingProtocols, Don't currently handle WebSocket endpoints
 					return nil
@@ -211,7 +223,11 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 	if action.Payload != nil {
 		payload = &ObjectType{}
 		payload.Name = "payload"
-		payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
+		if path, _ := codegen.TypePackage(action.Payload); path != "" {
+			payload.Type = codegen.GoTypeName(action.Payload, nil, 0, false)
+		} else {
+			payload.Type = fmt.Sprintf("%s.%s", g.Target, codegen.Goify(action.Payload.TypeName, true))
+		}
 		if !action.Payload.IsPrimitive() && !action.Payload.IsArray() && !action.Payload.IsHash() {
 			payload.Pointer = "*"
 		}
@@ -245,6 +261,9 @@ func (g *Generator) createTestMethod(resource *design.ResourceDefinition, action
 
 // pathParams returns the path params for the given action and route.
 func pathParams(action *design.ActionDefinition, route *design.RouteDefinition) []*ObjectType {
+	if action.Params == nil {
+		return nil
+	}
 	return paramFromNames(action, route.Params())
 }
 
@@ -292,6 +311,9 @@ func queryParams(action *design.ActionDefinition) []*ObjectType {
 }
 
 func paramFromNames(action *design.ActionDefinition, names []string) (params []*ObjectType) {
+	if action.Params == nil {
+		return nil
+	}
 	obj := action.Params.Type.ToObject()
 	for _, name := range names {
 		params = append(params, attToObject(name, action.Params, obj[name]))
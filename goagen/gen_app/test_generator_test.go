@@ -185,7 +185,7 @@ var _ = Describe("Generate", func() {
 
 		It("does not call Validate on the resulting media type when it does not exist", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(16))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -194,7 +194,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the ActionRouteResponse test methods ", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(8))
+			Ω(files).Should(HaveLen(16))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 
@@ -257,6 +257,31 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring(", payload app.CustomName)"))
 		})
 
+		It("generates the request decoder fuzz targets", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "fuzz_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(string(content)).Should(HavePrefix("//go:build go1.18\n// +build go1.18\n"))
+			Ω(content).Should(ContainSubstring("func FuzzUnmarshalGetFooPayload(f *testing.F) {"))
+			Ω(content).Should(ContainSubstring("unmarshalGetFooPayload(ctx, service, req)"))
+			Ω(content).Should(ContainSubstring("func FuzzNewShowFooContext(f *testing.F) {"))
+			Ω(content).Should(ContainSubstring(`params.Set("required", p3)`))
+			Ω(content).Should(ContainSubstring("NewShowFooContext(ctx, req, service)"))
+		})
+
+		It("generates the encoding benchmarks", func() {
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "benchmarks_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(content).Should(ContainSubstring("func BenchmarkUnmarshalGetFooPayload(b *testing.B) {"))
+			Ω(content).Should(ContainSubstring("func BenchmarkEncodeIntContainer(b *testing.B) {"))
+			Ω(content).Should(ContainSubstring("var v *IntContainer"))
+			Ω(content).ShouldNot(ContainSubstring("BenchmarkEncodeError"))
+			Ω(content).Should(ContainSubstring("func BenchmarkNewShowFooContext(b *testing.B) {"))
+			Ω(content).Should(ContainSubstring(`"required": []string{`))
+			Ω(content).Should(ContainSubstring("NewShowFooContext(ctx, req, service)"))
+		})
+
 		It("generates header compliant with https://github.com/golang/go/issues/13560", func() {
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "app", "test", "foo_testing.go"))
 			Ω(err).ShouldNot(HaveOccurred())
@@ -25,6 +25,7 @@ type (
 		CtxRespTmpl *template.Template
 		PayloadTmpl *template.Template
 		Finalizer   *codegen.Finalizer
+		Sanitizer   *codegen.Sanitizer
 		Validator   *codegen.Validator
 	}
 
@@ -37,6 +38,7 @@ type (
 		MountTmpl   *template.Template
 		handleCORST *template.Template
 		Finalizer   *codegen.Finalizer
+		Sanitizer   *codegen.Sanitizer
 		Validator   *codegen.Validator
 	}
 
@@ -68,6 +70,7 @@ type (
 		*codegen.SourceFile
 		UserTypeTmpl *template.Template
 		Finalizer    *codegen.Finalizer
+		Sanitizer    *codegen.Sanitizer
 		Validator    *codegen.Validator
 	}
 
@@ -85,6 +88,30 @@ type (
 		API          *design.APIDefinition
 		DefaultPkg   string
 		Security     *design.SecurityDefinition
+		ViewParam    string // Name of the param that selects the response view if any
+		ViewHeader   string // Name of the header that selects the response view if any
+		FieldMask    string // Name of the param that lists the response fields if any
+		Tenant       string // Name of the context field holding the tenant identifier if any
+		Async        bool   // Whether the action responds with the status of the job it starts
+		Resumable    bool   // Whether the action responds with the location of the upload it creates
+		// HeaderGroups lists the request header groups exposed in the context fields.
+		HeaderGroups []*HeaderGroupData
+		// QueryObjects lists the query objects exposed in the context fields.
+		QueryObjects []*QueryObjectData
+		// ResponseHeaderGroups lists the response header groups set by the context methods.
+		ResponseHeaderGroups []*HeaderGroupData
+		// SchemaVersionHeader is the name of the header that selects the schema revision of
+		// the response bodies if any.
+		SchemaVersionHeader string
+	}
+
+	// PartData contains the information required to generate the code that writes and reads a
+	// part of a multipart/mixed response.
+	PartData struct {
+		Name        string // Name of the part in the design
+		FieldName   string // Name of the part in the generated code
+		GoType      string // Go type of the JSON encoded content, empty for binary parts
+		ContentType string // Content type of the binary parts
 	}
 
 	// ControllerTemplateData contains the information required to generate an action handler.
@@ -97,6 +124,189 @@ type (
 		Decoders       []*EncoderTemplateData         // Decoder data
 		Origins        []*design.CORSDefinition       // CORS policies
 		PreflightPaths []string
+		CSRF           bool                      // Whether the actions are protected against CSRF
+		CSRFTokenPath  string                    // Full path of the CSRF token endpoint if any
+		SignedFiles    []*SignedFileTemplateData // File servers that require signed URLs
+		Middleware     []string                  // Names of the middleware used by the resource and its actions
+	}
+
+	// SignedFileTemplateData contains the information required to generate the function that
+	// signs the URLs of a file server.
+	SignedFileTemplateData struct {
+		Func     string // Name of the function, e.g. "SignBottleDownloadsURL"
+		Path     string // Request path without the wildcard, e.g. "/downloads/"
+		Wildcard string // Name of the variable holding the wildcard value if any, e.g. "filepath"
+	}
+
+	// JSONRPCWriter generate code for the JSON-RPC endpoint.
+	JSONRPCWriter struct {
+		*codegen.SourceFile
+	}
+
+	// JSONRPCMethodData contains the information required to expose an action on the JSON-RPC
+	// endpoint.
+	JSONRPCMethodData struct {
+		Name       string            // Name of the JSON-RPC method
+		Verb       string            // HTTP method of the action route
+		Path       string            // Full path of the action route
+		Separators map[string]string // Separators of the array query string parameters
+	}
+
+	// UploadsWriter generate code for the upload offset endpoints of the resumable actions.
+	UploadsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// UploadData describes the upload offset endpoints of a resumable action.
+	UploadData struct {
+		Name         string // Name of the function that mounts the endpoints
+		ResourceName string // Name of the resource that defines the action
+		ActionName   string // Name of the action
+		Path         string // Path of the endpoints
+	}
+
+	// JobsWriter generate code for the job status endpoint of the asynchronous actions.
+	JobsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// HealthWriter generate code for the health check endpoints.
+	HealthWriter struct {
+		*codegen.SourceFile
+	}
+
+	// ErrorClassesWriter generate code for the error classes.
+	ErrorClassesWriter struct {
+		*codegen.SourceFile
+	}
+
+	// EventsWriter generate code for the event consumers.
+	EventsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// EventData contains the information required to consume the messages of an event.
+	EventData struct {
+		Topic string // Name of the topic
+		Verb  string // HTTP method of the action route
+		Path  string // Full path of the action route
+	}
+
+	// RoutesWriter generate code for the route table.
+	RoutesWriter struct {
+		*codegen.SourceFile
+	}
+
+	// RouteData contains the information listed in the route table for a mounted route.
+	RouteData struct {
+		Verb       string                     // HTTP method of the route
+		Path       string                     // Full path of the route
+		Resource   string                     // Name of the resource
+		Endpoint   string                     // Name of the handler given to MuxHandler
+		Security   *design.SecurityDefinition // Security requirements of the route if any
+		Flag       string                     // Name of the feature flag gating the route if any
+		Middleware []string                   // Names of the middleware applied to the route in order
+	}
+
+	// TypesWriter generate code for the type registry.
+	TypesWriter struct {
+		*codegen.SourceFile
+	}
+
+	// TypeData contains the information listed in the type registry for a generated type.
+	TypeData struct {
+		Name       string // Name of the type in the design
+		GoType     string // Name of the generated Go type
+		Identifier string // Identifier of the media type if any
+		View       string // Name of the media type view if any
+		Schema     string // Standalone JSON schema of the type
+	}
+
+	// ProvidersWriter generate code for the dependency injection providers.
+	ProvidersWriter struct {
+		*codegen.SourceFile
+	}
+
+	// HeadersWriter generate code for the header name constants and accessors.
+	HeadersWriter struct {
+		*codegen.SourceFile
+	}
+
+	// SchemaVersionsWriter generate code for the functions that convert the schema revisions of
+	// the payloads and responses.
+	SchemaVersionsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// SchemaVersionData contains the information required to generate the code that converts a
+	// schema revision of a payload or of a response body.
+	SchemaVersionData struct {
+		Version string                     // Value that selects the revision, e.g. "1"
+		Type    *design.UserTypeDefinition // Type of the body in this revision
+		Convert string                     // Name of the conversion function variable
+		From    string                     // Reference to the Go type converted from
+		To      string                     // Reference to the Go type converted to
+		ToName  string                     // Name of the Go type converted to
+	}
+
+	// HeaderGroupsWriter generate code for the functions that map header groups to user types.
+	HeaderGroupsWriter struct {
+		*codegen.SourceFile
+	}
+
+	// HeaderGroupData contains the information required to generate the code that maps a group
+	// of headers to a user type.
+	HeaderGroupData struct {
+		Name     string                  // Go name of the group, e.g. RateLimit
+		Group    string                  // Name of the group in the design, e.g. rate_limit
+		Prefix   string                  // Prefix of the header names, e.g. X-RateLimit-
+		TypeName string                  // Name of the Go type, e.g. RateLimitInfo
+		TypeRef  string                  // Reference to the Go type, e.g. *RateLimitInfo
+		Validate bool                    // Whether the type defines a Validate method
+		Fields   []*HeaderGroupFieldData // Headers of the group sorted by name
+	}
+
+	// HeaderGroupFieldData contains the information required to map a header to a field of
+	// the type of a header group.
+	HeaderGroupFieldData struct {
+		Field    string // Name of the struct field
+		Key      string // Canonical name of the header
+		TypeName string // Name of the design type used in the errors
+		Parse    string // Expression parsing raw, empty for strings
+		Format   string // Expression formatting the field value
+		Pointer  bool   // Whether the field is a pointer
+		Required bool   // Whether the header is required
+		Default  string // Go code of the default value if any
+	}
+
+	// QueryObjectData contains the information required to generate the code that binds a
+	// user type to the query string.
+	QueryObjectData struct {
+		Name     string // Go name of the object, e.g. Filter
+		VarName  string // Name of the client method argument, e.g. filter
+		Key      string // Prefix of the query string parameter names, e.g. filter
+		TypeName string // Name of the Go type, e.g. BottleFilter
+		TypeRef  string // Reference to the Go type, e.g. *BottleFilter
+		Validate bool   // Whether the type defines a Validate method
+	}
+
+	// TrailerData contains the information required to map a trailer to a field of a media
+	// type.
+	TrailerData struct {
+		Key      string // Canonical name of the trailer
+		Field    string // Name of the struct field
+		TypeName string // Name of the design type used in the errors
+		Parse    string // Expression parsing raw, empty for strings
+		Format   string // Expression formatting the field value of r
+		Pointer  bool   // Whether the field is a pointer
+	}
+
+	// HeaderData contains the information required to generate the constant and the accessors
+	// of a header.
+	HeaderData struct {
+		Name string          // Go name of the header, e.g. XRequestID
+		Key  string          // Canonical name of the header, e.g. X-Request-Id
+		Type design.DataType // Primitive type of the header value, nil if there are no accessors
 	}
 
 	// ResourceData contains the information required to generate the resource GoGenerator
@@ -172,6 +382,15 @@ func (c *ContextTemplateData) MustValidate(name string) bool {
 	return c.Params.IsRequired(name) && !c.IsPathParam(name)
 }
 
+// NilCollections returns the encoding of the nil slices and maps of the API response bodies, see
+// design.APIDefinition.
+func (c *ContextTemplateData) NilCollections() string {
+	if c.API == nil {
+		return ""
+	}
+	return c.API.NilCollections
+}
+
 // IterateResponses iterates through the responses sorted by status code.
 func (c *ContextTemplateData) IterateResponses(it func(*design.ResponseDefinition) error) error {
 	m := make(map[int]*design.ResponseDefinition, len(c.Responses))
@@ -200,6 +419,7 @@ func NewContextsWriter(filename string) (*ContextsWriter, error) {
 	return &ContextsWriter{
 		SourceFile: file,
 		Finalizer:  codegen.NewFinalizer(),
+		Sanitizer:  codegen.NewSanitizer(),
 		Validator:  codegen.NewValidator(),
 	}, nil
 }
@@ -215,10 +435,20 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		"printVal":           codegen.PrintVal,
 		"canonicalHeaderKey": http.CanonicalHeaderKey,
 		"isPathParam":        data.IsPathParam,
+		"validationCode":     w.Validator.Code,
 	}
 	if err := w.ExecuteTemplate("new", ctxNewT, fn, data); err != nil {
 		return err
 	}
+	if err := w.ExecuteTemplate("stream", ctxStreamT, nil, data); err != nil {
+		return err
+	}
+	if err := w.ExecuteTemplate("canonicalHash", ctxCanonicalHashT, nil, data); err != nil {
+		return err
+	}
+	if err := w.ExecuteTemplate("headerGroups", ctxHeaderGroupsT, nil, data); err != nil {
+		return err
+	}
 	if data.Payload != nil {
 		found := false
 		for _, t := range design.Design.Types {
@@ -230,6 +460,7 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		if !found {
 			fn := template.FuncMap{
 				"finalizeCode":   w.Finalizer.Code,
+				"sanitizeCode":   w.Sanitizer.Code,
 				"validationCode": w.Validator.Code,
 			}
 			if err := w.ExecuteTemplate("payload", payloadT, fn, data); err != nil {
@@ -237,23 +468,42 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 			}
 		}
 	}
-	return data.IterateResponses(func(resp *design.ResponseDefinition) error {
+	err := data.IterateResponses(func(resp *design.ResponseDefinition) error {
 		respData := map[string]interface{}{
 			"Context":  data,
 			"Response": resp,
 		}
+		if len(resp.Parts) > 0 {
+			parts, err := ResponseParts(resp)
+			if err != nil {
+				return err
+			}
+			respData["Parts"] = parts
+			return w.ExecuteTemplate("response", ctxPartsRespT, nil, respData)
+		}
 		var mt *design.MediaTypeDefinition
 		if resp.Type != nil {
 			var ok bool
 			if mt, ok = resp.Type.(*design.MediaTypeDefinition); !ok {
 				respData["Type"] = resp.Type
 				respData["ContentType"] = resp.MediaType
+				if p, ok := resp.Type.(design.Primitive); ok {
+					respData["JSON"], respData["Finite"] = jsonAppender(p)
+					if respData["JSON"] != "" && resp.MediaType == "" {
+						respData["ContentType"] = "application/json"
+					}
+				}
 				return w.ExecuteTemplate("response", ctxTRespT, nil, respData)
 			}
 		} else {
 			mt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
 		}
 		if mt != nil {
+			if data.FieldMask != "" && resp.Status < 400 && data.Params != nil {
+				if att := data.Params.Type.ToObject()[data.FieldMask]; att != nil {
+					respData["MaskField"] = codegen.GoifyAtt(att, data.FieldMask, true)
+				}
+			}
 			var views []string
 			if resp.ViewName != "" {
 				views = []string{resp.ViewName}
@@ -275,11 +525,13 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 				respData["ViewName"] = view
 				respData["MediaType"] = mt
 				respData["ContentType"] = mt.ContentType
-				if view == "default" {
-					respData["RespName"] = codegen.Goify(resp.Name, true)
-				} else {
-					base := fmt.Sprintf("%s%s", resp.Name, strings.Title(view))
-					respData["RespName"] = codegen.Goify(base, true)
+				respData["RespName"] = respName(resp, view)
+				delete(respData, "LocationField")
+				respData["Trailers"] = BuildTrailers(resp, projected)
+				respData["Versions"] = responseVersions(resp, projected)
+				if loc := projected.Type.ToObject()[resp.LocationAttribute]; loc != nil {
+					respData["LocationField"] = codegen.GoifyAtt(loc, resp.LocationAttribute, true)
+					respData["LocationPointer"] = projected.IsPrimitivePointer(resp.LocationAttribute)
 				}
 				if err := w.ExecuteTemplate("response", ctxMTRespT, fn, respData); err != nil {
 					return err
@@ -289,6 +541,270 @@ func (w *ContextsWriter) Execute(data *ContextTemplateData) error {
 		}
 		return w.ExecuteTemplate("response", ctxNoMTRespT, nil, respData)
 	})
+	if err != nil {
+		return err
+	}
+	if err := w.writeRespond(data); err != nil {
+		return err
+	}
+	if data.Async {
+		if err := w.ExecuteTemplate("acceptedJob", ctxAcceptedJobT, nil, data); err != nil {
+			return err
+		}
+	}
+	if data.Resumable {
+		if err := w.ExecuteTemplate("createdUpload", ctxCreatedUploadT, nil, data); err != nil {
+			return err
+		}
+	}
+	return w.writeRequestedView(data)
+}
+
+// writeRequestedView writes the RequestedView method of contexts whose response view is selected
+// by a request param or header.
+func (w *ContextsWriter) writeRequestedView(data *ContextTemplateData) error {
+	var (
+		atts *design.AttributeDefinition
+		name string
+		kind string
+	)
+	switch {
+	case data.ViewParam != "":
+		atts, name, kind = data.Params, data.ViewParam, "param"
+	case data.ViewHeader != "":
+		atts, name, kind = data.Headers, data.ViewHeader, "header"
+	default:
+		return nil
+	}
+	if atts == nil || atts.Type.ToObject()[name] == nil {
+		return fmt.Errorf("%s %s of %s selects the response view but is not defined", kind, name, data.Name)
+	}
+	viewData := map[string]interface{}{
+		"Context": data,
+		"Name":    name,
+		"Kind":    kind,
+		"Field":   codegen.GoifyAtt(atts.Type.ToObject()[name], name, true),
+		"Pointer": atts.IsPrimitivePointer(name),
+	}
+	return w.ExecuteTemplate("view", ctxViewT, nil, viewData)
+}
+
+// writeRespond writes the Respond method of contexts whose responses are selected using the value
+// of a media type attribute.
+func (w *ContextsWriter) writeRespond(data *ContextTemplateData) error {
+	var (
+		tagged   []*design.ResponseDefinition
+		untagged []*design.ResponseDefinition
+	)
+	data.IterateResponses(func(resp *design.ResponseDefinition) error {
+		if resp.TagAttribute != "" {
+			tagged = append(tagged, resp)
+		} else {
+			untagged = append(untagged, resp)
+		}
+		return nil
+	})
+	if len(tagged) == 0 {
+		return nil
+	}
+	first := tagged[0]
+	mt, ok := first.Type.(*design.MediaTypeDefinition)
+	if !ok {
+		mt = design.Design.MediaTypeWithIdentifier(first.MediaType)
+	}
+	if mt == nil {
+		return fmt.Errorf("response %s of %s is tagged but has no media type", first.Name, data.Name)
+	}
+	view := first.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	projected, _, err := mt.Project(view)
+	if err != nil {
+		return err
+	}
+	att := projected.Type.ToObject()[first.TagAttribute]
+	if att == nil {
+		return fmt.Errorf("response %s of %s is tagged with unknown attribute %s", first.Name, data.Name, first.TagAttribute)
+	}
+	cases := make([]map[string]string, len(tagged))
+	for i, resp := range tagged {
+		cases[i] = map[string]string{"Value": resp.TagValue, "Method": respName(resp, view)}
+	}
+	var def string
+	for _, resp := range untagged {
+		rmt, ok := resp.Type.(*design.MediaTypeDefinition)
+		if !ok {
+			rmt = design.Design.MediaTypeWithIdentifier(resp.MediaType)
+		}
+		if rmt == mt && resp.ViewName == first.ViewName {
+			def = respName(resp, view)
+			break
+		}
+	}
+	respondData := map[string]interface{}{
+		"Context":   data,
+		"Projected": projected,
+		"Attribute": first.TagAttribute,
+		"Field":     codegen.GoifyAtt(att, first.TagAttribute, true),
+		"Pointer":   projected.IsPrimitivePointer(first.TagAttribute),
+		"Cases":     cases,
+		"Default":   def,
+	}
+	return w.ExecuteTemplate("respond", ctxRespondT, nil, respondData)
+}
+
+// ResponseParts returns the data used to generate the code that writes and reads the parts of
+// the given multipart/mixed response. The content of the parts whose type is a media type is
+// rendered with the default view.
+func ResponseParts(resp *design.ResponseDefinition) ([]*PartData, error) {
+	parts := make([]*PartData, len(resp.Parts))
+	for i, p := range resp.Parts {
+		data := &PartData{Name: p.Name, FieldName: codegen.Goify(p.Name, true), ContentType: p.MediaType}
+		if p.Type != nil {
+			t := p.Type
+			if mt, ok := t.(*design.MediaTypeDefinition); ok {
+				projected, _, err := mt.Project(design.DefaultView)
+				if err != nil {
+					return nil, err
+				}
+				t = projected
+			}
+			var required []string
+			if ut, ok := t.(*design.MediaTypeDefinition); ok {
+				required = ut.AllRequired()
+			} else if ut, ok := t.(*design.UserTypeDefinition); ok {
+				required = ut.AllRequired()
+			}
+			data.GoType = codegen.GoTypeRef(t, required, 0, false)
+		}
+		parts[i] = data
+	}
+	return parts, nil
+}
+
+// respName returns the name of the context method that sends the given response using the given
+// media type view.
+func respName(resp *design.ResponseDefinition, view string) string {
+	if view == design.DefaultView {
+		return codegen.Goify(resp.Name, true)
+	}
+	return codegen.Goify(fmt.Sprintf("%s%s", resp.Name, strings.Title(view)), true)
+}
+
+// jsonAppender returns the expression that JSON encodes the response value r of the given
+// primitive type without going through the service encoder. finite is true if the value must be
+// checked for NaN and infinity first. It returns the empty string for the primitive types with no
+// direct encoding.
+func jsonAppender(p design.Primitive) (expr string, finite bool) {
+	switch p.Kind() {
+	case design.BooleanKind:
+		return "goa.AppendJSONBool(nil, r)", false
+	case design.IntegerKind:
+		return "goa.AppendJSONInt(nil, int64(r))", false
+	case design.NumberKind:
+		return "goa.AppendJSONFloat(nil, r)", true
+	case design.StringKind:
+		return "goa.AppendJSONString(nil, r)", false
+	}
+	return "", false
+}
+
+// payloadLimits returns the code that initializes the goa.JSONLimits value describing the
+// maximum lengths of the arrays and maps of the given payload. It returns the empty string if the
+// payload defines no such limit.
+func payloadLimits(p *design.UserTypeDefinition) string {
+	if p == nil {
+		return ""
+	}
+	return attributeLimits(&design.AttributeDefinition{Type: p}, make(map[string]bool))
+}
+
+// attributeLimits returns the goa.JSONLimits code for the given attribute. seen lists the user
+// types being visited so that recursive types get no limits past their first occurrence.
+func attributeLimits(att *design.AttributeDefinition, seen map[string]bool) string {
+	if ut, ok := att.Type.(*design.UserTypeDefinition); ok {
+		if seen[ut.TypeName] {
+			return ""
+		}
+		seen[ut.TypeName] = true
+		defer delete(seen, ut.TypeName)
+	} else if mt, ok := att.Type.(*design.MediaTypeDefinition); ok {
+		if seen[mt.TypeName] {
+			return ""
+		}
+		seen[mt.TypeName] = true
+		defer delete(seen, mt.TypeName)
+	}
+	var elems []string
+	switch {
+	case att.Type.IsArray():
+		if l := maxLength(att); l > 0 {
+			elems = append(elems, fmt.Sprintf("MaxLength: %d", l))
+		}
+		if e := attributeLimits(att.Type.ToArray().ElemType, seen); e != "" {
+			elems = append(elems, "Elem: "+e)
+		}
+	case att.Type.IsHash():
+		if l := maxLength(att); l > 0 {
+			elems = append(elems, fmt.Sprintf("MaxLength: %d", l))
+		}
+		if e := attributeLimits(att.Type.ToHash().ElemType, seen); e != "" {
+			elems = append(elems, "Elem: "+e)
+		}
+	case att.Type.IsObject():
+		o := att.Type.ToObject()
+		names := make([]string, 0, len(o))
+		for n := range o {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		var fields []string
+		for _, n := range names {
+			key := codegen.JSONKey(n, o[n])
+			if key == "-" {
+				continue
+			}
+			if f := attributeLimits(o[n], seen); f != "" {
+				fields = append(fields, fmt.Sprintf("%q: %s", key, f))
+			}
+		}
+		if len(fields) > 0 {
+			elems = append(elems, "Fields: map[string]*goa.JSONLimits{"+strings.Join(fields, ", ")+"}")
+		}
+	}
+	if len(elems) == 0 {
+		return ""
+	}
+	return "&goa.JSONLimits{" + strings.Join(elems, ", ") + "}"
+}
+
+// maxLength returns the maximum length validation of the given attribute, 0 if there is none.
+func maxLength(att *design.AttributeDefinition) int {
+	if att.Validation == nil || att.Validation.MaxLength == nil {
+		return 0
+	}
+	return *att.Validation.MaxLength
+}
+
+// HasActions returns true if the value of the given key is true for any of the actions, e.g.
+// "Raw".
+func (d *ControllerTemplateData) HasActions(key string) bool {
+	for _, a := range d.Actions {
+		if b, ok := a[key].(bool); ok && b {
+			return true
+		}
+	}
+	return false
+}
+
+// NilCollections returns the encoding of the nil slices and maps of the API request bodies, see
+// design.APIDefinition.
+func (d *ControllerTemplateData) NilCollections() string {
+	if d.API == nil {
+		return ""
+	}
+	return d.API.NilCollections
 }
 
 // NewControllersWriter returns a handlers code writer.
@@ -301,6 +817,7 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 	return &ControllersWriter{
 		SourceFile: file,
 		Finalizer:  codegen.NewFinalizer(),
+		Sanitizer:  codegen.NewSanitizer(),
 		Validator:  codegen.NewValidator(),
 	}, nil
 }
@@ -308,55 +825,377 @@ func NewControllersWriter(filename string) (*ControllersWriter, error) {
 // WriteInitService writes the initService function
 func (w *ControllersWriter) WriteInitService(encoders, decoders []*EncoderTemplateData) error {
 	ctx := map[string]interface{}{
-		"API":      design.Design,
-		"Encoders": encoders,
-		"Decoders": decoders,
+		"API":            design.Design,
+		"Encoders":       encoders,
+		"Decoders":       decoders,
+		"ErrorEnvelope":  errorEnvelopeCode(design.Design.ErrorEnvelope),
+		"SensitiveNames": design.Design.SensitiveNames(),
+		"SensitiveTypes": codegen.SensitiveTypes(design.Design),
 	}
 	return w.ExecuteTemplate("service", serviceT, nil, ctx)
 }
 
-// Execute writes the handlers GoGenerator
-func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
-	if len(data) == 0 {
+// Execute writes the handlers GoGenerator
+func (w *ControllersWriter) Execute(data []*ControllerTemplateData) error {
+	if len(data) == 0 {
+		return nil
+	}
+	for _, d := range data {
+		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
+			return err
+		}
+		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
+			return err
+		}
+		if len(d.Origins) > 0 {
+			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, d); err != nil {
+				return err
+			}
+		}
+		fn := template.FuncMap{
+			"newCoerceData":  newCoerceData,
+			"finalizeCode":   w.Finalizer.Code,
+			"sanitizeCode":   w.Sanitizer.Code,
+			"validationCode": w.Validator.Code,
+		}
+		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
+			return err
+		}
+		if len(d.Actions) > 0 {
+			if err := w.ExecuteTemplate("errorEncoder", errorEncoderT, nil, d); err != nil {
+				return err
+			}
+		}
+		if len(d.SignedFiles) > 0 {
+			if err := w.ExecuteTemplate("signedURL", signedURLT, nil, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NewSecurityWriter returns a security functionality code writer.
+// Those functionalities are there to support action-middleware related to security.
+func NewSecurityWriter(filename string) (*SecurityWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &SecurityWriter{SourceFile: file}, nil
+}
+
+// Execute adds the different security schemes and middleware supporting functions.
+func (w *SecurityWriter) Execute(schemes []*design.SecuritySchemeDefinition) error {
+	return w.ExecuteTemplate("security_schemes", securitySchemesT, nil, schemes)
+}
+
+// NewJSONRPCWriter returns a JSON-RPC endpoint code writer.
+func NewJSONRPCWriter(filename string) (*JSONRPCWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONRPCWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that mounts the JSON-RPC endpoint with the given path and methods.
+func (w *JSONRPCWriter) Execute(path string, methods []*JSONRPCMethodData) error {
+	data := map[string]interface{}{"Path": path, "Methods": methods}
+	return w.ExecuteTemplate("jsonrpc", jsonrpcT, nil, data)
+}
+
+// NewUploadsWriter returns an upload offset endpoints code writer.
+func NewUploadsWriter(filename string) (*UploadsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that mounts the upload offset endpoints of the resumable actions.
+func (w *UploadsWriter) Execute(uploads []*UploadData) error {
+	return w.ExecuteTemplate("uploads", uploadsT, nil, uploads)
+}
+
+// NewJobsWriter returns a job status endpoint code writer.
+func NewJobsWriter(filename string) (*JobsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &JobsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that mounts the job status endpoint of the given API.
+func (w *JobsWriter) Execute(api *design.APIDefinition) error {
+	data := map[string]interface{}{
+		"Prefix":      strings.TrimSuffix(api.JobsPath, "/") + "/",
+		"Path":        api.JobStatusPath(),
+		"ContentType": design.JobMediaIdentifier,
+	}
+	return w.ExecuteTemplate("jobs", jobsT, nil, data)
+}
+
+// NewHealthWriter returns a health check endpoints code writer.
+func NewHealthWriter(filename string) (*HealthWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &HealthWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that mounts the health check endpoints with the given paths.
+func (w *HealthWriter) Execute(paths []string) error {
+	return w.ExecuteTemplate("health", healthT, nil, paths)
+}
+
+// NewErrorClassesWriter returns an error classes code writer.
+func NewErrorClassesWriter(filename string) (*ErrorClassesWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ErrorClassesWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that defines the error classes of the given responses.
+func (w *ErrorClassesWriter) Execute(classes []*design.ResponseDefinition) error {
+	return w.ExecuteTemplate("errors", errorClassesT, nil, classes)
+}
+
+// NewEventsWriter returns an event consumers code writer.
+func NewEventsWriter(filename string) (*EventsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &EventsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the code that consumes the given events.
+func (w *EventsWriter) Execute(events []*EventData) error {
+	return w.ExecuteTemplate("events", eventsT, nil, events)
+}
+
+// NewRoutesWriter returns a route table code writer.
+func NewRoutesWriter(filename string) (*RoutesWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &RoutesWriter{SourceFile: file}, nil
+}
+
+// Execute writes the route table listing the given routes of the service.
+func (w *RoutesWriter) Execute(service string, routes []*RouteData) error {
+	data := map[string]interface{}{
+		"Service": service,
+		"Routes":  routes,
+	}
+	return w.ExecuteTemplate("routes", routesT, nil, data)
+}
+
+// NewTypesWriter returns a type registry code writer.
+func NewTypesWriter(filename string) (*TypesWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &TypesWriter{SourceFile: file}, nil
+}
+
+// Execute writes the type registry listing the given types of the service.
+func (w *TypesWriter) Execute(service string, types []*TypeData) error {
+	data := map[string]interface{}{
+		"Service": service,
+		"Types":   types,
+	}
+	return w.ExecuteTemplate("types", typesT, nil, data)
+}
+
+// NewProvidersWriter returns a dependency injection providers code writer.
+func NewProvidersWriter(filename string) (*ProvidersWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &ProvidersWriter{SourceFile: file}, nil
+}
+
+// Execute writes the providers of the given dependency injection framework for the service with
+// the given name and controllers. middleware indexes the controllers whose Mount function accepts
+// the implementations of the middleware used in the design.
+func (w *ProvidersWriter) Execute(framework, service string, controllers []string, middleware map[string]bool) error {
+	data := map[string]interface{}{
+		"Framework":   framework,
+		"Service":     service,
+		"Controllers": controllers,
+		"Middleware":  middleware,
+	}
+	return w.ExecuteTemplate("providers", providersT, nil, data)
+}
+
+// NewHeadersWriter returns a header constants code writer.
+func NewHeadersWriter(filename string) (*HeadersWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &HeadersWriter{SourceFile: file}, nil
+}
+
+// Execute writes the constants and accessors of the given headers.
+func (w *HeadersWriter) Execute(headers []*HeaderData) error {
+	return w.ExecuteTemplate("headers", headersT, nil, headers)
+}
+
+// NewSchemaVersionsWriter returns a schema versions code writer.
+func NewSchemaVersionsWriter(filename string) (*SchemaVersionsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaVersionsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the functions that convert the given schema revisions.
+func (w *SchemaVersionsWriter) Execute(versions []*SchemaVersionData) error {
+	return w.ExecuteTemplate("schemaVersions", schemaVersionsT, nil, versions)
+}
+
+// NewHeaderGroupsWriter returns a header groups code writer.
+func NewHeaderGroupsWriter(filename string) (*HeaderGroupsWriter, error) {
+	file, err := codegen.SourceFileFor(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &HeaderGroupsWriter{SourceFile: file}, nil
+}
+
+// Execute writes the functions that map the given header groups to their types.
+func (w *HeaderGroupsWriter) Execute(groups []*HeaderGroupData) error {
+	return w.ExecuteTemplate("headerGroups", headerGroupsT, nil, groups)
+}
+
+// BuildHeaderGroups returns the data used to generate the code of the given header groups.
+func BuildHeaderGroups(groups []*design.HeaderGroupDefinition) []*HeaderGroupData {
+	if len(groups) == 0 {
+		return nil
+	}
+	validator := codegen.NewValidator()
+	res := make([]*HeaderGroupData, len(groups))
+	for i, g := range groups {
+		ut := g.Type
+		data := &HeaderGroupData{
+			Name:     codegen.Goify(g.Name, true),
+			Group:    g.Name,
+			Prefix:   g.Prefix,
+			TypeName: codegen.GoTypeName(ut, ut.AllRequired(), 0, false),
+			TypeRef:  codegen.GoTypeRef(ut, ut.AllRequired(), 0, false),
+			Validate: validator.Code(ut.AttributeDefinition, false, false, false, "ut", "type", 1, false) != "",
+		}
+		ut.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+			f := &HeaderGroupFieldData{
+				Field:    codegen.GoifyAtt(att, n, true),
+				Key:      g.HeaderKey(n),
+				TypeName: att.Type.Name(),
+				Pointer:  ut.IsPrimitivePointer(n),
+				Required: ut.IsRequired(n),
+			}
+			if !f.Pointer && ut.HasDefaultValue(n) {
+				f.Default = codegen.PrintVal(att.Type, att.DefaultValue)
+			}
+			if att.Type.Kind() == design.DateTimeKind {
+				f.TypeName = "datetime"
+			}
+			f.Parse, f.Format = headerCodec(att.Type.Kind(), "v."+f.Field, f.Pointer)
+			data.Fields = append(data.Fields, f)
+			return nil
+		})
+		res[i] = data
+	}
+	return res
+}
+
+// BuildQueryObjects returns the data used to generate the code of the given query objects.
+func BuildQueryObjects(objs []*design.QueryObjectDefinition) []*QueryObjectData {
+	if len(objs) == 0 {
+		return nil
+	}
+	validator := codegen.NewValidator()
+	res := make([]*QueryObjectData, len(objs))
+	for i, q := range objs {
+		ut := q.Type
+		res[i] = &QueryObjectData{
+			Name:     codegen.Goify(q.Name, true),
+			VarName:  codegen.Goify(q.Name, false),
+			Key:      q.Name,
+			TypeName: codegen.GoTypeName(ut, ut.AllRequired(), 0, false),
+			TypeRef:  codegen.GoTypeRef(ut, ut.AllRequired(), 0, false),
+			Validate: validator.Code(ut.AttributeDefinition, false, false, false, "ut", "type", 1, false) != "",
+		}
+	}
+	return res
+}
+
+// BuildTrailers returns the data used to generate the code that sets and reads the trailers of
+// the given response. mt is the projected media type rendered by the response, the trailers
+// whose attribute is not part of mt are skipped.
+func BuildTrailers(resp *design.ResponseDefinition, mt *design.MediaTypeDefinition) []*TrailerData {
+	if len(resp.Trailers) == 0 || mt == nil {
 		return nil
 	}
-	for _, d := range data {
-		if err := w.ExecuteTemplate("controller", ctrlT, nil, d); err != nil {
-			return err
-		}
-		if err := w.ExecuteTemplate("mount", mountT, nil, d); err != nil {
-			return err
-		}
-		if len(d.Origins) > 0 {
-			if err := w.ExecuteTemplate("handleCORS", handleCORST, nil, d); err != nil {
-				return err
-			}
+	names := make([]string, 0, len(resp.Trailers))
+	for n := range resp.Trailers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	obj := mt.Type.ToObject()
+	var res []*TrailerData
+	for _, n := range names {
+		name := resp.Trailers[n]
+		att := obj[name]
+		if att == nil {
+			continue
 		}
-		fn := template.FuncMap{
-			"newCoerceData":  newCoerceData,
-			"finalizeCode":   w.Finalizer.Code,
-			"validationCode": w.Validator.Code,
+		t := &TrailerData{
+			Key:      n,
+			Field:    codegen.GoifyAtt(att, name, true),
+			TypeName: att.Type.Name(),
+			Pointer:  mt.IsPrimitivePointer(name),
 		}
-		if err := w.ExecuteTemplate("unmarshal", unmarshalT, fn, d); err != nil {
-			return err
+		if att.Type.Kind() == design.DateTimeKind {
+			t.TypeName = "datetime"
 		}
+		t.Parse, t.Format = headerCodec(att.Type.Kind(), "r."+t.Field, t.Pointer)
+		res = append(res, t)
 	}
-	return nil
+	return res
 }
 
-// NewSecurityWriter returns a security functionality code writer.
-// Those functionalities are there to support action-middleware related to security.
-func NewSecurityWriter(filename string) (*SecurityWriter, error) {
-	file, err := codegen.SourceFileFor(filename)
-	if err != nil {
-		return nil, err
+// headerCodec returns the expression that parses the raw header value held by the variable raw
+// and the expression that formats value into a header value given the kind of the value. The
+// parse expression is empty for strings, value is dereferenced if pointer is true unless it holds
+// a date time.
+func headerCodec(kind design.Kind, value string, pointer bool) (parse, format string) {
+	format = "%s"
+	switch kind {
+	case design.BooleanKind:
+		parse, format = "strconv.ParseBool(raw)", "strconv.FormatBool(%s)"
+	case design.IntegerKind:
+		parse, format = "strconv.Atoi(raw)", "strconv.Itoa(%s)"
+	case design.NumberKind:
+		parse, format = "strconv.ParseFloat(raw, 64)", "strconv.FormatFloat(%s, 'f', -1, 64)"
+	case design.DateTimeKind:
+		parse, format = "time.Parse(time.RFC3339, raw)", "%s.Format(time.RFC3339)"
 	}
-	return &SecurityWriter{SourceFile: file}, nil
-}
-
-// Execute adds the different security schemes and middleware supporting functions.
-func (w *SecurityWriter) Execute(schemes []*design.SecuritySchemeDefinition) error {
-	return w.ExecuteTemplate("security_schemes", securitySchemesT, nil, schemes)
+	if pointer && kind != design.DateTimeKind {
+		value = "*" + value
+	}
+	return parse, fmt.Sprintf(format, value)
 }
 
 // NewResourcesWriter returns a contexts code writer.
@@ -390,6 +1229,11 @@ func (w *MediaTypesWriter) Execute(mt *design.MediaTypeDefinition) error {
 		mLinks *design.UserTypeDefinition
 		fn     = template.FuncMap{"validationCode": w.Validator.Code}
 	)
+	if mt.Type.IsObject() {
+		if err := w.ExecuteTemplate("mediatypeenums", mediaTypeEnumsT, fn, mt); err != nil {
+			return err
+		}
+	}
 	err := mt.IterateViews(func(view *design.ViewDefinition) error {
 		p, links, err := mt.Project(view.Name)
 		if mLinks == nil {
@@ -421,6 +1265,7 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 	return &UserTypesWriter{
 		SourceFile: file,
 		Finalizer:  codegen.NewFinalizer(),
+		Sanitizer:  codegen.NewSanitizer(),
 		Validator:  codegen.NewValidator(),
 	}, nil
 }
@@ -429,7 +1274,12 @@ func NewUserTypesWriter(filename string) (*UserTypesWriter, error) {
 func (w *UserTypesWriter) Execute(t *design.UserTypeDefinition) error {
 	fn := template.FuncMap{
 		"finalizeCode":   w.Finalizer.Code,
+		"sanitizeCode":   w.Sanitizer.Code,
 		"validationCode": w.Validator.Code,
+		"external": func(t *design.UserTypeDefinition) bool {
+			path, _ := codegen.TypePackage(t)
+			return path != ""
+		},
 	}
 	return w.ExecuteTemplate("types", userTypeT, fn, t)
 }
@@ -443,6 +1293,7 @@ func newCoerceData(name string, att *design.AttributeDefinition, pointer bool, p
 		"Attribute": att,
 		"Pkg":       pkg,
 		"Depth":     depth,
+		"EnumType":  codegen.GoEnumTypeName(att),
 	}
 }
 
@@ -463,8 +1314,30 @@ type {{ .Name }} struct {
 */}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Headers.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
 {{ end }}{{ end }}{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
 */}}	{{ goifyatt $att $name true }} {{ if and $att.Type.IsPrimitive ($.Params.IsPrimitivePointer $name) }}*{{ end }}{{ gotyperef .Type nil 0 false }}
-{{ end }}{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
+{{ end }}{{ end }}{{ range .HeaderGroups }}	{{ .Name }} {{ .TypeRef }}
+{{ end }}{{ range .QueryObjects }}	{{ .Name }} {{ .TypeRef }}
+{{ end }}{{ if .Payload }}	Payload {{ gotyperef .Payload nil 0 false }}
 {{ end }}}
+`
+	// ctxStreamT generates the method that writes a chunk of a streamed response.
+	// template input: *ContextTemplateData
+	ctxStreamT = `
+// WriteChunk writes b to the response and flushes it so that the client receives it right away. It
+// returns goa.ErrClientClosedRequest without writing once the client closed the connection, the
+// handlers that stream the response should stop encoding when it does.
+func (ctx *{{ .Name }}) WriteChunk(b []byte) error {
+	return goa.StreamWrite(ctx.Context, b)
+}
+`
+	// ctxCanonicalHashT generates the method that computes the digest of the normalized request.
+	// template input: *ContextTemplateData
+	ctxCanonicalHashT = `
+// CanonicalHash returns a stable digest of the request computed from its method, path, params
+// sorted by name and value and from the canonical JSON encoding of its payload, see
+// goa.CanonicalHash.
+func (ctx *{{ .Name }}) CanonicalHash() (string, error) {
+	return goa.CanonicalHash(ctx.Request.Method, ctx.Request.URL.Path, ctx.Params, {{ if .Payload }}ctx.Payload{{ else }}nil{{ end }})
+}
 `
 	// coerceT generates the code that coerces the generic deserialized
 	// data to the actual type.
@@ -484,10 +1357,11 @@ type {{ .Name }} struct {
 */}}{{/* IntegerType */}}{{/*
 */}}{{ $tmp := tempvar }}{{/*
 */}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.Atoi(raw{{ goify .Name true }}); err2 == nil {
-{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ .VarName }}
+{{ $val := .VarName }}{{ if .EnumType }}{{ $val = printf "%s(%s)" .EnumType .VarName }}{{ end }}{{/*
+*/}}{{ if .Pointer }}{{ $tmp2 := tempvar }}{{ tabs .Depth }}	{{ $tmp2 }} := {{ $val }}
 {{ tabs .Depth }}	{{ $tmp }} := &{{ $tmp2 }}
 {{ tabs .Depth }}	{{ .Pkg }} = {{ $tmp }}
-{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ .VarName }}
+{{ else }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $val }}
 {{ end }}{{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "integer"))
 {{ tabs .Depth }}}
@@ -496,20 +1370,25 @@ type {{ .Name }} struct {
 */}}{{/* NumberType */}}{{/*
 */}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
 */}}{{ tabs .Depth }}if {{ .VarName }}, err2 := strconv.ParseFloat(raw{{ goify .Name true }}, 64); err2 == nil {
-{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
-{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
+{{ if .EnumType }}{{ $tmp := tempvar }}{{ tabs .Depth }}	{{ $tmp }} := {{ .EnumType }}({{ .VarName }})
+{{ tabs .Depth }}	{{ .Pkg }} = {{ if .Pointer }}&{{ end }}{{ $tmp }}{{ else }}{{/*
+*/}}{{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
+{{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}{{ end }}
 {{ tabs .Depth }}} else {
 {{ tabs .Depth }}	err = goa.MergeErrors(err, goa.InvalidParamTypeError("{{ .Name }}", raw{{ goify .Name true }}, "number"))
 {{ tabs .Depth }}}
 {{ end }}{{ if eq .Attribute.Type.Kind 4 }}{{/*
 
 */}}{{/* StringType */}}{{/*
-*/}}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
-{{ end }}{{ if eq .Attribute.Type.Kind 5 }}{{/*
+*/}}{{ if .Attribute.Sanitizers }}{{ tabs .Depth }}raw{{ goify .Name true }} = goa.Sanitize(raw{{ goify .Name true }}{{ range .Attribute.Sanitizers }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if .EnumType }}{{ $tmp := tempvar }}{{ tabs .Depth }}{{ $tmp }} := {{ .EnumType }}(raw{{ goify .Name true }})
+{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}{{ $tmp }}
+{{ else }}{{ tabs .Depth }}{{ .Pkg }} = {{ if .Pointer }}&{{ end }}raw{{ goify .Name true }}
+{{ end }}{{ end }}{{ if eq .Attribute.Type.Kind 5 }}{{/*
 
 */}}{{/* DateTimeType */}}{{/*
 */}}{{ $varName := or (and (not .Pointer) .VarName) tempvar }}{{/*
-*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := time.Parse(time.RFC3339, raw{{ goify .Name true }}); err2 == nil {
+*/}}{{ tabs .Depth }}if {{ .VarName }}, err2 := {{ if .Attribute.TimeFormats }}goa.ParseTime(raw{{ goify .Name true }}{{ range .Attribute.TimeFormats }}, {{ printf "%q" . }}{{ end }}){{ else }}time.Parse(time.RFC3339, raw{{ goify .Name true }}){{ end }}; err2 == nil {
 {{ if .Pointer }}{{ tabs .Depth }}	{{ $varName }} := &{{ .VarName }}
 {{ end }}{{ tabs .Depth }}	{{ .Pkg }} = {{ $varName }}
 {{ tabs .Depth }}} else {
@@ -554,48 +1433,96 @@ func New{{ .Name }}(ctx context.Context, r *http.Request, service *goa.Service)
 	req.Request = r
 	rctx := {{ .Name }}{Context: ctx, ResponseData: resp, RequestData: req}{{/*
 */}}
-{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}	header{{ goify $name true }} := req.Header["{{ canonicalHeaderKey $name }}"]
-{{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $name true }}) == 0 {
-		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $name }}"))
+{{ if .Headers }}{{ range $name, $att := .Headers.Type.ToObject }}{{ $key := $att.AttributeKey $name }}	header{{ goify $key true }} := req.Header["{{ canonicalHeaderKey $key }}"]
+{{ $mustValidate := $.Headers.IsRequired $name }}{{ if $mustValidate }}	if len(header{{ goify $key true }}) == 0 {
+		err = goa.MergeErrors(err, goa.MissingHeaderError("{{ $key }}"))
 	} else {
-{{ else }}	if len(header{{ goify $name true }}) > 0 {
-{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}		req.Params["{{ $name }}"] = header{{ goify $name true }}
-{{ if eq (arrayAttribute $att).Type.Kind 4 }}		headers := header{{ goify $name true }}
-{{ else }}		headers := make({{ gotypedef $att 2 true false }}, len(header{{ goify $name true }}))
-		for i, raw{{ goify $name true}} := range header{{ goify $name true}} {
-{{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Headers.IsPrimitivePointer $name) "headers[i]" 3) }}{{/*
+{{ else }}	if len(header{{ goify $key true }}) > 0 {
+{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}		req.Params["{{ $key }}"] = header{{ goify $key true }}
+{{ if and (eq (arrayAttribute $att).Type.Kind 4) (not (arrayAttribute $att).Sanitizers) }}		headers := header{{ goify $key true }}
+{{ else }}		headers := make({{ gotypedef $att 2 true false }}, len(header{{ goify $key true }}))
+		for i, raw{{ goify $key true}} := range header{{ goify $key true}} {
+{{ template "Coerce" (newCoerceData $key (arrayAttribute $att) ($.Headers.IsPrimitivePointer $name) "headers[i]" 3) }}{{/*
 */}}		}
 {{ end }}		{{ printf "rctx.%s" (goifyatt $att $name true) }} = headers
-{{ else }}		raw{{ goify $name true}} := header{{ goify $name true}}[0]
-		req.Params["{{ $name }}"] = []string{raw{{ goify $name true }}}
-{{ template "Coerce" (newCoerceData $name $att ($.Headers.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
-*/}}{{ $validation := validationChecker $att ($.Headers.IsNonZero $name) ($.Headers.IsRequired $name) ($.Headers.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+{{ else }}		raw{{ goify $key true}} := header{{ goify $key true}}[0]
+		req.Params["{{ $key }}"] = []string{raw{{ goify $key true }}}
+{{ template "Coerce" (newCoerceData $key $att ($.Headers.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
+*/}}{{ $validation := validationChecker $att ($.Headers.IsNonZero $name) ($.Headers.IsRequired $name) ($.Headers.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $key 2 false }}{{/*
 */}}{{ if $validation }}{{ $validation }}
 {{ end }}	}
 {{ end }}{{ end }}{{/* if .Headers }}{{/*
 
-*/}}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{/*
-*/}}	param{{ goify $name true }} := req.Params["{{ $name }}"]
-{{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $name true }}) == 0 {
+*/}}{{ range .HeaderGroups }}	if v, err2 := Get{{ .Name }}Headers(req.Header); err2 != nil {
+		err = goa.MergeErrors(err, err2)
+	} else {
+		rctx.{{ .Name }} = v
+	}
+{{ end }}{{ range .QueryObjects }}	{
+		var v {{ .TypeName }}
+		if ok, err2 := goa.DecodeDeepObject(req.Params, {{ printf "%q" .Key }}, &v); err2 != nil {
+			err = goa.MergeErrors(err, err2)
+		} else if ok {
+{{ if .Validate }}			if err2 := v.Validate(); err2 != nil {
+				err = goa.MergeErrors(err, err2)
+			}
+{{ end }}			rctx.{{ .Name }} = &v
+		}
+	}
+{{ end }}{{ if .Params }}{{ range $name, $att := .Params.Type.ToObject }}{{ $key := $att.AttributeKey $name }}{{/*
+*/}}	param{{ goify $key true }} := req.Params["{{ $key }}"]
+{{ $mustValidate := $.MustValidate $name }}{{ if $mustValidate }}	if len(param{{ goify $key true }}) == 0 {
 		{{ if $.Params.HasDefaultValue $name }}{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}{{else}}{{/*
-*/}}err = goa.MergeErrors(err, goa.MissingParamError("{{ $name }}")){{end}}
+*/}}err = goa.MergeErrors(err, goa.MissingParamError("{{ $key }}")){{end}}
 	} else {
-{{ else }}{{ if $.Params.HasDefaultValue $name }}	if len(param{{ goify $name true }}) == 0 {
+{{ else }}{{ if $.Params.HasDefaultValue $name }}	if len(param{{ goify $key true }}) == 0 {
 		{{printf "rctx.%s" (goifyatt $att $name true) }} = {{ printVal $att.Type $att.DefaultValue }}
 	} else {
-{{ else }}	if len(param{{ goify $name true }}) > 0 {
-{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if eq (arrayAttribute $att).Type.Kind 4 }}		params := param{{ goify $name true }}
-{{ else }}		params := make({{ gotypedef $att 2 true false }}, len(param{{ goify $name true }}))
-		for i, raw{{ goify $name true}} := range param{{ goify $name true}} {
-{{ template "Coerce" (newCoerceData $name (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
+{{ else }}	if len(param{{ goify $key true }}) > 0 {
+{{ end }}{{ end }}{{/* if $mustValidate */}}{{ if $att.Type.IsArray }}{{ if and (eq (arrayAttribute $att).Type.Kind 4) (not (arrayAttribute $att).Sanitizers) }}{{/*
+*/}}{{ with $att.ParamSeparator }}		params := goa.SplitParam(param{{ goify $key true }}, {{ printf "%q" . }})
+{{ else }}		params := param{{ goify $key true }}
+{{ end }}{{ else }}{{ with $att.ParamSeparator }}{{/*
+*/}}		it := goa.NewParamIterator(param{{ goify $key true }}, {{ printf "%q" . }})
+		params := make({{ gotypedef $att 2 true false }}, it.Len())
+		for i := 0; it.Next(); i++ {
+			raw{{ goify $key true }} := it.Value()
+{{ template "Coerce" (newCoerceData $key (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
+*/}}		}
+{{ else }}		params := make({{ gotypedef $att 2 true false }}, len(param{{ goify $key true }}))
+		for i, raw{{ goify $key true}} := range param{{ goify $key true}} {
+{{ template "Coerce" (newCoerceData $key (arrayAttribute $att) ($.Params.IsPrimitivePointer $name) "params[i]" 3) }}{{/*
 */}}		}
-{{ end }}		{{ printf "rctx.%s" (goifyatt $att $name true) }} = params
-{{ else }}		raw{{ goify $name true}} := param{{ goify $name true}}[0]
-{{ template "Coerce" (newCoerceData $name $att ($.Params.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
-*/}}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $name 2 false }}{{/*
+{{ end }}{{ end }}		{{ printf "rctx.%s" (goifyatt $att $name true) }} = params
+{{ else }}		raw{{ goify $key true}} := param{{ goify $key true}}[0]
+{{ template "Coerce" (newCoerceData $key $att ($.Params.IsPrimitivePointer $name) (printf "rctx.%s" (goifyatt $att $name true)) 2) }}{{ end }}{{/*
+*/}}{{ $validation := validationChecker $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $key 2 false }}{{/*
+*/}}{{ if $att.Type.IsArray }}{{ $validation = validationCode $att ($.Params.IsNonZero $name) ($.Params.IsRequired $name) ($.Params.HasDefaultValue $name) (printf "rctx.%s" (goifyatt $att $name true)) $key 2 false }}{{ end }}{{/*
 */}}{{ if $validation }}{{ $validation }}
 {{ end }}	}
-{{ end }}{{ end }}{{/* if .Params */}}	return &rctx, err
+{{ end }}{{ end }}{{/* if .Params */}}{{ with .Tenant }}	rctx.Context = goa.WithTenant(rctx.Context, rctx.{{ . }})
+{{ end }}	return &rctx, err
+}
+`
+
+	// ctxAcceptedJobT generates the response helper of asynchronous actions.
+	// template input: *ContextTemplateData
+	ctxAcceptedJobT = `// AcceptedJob sends a HTTP response with status code 202 that describes the pending job with the
+// given identifier. The Location header contains the path of the job status endpoint.
+func (ctx *{{ .Name }}) AcceptedJob(id string) error {
+	href := JobHref(id)
+	return ctx.Accepted(&GoaJob{ID: id, Status: GoaJobStatusPending, Href: &href})
+}
+`
+
+	// ctxCreatedUploadT generates the response helper of resumable actions.
+	// template input: *ContextTemplateData
+	ctxCreatedUploadT = `// CreatedUpload sends a HTTP response with status code 201 for the upload with the given
+// identifier. The Location header contains the path of the upload offset endpoints.
+func (ctx *{{ .Name }}) CreatedUpload(id string) error {
+	ctx.ResponseData.Header().Set("Location", strings.TrimSuffix(ctx.RequestData.URL.Path, "/")+"/"+url.PathEscape(id))
+	ctx.ResponseData.Header().Set("Tus-Resumable", goa.TusResumable)
+	return ctx.Created()
 }
 `
 
@@ -606,9 +1533,36 @@ func (ctx *{{ .Context.Name }}) {{ goify .RespName true }}(r {{ gotyperef .Proje
 	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
-{{ if .Projected.Type.IsArray }}	if r == nil {
+{{ if and .Projected.Type.IsArray (ne .Context.NilCollections "null") }}	if r == nil {
 		r = {{ gotyperef .Projected .Projected.AllRequired 0 false }}{}
 	}
+{{ end }}{{ if eq .Context.NilCollections "empty" }}	goa.FillNilCollections(r)
+{{ end }}{{ if .Trailers }}	ctx.ResponseData.Header().Set("Trailer", "{{ range $i, $t := .Trailers }}{{ if $i }}, {{ end }}{{ $t.Key }}{{ end }}")
+	defer func() {
+{{ range .Trailers }}{{ if .Pointer }}		if r.{{ .Field }} != nil {
+			ctx.ResponseData.Header().Set({{ printf "%q" .Key }}, {{ .Format }})
+		}
+{{ else }}		ctx.ResponseData.Header().Set({{ printf "%q" .Key }}, {{ .Format }})
+{{ end }}{{ end }}	}()
+{{ end }}{{ if .LocationField }}{{ if .LocationPointer }}	if r.{{ .LocationField }} != nil {
+		ctx.ResponseData.Header().Set("Location", *r.{{ .LocationField }})
+	}
+{{ else }}	ctx.ResponseData.Header().Set("Location", r.{{ .LocationField }})
+{{ end }}{{ end }}{{ if .Versions }}	switch goa.ResponseSchemaVersion(ctx.RequestData.Request, {{ printf "%q" .Context.SchemaVersionHeader }}) {
+{{ range .Versions }}	case {{ printf "%q" .Version }}:
+		v, err := {{ .Convert }}(r)
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.Send(ctx.Context, {{ $.Response.Status }}, v)
+{{ end }}	}
+{{ end }}{{ if .MaskField }}	if len(ctx.{{ .MaskField }}) > 0 && ctx.ResponseData.Service.Encoder.IsJSON(ctx.RequestData.Header.Get("Accept")) {
+		b, err := goa.PruneJSON(r, ctx.{{ .MaskField }})
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.SendJSON(ctx.Context, {{ .Response.Status }}, b)
+	}
 {{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
 }
 `
@@ -620,16 +1574,80 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}(r {{ gotyperef .
 	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .ContentType }}")
 	}
-	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+{{ if and (eq .Context.NilCollections "empty") (not .Type.IsPrimitive) }}{{ if or .Type.IsArray .Type.IsHash }}	if r == nil {
+		r = {{ gotyperef .Type nil 0 false }}{}
+	}
+{{ end }}	goa.FillNilCollections(r)
+{{ end }}{{ if .JSON }}	if {{ if .Finite }}!math.IsNaN(r) && !math.IsInf(r, 0) && {{ end }}ctx.ResponseData.Service.Encoder.IsJSON(ctx.RequestData.Header.Get("Accept")) {
+		return ctx.ResponseData.Service.SendJSON(ctx.Context, {{ .Response.Status }}, {{ .JSON }})
+	}
+{{ end }}	return ctx.ResponseData.Service.Send(ctx.Context, {{ .Response.Status }}, r)
+}
+`
+
+	// ctxPartsRespT generates the response helpers for multipart/mixed responses.
+	// template input: map[string]interface{}
+	ctxPartsRespT = `{{ $writer := printf "%s%s%sParts" (goify .Context.ActionName true) (goify .Context.ResourceName true) (goify .Response.Name true) }}
+// {{ $writer }} writes the parts of the {{ .Response.Name }} response of the {{ .Context.ActionName }}
+// {{ .Context.ResourceName }} action one at a time. Close must be called once all the parts are written.
+type {{ $writer }} struct {
+	*goa.PartWriter
+}
+{{ range .Parts }}
+// Write{{ .FieldName }} writes a {{ printf "%q" .Name }} part and flushes it.
+func (w *{{ $writer }}) Write{{ .FieldName }}({{ if .GoType }}v {{ .GoType }}{{ else }}b []byte{{ end }}) error {
+	return w.{{ if .GoType }}WriteJSON({{ printf "%q" .Name }}, v){{ else }}WritePart({{ printf "%q" .Name }}, {{ printf "%q" .ContentType }}, b){{ end }}
+}
+{{ end }}
+// {{ goify .Response.Name true }} starts the multipart/mixed HTTP response with status code {{ .Response.Status }}.
+func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}() *{{ $writer }} {
+	return &{{ $writer }}{PartWriter: goa.NewPartWriter(ctx.Context, {{ .Response.Status }})}
 }
+`
+
+	// ctxRespondT generates the response helper that selects the response using the value of
+	// the tagged media type attribute.
+	// template input: map[string]interface{}
+	ctxRespondT = `// Respond sends the HTTP response selected by the value of the {{ .Attribute }} attribute of r.
+func (ctx *{{ .Context.Name }}) Respond(r {{ gotyperef .Projected .Projected.AllRequired 0 false }}) error {
+{{ if .Pointer }}	if r.{{ .Field }} != nil {
+		switch *r.{{ .Field }} {
+{{ range .Cases }}		case {{ printf "%q" .Value }}:
+			return ctx.{{ .Method }}(r)
+{{ end }}		}
+	}
+{{ else }}	switch r.{{ .Field }} {
+{{ range .Cases }}	case {{ printf "%q" .Value }}:
+		return ctx.{{ .Method }}(r)
+{{ end }}	}
+{{ end }}{{ if .Default }}	return ctx.{{ .Default }}(r)
+{{ else }}	return fmt.Errorf("no {{ .Context.ActionName }} response matches the value of the {{ .Attribute }} attribute")
+{{ end }}}
+`
+
+	// ctxViewT generates the RequestedView method of contexts whose response view is selected
+	// by the request.
+	// template input: map[string]interface{}
+	ctxViewT = `// RequestedView returns the name of the response view requested with the {{ .Name }} {{ .Kind }}.
+func (ctx *{{ .Context.Name }}) RequestedView() string {
+{{ if .Pointer }}	if ctx.{{ .Field }} == nil || *ctx.{{ .Field }} == "" {
+		return "default"
+	}
+	return *ctx.{{ .Field }}
+{{ else }}	if ctx.{{ .Field }} == "" {
+		return "default"
+	}
+	return ctx.{{ .Field }}
+{{ end }}}
 `
 
 	// ctxNoMTRespT generates the response helpers for responses with no known media type.
 	// template input: *ContextTemplateData
 	ctxNoMTRespT = `
 // {{ goify .Response.Name true }} sends a HTTP response with status code {{ .Response.Status }}.
-func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.MediaType }}resp []byte{{ end }}) error {
-{{ if .Response.MediaType }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.Redirect }}location string{{ if .Response.MediaType }}, {{ end }}{{ end }}{{ if .Response.MediaType }}resp []byte{{ end }}) error {
+{{ if .Response.Redirect }}	ctx.ResponseData.Header().Set("Location", location)
+{{ end }}{{ if .Response.MediaType }}	if ctx.ResponseData.Header().Get("Content-Type") == "" {
 		ctx.ResponseData.Header().Set("Content-Type", "{{ .Response.MediaType }}")
 	}
 {{ end }}	ctx.ResponseData.WriteHeader({{ .Response.Status }}){{ if .Response.MediaType }}
@@ -644,11 +1662,17 @@ func (ctx *{{ .Context.Name }}) {{ goify .Response.Name true }}({{ if .Response.
 	payloadT = `{{ $payload := .Payload }}{{ if .Payload.IsObject }}// {{ gotypename .Payload nil 0 true }} is the {{ .ResourceName }} {{ .ActionName }} action payload.{{/*
 */}}{{ $privateTypeName := gotypename .Payload nil 1 true }}
 type {{ $privateTypeName }} {{ gotypedef .Payload 0 true true }}
-
+{{ timeCodec .Payload.AttributeDefinition $privateTypeName true false }}
 {{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}// Finalize sets the default values defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Finalize() {
 {{ $assignment }}
 }{{ end }}
+{{ $sanitization := sanitizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $sanitization }}
+// Sanitize applies the sanitizers defined in the design.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Sanitize() {
+{{ $sanitization }}
+}
+{{ end }}
 
 {{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 true }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Validate() (err error) {
@@ -661,12 +1685,27 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 true }}) Publicize()
 	var pub {{ $typeName }}
 	{{ recursivePublicizer .Payload.AttributeDefinition "payload" "pub" 1 }}
 	return &pub
+}
+
+// Decode{{ $typeName }} decodes {{ $typeName }} from its wire representation read from body the same
+// way the action request bodies are: the default values are set and the result is validated.
+func Decode{{ $typeName }}(decoder *goa.HTTPDecoder, body io.Reader, contentType string) ({{ gotyperef .Payload .Payload.AllRequired 0 false }}, error) {
+	var payload {{ $privateTypeName }}
+	if err := decoder.Decode(&payload, body, contentType); err != nil {
+		return nil, err
+	}
+{{ if $assignment }}	payload.Finalize()
+{{ end }}{{ if $sanitization }}	payload.Sanitize()
+{{ end }}{{ if $validation }}	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+{{ end }}	return payload.Publicize(), nil
 }{{ end }}
 
 // {{ gotypename .Payload nil 0 false }} is the {{ .ResourceName }} {{ .ActionName }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-
-{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
+{{ timeCodec .Payload.AttributeDefinition (gotypename .Payload nil 1 false) false false }}
+{{ goenumtypedefs .Payload }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}// Validate runs the validation rules defined in the design.
 func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
@@ -677,9 +1716,11 @@ func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate()
 	ctrlT = `// {{ .Resource }}Controller is the controller interface for the {{ .Resource }} actions.
 type {{ .Resource }}Controller interface {
 	goa.Muxer
-{{ if .FileServers }}	goa.FileServer
-{{ end }}{{ range .Actions }}	{{ .Name }}(*{{ .Context }}) error
-{{ end }}}
+{{ if .HasActions "Raw" }}	goa.RawMuxer
+{{ end }}{{ if .HasActions "LazyBody" }}	goa.LazyMuxer
+{{ end }}{{ if .FileServers }}	goa.FileServer
+{{ end }}{{ range .Actions }}{{ if not .ProxyURL }}	{{ .Name }}(*{{ .Context }}) error
+{{ end }}{{ end }}}
 `
 
 	// serviceT generates the service initialization code.
@@ -689,30 +1730,74 @@ type {{ .Resource }}Controller interface {
 func initService(service *goa.Service) {
 	// Setup encoders and decoders
 {{ range .Encoders }}{{/*
-*/}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
+*/}}	service.Encoder.RegisterMissing({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
 {{ end }}{{ range .Decoders }}{{/*
-*/}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
+*/}}	service.Decoder.RegisterMissing({{ .PackageName }}.{{ .Function }}, "{{ join .MIMETypes "\", \"" }}")
 {{ end }}
 
 	// Setup default encoder and decoder
 {{ range .Encoders }}{{ if .Default }}{{/*
-*/}}	service.Encoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
+*/}}	service.Encoder.RegisterMissing({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
-*/}}	service.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
-{{ end }}{{ end }}}
+*/}}	service.Decoder.RegisterMissing({{ .PackageName }}.{{ .Function }}, "*/*")
+{{ end }}{{ end }}{{ if or .SensitiveNames .SensitiveTypes }}
+	// Setup sensitive data redaction
+{{ with .SensitiveNames }}	goa.RegisterSensitive({{ range $i, $n := . }}{{ if $i }}, {{ end }}{{ printf "%q" $n }}{{ end }})
+{{ end }}{{ range .SensitiveTypes }}{{ if .Fields }}{{/*
+*/}}	goa.RegisterSensitiveFields((*{{ .GoType }})(nil){{ range .Fields }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if .Identifier }}{{/*
+*/}}	goa.RegisterSensitiveMediaType({{ printf "%q" .Identifier }}, (*{{ .GoType }})(nil))
+{{ end }}{{ end }}{{ end }}{{ with .ErrorEnvelope }}
+	// Setup error response envelope
+	if service.ErrorEncoder == nil {
+		envelope := {{ . }}
+		service.ErrorEncoder = envelope.Encoder(service)
+	}
+{{ end }}}
 `
 
 	// mountT generates the code for a resource "Mount" function.
 	// template input: *ControllerTemplateData
-	mountT = `
-// Mount{{ .Resource }}Controller "mounts" a {{ .Resource }} resource controller on the given service.
-func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Controller) {
-	initService(service)
+	mountT = `{{ $res := .Resource }}{{ if .Middleware }}
+// {{ $res }}Middleware lists the implementations of the middleware used by the {{ $res }} actions.
+type {{ $res }}Middleware struct {
+{{ range .Middleware }}	// {{ goify . true }} implements the {{ printf "%q" . }} middleware.
+	{{ goify . true }} goa.Middleware
+{{ end }}}
+{{ end }}
+// Mount{{ $res }}Controller "mounts" a {{ $res }} resource controller on the given service.{{ if .Middleware }}
+// The middleware are applied to the actions in the order given by the design, Mount{{ $res }}Controller
+// panics if the implementation of a middleware is missing.{{ end }}
+func Mount{{ $res }}Controller(service *goa.Service, ctrl {{ $res }}Controller{{ if .Middleware }}, mw {{ $res }}Middleware{{ end }}) {
+{{ range .Middleware }}	if mw.{{ goify . true }} == nil {
+		panic("missing implementation of the {{ . }} middleware of the {{ $res }} controller")
+	}
+{{ end }}	initService(service)
 	var h goa.Handler
-{{ $res := .Resource }}{{ if .Origins }}{{ range .PreflightPaths }}{{/*
+{{ if .Origins }}{{ range .PreflightPaths }}{{/*
 */}}	service.Mux.Handle("OPTIONS", {{ printf "%q" . }}, ctrl.MuxHandler("preflight", handle{{ $res }}Origin(cors.HandlePreflight()), nil))
-{{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}
+{{ end }}{{ end }}{{ range .Actions }}{{ $action := . }}{{ if .ProxyURL }}
+	proxy{{ .Name }} := service.ProxyHandler({{ printf "%q" .ProxyURL }})
 	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Validate the request
+		if _, err := New{{ .Context }}(ctx, req, service); err != nil {
+			return err
+		}
+{{ if .LazyBody }}		// Load the request body, deferred until the middleware accepts the request
+		if err := goa.LoadRequestBody(ctx); err != nil {
+			return err
+		}
+{{ end }}{{ if and .Payload (not .PayloadOptional) }}		if goa.ContextRequest(ctx).Payload == nil {
+			return goa.MissingPayloadError()
+		}
+{{ end }}		return proxy{{ .Name }}(ctx, rw, req)
+	}
+{{ else }}
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -722,7 +1807,13 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 		if err != nil {
 			return err
 		}
-{{ if .Payload }}		// Build the payload
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, {{ with .FaultResponse }}rctx.{{ . }}{{ else }}nil{{ end }})
+{{ if .LazyBody }}		// Load the request body, deferred until the middleware accepts the request
+		if err := goa.LoadRequestBody(ctx); err != nil {
+			return err
+		}
+{{ end }}{{ if .Payload }}		// Build the payload
 		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
 			rctx.Payload = rawPayload.({{ gotyperef .Payload nil 1 false }})
 {{ if not .PayloadOptional }}		} else {
@@ -730,17 +1821,74 @@ func Mount{{ .Resource }}Controller(service *goa.Service, ctrl {{ .Resource }}Co
 {{ end }}		}
 {{ end }}		return ctrl.{{ .Name }}(rctx)
 	}
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if .ClientClosed }}	h = goa.ClientClosedHandler(h)
+{{ end }}{{ if .Audited }}	h = middleware.Audit(middleware.DefaultAuditSink)(h)
+{{ end }}{{ if .Recorded }}	h = middleware.Record(middleware.DefaultRecordSink, {{ printf "%q" .ResourceName }}, {{ printf "%q" .DesignName }})(h)
+{{ end }}{{ if .CacheTTL }}	h = middleware.Cache(middleware.DefaultCacheStore, {{ .CacheTTL }}{{ range .CacheKeys }}, {{ printf "%q" . }}{{ end }})(h)
+{{ end }}{{ if .Coalesced }}	h = middleware.Coalesce(middleware.DefaultCoalesceGroup)(h)
+{{ end }}{{ range .Middleware }}	h = mw.{{ . }}(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if $.CSRF }}	h = middleware.CSRF()(h)
+{{ end }}{{ with .Deprecation }}	h = middleware.Deprecated({{ .Since }}, {{ .Sunset }})(h)
+{{ end }}{{ with .Priority }}{{ $p := . }}	h = middleware.Shed(middleware.DefaultLoadSignal, middleware.DefaultShedRetryAfter, {{ printf "%q" .Header }}, {{ printf "%q" .DefaultLevel }}, map[string]float64{ {{ range .PriorityLevels }}{{ printf "%q" . }}: {{ index $p.Thresholds . }}, {{ end }}})(h)
+{{ end }}{{ with .Concurrency }}	h = middleware.LimitConcurrency({{ .Limit }}, {{ .Timeout }})(h)
+{{ end }}{{ with .Flag }}	h = middleware.Flag(middleware.DefaultFlagProvider, {{ printf "%q" .Name }}, {{ .Status }})(h)
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
-{{ end }}{{ range .Routes }}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" $action.DesignName }}, h, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }}))
+{{ end }}{{ range .Routes }}{{ $route := . }}{{ $h := "h" }}{{ with $action.LogSampler }}{{ $h = printf "middleware.LogAccess(%q, %s)(h)" (printf "%s %s" $route.Verb $route.FullPath) . }}{{ end }}{{/*
+*/}}	service.Mux.Handle("{{ .Verb }}", {{ printf "%q" .FullPath }}, {{ if $action.Raw }}ctrl.RawMuxHandler({{ printf "%q" $action.DesignName }}, {{ $h }})){{ else }}ctrl.{{ if $action.LazyBody }}Lazy{{ end }}MuxHandler({{ printf "%q" $action.DesignName }}, {{ $h }}, {{ if $action.Payload }}{{ $action.Unmarshal }}{{ else }}nil{{ end }})){{ end }}
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" $action.Name }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ end }}{{ with $action.BatchRoute }}{{ $route := index $action.Routes 0 }}
+	h = service.BatchHandler({{ printf "%q" $route.Verb }}, {{ printf "%q" $route.FullPath }})
+{{ with $action.Security }}	h = handleSecurity({{ printf "%q" .Scheme.SchemeName }}, h{{ range .Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if $.CSRF }}	h = middleware.CSRF()(h)
+{{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
+{{ end }}	service.Mux.Handle({{ printf "%q" .Verb }}, {{ printf "%q" .FullPath }}, ctrl.MuxHandler({{ printf "%q" (printf "%s batch" $action.DesignName) }}, h, nil))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "action", {{ printf "%q" (printf "%sBatch" $action.Name) }}, "route", {{ printf "%q" (printf "%s %s" .Verb .FullPath) }}{{ with $action.Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
 {{ end }}{{ end }}{{ range .FileServers }}
 	h = ctrl.FileHandler({{ printf "%q" .RequestPath }}, {{ printf "%q" .FilePath }})
-{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
+{{ if .Signed }}	h = middleware.VerifySignedURL(middleware.DefaultURLSigningKey)(h)
+{{ end }}{{ if .Security }}	h = handleSecurity({{ printf "%q" .Security.Scheme.SchemeName }}, h{{ range .Security.Scopes }}, {{ printf "%q" . }}{{ end }})
 {{ end }}{{ if $.Origins }}	h = handle{{ $res }}Origin(h)
 {{ end }}	service.Mux.Handle("GET", "{{ .RequestPath }}", ctrl.MuxHandler("serve", h, nil))
 	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "files", {{ printf "%q" .FilePath }}, "route", {{ printf "%q" (printf "GET %s" .RequestPath) }}{{ with .Security }}, "security", {{ printf "%q" .Scheme.SchemeName }}{{ end }})
+{{ end }}{{ if .CSRFTokenPath }}
+	h = middleware.CSRFTokenHandler()
+{{ if .Origins }}	h = handle{{ $res }}Origin(h)
+{{ end }}	service.Mux.Handle("GET", {{ printf "%q" .CSRFTokenPath }}, ctrl.MuxHandler("csrf", h, nil))
+	service.LogInfo("mount", "ctrl", {{ printf "%q" $res }}, "csrf", "token", "route", {{ printf "%q" (printf "GET %s" .CSRFTokenPath) }})
 {{ end }}}
+`
+
+	// signedURLT generates the functions that sign the URLs of the file servers.
+	// template input: *ControllerTemplateData
+	signedURLT = `{{ range .SignedFiles }}// {{ .Func }} returns the URL of {{ if .Wildcard }}the file {{ .Wildcard }} served under {{ .Path }}{{ else }}{{ .Path }}{{ end }} signed
+// with key and valid until expires.
+func {{ .Func }}(key []byte, {{ with .Wildcard }}{{ . }} string, {{ end }}expires time.Time) string {
+	return middleware.SignURL(key, {{ printf "%q" .Path }}{{ with .Wildcard }}+strings.TrimPrefix({{ . }}, "/"){{ end }}, expires)
+}
+
+{{ end }}`
+
+	// errorEncoderT generates the function that overrides the error encoder of a resource
+	// controller.
+	// template input: *ControllerTemplateData
+	errorEncoderT = `// Set{{ .Resource }}ErrorEncoder overrides the encoding of the errors returned by the given
+// {{ .Resource }} actions, by all the {{ .Resource }} actions if none is given. It panics if an
+// action does not belong to the {{ .Resource }} resource.
+func Set{{ .Resource }}ErrorEncoder(ctrl *goa.Controller, enc goa.ErrorEncoder, actions ...string) {
+	if len(actions) == 0 {
+		ctrl.ErrorEncoder = enc
+		return
+	}
+	for _, action := range actions {
+		switch action {
+		case {{ range $i, $a := .Actions }}{{ if $i }}, {{ end }}{{ printf "%q" $a.DesignName }}{{ end }}:
+			ctrl.SetErrorEncoder(action, enc)
+		default:
+			panic(fmt.Sprintf("unknown {{ .Resource }} action %q", action))
+		}
+	}
+}
 `
 
 	// handleCORST generates the code that checks whether a CORS request is authorized
@@ -777,22 +1925,49 @@ func handle{{ .Resource }}Origin(h goa.Handler) goa.Handler {
 
 	// unmarshalT generates the code for an action payload unmarshal function.
 	// template input: *ControllerTemplateData
-	unmarshalT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `{{ range .Actions }}{{ if .Payload }}
+	unmarshalT = `{{ define "Coerce" }}` + coerceT + `{{ end }}` + `{{ range .Actions }}{{ if .Payload }}{{ if and .PayloadLimits (not .PayloadMultipart) }}
+// {{ .Unmarshal }}Limits lists the maximum lengths of the payload collections enforced while
+// decoding the request body.
+var {{ .Unmarshal }}Limits = {{ .PayloadLimits }}
+{{ end }}
 // {{ .Unmarshal }} unmarshals the request body into the context request data Payload field.
 func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Request) error {
 	{{ if .PayloadMultipart}}var err error
 	var payload {{ gotypename .Payload nil 1 true }}
 	{{ $o := .Payload.ToObject }}{{ range $name, $att := $o -}}
-	{{ if eq $att.Type.Kind 13 }}_, raw{{ goify $name true }}, err2 := req.FormFile("{{ $name }}"){{ else }}{{/*
-*/}}	raw{{ goify $name true }} := req.FormValue("{{ $name }}"){{ end }}
-{{ template "Coerce" (newCoerceData $name $att true (printf "payload.%s" (goifyatt $att $name true)) 1) }}{{ end }}{{/*
+	{{ $key := $att.AttributeKey $name }}{{ if eq $att.Type.Kind 13 }}_, raw{{ goify $key true }}, err2 := req.FormFile("{{ $key }}"){{ else }}{{/*
+*/}}	raw{{ goify $key true }} := req.FormValue("{{ $key }}"){{ end }}
+{{ template "Coerce" (newCoerceData $key $att true (printf "payload.%s" (goifyatt $att $name true)) 1) }}{{ end }}{{/*
 */}}	if err != nil {
 		return err
-	}{{ else if .Payload.IsObject }}payload := &{{ gotypename .Payload nil 1 true }}{}
-	if err := service.DecodeRequest(req, payload); err != nil {
+	}{{ else if .Payload.IsObject }}{{ if .PayloadVersions }}{{ $payload := .Payload }}switch goa.RequestSchemaVersion(req, {{ printf "%q" .SchemaVersionHeader }}) {
+{{ range .PayloadVersions }}	case {{ printf "%q" .Version }}:
+		v := &{{ gotypename .Type nil 1 true }}{}
+		if err := service.DecodeRequest(req, v); err != nil {
+			return err
+		}{{ if finalizeCode .Type.AttributeDefinition "v" 2 }}
+		v.Finalize(){{ end }}{{ if sanitizeCode .Type.AttributeDefinition "v" 2 }}
+		v.Sanitize(){{ end }}{{ if validationCode .Type.AttributeDefinition false false false "v" "raw" 2 true }}
+		if err := v.Validate(); err != nil {
+			return err
+		}{{ end }}
+		payload, err := {{ .Convert }}(v.Publicize())
+		if err != nil {
+			return err
+		}{{ if validationCode $payload.AttributeDefinition false false false "payload" "raw" 2 false }}
+		if err := payload.Validate(); err != nil {
+			return err
+		}{{ end }}{{ if eq $.NilCollections "empty" }}
+		goa.FillNilCollections(payload){{ end }}
+		goa.ContextRequest(ctx).Payload = payload
+		return nil
+{{ end }}	}
+	{{ end }}payload := &{{ gotypename .Payload nil 1 true }}{}
+	if err := {{ if .PayloadLimits }}service.DecodeLimitedRequest(req, payload, {{ .Unmarshal }}Limits){{ else }}service.DecodeRequest(req, payload){{ end }}; err != nil {
 		return err
 	}{{ $assignment := finalizeCode .Payload.AttributeDefinition "payload" 1 }}{{ if $assignment }}
-	payload.Finalize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
+	payload.Finalize(){{ end }}{{ if sanitizeCode .Payload.AttributeDefinition "payload" 1 }}
+	payload.Sanitize(){{ end }}{{ else }}var payload {{ gotypename .Payload nil 1 false }}
 	if err := service.DecodeRequest(req, &payload); err != nil {
 		return err
 	}{{ end }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 true }}{{ if $validation }}
@@ -800,7 +1975,8 @@ func {{ .Unmarshal }}(ctx context.Context, service *goa.Service, req *http.Reque
 		// Initialize payload with private data structure so it can be logged
 		goa.ContextRequest(ctx).Payload = payload
 		return err
-	}{{ end }}
+	}{{ end }}{{ if eq $.NilCollections "empty" }}
+	goa.FillNilCollections({{ if not .Payload.IsObject }}&{{ end }}payload){{ end }}
 	goa.ContextRequest(ctx).Payload = payload{{ if .Payload.IsObject }}.Publicize(){{ end }}
 	return nil
 }
@@ -823,15 +1999,25 @@ func {{ .Name }}Href({{ if .CanonicalParams }}{{ join .CanonicalParams ", " }} i
 //
 // Identifier: {{ .Identifier }}{{ $typeName := gotypename . .AllRequired 0 false }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-
+{{ timeCodec .AttributeDefinition $typeName false true }}
 {{ $validation := validationCode .AttributeDefinition false false false "mt" "response" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} media type instance.
 func (mt {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }
+{{ end }}{{ if .Diff }}
+// Diff{{ $typeName }} returns the paths of the fields whose values differ between a and b, see
+// goa.Diff.
+func Diff{{ $typeName }}(a, b {{ gotyperef . .AllRequired 0 false }}) []string {
+	return goa.Diff(a, b)
+}
 {{ end }}
 `
 
+	// mediaTypeEnumsT generates the enum types shared by the views of a media type.
+	// template input: *design.MediaTypeDefinition
+	mediaTypeEnumsT = `{{ goenumtypedefs . }}`
+
 	// mediaTypeLinkT generates the code for a media type link.
 	// template input: MediaTypeLinkTemplateData
 	mediaTypeLinkT = `// {{ gotypedesc . true }}{{ $typeName := gotypename . .AllRequired 0 false }}
@@ -843,15 +2029,20 @@ func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 }{{ end }}
 `
 
-	// userTypeT generates the code for a user type.
+	// userTypeT generates the code for a user type. Only the private type is generated for the
+	// user types defined in other packages, see the struct:pkg:path metadata.
 	// template input: UserTypeTemplateData
 	userTypeT = `// {{ gotypedesc . false }}{{ $privateTypeName := gotypename . .AllRequired 0 true }}
 type {{ $privateTypeName }} {{ gotypedef . 0 true true }}
-{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
+{{ timeCodec .AttributeDefinition $privateTypeName true false }}{{ $assignment := finalizeCode .AttributeDefinition "ut" 1 }}{{ if $assignment }}// Finalize sets the default values for {{$privateTypeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 true }}) Finalize() {
 {{ $assignment }}
 }{{ end }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "request" 1 true }}{{ if $validation }}// Validate validates the {{$privateTypeName}} type instance.
+{{ $sanitization := sanitizeCode .AttributeDefinition "ut" 1 }}{{ if $sanitization }}// Sanitize applies the sanitizers defined in the design to {{$privateTypeName}} type instance.
+func (ut {{ gotyperef . .AllRequired 0 true }}) Sanitize() {
+{{ $sanitization }}
+}
+{{ end }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "request" 1 true }}{{ if $validation }}// Validate validates the {{$privateTypeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 true }}) Validate() (err error) {
 {{ $validation }}
 	return
@@ -864,15 +2055,289 @@ func (ut {{ gotyperef . .AllRequired 0 true }}) Publicize() {{ gotyperef . .AllR
 	return &pub
 }
 
+// Decode{{ goify .TypeName true }} decodes {{ $typeName }} from its wire representation read from body the same
+// way request bodies are: the default values are set and the result is validated. contentType
+// selects the decoder, e.g. service.Decoder or a decoder built with goa.NewHTTPDecoder.
+func Decode{{ goify .TypeName true }}(decoder *goa.HTTPDecoder, body io.Reader, contentType string) ({{ gotyperef . .AllRequired 0 false }}, error) {
+	var ut {{ $privateTypeName }}
+	if err := decoder.Decode(&ut, body, contentType); err != nil {
+		return nil, err
+	}
+{{ if $assignment }}	ut.Finalize()
+{{ end }}{{ if $sanitization }}	ut.Sanitize()
+{{ end }}{{ if $validation }}	if err := ut.Validate(); err != nil {
+		return nil, err
+	}
+{{ end }}	return ut.Publicize(), nil
+}
+{{ if not (external .) }}
 // {{ gotypedesc . true }}
 type {{ $typeName }} {{ gotypedef . 0 true false }}
-{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
+{{ timeCodec .AttributeDefinition $typeName false false }}{{ goenumtypedefs . }}{{ $validation := validationCode .AttributeDefinition false false false "ut" "type" 1 false }}{{ if $validation }}// Validate validates the {{$typeName}} type instance.
 func (ut {{ gotyperef . .AllRequired 0 false }}) Validate() (err error) {
 {{ $validation }}
 	return
 }{{ end }}
+{{ end }}`
+
+	// jsonrpcT generates the code that mounts the JSON-RPC endpoint.
+	// template input: map[string]interface{}
+	jsonrpcT = `// MountJSONRPC mounts the JSON-RPC 2.0 endpoint onto the service at {{ printf "%q" .Path }}.
+// The endpoint dispatches the calls to the handlers of the mounted controllers.
+func MountJSONRPC(service *goa.Service) {
+	service.MountJSONRPC({{ printf "%q" .Path }}, []*goa.JSONRPCMethod{
+{{ range .Methods }}		{Name: {{ printf "%q" .Name }}, Method: {{ printf "%q" .Verb }}, Path: {{ printf "%q" .Path }}{{ if .Separators }}, Separators: map[string]string{ {{ range $n, $s := .Separators }}{{ printf "%q" $n }}: {{ printf "%q" $s }}, {{ end }}}{{ end }}},
+{{ end }}	})
+	service.LogInfo("mount", "jsonrpc", {{ printf "%q" .Path }})
+}
+`
+
+	// healthT generates the code that mounts the health check endpoints.
+	// template input: []string
+	healthT = `// MountHealthChecks mounts the health check endpoints of the API servers onto the service.
+func MountHealthChecks(service *goa.Service) {
+{{ range . }}	service.MountHealthCheck({{ printf "%q" . }})
+{{ end }}}
+`
+
+	// uploadsT generates the code that mounts the upload offset endpoints.
+	// template input: []*UploadData
+	uploadsT = `{{ range . }}// {{ .Name }} mounts the endpoints that report the offset of the uploads created by the
+// {{ .ResourceName }} {{ .ActionName }} action and that receive their chunks onto the service.
+// store keeps track of the offsets and stores the content of the uploads.
+func {{ .Name }}(service *goa.Service, store goa.UploadStore) {
+	service.MountUpload({{ printf "%q" .Path }}, store)
+}
+
+{{ end }}`
+
+	// jobsT generates the code that mounts the job status endpoint.
+	// template input: map[string]interface{}
+	jobsT = `// JobStore retrieves the status of the jobs started by the asynchronous actions.
+type JobStore interface {
+	// Job returns the status of the job with the given identifier, nil if there is no such job.
+	Job(ctx context.Context, id string) (*GoaJob, error)
+}
+
+// JobHref returns the path of the status endpoint of the job with the given identifier.
+func JobHref(id string) string {
+	return {{ printf "%q" .Prefix }} + url.PathEscape(id)
+}
+
+// MountJobStatus mounts the endpoint that renders the status of the jobs started by the
+// asynchronous actions onto the service. The endpoint responds with status code 404 if store
+// does not know the job.
+func MountJobStatus(service *goa.Service, store JobStore) {
+	ctrl := service.NewController("JobStatusController")
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		id := goa.ContextRequest(ctx).Params.Get("jobID")
+		job, err := store.Job(ctx, id)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return goa.ErrNotFound("job not found", "id", id)
+		}
+		href := JobHref(id)
+		job.Href = &href
+		goa.ContextResponse(ctx).Header().Set("Content-Type", {{ printf "%q" .ContentType }})
+		return service.Send(ctx, 200, job)
+	}
+	service.Mux.Handle("GET", {{ printf "%q" .Path }}, ctrl.MuxHandler("status", h, nil))
+	service.LogInfo("mount", "ctrl", "JobStatus", "action", "Status", "route", {{ printf "%q" (printf "GET %s" .Path) }})
+}
+`
+
+	// errorClassesT generates the error classes defined with ErrorClass.
+	// template input: []*design.ResponseDefinition
+	errorClassesT = `{{ range . }}// Err{{ goify .Name true }} creates the errors of the {{ printf "%q" .Name }} class, they are rendered with
+// status code {{ .Status }}.
+var Err{{ goify .Name true }} = goa.NewErrorClass({{ printf "%q" .Name }}, {{ .Status }})
+
+{{ end }}`
+
+	// eventsT generates the code that consumes the events.
+	// template input: []*EventData
+	eventsT = `// ConsumeEvents subscribes to the topics of the events and dispatches the messages to the
+// handlers of the mounted controllers. The consumers that use the same group share the messages.
+func ConsumeEvents(ctx context.Context, service *goa.Service, sub goa.Subscriber, group string) error {
+	return service.ConsumeEvents(ctx, sub, group, []*goa.EventMethod{
+{{ range . }}		{Topic: {{ printf "%q" .Topic }}, Method: {{ printf "%q" .Verb }}, Path: {{ printf "%q" .Path }}},
+{{ end }}	})
+}
+`
+
+	// routesT generates the route table and the function that mounts its debug endpoint.
+	// template input: map[string]interface{}
+	routesT = `// Routes lists the routes mounted by the generated controllers.
+var Routes = []*goa.RouteInfo{
+{{ range .Routes }}	{Method: {{ printf "%q" .Verb }}, Pattern: {{ printf "%q" .Path }}, Service: {{ printf "%q" $.Service }}, Resource: {{ printf "%q" .Resource }}, Endpoint: {{ printf "%q" .Endpoint }}{{ with .Security }}, Scheme: {{ printf "%q" .Scheme.SchemeName }}{{ if .Scopes }}, Scopes: []string{ {{ range .Scopes }}{{ printf "%q" . }}, {{ end }}}{{ end }}{{ end }}{{ with .Flag }}, Flag: {{ printf "%q" . }}{{ end }}{{ if .Middleware }}, Middleware: []string{ {{ range .Middleware }}{{ printf "%q" . }}, {{ end }}}{{ end }}},
+{{ end }}}
+
+// MountRoutes mounts the endpoint that renders Routes as JSON onto the service under the given
+// path, e.g. "/debug/routes".
+func MountRoutes(service *goa.Service, path string) {
+	service.MountRoutes(path, Routes)
+}
 `
 
+	// typesT generates the type registry.
+	// template input: map[string]interface{}
+	typesT = `// Types indexes the Go types generated from the user types and media type views by design name.
+var Types = goa.TypeRegistry{
+{{ range .Types }}	{{ printf "%q" .Name }}: {
+		Name:    {{ printf "%q" .Name }},
+		Service: {{ printf "%q" $.Service }},
+{{ if .Identifier }}		Identifier: {{ printf "%q" .Identifier }},
+		View:       {{ printf "%q" .View }},
+{{ end }}		Type:    reflect.TypeOf((*{{ .GoType }})(nil)).Elem(),
+{{ if .Schema }}		Schema:  json.RawMessage({{ printf "%q" .Schema }}),
+{{ end }}	},
+{{ end }}}
+`
+
+	// providersT generates the dependency injection providers.
+	// template input: map[string]interface{}
+	providersT = `{{ if eq .Framework "wire" }}// ProviderSet provides the service, mounts the controllers given by the application and
+// provides the HTTP server of the service. The application binds the controller interfaces to
+// its implementations, e.g. with wire.Bind.
+var ProviderSet = wire.NewSet(NewService, wire.Struct(new(Controllers), "*"), MountControllers, NewServer)
+{{ else }}// Module provides the service, mounts the controllers provided by the application and provides
+// the HTTP server of the service. The application provides the controller interfaces, e.g. with
+// fx.Annotate and fx.As.
+var Module = fx.Options(fx.Provide(NewService, MountControllers, NewServer))
+{{ end }}
+// Controllers lists the controllers mounted by MountControllers.
+type Controllers struct {
+{{ if eq .Framework "fx" }}	fx.In
+
+{{ end }}{{ range .Controllers }}	{{ . }} {{ . }}Controller
+{{ if index $.Middleware . }}	{{ . }}Middleware {{ . }}Middleware
+{{ end }}{{ end }}}
+
+// Mounted is provided by MountControllers once the controllers are mounted.
+type Mounted struct{}
+
+// NewService creates the {{ printf "%q" .Service }} service.
+func NewService() *goa.Service {
+	return goa.New({{ printf "%q" .Service }})
+}
+
+// MountControllers mounts the given controllers onto the service.
+func MountControllers(service *goa.Service, ctrls Controllers) Mounted {
+{{ range .Controllers }}	Mount{{ . }}Controller(service, ctrls.{{ . }}{{ if index $.Middleware . }}, ctrls.{{ . }}Middleware{{ end }})
+{{ end }}	return Mounted{}
+}
+
+// NewServer returns the HTTP server of the service once the controllers are mounted.
+func NewServer(service *goa.Service, _ Mounted) *http.Server {
+	return service.Server
+}
+`
+
+	// headersT generates the header name constants and their typed accessors.
+	// template input: []*HeaderData
+	headersT = `// Names of the request and response headers used by the API.
+const (
+{{ range . }}	// Header{{ .Name }} is the name of the {{ .Key }} header.
+	Header{{ .Name }} = {{ printf "%q" .Key }}
+{{ end }})
+{{ range . }}{{ if .Type }}{{ $kind := .Type.Kind }}{{ $zero := "0" }}{{ if eq $kind 1 }}{{ $zero = "false" }}{{ else if eq $kind 4 }}{{ $zero = "\"\"" }}{{ else if eq $kind 5 }}{{ $zero = "time.Time{}" }}{{ end }}
+// GetHeader{{ .Name }} returns the value of the {{ .Key }} header, it returns an error if the header
+// is missing{{ if ne $kind 4 }} or if its value is invalid{{ end }}.
+func GetHeader{{ .Name }}(h http.Header) ({{ gotyperef .Type nil 0 false }}, error) {
+	raw := h.Get(Header{{ .Name }})
+	if raw == "" {
+		return {{ $zero }}, goa.MissingHeaderError(Header{{ .Name }})
+	}
+{{ if eq $kind 4 }}	return raw, nil
+{{ else }}	v, err := {{ if eq $kind 1 }}strconv.ParseBool(raw){{ else if eq $kind 2 }}strconv.Atoi(raw){{ else if eq $kind 3 }}strconv.ParseFloat(raw, 64){{ else }}time.Parse(time.RFC3339, raw){{ end }}
+	if err != nil {
+		return {{ $zero }}, goa.InvalidParamTypeError(Header{{ .Name }}, raw, {{ printf "%q" .Type.Name }})
+	}
+	return v, nil
+{{ end }}}
+
+// SetHeader{{ .Name }} sets the {{ .Key }} header to v.
+func SetHeader{{ .Name }}(h http.Header, v {{ gotyperef .Type nil 0 false }}) {
+	h.Set(Header{{ .Name }}, {{ if eq $kind 1 }}strconv.FormatBool(v){{ else if eq $kind 2 }}strconv.Itoa(v){{ else if eq $kind 3 }}strconv.FormatFloat(v, 'f', -1, 64){{ else if eq $kind 4 }}v{{ else }}v.Format(time.RFC3339){{ end }})
+}
+{{ end }}{{ end }}`
+
+	// schemaVersionsT generates the functions that convert the schema revisions.
+	// template input: []*SchemaVersionData
+	schemaVersionsT = `{{ range . }}
+// {{ .Convert }} converts {{ .From }} into {{ .To }} for the {{ printf "%q" .Version }} schema
+// revision. It copies the attributes with the same name by default, replace it to map renamed or
+// restructured attributes.
+var {{ .Convert }} = func(v {{ .From }}) ({{ .To }}, error) {
+	var res {{ .ToName }}
+	if err := goa.ConvertSchema(v, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+{{ end }}`
+
+	// headerGroupsT generates the functions that map the header groups to their types.
+	// template input: []*HeaderGroupData
+	headerGroupsT = `{{ range . }}
+// Get{{ .Name }}Headers builds {{ .TypeName }} from the {{ .Prefix }}* headers of the {{ .Group }}
+// header group. It returns nil if none of the headers is set and an error if a required header is
+// missing or if a value is invalid.
+func Get{{ .Name }}Headers(h http.Header) ({{ .TypeRef }}, error) {
+	var (
+		v   {{ .TypeName }}
+		set bool
+		err error
+	)
+{{ range .Fields }}	if raw := h.Get({{ printf "%q" .Key }}); raw != "" {
+		set = true
+{{ if .Parse }}		if x, err2 := {{ .Parse }}; err2 != nil {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError({{ printf "%q" .Key }}, raw, {{ printf "%q" .TypeName }}))
+		} else {
+			v.{{ .Field }} = {{ if .Pointer }}&{{ end }}x
+		}
+{{ else }}		v.{{ .Field }} = {{ if .Pointer }}&{{ end }}raw
+{{ end }}	}{{ if .Required }} else {
+		err = goa.MergeErrors(err, goa.MissingHeaderError({{ printf "%q" .Key }}))
+	}{{ else if .Default }} else {
+		v.{{ .Field }} = {{ .Default }}
+	}{{ end }}
+{{ end }}	if !set {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+{{ if .Validate }}	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+{{ end }}	return &v, nil
+}
+
+// Set{{ .Name }}Headers sets the {{ .Prefix }}* headers of the {{ .Group }} header group from v,
+// the headers of the nil fields are not set. It does nothing if v is nil.
+func Set{{ .Name }}Headers(h http.Header, v {{ .TypeRef }}) {
+	if v == nil {
+		return
+	}
+{{ range .Fields }}{{ if .Pointer }}	if v.{{ .Field }} != nil {
+		h.Set({{ printf "%q" .Key }}, {{ .Format }})
+	}
+{{ else }}	h.Set({{ printf "%q" .Key }}, {{ .Format }})
+{{ end }}{{ end }}}
+{{ end }}`
+
+	// ctxHeaderGroupsT generates the context methods that set the response header groups.
+	// template input: *ContextTemplateData
+	ctxHeaderGroupsT = `{{ range .ResponseHeaderGroups }}
+// Set{{ .Name }}Headers sets the {{ .Prefix }}* response headers from v.
+func (ctx *{{ $.Name }}) Set{{ .Name }}Headers(v {{ .TypeRef }}) {
+	Set{{ .Name }}Headers(ctx.ResponseData.Header(), v)
+}
+{{ end }}`
+
 	// securitySchemesT generates the code for the security module.
 	// template input: []*design.SecuritySchemeDefinition
 	securitySchemesT = `
@@ -902,7 +2367,22 @@ func {{ $funcName }}() *goa.{{ .Context }} {
 {{ end }}{{/*
 */}}		},{{ end }}{{/*
 */}}{{ else if eq .Context "BasicAuthSecurity" }}{{/*
-*/}}{{ else if eq .Context "JWTSecurity" }}{{/*
+*/}}{{ else if eq .Context "HMACSecurity" }}{{/*
+*/}}		Name: {{ printf "%q" .Name }},
+{{ else if eq .Context "CookieSecurity" }}{{/*
+*/}}		Name:      {{ printf "%q" .Name }},
+		Encrypted: {{ .Encrypted }},
+{{ else if eq .Context "SPIFFESecurity" }}{{/*
+*/}}		TrustDomain: {{ printf "%q" .TrustDomain }},{{ with .Identities }}
+		Identities: map[string][]string{
+{{ range $k, $v := . }}			{{ printf "%q" $k }}: { {{ range $v }}{{ printf "%q" . }}, {{ end }}},
+{{ end }}{{/*
+*/}}		},{{ end }}{{ with .Scopes }}
+		Scopes: map[string]string{
+{{ range $k, $v := . }}			{{ printf "%q" $k }}: {{ printf "%q" $v }},
+{{ end }}{{/*
+*/}}		},{{ end }}
+{{ else if eq .Context "JWTSecurity" }}{{/*
 */}}		In:   {{ if eq .In "header" }}goa.LocHeader{{ else }}goa.LocQuery{{ end }},
 		Name:             {{ printf "%q" .Name }},
 		TokenURL:         {{ printf "%q" .TokenURL }},{{ with .Scopes }}
@@ -915,7 +2395,37 @@ func {{ $funcName }}() *goa.{{ .Context }} {
 {{ if .Description }} def.Description = {{ printf "%q" .Description }}
 {{ end }}	return &def
 }
+{{ if .SessionType }}{{ $name := goify .SchemeName true }}{{ $type := gotypename .SessionType nil 0 false }}
+// New{{ $name }}Middleware creates the middleware that authenticates the requests with the
+// {{ .SchemeName }} session cookie using the given keys, mount it with Use{{ $name }}Middleware.
+func New{{ $name }}Middleware(keys cookieauth.KeyProvider) goa.Middleware {
+	return cookieauth.New(New{{ $name }}Security(), keys, func() interface{} { return &{{ $type }}{} })
+}
+
+// Context{{ $name }}Session returns the {{ .SchemeName }} session of the request, nil if the
+// request was not authenticated by the {{ .SchemeName }} middleware.
+func Context{{ $name }}Session(ctx context.Context) *{{ $type }} {
+	s, _ := cookieauth.ContextSession(ctx).(*{{ $type }})
+	return s
+}
+
+// Set{{ $name }}Cookie issues the {{ .SchemeName }} session cookie that expires after maxAge,
+// the cookie lasts for the browser session if maxAge is 0.
+func Set{{ $name }}Cookie(ctx context.Context, rw http.ResponseWriter, keys cookieauth.KeyProvider, s *{{ $type }}, maxAge time.Duration) error {
+	return cookieauth.SetCookie(ctx, rw, New{{ $name }}Security(), keys, s, maxAge)
+}
 
+// Clear{{ $name }}Cookie deletes the {{ .SchemeName }} session cookie.
+func Clear{{ $name }}Cookie(rw http.ResponseWriter) {
+	cookieauth.ClearCookie(rw, New{{ $name }}Security())
+}
+{{ end }}{{ if eq .Context "SPIFFESecurity" }}{{ $name := goify .SchemeName true }}
+// New{{ $name }}Middleware creates the middleware that authorizes the requests using the SPIFFE ID
+// of the client certificate, mount it with Use{{ $name }}Middleware.
+func New{{ $name }}Middleware() goa.Middleware {
+	return spiffeauth.New(New{{ $name }}Security())
+}
+{{ end }}
 {{ end }}// handleSecurity creates a handler that runs the auth middleware for the security scheme.
 func handleSecurity(schemeName string, h goa.Handler, scopes ...string) goa.Handler {
 	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -91,6 +91,7 @@ var _ = Describe("ContextsWriter", func() {
 					Ω(written).ShouldNot(BeEmpty())
 					Ω(written).Should(ContainSubstring(emptyContext))
 					Ω(written).Should(ContainSubstring(emptyContextFactory))
+					Ω(written).Should(ContainSubstring("return goa.CanonicalHash(ctx.Request.Method, ctx.Request.URL.Path, ctx.Params, nil)"))
 				})
 			})
 
@@ -136,6 +137,241 @@ var _ = Describe("ContextsWriter", func() {
 				})
 			})
 
+			Context("with tagged responses", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"state": {Type: design.String}},
+							},
+							TypeName: "Job",
+						},
+						Identifier: "application/vnd.goa.job",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:         "OK",
+							Status:       200,
+							MediaType:    mediaType.Identifier,
+							TagAttribute: "state",
+							TagValue:     "done",
+						},
+						"Accepted": {
+							Name:         "Accepted",
+							Status:       202,
+							MediaType:    mediaType.Identifier,
+							TagAttribute: "state",
+							TagValue:     "pending",
+						},
+						"PartialContent": {
+							Name:      "PartialContent",
+							Status:    206,
+							MediaType: mediaType.Identifier,
+						},
+					}
+				})
+
+				It("writes the Respond method", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(taggedRespond))
+				})
+			})
+
+			Context("with a view param", func() {
+				BeforeEach(func() {
+					params = &design.AttributeDefinition{
+						Type: design.Object{
+							"view": {Type: design.String, DefaultValue: "default"},
+						},
+					}
+				})
+
+				It("writes the RequestedView method", func() {
+					data.ViewParam = "view"
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(requestedView))
+				})
+			})
+
+			Context("with a tenant", func() {
+				BeforeEach(func() {
+					params = &design.AttributeDefinition{
+						Type:       design.Object{"tenantID": {Type: design.String}},
+						Validation: &dslengine.ValidationDefinition{Required: []string{"tenantID"}},
+					}
+				})
+
+				It("stores the tenant identifier in the context", func() {
+					data.Tenant = "TenantID"
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring("	rctx.Context = goa.WithTenant(rctx.Context, rctx.TenantID)\n	return &rctx, err\n"))
+				})
+			})
+
+			Context("with a field mask", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"id": {Type: design.Integer}, "name": {Type: design.String}},
+							},
+							TypeName: "Bottle",
+						},
+						Identifier: "application/vnd.goa.bottle",
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": {
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					elem := &design.AttributeDefinition{
+						Type:       design.String,
+						Validation: &dslengine.ValidationDefinition{Values: []interface{}{"id", "name"}},
+					}
+					params = &design.AttributeDefinition{
+						Type: design.Object{"fields": {Type: &design.Array{ElemType: elem}}},
+					}
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {Name: "OK", Status: 200, MediaType: mediaType.Identifier},
+					}
+				})
+
+				It("validates the fields and prunes the response", func() {
+					data.FieldMask = "fields"
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`goa.InvalidEnumValueError(` + "`fields[*]`" + `, e, []interface{}{"id", "name"})`))
+					Ω(written).Should(ContainSubstring(fieldMaskResp))
+				})
+			})
+
+			Context("with redirect responses", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{"href": {Type: design.String}},
+							},
+							TypeName: "Job",
+						},
+						Identifier:  "application/vnd.goa.job",
+						ContentType: "application/vnd.goa.job",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"Found": {
+							Name:     "Found",
+							Status:   302,
+							Redirect: true,
+						},
+						"SeeOther": {
+							Name:              "SeeOther",
+							Status:            303,
+							MediaType:         mediaType.Identifier,
+							Redirect:          true,
+							LocationAttribute: "href",
+						},
+					}
+				})
+
+				It("sets the Location header", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(redirectResp))
+					Ω(written).Should(ContainSubstring(locationResp))
+				})
+			})
+
+			Context("with trailers", func() {
+				BeforeEach(func() {
+					mediaType := &design.MediaTypeDefinition{
+						UserTypeDefinition: &design.UserTypeDefinition{
+							AttributeDefinition: &design.AttributeDefinition{
+								Type: design.Object{
+									"checksum": {Type: design.String},
+									"count":    {Type: design.Integer},
+								},
+							},
+							TypeName: "Export",
+						},
+						Identifier:  "application/vnd.goa.export",
+						ContentType: "application/vnd.goa.export",
+					}
+					defView := &design.ViewDefinition{
+						AttributeDefinition: mediaType.AttributeDefinition,
+						Name:                "default",
+						Parent:              mediaType,
+					}
+					mediaType.Views = map[string]*design.ViewDefinition{"default": defView}
+					design.Design = new(design.APIDefinition)
+					design.Design.MediaTypes = map[string]*design.MediaTypeDefinition{
+						design.CanonicalIdentifier(mediaType.Identifier): mediaType,
+					}
+					design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: mediaType.Identifier,
+							Trailers:  map[string]string{"X-Checksum": "checksum", "X-Record-Count": "count"},
+						},
+					}
+				})
+
+				It("declares and sets the trailers", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(trailersResp))
+				})
+			})
+
 			Context("with a collection media type", func() {
 				BeforeEach(func() {
 					elemType := &design.MediaTypeDefinition{
@@ -178,6 +414,82 @@ var _ = Describe("ContextsWriter", func() {
 	}
 	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
 				})
+
+				Context("with nil collections encoded as null", func() {
+					BeforeEach(func() {
+						design.Design.NilCollections = design.NilCollectionsNull
+					})
+
+					It("sends nil responses as is", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).ShouldNot(ContainSubstring("if r == nil {"))
+						Ω(written).ShouldNot(ContainSubstring("goa.FillNilCollections"))
+					})
+				})
+
+				Context("with nil collections encoded as empty", func() {
+					BeforeEach(func() {
+						design.Design.NilCollections = design.NilCollectionsEmpty
+					})
+
+					It("fills the nil collections of the response", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(`	if r == nil {
+		r = Collection{}
+	}
+	goa.FillNilCollections(r)
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)`))
+					})
+				})
+			})
+
+			Context("with primitive responses", func() {
+				BeforeEach(func() {
+					design.Design = new(design.APIDefinition)
+					responses = map[string]*design.ResponseDefinition{
+						"OK": {
+							Name:      "OK",
+							Status:    200,
+							MediaType: "application/json",
+							Type:      design.Integer,
+						},
+						"Accepted": {
+							Name:      "Accepted",
+							Status:    202,
+							MediaType: "application/json",
+							Type:      design.Number,
+						},
+						"PartialContent": {
+							Name:      "PartialContent",
+							Status:    206,
+							MediaType: "application/json",
+							Type:      design.DateTime,
+						},
+					}
+				})
+
+				It("writes the JSON encoding directly", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(intResp))
+					Ω(written).Should(ContainSubstring(numberResp))
+					Ω(written).Should(ContainSubstring(`func (ctx *ListBottleContext) PartialContent(r time.Time) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/json")
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 206, r)`))
+				})
 			})
 
 			Context("with an integer param", func() {
@@ -572,6 +884,36 @@ var _ = Describe("ContextsWriter", func() {
 					})
 				})
 
+				Context("with a pipe delimited style", func() {
+					BeforeEach(func() {
+						arrayParam.Metadata = dslengine.MetadataDefinition{"param:style": {design.PipeDelimitedStyle}}
+					})
+
+					It("splits the param values", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(arrayPipeContextFactory))
+					})
+
+					Context("with integer elements", func() {
+						BeforeEach(func() {
+							arrayParam.Type = &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}}
+						})
+
+						It("parses the split values in place", func() {
+							err := writer.Execute(data)
+							Ω(err).ShouldNot(HaveOccurred())
+							b, err := ioutil.ReadFile(filename)
+							Ω(err).ShouldNot(HaveOccurred())
+							written := string(b)
+							Ω(written).Should(ContainSubstring(intArrayPipeContextFactory))
+						})
+					})
+				})
+
 				Context("with required attribute", func() {
 					BeforeEach(func() {
 						validation.Required = []string{"param"}
@@ -868,6 +1210,15 @@ var _ = Describe("ContextsWriter", func() {
 					Ω(written).Should(ContainSubstring(payloadObjContext))
 				})
 
+				It("writes the payload decode function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(payloadObjDecode))
+				})
+
 				var _ = Describe("IterateResponses", func() {
 					var resps []*design.ResponseDefinition
 					var testIt = func(r *design.ResponseDefinition) error {
@@ -932,12 +1283,16 @@ var _ = Describe("ControllersWriter", func() {
 			filePath := "swagger/swagger.json"
 			var origins []*design.CORSDefinition
 			var preflightPaths []string
+			var signed bool
+			var signedFiles []*genapp.SignedFileTemplateData
 
 			var data []*genapp.ControllerTemplateData
 
 			BeforeEach(func() {
 				origins = nil
 				preflightPaths = nil
+				signed = false
+				signedFiles = nil
 			})
 
 			JustBeforeEach(func() {
@@ -945,6 +1300,7 @@ var _ = Describe("ControllersWriter", func() {
 				fileServer := &design.FileServerDefinition{
 					FilePath:    filePath,
 					RequestPath: requestPath,
+					Signed:      signed,
 				}
 				d := &genapp.ControllerTemplateData{
 					API:            &design.APIDefinition{},
@@ -952,6 +1308,7 @@ var _ = Describe("ControllersWriter", func() {
 					PreflightPaths: preflightPaths,
 					Resource:       "Public",
 					FileServers:    []*design.FileServerDefinition{fileServer},
+					SignedFiles:    signedFiles,
 				}
 				data = []*genapp.ControllerTemplateData{d}
 			})
@@ -981,110 +1338,342 @@ var _ = Describe("ControllersWriter", func() {
 					preflightPaths = []string{"/public/star\\*star/*filepath"}
 				})
 
-				It("writes the OPTIONS handler code", func() {
+				It("writes the OPTIONS handler code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(fileServerOptionsHandler))
+				})
+			})
+
+			Context("with signed URLs", func() {
+				BeforeEach(func() {
+					signed = true
+					signedFiles = []*genapp.SignedFileTemplateData{
+						{Func: "SignPublicSwaggerJSONURL", Path: "/swagger.json"},
+						{Func: "SignPublicDownloadsURL", Path: "/downloads/", Wildcard: "filepath"},
+					}
+				})
+
+				It("verifies the signatures and writes the signing functions", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`	h = ctrl.FileHandler("/swagger.json", "swagger/swagger.json")
+	h = middleware.VerifySignedURL(middleware.DefaultURLSigningKey)(h)
+`))
+					Ω(written).Should(ContainSubstring(signedURLFuncs))
+				})
+			})
+		})
+
+		Context("with data", func() {
+			var multipart bool
+			var actions, verbs, paths, contexts, unmarshals, proxies []string
+			var payloads []*design.UserTypeDefinition
+			var encoders, decoders []*genapp.EncoderTemplateData
+			var origins []*design.CORSDefinition
+			var csrf bool
+			var csrfTokenPath string
+			var raw bool
+			var lazyBody bool
+			var cacheTTL string
+			var cacheKeys []string
+			var coalesced bool
+			var logSampler string
+			var audited bool
+			var faultResponse string
+
+			var data []*genapp.ControllerTemplateData
+
+			BeforeEach(func() {
+				multipart = false
+				actions = nil
+				verbs = nil
+				paths = nil
+				contexts = nil
+				unmarshals = nil
+				proxies = nil
+				payloads = nil
+				encoders = nil
+				decoders = nil
+				origins = nil
+				csrf = false
+				csrfTokenPath = ""
+				raw = false
+				lazyBody = false
+				cacheTTL = ""
+				cacheKeys = nil
+				coalesced = false
+				logSampler = ""
+				audited = false
+				faultResponse = ""
+			})
+
+			JustBeforeEach(func() {
+				codegen.TempCount = 0
+				api := &design.APIDefinition{}
+				d := &genapp.ControllerTemplateData{
+					Resource:      "Bottles",
+					Origins:       origins,
+					CSRF:          csrf,
+					CSRFTokenPath: csrfTokenPath,
+				}
+				as := make([]map[string]interface{}, len(actions))
+				for i, a := range actions {
+					var unmarshal, proxy string
+					var payload *design.UserTypeDefinition
+					if i < len(unmarshals) {
+						unmarshal = unmarshals[i]
+					}
+					if i < len(proxies) {
+						proxy = proxies[i]
+					}
+					if i < len(payloads) {
+						payload = payloads[i]
+					}
+					as[i] = map[string]interface{}{
+						"Name":       codegen.Goify(a, true),
+						"DesignName": a,
+						"Routes": []*design.RouteDefinition{
+							{
+								Verb: verbs[i],
+								Path: paths[i],
+							}},
+						"Context":          contexts[i],
+						"Unmarshal":        unmarshal,
+						"Payload":          payload,
+						"PayloadMultipart": multipart,
+						"ProxyURL":         proxy,
+						"Raw":              raw,
+						"LazyBody":         lazyBody,
+						"CacheTTL":         cacheTTL,
+						"CacheKeys":        cacheKeys,
+						"Coalesced":        coalesced,
+						"LogSampler":       logSampler,
+						"Audited":          audited,
+						"FaultResponse":    faultResponse,
+					}
+				}
+				if len(as) > 0 {
+					d.API = api
+					d.Actions = as
+					d.Encoders = encoders
+					d.Decoders = decoders
+					data = []*genapp.ControllerTemplateData{d}
+				} else {
+					data = nil
+				}
+			})
+
+			Context("with missing data", func() {
+				It("returns an empty string", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(BeEmpty())
+				})
+			})
+
+			Context("with a simple controller", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+				})
+
+				It("writes the controller code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).ShouldNot(BeEmpty())
+					Ω(written).Should(ContainSubstring(simpleController))
+					Ω(written).Should(ContainSubstring(simpleMount))
+				})
+			})
+
+			Context("with a proxied action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					proxies = []string{"http://legacy.example.com"}
+				})
+
+				It("writes the proxy handler code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(proxyController))
+					Ω(written).Should(ContainSubstring(proxyMount))
+				})
+			})
+
+			Context("with a raw action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					raw = true
+				})
+
+				It("mounts the handler with the raw mux handler", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.RawMuxHandler("list", h))`))
+					Ω(written).Should(ContainSubstring("\tgoa.Muxer\n\tgoa.RawMuxer\n"))
+				})
+			})
+
+			Context("with a CSRF protected resource", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					csrf = true
+					csrfTokenPath = "/accounts/:accountID/bottles/csrf"
+				})
+
+				It("writes the CSRF middleware and token endpoint code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(csrfMount))
+				})
+			})
+
+			Context("with a cacheable action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					cacheTTL = "5 * time.Minute"
+					cacheKeys = []string{"accountID", "sort"}
+				})
+
+				It("writes the cache middleware code", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(cacheMount))
+				})
+			})
+
+			Context("with a coalesced action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					cacheTTL = "5 * time.Minute"
+					coalesced = true
+				})
+
+				It("wraps the cached handler with the coalescing middleware", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(fileServerOptionsHandler))
+					Ω(written).Should(ContainSubstring(coalesceMount))
 				})
 			})
-		})
-
-		Context("with data", func() {
-			var multipart bool
-			var actions, verbs, paths, contexts, unmarshals []string
-			var payloads []*design.UserTypeDefinition
-			var encoders, decoders []*genapp.EncoderTemplateData
-			var origins []*design.CORSDefinition
 
-			var data []*genapp.ControllerTemplateData
+			Context("with a sampled request log", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					logSampler = "middleware.NewFixedSampler(10)"
+				})
 
-			BeforeEach(func() {
-				multipart = false
-				actions = nil
-				verbs = nil
-				paths = nil
-				contexts = nil
-				unmarshals = nil
-				payloads = nil
-				encoders = nil
-				decoders = nil
-				origins = nil
+				It("wraps the handler with the request log middleware", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(logAccessMount))
+				})
 			})
 
-			JustBeforeEach(func() {
-				codegen.TempCount = 0
-				api := &design.APIDefinition{}
-				d := &genapp.ControllerTemplateData{
-					Resource: "Bottles",
-					Origins:  origins,
-				}
-				as := make([]map[string]interface{}, len(actions))
-				for i, a := range actions {
-					var unmarshal string
-					var payload *design.UserTypeDefinition
-					if i < len(unmarshals) {
-						unmarshal = unmarshals[i]
-					}
-					if i < len(payloads) {
-						payload = payloads[i]
-					}
-					as[i] = map[string]interface{}{
-						"Name":       codegen.Goify(a, true),
-						"DesignName": a,
-						"Routes": []*design.RouteDefinition{
-							{
-								Verb: verbs[i],
-								Path: paths[i],
-							}},
-						"Context":          contexts[i],
-						"Unmarshal":        unmarshal,
-						"Payload":          payload,
-						"PayloadMultipart": multipart,
-					}
-				}
-				if len(as) > 0 {
-					d.API = api
-					d.Actions = as
-					d.Encoders = encoders
-					d.Decoders = decoders
-					data = []*genapp.ControllerTemplateData{d}
-				} else {
-					data = nil
-				}
-			})
+			Context("with an audited action", func() {
+				BeforeEach(func() {
+					actions = []string{"list"}
+					verbs = []string{"GET"}
+					paths = []string{"/accounts/:accountID/bottles"}
+					contexts = []string{"ListBottleContext"}
+					audited = true
+				})
 
-			Context("with missing data", func() {
-				It("returns an empty string", func() {
+				It("wraps the handler with the audit middleware", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).Should(BeEmpty())
+					Ω(written).Should(ContainSubstring("	h = middleware.Audit(middleware.DefaultAuditSink)(h)\n	service.Mux.Handle("))
 				})
 			})
 
-			Context("with a simple controller", func() {
+			Context("with an action defining a fault response", func() {
 				BeforeEach(func() {
 					actions = []string{"list"}
 					verbs = []string{"GET"}
 					paths = []string{"/accounts/:accountID/bottles"}
 					contexts = []string{"ListBottleContext"}
+					faultResponse = "InternalServerError"
 				})
 
-				It("writes the controller code", func() {
+				It("recovers panics with the fault response", func() {
 					err := writer.Execute(data)
 					Ω(err).ShouldNot(HaveOccurred())
 					b, err := ioutil.ReadFile(filename)
 					Ω(err).ShouldNot(HaveOccurred())
 					written := string(b)
-					Ω(written).ShouldNot(BeEmpty())
-					Ω(written).Should(ContainSubstring(simpleController))
-					Ω(written).Should(ContainSubstring(simpleMount))
+					Ω(written).Should(ContainSubstring(faultMount))
+				})
+			})
+
+			Context("with multiple actions", func() {
+				BeforeEach(func() {
+					actions = []string{"list", "show"}
+					verbs = []string{"GET", "GET"}
+					paths = []string{"/accounts/:accountID/bottles", "/accounts/:accountID/bottles/:id"}
+					contexts = []string{"ListBottleContext", "ShowBottleContext"}
+				})
+
+				It("writes the error encoder override function", func() {
+					err := writer.Execute(data)
+					Ω(err).ShouldNot(HaveOccurred())
+					b, err := ioutil.ReadFile(filename)
+					Ω(err).ShouldNot(HaveOccurred())
+					written := string(b)
+					Ω(written).Should(ContainSubstring(errorEncoderCode))
 				})
 			})
 
@@ -1117,6 +1706,23 @@ var _ = Describe("ControllersWriter", func() {
 					written := string(b)
 					Ω(written).Should(ContainSubstring(payloadNoValidationsObjUnmarshal))
 				})
+
+				Context("with lazy body decoding", func() {
+					BeforeEach(func() {
+						lazyBody = true
+					})
+
+					It("defers the payload decoding to the handler", func() {
+						err := writer.Execute(data)
+						Ω(err).ShouldNot(HaveOccurred())
+						b, err := ioutil.ReadFile(filename)
+						Ω(err).ShouldNot(HaveOccurred())
+						written := string(b)
+						Ω(written).Should(ContainSubstring(lazyBodyController))
+						Ω(written).Should(ContainSubstring(`service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.LazyMuxHandler("list", h, unmarshalListBottlePayload))`))
+						Ω(written).Should(ContainSubstring("\tgoa.Muxer\n\tgoa.LazyMuxer\n"))
+					})
+				})
 			})
 			Context("with actions that take a payload with a required validation", func() {
 				BeforeEach(func() {
@@ -1568,6 +2174,99 @@ var _ = Describe("UserTypesWriter", func() {
 })
 
 const (
+	redirectResp = `// Found sends a HTTP response with status code 302.
+func (ctx *ListBottleContext) Found(location string) error {
+	ctx.ResponseData.Header().Set("Location", location)
+	ctx.ResponseData.WriteHeader(302)
+	return nil
+}
+`
+
+	locationResp = `// SeeOther sends a HTTP response with status code 303.
+func (ctx *ListBottleContext) SeeOther(r *Job) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.job")
+	}
+	if r.Href != nil {
+		ctx.ResponseData.Header().Set("Location", *r.Href)
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 303, r)
+}
+`
+
+	trailersResp = `// OK sends a HTTP response with status code 200.
+func (ctx *ListBottleContext) OK(r *Export) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/vnd.goa.export")
+	}
+	ctx.ResponseData.Header().Set("Trailer", "X-Checksum, X-Record-Count")
+	defer func() {
+		if r.Checksum != nil {
+			ctx.ResponseData.Header().Set("X-Checksum", *r.Checksum)
+		}
+		if r.Count != nil {
+			ctx.ResponseData.Header().Set("X-Record-Count", strconv.Itoa(*r.Count))
+		}
+	}()
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+`
+
+	intResp = `func (ctx *ListBottleContext) OK(r int) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/json")
+	}
+	if ctx.ResponseData.Service.Encoder.IsJSON(ctx.RequestData.Header.Get("Accept")) {
+		return ctx.ResponseData.Service.SendJSON(ctx.Context, 200, goa.AppendJSONInt(nil, int64(r)))
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+}
+`
+
+	numberResp = `func (ctx *ListBottleContext) Accepted(r float64) error {
+	if ctx.ResponseData.Header().Get("Content-Type") == "" {
+		ctx.ResponseData.Header().Set("Content-Type", "application/json")
+	}
+	if !math.IsNaN(r) && !math.IsInf(r, 0) && ctx.ResponseData.Service.Encoder.IsJSON(ctx.RequestData.Header.Get("Accept")) {
+		return ctx.ResponseData.Service.SendJSON(ctx.Context, 202, goa.AppendJSONFloat(nil, r))
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 202, r)
+}
+`
+
+	taggedRespond = `// Respond sends the HTTP response selected by the value of the state attribute of r.
+func (ctx *ListBottleContext) Respond(r *Job) error {
+	if r.State != nil {
+		switch *r.State {
+		case "done":
+			return ctx.OK(r)
+		case "pending":
+			return ctx.Accepted(r)
+		}
+	}
+	return ctx.PartialContent(r)
+}
+`
+
+	fieldMaskResp = `	if len(ctx.Fields) > 0 && ctx.ResponseData.Service.Encoder.IsJSON(ctx.RequestData.Header.Get("Accept")) {
+		b, err := goa.PruneJSON(r, ctx.Fields)
+		if err != nil {
+			return err
+		}
+		return ctx.ResponseData.Service.SendJSON(ctx.Context, 200, b)
+	}
+	return ctx.ResponseData.Service.Send(ctx.Context, 200, r)
+`
+
+	requestedView = `// RequestedView returns the name of the response view requested with the view param.
+func (ctx *ListBottleContext) RequestedView() string {
+	if ctx.View == "" {
+		return "default"
+	}
+	return ctx.View
+}
+`
+
 	emptyContext = `
 type ListBottleContext struct {
 	context.Context
@@ -2053,6 +2752,31 @@ func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Ser
 }
 `
 
+	arrayPipeContextFactory = `
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		params := goa.SplitParam(paramParam, "|")
+		rctx.Param = params
+	}
+`
+
+	intArrayPipeContextFactory = `
+	paramParam := req.Params["param"]
+	if len(paramParam) > 0 {
+		it := goa.NewParamIterator(paramParam, "|")
+		params := make([]int, it.Len())
+		for i := 0; it.Next(); i++ {
+			rawParam := it.Value()
+			if param, err2 := strconv.Atoi(rawParam); err2 == nil {
+				params[i] = param
+			} else {
+				err = goa.MergeErrors(err, goa.InvalidParamTypeError("param", rawParam, "integer"))
+			}
+		}
+		rctx.Param = params
+	}
+`
+
 	arrayDefaultContextFactory = `
 func NewListBottleContext(ctx context.Context, r *http.Request, service *goa.Service) (*ListBottleContext, error) {
 	var err error
@@ -2299,6 +3023,19 @@ type ListBottleContext struct {
 	*goa.RequestData
 	Payload *ListBottlePayload
 }
+`
+
+	payloadObjDecode = `
+func DecodeListBottlePayload(decoder *goa.HTTPDecoder, body io.Reader, contentType string) (*ListBottlePayload, error) {
+	var payload listBottlePayload
+	if err := decoder.Decode(&payload, body, contentType); err != nil {
+		return nil, err
+	}
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+	return payload.Publicize(), nil
+}
 `
 
 	payloadObjUnmarshal = `
@@ -2358,6 +3095,19 @@ type PublicController interface {
 	goa.Muxer
 	goa.FileServer
 }
+`
+
+	signedURLFuncs = `// SignPublicSwaggerJSONURL returns the URL of /swagger.json signed
+// with key and valid until expires.
+func SignPublicSwaggerJSONURL(key []byte, expires time.Time) string {
+	return middleware.SignURL(key, "/swagger.json", expires)
+}
+
+// SignPublicDownloadsURL returns the URL of the file filepath served under /downloads/ signed
+// with key and valid until expires.
+func SignPublicDownloadsURL(key []byte, filepath string, expires time.Time) string {
+	return middleware.SignURL(key, "/downloads/"+strings.TrimPrefix(filepath, "/"), expires)
+}
 `
 
 	fileServerOptionsHandler = `service.Mux.Handle("OPTIONS", "/public/star\\*star/*filepath", ctrl.MuxHandler("preflight", handlePublicOrigin(cors.HandlePreflight()), nil))`
@@ -2460,7 +3210,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -2470,6 +3220,8 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		return ctrl.List(rctx)
 	}
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
@@ -2481,7 +3233,7 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -2491,13 +3243,113 @@ func MountBottlesController(service *goa.Service, ctrl BottlesController) {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
+		return ctrl.List(rctx)
+	}
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
+}
+`
+
+	csrfMount = `		return ctrl.List(rctx)
+	}
+	h = middleware.CSRF()(h)
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
+
+	h = middleware.CSRFTokenHandler()
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles/csrf", ctrl.MuxHandler("csrf", h, nil))
+	service.LogInfo("mount", "ctrl", "Bottles", "csrf", "token", "route", "GET /accounts/:accountID/bottles/csrf")
+}
+`
+
+	cacheMount = `		return ctrl.List(rctx)
+	}
+	h = middleware.Cache(middleware.DefaultCacheStore, 5 * time.Minute, "accountID", "sort")(h)
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+`
+
+	coalesceMount = `	h = middleware.Cache(middleware.DefaultCacheStore, 5 * time.Minute)(h)
+	h = middleware.Coalesce(middleware.DefaultCoalesceGroup)(h)
+	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
+`
+
+	logAccessMount = `	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", middleware.LogAccess("GET /accounts/:accountID/bottles", middleware.NewFixedSampler(10))(h), nil))
+`
+
+	faultMount = `		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, rctx.InternalServerError)
 		return ctrl.List(rctx)
+`
+
+	errorEncoderCode = `// SetBottlesErrorEncoder overrides the encoding of the errors returned by the given
+// Bottles actions, by all the Bottles actions if none is given. It panics if an
+// action does not belong to the Bottles resource.
+func SetBottlesErrorEncoder(ctrl *goa.Controller, enc goa.ErrorEncoder, actions ...string) {
+	if len(actions) == 0 {
+		ctrl.ErrorEncoder = enc
+		return
+	}
+	for _, action := range actions {
+		switch action {
+		case "list", "show":
+			ctrl.SetErrorEncoder(action, enc)
+		default:
+			panic(fmt.Sprintf("unknown Bottles action %q", action))
+		}
+	}
+}
+`
+
+	proxyController = `// BottlesController is the controller interface for the Bottles actions.
+type BottlesController interface {
+	goa.Muxer
+}
+`
+
+	proxyMount = `func MountBottlesController(service *goa.Service, ctrl BottlesController) {
+	initService(service)
+	var h goa.Handler
+
+	proxyList := service.ProxyHandler("http://legacy.example.com")
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		// Check if there was an error loading the request
+		if err := goa.ContextError(ctx); err != nil {
+			return err
+		}
+		// Validate the request
+		if _, err := NewListBottleContext(ctx, req, service); err != nil {
+			return err
+		}
+		return proxyList(ctx, rw, req)
 	}
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 }
 `
 
+	lazyBodyController = `
+		// Build the context
+		rctx, err := NewListBottleContext(ctx, req, service)
+		if err != nil {
+			return err
+		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
+		// Load the request body, deferred until the middleware accepts the request
+		if err := goa.LoadRequestBody(ctx); err != nil {
+			return err
+		}
+		// Build the payload
+		if rawPayload := goa.ContextRequest(ctx).Payload; rawPayload != nil {
+			rctx.Payload = rawPayload.(*ListBottlePayload)
+		} else {
+			return goa.MissingPayloadError()
+		}
+		return ctrl.List(rctx)
+`
+
 	multiController = `// BottlesController is the controller interface for the Bottles actions.
 type BottlesController interface {
 	goa.Muxer
@@ -2510,7 +3362,7 @@ type BottlesController interface {
 	initService(service)
 	var h goa.Handler
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -2520,12 +3372,14 @@ type BottlesController interface {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		return ctrl.List(rctx)
 	}
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles", ctrl.MuxHandler("list", h, nil))
 	service.LogInfo("mount", "ctrl", "Bottles", "action", "List", "route", "GET /accounts/:accountID/bottles")
 
-	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+	h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) (err error) {
 		// Check if there was an error loading the request
 		if err := goa.ContextError(ctx); err != nil {
 			return err
@@ -2535,6 +3389,8 @@ type BottlesController interface {
 		if err != nil {
 			return err
 		}
+		// Convert panics into internal errors
+		defer service.RecoverFault(ctx, &err, nil)
 		return ctrl.Show(rctx)
 	}
 	service.Mux.Handle("GET", "/accounts/:accountID/bottles/:id", ctrl.MuxHandler("show", h, nil))
@@ -2568,6 +3424,17 @@ func (ut *simplePayload) Publicize() *SimplePayload {
 	return &pub
 }
 
+// DecodeSimplePayload decodes SimplePayload from its wire representation read from body the same
+// way request bodies are: the default values are set and the result is validated. contentType
+// selects the decoder, e.g. service.Decoder or a decoder built with goa.NewHTTPDecoder.
+func DecodeSimplePayload(decoder *goa.HTTPDecoder, body io.Reader, contentType string) (*SimplePayload, error) {
+	var ut simplePayload
+	if err := decoder.Decode(&ut, body, contentType); err != nil {
+		return nil, err
+	}
+	return ut.Publicize(), nil
+}
+
 // SimplePayload user type.
 type SimplePayload struct {
 	Name *string ` + "`" + `form:"name,omitempty" json:"name,omitempty" xml:"name,omitempty"` + "`" + `
@@ -2602,6 +3469,17 @@ func (ut *complexPayload) Publicize() *ComplexPayload {
 	return &pub
 }
 
+// DecodeComplexPayload decodes ComplexPayload from its wire representation read from body the same
+// way request bodies are: the default values are set and the result is validated. contentType
+// selects the decoder, e.g. service.Decoder or a decoder built with goa.NewHTTPDecoder.
+func DecodeComplexPayload(decoder *goa.HTTPDecoder, body io.Reader, contentType string) (*ComplexPayload, error) {
+	var ut complexPayload
+	if err := decoder.Decode(&ut, body, contentType); err != nil {
+		return nil, err
+	}
+	return ut.Publicize(), nil
+}
+
 // ComplexPayload user type.
 type ComplexPayload struct {
 	Misc map[int]*MiscPayload ` + "`" + `form:"misc,omitempty" json:"misc,omitempty" xml:"misc,omitempty"` + "`" + `
@@ -0,0 +1,10 @@
+/*
+Package genchangelog provides a generator that writes the human-readable changelog of the API
+surface: the fragment compares the current design against the design model snapshot produced by
+the "model" command for the previous release and lists the breaking changes, the new endpoints, the
+new error responses and the changed types in Markdown.
+
+The fragment is written to changelog/RELEASE.md where RELEASE is the name given with --release,
+"unreleased" by default. Run the "model" command once the release is cut to update the snapshot.
+*/
+package genchangelog
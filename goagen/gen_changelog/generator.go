@@ -0,0 +1,185 @@
+package genchangelog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_model"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of an API changelog generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the API changelog generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Snapshot string                // Path to the design model of the previous release
+	Release  string                // Name of the release described by the changelog
+	genfiles []string              // Generated files
+}
+
+// Section groups the changes listed under the same heading of the changelog.
+type Section struct {
+	// Title is the heading of the section.
+	Title string
+	// Changes lists the changes of the section.
+	Changes []*genmodel.Change
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var outDir, ver, snapshot, release string
+	set := flag.NewFlagSet("changelog", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.StringVar(&snapshot, "snapshot", "", "")
+	set.StringVar(&release, "release", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Snapshot: snapshot, Release: release, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate writes the changelog fragment.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	snapshot := g.Snapshot
+	if snapshot == "" {
+		snapshot = filepath.Join(g.OutDir, "model", "design.json")
+	}
+	old, err := genmodel.Load(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load design snapshot, use the model command to create it: %s", err)
+	}
+	release := g.Release
+	if release == "" {
+		release = "Unreleased"
+	}
+	content, err := Changelog(release, genmodel.Diff(old, genmodel.NewModel(g.API)))
+	if err != nil {
+		return
+	}
+
+	outDir := filepath.Join(g.OutDir, "changelog")
+	if err = os.MkdirAll(outDir, 0755); err != nil {
+		return
+	}
+	changelogFile := filepath.Join(outDir, fileName(release)+".md")
+	if err = ioutil.WriteFile(changelogFile, content, 0644); err != nil {
+		return
+	}
+	g.genfiles = append(g.genfiles, changelogFile)
+
+	return g.genfiles, nil
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+// Changelog renders the Markdown changelog fragment of the given release listing the given
+// changes. The breaking changes are listed first, the other changes are grouped by kind.
+func Changelog(release string, changes []*genmodel.Change) ([]byte, error) {
+	sections := []*Section{
+		{Title: "Breaking changes"},
+		{Title: "New endpoints"},
+		{Title: "New errors"},
+		{Title: "New responses"},
+		{Title: "Changed types"},
+	}
+	for _, c := range changes {
+		var s *Section
+		switch {
+		case c.Breaking:
+			s = sections[0]
+		case c.Kind == genmodel.AddedEndpoint:
+			s = sections[1]
+		case c.Kind == genmodel.AddedError:
+			s = sections[2]
+		case c.Kind == genmodel.AddedResponse:
+			s = sections[3]
+		default:
+			s = sections[4]
+		}
+		s.Changes = append(s.Changes, c)
+	}
+	var nonEmpty []*Section
+	for _, s := range sections {
+		if len(s.Changes) > 0 {
+			nonEmpty = append(nonEmpty, s)
+		}
+	}
+	var b bytes.Buffer
+	err := changelogTmpl.Execute(&b, map[string]interface{}{"Release": release, "Sections": nonEmpty})
+	return b.Bytes(), err
+}
+
+// unsafeChars matches the characters that are replaced in the changelog file names.
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName returns the name of the changelog file of the given release without extension.
+func fileName(release string) string {
+	return strings.Trim(unsafeChars.ReplaceAllString(strings.ToLower(release), "-"), "-.")
+}
+
+var changelogTmpl = template.Must(template.New("changelog").Funcs(template.FuncMap{
+	"location": location,
+}).Parse(changelogT))
+
+// location returns the Markdown code spans of the endpoint and attribute affected by the change.
+func location(c *genmodel.Change) string {
+	loc := "`" + c.Endpoint + "`"
+	if c.Path != "" {
+		loc += " `" + c.Path + "`"
+	}
+	return loc
+}
+
+const changelogT = `## {{ .Release }}
+{{ range .Sections }}
+### {{ .Title }}
+
+{{ range .Changes }}- {{ location . }}: {{ .Message }}
+{{ end }}{{ else }}
+No change to the API surface.
+{{ end }}`
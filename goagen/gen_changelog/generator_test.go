@@ -0,0 +1,114 @@
+package genchangelog_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_changelog"
+	"github.com/goadesign/goa/goagen/gen_model"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("changelogtest")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		apidsl.API("test api", nil)
+		apidsl.Resource("bottle", func() {
+			apidsl.Action("show", func() {
+				apidsl.Routing(apidsl.GET("/bottles/:id"))
+				apidsl.Response("OK")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		_, err = genmodel.NewGenerator(genmodel.API(design.Design), genmodel.OutDir(testPkg.Abs())).Generate()
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String(), "--release=v1.2.0"}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genchangelog.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with an unchanged design", func() {
+		It("writes an empty changelog", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(1))
+			Ω(files[0]).Should(Equal(filepath.Join(testPkg.Abs(), "changelog", "v1.2.0.md")))
+			content, err := ioutil.ReadFile(files[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("## v1.2.0\n\nNo change to the API surface.\n"))
+		})
+	})
+
+	Context("with new endpoints and errors", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response("OK")
+					apidsl.Response("NotFound")
+				})
+				apidsl.Action("list", func() {
+					apidsl.Routing(apidsl.GET("/bottles"))
+					apidsl.Response("OK")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("lists the changes by section", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(files[0])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("### New endpoints\n\n- `GET /bottles`: "))
+			Ω(string(content)).Should(ContainSubstring("### New errors\n\n- `GET /bottles/:id`: response with status 404 was added\n"))
+			Ω(string(content)).ShouldNot(ContainSubstring("### Breaking changes"))
+		})
+	})
+
+	Context("with a missing snapshot", func() {
+		BeforeEach(func() {
+			os.Args = append(os.Args, "--snapshot="+filepath.Join(testPkg.Abs(), "missing.json"))
+		})
+
+		It("fails", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(files).Should(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("Changelog", func() {
+	It("lists the breaking changes first", func() {
+		changes := []*genmodel.Change{
+			{Kind: genmodel.AddedField, Endpoint: "POST /bottles", Path: "response 201.color", Message: "field was added"},
+			{Kind: genmodel.RemovedEndpoint, Endpoint: "DELETE /bottles/:id", Message: "endpoint was removed", Breaking: true},
+		}
+		b, err := genchangelog.Changelog("Unreleased", changes)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal("## Unreleased\n\n### Breaking changes\n\n- `DELETE /bottles/:id`: endpoint was removed\n\n### Changed types\n\n- `POST /bottles` `response 201.color`: field was added\n"))
+	})
+})
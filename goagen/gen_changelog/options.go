@@ -0,0 +1,34 @@
+package genchangelog
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Snapshot Path to the design model of the previous release
+func Snapshot(snapshot string) Option {
+	return func(g *Generator) {
+		g.Snapshot = snapshot
+	}
+}
+
+//Release Name of the release described by the changelog
+func Release(release string) Option {
+	return func(g *Generator) {
+		g.Release = release
+	}
+}
@@ -0,0 +1,106 @@
+package genclient
+
+import (
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateBatchClient generates the path function and the client method of the batch endpoint of
+// the given action if it has one.
+func (g *Generator) generateBatchClient(action *design.ActionDefinition, file *codegen.SourceFile, funcs template.FuncMap) error {
+	r := action.BatchRoute
+	if r == nil {
+		return nil
+	}
+	routeParams := r.Params()
+	var pd []*paramData
+	for _, p := range routeParams {
+		requiredParams, _ := initParams(&design.AttributeDefinition{
+			Type: &design.Object{
+				p: action.Params.Type.ToObject()[p],
+			},
+			Validation: &dslengine.ValidationDefinition{
+				Required: routeParams,
+			},
+		})
+		pd = append(pd, requiredParams...)
+	}
+	var signer, scheme string
+	if action.Security != nil {
+		scheme = action.Security.Scheme.SchemeName
+		signer = codegen.Goify(scheme, true)
+	}
+	data := struct {
+		Route           *design.RouteDefinition
+		Name            string
+		ResourceName    string
+		Params          []*paramData
+		PayloadRef      string
+		CanonicalScheme string
+		Signer          string
+		SecurityScheme  string
+		CSRF            bool
+	}{
+		Route:           r,
+		Name:            action.Name,
+		ResourceName:    action.Parent.Name,
+		Params:          pd,
+		PayloadRef:      codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false),
+		CanonicalScheme: action.CanonicalScheme(),
+		Signer:          signer,
+		SecurityScheme:  scheme,
+		CSRF:            action.Parent.CSRF,
+	}
+	batchTmpl := template.Must(template.New("batch").Funcs(funcs).Parse(batchTmpl))
+	return batchTmpl.Execute(file, data)
+}
+
+const batchTmpl = `{{ $funcName := goify (printf "%s%sBatch" (title .Name) (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }}Path computes a request path to the batch endpoint of the {{ .Name }} action of {{ .ResourceName }}.
+func {{ $funcName }}Path({{ pathParams .Route }}) string {
+	{{ range $i, $param := .Params }}{{/*
+*/}}{{ toString $param.VarName (printf "param%d" $i) $param.Attribute }}
+	{{ end }}
+	return fmt.Sprintf({{ printf "%q" (pathTemplate .Route) }}{{ range $i, $param := .Params }}, {{ printf "param%d" $i }}{{ end }})
+}
+
+// {{ $funcName }} makes a request to the batch endpoint of the {{ .Name }} action of the {{ .ResourceName }} resource.
+// The endpoint invokes the action once per payload, use goaclient.DecodeBatch to decode the
+// results from the response.
+func (c *Client) {{ $funcName }}(ctx context.Context, path string, payloads []{{ .PayloadRef }}) (*http.Response, error) {
+	ctx = goaclient.ContextWithEndpoint(ctx, {{ printf "%q" .ResourceName }}, {{ printf "%q" .Name }}, payloads)
+	body, err := json.Marshal(payloads)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode body: %s", err)
+	}
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "{{ .CanonicalScheme }}"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+{{ if .Signer }}	signer := goaclient.ContextSigner(ctx, {{ printf "%q" .SecurityScheme }})
+	if signer == nil {
+		signer = c.{{ .Signer }}Signer
+	}
+	if signer != nil {
+		if err := signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}{{ if .CSRF }}	if c.CSRFSigner != nil {
+		if err := c.CSRFSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}	return c.Client.Do(ctx, req)
+}
+
+`
@@ -59,9 +59,19 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 	hasBasicAuthSigners := false
 	hasAPIKeySigners := false
 	hasTokenSigners := false
+	hasHMACSigners := false
+	hasCookieSigners := false
 	for _, s := range g.API.SecuritySchemes {
 		if signerType(s) != "" {
 			hasSigners = true
+			if s.Kind == design.HMACSecurityKind {
+				hasHMACSigners = true
+				continue
+			}
+			if s.Kind == design.CookieSecurityKind {
+				hasCookieSigners = true
+				continue
+			}
 			switch s.Type {
 			case "basic":
 				hasBasicAuthSigners = true
@@ -81,6 +91,9 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners bool
 		HasAPIKeySigners    bool
 		HasTokenSigners     bool
+		HasHMACSigners      bool
+		HasCookieSigners    bool
+		HasCSRF             bool
 	}{
 		API:                 g.API,
 		Version:             version,
@@ -89,11 +102,38 @@ func (g *Generator) generateMain(mainFile string, clientPkg, cliPkg string, func
 		HasBasicAuthSigners: hasBasicAuthSigners,
 		HasAPIKeySigners:    hasAPIKeySigners,
 		HasTokenSigners:     hasTokenSigners,
+		HasHMACSigners:      hasHMACSigners,
+		HasCookieSigners:    hasCookieSigners,
+		HasCSRF:             g.API.HasCSRF(),
 	}
 	err = file.ExecuteTemplate("main", mainTmpl, funcs, data)
 	return
 }
 
+// actionGroup returns the name of the CLI group of the action, if any.
+func actionGroup(a *design.ActionDefinition) string {
+	if g := a.Metadata["cli:group"]; len(g) > 0 {
+		return g[0]
+	}
+	if g := a.Parent.Metadata["cli:group"]; len(g) > 0 {
+		return g[0]
+	}
+	return ""
+}
+
+// commonGroup returns the CLI group shared by all the given actions, if any.
+func commonGroup(actions []*design.ActionDefinition) string {
+	var group string
+	for i, a := range actions {
+		g := actionGroup(a)
+		if i > 0 && g != group {
+			return ""
+		}
+		group = g
+	}
+	return group
+}
+
 func (g *Generator) generateCommands(commandsFile string, clientPkg string, funcs template.FuncMap) (err error) {
 	var file *codegen.SourceFile
 	file, err = codegen.SourceFileFor(commandsFile)
@@ -118,6 +158,9 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	funcs["formatExample"] = formatExample
 	funcs["shouldAddExample"] = shouldAddExample
 	funcs["kebabCase"] = codegen.KebabCase
+	funcs["promptFields"] = promptFields
+	funcs["tableColumns"] = tableColumns
+	funcs["flattenedField"] = flattenedField
 
 	commandTypesTmpl := template.Must(template.New("commandTypes").Funcs(funcs).Parse(commandTypesTmpl))
 	commandsTmpl := template.Must(template.New("commands").Funcs(funcs).Parse(commandsTmpl))
@@ -126,18 +169,22 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	registerTmpl := template.Must(template.New("register").Funcs(funcs).Parse(registerTmpl))
 
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bufio"),
 		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("log"),
 		codegen.SimpleImport("net/url"),
 		codegen.SimpleImport("os"),
 		codegen.SimpleImport("path"),
 		codegen.SimpleImport("path/filepath"),
+		codegen.SimpleImport("sort"),
 		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("strconv"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport("github.com/spf13/cobra"),
+		codegen.SimpleImport("github.com/spf13/pflag"),
 		codegen.SimpleImport(clientPkg),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
@@ -146,6 +193,14 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	if len(g.API.Resources) > 0 {
 		imports = append(imports, codegen.NewImport("goaclient", "github.com/goadesign/goa/client"))
 	}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.Payload != nil {
+				imports = codegen.AttributeImports(&design.AttributeDefinition{Type: action.Payload}, imports, nil)
+			}
+			return nil
+		})
+	})
 	title := fmt.Sprintf("%s: CLI Commands", g.API.Context())
 	if err = file.WriteHeader(title, "cli", imports); err != nil {
 		return err
@@ -168,12 +223,15 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	file.Write([]byte(")\n\n"))
 
 	actions := make(map[string][]*design.ActionDefinition)
-	hasDownloads := false
+	hasDownloads, hasPrompts := false, false
 	g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		if len(res.FileServers) > 0 {
 			hasDownloads = true
 		}
 		return res.IterateActions(func(action *design.ActionDefinition) error {
+			if action.Payload != nil && action.Payload.Type.IsObject() && !action.WebSocket() {
+				hasPrompts = true
+			}
 			name := codegen.Goify(action.Name, false)
 			if as, ok := actions[name]; ok {
 				actions[name] = append(as, action)
@@ -183,18 +241,45 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 			return nil
 		})
 	})
+	hasGroups := false
+	for _, as := range actions {
+		for _, a := range as {
+			if actionGroup(a) != "" {
+				hasGroups = true
+			}
+		}
+	}
+	funcs["actionGroup"] = actionGroup
+	funcs["commonGroup"] = commonGroup
 	data := struct {
 		Actions      map[string][]*design.ActionDefinition
 		Package      string
+		Tool         string
 		HasDownloads bool
+		HasGroups    bool
 	}{
 		Actions:      actions,
 		Package:      g.Target,
+		Tool:         g.Tool,
 		HasDownloads: hasDownloads,
+		HasGroups:    hasGroups,
 	}
 	if err = file.ExecuteTemplate("registerCmds", registerCmdsT, funcs, data); err != nil {
 		return err
 	}
+	if hasGroups {
+		if _, err = file.Write([]byte(groupedUsageT)); err != nil {
+			return err
+		}
+	}
+	if _, err = file.Write([]byte(completionT)); err != nil {
+		return err
+	}
+	if hasPrompts {
+		if _, err = file.Write([]byte(promptT)); err != nil {
+			return err
+		}
+	}
 
 	var fsdata []map[string]interface{}
 	g.API.IterateResources(func(res *design.ResourceDefinition) error {
@@ -240,6 +325,7 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 				"Resource":        action.Parent,
 				"Package":         g.Target,
 				"HasMultiContent": len(g.API.Consumes) > 1,
+				"Deprecation":     deprecationWarning(action.Deprecation),
 			}
 			var err error
 			if action.WebSocket() {
@@ -258,6 +344,19 @@ func (g *Generator) generateCommands(commandsFile string, clientPkg string, func
 	return
 }
 
+// deprecationWarning returns the warning printed by the command of a deprecated action, the empty
+// string if the action is not deprecated.
+func deprecationWarning(d *design.DeprecationDefinition) string {
+	if d == nil {
+		return ""
+	}
+	msg := "warning: this command is deprecated since " + d.Since.Format("2006-01-02")
+	if !d.Sunset.IsZero() {
+		msg += " and will stop working on " + d.Sunset.Format("2006-01-02")
+	}
+	return msg
+}
+
 // defaultRouteParams returns the parameters needed to build the first route of the given action.
 func defaultRouteParams(a *design.ActionDefinition) *design.AttributeDefinition {
 	r := a.Routes[0]
@@ -281,8 +380,9 @@ func defaultRouteTemplate(a *design.ActionDefinition) string {
 // ordered by the required first rules.
 func joinRouteParams(action *design.ActionDefinition, att *design.AttributeDefinition) string {
 	var (
-		params = action.Routes[0].Params()
-		elems  = make([]string, len(params))
+		params   = action.Routes[0].Params()
+		catchAll = action.Routes[0].CatchAll()
+		elems    = make([]string, len(params))
 	)
 	for i, p := range params {
 		patt, ok := att.Type.ToObject()[p]
@@ -292,6 +392,10 @@ func joinRouteParams(action *design.ActionDefinition, att *design.AttributeDefin
 		pf := "cmd.%s"
 		if patt.Type.Kind() == design.StringKind {
 			pf = "url.QueryEscape(cmd.%s)"
+			if p == catchAll {
+				// Keep the slashes of the catch-all param which may span multiple segments.
+				pf = `strings.Replace(url.QueryEscape(cmd.%s), "%%2F", "/", -1)`
+			}
 		}
 		field := fmt.Sprintf(pf, codegen.Goify(p, true))
 		elems[i] = field
@@ -523,6 +627,12 @@ func routes(action *design.ActionDefinition) string {
 // signerSignature returns the callee signature for the signer factory function for the given security
 // scheme.
 func signerSignature(sec *design.SecuritySchemeDefinition) string {
+	if sec.Kind == design.HMACSecurityKind {
+		return "keyID, secret string"
+	}
+	if sec.Kind == design.CookieSecurityKind {
+		return "session string"
+	}
 	switch sec.Type {
 	case "basic":
 		return "user, pass string"
@@ -540,6 +650,12 @@ func signerSignature(sec *design.SecuritySchemeDefinition) string {
 // signerArgs returns the caller signature for the signer factory function for the given security
 // scheme.
 func signerArgs(sec *design.SecuritySchemeDefinition) string {
+	if sec.Kind == design.HMACSecurityKind {
+		return "keyID, secret"
+	}
+	if sec.Kind == design.CookieSecurityKind {
+		return "session"
+	}
 	switch sec.Type {
 	case "basic":
 		return "user, pass"
@@ -596,11 +712,95 @@ func defaultVal(att *design.AttributeDefinition) string {
 	return fmt.Sprintf("%q", fmt.Sprintf("%v", att.DefaultValue))
 }
 
+// promptField describes a payload field prompted by the interactive mode of the generated CLI.
+type promptField struct {
+	// Name is the name of the payload field.
+	Name string
+	// Description is the field description.
+	Description string
+	// JSON is true if the value entered by the user must be decoded as JSON.
+	JSON bool
+}
+
+// promptFields returns the required fields of the given payload in order of definition.
+func promptFields(ut *design.UserTypeDefinition) []*promptField {
+	if ut.Validation == nil {
+		return nil
+	}
+	o := ut.Type.ToObject()
+	var fields []*promptField
+	for _, n := range ut.Validation.Required {
+		att, ok := o[n]
+		if !ok {
+			continue
+		}
+		var isJSON bool
+		switch att.Type.Kind() {
+		case design.StringKind, design.DateTimeKind, design.UUIDKind:
+		default:
+			isJSON = true
+		}
+		fields = append(fields, &promptField{Name: n, Description: att.Description, JSON: isJSON})
+	}
+	return fields
+}
+
+// tableColumns returns the names of the attributes of the view of the media type of the first
+// successful response of the given action. These are used as the columns of the table
+// output of the CLI, collections use the attributes of their elements.
+func tableColumns(a *design.ActionDefinition) []string {
+	var names []string
+	for _, r := range a.Responses {
+		if r.Status < 200 || r.Status > 299 || r.MediaType == "" {
+			continue
+		}
+		names = append(names, r.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := a.Responses[names[i]], a.Responses[names[j]]
+		if ri.Status != rj.Status {
+			return ri.Status < rj.Status
+		}
+		return names[i] < names[j]
+	})
+	resp := a.Responses[names[0]]
+	mt := design.Design.MediaTypeWithIdentifier(resp.MediaType)
+	if mt == nil {
+		return nil
+	}
+	if mt.IsArray() {
+		elem, ok := mt.ToArray().ElemType.Type.(*design.MediaTypeDefinition)
+		if !ok {
+			return nil
+		}
+		mt = elem
+	}
+	view := resp.ViewName
+	if view == "" {
+		view = design.DefaultView
+	}
+	var obj design.Object
+	if v, ok := mt.Views[view]; ok && v.Type != nil {
+		obj = v.Type.ToObject()
+	} else if mt.Type != nil {
+		obj = mt.Type.ToObject()
+	}
+	cols := make([]string, 0, len(obj))
+	for n := range obj {
+		cols = append(cols, n)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
 func shouldAddExample(ut *design.UserTypeDefinition) bool {
 	if ut == nil {
 		return false
 	}
-	return ut.Example != nil
+	return ut.Example != nil || len(ut.Examples) > 0
 }
 
 func formatExample(example interface{}) string {
@@ -628,7 +828,9 @@ func main() {
 	app.PersistentFlags().StringVarP(&c.Host, "host", "H", "{{ .API.Host }}", "API hostname")
 	app.PersistentFlags().DurationVarP(&httpClient.Timeout, "timeout", "t", time.Duration(20) * time.Second, "Set the request timeout")
 	app.PersistentFlags().BoolVar(&c.Dump, "dump", false, "Dump HTTP request and response.")
-
+{{ if .HasCSRF }}	var csrfToken string
+	app.PersistentFlags().StringVar(&csrfToken, "csrf-token", "", "CSRF token echoed in requests made to CSRF protected resources")
+{{ end }}
 {{ if .HasSigners }}	// Register signer flags
 {{ if .HasBasicAuthSigners }} var user, pass string
 	app.PersistentFlags().StringVar(&user, "user", "", "Username used for authentication")
@@ -639,6 +841,11 @@ func main() {
 {{ end }}{{ if .HasTokenSigners }} var token, typ string
 	app.PersistentFlags().StringVar(&token, "token", "", "Token used for authentication")
 	app.PersistentFlags().StringVar(&typ, "token-type", "Bearer", "Token type used for authentication")
+{{ end }}{{ if .HasHMACSigners }} var keyID, secret string
+	app.PersistentFlags().StringVar(&keyID, "key-id", "", "ID of the key used to sign requests")
+	app.PersistentFlags().StringVar(&secret, "secret", "", "Secret used to sign requests")
+{{ end }}{{ if .HasCookieSigners }} var session string
+	app.PersistentFlags().StringVar(&session, "session", "", "Value of the session cookie used for authentication")
 {{ end }}
 	// Parse flags and setup signers
 	app.ParseFlags(os.Args)
@@ -652,7 +859,9 @@ func main() {
 	// Initialize API client
 {{ range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}	c.Set{{ goify $security.SchemeName true }}Signer({{ goify $security.SchemeName false }}Signer)
-{{ end }}{{ end }} c.UserAgent = "{{ .API.Name }}-cli/{{ .Version }}"
+{{ end }}{{ end }}{{ if .HasCSRF }}{{ if not .HasSigners }}	app.ParseFlags(os.Args)
+{{ end }}	c.SetCSRFSigner(&goaclient.CSRFSigner{Token: csrfToken})
+{{ end }} c.UserAgent = "{{ .API.Name }}-cli/{{ .Version }}"
 
 	// Register API commands
 	cli.RegisterCommands(app, c)
@@ -675,7 +884,16 @@ func newHTTPClient() *http.Client {
 // new{{ goify $security.SchemeName true }}Signer returns the request signer used for authenticating
 // against the {{ $security.SchemeName }} security scheme.
 func new{{ goify $security.SchemeName true }}Signer({{ signerSignature $security }}) goaclient.Signer {
-{{ if eq .Type "basic" }}	return &goaclient.BasicSigner{
+{{ if eq .Context "HMACSecurity" }}	return &goaclient.HMACSigner{
+		KeyID: keyID,
+		Secret: secret,
+		Header: {{ printf "%q" $security.Name }},
+	}
+{{ else if eq .Context "CookieSecurity" }}	return &goaclient.CookieSigner{
+		Name: {{ printf "%q" $security.Name }},
+		Value: session,
+	}
+{{ else if eq .Type "basic" }}	return &goaclient.BasicSigner{
 		Username: user,
 		Password: pass,
 	}
@@ -700,13 +918,16 @@ const commandTypesTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Name (titl
 	{{ $cmdName }} struct {
 {{ if .Payload }}		Payload string
 		ContentType string
-{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
+{{ if and .Payload.Type.IsObject (not .WebSocket) }}		Interactive bool
+{{ end }}{{ end }}{{ $params := defaultRouteParams . }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false }}
 {{ end }}{{ end }}{{ $params := .QueryParams }}{{ if $params }}{{ range $name, $att := $params.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
 {{ end }}{{ end }}{{ $headers := .Headers }}{{ if $headers }}{{ range $name, $att := $headers.Type.ToObject }}{{ if $att.Description }}		{{ multiComment $att.Description }}
 {{ end }}		{{ goify $name true }} {{ cmdFieldType $att.Type false}}
 {{ end }}{{ end }}		PrettyPrint bool
+		Output string
+		Select string
 	}
 
 `
@@ -729,10 +950,11 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ $default := defaultPath .Action }}{{ if $default }}	path = "{{ $default }}"
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action)}}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
-	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
+{{ with .Deprecation }}	fmt.Fprintln(os.Stderr, {{ printf "%q" . }})
+{{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	ws, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }})
+	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{ range .Action.QueryObjects }}, nil{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
@@ -797,7 +1019,8 @@ const registerTmpl = `{{ $cmdName := goify (printf "%s%sCommand" .Action.Name (t
 func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Client) {
 {{ if .Action.Payload }}	cc.Flags().StringVar(&cmd.Payload, "payload", "", "Request body encoded in JSON")
 	cc.Flags().StringVar(&cmd.ContentType, "content", "", "Request content type override, e.g. 'application/x-www-form-urlencoded'")
-{{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
+{{ if and .Action.Payload.Type.IsObject (not .Action.WebSocket) }}	cc.Flags().BoolVar(&cmd.Interactive, "interactive", false, "Prompt for the required payload fields when --payload is not set")
+{{ end }}{{ end }}{{ $pparams := defaultRouteParams .Action }}{{ if $pparams }}{{ range $pname, $pparam := $pparams.Type.ToObject }}{{ $tmp := goify $pname false }}{{/*
 */}}{{ if not $pparam.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $pparam.Type false }}
 {{ end }}	cc.Flags().{{ flagType $pparam }}Var(&cmd.{{ goify $pname true }}, "{{ $pname }}", {{/*
 */}}{{ if $pparam.DefaultValue }}{{ defaultVal $pparam }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $pparam.Description }}` + "`" + `)
@@ -805,9 +1028,10 @@ func (cmd *{{ $cmdName }}) RegisterFlags(cc *cobra.Command, c *{{ .Package }}.Cl
 */}}{{ if not $param.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $param.Type false }}
 {{ end }}	cc.Flags().{{ flagType $param }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
 */}}{{ if $param.DefaultValue }}{{ defaultVal $param }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $param.Description }}` + "`" + `)
-{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{/*
-*/}} cc.Flags().StringVar(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
-*/}}{{ if $header.DefaultValue }}{{ defaultVal $header }}{{ else }}""{{ end }}, ` + "`" + `{{ escapeBackticks $header.Description }}` + "`" + `)
+{{ end }}{{ end }}{{ $headers := .Action.Headers }}{{ if $headers }}{{ range $name, $header := $headers.Type.ToObject }}{{ $tmp := goify $name false }}{{/*
+*/}}{{ if not $header.DefaultValue }}	var {{ $tmp }} {{ cmdFieldType $header.Type false }}
+{{ end }}	cc.Flags().{{ flagType $header }}Var(&cmd.{{ goify $name true }}, "{{ $name }}", {{/*
+*/}}{{ if $header.DefaultValue }}{{ defaultVal $header }}{{ else }}{{ $tmp }}{{ end }}, ` + "`" + `{{ escapeBackticks $header.Description }}` + "`" + `)
 {{ end }}{{ end }}}`
 
 const commandsTmpl = `
@@ -820,7 +1044,16 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ $default := defaultPath .Action }}{{ if $default }}	path = "{{ $default }}"
 {{ else }}{{ $pparams := defaultRouteParams .Action }}	path = fmt.Sprintf({{ printf "%q" (defaultRouteTemplate .Action) }}, {{ joinRouteParams .Action $pparams }})
 {{ end }}	}
-{{ if .Action.Payload }}var payload {{ gotyperefext .Action.Payload 2 .Package }}
+{{ if .Action.Payload }}{{ if .Action.Payload.Type.IsObject }}	if cmd.Interactive && cmd.Payload == "" {
+		p, err := promptPayload(os.Stdin, os.Stderr, []promptField{
+{{ range promptFields .Action.Payload }}			{Name: {{ printf "%q" .Name }}, Description: {{ printf "%q" .Description }}, JSON: {{ .JSON }}},
+{{ end }}		})
+		if err != nil {
+			return err
+		}
+		cmd.Payload = p
+	}
+{{ end }}var payload {{ gotyperefext .Action.Payload 2 .Package }}
 	if cmd.Payload != "" {
 		err := json.Unmarshal([]byte(cmd.Payload), &payload)
 		if err != nil {
@@ -828,29 +1061,204 @@ func (cmd *{{ $cmdName }}) Run(c *{{ .Package }}.Client, args []string) error {
 {{ else }}			return fmt.Errorf("failed to deserialize payload: %s", err)
 {{ end }}		}
 	}
+{{ end }}{{ with .Deprecation }}	fmt.Fprintln(os.Stderr, {{ printf "%q" . }})
 {{ end }}	logger := goa.NewLogger(log.New(os.Stderr, "", log.LstdFlags))
 	ctx := goa.WithLogger(context.Background(), logger){{ $specialTypeResult := handleSpecialTypes .Action.QueryParams .Action.Headers }}{{ $specialTypeResult.Output }}
 	resp, err := c.{{ goify (printf "%s%s" .Action.Name (title .Resource.Name)) true }}(ctx, path{{ if .Action.Payload }}, {{/*
-	*/}}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ else }}{{ end }}{{/*
-	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{/*
+	*/}}{{ with flattenedField .Action }}payload.{{ . }}{{ else }}{{ if or .Action.Payload.Type.IsObject .Action.Payload.IsPrimitive }}&{{ end }}payload{{ end }}{{ else }}{{ end }}{{/*
+	*/}}{{ $params := joinNames true .Action.QueryParams .Action.Headers }}{{ if $params }}, {{ format $params $specialTypeResult.Temps }}{{ end }}{{ range .Action.QueryObjects }}, nil{{ end }}{{/*
 	*/}}{{ if and .Action.Payload .HasMultiContent }}, cmd.ContentType{{ end }})
 	if err != nil {
 		goa.LogError(ctx, "failed", "err", err)
 		return err
 	}
 
-	goaclient.HandleResponse(c.Client, resp, cmd.PrettyPrint)
+	goaclient.HandleResponseOutput(c.Client, resp, &goaclient.OutputOptions{
+		Format:  cmd.Output,
+		Pretty:  cmd.PrettyPrint,
+{{ with tableColumns .Action }}		Columns: []string{ {{- range $i, $c := . }}{{ if $i }}, {{ end }}{{ printf "%q" $c }}{{ end -}} },
+{{ end }}		Select:  cmd.Select,
+	})
 	return nil
 }
 `
 
+// completionT is the code of the functions that generate the shell completion scripts of the CLI.
+const completionT = `
+// genCompletion writes the completion script of the given shell for the app commands.
+func genCompletion(app *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return app.GenBashCompletion(w)
+	case "zsh":
+		return app.GenZshCompletion(w)
+	case "fish":
+		genFishCompletion(app, w)
+		return nil
+	}
+	return fmt.Errorf("unsupported shell %#v, must be one of bash, zsh or fish", shell)
+}
+
+// genFishCompletion writes the fish completion script of the app commands.
+func genFishCompletion(app *cobra.Command, w io.Writer) {
+	name := app.Name()
+	fmt.Fprintf(w, "complete -c %s -f\n", name)
+	fishFlags(w, name, "", app.PersistentFlags())
+	for _, cmd := range app.Commands() {
+		if !cmd.IsAvailableCommand() {
+			continue
+		}
+		fmt.Fprintf(w, "complete -c %s -n '__fish_use_subcommand' -a %s%s\n", name, cmd.Name(), fishDesc(cmd.Short))
+		for _, sub := range cmd.Commands() {
+			if !sub.IsAvailableCommand() {
+				continue
+			}
+			cond := "__fish_seen_subcommand_from " + cmd.Name()
+			fmt.Fprintf(w, "complete -c %s -n '%s' -a %s%s\n", name, cond, sub.Name(), fishDesc(sub.Short))
+			fishFlags(w, name, cond+"; and __fish_seen_subcommand_from "+sub.Name(), sub.NonInheritedFlags())
+		}
+	}
+}
+
+// fishFlags writes the fish completions of the given flags if cond holds.
+func fishFlags(w io.Writer, name, cond string, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden {
+			return
+		}
+		line := "complete -c " + name
+		if cond != "" {
+			line += " -n '" + cond + "'"
+		}
+		line += " -l " + f.Name
+		if f.Shorthand != "" {
+			line += " -s " + f.Shorthand
+		}
+		if f.Value.Type() != "bool" {
+			line += " -r"
+		}
+		fmt.Fprintf(w, "%s%s\n", line, fishDesc(f.Usage))
+	})
+}
+
+// fishDesc returns the fish completion option that sets the given description, if any.
+func fishDesc(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	desc = strings.Replace(desc, "\\", "\\\\", -1)
+	desc = strings.Replace(desc, "'", "\\'", -1)
+	return " -d '" + strings.Replace(desc, "\n", " ", -1) + "'"
+}
+`
+
+// promptT is the code of the function used by the interactive mode of the commands.
+const promptT = `
+// promptField describes a payload field prompted in interactive mode.
+type promptField struct {
+	Name        string
+	Description string
+	JSON        bool
+}
+
+// promptPayload prompts for the values of the given payload fields and returns the JSON
+// encoded payload. The values of the fields whose JSON flag is set are decoded as JSON.
+func promptPayload(in io.Reader, out io.Writer, fields []promptField) (string, error) {
+	r := bufio.NewReader(in)
+	payload := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		for {
+			if f.Description != "" {
+				fmt.Fprintf(out, "%s (%s): ", f.Name, f.Description)
+			} else {
+				fmt.Fprintf(out, "%s: ", f.Name)
+			}
+			line, err := r.ReadString('\n')
+			if err != nil && line == "" {
+				return "", fmt.Errorf("failed to read %s: %s", f.Name, err)
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				fmt.Fprintf(out, "%s is required\n", f.Name)
+				continue
+			}
+			if !f.JSON {
+				payload[f.Name] = line
+				break
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(line), &v); err != nil {
+				fmt.Fprintf(out, "invalid value for %s: %s\n", f.Name, err)
+				continue
+			}
+			payload[f.Name] = v
+			break
+		}
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+`
+
+// groupedUsageT is the code of the CLI usage template that lists the commands by group.
+const groupedUsageT = `
+// groupedUsageTemplate is the usage template of the commands, it lists the available commands by
+// group.
+const groupedUsageTemplate = ` + "`" + `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if .HasAvailableSubCommands}}{{if commandGroup .Commands ""}}
+
+Available Commands:{{range .Commands}}{{if and (or .IsAvailableCommand (eq .Name "help")) (not (index .Annotations "group"))}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{range $group := commandGroups .Commands}}
+
+{{$group}} Commands:{{range $.Commands}}{{if and .IsAvailableCommand (eq (index .Annotations "group") $group)}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+
+Flags:
+{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+` + "`" + `
+
+// commandGroups returns the sorted names of the groups of the given commands.
+func commandGroups(cmds []*cobra.Command) []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, c := range cmds {
+		if g := c.Annotations["group"]; g != "" && c.IsAvailableCommand() && !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// commandGroup returns true if any of the given commands belongs to the given group.
+func commandGroup(cmds []*cobra.Command, group string) bool {
+	for _, c := range cmds {
+		if c.Annotations["group"] == group && (c.IsAvailableCommand() || c.Name() == "help") {
+			return true
+		}
+	}
+	return false
+}
+`
+
 // Takes map[string][]*design.ActionDefinition as input
 const registerCmdsT = `// RegisterCommands registers the resource action CLI commands.
 func RegisterCommands(app *cobra.Command, c *{{ .Package }}.Client) {
 {{ with .Actions }}{{ if gt (len .) 0 }}	var command, sub *cobra.Command
 {{ end }}{{ range $name, $actions := . }}	command = &cobra.Command{
 		Use:   "{{ kebabCase $name }}",
-		Short: ` + "`" + `{{ if eq (len $actions) 1 }}{{ $a := index $actions 0 }}{{ escapeBackticks $a.Description }}{{ else }}{{ $name }} action{{ end }}` + "`" + `,
+		Short: ` + "`" + `{{ if eq (len $actions) 1 }}{{ $a := index $actions 0 }}{{ escapeBackticks $a.Description }}{{ else }}{{ $name }} action{{ end }}` + "`" + `,{{ with commonGroup $actions }}
+		Annotations: map[string]string{"group": {{ printf "%q" . }}},{{ end }}
 	}
 {{ range $action := $actions }}{{ $cmdName := goify (printf "%s%sCommand" $action.Name (title (kebabCase $action.Parent.Name))) true }}{{/*
 */}}{{ $tmp := tempvar }}	{{ $tmp }} := new({{ $cmdName }})
@@ -859,16 +1267,40 @@ func RegisterCommands(app *cobra.Command, c *{{ .Package }}.Client) {
 		Short: ` + "`" + `{{ escapeBackticks $action.Parent.Description }}` + "`" + `,{{ if shouldAddExample $action.Payload }}
 		Long:  ` + "`" + `{{ escapeBackticks $action.Parent.Description }}
 
-Payload example:
+{{ if $action.Payload.Examples }}{{ range $i, $ex := $action.Payload.Examples }}{{ if $i }}
+
+{{ end }}Payload example ({{ $ex.Summary }}):
 
-{{ formatExample $action.Payload.Example }}` + "`" + `,{{ end }}
-		RunE:  func(cmd *cobra.Command, args []string) error { return {{ $tmp }}.Run(c, args) },
+{{ formatExample $ex.Value }}{{ end }}{{ else }}Payload example:
+
+{{ formatExample $action.Payload.Example }}{{ end }}` + "`" + `,{{ end }}
+		RunE:  func(cmd *cobra.Command, args []string) error { return {{ $tmp }}.Run(c, args) },{{ with actionGroup $action }}
+		Annotations: map[string]string{"group": {{ printf "%q" . }}},{{ end }}
 	}
 	{{ $tmp }}.RegisterFlags(sub, c)
 	sub.PersistentFlags().BoolVar(&{{ $tmp }}.PrettyPrint, "pp", false, "Pretty print response body")
+	sub.PersistentFlags().StringVarP(&{{ $tmp }}.Output, "output", "o", "json", "Output format: json, yaml or table")
+	sub.PersistentFlags().StringVar(&{{ $tmp }}.Select, "jq", "", "Select the output fields with a jq-like path, e.g. .items[].name")
 	command.AddCommand(sub)
 {{ end }}app.AddCommand(command)
-{{ end }}{{ end }}{{ if .HasDownloads }}
+{{ end }}{{ end }}{{ if .HasGroups }}
+	cobra.AddTemplateFunc("commandGroups", commandGroups)
+	cobra.AddTemplateFunc("commandGroup", commandGroup)
+	app.SetUsageTemplate(groupedUsageTemplate)
+{{ end }}	app.AddCommand(&cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Generate the shell completion script",
+		Long: ` + "`" + `Generate the shell completion script, e.g. for bash:
+
+	source <({{ .Tool }} completion bash)` + "`" + `,
+		ValidArgs: []string{"bash", "zsh", "fish"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("completion requires the name of the shell")
+			}
+			return genCompletion(app, args[0], os.Stdout)
+		},
+	}){{ if .HasDownloads }}
 	dl := new(DownloadCommand)
 	dlc := &cobra.Command{
 		Use:	"download [PATH]",
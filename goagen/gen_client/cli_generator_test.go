@@ -95,6 +95,17 @@ var _ = Describe("Generate", func() {
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(content).Should(HavePrefix(commandHeader))
 			})
+
+			It("generates the completion command", func() {
+				Ω(genErr).Should(BeNil())
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+				content := string(c)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(content).Should(ContainSubstring(`Use:   "completion [bash|zsh|fish]"`))
+				Ω(content).Should(ContainSubstring("return genCompletion(app, args[0], os.Stdout)"))
+				Ω(content).Should(ContainSubstring("func genFishCompletion(app *cobra.Command, w io.Writer) {"))
+				Ω(content).ShouldNot(ContainSubstring("func promptPayload("))
+			})
 		})
 	})
 
@@ -190,6 +201,214 @@ var _ = Describe("Generate", func() {
 		})
 	})
 
+	Context("with an action with a catch-all parameter", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Params: &design.AttributeDefinition{
+									Type: design.Object{
+										"path": &design.AttributeDefinition{Type: design.String},
+									},
+								},
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "/files/*path",
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("keeps the slashes of the parameter in the command path", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`path = fmt.Sprintf("/files/%v", strings.Replace(url.QueryEscape(cmd.Path), "%2F", "/", -1))`))
+		})
+	})
+
+	Context("with resources in CLI groups", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name:     "foo",
+						Metadata: dslengine.MetadataDefinition{"cli:group": {"Cellar"}},
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/foos"}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("annotates the commands with their group", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(strings.Count(content, `Annotations: map[string]string{"group": "Cellar"}`)).Should(Equal(2))
+			Ω(content).Should(ContainSubstring("app.SetUsageTemplate(groupedUsageTemplate)"))
+			Ω(content).Should(ContainSubstring("func commandGroups(cmds []*cobra.Command) []string {"))
+		})
+	})
+
+	Context("with an action with a payload with required fields and named examples", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"name":  &design.AttributeDefinition{Type: design.String, Description: "Name of foo"},
+						"count": &design.AttributeDefinition{Type: design.Integer},
+					},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"name", "count"}},
+					Examples: []*design.ExampleDefinition{
+						{Summary: "short", Value: map[string]interface{}{"name": "a"}},
+						{Summary: "long", Value: map[string]interface{}{"name": "abcdef"}},
+					},
+				},
+				TypeName: "FooPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:    "create",
+								Payload: payload,
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/foos"}},
+							},
+						},
+					},
+				},
+				Types: map[string]*design.UserTypeDefinition{"FooPayload": payload},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("prompts for the required payload fields in interactive mode", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`cc.Flags().BoolVar(&cmd.Interactive, "interactive", false, `))
+			Ω(content).Should(ContainSubstring("if cmd.Interactive && cmd.Payload == \"\" {"))
+			Ω(content).Should(ContainSubstring(`{Name: "name", Description: "Name of foo", JSON: false},`))
+			Ω(content).Should(ContainSubstring(`{Name: "count", Description: "", JSON: true},`))
+			Ω(content).Should(ContainSubstring("func promptPayload(in io.Reader, out io.Writer, fields []promptField) (string, error) {"))
+		})
+
+		It("lists the named examples in the command help", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("Payload example (short):\n\n{\n   \"name\": \"a\"\n}\n\nPayload example (long):"))
+			Ω(content).ShouldNot(ContainSubstring("Payload example:"))
+		})
+	})
+
+	Context("with an action returning a media type", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.ProjectedMediaTypes = make(design.MediaTypeRoot)
+			attrs := design.Object{
+				"id":   &design.AttributeDefinition{Type: design.Integer},
+				"name": &design.AttributeDefinition{Type: design.String},
+			}
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{Type: attrs},
+					TypeName:            "Foo",
+				},
+				Identifier: "application/vnd.foo+json",
+				Views: map[string]*design.ViewDefinition{
+					"default": {
+						AttributeDefinition: &design.AttributeDefinition{Type: design.Object{"name": attrs["name"]}},
+						Name:                "default",
+					},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Title:       "dummy API with no resource",
+				Description: "I told you it's dummy",
+				Consumes:    design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/foos"}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200, MediaType: "application/vnd.foo+json"},
+								},
+							},
+						},
+					},
+				},
+				MediaTypes: map[string]*design.MediaTypeDefinition{"application/vnd.foo+json": mt},
+			}
+			mt.Views["default"].Parent = mt
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("renders the response using the output flags", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			content := string(c)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`.PersistentFlags().StringVarP(&tmp1.Output, "output", "o", "json", `))
+			Ω(content).Should(ContainSubstring(`.PersistentFlags().StringVar(&tmp1.Select, "jq", "", `))
+			Ω(content).Should(ContainSubstring("goaclient.HandleResponseOutput(c.Client, resp, &goaclient.OutputOptions{"))
+			Ω(content).Should(ContainSubstring(`Columns: []string{"name"},`))
+		})
+	})
+
 	Context("with an action with an integer parameter with no default value", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -361,7 +580,7 @@ var _ = Describe("Generate", func() {
 
 			It("properly escapes the multi-line string used in the short description", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(12))
 				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 				content := string(c)
 				Ω(err).ShouldNot(HaveOccurred())
@@ -379,7 +598,7 @@ var _ = Describe("Generate", func() {
 
 			It("properly escapes the multi-line string used in the short description", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(12))
 				c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 				content := string(c)
 				Ω(err).ShouldNot(HaveOccurred())
@@ -426,7 +645,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates direct access to Command field when resolving path", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
@@ -483,7 +702,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates registers the signer flags from main", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
 			content := string(c)
 			Ω(err).ShouldNot(HaveOccurred())
@@ -491,4 +710,42 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(ContainSubstring("c.SetJWT1Signer(jwt1Signer)"))
 		})
 	})
+
+	Context("with a resumable action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:      "create",
+								Resumable: true,
+								Routes:    []*design.RouteDefinition{{Verb: "POST", Path: "/uploads"}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+			createAct.Finalize()
+		})
+
+		It("registers a typed flag for the upload length header and builds the tool", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(MatchRegexp(`UploadLength\s+int\n`))
+			Ω(content).Should(ContainSubstring(`cc.Flags().IntVar(&cmd.UploadLength, "Upload-Length", uploadLength, `))
+			_, err = gexec.Build(filepath.Join(testgenPackagePath, "tool", "testapi-cli"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
 })
@@ -9,8 +9,29 @@ The generated code includes a client package with:
     * Helper functions to build the corresponding request paths
     * Structs for the action payloads and dependent types
     * Structs for the action media types and corresponding decoder functions
+    * Sentinel errors for the error responses wrapped by the errors that DecodeError returns
+    * Methods that send the batch requests of the actions with a batch endpoint
+    * ShowJob and WaitJob methods that poll the job status endpoint of the asynchronous actions
+    * A ResumeUpload method that sends the content of the uploads created by the resumable actions
+    * Stream methods that reconnect the websocket connections with a jittered backoff
+    * Functions that read the trailers of the responses into the decoded media types
+    * Methods that decode the tagged responses of an action according to their status codes
+    * A NewWithMetrics constructor that records the calls, latencies and error classes per endpoint
 
 The generated code also includes a CLI tool with commands for each action and sub-commands for
-each resource.
+each resource. The "completion" command of the tool writes the bash, zsh or fish completion script
+of the commands. The commands of actions whose payload is an object accept an --interactive flag
+that prompts for the required payload fields when --payload is not set. The --output flag renders
+the response bodies as JSON, YAML or as a table whose columns are the attributes of the response
+media type and the --jq flag selects the rendered fields with a jq-like path.
+
+The load/main.go file of the tool directory builds a load testing tool that calls the client
+methods with the design example payloads at the rate and concurrency given on its command line
+and prints the latency percentiles of each endpoint.
+
+When the import path of the app package is given with --app-pkg the generator also creates a
+roundtrip_test.go file in the client package. The tests encode random values of the payload and
+result types with the client, decode and encode them back with the app types and check that the
+client decodes the same values.
 */
 package genclient
@@ -0,0 +1,208 @@
+package genclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// exampleData describes a generated client usage example.
+type exampleData struct {
+	// Method is the name of the client method called by the example.
+	Method string
+	// Resource and Action are the names of the resource and action of the method.
+	Resource, Action string
+	// PathFunc is the name of the function that computes the request path.
+	PathFunc string
+	// PathArgs lists the Go literals of the path function arguments.
+	PathArgs []string
+	// PayloadType is the name of the client payload type, empty if the method takes no payload
+	// or a flattened payload.
+	PayloadType string
+	// Payload is the Go string literal of the JSON encoded payload.
+	Payload string
+	// Args lists the Go literals of the method arguments following the path and payload.
+	Args []string
+}
+
+// generateExamples generates the Example functions that document the use of the client methods.
+func (g *Generator) generateExamples(pkgDir, clientPkg string) (err error) {
+	examples := g.examples()
+	if len(examples) == 0 {
+		return nil
+	}
+
+	exampleFile := filepath.Join(pkgDir, "examples_test.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(exampleFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("log"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa/uuid"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+		codegen.SimpleImport(clientPkg),
+	}
+	title := fmt.Sprintf("%s: Client usage examples", g.API.Context())
+	if err = file.WriteHeader(title, g.Target+"_test", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, exampleFile)
+
+	host := g.API.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	data := map[string]interface{}{
+		"ClientPkg": g.Target,
+		"Host":      host,
+		"Examples":  examples,
+	}
+	return file.ExecuteTemplate("examples", examplesT, template.FuncMap{"join": strings.Join}, data)
+}
+
+// examples returns the data of the examples of the client methods. Only the HTTP client methods
+// of actions whose payload, if any, is an object that the examples can decode from JSON are
+// documented.
+func (g *Generator) examples() []*exampleData {
+	var examples []*exampleData
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() || a.PayloadMultipart || len(a.Routes) == 0 {
+				return nil
+			}
+			method := codegen.Goify(a.Name+strings.Title(res.Name), true)
+			ex := &exampleData{
+				Method:   method,
+				Resource: res.Name,
+				Action:   a.Name,
+				PathFunc: method + "Path",
+			}
+			for _, p := range a.Routes[0].Params() {
+				ex.PathArgs = append(ex.PathArgs, exampleLiteral(a.Params.Type.ToObject()[p], true))
+			}
+			if p := a.Payload; p != nil {
+				if n := flattenedPayload(a); n != "" {
+					ex.Args = append(ex.Args, exampleLiteral(p.ToObject()[n], !p.IsPrimitivePointer(n)))
+				} else {
+					if !p.Type.IsObject() || hasFile(p.AttributeDefinition, nil) {
+						return nil
+					}
+					if path, _ := codegen.TypePackage(p); path != "" {
+						return nil
+					}
+					ex.PayloadType = codegen.GoTypeName(p, nil, 0, false)
+					ex.Payload = goStringLiteral(examplePayload(p))
+					ex.Args = append(ex.Args, "&payload")
+				}
+			}
+			ex.Args = append(ex.Args, exampleParams(a.QueryParams)...)
+			ex.Args = append(ex.Args, exampleParams(a.Headers)...)
+			for range a.QueryObjects {
+				ex.Args = append(ex.Args, "nil")
+			}
+			if a.Payload != nil && len(g.API.Consumes) > 1 {
+				ex.Args = append(ex.Args, fmt.Sprintf("%q", g.API.Consumes[0].MIMETypes[0]))
+			}
+			examples = append(examples, ex)
+			return nil
+		})
+	})
+	return examples
+}
+
+// examplePayload returns the JSON encoding of the example of the given payload, of a random
+// value if the example cannot be encoded.
+func examplePayload(p *design.UserTypeDefinition) string {
+	if p.Example != nil {
+		if b, err := json.Marshal(p.Example); err == nil {
+			return string(b)
+		}
+	}
+	b, _ := json.Marshal(sampleValue(p.AttributeDefinition, design.NewRandomGenerator(p.TypeName), roundTripDepth))
+	return string(b)
+}
+
+// exampleParams returns the literals of the client method arguments that correspond to the given
+// params in the order of the method signature: required params first then optional params, both
+// sorted by name.
+func exampleParams(att *design.AttributeDefinition) []string {
+	reqData, optData := initParams(att)
+	sort.Sort(byParamName(reqData))
+	sort.Sort(byParamName(optData))
+	args := make([]string, 0, len(reqData)+len(optData))
+	for _, p := range reqData {
+		args = append(args, exampleLiteral(p.Attribute, true))
+	}
+	for _, p := range optData {
+		args = append(args, exampleLiteral(p.Attribute, false))
+	}
+	return args
+}
+
+// exampleLiteral returns the Go literal of the example value of the given attribute. The
+// literals of optional and non-primitive values are nil, UUIDs are randomly generated.
+func exampleLiteral(att *design.AttributeDefinition, required bool) string {
+	if att == nil || !required || !att.Type.IsPrimitive() {
+		return "nil"
+	}
+	ex := att.Example
+	if ex == nil {
+		ex = sampleValue(att, design.NewRandomGenerator(att.Type.Name()), 0)
+	}
+	switch att.Type.Kind() {
+	case design.BooleanKind, design.IntegerKind, design.NumberKind:
+		return fmt.Sprint(ex)
+	case design.StringKind:
+		return fmt.Sprintf("%q", fmt.Sprint(ex))
+	case design.DateTimeKind:
+		t, ok := ex.(time.Time)
+		if !ok {
+			t, _ = time.Parse(time.RFC3339, fmt.Sprint(ex))
+		}
+		t = t.UTC()
+		return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, 0, time.UTC)",
+			t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+	case design.UUIDKind:
+		return "uuid.NewV4()"
+	}
+	return "nil"
+}
+
+const examplesT = `{{ range .Examples }}
+// This example calls the {{ .Action }} action of the {{ .Resource }} resource{{ if .Payload }} with a
+// payload built from the design examples{{ end }}.
+func ExampleClient_{{ .Method }}() {
+	c := {{ $.ClientPkg }}.New(goaclient.HTTPClientDoer(http.DefaultClient))
+	c.Host = {{ printf "%q" $.Host }}
+{{ if .PayloadType }}	var payload {{ $.ClientPkg }}.{{ .PayloadType }}
+	if err := json.Unmarshal([]byte({{ .Payload }}), &payload); err != nil {
+		log.Fatal(err)
+	}
+{{ end }}	resp, err := c.{{ .Method }}(context.Background(), {{ $.ClientPkg }}.{{ .PathFunc }}({{ join .PathArgs ", " }}){{ range .Args }}, {{ . }}{{ end }})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer resp.Body.Close()
+	fmt.Println(resp.Status)
+}
+{{ end }}`
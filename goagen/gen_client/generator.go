@@ -39,6 +39,7 @@ type Generator struct {
 	ToolDirName    string                // Name of tool directory where CLI main is generated once
 	Tool           string                // Name of CLI tool
 	NoTool         bool                  // Whether to skip tool generation
+	AppPkg         string                // Import path of the app package used by the round-trip tests, may be relative to OutDir
 	genfiles       []string
 	encoders       []*genapp.EncoderTemplateData
 	decoders       []*genapp.EncoderTemplateData
@@ -48,8 +49,8 @@ type Generator struct {
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, target, toolDir, tool, ver string
-		notool, regen                      bool
+		outDir, target, toolDir, tool, ver, appPkg, services string
+		notool, regen                                        bool
 	)
 	dtool := defaultToolName(design.Design)
 
@@ -61,9 +62,16 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.StringVar(&appPkg, "app-pkg", "", "")
 	set.String("design", "", "")
 	set.Bool("force", false, "")
+	set.Bool("stubs", false, "")
+	set.Bool("deploy", false, "")
+	set.Bool("http3", false, "")
 	set.Bool("notest", false, "")
+	set.String("di", "", "")
+	set.Bool("split", false, "")
+	set.StringVar(&services, "service", "", "")
 	set.Parse(os.Args[1:])
 
 	// First check compatibility
@@ -71,9 +79,14 @@ func Generate() (files []string, err error) {
 		return nil, err
 	}
 
+	api, err := codegen.SelectedAPI(services)
+	if err != nil {
+		return nil, err
+	}
+
 	// Now proceed
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, API: design.Design}
+	g := &Generator{OutDir: outDir, Target: target, ToolDirName: toolDir, Tool: tool, NoTool: notool, AppPkg: appPkg, API: api}
 
 	return g.Generate()
 }
@@ -144,8 +157,10 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"add":                func(a, b int) int { return a + b },
 			"cmdFieldType":       cmdFieldType,
 			"defaultPath":        defaultPath,
+			"emptyCollections":   func() bool { return g.API.NilCollections == design.NilCollectionsEmpty },
 			"escapeBackticks":    escapeBackticks,
 			"goify":              codegen.Goify,
+			"goenumtypedefs":     codegen.GoEnumTypeDefs,
 			"gotypedef":          codegen.GoTypeDef,
 			"gotypedesc":         codegen.GoTypeDesc,
 			"gotypename":         codegen.GoTypeName,
@@ -156,13 +171,16 @@ func (g *Generator) Generate() (_ []string, err error) {
 			"multiComment":       multiComment,
 			"pathParams":         pathParams,
 			"pathTemplate":       pathTemplate,
+			"signerKind":         signerKind,
 			"signerType":         signerType,
 			"tempvar":            codegen.Tempvar,
+			"timeCodec":          codegen.TimeCodec,
 			"title":              strings.Title,
 			"toString":           toString,
 			"typeName":           typeName,
 			"format":             format,
 			"handleSpecialTypes": handleSpecialTypes,
+			"validationCode":     codegen.NewValidator().Code,
 		}
 		clientPkg, err = codegen.PackagePath(pkgDir)
 		if err != nil {
@@ -205,6 +223,60 @@ func (g *Generator) Generate() (_ []string, err error) {
 		return
 	}
 
+	// Generate client/jobs.go
+	if err = g.generateJobs(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/uploads.go
+	if err = g.generateUploads(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/header_groups.go
+	if err = g.generateHeaderGroups(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/parts.go
+	if err = g.generateParts(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/trailers.go
+	if err = g.generateTrailers(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/responses.go
+	if err = g.generateResponses(pkgDir); err != nil {
+		return
+	}
+
+	// Generate client/replay.go
+	if err = g.generateReplay(pkgDir); err != nil {
+		return
+	}
+
+	// Generate tool/load/main.go
+	if !g.NoTool {
+		if err = g.generateLoad(filepath.Join(g.OutDir, g.ToolDirName, "load"), clientPkg); err != nil {
+			return
+		}
+	}
+
+	// Generate client/examples_test.go
+	if err = g.generateExamples(pkgDir, clientPkg); err != nil {
+		return
+	}
+
+	// Generate client/roundtrip_test.go
+	if g.AppPkg != "" {
+		if err = g.generateRoundTripTests(pkgDir, clientPkg); err != nil {
+			return
+		}
+	}
+
 	return g.genfiles, nil
 }
 
@@ -265,7 +337,11 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 
 	// Setup codegen
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("errors"),
+		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strings"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
@@ -281,18 +357,73 @@ func (g *Generator) generateClient(clientFile string, clientPkg string, funcs te
 
 	// Generate
 	data := struct {
-		API      *design.APIDefinition
-		Encoders []*genapp.EncoderTemplateData
-		Decoders []*genapp.EncoderTemplateData
+		API            *design.APIDefinition
+		Encoders       []*genapp.EncoderTemplateData
+		Decoders       []*genapp.EncoderTemplateData
+		Errors         []*errorData
+		SensitiveNames []string
+		SensitiveTypes []*codegen.SensitiveType
 	}{
-		API:      g.API,
-		Encoders: encoders,
-		Decoders: decoders,
+		API:            g.API,
+		Encoders:       encoders,
+		Decoders:       decoders,
+		Errors:         g.errorResponses(),
+		SensitiveNames: g.API.SensitiveNames(),
+		SensitiveTypes: codegen.SensitiveTypes(g.API),
 	}
 	err = clientTmpl.Execute(file, data)
 	return
 }
 
+// errorData describes an error response of the API for which the client declares a sentinel
+// error.
+type errorData struct {
+	// VarName is the name of the sentinel error variable.
+	VarName string
+	// Name is the name of the response.
+	Name string
+	// Status is the response status code.
+	Status int
+	// Mapped is true if DecodeError wraps the sentinel error for responses with Status. Only
+	// the first error response sorted by name is mapped for a given status code.
+	Mapped bool
+}
+
+// errorResponses returns the error responses of the API actions sorted by name.
+func (g *Generator) errorResponses() []*errorData {
+	byName := make(map[string]*design.ResponseDefinition)
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for n, r := range a.Responses {
+				if r.Status >= 400 {
+					if _, ok := byName[n]; !ok {
+						byName[n] = r
+					}
+				}
+			}
+			return nil
+		})
+	})
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	mapped := make(map[int]bool)
+	errs := make([]*errorData, len(names))
+	for i, n := range names {
+		status := byName[n].Status
+		errs[i] = &errorData{
+			VarName: "Err" + codegen.Goify(n, true),
+			Name:    n,
+			Status:  status,
+			Mapped:  !mapped[status],
+		}
+		mapped[status] = true
+	}
+	return errs
+}
+
 func (g *Generator) generateClientResources(pkgDir, clientPkg string, funcs template.FuncMap) error {
 	err := g.API.IterateResources(func(res *design.ResourceDefinition) error {
 		return g.generateResourceClient(pkgDir, res, funcs)
@@ -346,8 +477,16 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("context"),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
+	res.IterateActions(func(a *design.ActionDefinition) error {
+		if a.Payload != nil {
+			imports = codegen.AttributeImports(&design.AttributeDefinition{Type: a.Payload}, imports, nil)
+		}
+		return nil
+	})
 	title := fmt.Sprintf("%s: %s Resource Client", g.API.Context(), res.Name)
 	if err = file.WriteHeader(title, g.Target, imports); err != nil {
 		return err
@@ -406,7 +545,10 @@ func (g *Generator) generateResourceClient(pkgDir string, res *design.ResourceDe
 				return err
 			}
 		}
-		return g.generateActionClient(action, file, funcs)
+		if err := g.generateActionClient(action, file, funcs); err != nil {
+			return err
+		}
+		return g.generateBatchClient(action, file, funcs)
 	})
 	return
 }
@@ -460,13 +602,34 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		queryParams   []*paramData
 		headers       []*paramData
 		signer        string
+		scheme        string
 		clientsTmpl   = template.Must(template.New("clients").Funcs(funcs).Parse(clientsTmpl))
 		requestsTmpl  = template.Must(template.New("requests").Funcs(funcs).Parse(requestsTmpl))
 		clientsWSTmpl = template.Must(template.New("clientsws").Funcs(funcs).Parse(clientsWSTmpl))
+
+		clientsJSONRPCTmpl = template.Must(template.New("clientsjsonrpc").Funcs(funcs).Parse(clientsJSONRPCTmpl))
+		publishTmpl        = template.Must(template.New("publish").Funcs(funcs).Parse(publishTmpl))
+		fieldMaskTmpl      = template.Must(template.New("fieldmask").Funcs(funcs).Parse(fieldMaskTmpl))
 	)
+	var payloadParam, payloadInit string
 	if action.Payload != nil {
-		params = append(params, "payload "+codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false))
+		payloadRef := codegen.GoTypeRef(action.Payload, action.Payload.AllRequired(), 1, false)
+		params = append(params, "payload "+payloadRef)
 		names = append(names, "payload")
+		payloadParam = params[0]
+		if n := flattenedPayload(action); n != "" {
+			att := action.Payload.ToObject()[n]
+			varName := codegen.Goify(n, false)
+			typ := codegen.GoEnumTypeName(att)
+			if typ == "" {
+				typ = codegen.GoTypeRef(att.Type, nil, 1, false)
+			}
+			if action.Payload.IsPrimitivePointer(n) {
+				typ = "*" + typ
+			}
+			payloadParam = varName + " " + typ
+			payloadInit = fmt.Sprintf("payload := &%s{%s: %s}", strings.TrimPrefix(payloadRef, "*"), codegen.GoifyAtt(att, n, true), varName)
+		}
 	}
 
 	initParamsScoped := func(att *design.AttributeDefinition) []*paramData {
@@ -488,9 +651,51 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	}
 	queryParams = initParamsScoped(action.QueryParams)
 	headers = initParamsScoped(action.Headers)
+	queryObjects := genapp.BuildQueryObjects(action.QueryObjects)
+	for _, q := range queryObjects {
+		names = append(names, q.VarName)
+		params = append(params, q.VarName+" "+q.TypeRef)
+	}
 
-	if action.Security != nil {
-		signer = codegen.Goify(action.Security.Scheme.SchemeName, true)
+	if action.Security != nil && signerType(action.Security.Scheme) != "" {
+		// SPIFFE schemes authenticate the TLS connections instead of the requests.
+		scheme = action.Security.Scheme.SchemeName
+		signer = codegen.Goify(scheme, true)
+	}
+	var (
+		rpcParams      []string
+		rpcParamValues []*paramData
+	)
+	if design.Design.JSONRPCPath != "" && !action.WebSocket() && !action.PayloadMultipart {
+		if action.Payload != nil {
+			rpcParams = append(rpcParams, payloadParam)
+			rpcParamValues = append(rpcParamValues, &paramData{Name: "payload", VarName: "payload"})
+		}
+		var rpcAtt *design.AttributeDefinition
+		if action.Params != nil {
+			// Path parameters are always required
+			required := action.Params.AllRequired()
+			for _, r := range action.Routes[:1] {
+				required = append(required, r.Params()...)
+			}
+			rpcAtt = &design.AttributeDefinition{
+				Type:       action.Params.Type,
+				Validation: &dslengine.ValidationDefinition{Required: required},
+			}
+		}
+		reqData, optData := initParams(rpcAtt)
+		sort.Sort(byParamName(reqData))
+		sort.Sort(byParamName(optData))
+		for _, p := range reqData {
+			rpcParams = append(rpcParams, p.VarName+" "+cmdFieldType(p.Attribute.Type, false))
+		}
+		for _, p := range optData {
+			rpcParams = append(rpcParams, p.VarName+" "+cmdFieldType(p.Attribute.Type, p.Attribute.Type.IsPrimitive()))
+		}
+		rpcParamValues = append(rpcParamValues, append(reqData, optData...)...)
+		for _, h := range headers {
+			rpcParams = append(rpcParams, h.VarName+" "+cmdFieldType(h.Attribute.Type, h.CheckNil && h.Attribute.Type.IsPrimitive()))
+		}
 	}
 	data := struct {
 		Name               string
@@ -503,11 +708,24 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		HasMultiContent    bool
 		DefaultContentType string
 		Params             string
+		ClientParams       string
 		ParamNames         string
 		CanonicalScheme    string
 		Signer             string
+		SecurityScheme     string
+		CSRF               bool
 		QueryParams        []*paramData
 		Headers            []*paramData
+		QueryObjects       []*genapp.QueryObjectData
+		JSONRPCPath        string
+		JSONRPCParams      string
+		JSONRPCParamValues []*paramData
+		Topic              string
+		PayloadParam       string
+		PayloadInit        string
+		ValidatePayload    bool
+		FieldMask          string
+		Fields             []string
 	}{
 		Name:               action.Name,
 		ResourceName:       action.Parent.Name,
@@ -519,11 +737,29 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 		HasMultiContent:    len(design.Design.Consumes) > 1,
 		DefaultContentType: design.Design.Consumes[0].MIMETypes[0],
 		Params:             strings.Join(params, ", "),
+		ClientParams:       strings.Join(params, ", "),
 		ParamNames:         strings.Join(names, ", "),
 		CanonicalScheme:    action.CanonicalScheme(),
 		Signer:             signer,
+		SecurityScheme:     scheme,
+		CSRF:               action.Parent.CSRF,
 		QueryParams:        queryParams,
 		Headers:            headers,
+		QueryObjects:       queryObjects,
+		JSONRPCPath:        design.Design.JSONRPCPath,
+		JSONRPCParams:      strings.Join(rpcParams, ", "),
+		JSONRPCParamValues: rpcParamValues,
+		Topic:              action.Topic,
+		PayloadParam:       payloadParam,
+		PayloadInit:        payloadInit,
+		ValidatePayload:    hasPayloadValidation(action),
+		FieldMask:          action.FieldMask,
+	}
+	if payloadInit != "" {
+		data.ClientParams = strings.Join(append([]string{payloadParam}, params[1:]...), ", ")
+	}
+	if action.FieldMask != "" {
+		data.Fields = action.ResponseFields()
 	}
 	if action.WebSocket() {
 		return clientsWSTmpl.Execute(file, data)
@@ -531,7 +767,23 @@ func (g *Generator) generateActionClient(action *design.ActionDefinition, file *
 	if err := clientsTmpl.Execute(file, data); err != nil {
 		return err
 	}
-	return requestsTmpl.Execute(file, data)
+	if err := requestsTmpl.Execute(file, data); err != nil {
+		return err
+	}
+	if action.Topic != "" {
+		if err := publishTmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
+	if len(data.Fields) > 0 {
+		if err := fieldMaskTmpl.Execute(file, data); err != nil {
+			return err
+		}
+	}
+	if design.Design.JSONRPCPath == "" || action.PayloadMultipart {
+		return nil
+	}
+	return clientsJSONRPCTmpl.Execute(file, data)
 }
 
 // fileServerMethod returns the name of the client method for downloading assets served by the given
@@ -590,6 +842,7 @@ func (g *Generator) generateMediaTypes(pkgDir string, funcs template.FuncMap) (e
 	title := fmt.Sprintf("%s: Application Media Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("net/http"),
 		codegen.SimpleImport("time"),
@@ -644,13 +897,15 @@ func (g *Generator) generateUserTypes(pkgDir string) (err error) {
 	title := fmt.Sprintf("%s: Application User Types", g.API.Context())
 	imports := []*codegen.ImportSpec{
 		codegen.SimpleImport("github.com/goadesign/goa"),
+		codegen.SimpleImport("encoding/json"),
 		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("io"),
 		codegen.SimpleImport("time"),
 		codegen.SimpleImport("unicode/utf8"),
 		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
 	}
 	for _, v := range g.API.Types {
-		imports = codegen.AttributeImports(v.AttributeDefinition, imports, nil)
+		imports = codegen.AttributeImports(&design.AttributeDefinition{Type: v}, imports, nil)
 	}
 	if err = utWr.WriteHeader(title, g.Target, imports); err != nil {
 		return err
@@ -720,6 +975,12 @@ func multiComment(text string) string {
 // gotTypeRefExt computes the type reference for a type in a different package.
 func goTypeRefExt(t design.DataType, tabs int, pkg string) string {
 	ref := codegen.GoTypeRef(t, nil, tabs, false)
+	if ut, ok := t.(*design.UserTypeDefinition); ok {
+		if path, _ := codegen.TypePackage(ut); path != "" {
+			// Already qualified with the package defining the type.
+			return strings.TrimPrefix(ref, "*")
+		}
+	}
 	if strings.HasPrefix(ref, "*") {
 		return fmt.Sprintf("%s.%s", pkg, ref[1:])
 	}
@@ -791,6 +1052,9 @@ var arrayToStringTmpl *template.Template
 func toString(name, target string, att *design.AttributeDefinition) string {
 	switch actual := att.Type.(type) {
 	case design.Primitive:
+		if codegen.GoEnumTypeName(att) != "" {
+			name = fmt.Sprintf("%s(%s)", codegen.GoNativeType(actual), name)
+		}
 		switch actual.Kind() {
 		case design.IntegerKind:
 			return fmt.Sprintf("%s := strconv.Itoa(%s)", target, name)
@@ -801,6 +1065,10 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 		case design.StringKind:
 			return fmt.Sprintf("%s := %s", target, name)
 		case design.DateTimeKind:
+			if formats := att.TimeFormats(); len(formats) > 0 {
+				// Round(0) copies the value whether name is a pointer or not.
+				return fmt.Sprintf("%s := goa.FormatTime(%s.Round(0), %q, \"\")", target, strings.Replace(name, "*", "", -1), formats[0])
+			}
 			return fmt.Sprintf("%s := %s.Format(time.RFC3339)", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
 		case design.UUIDKind:
 			return fmt.Sprintf("%s := %s.String()", target, strings.Replace(name, "*", "", -1)) // remove pointer if present
@@ -828,13 +1096,31 @@ func toString(name, target string, att *design.AttributeDefinition) string {
 func defaultPath(action *design.ActionDefinition) string {
 	for _, r := range action.Routes {
 		candidate := r.FullPath()
-		if !strings.ContainsRune(candidate, ':') {
+		if len(design.ExtractWildcards(candidate)) == 0 {
 			return candidate
 		}
 	}
 	return ""
 }
 
+// signerKind returns the kind of the signer of the given security scheme used to generate the
+// context helpers of the client: "basic", "apiKey", "token", "hmac" or "cookie".
+func signerKind(scheme *design.SecuritySchemeDefinition) string {
+	switch scheme.Kind {
+	case design.JWTSecurityKind, design.OAuth2SecurityKind:
+		return "token"
+	case design.APIKeySecurityKind:
+		return "apiKey"
+	case design.BasicAuthSecurityKind:
+		return "basic"
+	case design.HMACSecurityKind:
+		return "hmac"
+	case design.CookieSecurityKind:
+		return "cookie"
+	}
+	return ""
+}
+
 // signerType returns the name of the client signer used for the defined security model on the Action
 func signerType(scheme *design.SecuritySchemeDefinition) string {
 	switch scheme.Kind {
@@ -846,6 +1132,10 @@ func signerType(scheme *design.SecuritySchemeDefinition) string {
 		return "goaclient.APIKeySigner"
 	case design.BasicAuthSecurityKind:
 		return "goaclient.BasicSigner"
+	case design.HMACSecurityKind:
+		return "goaclient.HMACSigner"
+	case design.CookieSecurityKind:
+		return "goaclient.CookieSigner"
 	}
 	return ""
 }
@@ -884,7 +1174,7 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	for n, q := range obj {
 		varName := codegen.Goify(n, false)
 		param := &paramData{
-			Name:      n,
+			Name:      q.AttributeKey(n),
 			VarName:   varName,
 			Attribute: q,
 		}
@@ -902,6 +1192,7 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 			if q.Type.IsArray() {
 				param.IsArray = true
 				param.ElemAttribute = q.Type.ToArray().ElemType
+				param.Separator = q.ParamSeparator()
 			}
 			param.MustToString = true
 			param.ValueName = varName
@@ -917,6 +1208,59 @@ func initParams(att *design.AttributeDefinition) ([]*paramData, []*paramData) {
 	return reqParamData, optParamData
 }
 
+// hasPayloadValidation returns true if the generated payload type of the action defines a
+// Validate method that the request builders call when the client validates the payloads.
+func hasPayloadValidation(action *design.ActionDefinition) bool {
+	p := action.Payload
+	if p == nil || action.PayloadMultipart || !(p.IsObject() || p.IsArray() || p.IsHash()) {
+		return false
+	}
+	context := "raw"
+	for _, t := range design.Design.Types {
+		if t.TypeName == p.TypeName {
+			if path, _ := codegen.TypePackage(t); path != "" {
+				return false
+			}
+			context = "type"
+			break
+		}
+	}
+	return codegen.NewValidator().Code(p.AttributeDefinition, false, false, false, "payload", context, 1, false) != ""
+}
+
+// flattenedPayload returns the name of the payload attribute taken by the generated client methods
+// in place of the payload when the action sets the "client:payload:flatten" metadata. The payload
+// must consist of a single primitive attribute, flattenedPayload returns an empty string
+// otherwise.
+func flattenedPayload(action *design.ActionDefinition) string {
+	if _, ok := action.Metadata["client:payload:flatten"]; !ok {
+		return ""
+	}
+	if action.Payload == nil || action.PayloadMultipart || action.WebSocket() || !action.Payload.Type.IsObject() {
+		return ""
+	}
+	obj := action.Payload.ToObject()
+	if len(obj) != 1 {
+		return ""
+	}
+	for n, att := range obj {
+		if att.Type.IsPrimitive() && att.Type.Kind() != design.FileKind {
+			return n
+		}
+	}
+	return ""
+}
+
+// flattenedField returns the name of the payload struct field holding the attribute returned by
+// flattenedPayload, an empty string if the action payload is not flattened.
+func flattenedField(action *design.ActionDefinition) string {
+	n := flattenedPayload(action)
+	if n == "" {
+		return ""
+	}
+	return codegen.GoifyAtt(action.Payload.ToObject()[n], n, true)
+}
+
 // paramData is the data structure holding the information needed to generate query params and
 // headers handling code.
 type paramData struct {
@@ -927,6 +1271,7 @@ type paramData struct {
 	ElemAttribute *design.AttributeDefinition
 	MustToString  bool
 	IsArray       bool
+	Separator     string
 	CheckNil      bool
 }
 
@@ -946,13 +1291,23 @@ const (
 
 	payloadTmpl = `// {{ gotypename .Payload nil 0 false }} is the {{ .Parent.Name }} {{ .Name }} action payload.
 type {{ gotypename .Payload nil 1 false }} {{ gotypedef .Payload 0 true false }}
-`
+{{ timeCodec .Payload.AttributeDefinition (gotypename .Payload nil 1 false) false false }}
+{{ goenumtypedefs .Payload }}{{ $validation := validationCode .Payload.AttributeDefinition false false false "payload" "raw" 1 false }}{{ if $validation }}
+// Validate runs the validation rules defined in the design.
+func (payload {{ gotyperef .Payload .Payload.AllRequired 0 false }}) Validate() (err error) {
+{{ $validation }}
+	return
+}
+{{ end }}`
 
 	typeDecodeTmpl = `{{ $typeName := typeName . }}{{ $funcName := printf "Decode%s" $typeName }}// {{ $funcName }} decodes the {{ $typeName }} instance encoded in resp body.
 func (c *Client) {{ $funcName }}(resp *http.Response) ({{ decodegotyperef . .AllRequired 0 false }}, error) {
 	var decoded {{ decodegotypename . .AllRequired 0 false }}
 	err := c.Decoder.Decode(&decoded, resp.Body, resp.Header.Get("Content-Type"))
-	return {{ if .IsObject }}&{{ end }}decoded, err
+{{ if emptyCollections }}	if err == nil {
+		goa.FillNilCollections(&decoded)
+	}
+{{ end }}	return {{ if .IsObject }}&{{ end }}decoded, err
 }
 `
 
@@ -969,7 +1324,9 @@ func {{ $funcName }}({{ pathParams .Route }}) string {
 	clientsTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{ $desc := .Description }}{{/*
 */}}{{ if $desc }}{{ multiComment $desc }}{{ else }}{{/*
 */}}// {{ $funcName }} makes a request to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource{{ end }}
-func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*http.Response, error) {
+func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .ClientParams }}, {{ .ClientParams }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType string{{ end }}) (*http.Response, error) {
+{{ with .PayloadInit }}	{{ . }}
+{{ end }}	ctx = goaclient.ContextWithEndpoint(ctx, {{ printf "%q" .ResourceName }}, {{ printf "%q" .Name }}, {{ if .HasPayload }}payload{{ else }}nil{{ end }})
 	req, err := c.New{{ $funcName }}Request(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }}{{ if and .HasPayload .HasMultiContent }}, contentType{{ end }})
 	if err != nil {
 		return nil, err
@@ -986,16 +1343,25 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 		scheme = "{{ .CanonicalScheme }}"
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
-{{ if .QueryParams }}	values := u.Query()
+{{ if or .QueryParams .QueryObjects }}	values := u.Query()
 {{ range .QueryParams }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}{{/*
 
 // ARRAY
-*/}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
+*/}}{{ if .IsArray }}{{ if .Separator }}{{ $vals := tempvar }}	{{ $vals }} := make([]string, len({{ .VarName }}))
+	for i, p := range {{ .VarName }} {
+{{ $tmp := tempvar }}		{{ toString "p" $tmp .ElemAttribute }}
+		{{ $vals }}[i] = {{ $tmp }}
+	}
+	if len({{ $vals }}) > 0 {
+		values.Set("{{ .Name }}", strings.Join({{ $vals }}, {{ printf "%q" .Separator }}))
+	}
+{{ else }}		for _, p := range {{ .VarName }} {
 {{ if .MustToString }}{{ $tmp := tempvar }}			{{ toString "p" $tmp .ElemAttribute }}
 			values.Add("{{ .Name }}", {{ $tmp }})
 {{ else }}			values.Add("{{ .Name }}", {{ .ValueName }})
-{{ end }}}{{/*
+{{ end }}}
+{{ end }}{{/*
 
 // NON STRING
 */}}{{ else if .MustToString }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
@@ -1005,7 +1371,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 // STRING
 */}}{{ else }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}
-{{ end }}{{ end }}	u.RawQuery = values.Encode()
+{{ end }}{{ end }}{{ range .QueryObjects }}	goa.EncodeDeepObject(values, {{ printf "%q" .Key }}, {{ .VarName }})
+{{ end }}	u.RawQuery = values.Encode()
 {{ end }}	url_ := u.String()
 	cfg, err := websocket.NewConfig(url_, url_)
 	if err != nil {
@@ -1013,8 +1380,91 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	}
 {{ range $header := .Headers }}{{ $tmp := tempvar }}	{{ toString $header.VarName $tmp $header.Attribute }}
 	cfg.Header["{{ $header.Name }}"] = []string{ {{ $tmp }} }
-{{ end }}	return websocket.DialConfig(cfg)
+{{ end }}	goaclient.ResumeStream(ctx, cfg)
+	return websocket.DialConfig(cfg)
+}
+
+// {{ $funcName }}Stream establishes a websocket connection to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource
+// and runs handle with it. It reconnects with a jittered backoff configured by opts until handle
+// returns nil or ctx is done, opts.Resume may set the token used by the server to resume the stream.
+func (c *Client) {{ $funcName }}Stream(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}, handle func(*websocket.Conn) error, opts *goaclient.StreamOptions) error {
+	return goaclient.Stream(ctx, opts, func(ctx context.Context) (*websocket.Conn, error) {
+		return c.{{ $funcName }}(ctx, path{{ if .ParamNames }}, {{ .ParamNames }}{{ end }})
+	}, handle)
 }
+`
+
+	clientsJSONRPCTmpl = `{{ $funcName := goify (printf "%s%sJSONRPC" .Name (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }} calls the {{ .ResourceName }}.{{ .Name }} method of the JSON-RPC endpoint.
+// The body of the returned response is the call result, it can be decoded with the same functions
+// as the responses of the {{ .Name }} action endpoint.
+func (c *Client) {{ $funcName }}(ctx context.Context{{ if .JSONRPCParams }}, {{ .JSONRPCParams }}{{ end }}) (*http.Response, error) {
+{{ with .PayloadInit }}	{{ . }}
+{{ end }}	ctx = goaclient.ContextWithEndpoint(ctx, {{ printf "%q" .ResourceName }}, {{ printf "%q" .Name }}, {{ if .HasPayload }}payload{{ else }}nil{{ end }})
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "{{ .CanonicalScheme }}"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: {{ printf "%q" .JSONRPCPath }}}
+	req, err := goaclient.NewJSONRPCRequest(ctx, u.String(), "{{ .ResourceName }}.{{ .Name }}", {{ if .JSONRPCParamValues }}map[string]interface{}{
+{{ range .JSONRPCParamValues }}		{{ printf "%q" .Name }}: {{ .VarName }},
+{{ end }}	}{{ else }}nil{{ end }})
+	if err != nil {
+		return nil, err
+	}
+{{ range .Headers }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
+{{ end }}{{ if .MustToString }}{{ $tmp := tempvar }}	{{ toString .ValueName $tmp .Attribute }}
+	req.Header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
+	req.Header.Set("{{ .Name }}", {{ .ValueName }})
+{{ end }}{{ if .CheckNil }}	}{{ end }}
+{{ end }}{{ if or .Signer .CSRF }}	// Route the request before signing it so that the signature covers its final URL
+	req, err = c.Route(req)
+	if err != nil {
+		return nil, err
+	}
+{{ end }}{{ if .Signer }}	signer := goaclient.ContextSigner(ctx, {{ printf "%q" .SecurityScheme }})
+	if signer == nil {
+		signer = c.{{ .Signer }}Signer
+	}
+	if signer != nil {
+		if err := signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}{{ if .CSRF }}	if c.CSRFSigner != nil {
+		if err := c.CSRFSigner.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}	resp, err := c.Client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return goaclient.JSONRPCResult(resp)
+}
+`
+
+	publishTmpl = `{{ $funcName := goify (printf "Publish%s%s" (title .Name) (title .ResourceName)) true }}{{/*
+*/}}// {{ $funcName }} publishes the {{ .Name }} event of the {{ .ResourceName }} resource to the {{ printf "%q" .Topic }} topic.
+func (c *Client) {{ $funcName }}(ctx context.Context, pub goa.Publisher{{ if .PayloadParam }}, {{ .PayloadParam }}{{ end }}) error {
+{{ with .PayloadInit }}	{{ . }}
+{{ end }}{{ if .HasPayload }}	var body bytes.Buffer
+	if err := c.Encoder.Encode(payload, &body, "*/*"); err != nil {
+		return fmt.Errorf("failed to encode body: %s", err)
+	}
+{{ end }}	return pub.Publish(ctx, &goa.Message{
+		Topic:  {{ printf "%q" .Topic }},
+		Header: map[string]string{"Content-Type": "{{ .DefaultContentType }}"},
+{{ if .HasPayload }}		Body:   body.Bytes(),
+{{ end }}	})
+}
+`
+
+	fieldMaskTmpl = `{{ $funcName := goify (printf "%s%s" .Name (title .ResourceName)) true }}{{/*
+*/}}// Names of the response fields that may be listed in the {{ .FieldMask }} param of {{ $funcName }}.
+const (
+{{ range .Fields }}	{{ $funcName }}Field{{ goify . true }} = {{ printf "%q" . }}
+{{ end }})
 `
 
 	fsTmpl = `// {{ .Name }} downloads {{ if .DirName }}{{ .DirName }}files with the given filename{{ else }}{{ .FileName }}{{ end }} and writes it to the file dest.
@@ -1056,12 +1506,17 @@ func (c * Client) {{ .Name }}(ctx context.Context, {{ if .DirName }}filename, {{
 	requestsTmpl = `{{ $funcName := goify (printf "New%s%sRequest" (title .Name) (title .ResourceName)) true }}{{/*
 */}}// {{ $funcName }} create the request corresponding to the {{ .Name }} action endpoint of the {{ .ResourceName }} resource.
 func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }}, {{ .Params }}{{ end }}{{ if .HasPayload }}{{ if .HasMultiContent }}, contentType string{{ end }}{{ end }}) (*http.Request, error) {
-{{ if .HasPayload }}	var body bytes.Buffer
+{{ if .ValidatePayload }}	if c.ValidatePayloads && payload != nil {
+		if err := payload.Validate(); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}{{ if .HasPayload }}	var body bytes.Buffer
 {{ if .PayloadMultipart }}	w := multipart.NewWriter(&body)
 {{ $o := .Payload.ToObject }}{{ range $name, $att := $o }}{{ if eq $att.Type.Kind 13 }}{{/*
 */}}	{
 		_, file := filepath.Split({{ printf "payload.%s" (goify $name true) }})
-		fw, err := w.CreateFormFile("{{ $name }}", file)
+		fw, err := w.CreateFormFile("{{ $att.AttributeKey $name }}", file)
 		if err != nil {
 			return nil, err
 		}
@@ -1075,7 +1530,7 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 		}
 	}
 {{ else }}	{
-		fw, err := w.CreateFormField("{{ $name }}")
+		fw, err := w.CreateFormField("{{ $att.AttributeKey $name }}")
 		if err != nil {
 			return nil, err
 		}
@@ -1090,6 +1545,7 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 {{ else }}{{ if .HasMultiContent }}	if contentType == "" {
 		contentType = "*/*" // Use default encoder
 	}
+{{ end }}{{ if emptyCollections }}	goa.FillNilCollections(&payload)
 {{ end }}	err := c.Encoder.Encode(payload, &body, {{ if .HasMultiContent }}contentType{{ else }}"*/*"{{ end }})
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode body: %s", err)
@@ -1099,16 +1555,24 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 		scheme = "{{ .CanonicalScheme }}"
 	}
 	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
-{{ if .QueryParams }}	values := u.Query()
+{{ if or .QueryParams .QueryObjects }}	values := u.Query()
 {{ range .QueryParams }}{{/*
 
 // ARRAY
-*/}}{{ if .IsArray }}		for _, p := range {{ .VarName }} {
+*/}}{{ if .IsArray }}{{ if .Separator }}{{ $vals := tempvar }}	{{ $vals }} := make([]string, len({{ .VarName }}))
+	for i, p := range {{ .VarName }} {
+{{ $tmp := tempvar }}		{{ toString "p" $tmp .ElemAttribute }}
+		{{ $vals }}[i] = {{ $tmp }}
+	}
+	if len({{ $vals }}) > 0 {
+		values.Set("{{ .Name }}", strings.Join({{ $vals }}, {{ printf "%q" .Separator }}))
+	}
+{{ else }}		for _, p := range {{ .VarName }} {
 {{ if .MustToString }}{{ $tmp := tempvar }}			{{ toString "p" $tmp .ElemAttribute }}
 			values.Add("{{ .Name }}", {{ $tmp }})
 {{ else }}			values.Add("{{ .Name }}", {{ .ValueName }})
 {{ end }}	 }
-{{/*
+{{ end }}{{/*
 
 // NON STRING
 */}}{{ else if .MustToString }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
@@ -1121,7 +1585,8 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 */}}{{ else }}{{ if .CheckNil }}	if {{ .VarName }} != nil {
 	{{ end }}	values.Set("{{ .Name }}", {{ .ValueName }})
 {{ if .CheckNil }}	}
-{{ end }}{{ end }}{{ end }}	u.RawQuery = values.Encode()
+{{ end }}{{ end }}{{ end }}{{ range .QueryObjects }}	goa.EncodeDeepObject(values, {{ printf "%q" .Key }}, {{ .VarName }})
+{{ end }}	u.RawQuery = values.Encode()
 {{ end }}{{ if .HasPayload }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), &body)
 {{ else }}	req, err := http.NewRequest({{ $route := index .Routes 0 }}"{{ $route.Verb }}", u.String(), nil)
 {{ end }}	if err != nil {
@@ -1140,8 +1605,22 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	header.Set("{{ .Name }}", {{ $tmp }}){{ else }}
 	header.Set("{{ .Name }}", {{ .ValueName }})
 {{ end }}{{ if .CheckNil }}	}{{ end }}
-{{ end }}{{ end }}{{ if .Signer }}	if c.{{ .Signer }}Signer != nil {
-		if err := c.{{ .Signer }}Signer.Sign(req); err != nil {
+{{ end }}{{ end }}{{ if or .Signer .CSRF }}	// Route the request before signing it so that the signature covers its final URL
+	req, err = c.Route(req)
+	if err != nil {
+		return nil, err
+	}
+{{ end }}{{ if .Signer }}	signer := goaclient.ContextSigner(ctx, {{ printf "%q" .SecurityScheme }})
+	if signer == nil {
+		signer = c.{{ .Signer }}Signer
+	}
+	if signer != nil {
+		if err := signer.Sign(req); err != nil {
+			return nil, err
+		}
+	}
+{{ end }}{{ if .CSRF }}	if c.CSRFSigner != nil {
+		if err := c.CSRFSigner.Sign(req); err != nil {
 			return nil, err
 		}
 	}
@@ -1152,18 +1631,24 @@ func (c *Client) {{ $funcName }}(ctx context.Context, path string{{ if .Params }
 	clientTmpl = `// Client is the {{ .API.Name }} service client.
 type Client struct {
 	*goaclient.Client{{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}
-	{{ goify $security.SchemeName true }}Signer goaclient.Signer{{ end }}{{ end }}
+	{{ goify $security.SchemeName true }}Signer goaclient.Signer{{ end }}{{ end }}{{ if .API.HasCSRF }}
+	CSRFSigner goaclient.Signer{{ end }}
 	Encoder *goa.HTTPEncoder
 	Decoder *goa.HTTPDecoder
+	// ValidatePayloads causes the request payloads to be validated against the design before
+	// they are sent, the validation errors are returned without making the requests.
+	ValidatePayloads bool
 }
 
-// New instantiates the client.
-func New(c goaclient.Doer) *Client {
+// New instantiates the client. The signers are given the fully built requests before they are
+// sent, in addition to any signer required by the action security scheme.
+func New(c goaclient.Doer, signers ...goaclient.Signer) *Client {
 	client := &Client{
 		Client: goaclient.New(c),
 		Encoder: goa.NewHTTPEncoder(),
 		Decoder: goa.NewHTTPDecoder(),
 	}
+	client.Signers = signers
 
 {{ if .Encoders }}	// Setup encoders and decoders
 {{ range .Encoders }}{{/*
@@ -1178,15 +1663,241 @@ func New(c goaclient.Doer) *Client {
 {{ end }}{{ end }}{{ range .Decoders }}{{ if .Default }}{{/*
 */}}	client.Decoder.Register({{ .PackageName }}.{{ .Function }}, "*/*")
 {{ end }}{{ end }}
+{{ end }}{{ if or .SensitiveNames .SensitiveTypes }}	// Setup sensitive data redaction of the request and response dumps
+{{ with .SensitiveNames }}	goa.RegisterSensitive({{ range $i, $n := . }}{{ if $i }}, {{ end }}{{ printf "%q" $n }}{{ end }})
+{{ end }}{{ range .SensitiveTypes }}{{ if .Fields }}{{/*
+*/}}	goa.RegisterSensitiveFields((*{{ .GoType }})(nil){{ range .Fields }}, {{ printf "%q" . }}{{ end }})
+{{ end }}{{ if .Identifier }}{{/*
+*/}}	goa.RegisterSensitiveMediaType({{ printf "%q" .Identifier }}, (*{{ .GoType }})(nil))
+{{ end }}{{ end }}
 {{ end }}	return client
 }
 
+// NewWithTransport instantiates the client using a dedicated HTTP transport configured with the
+// given options, e.g. goaclient.MaxIdleConnsPerHost or goaclient.RequestTimeout.
+func NewWithTransport(opts ...goaclient.TransportOption) *Client {
+	return New(goaclient.NewDoer(opts...))
+}
+
+// NewBalanced instantiates a client that distributes the requests across the given hosts, picker
+// selects the host of each request and defaults to round-robin. See goaclient.NewBalancer.
+func NewBalanced(c goaclient.Doer, picker goaclient.Picker, hosts []*goaclient.Host, signers ...goaclient.Signer) *Client {
+	return New(goaclient.NewBalancer(c, picker, hosts...), signers...)
+}
+
+// NewCached instantiates a client that caches the responses to GET requests in store according to
+// their Cache-Control, Expires and ETag headers. See goaclient.Cache.
+func NewCached(c goaclient.Doer, store goaclient.CacheStore, signers ...goaclient.Signer) *Client {
+	client := New(c, signers...)
+	client.Use(goaclient.Cache(store))
+	return client
+}
+
+// NewValidating instantiates a client that validates the request payloads before sending them,
+// the validation errors are the same the service would return. See Client.ValidatePayloads.
+func NewValidating(c goaclient.Doer, signers ...goaclient.Signer) *Client {
+	client := New(c, signers...)
+	client.ValidatePayloads = true
+	return client
+}
+
+// NewWithMetrics instantiates a client that records the number, the duration and the error class
+// of the calls made to each endpoint in meter. See goaclient.MetricsMiddleware.
+func NewWithMetrics(c goaclient.Doer, meter goaclient.Meter, signers ...goaclient.Signer) *Client {
+	client := New(c, signers...)
+	client.Use(goaclient.MetricsMiddleware(meter))
+	return client
+}
+{{ if .API.HasSPIFFESecurity }}
+// NewWithX509Source instantiates a client that authenticates with the X.509 SVIDs returned by
+// source using mutual TLS. serverID is the SPIFFE ID the certificate of the service must hold,
+// any ID of the trust bundle is accepted if empty. See goaclient.SPIFFE.
+func NewWithX509Source(source goaclient.X509Source, serverID string, opts ...goaclient.TransportOption) *Client {
+	return NewWithTransport(append(opts, goaclient.SPIFFE(source, serverID))...)
+}
+{{ end }}{{ if .API.Servers }}
+// Server describes a host serving the API.
+type Server struct {
+	// Description of the server.
+	Description string
+	// Host is the server hostname, it may contain variables of the form {name}.
+	Host string
+	// Scheme is the URL scheme used to send requests to the server.
+	Scheme string
+	// Variables lists the default values of the host variables indexed by name.
+	Variables map[string]string
+}
+
+// Servers lists the servers of the API indexed by name.
+var Servers = map[string]*Server{
+{{ range .API.Servers }}	{{ printf "%q" .Name }}: {
+{{ if .Description }}		Description: {{ printf "%q" .Description }},
+{{ end }}		Host: {{ printf "%q" .Host }},
+{{ if .Schemes }}		Scheme: {{ printf "%q" (index .Schemes 0) }},
+{{ end }}{{ if .Variables }}		Variables: map[string]string{
+{{ range .Variables }}			{{ printf "%q" .Name }}: {{ printf "%q" .Default }},
+{{ end }}		},
+{{ end }}	},
+{{ end }}}
+
+// NewForServer instantiates a client that sends the requests to the named server. vars overrides
+// the default values of the server host variables.
+func NewForServer(c goaclient.Doer, server string, vars map[string]string, signers ...goaclient.Signer) (*Client, error) {
+	s, ok := Servers[server]
+	if !ok {
+		return nil, fmt.Errorf("unknown server %q", server)
+	}
+	host := s.Host
+	for n, v := range s.Variables {
+		if val, ok := vars[n]; ok {
+			v = val
+		}
+		host = strings.Replace(host, "{"+n+"}", v, -1)
+	}
+	client := New(c, signers...)
+	client.Host = host
+	if s.Scheme != "" {
+		client.Scheme = s.Scheme
+	}
+	return client, nil
+}
+{{ end }}{{ if .Errors }}
+// The errors wrapped by the errors that DecodeError returns for the error responses of the API,
+// use errors.Is to test for them.
+var (
+{{ range .Errors }}	// {{ .VarName }} is the error returned for {{ printf "%q" .Name }} responses.
+	{{ .VarName }} = errors.New({{ printf "%q" .Name }})
+{{ end }})
+
+// errorsByStatus indexes the errors of the API responses by status code.
+var errorsByStatus = map[int]error{
+{{ range .Errors }}{{ if .Mapped }}	{{ .Status }}: {{ .VarName }},
+{{ end }}{{ end }}}
+{{ end }}
+// DecodeError returns a *goaclient.ResponseError if the status code of resp is 400 or more, nil
+// otherwise.{{ if .Errors }} The error wraps the error declared for the response, e.g.
+// errors.Is(err, {{ (index .Errors 0).VarName }}) holds for {{ printf "%q" (index .Errors 0).Name }} responses.{{ end }}
+func (c *Client) DecodeError(resp *http.Response) error {
+	return goaclient.DecodeError(resp, {{ if .Errors }}errorsByStatus{{ else }}nil{{ end }})
+}
+
 {{range $security := .API.SecuritySchemes }}{{ $signer := signerType $security }}{{ if $signer }}{{/*
 */}}{{ $name := printf "%sSigner" (goify $security.SchemeName true) }}{{/*
 */}}// Set{{ $name }} sets the request signer for the {{ $security.SchemeName }} security scheme.
 func (c *Client) Set{{ $name }}(signer goaclient.Signer) {
 	c.{{ $name }} = signer
 }
-{{ end }}{{ end }}
+{{ end }}{{ end }}{{ range $security := .API.SecuritySchemes }}{{ $kind := signerKind $security }}{{ if $kind }}{{/*
+*/}}{{ $name := goify $security.SchemeName true }}{{ $scheme := printf "%q" $security.SchemeName }}{{/*
+
+// BASIC
+*/}}{{ if eq $kind "basic" }}
+// ContextWith{{ $name }}Credentials returns a context that makes the requests sent with it use the
+// given credentials for the {{ $security.SchemeName }} security scheme instead of the client signer.
+func ContextWith{{ $name }}Credentials(ctx context.Context, username, password string) context.Context {
+	return goaclient.ContextWithSigner(ctx, {{ $scheme }}, &goaclient.BasicSigner{Username: username, Password: password})
+}
+
+// Context{{ $name }}Credentials returns the credentials of the {{ $security.SchemeName }} security scheme
+// set in the context with ContextWith{{ $name }}Credentials.
+func Context{{ $name }}Credentials(ctx context.Context) (username, password string, ok bool) {
+	s, ok := goaclient.ContextSigner(ctx, {{ $scheme }}).(*goaclient.BasicSigner)
+	if !ok {
+		return "", "", false
+	}
+	return s.Username, s.Password, true
+}
+{{/*
+
+// API KEY
+*/}}{{ else if eq $kind "apiKey" }}
+// ContextWith{{ $name }}Key returns a context that makes the requests sent with it use the given key
+// for the {{ $security.SchemeName }} security scheme instead of the client signer.
+func ContextWith{{ $name }}Key(ctx context.Context, key string) context.Context {
+	return goaclient.ContextWithSigner(ctx, {{ $scheme }}, &goaclient.APIKeySigner{
+		SignQuery: {{ if eq $security.In "query" }}true{{ else }}false{{ end }},
+		KeyName:   {{ printf "%q" $security.Name }},
+		KeyValue:  key,
+		Format:    {{ if eq $security.Name "Authorization" }}"Bearer %s"{{ else }}"%s"{{ end }},
+	})
+}
+
+// Context{{ $name }}Key returns the key of the {{ $security.SchemeName }} security scheme set in the
+// context with ContextWith{{ $name }}Key.
+func Context{{ $name }}Key(ctx context.Context) (string, bool) {
+	s, ok := goaclient.ContextSigner(ctx, {{ $scheme }}).(*goaclient.APIKeySigner)
+	if !ok {
+		return "", false
+	}
+	return s.KeyValue, true
+}
+{{/*
+
+// JWT AND OAUTH2
+*/}}{{ else if eq $kind "token" }}
+// ContextWith{{ $name }}TokenSource returns a context that makes the requests sent with it use the
+// tokens returned by source for the {{ $security.SchemeName }} security scheme instead of the client
+// signer. Use a *goaclient.StaticTokenSource to send a given token.
+func ContextWith{{ $name }}TokenSource(ctx context.Context, source goaclient.TokenSource) context.Context {
+	return goaclient.ContextWithSigner(ctx, {{ $scheme }}, &{{ signerType $security }}{TokenSource: source})
+}
+
+// Context{{ $name }}TokenSource returns the token source of the {{ $security.SchemeName }} security
+// scheme set in the context with ContextWith{{ $name }}TokenSource, nil if there is none.
+func Context{{ $name }}TokenSource(ctx context.Context) goaclient.TokenSource {
+	s, ok := goaclient.ContextSigner(ctx, {{ $scheme }}).(*{{ signerType $security }})
+	if !ok {
+		return nil
+	}
+	return s.TokenSource
+}
+{{/*
+
+// HMAC
+*/}}{{ else if eq $kind "hmac" }}
+// ContextWith{{ $name }}Secret returns a context that makes the requests sent with it be signed with
+// the given key for the {{ $security.SchemeName }} security scheme instead of the client signer.
+func ContextWith{{ $name }}Secret(ctx context.Context, keyID, secret string) context.Context {
+	return goaclient.ContextWithSigner(ctx, {{ $scheme }}, &goaclient.HMACSigner{
+		KeyID:  keyID,
+		Secret: secret,
+		Header: {{ printf "%q" $security.Name }},
+	})
+}
+
+// Context{{ $name }}Secret returns the key of the {{ $security.SchemeName }} security scheme set in
+// the context with ContextWith{{ $name }}Secret.
+func Context{{ $name }}Secret(ctx context.Context) (keyID, secret string, ok bool) {
+	s, ok := goaclient.ContextSigner(ctx, {{ $scheme }}).(*goaclient.HMACSigner)
+	if !ok {
+		return "", "", false
+	}
+	return s.KeyID, s.Secret, true
+}
+{{/*
+
+// COOKIE
+*/}}{{ else if eq $kind "cookie" }}
+// ContextWith{{ $name }}Cookie returns a context that makes the requests sent with it use the given
+// session cookie value for the {{ $security.SchemeName }} security scheme instead of the client signer.
+func ContextWith{{ $name }}Cookie(ctx context.Context, value string) context.Context {
+	return goaclient.ContextWithSigner(ctx, {{ $scheme }}, &goaclient.CookieSigner{Name: {{ printf "%q" $security.Name }}, Value: value})
+}
+
+// Context{{ $name }}Cookie returns the session cookie value of the {{ $security.SchemeName }} security
+// scheme set in the context with ContextWith{{ $name }}Cookie.
+func Context{{ $name }}Cookie(ctx context.Context) (string, bool) {
+	s, ok := goaclient.ContextSigner(ctx, {{ $scheme }}).(*goaclient.CookieSigner)
+	if !ok {
+		return "", false
+	}
+	return s.Value, true
+}
+{{ end }}{{ end }}{{ end }}{{ if .API.HasCSRF }}
+// SetCSRFSigner sets the request signer that echoes the CSRF token issued by the service.
+func (c *Client) SetCSRFSigner(signer goaclient.Signer) {
+	c.CSRFSigner = signer
+}
+{{ end }}
 `
 )
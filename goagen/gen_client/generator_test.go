@@ -130,6 +130,32 @@ var _ = Describe("Generate", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(string(content)).Should(HavePrefix(userTypesHeader))
 		})
+
+		It("records the endpoint in the request context", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(`ctx = goaclient.ContextWithEndpoint(ctx, "foo", "show", nil)`))
+		})
+
+		It("generates client usage examples", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "examples_test.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("package client_test"))
+			Ω(string(content)).Should(ContainSubstring("func ExampleClient_ShowFoo() {"))
+			Ω(string(content)).Should(ContainSubstring("resp, err := c.ShowFoo(context.Background(), client.ShowFooPath())"))
+		})
+
+		It("generates the load testing tool", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "load", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("package main"))
+			Ω(string(content)).Should(ContainSubstring(`{Name: "foo show", Call: func(ctx context.Context) (*http.Response, error) {`))
+			Ω(string(content)).Should(ContainSubstring("return c.ShowFoo(ctx, client.ShowFooPath())"))
+			Ω(string(content)).Should(ContainSubstring("goaclient.Load(context.Background(), opts, targets...)"))
+		})
 	})
 
 	Context("with a required UUID header", func() {
@@ -166,7 +192,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates header initialization code that compiles", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -212,7 +238,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates path initialization code that uses all defined URL params in proper format", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -268,7 +294,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates param initialization code that uses the param name given in the design", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			content := string(c)
@@ -295,11 +321,357 @@ var _ = Describe("Generate", func() {
 
 			It("should not return an error", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(5)) // 9, minus 4 entries for tool paths
+				Ω(files).Should(HaveLen(6)) // 12, minus 6 entries for tool paths
+			})
+		})
+	})
+
+	Context("with a pipe delimited array querystring param", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			o := design.Object{
+				"ids": &design.AttributeDefinition{
+					Type:     &design.Array{ElemType: &design.AttributeDefinition{Type: design.Integer}},
+					Metadata: dslengine.MetadataDefinition{"param:style": {design.PipeDelimitedStyle}},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:        "list",
+								Routes:      []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+								QueryParams: &design.AttributeDefinition{Type: o},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+		})
+
+		It("joins the param values", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`values.Set("ids", strings.Join(tmp2, "|"))`))
+		})
+	})
+
+	Context("with a query object", func() {
+		BeforeEach(func() {
+			filter := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"color": {Type: design.String}},
+				},
+				TypeName: "BottleFilter",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Types:    map[string]*design.UserTypeDefinition{"BottleFilter": filter},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"list": {
+								Name:   "list",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			listAct := fooRes.Actions["list"]
+			listAct.Parent = fooRes
+			listAct.Routes[0].Parent = listAct
+			listAct.QueryObjects = []*design.QueryObjectDefinition{
+				{Name: "filter", Type: filter, Parent: listAct},
+			}
+		})
+
+		It("encodes the object in the query string", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ListFoo(ctx context.Context, path string, filter *BottleFilter) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring(`goa.EncodeDeepObject(values, "filter", filter)`))
+		})
+	})
+
+	Context("with a JSON-RPC endpoint", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			params := &design.AttributeDefinition{
+				Type: design.Object{
+					"id":    &design.AttributeDefinition{Type: design.Integer},
+					"limit": &design.AttributeDefinition{Type: design.Integer},
+				},
+			}
+			design.Design = &design.APIDefinition{
+				Name:        "testapi",
+				Consumes:    design.DefaultEncoders,
+				JSONRPCPath: "/rpc",
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/:id"}},
+								Params: params,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates a method calling the JSON-RPC endpoint", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFooJSONRPC(ctx context.Context, id int, limit *int) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring(`req, err := goaclient.NewJSONRPCRequest(ctx, u.String(), "foo.show", map[string]interface{}{`))
+			Ω(content).Should(ContainSubstring(`Path: "/rpc"`))
+			Ω(content).Should(ContainSubstring("return goaclient.JSONRPCResult(resp)"))
+		})
+	})
+
+	Context("with an event", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "CreatedPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"created": {
+								Name:    "created",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/created"}},
+								Payload: payload,
+								Topic:   "foo.created",
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createdAct := fooRes.Actions["created"]
+			createdAct.Parent = fooRes
+			createdAct.Routes[0].Parent = createdAct
+		})
+
+		It("generates a method publishing the event", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) PublishCreatedFoo(ctx context.Context, pub goa.Publisher, payload *CreatedPayload) error {"))
+			Ω(content).Should(ContainSubstring(`Topic:  "foo.created",`))
+			Ω(content).Should(ContainSubstring("return pub.Publish(ctx, &goa.Message{"))
+		})
+	})
+
+	Context("with a flattened payload", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type:       design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+				},
+				TypeName: "RenamePayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"rename": {
+								Name:     "rename",
+								Routes:   []*design.RouteDefinition{{Verb: "POST", Path: "/rename"}},
+								Payload:  payload,
+								Metadata: dslengine.MetadataDefinition{"client:payload:flatten": nil},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			renameAct := fooRes.Actions["rename"]
+			renameAct.Parent = fooRes
+			renameAct.Routes[0].Parent = renameAct
+		})
+
+		It("generates a client method taking the payload attribute", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) RenameFoo(ctx context.Context, path string, name string, contentType string) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("payload := &RenamePayload{Name: name}"))
+			Ω(content).Should(ContainSubstring("func (c *Client) NewRenameFooRequest(ctx context.Context, path string, payload *RenamePayload, contentType string) (*http.Request, error) {"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "tool", "cli", "commands.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(c)).Should(ContainSubstring("c.RenameFoo(ctx, path, payload.Name, cmd.ContentType)"))
+		})
+	})
+
+	Context("with a payload with validations", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type:       design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+					Validation: &dslengine.ValidationDefinition{Required: []string{"name"}},
+				},
+				TypeName: "CreatePayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:    "create",
+								Routes:  []*design.RouteDefinition{{Verb: "POST", Path: "/"}},
+								Payload: payload,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("validates the payload before encoding it when the client validates payloads", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (payload *CreatePayload) Validate() (err error) {"))
+			Ω(content).Should(ContainSubstring(`goa.MissingAttributeError(` + "`raw`" + `, "name")`))
+			Ω(content).Should(ContainSubstring("if c.ValidatePayloads && payload != nil {"))
+			c, err = ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content = string(c)
+			Ω(content).Should(ContainSubstring("ValidatePayloads bool"))
+			Ω(content).Should(ContainSubstring("func NewValidating(c goaclient.Doer, signers ...goaclient.Signer) *Client {"))
+		})
+
+		Context("with nil collections encoded as empty", func() {
+			BeforeEach(func() {
+				design.Design.NilCollections = design.NilCollectionsEmpty
+			})
+
+			It("fills the nil collections of the payload before encoding it", func() {
+				Ω(genErr).Should(BeNil())
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(c)).Should(ContainSubstring(`	goa.FillNilCollections(&payload)
+	err := c.Encoder.Encode(payload, &body, contentType)`))
 			})
 		})
 	})
 
+	Context("with a field mask", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"id":   &design.AttributeDefinition{Type: design.Integer},
+							"name": &design.AttributeDefinition{Type: design.String},
+						},
+					},
+					TypeName: "Bottle",
+				},
+				Identifier: "application/vnd.bottle",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {AttributeDefinition: mt.AttributeDefinition, Name: "default", Parent: mt},
+			}
+			design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.CanonicalIdentifier(mt.Identifier): mt},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:      "show",
+								Routes:    []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+								FieldMask: "fields",
+								Params: &design.AttributeDefinition{
+									Type: design.Object{
+										"fields": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+									},
+								},
+								QueryParams: &design.AttributeDefinition{
+									Type: design.Object{
+										"fields": &design.AttributeDefinition{Type: &design.Array{ElemType: &design.AttributeDefinition{Type: design.String}}},
+									},
+								},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {Name: "OK", Status: 200, MediaType: mt.Identifier},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates the names of the fields", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFoo(ctx context.Context, path string, fields []string) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("// Names of the response fields that may be listed in the fields param of ShowFoo."))
+			Ω(content).Should(ContainSubstring(`ShowFooFieldID   = "id"`))
+			Ω(content).Should(ContainSubstring(`ShowFooFieldName = "name"`))
+		})
+	})
+
 	Context("with an action using websocket", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
@@ -365,6 +737,17 @@ var _ = Describe("Generate", func() {
 `))
 		})
 
+		It("generates the reconnecting stream method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`	goaclient.ResumeStream(ctx, cfg)
+	return websocket.DialConfig(cfg)`))
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowFooStream(ctx context.Context, path string, fieldsBar []string, fieldsBat *time.Time, fieldsBaz []int, fieldsFoo *string, handle func(*websocket.Conn) error, opts *goaclient.StreamOptions) error {"))
+			Ω(content).Should(ContainSubstring("return c.ShowFoo(ctx, path, fieldsBar, fieldsBat, fieldsBaz, fieldsFoo)"))
+		})
+
 		Context("with --notool", func() {
 			BeforeEach(func() {
 				os.Args = append(os.Args, "--notool")
@@ -412,7 +795,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates Path function with unique names", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("func ShowFooPath("))
@@ -435,7 +818,7 @@ var _ = Describe("Generate", func() {
 
 			It("generates a Download function", func() {
 				Ω(genErr).Should(BeNil())
-				Ω(files).Should(HaveLen(9))
+				Ω(files).Should(HaveLen(12))
 				content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 				Ω(err).ShouldNot(HaveOccurred())
 				Ω(content).Should(ContainSubstring("func (c *Client) DownloadSwaggerJSON("))
@@ -494,7 +877,7 @@ var _ = Describe("Generate", func() {
 
 		It("generates the correct client Fields", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("JWT1Signer goaclient.Signer"))
@@ -503,16 +886,657 @@ var _ = Describe("Generate", func() {
 
 		It("generates the Signer.Sign call from Action", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(content).Should(ContainSubstring(`		if err := c.JWT1Signer.Sign(req); err != nil {
+			Ω(content).Should(ContainSubstring(`	signer := goaclient.ContextSigner(ctx, "jwt-1")
+	if signer == nil {
+		signer = c.JWT1Signer
+	}
+	if signer != nil {
+		if err := signer.Sign(req); err != nil {
 			return nil, err
-		}`))
+		}
+	}`))
+		})
+
+		It("routes the request before signing it", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`	req, err = c.Route(req)
+	if err != nil {
+		return nil, err
+	}
+	signer := goaclient.ContextSigner(ctx, "jwt-1")`))
+		})
+
+		It("generates the context helpers of the security scheme", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`func ContextWithJWT1TokenSource(ctx context.Context, source goaclient.TokenSource) context.Context {
+	return goaclient.ContextWithSigner(ctx, "jwt-1", &goaclient.JWTSigner{TokenSource: source})
+}`))
+			Ω(content).Should(ContainSubstring("func ContextJWT1TokenSource(ctx context.Context) goaclient.TokenSource {"))
 		})
 	})
 
-	Context("with an action with a user type payload", func() {
+	Context("with an action with HMAC security configured", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName: "hmac",
+				Kind:       design.HMACSecurityKind,
+				Type:       "apiKey",
+				In:         "header",
+				Name:       "Authorization",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Security: &design.SecurityDefinition{
+									Scheme: securitySchemeDef,
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates a client constructor that accepts signers", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func New(c goaclient.Doer, signers ...goaclient.Signer) *Client {"))
+			Ω(content).Should(ContainSubstring("client.Signers = signers"))
+			Ω(content).Should(ContainSubstring("HmacSigner goaclient.Signer"))
+		})
+
+		It("generates the context helpers of the HMAC security scheme", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func ContextWithHmacSecret(ctx context.Context, keyID, secret string) context.Context {"))
+			Ω(content).Should(ContainSubstring("func ContextHmacSecret(ctx context.Context) (keyID, secret string, ok bool) {"))
+		})
+
+		It("generates client constructors that configure the transport", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func NewWithTransport(opts ...goaclient.TransportOption) *Client {"))
+			Ω(content).Should(ContainSubstring("return New(goaclient.NewDoer(opts...))"))
+			Ω(content).Should(ContainSubstring("func NewBalanced(c goaclient.Doer, picker goaclient.Picker, hosts []*goaclient.Host, signers ...goaclient.Signer) *Client {"))
+			Ω(content).Should(ContainSubstring("func NewCached(c goaclient.Doer, store goaclient.CacheStore, signers ...goaclient.Signer) *Client {"))
+			Ω(content).Should(ContainSubstring("client.Use(goaclient.Cache(store))"))
+			Ω(content).Should(ContainSubstring("func NewWithMetrics(c goaclient.Doer, meter goaclient.Meter, signers ...goaclient.Signer) *Client {"))
+			Ω(content).Should(ContainSubstring("client.Use(goaclient.MetricsMiddleware(meter))"))
+		})
+
+		It("generates the HMAC signer in the CLI", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("return &goaclient.HMACSigner{"))
+			Ω(content).Should(ContainSubstring(`"key-id"`))
+		})
+	})
+
+	Context("with an action with cookie security configured", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName: "session",
+				Kind:       design.CookieSecurityKind,
+				Type:       "apiKey",
+				In:         "cookie",
+				Name:       "sid",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name: "show",
+								Routes: []*design.RouteDefinition{
+									{
+										Verb: "GET",
+										Path: "",
+									},
+								},
+								Security: &design.SecurityDefinition{
+									Scheme: securitySchemeDef,
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates the context helpers of the cookie security scheme", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("SessionSigner goaclient.Signer"))
+			Ω(content).Should(ContainSubstring(`&goaclient.CookieSigner{Name: "sid", Value: value}`))
+			Ω(content).Should(ContainSubstring("func ContextSessionCookie(ctx context.Context) (string, bool) {"))
+		})
+
+		It("generates the cookie signer in the CLI", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "tool", "testapi-cli", "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("return &goaclient.CookieSigner{"))
+			Ω(content).Should(ContainSubstring(`"session"`))
+		})
+	})
+
+	Context("with an action with SPIFFE security configured", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			securitySchemeDef := &design.SecuritySchemeDefinition{
+				SchemeName:  "workload",
+				Kind:        design.SPIFFESecurityKind,
+				Type:        "apiKey",
+				TrustDomain: "example.org",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{
+					securitySchemeDef,
+				},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:     "show",
+								Routes:   []*design.RouteDefinition{{Verb: "GET", Path: ""}},
+								Security: &design.SecurityDefinition{Scheme: securitySchemeDef},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates the constructor using the X.509 SVID source", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring("func NewWithX509Source(source goaclient.X509Source, serverID string, opts ...goaclient.TransportOption) *Client {"))
+			Ω(content).Should(ContainSubstring("goaclient.SPIFFE(source, serverID)"))
+			Ω(content).ShouldNot(ContainSubstring("WorkloadSigner"))
+		})
+	})
+
+	Context("with servers", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Servers: []*design.ServerDefinition{
+					{Name: "dev", Host: "localhost:8080"},
+					{
+						Name:      "prod",
+						Host:      "{region}.example.com",
+						Schemes:   []string{"https"},
+						Variables: []*design.ServerVariableDefinition{{Name: "region", Default: "us"}},
+					},
+				},
+			}
+		})
+
+		It("generates the servers and a constructor selecting one", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`"dev": {`))
+			Ω(content).Should(ContainSubstring(`Host:   "{region}.example.com",`))
+			Ω(content).Should(ContainSubstring(`"region": "us",`))
+			Ω(content).Should(ContainSubstring("func NewForServer(c goaclient.Doer, server string, vars map[string]string, signers ...goaclient.Signer) (*Client, error) {"))
+		})
+	})
+
+	Context("with a type with sensitive attributes", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			credentials := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{
+						"login":    &design.AttributeDefinition{Type: design.String},
+						"password": &design.AttributeDefinition{Type: design.String, Sensitive: true},
+					},
+				},
+				TypeName: "Credentials",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Types:    map[string]*design.UserTypeDefinition{"Credentials": credentials},
+			}
+		})
+
+		It("registers the sensitive attributes with the type that defines them", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(content).Should(ContainSubstring(`goa.RegisterSensitiveFields((*Credentials)(nil), "password")`))
+			Ω(content).ShouldNot(ContainSubstring("goa.RegisterSensitive("))
+		})
+	})
+
+	Context("with error responses", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/foos"}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK":       {Name: "OK", Status: 200},
+									"NotFound": {Name: "NotFound", Status: 404},
+									"Gone":     {Name: "Gone", Status: 410},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			showAct := fooRes.Actions["show"]
+			showAct.Parent = fooRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates sentinel errors for the error responses", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "client.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring(`ErrGone = errors.New("Gone")`))
+			Ω(content).Should(ContainSubstring(`ErrNotFound = errors.New("NotFound")`))
+			Ω(content).ShouldNot(ContainSubstring("ErrOK"))
+			Ω(content).Should(ContainSubstring("404: ErrNotFound,"))
+			Ω(content).Should(ContainSubstring("410: ErrGone,"))
+			Ω(content).Should(ContainSubstring("return goaclient.DecodeError(resp, errorsByStatus)"))
+		})
+	})
+
+	Context("with an async action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				JobsPath:   "/jobs",
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.JobMediaIdentifier: design.JobMedia},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"export": {
+								Name:   "export",
+								Async:  true,
+								Routes: []*design.RouteDefinition{{Verb: "POST", Path: "/exports"}},
+								Responses: map[string]*design.ResponseDefinition{
+									design.Accepted: {Name: design.Accepted, Status: 202, MediaType: design.JobMediaIdentifier},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			exportAct := fooRes.Actions["export"]
+			exportAct.Parent = fooRes
+			exportAct.Routes[0].Parent = exportAct
+		})
+
+		It("generates the job polling methods", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "jobs.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ShowJob(ctx context.Context, path string) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring("func (c *Client) WaitJob(ctx context.Context, path string, opts *goaclient.PollOptions) (*GoaJob, error) {"))
+			Ω(content).Should(ContainSubstring("job, err = c.DecodeGoaJob(resp)"))
+		})
+	})
+
+	Context("with a resumable action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:      "create",
+								Resumable: true,
+								Routes:    []*design.RouteDefinition{{Verb: "POST", Path: "/uploads"}},
+								Responses: map[string]*design.ResponseDefinition{
+									design.Created: {Name: design.Created, Status: 201},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+		})
+
+		It("generates the resumable upload method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "uploads.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) ResumeUpload(ctx context.Context, path string, r io.ReaderAt, size int64, opts *goaclient.UploadOptions) error {"))
+			Ω(content).Should(ContainSubstring("return goaclient.Upload(ctx, c.Client, u.String(), r, size, opts)"))
+		})
+	})
+
+	Context("with a recorded action", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			scheme := &design.SecuritySchemeDefinition{SchemeName: "jwt", Kind: design.JWTSecurityKind}
+			design.Design = &design.APIDefinition{
+				Name:            "testapi",
+				Consumes:        design.DefaultEncoders,
+				SecuritySchemes: []*design.SecuritySchemeDefinition{scheme},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"update": {
+								Name:     "update",
+								Recorded: true,
+								Security: &design.SecurityDefinition{Scheme: scheme},
+								Routes:   []*design.RouteDefinition{{Verb: "PATCH", Path: "/foos"}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			updateAct := fooRes.Actions["update"]
+			updateAct.Parent = fooRes
+			updateAct.Routes[0].Parent = updateAct
+		})
+
+		It("generates the replay method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "replay.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) Replay(ctx context.Context, rec *middleware.Recording) (*http.Response, error) {"))
+			Ω(content).Should(ContainSubstring(`case "foo#update":`))
+			Ω(content).Should(ContainSubstring("signer = c.JWTSigner"))
+		})
+	})
+
+	Context("with a multipart/mixed response", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"batch": {
+								Name:   "batch",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/foos/batch"}},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			batchAct := fooRes.Actions["batch"]
+			batchAct.Parent = fooRes
+			batchAct.Routes[0].Parent = batchAct
+			resp := &design.ResponseDefinition{Name: "OK", Status: 200, MediaType: "multipart/mixed", Parent: batchAct}
+			resp.Parts = []*design.PartDefinition{
+				{Name: "total", Type: design.Integer, Parent: resp},
+				{Name: "label", MediaType: "image/png", Parent: resp},
+			}
+			batchAct.Responses = map[string]*design.ResponseDefinition{"OK": resp}
+		})
+
+		It("generates the parts iterator", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "parts.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeBatchFooOKParts(resp *http.Response) (*BatchFooOKParts, error) {"))
+			Ω(content).Should(ContainSubstring("Total int"))
+			Ω(content).Should(ContainSubstring("Label []byte"))
+			Ω(content).Should(ContainSubstring("if err := p.Decode(&part.Total); err != nil {"))
+			Ω(content).Should(ContainSubstring("part.Label = b"))
+		})
+	})
+
+	Context("with a response with trailers", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"checksum": &design.AttributeDefinition{Type: design.String},
+							"count":    &design.AttributeDefinition{Type: design.Integer},
+						},
+					},
+					TypeName: "Export",
+				},
+				Identifier: "application/vnd.export",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {AttributeDefinition: mt.AttributeDefinition, Name: "default", Parent: mt},
+			}
+			design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.CanonicalIdentifier(mt.Identifier): mt},
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"export": {
+								Name:   "export",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/export"}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK": {
+										Name:      "OK",
+										Status:    200,
+										MediaType: mt.Identifier,
+										Trailers:  map[string]string{"X-Checksum": "checksum", "X-Record-Count": "count"},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			exportAct := fooRes.Actions["export"]
+			exportAct.Parent = fooRes
+			exportAct.Routes[0].Parent = exportAct
+		})
+
+		It("generates the functions that read the trailers", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "trailers.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func ReadExportFooOKTrailers(resp *http.Response, r *Export) error {"))
+			Ω(content).Should(ContainSubstring(`if raw := resp.Trailer.Get("X-Checksum"); raw != "" {`))
+			Ω(content).Should(ContainSubstring("r.Checksum = &raw"))
+			Ω(content).Should(ContainSubstring("if x, err2 := strconv.Atoi(raw); err2 != nil {"))
+			Ω(content).Should(ContainSubstring("r.Count = &x"))
+		})
+	})
+
+	Context("with tagged responses", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			mt := &design.MediaTypeDefinition{
+				UserTypeDefinition: &design.UserTypeDefinition{
+					AttributeDefinition: &design.AttributeDefinition{
+						Type: design.Object{
+							"state": &design.AttributeDefinition{Type: design.String},
+						},
+					},
+					TypeName: "Job",
+				},
+				Identifier: "application/vnd.job",
+			}
+			mt.Views = map[string]*design.ViewDefinition{
+				"default": {AttributeDefinition: mt.AttributeDefinition, Name: "default", Parent: mt},
+			}
+			design.ProjectedMediaTypes = make(map[string]*design.MediaTypeDefinition)
+			design.Design = &design.APIDefinition{
+				Name:       "testapi",
+				Consumes:   design.DefaultEncoders,
+				MediaTypes: map[string]*design.MediaTypeDefinition{design.CanonicalIdentifier(mt.Identifier): mt},
+				Resources: map[string]*design.ResourceDefinition{
+					"job": {
+						Name: "job",
+						Actions: map[string]*design.ActionDefinition{
+							"show": {
+								Name:   "show",
+								Routes: []*design.RouteDefinition{{Verb: "GET", Path: "/job"}},
+								Responses: map[string]*design.ResponseDefinition{
+									"OK":        {Name: "OK", Status: 200, MediaType: mt.Identifier, TagAttribute: "state", TagValue: "done"},
+									"Accepted":  {Name: "Accepted", Status: 202, MediaType: mt.Identifier, TagAttribute: "state", TagValue: "pending"},
+									"NoContent": {Name: "NoContent", Status: 204},
+									"NotFound":  {Name: "NotFound", Status: 404},
+								},
+							},
+						},
+					},
+				},
+			}
+			jobRes := design.Design.Resources["job"]
+			showAct := jobRes.Actions["show"]
+			showAct.Parent = jobRes
+			showAct.Routes[0].Parent = showAct
+		})
+
+		It("generates the method that decodes the responses by status code", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "responses.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func (c *Client) DecodeShowJobResponse(resp *http.Response) (*Job, error) {"))
+			Ω(content).Should(ContainSubstring("\tcase 200, 202: // OK, Accepted\n\t\treturn c.DecodeJob(resp)\n"))
+			Ω(content).Should(ContainSubstring("\tcase 204: // NoContent\n\t\treturn nil, nil\n"))
+			Ω(content).ShouldNot(ContainSubstring("case 404"))
+			Ω(content).Should(ContainSubstring("if err := c.DecodeError(resp); err != nil {"))
+		})
+	})
+
+	Context("with an action with a batch endpoint", func() {
+		BeforeEach(func() {
+			codegen.TempCount = 0
+			payload := &design.UserTypeDefinition{
+				AttributeDefinition: &design.AttributeDefinition{
+					Type: design.Object{"name": &design.AttributeDefinition{Type: design.String}},
+				},
+				TypeName: "CreateFooPayload",
+			}
+			design.Design = &design.APIDefinition{
+				Name:     "testapi",
+				Consumes: design.DefaultEncoders,
+				Resources: map[string]*design.ResourceDefinition{
+					"foo": {
+						Name: "foo",
+						Actions: map[string]*design.ActionDefinition{
+							"create": {
+								Name:       "create",
+								Routes:     []*design.RouteDefinition{{Verb: "POST", Path: "/accounts/:accountID/foos"}},
+								BatchRoute: &design.RouteDefinition{Verb: "POST", Path: "/accounts/:accountID/foos/batch"},
+								Params: &design.AttributeDefinition{Type: design.Object{
+									"accountID": &design.AttributeDefinition{Type: design.Integer},
+								}},
+								Payload: payload,
+							},
+						},
+					},
+				},
+			}
+			fooRes := design.Design.Resources["foo"]
+			createAct := fooRes.Actions["create"]
+			createAct.Parent = fooRes
+			createAct.Routes[0].Parent = createAct
+			createAct.BatchRoute.Parent = createAct
+		})
+
+		It("generates the batch client method", func() {
+			Ω(genErr).Should(BeNil())
+			c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "foo.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			content := string(c)
+			Ω(content).Should(ContainSubstring("func CreateFooBatchPath(accountID int) string {"))
+			Ω(content).Should(ContainSubstring(`return fmt.Sprintf("/accounts/%s/foos/batch", param0)`))
+			Ω(content).Should(ContainSubstring("func (c *Client) CreateFooBatch(ctx context.Context, path string, payloads []*CreateFooPayload) (*http.Response, error) {"))
+		})
+	})
+
+	Context("with an action with a user type payload", func() {
 		BeforeEach(func() {
 			codegen.TempCount = 0
 			testType := &design.UserTypeDefinition{
@@ -559,11 +1583,31 @@ var _ = Describe("Generate", func() {
 
 		It("generates the user type imports", func() {
 			Ω(genErr).Should(BeNil())
-			Ω(files).Should(HaveLen(9))
+			Ω(files).Should(HaveLen(12))
 			content, err := ioutil.ReadFile(filepath.Join(outDir, "client", "user_types.go"))
 			Ω(err).ShouldNot(HaveOccurred())
 			Ω(content).Should(ContainSubstring("uuid \"github.com/goadesign/goa/uuid\""))
 		})
+
+		Context("with --app-pkg", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--app-pkg=app")
+			})
+
+			It("generates the round-trip tests", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(HaveLen(13))
+				c, err := ioutil.ReadFile(filepath.Join(outDir, "client", "roundtrip_test.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				content := string(c)
+				Ω(content).Should(ContainSubstring("package client_test"))
+				Ω(content).Should(ContainSubstring(`"` + filepath.Base(outDir) + `/app"`))
+				Ω(content).Should(ContainSubstring("func TestRoundTripTestType(t *testing.T) {"))
+				Ω(content).Should(ContainSubstring("return new(client.TestType)"))
+				Ω(content).Should(ContainSubstring("roundTrip(t, s, newClient, new(app.TestType))"))
+				Ω(content).Should(ContainSubstring("samples := []string{"))
+			})
+		})
 	})
 })
 
@@ -577,6 +1621,7 @@ var _ = Describe("NewGenerator", func() {
 		toolDirName string
 		tool        string
 		noTool      bool
+		appPkg      string
 	}{
 		api: &design.APIDefinition{
 			Name: "test api",
@@ -585,6 +1630,7 @@ var _ = Describe("NewGenerator", func() {
 		toolDirName: "test_dir",
 		tool:        "mycli",
 		noTool:      true,
+		appPkg:      "github.com/acme/app",
 	}
 
 	Context("with options all options set", func() {
@@ -597,6 +1643,7 @@ var _ = Describe("NewGenerator", func() {
 				genclient.ToolDirName(args.toolDirName),
 				genclient.Tool(args.tool),
 				genclient.NoTool(args.noTool),
+				genclient.AppPkg(args.appPkg),
 			)
 		})
 
@@ -608,6 +1655,7 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.ToolDirName).Should(Equal(args.toolDirName))
 			Ω(generator.Tool).Should(Equal(args.tool))
 			Ω(generator.NoTool).Should(Equal(args.noTool))
+			Ω(generator.AppPkg).Should(Equal(args.appPkg))
 		})
 
 	})
@@ -0,0 +1,44 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_app"
+)
+
+// generateHeaderGroups generates the functions that map the header groups to their types so that
+// clients can set the request headers and read the response headers of a group. Nothing is
+// generated if the API defines no header group.
+func (g *Generator) generateHeaderGroups(pkgDir string) (err error) {
+	groups := g.API.HeaderGroups()
+	if len(groups) == 0 {
+		return nil
+	}
+
+	groupsFile := filepath.Join(pkgDir, "header_groups.go")
+	var groupsWr *genapp.HeaderGroupsWriter
+	groupsWr, err = genapp.NewHeaderGroupsWriter(groupsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		groupsWr.Close()
+		if err == nil {
+			err = groupsWr.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	title := fmt.Sprintf("%s: Header Groups", g.API.Context())
+	if err = groupsWr.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, groupsFile)
+	return groupsWr.Execute(genapp.BuildHeaderGroups(groups))
+}
@@ -0,0 +1,98 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateJobs generates the client methods that retrieve and poll the status of the jobs started
+// by the asynchronous actions of the API. Nothing is generated if the API has no async action.
+func (g *Generator) generateJobs(pkgDir string) (err error) {
+	if !g.API.HasAsyncActions() {
+		return nil
+	}
+
+	jobsFile := filepath.Join(pkgDir, "jobs.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(jobsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("errors"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+	}
+	title := fmt.Sprintf("%s: Job Status Client", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, jobsFile)
+
+	data := map[string]interface{}{
+		"TypeName": codegen.GoTypeName(design.JobMedia, nil, 0, false),
+		"Path":     g.API.JobStatusPath(),
+	}
+	return file.ExecuteTemplate("jobs", jobsT, nil, data)
+}
+
+const jobsT = `// ShowJob makes a request to the job status endpoint. path is the value of the Location header
+// of the 202 responses of the asynchronous actions, e.g. {{ printf "%q" .Path }}.
+func (c *Client) ShowJob(ctx context.Context, path string) (*http.Response, error) {
+	ctx = goaclient.ContextWithEndpoint(ctx, "jobs", "show", nil)
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.Do(ctx, req)
+}
+
+// WaitJob polls the job status endpoint with an exponential backoff configured by opts until the
+// job located at path succeeds or fails and returns its final state. opts may be nil in which
+// case the defaults of goaclient.Poll are used. WaitJob returns an error if a request fails, if
+// the job fails or if ctx is done before the job completes.
+func (c *Client) WaitJob(ctx context.Context, path string, opts *goaclient.PollOptions) (*{{ .TypeName }}, error) {
+	var job *{{ .TypeName }}
+	err := goaclient.Poll(ctx, opts, func(ctx context.Context) (bool, error) {
+		resp, err := c.ShowJob(ctx, path)
+		if err != nil {
+			return false, err
+		}
+		if err := c.DecodeError(resp); err != nil {
+			return false, err
+		}
+		defer resp.Body.Close()
+		if job, err = c.Decode{{ .TypeName }}(resp); err != nil {
+			return false, err
+		}
+		return job.Status == "succeeded" || job.Status == "failed", nil
+	})
+	if err != nil {
+		return job, err
+	}
+	if job.Status == "failed" {
+		msg := "job failed"
+		if job.Error != nil {
+			msg += ": " + *job.Error
+		}
+		return job, errors.New(msg)
+	}
+	return job, nil
+}
+`
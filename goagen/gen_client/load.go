@@ -0,0 +1,137 @@
+package genclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateLoad generates the load testing tool that calls the client methods documented by the
+// examples with the example payloads and reports the latency percentiles of each endpoint.
+// Nothing is generated if no client method has an example.
+func (g *Generator) generateLoad(loadDir, clientPkg string) (err error) {
+	if err = os.RemoveAll(loadDir); err != nil {
+		return err
+	}
+	examples := g.examples()
+	if len(examples) == 0 {
+		return nil
+	}
+	if err = os.MkdirAll(loadDir, 0755); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, loadDir)
+	for i, ex := range examples {
+		if ex.PayloadType == "" {
+			continue
+		}
+		for j, arg := range ex.Args {
+			if arg == "&payload" {
+				ex.Args[j] = fmt.Sprintf("&payload%d", i)
+			}
+		}
+	}
+
+	loadFile := filepath.Join(loadDir, "main.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(loadFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("flag"),
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("log"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("os"),
+		codegen.SimpleImport("strings"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa/uuid"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+		codegen.SimpleImport(clientPkg),
+	}
+	title := fmt.Sprintf("%s: Load Testing Tool", g.API.Context())
+	if err = file.WriteHeader(title, "main", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, loadFile)
+
+	host := g.API.Host
+	if host == "" {
+		host = "localhost:8080"
+	}
+	scheme := "http"
+	if len(g.API.Schemes) > 0 {
+		scheme = g.API.Schemes[0]
+	}
+	data := map[string]interface{}{
+		"ClientPkg": g.Target,
+		"Host":      host,
+		"Scheme":    scheme,
+		"Examples":  examples,
+	}
+	return file.ExecuteTemplate("load", loadT, nil, data)
+}
+
+const loadT = `// main sends the design example requests to the endpoints of the service at the rate given on
+// the command line and prints the latency percentiles of each endpoint.
+func main() {
+	var (
+		host        = flag.String("H", {{ printf "%q" .Host }}, "Host of the service")
+		scheme      = flag.String("s", {{ printf "%q" .Scheme }}, "Scheme used to make requests")
+		rps         = flag.Float64("rps", 10, "Number of requests per second, 0 sends them as fast as possible")
+		concurrency = flag.Int("c", 1, "Number of concurrent requests")
+		duration    = flag.Duration("d", 10*time.Second, "Duration of the test")
+		requests    = flag.Int("n", 0, "Total number of requests, 0 for no limit")
+		timeout     = flag.Duration("timeout", 20*time.Second, "Timeout of each request")
+		endpoints   = flag.String("endpoints", "", "Comma separated list of the endpoints to exercise, e.g. \"{{ (index .Examples 0).Resource }} {{ (index .Examples 0).Action }}\", all if empty")
+	)
+	flag.Parse()
+
+	c := {{ .ClientPkg }}.New(goaclient.HTTPClientDoer(&http.Client{Timeout: *timeout}))
+	c.Host = *host
+	c.Scheme = *scheme
+{{ range $i, $ex := .Examples }}{{ if .PayloadType }}	var payload{{ $i }} {{ $.ClientPkg }}.{{ .PayloadType }}
+	if err := json.Unmarshal([]byte({{ .Payload }}), &payload{{ $i }}); err != nil {
+		log.Fatal(err)
+	}
+{{ end }}{{ end }}
+	targets := []*goaclient.LoadTarget{
+{{ range .Examples }}		{Name: {{ printf "%q" (printf "%s %s" .Resource .Action) }}, Call: func(ctx context.Context) (*http.Response, error) {
+			return c.{{ .Method }}(ctx, {{ $.ClientPkg }}.{{ .PathFunc }}({{ join .PathArgs ", " }}){{ range .Args }}, {{ . }}{{ end }})
+		}},
+{{ end }}	}
+	if *endpoints != "" {
+		selected := make(map[string]bool)
+		for _, e := range strings.Split(*endpoints, ",") {
+			selected[strings.TrimSpace(e)] = true
+		}
+		var filtered []*goaclient.LoadTarget
+		for _, t := range targets {
+			if selected[t.Name] {
+				filtered = append(filtered, t)
+			}
+		}
+		if len(filtered) == 0 {
+			fmt.Fprintf(os.Stderr, "no endpoint matches %q\n", *endpoints)
+			os.Exit(1)
+		}
+		targets = filtered
+	}
+
+	opts := &goaclient.LoadOptions{RPS: *rps, Concurrency: *concurrency, Duration: *duration, Requests: *requests}
+	for _, r := range goaclient.Load(context.Background(), opts, targets...) {
+		fmt.Println(r)
+	}
+}
+`
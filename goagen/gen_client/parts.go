@@ -0,0 +1,112 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_app"
+)
+
+// generateParts generates the iterators over the parts of the multipart/mixed responses. Nothing
+// is generated if no response defines parts.
+func (g *Generator) generateParts(pkgDir string) (err error) {
+	var responses []map[string]interface{}
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			return a.IterateResponses(func(r *design.ResponseDefinition) error {
+				if len(r.Parts) == 0 {
+					return nil
+				}
+				parts, err := genapp.ResponseParts(r)
+				if err != nil {
+					return err
+				}
+				responses = append(responses, map[string]interface{}{
+					"Name":     codegen.Goify(a.Name, true) + codegen.Goify(res.Name, true) + codegen.Goify(r.Name, true),
+					"Action":   a.Name,
+					"Resource": res.Name,
+					"Response": r.Name,
+					"Parts":    parts,
+				})
+				return nil
+			})
+		})
+	})
+	if err != nil || len(responses) == 0 {
+		return err
+	}
+
+	partsFile := filepath.Join(pkgDir, "parts.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(partsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("net/http"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+		codegen.NewImport("uuid", "github.com/goadesign/goa/uuid"),
+	}
+	title := fmt.Sprintf("%s: Multipart Response Parts", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, partsFile)
+	for _, r := range responses {
+		if err = file.ExecuteTemplate("parts", partsT, nil, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const partsT = `// {{ .Name }}Part is a part of the {{ .Response }} response of the {{ .Action }} {{ .Resource }}
+// action, only the field of the part is set.
+type {{ .Name }}Part struct {
+{{ range .Parts }}	{{ .FieldName }} {{ if .GoType }}{{ .GoType }}{{ else }}[]byte{{ end }}
+{{ end }}}
+
+// {{ .Name }}Parts iterates over the parts of the {{ .Response }} response of the {{ .Action }}
+// {{ .Resource }} action as they are received.
+type {{ .Name }}Parts struct {
+	*goaclient.PartReader
+}
+
+// Part decodes the current part, call Next first to advance to the next part.
+func (p *{{ .Name }}Parts) Part() (*{{ .Name }}Part, error) {
+	var part {{ .Name }}Part
+	switch p.Name() {
+{{ range .Parts }}	case {{ printf "%q" .Name }}:
+{{ if .GoType }}		if err := p.Decode(&part.{{ .FieldName }}); err != nil {
+			return nil, err
+		}
+{{ else }}		b, err := p.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		part.{{ .FieldName }} = b
+{{ end }}{{ end }}	default:
+		return nil, fmt.Errorf("unexpected part %q", p.Name())
+	}
+	return &part, nil
+}
+
+// Decode{{ .Name }}Parts returns an iterator over the parts of the given {{ .Response }} response.
+// The caller must close the iterator once done.
+func (c *Client) Decode{{ .Name }}Parts(resp *http.Response) (*{{ .Name }}Parts, error) {
+	r, err := goaclient.NewPartReader(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &{{ .Name }}Parts{PartReader: r}, nil
+}
+`
@@ -0,0 +1,121 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateReplay generates the client method that re-issues the requests recorded by the
+// middleware of the recorded actions. Nothing is generated if the API has no recorded action.
+func (g *Generator) generateReplay(pkgDir string) (err error) {
+	if !g.API.HasRecordedActions() {
+		return nil
+	}
+
+	replayFile := filepath.Join(pkgDir, "replay.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(replayFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/url"),
+		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+	}
+	title := fmt.Sprintf("%s: Request Replay Client", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, replayFile)
+
+	var actions []map[string]interface{}
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if !a.Recorded {
+				return nil
+			}
+			var signer, scheme string
+			if a.Security != nil {
+				scheme = a.Security.Scheme.SchemeName
+				signer = codegen.Goify(scheme, true)
+			}
+			if signer == "" && !res.CSRF {
+				return nil
+			}
+			actions = append(actions, map[string]interface{}{
+				"Resource":       res.Name,
+				"Name":           a.Name,
+				"Signer":         signer,
+				"SecurityScheme": scheme,
+				"CSRF":           res.CSRF,
+			})
+			return nil
+		})
+	})
+	return file.ExecuteTemplate("replay", replayT, nil, actions)
+}
+
+const replayT = `// Replay re-issues the request described by rec, e.g. a recording made by the middleware of a
+// recorded action, and returns the response. The request is sent to the client host and signed
+// with the signers of the recorded action, the payload is sent as JSON. The values redacted
+// during the recording are sent as is.
+func (c *Client) Replay(ctx context.Context, rec *middleware.Recording) (*http.Response, error) {
+	ctx = goaclient.ContextWithEndpoint(ctx, rec.Resource, rec.Action, nil)
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: rec.Path, RawQuery: rec.Query.Encode()}
+	var body io.Reader
+	if len(rec.Payload) > 0 {
+		body = bytes.NewReader(rec.Payload)
+	}
+	req, err := http.NewRequest(rec.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range rec.Header {
+		if k == "Content-Length" {
+			continue
+		}
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+{{ if . }}	switch rec.Resource + "#" + rec.Action {
+{{ range . }}	case {{ printf "%q" (printf "%s#%s" .Resource .Name) }}:
+{{ if .Signer }}		signer := goaclient.ContextSigner(ctx, {{ printf "%q" .SecurityScheme }})
+		if signer == nil {
+			signer = c.{{ .Signer }}Signer
+		}
+		if signer != nil {
+			if err := signer.Sign(req); err != nil {
+				return nil, err
+			}
+		}
+{{ end }}{{ if .CSRF }}		if c.CSRFSigner != nil {
+			if err := c.CSRFSigner.Sign(req); err != nil {
+				return nil, err
+			}
+		}
+{{ end }}{{ end }}	}
+{{ end }}	return c.Client.Do(ctx, req)
+}
+`
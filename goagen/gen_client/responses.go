@@ -0,0 +1,170 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+type (
+	// responsesData describes the function that decodes the responses of an action.
+	responsesData struct {
+		// Name is the prefix of the function name, e.g. ShowJob.
+		Name string
+		// Resource and Action are the names of the resource and action.
+		Resource, Action string
+		// ResultType is the type of the decoded result, interface{} if the responses use
+		// different media types.
+		ResultType string
+		// Cases lists the status codes grouped by decoding function.
+		Cases []*responseCase
+	}
+
+	// responseCase describes the decoding of the responses sent with a set of status codes.
+	responseCase struct {
+		// Statuses is the comma separated list of status codes.
+		Statuses string
+		// Responses is the comma separated list of response names.
+		Responses string
+		// Decode is the name of the client method that decodes the response body, empty if
+		// the responses have no body.
+		Decode string
+	}
+)
+
+// generateResponses generates the client methods that decode the responses of the actions that
+// select their response with the Tag DSL. The methods select the decoding from the response
+// status code. Nothing is generated if no action defines tagged responses.
+func (g *Generator) generateResponses(pkgDir string) (err error) {
+	var data []*responsesData
+	err = g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			d, err := buildResponsesData(g.API, a)
+			if err != nil {
+				return err
+			}
+			if d != nil {
+				data = append(data, d)
+			}
+			return nil
+		})
+	})
+	if err != nil || len(data) == 0 {
+		return err
+	}
+
+	responsesFile := filepath.Join(pkgDir, "responses.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(responsesFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("fmt"),
+		codegen.SimpleImport("net/http"),
+	}
+	title := fmt.Sprintf("%s: Response Decoders", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, responsesFile)
+	return file.ExecuteTemplate("responses", responsesT, nil, data)
+}
+
+// buildResponsesData returns the data used to render the response decoding method of the given
+// action, nil if the action defines no tagged response. Error responses are left to DecodeError.
+func buildResponsesData(api *design.APIDefinition, a *design.ActionDefinition) (*responsesData, error) {
+	var (
+		resps  []*design.ResponseDefinition
+		tagged bool
+	)
+	for _, resp := range a.Responses {
+		if resp.TagAttribute != "" {
+			tagged = true
+		}
+		resps = append(resps, resp)
+	}
+	if !tagged {
+		return nil, nil
+	}
+	sort.Slice(resps, func(i, j int) bool { return resps[i].Status < resps[j].Status })
+
+	var (
+		cases   []*responseCase
+		byFunc  = make(map[string]*responseCase)
+		results = make(map[string]bool)
+		result  string
+	)
+	for _, resp := range resps {
+		var decode string
+		mt, ok := resp.Type.(*design.MediaTypeDefinition)
+		if !ok && resp.MediaType != "" {
+			mt = api.MediaTypeWithIdentifier(resp.MediaType)
+		}
+		if mt != nil {
+			if mt.IsError() {
+				continue
+			}
+			view := resp.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			projected, _, err := mt.Project(view)
+			if err != nil {
+				return nil, err
+			}
+			decode = "Decode" + typeName(projected)
+			result = decodeGoTypeRef(projected, projected.AllRequired(), 0, false)
+			results[result] = true
+		} else if resp.Status >= 400 {
+			continue
+		}
+		status := strconv.Itoa(resp.Status)
+		if c, ok := byFunc[decode]; ok {
+			c.Statuses += ", " + status
+			c.Responses += ", " + resp.Name
+			continue
+		}
+		c := &responseCase{Statuses: status, Responses: resp.Name, Decode: decode}
+		byFunc[decode] = c
+		cases = append(cases, c)
+	}
+	if len(results) != 1 {
+		result = "interface{}"
+	}
+	return &responsesData{
+		Name:       codegen.Goify(a.Name, true) + codegen.Goify(a.Parent.Name, true),
+		Resource:   a.Parent.Name,
+		Action:     a.Name,
+		ResultType: result,
+		Cases:      cases,
+	}, nil
+}
+
+// responsesT renders the response decoding methods.
+// template input: []*responsesData
+const responsesT = `{{ range . }}
+// Decode{{ .Name }}Response decodes the response of the {{ .Action }} action of the {{ .Resource }} resource
+// according to its status code. It returns the error decoded by DecodeError for the other status
+// codes.
+func (c *Client) Decode{{ .Name }}Response(resp *http.Response) ({{ .ResultType }}, error) {
+	switch resp.StatusCode {
+{{ range .Cases }}	case {{ .Statuses }}: // {{ .Responses }}
+		return {{ if .Decode }}c.{{ .Decode }}(resp){{ else }}nil, nil{{ end }}
+{{ end }}	}
+	if err := c.DecodeError(resp); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+}
+{{ end }}`
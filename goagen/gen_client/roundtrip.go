@@ -0,0 +1,294 @@
+package genclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// roundTripSamples is the number of random values checked by each generated round-trip test.
+const roundTripSamples = 3
+
+// roundTripDepth is the maximum nesting of the objects of the random values.
+const roundTripDepth = 3
+
+// roundTripData describes a type checked by a generated round-trip test.
+type roundTripData struct {
+	// TypeName is the name of the Go type in both the client and the app packages.
+	TypeName string
+	// Samples lists the Go string literals of the JSON encoded random values.
+	Samples []string
+}
+
+// generateRoundTripTests generates the tests that check that the payload and result types
+// encoded by the client and decoded then encoded back by the service decode to the same client
+// values.
+func (g *Generator) generateRoundTripTests(pkgDir, clientPkg string) (err error) {
+	appPkg := g.AppPkg
+	if _, err := codegen.PackageSourcePath(appPkg); err != nil {
+		imp, err := codegen.PackagePath(g.OutDir)
+		if err != nil {
+			return err
+		}
+		appPkg = path.Join(filepath.ToSlash(imp), appPkg)
+	}
+	tests := g.roundTripTypes()
+	if len(tests) == 0 {
+		return nil
+	}
+
+	testFile := filepath.Join(pkgDir, "roundtrip_test.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(testFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("reflect"),
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport(appPkg),
+		codegen.SimpleImport(clientPkg),
+	}
+	title := fmt.Sprintf("%s: Client encoding round-trip tests", g.API.Context())
+	if err = file.WriteHeader(title, g.Target+"_test", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, testFile)
+
+	data := map[string]interface{}{
+		"AppPkg":    path.Base(appPkg),
+		"ClientPkg": g.Target,
+		"Tests":     tests,
+	}
+	return file.ExecuteTemplate("roundTrip", roundTripT, template.FuncMap{}, data)
+}
+
+// roundTripTypes returns the payload and result types of the API actions sorted by name.
+// External types, types of multipart payloads and error media types are skipped.
+func (g *Generator) roundTripTypes() []*roundTripData {
+	types := make(map[string]*design.AttributeDefinition)
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.WebSocket() {
+				return nil
+			}
+			if p := a.Payload; p != nil && !a.PayloadMultipart && p.Type.IsObject() && !hasFile(p.AttributeDefinition, nil) {
+				if path, _ := codegen.TypePackage(p); path == "" {
+					types[codegen.GoTypeName(p, nil, 0, false)] = p.AttributeDefinition
+				}
+			}
+			for _, r := range a.Responses {
+				if r.Status < 200 || r.Status > 299 || r.MediaType == "" {
+					continue
+				}
+				mt := g.API.MediaTypeWithIdentifier(r.MediaType)
+				if mt == nil || mt.IsError() {
+					continue
+				}
+				view := r.ViewName
+				if view == "" {
+					view = design.DefaultView
+				}
+				p, _, err := mt.Project(view)
+				if err != nil {
+					continue
+				}
+				types[codegen.GoTypeName(p, nil, 0, false)] = p.AttributeDefinition
+			}
+			return nil
+		})
+	})
+	names := make([]string, 0, len(types))
+	for n := range types {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	tests := make([]*roundTripData, len(names))
+	for i, n := range names {
+		samples := make([]string, roundTripSamples)
+		for j := range samples {
+			r := design.NewRandomGenerator(fmt.Sprintf("%s/%d", n, j))
+			b, _ := json.Marshal(sampleValue(types[n], r, roundTripDepth))
+			samples[j] = goStringLiteral(string(b))
+		}
+		tests[i] = &roundTripData{TypeName: n, Samples: samples}
+	}
+	return tests
+}
+
+// sampleValue returns a random JSON value of the type of the given attribute. The optional
+// attributes of objects are randomly omitted and depth limits the nesting of objects so that
+// the values of recursive types are finite.
+func sampleValue(att *design.AttributeDefinition, r *design.RandomGenerator, depth int) interface{} {
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		return sampleValue(actual.AttributeDefinition, r, depth)
+	case *design.MediaTypeDefinition:
+		return sampleValue(actual.AttributeDefinition, r, depth)
+	case design.Primitive:
+		switch actual.Kind() {
+		case design.BooleanKind:
+			return r.Bool()
+		case design.IntegerKind:
+			return r.Intn(1000)
+		case design.NumberKind:
+			return r.Float64()
+		case design.DateTimeKind:
+			return r.DateTime().Format(time.RFC3339)
+		case design.UUIDKind:
+			return r.UUID().String()
+		}
+		return r.String()
+	case *design.Array:
+		n := 0
+		if depth > 0 {
+			n = r.Intn(3)
+		}
+		vals := make([]interface{}, n)
+		for i := range vals {
+			vals[i] = sampleValue(actual.ElemType, r, depth-1)
+		}
+		return vals
+	case *design.Hash:
+		vals := make(map[string]interface{})
+		switch actual.KeyType.Type.Kind() {
+		case design.StringKind, design.IntegerKind, design.DateTimeKind, design.UUIDKind:
+		default:
+			// JSON objects cannot be decoded into Go maps with such keys.
+			return vals
+		}
+		if depth > 0 {
+			for i := r.Intn(3); i > 0; i-- {
+				k := fmt.Sprint(sampleValue(actual.KeyType, r, depth-1))
+				vals[k] = sampleValue(actual.ElemType, r, depth-1)
+			}
+		}
+		return vals
+	case design.Object:
+		names := make([]string, 0, len(actual))
+		for n := range actual {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		vals := make(map[string]interface{})
+		if depth < -roundTripDepth {
+			// Recursive required attributes, the value is invalid but finite.
+			return vals
+		}
+		for _, n := range names {
+			if !att.IsRequired(n) && (depth <= 0 || !r.Bool()) {
+				continue
+			}
+			vals[n] = sampleValue(actual[n], r, depth-1)
+		}
+		return vals
+	}
+	return nil
+}
+
+// hasFile returns true if the given attribute or any of its children is a file.
+func hasFile(att *design.AttributeDefinition, seen map[string]bool) bool {
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	switch actual := att.Type.(type) {
+	case *design.UserTypeDefinition:
+		if seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasFile(actual.AttributeDefinition, seen)
+	case *design.MediaTypeDefinition:
+		if seen[actual.TypeName] {
+			return false
+		}
+		seen[actual.TypeName] = true
+		return hasFile(actual.AttributeDefinition, seen)
+	case design.Primitive:
+		return actual.Kind() == design.FileKind
+	case *design.Array:
+		return hasFile(actual.ElemType, seen)
+	case *design.Hash:
+		return hasFile(actual.KeyType, seen) || hasFile(actual.ElemType, seen)
+	case design.Object:
+		for _, a := range actual {
+			if hasFile(a, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goStringLiteral returns the Go raw string literal for s if possible, the interpreted string
+// literal otherwise.
+func goStringLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+const roundTripT = `// roundTrip decodes sample with the client, encodes the resulting value and decodes the
+// encoding into both the client value cv and the service value sv. It then encodes sv and decodes
+// the result into the client value cv2 and fails t if cv and cv2 differ. newClient returns a
+// pointer to a new client value. Decoding the client encoding rather than the sample makes the
+// comparison ignore the differences erased by encoding such as empty optional collections.
+func roundTrip(t *testing.T, sample string, newClient func() interface{}, sv interface{}) {
+	t.Helper()
+	v := newClient()
+	if err := json.Unmarshal([]byte(sample), v); err != nil {
+		t.Fatalf("failed to decode sample %s: %s", sample, err)
+	}
+	cb, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("client failed to encode value: %s", err)
+	}
+	cv := newClient()
+	if err := json.Unmarshal(cb, cv); err != nil {
+		t.Fatalf("client failed to decode %s: %s", cb, err)
+	}
+	if err := json.Unmarshal(cb, sv); err != nil {
+		t.Fatalf("service failed to decode %s: %s", cb, err)
+	}
+	sb, err := json.Marshal(sv)
+	if err != nil {
+		t.Fatalf("service failed to encode value: %s", err)
+	}
+	cv2 := newClient()
+	if err := json.Unmarshal(sb, cv2); err != nil {
+		t.Fatalf("client failed to decode %s: %s", sb, err)
+	}
+	if !reflect.DeepEqual(cv, cv2) {
+		t.Errorf("round trip mismatch:\nclient encoding:  %s\nservice encoding: %s", cb, sb)
+	}
+}
+{{ range .Tests }}
+// TestRoundTrip{{ .TypeName }} checks that {{ .TypeName }} values survive the round trip between
+// the client and the service.
+func TestRoundTrip{{ .TypeName }}(t *testing.T) {
+	samples := []string{
+{{ range .Samples }}		{{ . }},
+{{ end }}	}
+	newClient := func() interface{} { return new({{ $.ClientPkg }}.{{ .TypeName }}) }
+	for _, s := range samples {
+		roundTrip(t, s, newClient, new({{ $.AppPkg }}.{{ .TypeName }}))
+	}
+}
+{{ end }}`
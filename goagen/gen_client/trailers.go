@@ -0,0 +1,119 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_app"
+)
+
+// trailersData describes the function that reads the trailers of a response.
+type trailersData struct {
+	// Name is the prefix of the function name, e.g. ExportReportOK.
+	Name string
+	// Resource, Action and Response are the names of the resource, action and response.
+	Resource, Action, Response string
+	// TypeName is the name of the client type of the response media type.
+	TypeName string
+	// Trailers lists the trailers sorted by name.
+	Trailers []*genapp.TrailerData
+}
+
+// generateTrailers generates the functions that read the trailers of the responses into the
+// decoded media types. Nothing is generated if no response defines trailers.
+func (g *Generator) generateTrailers(pkgDir string) (err error) {
+	var data []*trailersData
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			names := make([]string, 0, len(a.Responses))
+			for n := range a.Responses {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			for _, n := range names {
+				resp := a.Responses[n]
+				if len(resp.Trailers) == 0 {
+					continue
+				}
+				mt, ok := resp.Type.(*design.MediaTypeDefinition)
+				if !ok {
+					mt = g.API.MediaTypeWithIdentifier(resp.MediaType)
+				}
+				if mt == nil {
+					continue
+				}
+				view := resp.ViewName
+				if view == "" {
+					view = design.DefaultView
+				}
+				projected, _, err := mt.Project(view)
+				if err != nil {
+					return err
+				}
+				trailers := genapp.BuildTrailers(resp, projected)
+				if len(trailers) == 0 {
+					continue
+				}
+				data = append(data, &trailersData{
+					Name:     codegen.Goify(a.Name, true) + codegen.Goify(res.Name, true) + codegen.Goify(resp.Name, true),
+					Resource: res.Name,
+					Action:   a.Name,
+					Response: resp.Name,
+					TypeName: codegen.GoTypeName(projected, projected.AllRequired(), 0, false),
+					Trailers: trailers,
+				})
+			}
+			return nil
+		})
+	})
+	if len(data) == 0 {
+		return nil
+	}
+
+	trailersFile := filepath.Join(pkgDir, "trailers.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(trailersFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("time"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	title := fmt.Sprintf("%s: Response Trailers", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, trailersFile)
+	return file.ExecuteTemplate("trailers", trailersT, nil, data)
+}
+
+const trailersT = `{{ range . }}
+// Read{{ .Name }}Trailers sets the fields of r sent in the trailers of the {{ .Response }} response of
+// the {{ .Action }} action of the {{ .Resource }} resource. The trailers are only available once the
+// response body has been read entirely, e.g. after decoding r. The fields whose trailer is missing
+// are left unchanged.
+func Read{{ .Name }}Trailers(resp *http.Response, r *{{ .TypeName }}) error {
+	var err error
+{{ range .Trailers }}	if raw := resp.Trailer.Get({{ printf "%q" .Key }}); raw != "" {
+{{ if .Parse }}		if x, err2 := {{ .Parse }}; err2 != nil {
+			err = goa.MergeErrors(err, goa.InvalidParamTypeError({{ printf "%q" .Key }}, raw, {{ printf "%q" .TypeName }}))
+		} else {
+			r.{{ .Field }} = {{ if .Pointer }}&{{ end }}x
+		}
+{{ else }}		r.{{ .Field }} = {{ if .Pointer }}&{{ end }}raw
+{{ end }}	}
+{{ end }}	return err
+}
+{{ end }}`
@@ -0,0 +1,58 @@
+package genclient
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateUploads generates the client method that sends the content of the uploads created by
+// the resumable actions of the API. Nothing is generated if the API has no resumable action.
+func (g *Generator) generateUploads(pkgDir string) (err error) {
+	if !g.API.HasResumableActions() {
+		return nil
+	}
+
+	uploadsFile := filepath.Join(pkgDir, "uploads.go")
+	var file *codegen.SourceFile
+	file, err = codegen.SourceFileFor(uploadsFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("context"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("net/url"),
+		codegen.NewImport("goaclient", "github.com/goadesign/goa/client"),
+	}
+	title := fmt.Sprintf("%s: Resumable Upload Client", g.API.Context())
+	if err = file.WriteHeader(title, g.Target, imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, uploadsFile)
+
+	return file.ExecuteTemplate("uploads", uploadsT, nil, nil)
+}
+
+const uploadsT = `// ResumeUpload sends the size bytes of content read from r to the upload offset endpoints located
+// at path, the value of the Location header of the 201 responses of the resumable actions. The
+// content is sent in chunks and the transfer resumes from the offset reported by the server after
+// a failure or when ResumeUpload is called again for the same upload. opts may be nil in which
+// case the defaults of goaclient.Upload are used.
+func (c *Client) ResumeUpload(ctx context.Context, path string, r io.ReaderAt, size int64, opts *goaclient.UploadOptions) error {
+	ctx = goaclient.ContextWithEndpoint(ctx, "uploads", "resume", nil)
+	scheme := c.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	u := url.URL{Host: c.Host, Scheme: scheme, Path: path}
+	return goaclient.Upload(ctx, c.Client, u.String(), r, size, opts)
+}
+`
@@ -33,6 +33,7 @@ type Generator struct {
 	AppPkg    string                // Name of generated "app" package
 	Force     bool                  // Whether to override existing files
 	Regen     bool                  // Whether to regenerate scaffolding in place, retaining controller impls
+	Stubs     bool                  // Whether the scaffolded actions return a "not implemented" error
 	Pkg       string                // Name of the generated package
 	Resource  string                // Name of the generated file
 	genfiles  []string              // Generated files
@@ -42,7 +43,7 @@ type Generator struct {
 func Generate() (files []string, err error) {
 	var (
 		outDir, designPkg, appPkg, ver, res, pkg string
-		force, regen                             bool
+		force, regen, stubs                      bool
 	)
 
 	set := flag.NewFlagSet("controller", flag.PanicOnError)
@@ -54,14 +55,17 @@ func Generate() (files []string, err error) {
 	set.StringVar(&ver, "version", "", "")
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&stubs, "stubs", false, "")
 	set.Bool("notest", false, "")
+	set.String("di", "", "")
+	set.Bool("split", false, "")
 	set.Parse(os.Args[1:])
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Force: force, Regen: regen, API: design.Design, Pkg: pkg, Resource: res}
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, AppPkg: appPkg, Force: force, Regen: regen, Stubs: stubs, API: design.Design, Pkg: pkg, Resource: res}
 
 	return g.Generate()
 }
@@ -94,7 +98,7 @@ func (g *Generator) Generate() (_ []string, err error) {
 			err      error
 		)
 		if g.Resource == "" || g.Resource == r.Name {
-			filename, err = genmain.GenerateController(g.Force, g.Regen, g.AppPkg, g.OutDir, g.Pkg, r.Name, r)
+			filename, err = genmain.GenerateController(g.Force, g.Regen, g.Stubs, g.AppPkg, g.OutDir, g.Pkg, r.Name, r)
 		}
 
 		if err != nil {
@@ -47,6 +47,13 @@ func Regen(regen bool) Option {
 	}
 }
 
+//Stubs Whether the scaffolded actions return a "not implemented" error
+func Stubs(stubs bool) Option {
+	return func(g *Generator) {
+		g.Stubs = stubs
+	}
+}
+
 //Pkg sets the name of generated package
 func Pkg(name string) Option {
 	return func(g *Generator) {
@@ -0,0 +1,9 @@
+/*
+Package gengateway provides a generator for API gateway configurations.
+
+The generator produces a Kong declarative configuration (https://docs.konghq.com/) that lists one
+route per action and file server route of the design. The routes are secured with the Kong plugin
+corresponding to the action security scheme and rate limited with the limits set using the
+"gateway:rate-limit" metadata, see apidsl.Metadata.
+*/
+package gengateway
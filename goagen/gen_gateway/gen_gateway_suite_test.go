@@ -0,0 +1,13 @@
+package gengateway_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenGateway(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenGateway Suite")
+}
@@ -0,0 +1,123 @@
+package gengateway
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of an API gateway configuration Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the API gateway configuration generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Upstream string                // URL of the proxied service, defaults to the API scheme and host
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, upstream, ver string
+	)
+
+	set := flag.NewFlagSet("gateway", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.StringVar(&upstream, "upstream", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Upstream: upstream, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the gateway configuration files.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	upstream := g.Upstream
+	if upstream == "" {
+		upstream = defaultUpstream(g.API)
+	}
+	if upstream == "" {
+		return nil, fmt.Errorf("missing upstream URL, set the API host in the design or use --upstream")
+	}
+	conf, err := NewKongConfig(g.API, upstream)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := yaml.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	gatewayDir := filepath.Join(g.OutDir, "gateway")
+	os.RemoveAll(gatewayDir)
+	if err = os.MkdirAll(gatewayDir, 0755); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, gatewayDir)
+
+	kongFile := filepath.Join(gatewayDir, "kong.yaml")
+	if err := ioutil.WriteFile(kongFile, raw, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, kongFile)
+
+	return g.genfiles, nil
+}
+
+// defaultUpstream returns the URL built from the API scheme and host or the empty string if the
+// design does not define a host.
+func defaultUpstream(api *design.APIDefinition) string {
+	if api.Host == "" {
+		return ""
+	}
+	scheme := "http"
+	if len(api.Schemes) > 0 {
+		scheme = api.Schemes[0]
+	}
+	return fmt.Sprintf("%s://%s", scheme, api.Host)
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
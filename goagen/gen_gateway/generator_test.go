@@ -0,0 +1,114 @@
+package gengateway_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_gateway"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *gengateway.Generator
+
+	var args = struct {
+		api      *design.APIDefinition
+		outDir   string
+		upstream string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:   "out_dir",
+		upstream: "http://upstream:8080",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = gengateway.NewGenerator(
+				gengateway.API(args.api),
+				gengateway.OutDir(args.outDir),
+				gengateway.Upstream(args.upstream),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Upstream).Should(Equal(args.upstream))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir, host string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		host = "cellar.example.com"
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "gateway")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		API("test api", func() {
+			if host != "" {
+				Host(host)
+			}
+			Scheme("https")
+		})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET("/bottles"))
+				Response("OK")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		g := gengateway.NewGenerator(gengateway.API(design.Design), gengateway.OutDir(outDir))
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	It("writes the Kong declarative configuration", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		kongFile := filepath.Join(outDir, "gateway", "kong.yaml")
+		Ω(files).Should(ContainElement(kongFile))
+		b, err := ioutil.ReadFile(kongFile)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`_format_version: "3.0"
+services:
+- name: test-api
+  url: https://cellar.example.com
+  routes:
+  - name: bottle-list
+    methods:
+    - GET
+    paths:
+    - ~/bottles$
+    strip_path: false
+`))
+	})
+
+	Context("with no host", func() {
+		BeforeEach(func() {
+			host = ""
+		})
+
+		It("requires the upstream URL", func() {
+			Ω(genErr).Should(HaveOccurred())
+			Ω(genErr.Error()).Should(ContainSubstring("--upstream"))
+		})
+	})
+})
@@ -0,0 +1,239 @@
+package gengateway
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// KongConfig is a Kong declarative configuration.
+	// See https://docs.konghq.com/gateway/latest/production/deployment-topologies/db-less-and-declarative-config/
+	KongConfig struct {
+		// FormatVersion is the version of the declarative configuration format.
+		FormatVersion string `yaml:"_format_version"`
+		// Services lists the proxied services.
+		Services []*KongService `yaml:"services"`
+	}
+
+	// KongService is an upstream service proxied by Kong.
+	KongService struct {
+		// Name of the service
+		Name string `yaml:"name"`
+		// URL of the upstream service
+		URL string `yaml:"url"`
+		// Routes lists the routes proxied to the service.
+		Routes []*KongRoute `yaml:"routes,omitempty"`
+		// Plugins lists the plugins applied to all the service routes.
+		Plugins []*KongPlugin `yaml:"plugins,omitempty"`
+	}
+
+	// KongRoute matches the requests proxied to a service.
+	KongRoute struct {
+		// Name of the route
+		Name string `yaml:"name"`
+		// Methods lists the HTTP methods matched by the route.
+		Methods []string `yaml:"methods"`
+		// Paths lists the regular expressions matching the request paths.
+		Paths []string `yaml:"paths"`
+		// StripPath is always false so that the service receives the full request path.
+		StripPath bool `yaml:"strip_path"`
+		// Plugins lists the plugins applied to the route.
+		Plugins []*KongPlugin `yaml:"plugins,omitempty"`
+	}
+
+	// KongPlugin is a Kong plugin configuration.
+	KongPlugin struct {
+		// Name of the plugin, e.g. "key-auth"
+		Name string `yaml:"name"`
+		// Config is the plugin configuration.
+		Config map[string]interface{} `yaml:"config,omitempty"`
+	}
+)
+
+// KongFormatVersion is the version of the generated declarative configuration format.
+const KongFormatVersion = "3.0"
+
+// RateLimitMetadata is the metadata key used to set the rate limits of the API, a resource or an
+// action. Each value has the form N/unit where unit is one of second, minute, hour, day, month or
+// year, e.g. "100/minute".
+const RateLimitMetadata = "gateway:rate-limit"
+
+// rateLimitUnits lists the units supported by the Kong rate-limiting plugin.
+var rateLimitUnits = []string{"second", "minute", "hour", "day", "month", "year"}
+
+// NewKongConfig returns the Kong declarative configuration that proxies the routes of the given
+// API to the given upstream URL.
+func NewKongConfig(api *design.APIDefinition, upstream string) (*KongConfig, error) {
+	service := &KongService{Name: kongName(api.Name), URL: upstream}
+	limit, err := rateLimitPlugin(api, api.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil {
+		service.Plugins = append(service.Plugins, limit)
+	}
+	err = api.IterateResources(func(res *design.ResourceDefinition) error {
+		err := res.IterateActions(func(a *design.ActionDefinition) error {
+			mdata := a.Metadata
+			if _, ok := mdata[RateLimitMetadata]; !ok {
+				mdata = res.Metadata
+			}
+			limit, err := rateLimitPlugin(a, mdata)
+			if err != nil {
+				return err
+			}
+			for i, r := range a.Routes {
+				name := kongName(res.Name + "-" + a.Name)
+				if i > 0 {
+					name += "-" + strconv.Itoa(i+1)
+				}
+				service.Routes = append(service.Routes, newKongRoute(name, r.Verb, r.FullPath(), a.Security, limit))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		limit, err := rateLimitPlugin(res, res.Metadata)
+		if err != nil {
+			return err
+		}
+		i := 0
+		return res.IterateFileServers(func(fs *design.FileServerDefinition) error {
+			i++
+			name := kongName(res.Name + "-files")
+			if i > 1 {
+				name += "-" + strconv.Itoa(i)
+			}
+			service.Routes = append(service.Routes, newKongRoute(name, "GET", fs.RequestPath, fs.Security, limit))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &KongConfig{FormatVersion: KongFormatVersion, Services: []*KongService{service}}, nil
+}
+
+// newKongRoute returns the route that matches the given method and goa path, secured with the
+// given requirements and rate limited with the given plugin if not nil.
+func newKongRoute(name, method, path string, security *design.SecurityDefinition, limit *KongPlugin) *KongRoute {
+	route := &KongRoute{
+		Name:    name,
+		Methods: []string{method},
+		Paths:   []string{kongPath(path)},
+	}
+	if auth := securityPlugin(security); auth != nil {
+		route.Plugins = append(route.Plugins, auth)
+	}
+	if limit != nil {
+		route.Plugins = append(route.Plugins, limit)
+	}
+	return route
+}
+
+// kongPath returns the Kong regular expression matching the given goa path, e.g.
+// "~/bottles/[^/]+$" for "/bottles/:id". Wildcards match the rest of the path.
+func kongPath(path string) string {
+	if path == "" {
+		path = "/"
+	}
+	elems := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, elem := range elems {
+		switch {
+		case strings.HasPrefix(elem, ":"):
+			elems[i] = "[^/]+"
+		case strings.HasPrefix(elem, "*"):
+			elems[i] = ".*"
+		default:
+			elems[i] = regexp.QuoteMeta(elem)
+		}
+	}
+	return "~/" + strings.Join(elems, "/") + "$"
+}
+
+// kongNameRegex matches the characters that cannot be used in Kong entity names.
+var kongNameRegex = regexp.MustCompile(`[^A-Za-z0-9_.~-]+`)
+
+// kongName returns a valid Kong entity name built from the given design name.
+func kongName(name string) string {
+	return kongNameRegex.ReplaceAllString(name, "-")
+}
+
+// securityPlugin returns the Kong plugin that enforces the given security requirements or nil if
+// there is none.
+func securityPlugin(security *design.SecurityDefinition) *KongPlugin {
+	if security == nil || security.Scheme == nil {
+		return nil
+	}
+	scheme := security.Scheme
+	switch scheme.Kind {
+	case design.BasicAuthSecurityKind:
+		return &KongPlugin{Name: "basic-auth"}
+	case design.APIKeySecurityKind:
+		return &KongPlugin{Name: "key-auth", Config: map[string]interface{}{
+			"key_names":     []string{scheme.Name},
+			"key_in_header": scheme.In == "header",
+			"key_in_query":  scheme.In == "query",
+		}}
+	case design.JWTSecurityKind:
+		p := &KongPlugin{Name: "jwt"}
+		switch scheme.In {
+		case "header":
+			p.Config = map[string]interface{}{"header_names": []string{scheme.Name}}
+		case "query":
+			p.Config = map[string]interface{}{"uri_param_names": []string{scheme.Name}}
+		}
+		return p
+	case design.HMACSecurityKind:
+		return &KongPlugin{Name: "hmac-auth"}
+	case design.OAuth2SecurityKind:
+		config := map[string]interface{}{
+			"enable_authorization_code": scheme.Flow == "accessCode",
+			"enable_implicit_grant":     scheme.Flow == "implicit",
+			"enable_password_grant":     scheme.Flow == "password",
+			"enable_client_credentials": scheme.Flow == "application",
+		}
+		if len(security.Scopes) > 0 {
+			config["scopes"] = security.Scopes
+			config["mandatory_scope"] = true
+		}
+		return &KongPlugin{Name: "oauth2", Config: config}
+	}
+	return nil
+}
+
+// rateLimitPlugin returns the Kong rate-limiting plugin that enforces the limits set in the given
+// metadata or nil if there is none.
+func rateLimitPlugin(def dslengine.Definition, mdata dslengine.MetadataDefinition) (*KongPlugin, error) {
+	vals, ok := mdata[RateLimitMetadata]
+	if !ok {
+		return nil, nil
+	}
+	config := make(map[string]interface{}, len(vals))
+	for _, val := range vals {
+		elems := strings.Split(val, "/")
+		n, err := strconv.Atoi(elems[0])
+		if len(elems) != 2 || err != nil || n <= 0 || !isRateLimitUnit(elems[1]) {
+			return nil, fmt.Errorf("%s: invalid %s metadata value %#v, must be of the form N/unit where unit is one of %s",
+				def.Context(), RateLimitMetadata, val, strings.Join(rateLimitUnits, ", "))
+		}
+		config[elems[1]] = n
+	}
+	return &KongPlugin{Name: "rate-limiting", Config: config}, nil
+}
+
+// isRateLimitUnit returns true if unit is a rate limit unit supported by Kong.
+func isRateLimitUnit(unit string) bool {
+	for _, u := range rateLimitUnits {
+		if u == unit {
+			return true
+		}
+	}
+	return false
+}
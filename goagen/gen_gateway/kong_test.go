@@ -0,0 +1,189 @@
+package gengateway_test
+
+import (
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_gateway"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewKongConfig", func() {
+	var conf *gengateway.KongConfig
+	var confErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("cellar", func() {
+			BasePath("/api")
+		})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		conf, confErr = gengateway.NewKongConfig(design.Design, "http://cellar:8080")
+	})
+
+	route := func(name string) *gengateway.KongRoute {
+		for _, r := range conf.Services[0].Routes {
+			if r.Name == name {
+				return r
+			}
+		}
+		return nil
+	}
+
+	Context("with routes", func() {
+		BeforeEach(func() {
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id"), GET("//v1/bottles/:id"))
+					Response("OK")
+				})
+				Action("list", func() {
+					Routing(GET(""))
+					Response("OK")
+				})
+				Files("/files/*filepath", "/www")
+			})
+		})
+
+		It("maps each route", func() {
+			Ω(confErr).ShouldNot(HaveOccurred())
+			Ω(conf.FormatVersion).Should(Equal(gengateway.KongFormatVersion))
+			Ω(conf.Services).Should(HaveLen(1))
+			Ω(conf.Services[0].Name).Should(Equal("cellar"))
+			Ω(conf.Services[0].URL).Should(Equal("http://cellar:8080"))
+			Ω(conf.Services[0].Routes).Should(HaveLen(4))
+			Ω(route("bottle-show")).Should(Equal(&gengateway.KongRoute{
+				Name:    "bottle-show",
+				Methods: []string{"GET"},
+				Paths:   []string{"~/api/bottles/[^/]+$"},
+			}))
+			Ω(route("bottle-show-2").Paths).Should(Equal([]string{"~/v1/bottles/[^/]+$"}))
+			Ω(route("bottle-list").Paths).Should(Equal([]string{"~/api/bottles$"}))
+			Ω(route("bottle-files").Paths).Should(Equal([]string{"~/files/.*$"}))
+		})
+	})
+
+	Context("with security", func() {
+		BeforeEach(func() {
+			key := APIKeySecurity("key", func() { Header("X-Key") })
+			jwt := JWTSecurity("jwt", func() { Query("token") })
+			oauth := OAuth2Security("oauth", func() {
+				AccessCodeFlow("/auth", "/token")
+				Scope("read", "Read")
+			})
+			basic := BasicAuthSecurity("basic")
+			Resource("bottle", func() {
+				Security(key)
+				Action("key", func() {
+					Routing(GET("/key"))
+					Response("OK")
+				})
+				Action("jwt", func() {
+					Security(jwt)
+					Routing(GET("/jwt"))
+					Response("OK")
+				})
+				Action("oauth", func() {
+					Security(oauth, func() { Scope("read") })
+					Routing(GET("/oauth"))
+					Response("OK")
+				})
+				Action("basic", func() {
+					Security(basic)
+					Routing(GET("/basic"))
+					Response("OK")
+				})
+				Action("public", func() {
+					NoSecurity()
+					Routing(GET("/public"))
+					Response("OK")
+				})
+			})
+		})
+
+		It("secures the routes with the Kong plugins", func() {
+			Ω(confErr).ShouldNot(HaveOccurred())
+			Ω(route("bottle-key").Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name:   "key-auth",
+				Config: map[string]interface{}{"key_names": []string{"X-Key"}, "key_in_header": true, "key_in_query": false},
+			}}))
+			Ω(route("bottle-jwt").Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name:   "jwt",
+				Config: map[string]interface{}{"uri_param_names": []string{"token"}},
+			}}))
+			Ω(route("bottle-oauth").Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name: "oauth2",
+				Config: map[string]interface{}{
+					"enable_authorization_code": true,
+					"enable_implicit_grant":     false,
+					"enable_password_grant":     false,
+					"enable_client_credentials": false,
+					"scopes":                    []string{"read"},
+					"mandatory_scope":           true,
+				},
+			}}))
+			Ω(route("bottle-basic").Plugins).Should(Equal([]*gengateway.KongPlugin{{Name: "basic-auth"}}))
+			Ω(route("bottle-public").Plugins).Should(BeEmpty())
+		})
+	})
+
+	Context("with rate limits", func() {
+		BeforeEach(func() {
+			base := design.Design.DSLFunc
+			design.Design.DSLFunc = func() {
+				base()
+				Metadata("gateway:rate-limit", "1000/hour")
+			}
+			Resource("bottle", func() {
+				Metadata("gateway:rate-limit", "10/second")
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response("OK")
+				})
+				Action("create", func() {
+					Metadata("gateway:rate-limit", "1/second", "20/minute")
+					Routing(POST("/bottles"))
+					Response("OK")
+				})
+			})
+		})
+
+		It("limits the service and the routes", func() {
+			Ω(confErr).ShouldNot(HaveOccurred())
+			Ω(conf.Services[0].Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name:   "rate-limiting",
+				Config: map[string]interface{}{"hour": 1000},
+			}}))
+			Ω(route("bottle-list").Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name:   "rate-limiting",
+				Config: map[string]interface{}{"second": 10},
+			}}))
+			Ω(route("bottle-create").Plugins).Should(Equal([]*gengateway.KongPlugin{{
+				Name:   "rate-limiting",
+				Config: map[string]interface{}{"second": 1, "minute": 20},
+			}}))
+		})
+	})
+
+	Context("with an invalid rate limit", func() {
+		BeforeEach(func() {
+			Resource("bottle", func() {
+				Action("list", func() {
+					Metadata("gateway:rate-limit", "10 per second")
+					Routing(GET("/bottles"))
+					Response("OK")
+				})
+			})
+		})
+
+		It("returns an error", func() {
+			Ω(confErr).Should(HaveOccurred())
+			Ω(confErr.Error()).Should(ContainSubstring(`invalid gateway:rate-limit metadata value "10 per second"`))
+		})
+	})
+})
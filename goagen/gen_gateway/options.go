@@ -0,0 +1,27 @@
+package gengateway
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//OutDir Path to output directory
+func OutDir(outDir string) Option {
+	return func(g *Generator) {
+		g.OutDir = outDir
+	}
+}
+
+//Upstream URL of the service proxied by the gateway
+func Upstream(upstream string) Option {
+	return func(g *Generator) {
+		g.Upstream = upstream
+	}
+}
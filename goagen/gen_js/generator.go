@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -175,7 +176,7 @@ func (g *Generator) generateJS(jsFile string) (_ *design.ActionDefinition, err e
 				exampleAction = a
 			}
 			data := map[string]interface{}{"Action": a}
-			funcs := template.FuncMap{"params": params}
+			funcs := template.FuncMap{"params": params, "queryKey": queryKey}
 			if err = file.ExecuteTemplate("jsFuncs", jsFuncsT, funcs, data); err != nil {
 				return
 			}
@@ -293,6 +294,16 @@ func params(action *design.ActionDefinition) []string {
 	return params
 }
 
+// queryKey returns the JavaScript object key of the query string param with the given name,
+// see the Key DSL.
+func queryKey(action *design.ActionDefinition, name string) string {
+	key := action.QueryParams.Type.ToObject()[name].AttributeKey(name)
+	if key == name {
+		return name
+	}
+	return strconv.Quote(key)
+}
+
 const moduleT = `// This module exports functions that give access to the {{.API.Name}} API hosted at {{.API.Host}}.
 // It uses the axios javascript library for making the actual HTTP requests.
 define(['axios'] , function (axios) {
@@ -335,9 +346,11 @@ const jsFuncsT = `{{$params := params .Action}}
       method: '{{toLower (index .Action.Routes 0).Verb}}',
 {{if $params}}      params: {
 {{range $index, $param := $params}}{{if $index}},
-{{end}}        {{$param}}: {{$param}}{{end}}
+{{end}}        {{queryKey $.Action $param}}: {{$param}}{{end}}
       },
 {{end}}{{if .Action.Payload}}    data: data,
+{{end}}{{if .Action.Parent.CSRF}}      xsrfCookieName: 'csrf_token',
+      xsrfHeaderName: 'X-CSRF-Token',
 {{end}}      responseType: 'json'
     };
     if (config) {
@@ -0,0 +1,14 @@
+/*
+Package genlint provides a generator that checks the API design against a set of style rules.
+
+The generator does not produce any file, instead it fails and lists the violations found if any.
+Rules are pluggable: additional rules may be registered with Register. Violations may be suppressed
+with the "lint:ignore" metadata which accepts the names of the rules to ignore for the definition
+and the definitions it contains, for example:
+
+	Action("legacy", func() {
+		Metadata("lint:ignore", "kebab-path", "description")
+		Routing(GET("/legacyAction"))
+	})
+*/
+package genlint
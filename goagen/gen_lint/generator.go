@@ -0,0 +1,66 @@
+package genlint
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+//NewGenerator returns an initialized instance of a design linter
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the design linter.
+type Generator struct {
+	API   *design.APIDefinition // The API definition
+	Rules []*Rule               // The rules to check, defaults to all the registered rules
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var ver string
+	set := flag.NewFlagSet("lint", flag.PanicOnError)
+	set.String("out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate checks the design and returns an error listing the violations if any.
+func (g *Generator) Generate() ([]string, error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+	rules := g.Rules
+	if rules == nil {
+		rules = registered
+	}
+	violations := Lint(g.API, rules...)
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.Error()
+	}
+	return nil, fmt.Errorf("%d lint violation(s):\n%s", len(violations), strings.Join(msgs, "\n"))
+}
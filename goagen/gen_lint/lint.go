@@ -0,0 +1,115 @@
+package genlint
+
+import (
+	"fmt"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+// SuppressMetadata is the name of the metadata listing the rules that must not be checked for a
+// definition and the definitions it contains.
+const SuppressMetadata = "lint:ignore"
+
+type (
+	// Rule is a design lint rule.
+	Rule struct {
+		// Name identifies the rule in reports and suppressions, e.g. "kebab-path".
+		Name string
+		// Description describes what the rule checks.
+		Description string
+		// Check runs the rule against the API design and reports the violations.
+		Check func(api *design.APIDefinition, report Reporter)
+	}
+
+	// Reporter records a violation with the given message. defs lists the definition
+	// violating the rule first followed by its parents, they are used to build the
+	// violation context and to look up suppressions.
+	Reporter func(msg string, defs ...dslengine.Definition)
+
+	// Violation describes a rule violation.
+	Violation struct {
+		// Rule is the name of the violated rule.
+		Rule string
+		// Context describes the definition violating the rule.
+		Context string
+		// Message describes the violation.
+		Message string
+	}
+)
+
+// registered holds the rules checked by default.
+var registered []*Rule
+
+// Register adds a rule to the set of rules checked by default.
+func Register(r *Rule) {
+	registered = append(registered, r)
+}
+
+// RegisteredRules returns the rules checked by default.
+func RegisteredRules() []*Rule {
+	return append([]*Rule(nil), registered...)
+}
+
+// Lint checks the API against the given rules and returns the violations that are not
+// suppressed.
+func Lint(api *design.APIDefinition, rules ...*Rule) []*Violation {
+	var violations []*Violation
+	for _, r := range rules {
+		rule := r
+		if suppressed(rule.Name, api) {
+			continue
+		}
+		rule.Check(api, func(msg string, defs ...dslengine.Definition) {
+			for _, def := range defs {
+				if suppressed(rule.Name, def) {
+					return
+				}
+			}
+			v := &Violation{Rule: rule.Name, Message: msg}
+			for _, def := range defs {
+				if ctx := def.Context(); ctx != "" {
+					v.Context = ctx
+					break
+				}
+			}
+			violations = append(violations, v)
+		})
+	}
+	return violations
+}
+
+// Error returns a human friendly description of the violation.
+func (v *Violation) Error() string {
+	if v.Context == "" {
+		return fmt.Sprintf("%s (%s)", v.Message, v.Rule)
+	}
+	return fmt.Sprintf("%s: %s (%s)", v.Context, v.Message, v.Rule)
+}
+
+// suppressed returns true if the metadata of the given definition suppresses the given rule.
+func suppressed(rule string, def dslengine.Definition) bool {
+	var md dslengine.MetadataDefinition
+	switch actual := def.(type) {
+	case *design.APIDefinition:
+		md = actual.Metadata
+	case *design.ResourceDefinition:
+		md = actual.Metadata
+	case *design.ActionDefinition:
+		md = actual.Metadata
+	case *design.ResponseDefinition:
+		md = actual.Metadata
+	case *design.AttributeDefinition:
+		md = actual.Metadata
+	case *design.UserTypeDefinition:
+		md = actual.Metadata
+	case *design.MediaTypeDefinition:
+		md = actual.Metadata
+	}
+	for _, name := range md[SuppressMetadata] {
+		if name == rule || name == "all" {
+			return true
+		}
+	}
+	return false
+}
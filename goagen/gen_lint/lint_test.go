@@ -0,0 +1,123 @@
+package genlint_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_lint"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	var dsl func()
+	var rule *genlint.Rule
+	var violations []*genlint.Violation
+
+	JustBeforeEach(func() {
+		dslengine.Reset()
+		API("test", nil)
+		Resource("bottle", dsl)
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		violations = genlint.Lint(Design, rule)
+	})
+
+	Context("with the kebab-path rule", func() {
+		BeforeEach(func() {
+			rule = genlint.KebabPath
+			dsl = func() {
+				BasePath("/bottles")
+				Action("show", func() {
+					Routing(GET("/:id/wine-list"))
+				})
+				Action("list", func() {
+					Routing(GET("/allBottles"))
+				})
+			}
+		})
+
+		It("reports the non kebab-case segments", func() {
+			Ω(violations).Should(HaveLen(1))
+			Ω(violations[0].Rule).Should(Equal("kebab-path"))
+			Ω(violations[0].Context).Should(Equal(`resource "bottle" action "list"`))
+			Ω(violations[0].Message).Should(ContainSubstring(`"allBottles"`))
+		})
+
+		Context("with a suppression", func() {
+			BeforeEach(func() {
+				dsl = func() {
+					Action("list", func() {
+						Metadata("lint:ignore", "kebab-path")
+						Routing(GET("/allBottles"))
+					})
+				}
+			})
+
+			It("does not report the violation", func() {
+				Ω(violations).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with the unbounded-array rule", func() {
+		BeforeEach(func() {
+			rule = genlint.UnboundedArray
+			dsl = func() {
+				Action("create", func() {
+					Routing(POST(""))
+					Payload(func() {
+						Attribute("tags", ArrayOf(String))
+						Attribute("ids", ArrayOf(Integer), func() { MaxLength(10) })
+					})
+				})
+			}
+		})
+
+		It("reports the arrays with no maximum length", func() {
+			Ω(violations).Should(HaveLen(1))
+			Ω(violations[0].Message).Should(Equal("array payload.tags has no maximum length"))
+		})
+	})
+
+	Context("with the description rule", func() {
+		BeforeEach(func() {
+			rule = genlint.MissingDescription
+			dsl = func() {
+				Description("Bottles")
+				Action("show", func() {
+					Routing(GET(""))
+				})
+			}
+		})
+
+		It("reports the missing descriptions", func() {
+			Ω(violations).Should(HaveLen(2))
+			Ω(violations[0].Context).Should(Equal(`API "test"`))
+			Ω(violations[1].Context).Should(Equal(`resource "bottle" action "show"`))
+		})
+	})
+
+	Context("with the error-usage rule", func() {
+		BeforeEach(func() {
+			rule = genlint.InconsistentErrors
+			dsl = func() {
+				Action("show", func() {
+					Routing(GET("/:id"))
+					Response(NotFound, ErrorMedia)
+					Response(BadRequest, ErrorMedia)
+				})
+				Action("create", func() {
+					Routing(POST(""))
+					Response(BadRequest, func() {
+						Media("application/json")
+					})
+				})
+			}
+		})
+
+		It("reports the responses that do not use the common error media type", func() {
+			Ω(violations).Should(HaveLen(1))
+			Ω(violations[0].Context).Should(ContainSubstring(`action "create"`))
+		})
+	})
+})
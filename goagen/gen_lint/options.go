@@ -0,0 +1,20 @@
+package genlint
+
+import "github.com/goadesign/goa/design"
+
+//Option a generator option definition
+type Option func(*Generator)
+
+//API The API definition
+func API(API *design.APIDefinition) Option {
+	return func(g *Generator) {
+		g.API = API
+	}
+}
+
+//Rules The rules checked by the generator
+func Rules(rules ...*Rule) Option {
+	return func(g *Generator) {
+		g.Rules = rules
+	}
+}
@@ -0,0 +1,198 @@
+package genlint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+)
+
+var (
+	// MissingDescription checks that the API, resources, actions and types are described.
+	MissingDescription = &Rule{
+		Name:        "description",
+		Description: "API, resources, actions and types must have a description",
+		Check:       checkDescriptions,
+	}
+
+	// InconsistentErrors checks that all the error responses use the same media type.
+	InconsistentErrors = &Rule{
+		Name:        "error-usage",
+		Description: "error responses must all use the same media type",
+		Check:       checkErrors,
+	}
+
+	// KebabPath checks that the route path segments are kebab-case.
+	KebabPath = &Rule{
+		Name:        "kebab-path",
+		Description: "route path segments must be lower case words separated with dashes",
+		Check:       checkPaths,
+	}
+
+	// UnboundedArray checks that the array request parameters and payload attributes define
+	// a maximum length.
+	UnboundedArray = &Rule{
+		Name:        "unbounded-array",
+		Description: "array params and payload attributes must define a maximum length",
+		Check:       checkArrays,
+	}
+)
+
+// kebabRegex matches kebab-case path segments.
+var kebabRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func init() {
+	Register(MissingDescription)
+	Register(InconsistentErrors)
+	Register(KebabPath)
+	Register(UnboundedArray)
+}
+
+func checkDescriptions(api *design.APIDefinition, report Reporter) {
+	if api.Description == "" {
+		report("missing description", api)
+	}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		if res.Description == "" {
+			report("missing description", res)
+		}
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Description == "" {
+				report("missing description", a, res)
+			}
+			return nil
+		})
+	})
+	api.IterateMediaTypes(func(mt *design.MediaTypeDefinition) error {
+		if !mt.IsError() && mt.Description == "" {
+			report("missing description", mt)
+		}
+		return nil
+	})
+	api.IterateUserTypes(func(ut *design.UserTypeDefinition) error {
+		if ut.Description == "" {
+			report("missing description", ut)
+		}
+		return nil
+	})
+}
+
+func checkErrors(api *design.APIDefinition, report Reporter) {
+	type errorResponse struct {
+		resp *design.ResponseDefinition
+		a    *design.ActionDefinition
+	}
+	var (
+		resps  []*errorResponse
+		counts = make(map[string]int)
+	)
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			return a.IterateResponses(func(r *design.ResponseDefinition) error {
+				if r.Status < 400 || r.MediaType == "" {
+					return nil
+				}
+				resps = append(resps, &errorResponse{r, a})
+				counts[r.MediaType]++
+				return nil
+			})
+		})
+	})
+	if len(counts) < 2 {
+		return
+	}
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	common := ids[0]
+	for _, id := range ids[1:] {
+		if counts[id] > counts[common] {
+			common = id
+		}
+	}
+	for _, r := range resps {
+		if r.resp.MediaType != common {
+			report(fmt.Sprintf("error response uses media type %s, other error responses use %s", r.resp.MediaType, common),
+				r.resp, r.a, r.a.Parent)
+		}
+	}
+}
+
+func checkPaths(api *design.APIDefinition, report Reporter) {
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for _, r := range a.Routes {
+				for _, seg := range strings.Split(r.FullPath(), "/") {
+					if seg == "" || strings.HasPrefix(seg, ":") || strings.HasPrefix(seg, "*") {
+						continue
+					}
+					if !kebabRegex.MatchString(seg) {
+						report(fmt.Sprintf("path segment %q of route %s %s is not kebab-case", seg, r.Verb, r.FullPath()), a, res)
+					}
+				}
+			}
+			return nil
+		})
+	})
+}
+
+func checkArrays(api *design.APIDefinition, report Reporter) {
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if a.Params != nil {
+				walkArrays("params", a.Params, nil, func(path string, att *design.AttributeDefinition) {
+					report(fmt.Sprintf("array %s has no maximum length", path), att, a, res)
+				})
+			}
+			if a.Payload != nil {
+				walkArrays("payload", a.Payload.AttributeDefinition, nil, func(path string, att *design.AttributeDefinition) {
+					report(fmt.Sprintf("array %s has no maximum length", path), att, a, res)
+				})
+			}
+			return nil
+		})
+	})
+}
+
+// walkArrays calls found for each array attribute under att that does not define a maximum
+// length. seen contains the names of the user types being walked to break cycles.
+func walkArrays(path string, att *design.AttributeDefinition, seen map[string]bool, found func(string, *design.AttributeDefinition)) {
+	switch actual := att.Type.(type) {
+	case *design.Array:
+		if att.Validation == nil || att.Validation.MaxLength == nil {
+			found(path, att)
+		}
+		walkArrays(path+"[]", actual.ElemType, seen, found)
+	case *design.Hash:
+		walkArrays(path+"{}", actual.ElemType, seen, found)
+	case design.Object:
+		names := make([]string, 0, len(actual))
+		for n := range actual {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			walkArrays(path+"."+n, actual[n], seen, found)
+		}
+	case *design.UserTypeDefinition:
+		walkUserType(path, actual, seen, found)
+	case *design.MediaTypeDefinition:
+		walkUserType(path, actual.UserTypeDefinition, seen, found)
+	}
+}
+
+// walkUserType walks the attributes of the given user type unless it is already being walked.
+func walkUserType(path string, ut *design.UserTypeDefinition, seen map[string]bool, found func(string, *design.AttributeDefinition)) {
+	if seen[ut.TypeName] {
+		return
+	}
+	s := map[string]bool{ut.TypeName: true}
+	for n := range seen {
+		s[n] = true
+	}
+	walkArrays(path, ut.AttributeDefinition, s, found)
+}
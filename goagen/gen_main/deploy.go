@@ -0,0 +1,167 @@
+package genmain
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/goadesign/goa/design"
+)
+
+// generateDeployment generates the Dockerfile that builds the service image and the Kubernetes
+// manifests that deploy it. Existing files are preserved unless Force is true.
+func (g *Generator) generateDeployment() error {
+	name := deploymentName(g.API.Name)
+	data := map[string]interface{}{
+		"API":   g.API,
+		"Name":  name,
+		"Image": name + ":latest",
+		"Port":  servicePort(g.API),
+		"TLS":   usesTLS(g.API),
+	}
+	if paths := g.API.HealthChecks(); len(paths) > 0 {
+		data["HealthCheck"] = paths[0]
+	}
+	if err := g.writeDeploymentFile(filepath.Join(g.OutDir, "Dockerfile"), dockerfileT, data); err != nil {
+		return err
+	}
+	return g.writeDeploymentFile(filepath.Join(g.OutDir, "kubernetes.yaml"), kubernetesT, data)
+}
+
+// writeDeploymentFile renders the given template in the file with the given name.
+func (g *Generator) writeDeploymentFile(filename, tmpl string, data map[string]interface{}) (err error) {
+	if g.Force {
+		os.Remove(filename)
+	}
+	if _, err := os.Stat(filename); err == nil {
+		return nil
+	}
+	t, err := template.New(filepath.Base(filename)).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(g.OutDir, 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	g.genfiles = append(g.genfiles, filename)
+	return t.Execute(f, data)
+}
+
+// servicePort returns the port the generated main listens on: the port of the API host or of the
+// first server host, 8080 if none defines one.
+func servicePort(api *design.APIDefinition) string {
+	hosts := []string{api.Host}
+	if len(api.Servers) > 0 {
+		hosts = append(hosts, api.Servers[0].Host)
+	}
+	for _, host := range hosts {
+		if _, port, err := net.SplitHostPort(host); err == nil && port != "" {
+			return port
+		}
+	}
+	return "8080"
+}
+
+// usesTLS returns true if the API is served over HTTPS.
+func usesTLS(api *design.APIDefinition) bool {
+	for _, scheme := range api.Schemes {
+		if scheme == "https" {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidNameChars matches the characters that cannot be used in Kubernetes object names.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// deploymentName returns the Kubernetes object name built from the given API name.
+func deploymentName(name string) string {
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "service"
+	}
+	return name
+}
+
+const dockerfileT = `# Build the {{ .API.Name }} service image from the directory containing its main package:
+#
+#    docker build -t {{ .Image }} .
+#
+FROM golang:alpine AS build
+WORKDIR /src
+COPY . .
+RUN CGO_ENABLED=0 go build -o /{{ .Name }} .
+
+FROM alpine
+WORKDIR /srv
+COPY --from=build /{{ .Name }} /srv/{{ .Name }}
+{{ if .TLS }}COPY --from=build /src/cert.pem /src/key.pem /srv/
+{{ end }}EXPOSE {{ .Port }}
+ENTRYPOINT ["/srv/{{ .Name }}"]
+`
+
+const kubernetesT = `# Kubernetes manifests deploying the {{ .API.Name }} service image built with the Dockerfile,
+# apply with:
+#
+#    kubectl apply -f kubernetes.yaml
+#
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Name }}
+    spec:
+      containers:
+      - name: {{ .Name }}
+        image: {{ .Image }}
+        ports:
+        - containerPort: {{ .Port }}
+{{- with .HealthCheck }}
+        livenessProbe:
+          httpGet:
+            path: {{ printf "%q" . }}
+            port: {{ $.Port }}
+            scheme: {{ if $.TLS }}HTTPS{{ else }}HTTP{{ end }}
+        readinessProbe:
+          httpGet:
+            path: {{ printf "%q" . }}
+            port: {{ $.Port }}
+            scheme: {{ if $.TLS }}HTTPS{{ else }}HTTP{{ end }}
+{{- end }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  labels:
+    app: {{ .Name }}
+spec:
+  selector:
+    app: {{ .Name }}
+  ports:
+  - port: {{ if .TLS }}443{{ else }}80{{ end }}
+    targetPort: {{ .Port }}
+`
@@ -5,5 +5,12 @@ bootstrap new applications.
 The generator creates a main.go file and one file per resource listed in the API metadata.
 If a file already exists it skips its creation unless the flag --force is provided on the command
 line in which case it overrides the content of existing files.
+The flag --regen regenerates the controller files preserving the action implementations and the
+declarations added to the files. Scaffolded files that were renamed are not generated again.
+The flag --stubs makes the scaffolded actions return a "not implemented" error instead of a
+default response so that the actions that are not implemented yet do not succeed silently.
+The flag --http3 generates a main that also serves HTTP/3 over QUIC using quic-go when it is run
+with the -http3 flag. The HTTP/1.1 and HTTP/2 listener then advertises the HTTP/3 endpoint in the
+Alt-Svc header of its responses.
 */
 package genmain
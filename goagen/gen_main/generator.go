@@ -2,12 +2,13 @@ package genmain
 
 import (
 	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"net"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
@@ -39,14 +40,17 @@ type Generator struct {
 	Target    string                // Name of generated "app" package
 	Force     bool                  // Whether to override existing files
 	Regen     bool                  // Whether to regenerate scaffolding in place, maintaining controller implementation
+	Stubs     bool                  // Whether the scaffolded actions return a "not implemented" error
+	Deploy    bool                  // Whether to generate a Dockerfile and Kubernetes manifests
+	HTTP3     bool                  // Whether the generated main may also serve HTTP/3 over QUIC
 	genfiles  []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, toolDir, designPkg, target, ver string
-		force, notool, regen                    bool
+		outDir, toolDir, designPkg, target, ver, services string
+		force, notool, regen, stubs, deploy, http3        bool
 	)
 
 	set := flag.NewFlagSet("main", flag.PanicOnError)
@@ -58,40 +62,53 @@ func Generate() (files []string, err error) {
 	set.BoolVar(&notool, "notool", false, "")
 	set.BoolVar(&force, "force", false, "")
 	set.BoolVar(&regen, "regen", false, "")
+	set.BoolVar(&stubs, "stubs", false, "")
+	set.BoolVar(&deploy, "deploy", false, "")
+	set.BoolVar(&http3, "http3", false, "")
+	set.String("app-pkg", "", "")
 	set.Bool("notest", false, "")
+	set.String("di", "", "")
+	set.Bool("split", false, "")
+	set.StringVar(&services, "service", "", "")
 	set.Parse(os.Args[1:])
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
+	api, err := codegen.SelectedAPI(services)
+	if err != nil {
+		return nil, err
+	}
+
 	target = codegen.Goify(target, false)
-	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, API: design.Design}
+	g := &Generator{OutDir: outDir, DesignPkg: designPkg, Target: target, Force: force, Regen: regen, Stubs: stubs, Deploy: deploy, HTTP3: http3, API: api}
 
 	return g.Generate()
 }
 
-func extractControllerBody(filename string) (map[string]string, []*ast.ImportSpec, error) {
+// extractControllerBody returns the action implementations, the imports and the source of the
+// declarations added by the user to the existing controller file of the given resource if any.
+func extractControllerBody(filename string, r *design.ResourceDefinition) (map[string]string, []*ast.ImportSpec, []string, error) {
 	// First check if a file is there. If not, return empty results to let generation proceed.
 	if _, e := os.Stat(filename); e != nil {
-		return map[string]string{}, []*ast.ImportSpec{}, nil
+		return map[string]string{}, []*ast.ImportSpec{}, nil, nil
 	}
-	fset := token.NewFileSet()
-	pfile, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly)
+	src, err := ioutil.ReadFile(filename)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	f, err := os.Open(filename)
+	fset := token.NewFileSet()
+	pfile, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	defer f.Close()
 	var (
 		inBlock bool
 		block   []string
 	)
 	actionImpls := map[string]string{}
-	scanner := bufio.NewScanner(f)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
 	for scanner.Scan() {
 		line := scanner.Text()
 		match := linePattern.FindStringSubmatch(line)
@@ -111,21 +128,130 @@ func extractControllerBody(filename string) (map[string]string, []*ast.ImportSpe
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+	var decls []string
+	if f, err := parser.ParseFile(fset, filename, src, parser.ParseComments); err == nil {
+		// Only files that parse can have their other declarations preserved.
+		decls = userDecls(fset, f, src, r, actionImpls)
+	}
+	return actionImpls, pfile.Imports, decls, nil
+}
+
+// userDecls returns the source of the top level declarations of the given controller file that
+// are not generated: the declarations other than the controller type, its constructor and the
+// methods implementing the actions.
+func userDecls(fset *token.FileSet, f *ast.File, src []byte, r *design.ResourceDefinition, actionImpls map[string]string) []string {
+	ctrlName := codegen.Goify(r.Name, true) + "Controller"
+	generated := map[string]bool{}
+	r.IterateActions(func(a *design.ActionDefinition) error {
+		generated[codegen.Goify(a.Name, true)] = true
+		return nil
+	})
+	for descr := range actionImpls {
+		if strings.HasPrefix(descr, ctrlName+"_") {
+			generated[strings.TrimPrefix(descr, ctrlName+"_")] = true
+		}
+	}
+	var decls []string
+	for _, d := range f.Decls {
+		var doc *ast.CommentGroup
+		switch actual := d.(type) {
+		case *ast.GenDecl:
+			if actual.Tok == token.IMPORT {
+				continue
+			}
+			if actual.Tok == token.TYPE && len(actual.Specs) == 1 {
+				if ts, ok := actual.Specs[0].(*ast.TypeSpec); ok && ts.Name.Name == ctrlName {
+					continue
+				}
+			}
+			doc = actual.Doc
+		case *ast.FuncDecl:
+			if actual.Recv == nil {
+				if actual.Name.Name == "New"+ctrlName {
+					continue
+				}
+			} else if recvName(actual.Recv) == ctrlName {
+				name := strings.TrimSuffix(actual.Name.Name, "WSHandler")
+				if generated[name] {
+					continue
+				}
+			}
+			doc = actual.Doc
+		}
+		start := d.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		decls = append(decls, string(src[fset.Position(start).Offset:fset.Position(d.End()).Offset]))
+	}
+	return decls
+}
+
+// recvName returns the name of the type of the given method receiver.
+func recvName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	t := recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if id, ok := t.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// declaredElsewhere returns true if a Go file of dir other than filename declares the type or
+// function with the given name. This prevents generating duplicate declarations when a scaffolded
+// file was renamed.
+func declaredElsewhere(dir, filename, name string) (bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return false, err
+	}
+	for _, p := range paths {
+		if p == filename || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), p, nil, 0)
+		if err != nil {
+			// Not our business, the file does not compile anyway.
+			continue
+		}
+		for _, d := range f.Decls {
+			switch actual := d.(type) {
+			case *ast.GenDecl:
+				for _, spec := range actual.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == name {
+						return true, nil
+					}
+				}
+			case *ast.FuncDecl:
+				if actual.Recv == nil && actual.Name.Name == name {
+					return true, nil
+				}
+			}
+		}
 	}
-	return actionImpls, pfile.Imports, nil
+	return false, nil
 }
 
 // GenerateController generates the controller corresponding to the given
-// resource and returns the generated filename.
-func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *design.ResourceDefinition) (filename string, err error) {
+// resource and returns the generated filename. The actions of the controller
+// return a "not implemented" error if stubs is true, a default response
+// otherwise.
+func GenerateController(force, regen, stubs bool, appPkg, outDir, pkg, name string, r *design.ResourceDefinition) (filename string, err error) {
 	filename = filepath.Join(outDir, codegen.SnakeCase(name)+".go")
 	var (
 		actionImpls      map[string]string
 		extractedImports []*ast.ImportSpec
+		extractedDecls   []string
 	)
 	if regen {
-		actionImpls, extractedImports, err = extractControllerBody(filename)
+		actionImpls, extractedImports, extractedDecls, err = extractControllerBody(filename, r)
 		if err != nil {
 			return "", err
 		}
@@ -137,6 +263,10 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	if _, e := os.Stat(filename); e == nil {
 		return "", nil
 	}
+	ctrlName := codegen.Goify(r.Name, true) + "Controller"
+	if moved, err := declaredElsewhere(outDir, filename, ctrlName); err != nil || moved {
+		return "", err
+	}
 	if err = os.MkdirAll(outDir, 0755); err != nil {
 		return "", err
 	}
@@ -167,7 +297,9 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	}
 
 	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("fmt"),
 		codegen.SimpleImport("io"),
+		codegen.SimpleImport("time"),
 		codegen.SimpleImport("github.com/goadesign/goa"),
 		codegen.SimpleImport(imp),
 		codegen.SimpleImport("golang.org/x/net/websocket"),
@@ -185,7 +317,7 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		imports = append(imports, cgimp)
 	}
 
-	funcs := funcMap(pkgName, actionImpls)
+	funcs := funcMap(pkgName, actionImpls, stubs)
 	if err = file.WriteHeader("", pkg, imports); err != nil {
 		return "", err
 	}
@@ -193,6 +325,10 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 		return "", err
 	}
 	err = r.IterateActions(func(a *design.ActionDefinition) error {
+		if a.ProxyURL != "" {
+			// Proxied actions are forwarded upstream by the generated handlers.
+			return nil
+		}
 		if a.WebSocket() {
 			return file.ExecuteTemplate("actionWS", actionWST, funcs, a)
 		}
@@ -201,6 +337,11 @@ func GenerateController(force, regen bool, appPkg, outDir, pkg, name string, r *
 	if err != nil {
 		return "", err
 	}
+	for _, d := range extractedDecls {
+		if _, err = file.Write([]byte("\n" + d + "\n")); err != nil {
+			return "", err
+		}
+	}
 	return
 }
 
@@ -228,19 +369,28 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if g.Force {
 		os.Remove(mainFile)
 	}
-	_, err = os.Stat(mainFile)
+	moved, err := declaredElsewhere(g.OutDir, mainFile, "main")
 	if err != nil {
+		return nil, err
+	}
+	if _, err = os.Stat(mainFile); err != nil && !moved {
 		// ensure that the output directory exists before creating a new main
 		if err = os.MkdirAll(g.OutDir, 0755); err != nil {
 			return nil, err
 		}
-		if err = g.createMainFile(mainFile, funcMap(g.Target, nil)); err != nil {
+		if err = g.createMainFile(mainFile, funcMap(g.Target, nil, false)); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.Deploy {
+		if err = g.generateDeployment(); err != nil {
 			return nil, err
 		}
 	}
 
 	err = g.API.IterateResources(func(r *design.ResourceDefinition) error {
-		filename, err := GenerateController(g.Force, g.Regen, g.Target, g.OutDir, "main", r.Name, r)
+		filename, err := GenerateController(g.Force, g.Regen, g.Stubs, g.Target, g.OutDir, "main", r.Name, r)
 		if err != nil {
 			return err
 		}
@@ -276,13 +426,6 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		}
 	}()
 	g.genfiles = append(g.genfiles, mainFile)
-	funcs["getPort"] = func(hostport string) string {
-		_, port, err := net.SplitHostPort(hostport)
-		if err != nil {
-			return "8080"
-		}
-		return port
-	}
 	outPkg, err := codegen.PackagePath(g.OutDir)
 	if err != nil {
 		return err
@@ -294,25 +437,45 @@ func (g *Generator) createMainFile(mainFile string, funcs template.FuncMap) (err
 		codegen.SimpleImport("github.com/goadesign/goa/middleware"),
 		codegen.SimpleImport(appPkg),
 	}
+	if g.HTTP3 {
+		imports = append(imports,
+			codegen.SimpleImport("flag"),
+			codegen.SimpleImport("net/http"),
+			codegen.SimpleImport("github.com/quic-go/quic-go/http3"),
+		)
+	}
 	file.Write([]byte("//go:generate goagen bootstrap -d " + g.DesignPkg + "\n\n"))
 	if err = file.WriteHeader("", "main", imports); err != nil {
 		return err
 	}
-	tls := false
-	for _, scheme := range g.API.Schemes {
-		if scheme == "https" {
-			tls = true
-		}
-	}
 	data := map[string]interface{}{
-		"Name": g.API.Name,
-		"API":  g.API,
-		"TLS":  tls,
+		"Name":         g.API.Name,
+		"API":          g.API,
+		"TLS":          usesTLS(g.API),
+		"Port":         servicePort(g.API),
+		"HealthChecks": g.API.HealthChecks(),
+		"HTTP3":        g.HTTP3,
+		"RequestID":    requestIDMiddleware(g.API),
 	}
 	err = file.ExecuteTemplate("main", mainT, funcs, data)
 	return
 }
 
+// requestIDMiddleware returns the code that creates the request ID middleware of the service given
+// the format of the request IDs defined in the design.
+func requestIDMiddleware(api *design.APIDefinition) string {
+	switch api.RequestIDFormat {
+	case design.RequestIDUUIDv7:
+		return "middleware.RequestIDWithFunc(middleware.UUIDv7)"
+	case design.RequestIDULID:
+		return "middleware.RequestIDWithFunc(middleware.ULID)"
+	case design.RequestIDSnowflake:
+		return "middleware.RequestIDWithFunc(middleware.NewSnowflake(0))"
+	default:
+		return "middleware.RequestID()"
+	}
+}
+
 // tempCount is the counter used to create unique temporary variable names.
 var tempCount int
 
@@ -377,11 +540,12 @@ func okResp(a *design.ActionDefinition, appPkg string) map[string]interface{} {
 }
 
 // funcMap creates the funcMap used to render the controller code.
-func funcMap(appPkg string, actionImpls map[string]string) template.FuncMap {
+func funcMap(appPkg string, actionImpls map[string]string, stubs bool) template.FuncMap {
 	return template.FuncMap{
 		"tempvar":   tempvar,
 		"okResp":    okResp,
 		"targetPkg": func() string { return appPkg },
+		"stubs":     func() bool { return stubs },
 		"actionBody": func(name string) string {
 			body, ok := actionImpls[name]
 			if !ok {
@@ -420,10 +584,12 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .
 
 	{{ actionBody $actionDescr }}
 
-{{ if printResp $actionDescr }}
+{{ if printResp $actionDescr }}{{ if stubs }}
+	return fmt.Errorf("not implemented")
+{{ else }}
 {{ $ok := okResp . targetPkg }}{{ if $ok }} res := {{ $ok.TypeRef }}
 {{ end }} return {{ if $ok }}ctx.{{ $ok.Name }}(res){{ else }}nil{{ end }}
-{{ end }}	// {{ $actionDescr }}: end_implement
+{{ end }}{{ end }}	// {{ $actionDescr }}: end_implement
 }
 `
 
@@ -432,7 +598,10 @@ const actionWST = `
 {{- $actionDescr := printf "%s_%s" $ctrlName (goify .Name true) -}}
 // {{ goify .Name true }} runs the {{ .Name }} action.
 func (c *{{ $ctrlName }}) {{ goify .Name true }}(ctx *{{ targetPkg }}.{{ goify .Name true }}{{ goify .Parent.Name true }}Context) error {
-	c.{{ goify .Name true }}WSHandler(ctx).ServeHTTP(ctx.ResponseWriter, ctx.Request)
+	c.Service.ServeWebSocket(ctx, c.{{ goify .Name true }}WSHandler(ctx), &goa.WebSocketOptions{
+		PingInterval: 30 * time.Second,
+		IdleTimeout:  time.Minute,
+	})
 	return nil
 }
 
@@ -452,30 +621,59 @@ func (c *{{ $ctrlName }}) {{ goify .Name true }}WSHandler(ctx *{{ targetPkg }}.{
 
 const mainT = `
 func main() {
-	// Create service
+{{ if .HTTP3 }}	useHTTP3 := flag.Bool("http3", false, "also serve HTTP/3 over QUIC and advertise it with the Alt-Svc header")
+	flag.Parse()
+
+{{ end }}	// Create service
 	service := goa.New({{ printf "%q" .Name }})
 
 	// Mount middleware
-	service.Use(middleware.RequestID())
+{{ if eq .API.RequestIDFormat "snowflake" }}	// TODO: use a distinct node number between 0 and 1023 for each instance of the service
+{{ end }}	service.Use({{ .RequestID }})
 	service.Use(middleware.LogRequest(true))
 	service.Use(middleware.ErrorHandler(service, true))
 	service.Use(middleware.Recover())
 {{ $api := .API }}
 {{ range $name, $res := $api.Resources }}{{ $name := goify $res.Name true }} // Mount "{{$res.Name}}" controller
 	{{ $tmp := tempvar }}{{ $tmp }} := New{{ $name }}Controller(service)
-	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }})
+	{{ targetPkg }}.Mount{{ $name }}Controller(service, {{ $tmp }}{{ with $res.AllMiddleware }}, {{ targetPkg }}.{{ $name }}Middleware{
+{{ range . }}		// TODO: replace with the implementation of the {{ printf "%q" . }} middleware
+		{{ goify . true }}: func(h goa.Handler) goa.Handler { return h },
+{{ end }}	}{{ end }})
+{{ end }}{{ if $api.JSONRPCPath }}
+	// Mount JSON-RPC endpoint
+	{{ targetPkg }}.MountJSONRPC(service)
+{{ end }}{{ if .HealthChecks }}
+	// Mount health check endpoints
+	{{ targetPkg }}.MountHealthChecks(service)
 {{ end }}
 
-{{ if .TLS }}
 	// Start service
-	if err := service.ListenAndServeTLS(":{{ getPort .API.Host }}", "cert.pem", "key.pem"); err != nil {
+{{ if .HTTP3 }}	if *useHTTP3 {
+		h3 := &http3.Server{Addr: ":{{ .Port }}", Handler: service.Mux}
+		go func() {
+			service.LogInfo("listen", "transport", "http3", "addr", h3.Addr)
+			if err := h3.ListenAndServeTLS("cert.pem", "key.pem"); err != nil {
+				service.LogError("startup", "err", err)
+			}
+		}()
+		// Advertise the HTTP/3 endpoint to the HTTP/1.1 and HTTP/2 clients.
+		handler := service.Server.Handler
+		service.Server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h3.SetQUICHeaders(w.Header())
+			handler.ServeHTTP(w, r)
+		})
+		// HTTP/3 requires TLS, serve the other protocols over TLS as well.
+		if err := service.ListenAndServeTLS(":{{ .Port }}", "cert.pem", "key.pem"); err != nil {
+			service.LogError("startup", "err", err)
+		}
+		return
+	}
+{{ end }}{{ if .TLS }}	if err := service.ListenAndServeTLS(":{{ .Port }}", "cert.pem", "key.pem"); err != nil {
 		service.LogError("startup", "err", err)
 	}
-{{ else }}
-	// Start service
-	if err := service.ListenAndServe(":{{ getPort .API.Host }}"); err != nil {
+{{ else }}	if err := service.ListenAndServe(":{{ .Port }}"); err != nil {
 		service.LogError("startup", "err", err)
 	}
-{{ end }}
-}
+{{ end }}}
 `
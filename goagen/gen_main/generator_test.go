@@ -75,6 +75,120 @@ var _ = Describe("Generate", func() {
 			})
 
 		})
+
+		Context("with HTTP/3", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--http3")
+			})
+
+			It("generates a main serving HTTP/3 when requested", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(`"github.com/quic-go/quic-go/http3"`))
+				Ω(string(content)).Should(ContainSubstring(`useHTTP3 := flag.Bool("http3", false,`))
+				Ω(string(content)).Should(ContainSubstring(`h3 := &http3.Server{Addr: ":8080", Handler: service.Mux}`))
+				Ω(string(content)).Should(ContainSubstring("h3.SetQUICHeaders(w.Header())"))
+				Ω(string(content)).Should(ContainSubstring(http3ListenAndServeTLSCode))
+			})
+
+			It("still serves plain HTTP when HTTP/3 is disabled at runtime", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(listenAndServeCode))
+				Ω(string(content)).ShouldNot(ContainSubstring(listenAndServeTLSCode))
+			})
+
+			Context("via HTTPS", func() {
+				BeforeEach(func() {
+					design.Design.Schemes = []string{"https"}
+				})
+
+				It("serves TLS when HTTP/3 is disabled at runtime", func() {
+					Ω(genErr).Should(BeNil())
+					content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+					Ω(err).ShouldNot(HaveOccurred())
+					Ω(string(content)).Should(ContainSubstring(http3ListenAndServeTLSCode))
+					Ω(string(content)).Should(ContainSubstring(listenAndServeTLSCode))
+				})
+			})
+		})
+	})
+
+	Context("with a JSON-RPC endpoint", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:        "test api",
+				JSONRPCPath: "/rpc",
+			}
+		})
+
+		It("mounts the endpoint", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(".MountJSONRPC(service)"))
+		})
+	})
+
+	Context("with a request ID format", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name:            "test api",
+				RequestIDFormat: design.RequestIDSnowflake,
+			}
+		})
+
+		It("uses the corresponding request ID function", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring("service.Use(middleware.RequestIDWithFunc(middleware.NewSnowflake(0)))"))
+			Ω(string(content)).ShouldNot(ContainSubstring("service.Use(middleware.RequestID())"))
+		})
+	})
+
+	Context("with servers defining health checks", func() {
+		BeforeEach(func() {
+			design.Design = &design.APIDefinition{
+				Name: "Test API",
+				Servers: []*design.ServerDefinition{
+					{Name: "dev", Host: "localhost:8088", HealthCheck: "/healthz"},
+				},
+			}
+		})
+
+		It("mounts the health check endpoints", func() {
+			Ω(genErr).Should(BeNil())
+			content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(ContainSubstring(".MountHealthChecks(service)"))
+			Ω(string(content)).Should(ContainSubstring(`service.ListenAndServe(":8088")`))
+		})
+
+		It("does not generate the deployment artifacts", func() {
+			Ω(files).ShouldNot(ContainElement(filepath.Join(outDir, "Dockerfile")))
+		})
+
+		Context("with deployment artifacts", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--deploy")
+			})
+
+			It("generates the Dockerfile and Kubernetes manifests", func() {
+				Ω(genErr).Should(BeNil())
+				Ω(files).Should(ContainElement(filepath.Join(outDir, "Dockerfile")))
+				Ω(files).Should(ContainElement(filepath.Join(outDir, "kubernetes.yaml")))
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "Dockerfile"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("EXPOSE 8088\n"))
+				content, err = ioutil.ReadFile(filepath.Join(outDir, "kubernetes.yaml"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring(deploymentProbesCode))
+				Ω(string(content)).Should(ContainSubstring("  name: test-api\n"))
+			})
+		})
 	})
 
 	Context("with resources", func() {
@@ -112,6 +226,34 @@ var _ = Describe("Generate", func() {
 			Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*// Put your logic here\s*return nil\s*// FirstController_Alpha: end_implement`))
 		})
 
+		Context("with middleware", func() {
+			BeforeEach(func() {
+				resource.Middleware = []string{"rate-limit"}
+			})
+
+			It("mounts the controller with pass-through middleware", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "main.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(MatchRegexp(`\.MountFirstController\(service, \w+, \w+\.FirstMiddleware\{`))
+				Ω(string(content)).Should(ContainSubstring(`RateLimit: func(h goa.Handler) goa.Handler { return h },`))
+			})
+		})
+
+		Context("with stubs", func() {
+			BeforeEach(func() {
+				os.Args = append(os.Args, "--stubs")
+			})
+
+			It("generates actions returning a not implemented error", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(content).Should(MatchRegexp(`// FirstController_Alpha: start_implement\s*// Put your logic here\s*return fmt.Errorf\("not implemented"\)\s*// FirstController_Alpha: end_implement`))
+				Ω(string(content)).Should(MatchRegexp(`import \(\s*[^)]*\"fmt\"`))
+			})
+		})
+
 		Context("regenerated with a new resource", func() {
 			BeforeEach(func() {
 				// Perform a first generation
@@ -185,6 +327,47 @@ var _ = Describe("Generate", func() {
 			})
 		})
 
+		Context("regenerated after adding declarations", func() {
+			BeforeEach(func() {
+				files, genErr = genmain.Generate()
+				Ω(genErr).ShouldNot(HaveOccurred())
+				existing, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				existing = append(existing, []byte("\n// helper helps.\nfunc helper() string { return \"help\" }\n")...)
+				err = ioutil.WriteFile(filepath.Join(outDir, "first.go"), existing, os.ModePerm)
+				Ω(err).ShouldNot(HaveOccurred())
+				os.Args = append(os.Args, "--regen")
+			})
+
+			It("preserves the declarations", func() {
+				Ω(genErr).Should(BeNil())
+				content, err := ioutil.ReadFile(filepath.Join(outDir, "first.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(string(content)).Should(ContainSubstring("// helper helps.\nfunc helper() string { return \"help\" }"))
+				Ω(strings.Count(string(content), "func NewFirstController(")).Should(Equal(1))
+			})
+		})
+
+		Context("regenerated after renaming the files", func() {
+			BeforeEach(func() {
+				files, genErr = genmain.Generate()
+				Ω(genErr).ShouldNot(HaveOccurred())
+				err := os.Rename(filepath.Join(outDir, "first.go"), filepath.Join(outDir, "controllers.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				err = os.Rename(filepath.Join(outDir, "main.go"), filepath.Join(outDir, "service.go"))
+				Ω(err).ShouldNot(HaveOccurred())
+				os.Args = append(os.Args, "--force")
+			})
+
+			It("does not generate the files again", func() {
+				Ω(genErr).Should(BeNil())
+				_, err := os.Stat(filepath.Join(outDir, "first.go"))
+				Ω(os.IsNotExist(err)).Should(BeTrue())
+				_, err = os.Stat(filepath.Join(outDir, "main.go"))
+				Ω(os.IsNotExist(err)).Should(BeTrue())
+			})
+		})
+
 	})
 })
 
@@ -198,6 +381,8 @@ var _ = Describe("NewGenerator", func() {
 		target    string
 		force     bool
 		regen     bool
+		stubs     bool
+		deploy    bool
 		noExample bool
 	}{
 		api: &design.APIDefinition{
@@ -208,6 +393,8 @@ var _ = Describe("NewGenerator", func() {
 		target:    "app",
 		force:     false,
 		regen:     false,
+		stubs:     true,
+		deploy:    true,
 	}
 
 	Context("with options all options set", func() {
@@ -220,6 +407,8 @@ var _ = Describe("NewGenerator", func() {
 				genmain.Target(args.target),
 				genmain.Force(args.force),
 				genmain.Regen(args.regen),
+				genmain.Stubs(args.stubs),
+				genmain.Deploy(args.deploy),
 			)
 		})
 
@@ -231,17 +420,42 @@ var _ = Describe("NewGenerator", func() {
 			Ω(generator.Target).Should(Equal(args.target))
 			Ω(generator.Force).Should(Equal(args.force))
 			Ω(generator.Regen).Should(Equal(args.regen))
+			Ω(generator.Stubs).Should(Equal(args.stubs))
+			Ω(generator.Deploy).Should(Equal(args.deploy))
 		})
 
 	})
 })
 
+const deploymentProbesCode = `        ports:
+        - containerPort: 8088
+        livenessProbe:
+          httpGet:
+            path: "/healthz"
+            port: 8088
+            scheme: HTTP
+        readinessProbe:
+          httpGet:
+            path: "/healthz"
+            port: 8088
+            scheme: HTTP
+---
+`
+
 const listenAndServeCode = `
 	if err := service.ListenAndServe(":8080"); err != nil {
 		service.LogError("startup", "err", err)
 	}
 `
 
+const http3ListenAndServeTLSCode = `
+		if err := service.ListenAndServeTLS(":8080", "cert.pem", "key.pem"); err != nil {
+			service.LogError("startup", "err", err)
+		}
+		return
+	}
+`
+
 const listenAndServeTLSCode = `
 	if err := service.ListenAndServeTLS(":8080", "cert.pem", "key.pem"); err != nil {
 		service.LogError("startup", "err", err)
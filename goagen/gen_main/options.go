@@ -46,3 +46,24 @@ func Regen(regen bool) Option {
 		g.Regen = regen
 	}
 }
+
+//Stubs Whether the scaffolded actions return a "not implemented" error
+func Stubs(stubs bool) Option {
+	return func(g *Generator) {
+		g.Stubs = stubs
+	}
+}
+
+//Deploy Whether to generate a Dockerfile and Kubernetes manifests
+func Deploy(deploy bool) Option {
+	return func(g *Generator) {
+		g.Deploy = deploy
+	}
+}
+
+//HTTP3 Whether the generated main may also serve HTTP/3 over QUIC
+func HTTP3(http3 bool) Option {
+	return func(g *Generator) {
+		g.HTTP3 = http3
+	}
+}
@@ -0,0 +1,386 @@
+package genmodel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+)
+
+const (
+	// RemovedEndpoint indicates an endpoint that no longer exists.
+	RemovedEndpoint = "removed_endpoint"
+	// AddedEndpoint indicates a new endpoint.
+	AddedEndpoint = "added_endpoint"
+	// RemovedResponse indicates a response status that is no longer returned.
+	RemovedResponse = "removed_response"
+	// RemovedField indicates a response field that no longer exists.
+	RemovedField = "removed_field"
+	// NewRequired indicates a request field or body that became required.
+	NewRequired = "new_required"
+	// ChangedType indicates a type whose kind changed.
+	ChangedType = "changed_type"
+	// NarrowedType indicates a request type whose validations reject values accepted
+	// previously.
+	NarrowedType = "narrowed_type"
+	// RenumberedField indicates a field whose protocol buffers field number changed.
+	RenumberedField = "renumbered_field"
+	// AddedResponse indicates a new successful or informational response status.
+	AddedResponse = "added_response"
+	// AddedError indicates a new error response status.
+	AddedError = "added_error"
+	// AddedField indicates a new optional request field or a new response field.
+	AddedField = "added_field"
+	// RenamedParam indicates a path param whose name changed, the requests are not affected.
+	RenamedParam = "renamed_param"
+)
+
+// Change describes a difference between two versions of a design.
+type Change struct {
+	// Kind identifies the type of change, e.g. RemovedEndpoint.
+	Kind string `json:"kind"`
+	// Endpoint is the verb and path of the endpoint affected by the change.
+	Endpoint string `json:"endpoint"`
+	// Path is the path to the affected attribute, e.g. "payload.name", if any.
+	Path string `json:"path,omitempty"`
+	// Message describes the change.
+	Message string `json:"message"`
+	// Breaking is true if the change may break existing clients.
+	Breaking bool `json:"breaking"`
+}
+
+// String returns a human friendly description of the change.
+func (c *Change) String() string {
+	loc := c.Endpoint
+	if c.Path != "" {
+		loc += " " + c.Path
+	}
+	return fmt.Sprintf("%s: %s", loc, c.Message)
+}
+
+// Diff compares two models and returns the list of changes sorted by endpoint. The endpoints whose
+// paths only differ by the names of their path params are matched by the position of the params
+// and the renamed params are reported.
+func Diff(before, after *Model) []*Change {
+	d := &differ{}
+	renamed := renamedEndpoints(before, after)
+	for _, key := range sortedKeys(before.Endpoints) {
+		be := before.Endpoints[key]
+		if ae, ok := after.Endpoints[key]; ok {
+			d.endpoint(key, be, ae)
+			continue
+		}
+		ak, ok := renamed[key]
+		if !ok {
+			d.add(RemovedEndpoint, key, "", true, "endpoint was removed")
+			continue
+		}
+		d.endpoint(ak, d.renameParams(key, ak, be), after.Endpoints[ak])
+	}
+	matched := make(map[string]bool, len(renamed))
+	for _, ak := range renamed {
+		matched[ak] = true
+	}
+	for _, key := range sortedKeys(after.Endpoints) {
+		if _, ok := before.Endpoints[key]; !ok && !matched[key] {
+			d.add(AddedEndpoint, key, "", false, "endpoint was added")
+		}
+	}
+	return d.changes
+}
+
+// Breaking returns the breaking changes in the given list.
+func Breaking(changes []*Change) []*Change {
+	var res []*Change
+	for _, c := range changes {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// differ accumulates the changes found while comparing models.
+type differ struct {
+	changes []*Change
+}
+
+func (d *differ) add(kind, endpoint, path string, breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		Kind:     kind,
+		Endpoint: endpoint,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Breaking: breaking,
+	})
+}
+
+func (d *differ) endpoint(key string, before, after *Endpoint) {
+	d.request(key, "params", before.Params, after.Params)
+	d.request(key, "headers", before.Headers, after.Headers)
+	if after.PayloadRequired && (before.Payload == nil || !before.PayloadRequired) {
+		d.add(NewRequired, key, "payload", true, "request body is now required")
+	}
+	if before.Payload != nil && after.Payload != nil {
+		d.request(key, "payload", before.Payload, after.Payload)
+	}
+	statuses := make([]string, 0, len(before.Responses))
+	for s := range before.Responses {
+		statuses = append(statuses, s)
+	}
+	sort.Strings(statuses)
+	for _, s := range statuses {
+		nr, ok := after.Responses[s]
+		if !ok {
+			d.add(RemovedResponse, key, "", true, "response with status %s was removed", s)
+			continue
+		}
+		d.response(key, "response "+s, before.Responses[s], nr)
+	}
+	for _, s := range sortedKeys(after.Responses) {
+		if _, ok := before.Responses[s]; ok {
+			continue
+		}
+		kind := AddedResponse
+		if status, err := strconv.Atoi(s); err == nil && status >= 400 {
+			kind = AddedError
+		}
+		d.add(kind, key, "", false, "response with status %s was added", s)
+	}
+}
+
+// renameParams records the path params of the endpoint beforeKey renamed in the endpoint afterKey
+// and returns a copy of e whose params use the new names.
+func (d *differ) renameParams(beforeKey, afterKey string, e *Endpoint) *Endpoint {
+	from, to := design.ExtractWildcards(beforeKey), design.ExtractWildcards(afterKey)
+	names := make(map[string]string, len(from))
+	for i, n := range from {
+		if to[i] != n {
+			names[n] = to[i]
+			d.add(RenamedParam, afterKey, join("params", n), false, "path param was renamed to %s", to[i])
+		}
+	}
+	if e.Params == nil || len(names) == 0 {
+		return e
+	}
+	rename := func(n string) string {
+		if r, ok := names[n]; ok {
+			return r
+		}
+		return n
+	}
+	params := *e.Params
+	params.Fields = make(map[string]*Type, len(e.Params.Fields))
+	for n, f := range e.Params.Fields {
+		params.Fields[rename(n)] = f
+	}
+	params.Required = make([]string, len(e.Params.Required))
+	for i, n := range e.Params.Required {
+		params.Required[i] = rename(n)
+	}
+	sort.Strings(params.Required)
+	res := *e
+	res.Params = &params
+	return &res
+}
+
+// request compares types sent by clients: new required fields and narrower validations break
+// existing clients.
+func (d *differ) request(key, path string, before, after *Type) {
+	if after == nil {
+		return
+	}
+	if before == nil {
+		for _, r := range after.Required {
+			d.add(NewRequired, key, join(path, r), true, "new required field")
+		}
+		return
+	}
+	d.renumbered(key, path, before, after)
+	if before.Kind != after.Kind {
+		d.add(ChangedType, key, path, true, "type changed from %s to %s", before.Kind, after.Kind)
+		return
+	}
+	for _, r := range after.Required {
+		if !contains(before.Required, r) {
+			d.add(NewRequired, key, join(path, r), true, "field is now required")
+		}
+	}
+	d.narrowed(key, path, before, after)
+	for _, n := range sortedKeys(before.Fields) {
+		if nf, ok := after.Fields[n]; ok {
+			d.request(key, join(path, n), before.Fields[n], nf)
+		}
+	}
+	for _, n := range sortedKeys(after.Fields) {
+		if _, ok := before.Fields[n]; !ok && !contains(after.Required, n) {
+			d.add(AddedField, key, join(path, n), false, "optional field was added")
+		}
+	}
+	if before.Elem != nil && after.Elem != nil {
+		d.request(key, path+"[]", before.Elem, after.Elem)
+	}
+	if before.Key != nil && after.Key != nil {
+		d.request(key, path+"{}", before.Key, after.Key)
+	}
+}
+
+// response compares types received by clients: removed fields and type changes break existing
+// clients.
+func (d *differ) response(key, path string, before, after *Type) {
+	if before == nil {
+		return
+	}
+	if after == nil {
+		d.add(ChangedType, key, path, true, "body was removed")
+		return
+	}
+	d.renumbered(key, path, before, after)
+	if before.Kind != after.Kind {
+		d.add(ChangedType, key, path, true, "type changed from %s to %s", before.Kind, after.Kind)
+		return
+	}
+	for _, n := range sortedKeys(before.Fields) {
+		nf, ok := after.Fields[n]
+		if !ok {
+			d.add(RemovedField, key, join(path, n), true, "field was removed")
+			continue
+		}
+		d.response(key, join(path, n), before.Fields[n], nf)
+	}
+	for _, n := range sortedKeys(after.Fields) {
+		if _, ok := before.Fields[n]; !ok {
+			d.add(AddedField, key, join(path, n), false, "field was added")
+		}
+	}
+	if before.Elem != nil && after.Elem != nil {
+		d.response(key, path+"[]", before.Elem, after.Elem)
+	}
+}
+
+// renumbered records the change of the field number of a field, the messages encoded with the
+// old number are not decoded into the field anymore.
+func (d *differ) renumbered(key, path string, before, after *Type) {
+	if before.FieldNumber == 0 || before.FieldNumber == after.FieldNumber {
+		return
+	}
+	if after.FieldNumber == 0 {
+		d.add(RenumberedField, key, path, true, "field number %d was removed", before.FieldNumber)
+		return
+	}
+	d.add(RenumberedField, key, path, true, "field number changed from %d to %d", before.FieldNumber, after.FieldNumber)
+}
+
+// narrowed records the validations of after that reject values accepted by before.
+func (d *differ) narrowed(key, path string, before, after *Type) {
+	if len(after.Enum) > 0 {
+		for _, v := range before.Enum {
+			if !containsValue(after.Enum, v) {
+				d.add(NarrowedType, key, path, true, "enum value %v was removed", v)
+			}
+		}
+		if len(before.Enum) == 0 {
+			d.add(NarrowedType, key, path, true, "enum validation was added")
+		}
+	}
+	if after.Format != "" && after.Format != before.Format {
+		d.add(NarrowedType, key, path, true, "format is now %s", after.Format)
+	}
+	if after.Pattern != "" && after.Pattern != before.Pattern {
+		d.add(NarrowedType, key, path, true, "pattern is now %s", after.Pattern)
+	}
+	if after.Minimum != nil && (before.Minimum == nil || *after.Minimum > *before.Minimum) {
+		d.add(NarrowedType, key, path, true, "minimum is now %v", *after.Minimum)
+	}
+	if after.Maximum != nil && (before.Maximum == nil || *after.Maximum < *before.Maximum) {
+		d.add(NarrowedType, key, path, true, "maximum is now %v", *after.Maximum)
+	}
+	if after.ExclusiveMinimum != nil && (before.ExclusiveMinimum == nil || *after.ExclusiveMinimum > *before.ExclusiveMinimum) {
+		d.add(NarrowedType, key, path, true, "exclusive minimum is now %v", *after.ExclusiveMinimum)
+	}
+	if after.ExclusiveMaximum != nil && (before.ExclusiveMaximum == nil || *after.ExclusiveMaximum < *before.ExclusiveMaximum) {
+		d.add(NarrowedType, key, path, true, "exclusive maximum is now %v", *after.ExclusiveMaximum)
+	}
+	if after.MultipleOf != nil && (before.MultipleOf == nil || math.Mod(*before.MultipleOf, *after.MultipleOf) != 0) {
+		d.add(NarrowedType, key, path, true, "values must now be a multiple of %v", *after.MultipleOf)
+	}
+	if after.MinLength != nil && (before.MinLength == nil || *after.MinLength > *before.MinLength) {
+		d.add(NarrowedType, key, path, true, "minimum length is now %d", *after.MinLength)
+	}
+	if after.MaxLength != nil && (before.MaxLength == nil || *after.MaxLength < *before.MaxLength) {
+		d.add(NarrowedType, key, path, true, "maximum length is now %d", *after.MaxLength)
+	}
+}
+
+// renamedEndpoints matches the endpoints that only exist in one of the models and whose keys only
+// differ by the names of the path params. It returns the keys of after indexed by the keys of
+// before.
+func renamedEndpoints(before, after *Model) map[string]string {
+	added := make(map[string][]string)
+	for _, key := range sortedKeys(after.Endpoints) {
+		if _, ok := before.Endpoints[key]; !ok {
+			s := shape(key)
+			added[s] = append(added[s], key)
+		}
+	}
+	res := make(map[string]string)
+	for _, key := range sortedKeys(before.Endpoints) {
+		if _, ok := after.Endpoints[key]; ok {
+			continue
+		}
+		s := shape(key)
+		if keys := added[s]; len(keys) == 1 {
+			res[key] = keys[0]
+			delete(added, s)
+		}
+	}
+	return res
+}
+
+// shape returns the endpoint key with the path params replaced with *, e.g. "GET /bottles/*" for
+// "GET /bottles/:id".
+func shape(key string) string {
+	return design.WildcardRegex.ReplaceAllLiteralString(key, "/*")
+}
+
+// join appends the field name to the attribute path.
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue returns true if vals contains a value equal to val.
+func containsValue(vals []interface{}, val interface{}) bool {
+	for _, v := range vals {
+		if reflect.DeepEqual(v, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the sorted keys of m which must be a map indexed by strings.
+func sortedKeys(m interface{}) []string {
+	v := reflect.ValueOf(m)
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,235 @@
+package genmodel_test
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	var oldDSL, newDSL func()
+	var oldAttrs, newAttrs []string
+	var changes []*genmodel.Change
+
+	model := func(dsl func(), attrs []string) *genmodel.Model {
+		dslengine.Reset()
+		API("test", nil)
+		MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				for _, a := range attrs {
+					Attribute(a)
+				}
+			})
+			View("default", func() {
+				for _, a := range attrs {
+					Attribute(a)
+				}
+			})
+		})
+		Resource("bottle", dsl)
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		return genmodel.NewModel(Design)
+	}
+
+	BeforeEach(func() {
+		oldAttrs = []string{"id", "name"}
+		newAttrs = []string{"id", "name"}
+	})
+
+	bottle := func(nameLen int, required ...string) func() {
+		return func() {
+			Action("create", func() {
+				Routing(POST("/bottles"))
+				Payload(func() {
+					Attribute("name", String, func() { MaxLength(nameLen) })
+					Attribute("vintage", Integer)
+					Required(required...)
+				})
+				Response(Created, func() {
+					Media("application/vnd.bottle")
+				})
+			})
+			Action("show", func() {
+				Routing(GET("/bottles/:id"))
+				Response(OK)
+			})
+		}
+	}
+
+	JustBeforeEach(func() {
+		old := model(oldDSL, oldAttrs)
+		changes = genmodel.Diff(old, model(newDSL, newAttrs))
+	})
+
+	Context("with identical designs", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name")
+			newDSL = bottle(10, "name")
+		})
+
+		It("reports no change", func() {
+			Ω(changes).Should(BeEmpty())
+		})
+	})
+
+	Context("with a new required field and a narrowed type", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name")
+			newDSL = bottle(5, "name", "vintage")
+		})
+
+		It("reports breaking changes", func() {
+			Ω(genmodel.Breaking(changes)).Should(HaveLen(2))
+			Ω(changes[0].Kind).Should(Equal(genmodel.NewRequired))
+			Ω(changes[0].Endpoint).Should(Equal("POST /bottles"))
+			Ω(changes[0].Path).Should(Equal("payload.vintage"))
+			Ω(changes[1].Kind).Should(Equal(genmodel.NarrowedType))
+			Ω(changes[1].Path).Should(Equal("payload.name"))
+		})
+	})
+
+	Context("with a widened type", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name", "vintage")
+			newDSL = bottle(20, "name")
+		})
+
+		It("reports no breaking change", func() {
+			Ω(genmodel.Breaking(changes)).Should(BeEmpty())
+		})
+	})
+
+	Context("with a removed endpoint", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name")
+			newDSL = func() {
+				Action("create", func() {
+					Routing(POST("/bottles"))
+					Response(Created)
+				})
+				Action("list", func() {
+					Routing(GET("/bottles"))
+					Response(OK)
+				})
+			}
+		})
+
+		It("reports the removed endpoint, the removed response body and the added endpoint", func() {
+			Ω(changes).Should(HaveLen(3))
+			Ω(changes[0].Kind).Should(Equal(genmodel.RemovedEndpoint))
+			Ω(changes[0].Endpoint).Should(Equal("GET /bottles/:id"))
+			Ω(changes[1].Kind).Should(Equal(genmodel.ChangedType))
+			Ω(changes[1].Path).Should(Equal("response 201"))
+			Ω(changes[2].Kind).Should(Equal(genmodel.AddedEndpoint))
+			Ω(changes[2].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with new fields and responses", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name")
+			newDSL = func() {
+				Action("create", func() {
+					Routing(POST("/bottles"))
+					Payload(func() {
+						Attribute("name", String, func() { MaxLength(10) })
+						Attribute("vintage", Integer)
+						Required("name")
+					})
+					Response(Created, func() {
+						Media("application/vnd.bottle")
+					})
+				})
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Response(OK)
+					Response(NotFound)
+				})
+			}
+			newAttrs = []string{"id", "name", "color"}
+		})
+
+		It("reports the additions as non breaking changes", func() {
+			Ω(genmodel.Breaking(changes)).Should(BeEmpty())
+			Ω(changes).Should(HaveLen(2))
+			Ω(changes[0].Kind).Should(Equal(genmodel.AddedError))
+			Ω(changes[0].Endpoint).Should(Equal("GET /bottles/:id"))
+			Ω(changes[1].Kind).Should(Equal(genmodel.AddedField))
+			Ω(changes[1].Path).Should(Equal("response 201.color"))
+		})
+	})
+
+	Context("with a removed response field", func() {
+		BeforeEach(func() {
+			oldDSL = bottle(10, "name")
+			newDSL = bottle(10, "name")
+			newAttrs = []string{"id"}
+		})
+
+		It("reports the removed field", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(genmodel.RemovedField))
+			Ω(changes[0].Path).Should(Equal("response 201.name"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+
+	Context("with a renamed path param", func() {
+		show := func(param string) func() {
+			return func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:" + param))
+					Params(func() {
+						Param(param, Integer, func() { Minimum(1) })
+					})
+					Response(OK)
+				})
+			}
+		}
+
+		BeforeEach(func() {
+			oldDSL = show("id")
+			newDSL = show("bottleID")
+		})
+
+		It("reports the rename instead of a removed and an added endpoint", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(genmodel.RenamedParam))
+			Ω(changes[0].Endpoint).Should(Equal("GET /bottles/:bottleID"))
+			Ω(changes[0].Path).Should(Equal("params.id"))
+			Ω(changes[0].Message).Should(Equal("path param was renamed to bottleID"))
+			Ω(changes[0].Breaking).Should(BeFalse())
+		})
+	})
+
+	Context("with a renumbered field", func() {
+		numbered := func(vintage int) func() {
+			return func() {
+				Action("create", func() {
+					Routing(POST("/bottles"))
+					Payload(func() {
+						Field(1, "name", String)
+						Field(vintage, "vintage", Integer)
+					})
+					Response(Created)
+				})
+			}
+		}
+
+		BeforeEach(func() {
+			oldDSL = numbered(2)
+			newDSL = numbered(3)
+		})
+
+		It("reports a breaking change", func() {
+			Ω(changes).Should(HaveLen(1))
+			Ω(changes[0].Kind).Should(Equal(genmodel.RenumberedField))
+			Ω(changes[0].Path).Should(Equal("payload.vintage"))
+			Ω(changes[0].Message).Should(Equal("field number changed from 2 to 3"))
+			Ω(changes[0].Breaking).Should(BeTrue())
+		})
+	})
+})
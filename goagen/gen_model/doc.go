@@ -0,0 +1,10 @@
+/*
+Package genmodel provides a generator that serializes the evaluated API design into a model suitable
+for comparison as well as the logic that compares two models to detect breaking changes.
+
+The model of the current design is written to model/design.json. Comparing the models produced by
+two versions of a design with Diff reports the changes that may break existing clients such as
+removed endpoints, narrowed types, renumbered fields or new required fields. It also reports the
+additions such as new endpoints, fields or error responses which do not break existing clients.
+*/
+package genmodel
@@ -0,0 +1,13 @@
+package genmodel_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGenModel(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GenModel Suite")
+}
@@ -0,0 +1,64 @@
+package genmodel_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_model"
+	"github.com/goadesign/goa/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var files []string
+	var genErr error
+	var workspace *codegen.Workspace
+	var testPkg *codegen.Package
+
+	BeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		testPkg, err = workspace.NewPackage("modeltest")
+		Ω(err).ShouldNot(HaveOccurred())
+		os.Args = []string{"goagen", "--out=" + testPkg.Abs(), "--design=foo", "--version=" + version.String()}
+	})
+
+	JustBeforeEach(func() {
+		files, genErr = genmodel.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	Context("with an API with a resource", func() {
+		BeforeEach(func() {
+			dslengine.Reset()
+			apidsl.API("test api", nil)
+			apidsl.Resource("bottle", func() {
+				apidsl.Action("show", func() {
+					apidsl.Routing(apidsl.GET("/bottles/:id"))
+					apidsl.Response("OK")
+				})
+			})
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		})
+
+		It("writes the model that can be loaded back", func() {
+			Ω(genErr).Should(BeNil())
+			Ω(files).Should(HaveLen(2))
+			m, err := genmodel.Load(filepath.Join(testPkg.Abs(), "model", "design.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Name).Should(Equal("test api"))
+			Ω(m.Endpoints).Should(HaveKey("GET /bottles/:id"))
+			e := m.Endpoints["GET /bottles/:id"]
+			Ω(e.Params.Fields).Should(HaveKey("id"))
+			Ω(e.Responses).Should(HaveKey("200"))
+		})
+	})
+})
@@ -0,0 +1,239 @@
+package genmodel
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Model is the comparable representation of an evaluated API design.
+	Model struct {
+		// Name is the API name.
+		Name string `json:"name"`
+		// Endpoints lists the API endpoints indexed by verb and full path, e.g.
+		// "GET /bottles/:id".
+		Endpoints map[string]*Endpoint `json:"endpoints"`
+	}
+
+	// Endpoint describes a single route of an action.
+	Endpoint struct {
+		// Resource is the name of the parent resource.
+		Resource string `json:"resource"`
+		// Action is the name of the action.
+		Action string `json:"action"`
+		// Params describes the path and query string parameters.
+		Params *Type `json:"params,omitempty"`
+		// Headers describes the request headers.
+		Headers *Type `json:"headers,omitempty"`
+		// Payload describes the request body.
+		Payload *Type `json:"payload,omitempty"`
+		// PayloadRequired is true if requests must have a body.
+		PayloadRequired bool `json:"payload_required,omitempty"`
+		// Responses describes the response bodies indexed by HTTP status code, the value is
+		// nil for responses without a body.
+		Responses map[string]*Type `json:"responses,omitempty"`
+	}
+
+	// Type describes the shape and validations of a data type.
+	Type struct {
+		// Kind is one of "boolean", "integer", "number", "string", "datetime", "uuid",
+		// "any", "file", "array", "hash" or "object".
+		Kind string `json:"kind"`
+		// Ref is the name of the user type for recursive type references.
+		Ref string `json:"ref,omitempty"`
+		// FieldNumber is the protocol buffers field number of the field, see the Field DSL.
+		FieldNumber int `json:"field_number,omitempty"`
+		// Fields lists the object fields.
+		Fields map[string]*Type `json:"fields,omitempty"`
+		// Required lists the names of the required object fields.
+		Required []string `json:"required,omitempty"`
+		// Elem is the type of the array or hash elements.
+		Elem *Type `json:"elem,omitempty"`
+		// Key is the type of the hash keys.
+		Key *Type `json:"key,omitempty"`
+		// Enum lists the allowed values.
+		Enum []interface{} `json:"enum,omitempty"`
+		// Format is the format validation.
+		Format string `json:"format,omitempty"`
+		// Pattern is the regular expression validation.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum is the minimum value validation.
+		Minimum *float64 `json:"minimum,omitempty"`
+		// Maximum is the maximum value validation.
+		Maximum *float64 `json:"maximum,omitempty"`
+		// ExclusiveMinimum is the exclusive minimum value validation.
+		ExclusiveMinimum *float64 `json:"exclusive_minimum,omitempty"`
+		// ExclusiveMaximum is the exclusive maximum value validation.
+		ExclusiveMaximum *float64 `json:"exclusive_maximum,omitempty"`
+		// MultipleOf is the multiple of validation.
+		MultipleOf *float64 `json:"multiple_of,omitempty"`
+		// MinLength is the minimum length validation.
+		MinLength *int `json:"min_length,omitempty"`
+		// MaxLength is the maximum length validation.
+		MaxLength *int `json:"max_length,omitempty"`
+	}
+)
+
+// NewModel builds the model of the given API.
+func NewModel(api *design.APIDefinition) *Model {
+	m := &Model{Name: api.Name, Endpoints: make(map[string]*Endpoint)}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			for _, r := range a.Routes {
+				m.Endpoints[r.Verb+" "+r.FullPath()] = newEndpoint(api, a)
+			}
+			return nil
+		})
+	})
+	return m
+}
+
+// Load reads the model serialized in the given file.
+func Load(path string) (*Model, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Model
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// newEndpoint builds the model of the given action.
+func newEndpoint(api *design.APIDefinition, a *design.ActionDefinition) *Endpoint {
+	e := &Endpoint{
+		Resource: a.Parent.Name,
+		Action:   a.Name,
+		Params:   newType(a.Params, nil),
+		Headers:  newType(a.Headers, nil),
+	}
+	if a.Payload != nil {
+		e.Payload = newType(&design.AttributeDefinition{Type: a.Payload}, nil)
+		e.PayloadRequired = !a.PayloadOptional
+	}
+	a.IterateResponses(func(r *design.ResponseDefinition) error {
+		if e.Responses == nil {
+			e.Responses = make(map[string]*Type)
+		}
+		var body *Type
+		if r.Type != nil {
+			body = newType(&design.AttributeDefinition{Type: r.Type}, nil)
+		} else if mt := api.MediaTypeWithIdentifier(r.MediaType); mt != nil && !mt.IsError() {
+			body = newType(mt.AttributeDefinition, nil)
+		}
+		e.Responses[strconv.Itoa(r.Status)] = body
+		return nil
+	})
+	return e
+}
+
+// newType builds the model of the given attribute. seen contains the names of the user types
+// being built to break cycles created by recursive types.
+func newType(att *design.AttributeDefinition, seen map[string]bool) *Type {
+	if att == nil || att.Type == nil {
+		return nil
+	}
+	t := &Type{FieldNumber: att.FieldNumber}
+	if v := att.Validation; v != nil {
+		t.Enum = v.Values
+		t.Format = v.Format
+		t.Pattern = v.Pattern
+		t.Minimum = v.Minimum
+		t.Maximum = v.Maximum
+		t.ExclusiveMinimum = v.ExclusiveMinimum
+		t.ExclusiveMaximum = v.ExclusiveMaximum
+		t.MultipleOf = v.MultipleOf
+		t.MinLength = v.MinLength
+		t.MaxLength = v.MaxLength
+		if len(v.Required) > 0 {
+			t.Required = append([]string(nil), v.Required...)
+			sort.Strings(t.Required)
+		}
+	}
+	switch actual := att.Type.(type) {
+	case design.Primitive:
+		switch actual {
+		case design.DateTime:
+			t.Kind = "datetime"
+		case design.UUID:
+			t.Kind = "uuid"
+		default:
+			t.Kind = actual.Name()
+		}
+	case *design.Array:
+		t.Kind = "array"
+		t.Elem = newType(actual.ElemType, seen)
+	case *design.Hash:
+		t.Kind = "hash"
+		t.Key = newType(actual.KeyType, seen)
+		t.Elem = newType(actual.ElemType, seen)
+	case design.Object:
+		t.Kind = "object"
+		t.Fields = make(map[string]*Type, len(actual))
+		for n, a := range actual {
+			t.Fields[n] = newType(a, seen)
+		}
+	case *design.UserTypeDefinition:
+		return userType(t, actual, seen)
+	case *design.MediaTypeDefinition:
+		return userType(t, actual.UserTypeDefinition, seen)
+	}
+	return t
+}
+
+// userType builds the model of the given user type and merges the field number and validations
+// of the attribute that uses it held by t.
+func userType(t *Type, ut *design.UserTypeDefinition, seen map[string]bool) *Type {
+	if seen[ut.TypeName] {
+		return &Type{Kind: "object", Ref: ut.TypeName}
+	}
+	s := make(map[string]bool, len(seen)+1)
+	for n := range seen {
+		s[n] = true
+	}
+	s[ut.TypeName] = true
+	res := newType(ut.AttributeDefinition, s)
+	if res == nil {
+		return t
+	}
+	if t.FieldNumber != 0 {
+		res.FieldNumber = t.FieldNumber
+	}
+	if t.Enum != nil {
+		res.Enum = t.Enum
+	}
+	if t.Format != "" {
+		res.Format = t.Format
+	}
+	if t.Pattern != "" {
+		res.Pattern = t.Pattern
+	}
+	if t.Minimum != nil {
+		res.Minimum = t.Minimum
+	}
+	if t.Maximum != nil {
+		res.Maximum = t.Maximum
+	}
+	if t.ExclusiveMinimum != nil {
+		res.ExclusiveMinimum = t.ExclusiveMinimum
+	}
+	if t.ExclusiveMaximum != nil {
+		res.ExclusiveMaximum = t.ExclusiveMaximum
+	}
+	if t.MultipleOf != nil {
+		res.MultipleOf = t.MultipleOf
+	}
+	if t.MinLength != nil {
+		res.MinLength = t.MinLength
+	}
+	if t.MaxLength != nil {
+		res.MaxLength = t.MaxLength
+	}
+	return res
+}
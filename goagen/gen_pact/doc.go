@@ -0,0 +1,11 @@
+/*
+Package genpact provides a generator for consumer-driven contracts in the Pact format
+(https://docs.pact.io/).
+
+The generator produces a Pact specification v2 file that lists one interaction per request and
+response example of each action, see apidsl.HTTPExample. The actions that define no example get
+one interaction whose request and response bodies are generated from the design and matched by
+type. The generator also produces the Verify function that replays the interactions against the
+service handler and scaffolds the provider verification test that calls it.
+*/
+package genpact
@@ -0,0 +1,341 @@
+package genpact
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/utils"
+)
+
+//NewGenerator returns an initialized instance of a Pact contract Generator
+func NewGenerator(options ...Option) *Generator {
+	g := &Generator{}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generator is the Pact contract generator.
+type Generator struct {
+	API      *design.APIDefinition // The API definition
+	OutDir   string                // Path to output directory
+	Consumer string                // Name of the consumer, defaults to the API name followed by "-client"
+	Provider string                // Name of the provider, defaults to the API name
+	genfiles []string              // Generated files
+}
+
+// Generate is the generator entry point called by the meta generator.
+func Generate() (files []string, err error) {
+	var (
+		outDir, consumer, provider, ver string
+	)
+
+	set := flag.NewFlagSet("pact", flag.PanicOnError)
+	set.StringVar(&outDir, "out", "", "")
+	set.StringVar(&ver, "version", "", "")
+	set.String("design", "", "")
+	set.StringVar(&consumer, "consumer", "", "")
+	set.StringVar(&provider, "provider", "", "")
+	set.Parse(os.Args[1:])
+
+	if err := codegen.CheckVersion(ver); err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, Consumer: consumer, Provider: provider, API: design.Design}
+
+	return g.Generate()
+}
+
+// Generate produces the contract file, the verification helper and the provider test scaffold.
+// The scaffold is only generated if it does not exist yet.
+func (g *Generator) Generate() (_ []string, err error) {
+	if g.API == nil {
+		return nil, fmt.Errorf("missing API definition, make sure design is properly initialized")
+	}
+
+	go utils.Catch(nil, func() { g.Cleanup() })
+
+	defer func() {
+		if err != nil {
+			g.Cleanup()
+		}
+	}()
+
+	consumer, provider := g.Consumer, g.Provider
+	if provider == "" {
+		provider = g.API.Name
+	}
+	if consumer == "" {
+		consumer = provider + "-client"
+	}
+	pact, err := NewPact(g.API, consumer, provider)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	pactDir := filepath.Join(g.OutDir, "pacts")
+	if err = os.MkdirAll(pactDir, 0755); err != nil {
+		return nil, err
+	}
+
+	name := strings.Replace(strings.ToLower(consumer+"-"+provider), " ", "-", -1) + ".json"
+	pactFile := filepath.Join(pactDir, name)
+	if err = ioutil.WriteFile(pactFile, raw, 0644); err != nil {
+		return nil, err
+	}
+	g.genfiles = append(g.genfiles, pactFile)
+
+	if err = g.generateVerify(filepath.Join(pactDir, "verify.go")); err != nil {
+		return nil, err
+	}
+	testFile := filepath.Join(pactDir, "provider_test.go")
+	if _, e := os.Stat(testFile); e != nil {
+		if err = g.generateProviderTest(testFile, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.genfiles, nil
+}
+
+// generateVerify generates the Verify function that replays the interactions of a contract.
+func (g *Generator) generateVerify(verifyFile string) (err error) {
+	os.Remove(verifyFile)
+	file, err := codegen.SourceFileFor(verifyFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	title := fmt.Sprintf("%s: Pact Contract Verification", g.API.Context())
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("bytes"),
+		codegen.SimpleImport("encoding/json"),
+		codegen.SimpleImport("io"),
+		codegen.SimpleImport("io/ioutil"),
+		codegen.SimpleImport("net/http"),
+		codegen.SimpleImport("net/http/httptest"),
+		codegen.SimpleImport("reflect"),
+		codegen.SimpleImport("strconv"),
+		codegen.SimpleImport("testing"),
+	}
+	if err = file.WriteHeader(title, "pacts", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, verifyFile)
+	return file.ExecuteTemplate("verify", verifyT, nil, nil)
+}
+
+// generateProviderTest generates the scaffold of the provider verification test.
+func (g *Generator) generateProviderTest(testFile, pactFile string) (err error) {
+	file, err := codegen.SourceFileFor(testFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+		if err == nil {
+			err = file.FormatCode()
+		}
+	}()
+	imports := []*codegen.ImportSpec{
+		codegen.SimpleImport("testing"),
+		codegen.SimpleImport("github.com/goadesign/goa"),
+	}
+	if err = file.WriteHeader("", "pacts", imports); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, testFile)
+	var resources []string
+	g.API.IterateResources(func(res *design.ResourceDefinition) error {
+		if len(res.Actions) > 0 || len(res.FileServers) > 0 {
+			resources = append(resources, codegen.Goify(res.Name, true))
+		}
+		return nil
+	})
+	data := map[string]interface{}{
+		"API":       g.API.Name,
+		"File":      pactFile,
+		"Resources": resources,
+	}
+	return file.ExecuteTemplate("provider", providerT, nil, data)
+}
+
+// Cleanup removes all the files generated by this generator during the last invokation of Generate.
+func (g *Generator) Cleanup() {
+	for _, f := range g.genfiles {
+		os.Remove(f)
+	}
+	g.genfiles = nil
+}
+
+const (
+	// verifyT generates the function that verifies a provider against a contract.
+	// template input: nil
+	verifyT = `// Verify replays the interactions of the given Pact file against h and reports the responses that
+// do not match the contract to t. setup is called with the provider state of each interaction
+// before the request is sent so that the test may prepare the data the interaction expects, it
+// may be nil. The JSON response bodies of the interactions with a "type" matching rule must have
+// values of the same type as the contract, the attributes that the actual body omits are ignored.
+func Verify(t testing.TB, h http.Handler, file string, setup func(state string)) {
+	raw, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read contract: %s", err)
+	}
+	var pact struct {
+		Interactions []struct {
+			Description   string ` + "`json:\"description\"`" + `
+			ProviderState string ` + "`json:\"providerState\"`" + `
+			Request       struct {
+				Method  string            ` + "`json:\"method\"`" + `
+				Path    string            ` + "`json:\"path\"`" + `
+				Query   string            ` + "`json:\"query\"`" + `
+				Headers map[string]string ` + "`json:\"headers\"`" + `
+				Body    json.RawMessage   ` + "`json:\"body\"`" + `
+			} ` + "`json:\"request\"`" + `
+			Response struct {
+				Status        int                          ` + "`json:\"status\"`" + `
+				Body          json.RawMessage              ` + "`json:\"body\"`" + `
+				MatchingRules map[string]map[string]string ` + "`json:\"matchingRules\"`" + `
+			} ` + "`json:\"response\"`" + `
+		} ` + "`json:\"interactions\"`" + `
+	}
+	if err := json.Unmarshal(raw, &pact); err != nil {
+		t.Fatalf("failed to decode contract: %s", err)
+	}
+	for _, i := range pact.Interactions {
+		if setup != nil {
+			setup(i.ProviderState)
+		}
+		var body io.Reader
+		if len(i.Request.Body) > 0 {
+			body = bytes.NewReader(i.Request.Body)
+		}
+		target := i.Request.Path
+		if i.Request.Query != "" {
+			target += "?" + i.Request.Query
+		}
+		req := httptest.NewRequest(i.Request.Method, target, body)
+		for k, v := range i.Request.Headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != i.Response.Status {
+			t.Errorf("%s: got status %d, expected %d", i.Description, rec.Code, i.Response.Status)
+			continue
+		}
+		if len(i.Response.Body) == 0 {
+			continue
+		}
+		var expected, actual interface{}
+		if err := json.Unmarshal(i.Response.Body, &expected); err != nil {
+			t.Errorf("%s: invalid contract body: %s", i.Description, err)
+			continue
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &actual); err != nil {
+			t.Errorf("%s: invalid response body: %s", i.Description, err)
+			continue
+		}
+		byType := i.Response.MatchingRules["$.body"]["match"] == "type"
+		if path := mismatch(expected, actual, byType, "$.body"); path != "" {
+			t.Errorf("%s: response body does not match the contract at %s", i.Description, path)
+		}
+	}
+}
+
+// mismatch returns the JSON path of the first value of actual that does not match expected, the
+// empty string if actual matches.
+func mismatch(expected, actual interface{}, byType bool, path string) string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return path
+		}
+		for k, v := range e {
+			av, ok := a[k]
+			if !ok && byType {
+				continue
+			}
+			if p := mismatch(v, av, byType, path+"."+k); p != "" {
+				return p
+			}
+		}
+		return ""
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return path
+		}
+		if byType {
+			if len(e) == 0 {
+				return ""
+			}
+			for _, av := range a {
+				if p := mismatch(e[0], av, byType, path+"[*]"); p != "" {
+					return p
+				}
+			}
+			return ""
+		}
+		if len(a) != len(e) {
+			return path
+		}
+		for i := range e {
+			if p := mismatch(e[i], a[i], byType, path+"["+strconv.Itoa(i)+"]"); p != "" {
+				return p
+			}
+		}
+		return ""
+	default:
+		if byType {
+			if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
+				return path
+			}
+			return ""
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return path
+		}
+		return ""
+	}
+}
+`
+
+	// providerT generates the provider verification test scaffold.
+	// template input: map[string]interface{}
+	providerT = `// TestProvider verifies that the service honors the contract of its consumer. Mount the
+// controllers onto the service, prepare the data required by each provider state in setup and
+// remove the call to Skip.
+func TestProvider(t *testing.T) {
+	service := goa.New({{ printf "%q" .API }})
+{{ range .Resources }}	// app.Mount{{ . }}Controller(service, New{{ . }}Controller(service))
+{{ end }}	t.Skip("mount the controllers to verify the provider")
+
+	setup := func(state string) {
+		// Prepare the data read by the interactions given their provider state.
+	}
+	Verify(t, service.Mux, {{ printf "%q" .File }}, setup)
+}
+`
+)
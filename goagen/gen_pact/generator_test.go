@@ -0,0 +1,116 @@
+package genpact_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/goadesign/goa/goagen/gen_pact"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewGenerator", func() {
+	var generator *genpact.Generator
+
+	var args = struct {
+		api      *design.APIDefinition
+		outDir   string
+		consumer string
+		provider string
+	}{
+		api: &design.APIDefinition{
+			Name: "test api",
+		},
+		outDir:   "out_dir",
+		consumer: "web",
+		provider: "cellar",
+	}
+
+	Context("with options all options set", func() {
+		BeforeEach(func() {
+			generator = genpact.NewGenerator(
+				genpact.API(args.api),
+				genpact.OutDir(args.outDir),
+				genpact.Consumer(args.consumer),
+				genpact.Provider(args.provider),
+			)
+		})
+
+		It("has all public properties set with expected value", func() {
+			Ω(generator).ShouldNot(BeNil())
+			Ω(generator.API.Name).Should(Equal(args.api.Name))
+			Ω(generator.OutDir).Should(Equal(args.outDir))
+			Ω(generator.Consumer).Should(Equal(args.consumer))
+			Ω(generator.Provider).Should(Equal(args.provider))
+		})
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var workspace *codegen.Workspace
+	var outDir string
+	var files []string
+	var genErr error
+
+	JustBeforeEach(func() {
+		var err error
+		workspace, err = codegen.NewWorkspace("test")
+		Ω(err).ShouldNot(HaveOccurred())
+		outDir, err = ioutil.TempDir(filepath.Join(workspace.Path, "src"), "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		API("cellar", func() {})
+		Resource("bottle", func() {
+			Action("list", func() {
+				Routing(GET("/bottles"))
+				Response("NoContent")
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		g := genpact.NewGenerator(genpact.API(design.Design), genpact.OutDir(outDir))
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		workspace.Delete()
+	})
+
+	It("generates the contract, the verification helper and the provider test", func() {
+		Ω(genErr).ShouldNot(HaveOccurred())
+		Ω(files).Should(HaveLen(3))
+		raw, err := ioutil.ReadFile(filepath.Join(outDir, "pacts", "cellar-client-cellar.json"))
+		Ω(err).ShouldNot(HaveOccurred())
+		var pact genpact.Pact
+		Ω(json.Unmarshal(raw, &pact)).Should(Succeed())
+		Ω(pact.Consumer.Name).Should(Equal("cellar-client"))
+		Ω(pact.Provider.Name).Should(Equal("cellar"))
+		Ω(pact.Interactions).Should(HaveLen(1))
+		verify, err := ioutil.ReadFile(filepath.Join(outDir, "pacts", "verify.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(verify)).Should(ContainSubstring("func Verify(t testing.TB, h http.Handler, file string, setup func(state string)) {"))
+		test, err := ioutil.ReadFile(filepath.Join(outDir, "pacts", "provider_test.go"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(test)).Should(ContainSubstring("// app.MountBottleController(service, NewBottleController(service))"))
+		Ω(string(test)).Should(ContainSubstring(`Verify(t, service.Mux, "cellar-client-cellar.json", setup)`))
+	})
+
+	Context("with an existing provider test", func() {
+		It("does not overwrite it", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			testFile := filepath.Join(outDir, "pacts", "provider_test.go")
+			Ω(ioutil.WriteFile(testFile, []byte("package pacts\n"), 0644)).Should(Succeed())
+			g := genpact.NewGenerator(genpact.API(design.Design), genpact.OutDir(outDir))
+			files, err := g.Generate()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(files).Should(HaveLen(2))
+			content, err := ioutil.ReadFile(testFile)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(content)).Should(Equal("package pacts\n"))
+		})
+	})
+})
@@ -0,0 +1,281 @@
+package genpact
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa/design"
+)
+
+type (
+	// Pact is a consumer-driven contract in the Pact specification v2 format.
+	// See https://github.com/pact-foundation/pact-specification/tree/version-2
+	Pact struct {
+		// Consumer is the service that sends the requests.
+		Consumer *Pacticipant `json:"consumer"`
+		// Provider is the service that handles the requests.
+		Provider *Pacticipant `json:"provider"`
+		// Interactions lists the requests and the responses they expect.
+		Interactions []*Interaction `json:"interactions"`
+		// Metadata holds the version of the Pact specification.
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+
+	// Pacticipant is a party of a contract.
+	Pacticipant struct {
+		// Name of the service
+		Name string `json:"name"`
+	}
+
+	// Interaction is a request and the response the consumer expects.
+	Interaction struct {
+		// Description uniquely identifies the interaction in the contract.
+		Description string `json:"description"`
+		// ProviderState describes the state the provider must be in to handle the request.
+		ProviderState string `json:"providerState,omitempty"`
+		// Request is the request sent by the consumer.
+		Request *Request `json:"request"`
+		// Response is the response expected by the consumer.
+		Response *Response `json:"response"`
+	}
+
+	// Request is the request of an interaction.
+	Request struct {
+		// Method is the HTTP method of the request.
+		Method string `json:"method"`
+		// Path is the request path.
+		Path string `json:"path"`
+		// Query is the request query string if any.
+		Query string `json:"query,omitempty"`
+		// Headers lists the request headers.
+		Headers map[string]string `json:"headers,omitempty"`
+		// Body is the request body if any.
+		Body interface{} `json:"body,omitempty"`
+	}
+
+	// Response is the response of an interaction.
+	Response struct {
+		// Status is the response status code.
+		Status int `json:"status"`
+		// Body is the response body if any.
+		Body interface{} `json:"body,omitempty"`
+		// MatchingRules lists the rules that relax the comparison of the response with the
+		// actual response indexed by JSON path.
+		MatchingRules map[string]map[string]string `json:"matchingRules,omitempty"`
+	}
+)
+
+// SpecificationVersion is the version of the Pact specification of the generated contracts.
+const SpecificationVersion = "2.0.0"
+
+// NewPact returns the contract between the given consumer and provider built from the examples of
+// the API actions. The websocket actions and the actions with a multipart payload are skipped.
+func NewPact(api *design.APIDefinition, consumer, provider string) (*Pact, error) {
+	pact := &Pact{
+		Consumer: &Pacticipant{Name: consumer},
+		Provider: &Pacticipant{Name: provider},
+		Metadata: map[string]interface{}{
+			"pactSpecification": map[string]string{"version": SpecificationVersion},
+		},
+	}
+	err := api.IterateResources(func(res *design.ResourceDefinition) error {
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			if len(a.Routes) == 0 || a.WebSocket() || a.PayloadMultipart {
+				return nil
+			}
+			interactions, err := actionInteractions(api, a)
+			if err != nil {
+				return err
+			}
+			pact.Interactions = append(pact.Interactions, interactions...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pact, nil
+}
+
+// actionInteractions returns the interactions of the first route of the given action, one per
+// example or one built from the generated examples if the action has none.
+func actionInteractions(api *design.APIDefinition, a *design.ActionDefinition) ([]*Interaction, error) {
+	route := a.Routes[0]
+	rand := api.RandomGenerator()
+	desc := a.Name + " " + a.Parent.Name
+	examples := route.AllHTTPExamples()
+	if len(examples) == 0 {
+		resp := successResponse(a)
+		if resp == nil {
+			return nil, nil
+		}
+		req, err := newRequest(rand, a, route)
+		if err != nil {
+			return nil, err
+		}
+		if a.Payload != nil {
+			req.Body = toStringMap(a.Payload.GenerateExample(rand, nil))
+			req.Headers["Content-Type"] = "application/json"
+		}
+		r := &Response{Status: resp.Status}
+		if mt := api.MediaTypeWithIdentifier(resp.MediaType); mt != nil {
+			view := resp.ViewName
+			if view == "" {
+				view = design.DefaultView
+			}
+			p, _, err := mt.Project(view)
+			if err != nil {
+				return nil, err
+			}
+			r.Body = toStringMap(p.GenerateExample(rand, nil))
+			r.MatchingRules = map[string]map[string]string{"$.body": {"match": "type"}}
+		}
+		return []*Interaction{{Description: desc, ProviderState: providerState(a), Request: req, Response: r}}, nil
+	}
+	interactions := make([]*Interaction, len(examples))
+	for i, ex := range examples {
+		resp, ok := a.Responses[ex.Response]
+		if !ok {
+			return nil, fmt.Errorf("action %s of resource %s: example set for unknown response %s", a.Name, a.Parent.Name, ex.Response)
+		}
+		req, err := newRequest(rand, a, route)
+		if err != nil {
+			return nil, err
+		}
+		if ex.Request != nil {
+			req.Body = toStringMap(ex.Request)
+			req.Headers["Content-Type"] = ex.ContentType
+		}
+		interactions[i] = &Interaction{
+			Description:   fmt.Sprintf("%s (%s %s)", desc, ex.Response, ex.ContentType),
+			ProviderState: providerState(a),
+			Request:       req,
+			Response:      &Response{Status: resp.Status, Body: toStringMap(ex.Body)},
+		}
+	}
+	return interactions, nil
+}
+
+// newRequest returns the request of the given route built with the examples of the path params
+// and of the required query params and headers.
+func newRequest(rand *design.RandomGenerator, a *design.ActionDefinition, route *design.RouteDefinition) (*Request, error) {
+	params := a.AllParams()
+	var err error
+	path := design.WildcardRegex.ReplaceAllStringFunc(route.FullPath(), func(w string) string {
+		name := design.WildcardRegex.FindStringSubmatch(w)[1]
+		att, ok := params.Type.ToObject()[name]
+		if !ok {
+			err = fmt.Errorf("action %s of resource %s: unknown path param %s", a.Name, a.Parent.Name, name)
+			return w
+		}
+		return "/" + url.PathEscape(exampleString(att.GenerateExample(rand, nil)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	req := &Request{Method: route.Verb, Path: path, Headers: make(map[string]string)}
+	if qp := a.QueryParams; qp != nil {
+		values := make(url.Values)
+		for name, att := range qp.Type.ToObject() {
+			if qp.IsRequired(name) {
+				values.Set(att.AttributeKey(name), exampleString(att.GenerateExample(rand, nil)))
+			}
+		}
+		req.Query = values.Encode()
+	}
+	if h := a.Headers; h != nil {
+		for name, att := range h.Type.ToObject() {
+			if h.IsRequired(name) {
+				req.Headers[att.AttributeKey(name)] = exampleString(att.GenerateExample(rand, nil))
+			}
+		}
+	}
+	return req, nil
+}
+
+// successResponse returns the response of the action with the lowest 2xx status code, nil if
+// there is none.
+func successResponse(a *design.ActionDefinition) *design.ResponseDefinition {
+	var names []string
+	for n, r := range a.Responses {
+		if r.Status >= 200 && r.Status < 300 {
+			names = append(names, n)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := a.Responses[names[i]], a.Responses[names[j]]
+		if ri.Status == rj.Status {
+			return names[i] < names[j]
+		}
+		return ri.Status < rj.Status
+	})
+	if len(names) == 0 {
+		return nil
+	}
+	return a.Responses[names[0]]
+}
+
+// providerState returns the provider state of the interactions of the given action, it names the
+// security scheme the requests must be authorized with if any.
+func providerState(a *design.ActionDefinition) string {
+	if a.Security == nil {
+		return ""
+	}
+	state := "authorized with " + a.Security.Scheme.SchemeName
+	if len(a.Security.Scopes) > 0 {
+		state += " (" + strings.Join(a.Security.Scopes, ", ") + ")"
+	}
+	return state
+}
+
+// exampleString returns the representation of the given example in a path, query string or
+// header.
+func exampleString(val interface{}) string {
+	switch actual := val.(type) {
+	case time.Time:
+		return actual.Format(time.RFC3339)
+	case []interface{}:
+		elems := make([]string, len(actual))
+		for i, e := range actual {
+			elems[i] = exampleString(e)
+		}
+		return strings.Join(elems, ",")
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(actual)
+	}
+}
+
+// toStringMap converts the maps with interface{} keys of the given example into maps with string
+// keys so that it can be serialized to JSON.
+func toStringMap(val interface{}) interface{} {
+	switch actual := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			m[fmt.Sprint(k)] = toStringMap(v)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			m[k] = toStringMap(v)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(actual))
+		for i, e := range actual {
+			s[i] = toStringMap(e)
+		}
+		return s
+	default:
+		return actual
+	}
+}
@@ -0,0 +1,122 @@
+package genpact_test
+
+import (
+	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_pact"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewPact", func() {
+	var pact *genpact.Pact
+	var newErr error
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		API("cellar", func() {})
+	})
+
+	JustBeforeEach(func() {
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		pact, newErr = genpact.NewPact(design.Design, "web", "cellar")
+	})
+
+	Context("with request and response examples", func() {
+		BeforeEach(func() {
+			Resource("bottle", func() {
+				BasePath("/bottles")
+				Action("create", func() {
+					Routing(POST("/:account"))
+					Params(func() {
+						Param("account", design.Integer, func() { Example(42) })
+					})
+					Payload(func() { Attribute("name", design.String) })
+					HTTPExample("application/json", map[string]interface{}{"name": "red"}, "Created", map[string]interface{}{"id": 1})
+					Response("Created")
+				})
+			})
+		})
+
+		It("lists one interaction per example", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(pact.Consumer.Name).Should(Equal("web"))
+			Ω(pact.Metadata["pactSpecification"]).Should(Equal(map[string]string{"version": genpact.SpecificationVersion}))
+			Ω(pact.Interactions).Should(HaveLen(1))
+			i := pact.Interactions[0]
+			Ω(i.Description).Should(Equal("create bottle (Created application/json)"))
+			Ω(i.Request.Method).Should(Equal("POST"))
+			Ω(i.Request.Path).Should(Equal("/bottles/42"))
+			Ω(i.Request.Headers).Should(Equal(map[string]string{"Content-Type": "application/json"}))
+			Ω(i.Request.Body).Should(Equal(map[string]interface{}{"name": "red"}))
+			Ω(i.Response.Status).Should(Equal(201))
+			Ω(i.Response.Body).Should(Equal(map[string]interface{}{"id": 1}))
+			Ω(i.Response.MatchingRules).Should(BeEmpty())
+		})
+	})
+
+	Context("without examples", func() {
+		BeforeEach(func() {
+			bottle := MediaType("application/vnd.bottle", func() {
+				Attributes(func() {
+					Attribute("id", design.Integer)
+					Attribute("name", design.String)
+				})
+				View("default", func() {
+					Attribute("id")
+					Attribute("name")
+				})
+			})
+			basic := BasicAuthSecurity("basic")
+			Resource("bottle", func() {
+				Action("show", func() {
+					Routing(GET("/bottles/:id"))
+					Params(func() {
+						Param("id", design.Integer)
+						Param("vintage", design.Integer)
+						Required("vintage")
+					})
+					Headers(func() {
+						Header("X-Account", design.String, func() { Example("acme") })
+						Required("X-Account")
+					})
+					Security(basic)
+					Response("OK", bottle)
+					Response("NotFound")
+				})
+			})
+		})
+
+		It("generates the interaction of the success response", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(pact.Interactions).Should(HaveLen(1))
+			i := pact.Interactions[0]
+			Ω(i.Description).Should(Equal("show bottle"))
+			Ω(i.ProviderState).Should(Equal("authorized with basic"))
+			Ω(i.Request.Path).Should(MatchRegexp(`^/bottles/-?\d+$`))
+			Ω(i.Request.Query).Should(MatchRegexp(`^vintage=-?\d+$`))
+			Ω(i.Request.Headers).Should(Equal(map[string]string{"X-Account": "acme"}))
+			Ω(i.Response.Status).Should(Equal(200))
+			Ω(i.Response.Body).Should(HaveKey("id"))
+			Ω(i.Response.MatchingRules).Should(Equal(map[string]map[string]string{"$.body": {"match": "type"}}))
+		})
+	})
+
+	Context("with a websocket action", func() {
+		BeforeEach(func() {
+			Resource("bottle", func() {
+				Action("watch", func() {
+					Routing(GET("/bottles/watch"))
+					Scheme("ws")
+					Response("SwitchingProtocols")
+				})
+			})
+		})
+
+		It("skips the action", func() {
+			Ω(newErr).ShouldNot(HaveOccurred())
+			Ω(pact.Interactions).Should(BeEmpty())
+		})
+	})
+})
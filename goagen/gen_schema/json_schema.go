@@ -7,8 +7,10 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
 )
 
 type (
@@ -26,6 +28,7 @@ type (
 		Description  string                 `json:"description,omitempty"`
 		DefaultValue interface{}            `json:"default,omitempty"`
 		Example      interface{}            `json:"example,omitempty"`
+		Examples     map[string]interface{} `json:"x-examples,omitempty"`
 
 		// Hyper schema
 		Media     *JSONMedia  `json:"media,omitempty"`
@@ -35,20 +38,30 @@ type (
 		Ref       string      `json:"$ref,omitempty"`
 
 		// Validation
-		Enum                 []interface{} `json:"enum,omitempty"`
-		Format               string        `json:"format,omitempty"`
-		Pattern              string        `json:"pattern,omitempty"`
-		Minimum              *float64      `json:"minimum,omitempty"`
-		Maximum              *float64      `json:"maximum,omitempty"`
-		MinLength            *int          `json:"minLength,omitempty"`
-		MaxLength            *int          `json:"maxLength,omitempty"`
-		Required             []string      `json:"required,omitempty"`
-		AdditionalProperties bool          `json:"additionalProperties,omitempty"`
+		Enum                 []interface{}       `json:"enum,omitempty"`
+		Format               string              `json:"format,omitempty"`
+		Pattern              string              `json:"pattern,omitempty"`
+		Minimum              *float64            `json:"minimum,omitempty"`
+		ExclusiveMinimum     bool                `json:"exclusiveMinimum,omitempty"`
+		Maximum              *float64            `json:"maximum,omitempty"`
+		ExclusiveMaximum     bool                `json:"exclusiveMaximum,omitempty"`
+		MultipleOf           *float64            `json:"multipleOf,omitempty"`
+		MinLength            *int                `json:"minLength,omitempty"`
+		MaxLength            *int                `json:"maxLength,omitempty"`
+		Required             []string            `json:"required,omitempty"`
+		Dependencies         map[string][]string `json:"dependencies,omitempty"`
+		AdditionalProperties bool                `json:"additionalProperties,omitempty"`
 
 		// Union
 		AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+
+		// Extensions defines the vendor extensions, see ExtensionsFromMetadata.
+		Extensions map[string]interface{} `json:"-"`
 	}
 
+	// _JSONSchema is used to marshal the schema fields without recursing into MarshalJSON.
+	_JSONSchema JSONSchema
+
 	// JSONType is the JSON type enum.
 	JSONType string
 
@@ -123,6 +136,52 @@ func (s *JSONSchema) JSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// MarshalJSON returns the JSON encoding of s including its vendor extensions.
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	marshaled, err := json.Marshal(_JSONSchema(s))
+	if err != nil || len(s.Extensions) == 0 {
+		return marshaled, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(marshaled, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range s.Extensions {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// ExtensionsFromMetadata returns the vendor extensions set with the "swagger:extension:x-xxx" or
+// "openapi:extension:x-xxx" metadata keys. The values are decoded as JSON if possible and used as
+// strings otherwise. It returns nil if there is no extension.
+func ExtensionsFromMetadata(mdata dslengine.MetadataDefinition) map[string]interface{} {
+	var extensions map[string]interface{}
+	for key, value := range mdata {
+		chunks := strings.Split(key, ":")
+		if len(chunks) != 3 {
+			continue
+		}
+		if (chunks[0] != "swagger" && chunks[0] != "openapi") || chunks[1] != "extension" {
+			continue
+		}
+		if !strings.HasPrefix(chunks[2], "x-") || len(value) == 0 {
+			continue
+		}
+		if extensions == nil {
+			extensions = make(map[string]interface{})
+		}
+		val := value[0]
+		var ival interface{}
+		if err := json.Unmarshal([]byte(val), &ival); err != nil {
+			extensions[chunks[2]] = val
+			continue
+		}
+		extensions[chunks[2]] = ival
+	}
+	return extensions
+}
+
 // APISchema produces the API JSON hyper schema.
 func APISchema(api *design.APIDefinition) *JSONSchema {
 	api.IterateResources(func(r *design.ResourceDefinition) error {
@@ -304,7 +363,7 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 		for n, at := range actual {
 			prop := NewJSONSchema()
 			buildAttributeSchema(api, prop, at)
-			s.Properties[n] = prop
+			s.Properties[at.AttributeKey(n)] = prop
 		}
 	case *design.Hash:
 		s.Type = JSONObject
@@ -318,6 +377,52 @@ func TypeSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
 	return s
 }
 
+// StandaloneSchema produces the JSON schema of the given user type or media type definition
+// together with the definitions it references directly or indirectly so that the schema can be
+// used on its own. It returns nil if t is not a user type or a media type.
+func StandaloneSchema(api *design.APIDefinition, t design.DataType) *JSONSchema {
+	ref := TypeSchema(api, t).Ref
+	def, ok := Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if ref == "" || !ok {
+		return nil
+	}
+	s := *def
+	s.Schema = SchemaRef
+	s.Definitions = make(map[string]*JSONSchema)
+	collectDefinitions(def, s.Definitions)
+	return &s
+}
+
+// collectDefinitions adds the definitions referenced by s to defs recursively.
+func collectDefinitions(s *JSONSchema, defs map[string]*JSONSchema) {
+	if s == nil {
+		return
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		name := strings.TrimPrefix(s.Ref, "#/definitions/")
+		if def, ok := Definitions[name]; ok {
+			if _, done := defs[name]; !done {
+				defs[name] = def
+				collectDefinitions(def, defs)
+			}
+		}
+	}
+	collectDefinitions(s.Items, defs)
+	for _, p := range s.Properties {
+		collectDefinitions(p, defs)
+	}
+	for _, d := range s.Definitions {
+		collectDefinitions(d, defs)
+	}
+	for _, a := range s.AnyOf {
+		collectDefinitions(a, defs)
+	}
+	for _, l := range s.Links {
+		collectDefinitions(l.Schema, defs)
+		collectDefinitions(l.TargetSchema, defs)
+	}
+}
+
 type mergeItems []struct {
 	a, b   interface{}
 	needed bool
@@ -338,6 +443,11 @@ func (s *JSONSchema) createMergeItems(other *JSONSchema) mergeItems {
 		{&s.Format, other.Format, s.Format == ""},
 		{&s.Pattern, other.Pattern, s.Pattern == ""},
 		{&s.AdditionalProperties, other.AdditionalProperties, s.AdditionalProperties == false},
+		{&s.ExclusiveMinimum, other.ExclusiveMinimum, s.ExclusiveMinimum == false},
+		{&s.ExclusiveMaximum, other.ExclusiveMaximum, s.ExclusiveMaximum == false},
+		{&s.MultipleOf, other.MultipleOf, s.MultipleOf == nil},
+		{&s.Extensions, other.Extensions, s.Extensions == nil},
+		{&s.Dependencies, other.Dependencies, s.Dependencies == nil},
 		{
 			a: s.Minimum, b: other.Minimum,
 			needed: (s.Minimum == nil && s.Minimum != nil) ||
@@ -412,11 +522,16 @@ func (s *JSONSchema) Dup() *JSONSchema {
 		Format:               s.Format,
 		Pattern:              s.Pattern,
 		Minimum:              s.Minimum,
+		ExclusiveMinimum:     s.ExclusiveMinimum,
 		Maximum:              s.Maximum,
+		ExclusiveMaximum:     s.ExclusiveMaximum,
+		MultipleOf:           s.MultipleOf,
 		MinLength:            s.MinLength,
 		MaxLength:            s.MaxLength,
 		Required:             s.Required,
+		Dependencies:         s.Dependencies,
 		AdditionalProperties: s.AdditionalProperties,
+		Extensions:           s.Extensions,
 	}
 	for n, p := range s.Properties {
 		js.Properties[n] = p.Dup()
@@ -445,7 +560,43 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	}
 	s.DefaultValue = toStringMap(at.DefaultValue)
 	s.Description = at.Description
-	s.Example = at.GenerateExample(api.RandomGenerator(), nil)
+	if ext := ExtensionsFromMetadata(at.Metadata); ext != nil {
+		s.Extensions = ext
+	}
+	s.ReadOnly = at.ReadOnly
+	if at.WriteOnly {
+		// Swagger 2.0 schemas have no writeOnly property.
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]interface{})
+		}
+		s.Extensions["x-writeOnly"] = true
+	}
+	if at.HasTimeEncoding() {
+		// JSON schemas describe a single date time encoding.
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]interface{})
+		}
+		format, zone := at.TimeOutput()
+		s.Extensions["x-time-formats"] = at.TimeFormats()
+		s.Extensions["x-time-output"] = format
+		if zone != "" {
+			s.Extensions["x-time-zone"] = zone
+		}
+	}
+	if at.FieldNumber > 0 {
+		// JSON schemas have no notion of protocol buffers field numbers.
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]interface{})
+		}
+		s.Extensions["x-field-number"] = at.FieldNumber
+	}
+	s.Example = RedactExample(at, at.GenerateExample(api.RandomGenerator(), nil))
+	for _, ex := range at.Examples {
+		if s.Examples == nil {
+			s.Examples = make(map[string]interface{}, len(at.Examples))
+		}
+		s.Examples[ex.Summary] = RedactExample(at, ex.Value)
+	}
 	val := at.Validation
 	if val == nil {
 		return s
@@ -453,11 +604,14 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	s.Enum = val.Values
 	s.Format = val.Format
 	s.Pattern = val.Pattern
-	if val.Minimum != nil {
-		s.Minimum = val.Minimum
+	if min, exclusive := val.LowerBound(); min != nil {
+		s.Minimum, s.ExclusiveMinimum = min, exclusive
+	}
+	if max, exclusive := val.UpperBound(); max != nil {
+		s.Maximum, s.ExclusiveMaximum = max, exclusive
 	}
-	if val.Maximum != nil {
-		s.Maximum = val.Maximum
+	if val.MultipleOf != nil {
+		s.MultipleOf = val.MultipleOf
 	}
 	if val.MinLength != nil {
 		s.MinLength = val.MinLength
@@ -465,10 +619,127 @@ func buildAttributeSchema(api *design.APIDefinition, s *JSONSchema, at *design.A
 	if val.MaxLength != nil {
 		s.MaxLength = val.MaxLength
 	}
-	s.Required = val.Required
+	s.Required = attributeKeys(at, val.Required)
+	var together [][]string
+	for _, g := range val.RequiredTogether {
+		together = append(together, attributeKeys(at, g))
+	}
+	s.Dependencies = dependencies(together)
+	for _, g := range val.MutuallyExclusive {
+		s.Description = addParagraph(s.Description, fmt.Sprintf("At most one of %s may be set.", strings.Join(attributeKeys(at, g), ", ")))
+	}
+	for _, g := range val.AtLeastOneOf {
+		s.Description = addParagraph(s.Description, fmt.Sprintf("At least one of %s must be set.", strings.Join(attributeKeys(at, g), ", ")))
+	}
 	return s
 }
 
+// attributeKeys returns the keys of the child attributes of at with the given names, see the Key
+// DSL.
+func attributeKeys(at *design.AttributeDefinition, names []string) []string {
+	obj := at.Type.ToObject()
+	if obj == nil || len(names) == 0 {
+		return names
+	}
+	keys := make([]string, len(names))
+	for i, n := range names {
+		keys[i] = n
+		if att, ok := obj[n]; ok {
+			keys[i] = att.AttributeKey(n)
+		}
+	}
+	return keys
+}
+
+// dependencies returns the JSON schema property dependencies that describe the given groups of
+// properties that must be set together, nil if there is none.
+func dependencies(groups [][]string) map[string][]string {
+	if len(groups) == 0 {
+		return nil
+	}
+	deps := make(map[string][]string)
+	for _, g := range groups {
+		for _, n := range g {
+			for _, o := range g {
+				if o != n && !contains(deps[n], o) {
+					deps[n] = append(deps[n], o)
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// addParagraph appends the given paragraph to the description.
+func addParagraph(desc, paragraph string) string {
+	if desc == "" {
+		return paragraph
+	}
+	return desc + "\n\n" + paragraph
+}
+
+// contains returns true if the given list contains the given value.
+func contains(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedExample is the example value used in place of sensitive strings.
+const redactedExample = "********"
+
+// RedactExample masks the values of the sensitive attributes in the given example. The example of
+// a sensitive attribute that is not a string is omitted altogether.
+func RedactExample(at *design.AttributeDefinition, example interface{}) interface{} {
+	if example == nil {
+		return nil
+	}
+	if at.Sensitive {
+		if at.Type.Kind() == design.StringKind {
+			return redactedExample
+		}
+		return nil
+	}
+	switch {
+	case at.Type.IsObject():
+		m, ok := example.(map[string]interface{})
+		if !ok {
+			return example
+		}
+		byKey := make(map[string]*design.AttributeDefinition)
+		for n, att := range at.Type.ToObject() {
+			byKey[att.AttributeKey(n)] = att
+		}
+		res := make(map[string]interface{}, len(m))
+		for n, v := range m {
+			if att, ok := byKey[n]; ok {
+				if v = RedactExample(att, v); v == nil {
+					continue
+				}
+			}
+			res[n] = v
+		}
+		return res
+	case at.Type.IsArray():
+		val := reflect.ValueOf(example)
+		if val.Kind() != reflect.Slice {
+			return example
+		}
+		elem := at.Type.ToArray().ElemType
+		res := make([]interface{}, 0, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			if v := RedactExample(elem, val.Index(i).Interface()); v != nil {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+	return example
+}
+
 // toStringMap converts map[interface{}]interface{} to a map[string]interface{} when possible.
 func toStringMap(val interface{}) interface{} {
 	switch actual := val.(type) {
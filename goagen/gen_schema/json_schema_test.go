@@ -1,6 +1,8 @@
 package genschema_test
 
 import (
+	"encoding/json"
+
 	"github.com/goadesign/goa/design"
 	. "github.com/goadesign/goa/design/apidsl"
 	"github.com/goadesign/goa/dslengine"
@@ -46,6 +48,160 @@ var _ = Describe("TypeSchema", func() {
 		})
 	})
 
+	Context("with a type with sensitive attributes", func() {
+		BeforeEach(func() {
+			Type("Credentials", func() {
+				Attribute("login", design.String, func() {
+					Example("foo")
+				})
+				Attribute("password", design.String, func() {
+					Example("bar")
+					Sensitive()
+				})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Credentials"]
+		})
+
+		It("masks the sensitive examples", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Credentials"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["login"].Example).Should(Equal("foo"))
+			Ω(def.Properties["password"].Example).Should(Equal("********"))
+			Ω(def.Example).Should(Equal(map[string]interface{}{"login": "foo", "password": "********"}))
+		})
+	})
+
+	Context("with a type with named examples", func() {
+		BeforeEach(func() {
+			Type("Bottle", func() {
+				Attribute("vintage", design.Integer, func() {
+					Example("recent", 2015)
+					Example("old", 1962)
+				})
+				Attribute("secret", design.String, func() {
+					Example("short", "foo")
+					Sensitive()
+				})
+				Example("empty", map[string]interface{}{})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Bottle"]
+		})
+
+		It("lists the named examples", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Bottle"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Examples).Should(Equal(map[string]interface{}{"empty": map[string]interface{}{}}))
+			Ω(def.Properties["vintage"].Example).Should(Equal(2015))
+			Ω(def.Properties["vintage"].Examples).Should(Equal(map[string]interface{}{"recent": 2015, "old": 1962}))
+			Ω(def.Properties["secret"].Examples).Should(Equal(map[string]interface{}{"short": "********"}))
+		})
+	})
+
+	Context("with a type with vendor extensions", func() {
+		BeforeEach(func() {
+			Type("Barrel", func() {
+				Metadata("openapi:extension:x-type", `{"kind":"wine"}`)
+				Attribute("vintage", design.Integer, func() {
+					Metadata("swagger:extension:x-since", "1.2")
+					Metadata("openapi:extension:y-ignored", "true")
+				})
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Barrel"]
+		})
+
+		It("sets the schema extensions", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Barrel"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Extensions).Should(Equal(map[string]interface{}{"x-type": map[string]interface{}{"kind": "wine"}}))
+			Ω(def.Properties["vintage"].Extensions).Should(Equal(map[string]interface{}{"x-since": 1.2}))
+		})
+
+		It("marshals the extensions with the schema fields", func() {
+			b, err := json.Marshal(genschema.Definitions["Barrel"])
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(b)).Should(ContainSubstring(`"x-type":{"kind":"wine"}`))
+			Ω(string(b)).Should(ContainSubstring(`"x-since":1.2`))
+			Ω(string(b)).Should(ContainSubstring(`"type":"integer"`))
+			Ω(string(b)).ShouldNot(ContainSubstring("y-ignored"))
+		})
+	})
+
+	Context("with a type with field group validations", func() {
+		BeforeEach(func() {
+			Type("Period", func() {
+				Description("A period")
+				Attribute("start", design.DateTime)
+				Attribute("end", design.DateTime)
+				Attribute("days", design.Integer)
+				RequiredTogether("start", "end")
+				MutuallyExclusive("end", "days")
+				AtLeastOneOf("start", "days")
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Period"]
+		})
+
+		It("describes the groups", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Period"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Dependencies).Should(Equal(map[string][]string{"start": {"end"}, "end": {"start"}}))
+			Ω(def.Description).Should(Equal("A period\n\nAt most one of end, days may be set.\n\nAt least one of start, days must be set."))
+		})
+	})
+
+	Context("with a type with read-only and write-only attributes", func() {
+		BeforeEach(func() {
+			Type("Login", func() {
+				Attribute("id", design.Integer, func() { ReadOnly() })
+				Attribute("password", design.String, func() { WriteOnly() })
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Login"]
+		})
+
+		It("flags the properties", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["Login"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["id"].ReadOnly).Should(BeTrue())
+			Ω(def.Properties["id"].Extensions).Should(BeNil())
+			Ω(def.Properties["password"].ReadOnly).Should(BeFalse())
+			Ω(def.Properties["password"].Extensions).Should(Equal(map[string]interface{}{"x-writeOnly": true}))
+		})
+	})
+
+	Context("with a type with field numbers", func() {
+		BeforeEach(func() {
+			Type("NumberedBottle", func() {
+				Field(1, "name", design.String)
+				Attribute("vintage", design.Integer)
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["NumberedBottle"]
+		})
+
+		It("describes the field numbers", func() {
+			Ω(s).ShouldNot(BeNil())
+			def := genschema.Definitions["NumberedBottle"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["name"].Extensions).Should(Equal(map[string]interface{}{"x-field-number": 1}))
+			Ω(def.Properties["vintage"].Extensions).Should(BeNil())
+		})
+	})
+
 	Context("with a media type with self-referencing attributes", func() {
 		BeforeEach(func() {
 			MediaType("application/vnd.menu+json", func() {
@@ -78,4 +234,64 @@ var _ = Describe("TypeSchema", func() {
 		})
 
 	})
+
+	Context("with a recursive type and a maximum recursion depth", func() {
+		BeforeEach(func() {
+			genschema.Definitions = make(map[string]*genschema.JSONSchema)
+			API("test", func() {
+				MaxRecursionDepth(1)
+			})
+			Type("Node", func() {
+				Attribute("name", design.String, func() {
+					Example("foo")
+				})
+				Attribute("parent", "Node")
+			})
+
+			Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+			typ = design.Design.Types["Node"]
+		})
+
+		It("refers to the type and limits the depth of the example", func() {
+			Ω(s.Ref).Should(Equal("#/definitions/Node"))
+			def := genschema.Definitions["Node"]
+			Ω(def).ShouldNot(BeNil())
+			Ω(def.Properties["parent"].Ref).Should(Equal("#/definitions/Node"))
+			Ω(def.Example).Should(Equal(map[string]interface{}{
+				"name":   "foo",
+				"parent": map[string]interface{}{"name": "foo"},
+			}))
+		})
+	})
+})
+
+var _ = Describe("StandaloneSchema", func() {
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+	})
+
+	It("includes the definitions of the referenced types", func() {
+		Type("Address", func() {
+			Attribute("city", design.String)
+		})
+		Type("Account", func() {
+			Attribute("address", "Address")
+			Attribute("previous", ArrayOf("Address"))
+		})
+		Type("Unrelated", func() {
+			Attribute("name", design.String)
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+		s := genschema.StandaloneSchema(design.Design, design.Design.Types["Account"])
+		Ω(s).ShouldNot(BeNil())
+		Ω(s.Title).Should(Equal("Account"))
+		Ω(s.Schema).Should(Equal(genschema.SchemaRef))
+		Ω(s.Definitions).Should(HaveLen(1))
+		Ω(s.Definitions).Should(HaveKey("Address"))
+	})
+
+	It("returns nil for the other types", func() {
+		Ω(genschema.StandaloneSchema(design.Design, design.String)).Should(BeNil())
+	})
 })
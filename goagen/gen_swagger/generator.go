@@ -1,6 +1,7 @@
 package genswagger
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -30,14 +31,15 @@ func NewGenerator(options ...Option) *Generator {
 type Generator struct {
 	API      *design.APIDefinition // The API definition
 	OutDir   string                // Path to output directory
+	Split    bool                  // Whether to also write the per resource documents
 	genfiles []string              // Generated files
 }
 
 // Generate is the generator entry point called by the meta generator.
 func Generate() (files []string, err error) {
 	var (
-		outDir, toolDir, target, ver string
-		notool, regen                bool
+		outDir, toolDir, target, ver, services string
+		notool, regen, split                   bool
 	)
 
 	set := flag.NewFlagSet("swagger", flag.PanicOnError)
@@ -49,14 +51,26 @@ func Generate() (files []string, err error) {
 	set.StringVar(&target, "pkg", "app", "")
 	set.BoolVar(&regen, "regen", false, "")
 	set.Bool("force", false, "")
+	set.Bool("stubs", false, "")
+	set.Bool("deploy", false, "")
+	set.Bool("http3", false, "")
+	set.String("app-pkg", "", "")
 	set.Bool("notest", false, "")
+	set.String("di", "", "")
+	set.StringVar(&services, "service", "", "")
+	set.BoolVar(&split, "split", false, "")
 	set.Parse(os.Args[1:])
 
 	if err := codegen.CheckVersion(ver); err != nil {
 		return nil, err
 	}
 
-	g := &Generator{OutDir: outDir, API: design.Design}
+	api, err := codegen.SelectedAPI(services)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{OutDir: outDir, API: api, Split: split}
 
 	return g.Generate()
 }
@@ -79,6 +93,9 @@ func (g *Generator) Generate() (_ []string, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = s.Validate(g.API); err != nil {
+		return nil, fmt.Errorf("invalid swagger specification:\n%s", err)
+	}
 
 	swaggerDir := filepath.Join(g.OutDir, "swagger")
 	os.RemoveAll(swaggerDir)
@@ -98,23 +115,79 @@ func (g *Generator) Generate() (_ []string, err error) {
 	}
 	g.genfiles = append(g.genfiles, swaggerFile)
 
-	// YAML
-	var yamlSource interface{}
-	if err = json.Unmarshal(rawJSON, &yamlSource); err != nil {
+	// YAML, openapi.yaml is the name expected by most tools, swagger.yaml is kept for
+	// backwards compatibility.
+	rawYAML, err := JSONToYAML(rawJSON)
+	if err != nil {
 		return nil, err
 	}
+	for _, name := range []string{"openapi.yaml", "swagger.yaml"} {
+		swaggerFile = filepath.Join(swaggerDir, name)
+		if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
+			return nil, err
+		}
+		g.genfiles = append(g.genfiles, swaggerFile)
+	}
+
+	// Shared definitions and per resource documents
+	if g.Split {
+		if err := g.generateSplit(swaggerDir, s); err != nil {
+			return nil, err
+		}
+	}
 
-	rawYAML, err := yaml.Marshal(yamlSource)
+	return g.genfiles, nil
+}
+
+// JSONToYAML converts the given JSON document to YAML. Unlike a round trip through a map the
+// conversion preserves the order of the object keys so that the YAML document lists the fields
+// in the same order as the JSON document.
+func JSONToYAML(rawJSON []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(rawJSON))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
 	if err != nil {
 		return nil, err
 	}
-	swaggerFile = filepath.Join(swaggerDir, "swagger.yaml")
-	if err := ioutil.WriteFile(swaggerFile, rawYAML, 0644); err != nil {
+	return yaml.Marshal(v)
+}
+
+// decodeOrdered decodes the next JSON value read from dec. Objects are decoded into
+// yaml.MapSlice values to retain the order of their keys.
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	t, err := dec.Token()
+	if err != nil {
 		return nil, err
 	}
-	g.genfiles = append(g.genfiles, swaggerFile)
-
-	return g.genfiles, nil
+	switch t {
+	case json.Delim('{'):
+		obj := yaml.MapSlice{}
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, yaml.MapItem{Key: k, Value: v})
+		}
+		_, err = dec.Token() // closing brace
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			v, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = dec.Token() // closing bracket
+		return arr, err
+	}
+	return t, nil
 }
 
 // Cleanup removes all the files generated by this generator during the last invokation of Generate.
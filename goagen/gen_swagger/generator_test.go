@@ -1,7 +1,14 @@
 package genswagger_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/dslengine"
+	"github.com/goadesign/goa/goagen/gen_schema"
 	"github.com/goadesign/goa/goagen/gen_swagger"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -36,3 +43,108 @@ var _ = Describe("NewGenerator", func() {
 		})
 	})
 })
+
+var _ = Describe("JSONToYAML", func() {
+	It("preserves the order of the object keys", func() {
+		raw := []byte(`{"swagger":"2.0","info":{"title":"t","version":""},"paths":{"/b":{},"/a":{}},"tags":[{"name":"x","description":"y"}],"minimum":0.5,"count":3}`)
+		y, err := genswagger.JSONToYAML(raw)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(y)).Should(Equal(`swagger: "2.0"
+info:
+  title: t
+  version: ""
+paths:
+  /b: {}
+  /a: {}
+tags:
+- name: x
+  description: "y"
+minimum: 0.5
+count: 3
+`))
+	})
+
+	It("fails on invalid JSON", func() {
+		_, err := genswagger.JSONToYAML([]byte(`{"swagger":`))
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Generate", func() {
+	var outDir string
+	var files []string
+	var genErr error
+
+	BeforeEach(func() {
+		var err error
+		outDir, err = ioutil.TempDir("", "")
+		Ω(err).ShouldNot(HaveOccurred())
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		API("split", func() {
+			Title("split")
+		})
+		bottle := MediaType("application/vnd.bottle", func() {
+			Attributes(func() {
+				Attribute("name", design.String)
+			})
+			View("default", func() {
+				Attribute("name")
+			})
+		})
+		Resource("bottle", func() {
+			Action("show", func() {
+				Routing(GET("/bottles/:id"))
+				Response(design.OK, bottle)
+			})
+		})
+		Resource("account", func() {
+			Action("show", func() {
+				Routing(GET("/accounts/:id"))
+				Response(design.NoContent)
+			})
+		})
+		Ω(dslengine.Run()).ShouldNot(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		g := genswagger.NewGenerator(
+			genswagger.API(design.Design),
+			genswagger.OutDir(outDir),
+			genswagger.Split(true),
+		)
+		files, genErr = g.Generate()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outDir)
+	})
+
+	Context("with Split", func() {
+		It("writes the shared definitions and one document per resource", func() {
+			Ω(genErr).ShouldNot(HaveOccurred())
+			Ω(files).Should(ContainElement(filepath.Join(outDir, "swagger", "definitions.json")))
+			merged, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "swagger.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(merged)).Should(ContainSubstring(`"$ref":"#/definitions/Bottle"`))
+			defs, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "definitions.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(defs)).Should(ContainSubstring(`"Bottle":`))
+			doc, err := ioutil.ReadFile(filepath.Join(outDir, "swagger", "services", "bottle.json"))
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(doc)).Should(ContainSubstring(`"$ref":"../definitions.json#/Bottle"`))
+			Ω(string(doc)).ShouldNot(ContainSubstring("/accounts/{id}"))
+			Ω(string(doc)).ShouldNot(ContainSubstring(`"definitions"`))
+			_, err = os.Stat(filepath.Join(outDir, "swagger", "services", "account.yaml"))
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ExternalizeRefs", func() {
+	It("rewrites the references to the definitions", func() {
+		raw := []byte(`{"schema":{"$ref":"#/definitions/Bottle"},"other":{"$ref":"#/responses/OK"}}`)
+		Ω(string(genswagger.ExternalizeRefs(raw, "definitions.json#/"))).Should(Equal(
+			`{"schema":{"$ref":"definitions.json#/Bottle"},"other":{"$ref":"#/responses/OK"}}`))
+	})
+})
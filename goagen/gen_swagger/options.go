@@ -18,3 +18,10 @@ func OutDir(outDir string) Option {
 		g.OutDir = outDir
 	}
 }
+
+//Split Whether to also write the shared definitions and the per resource documents
+func Split(split bool) Option {
+	return func(g *Generator) {
+		g.Split = split
+	}
+}
@@ -0,0 +1,73 @@
+package genswagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goadesign/goa/goagen/codegen"
+)
+
+// generateSplit writes the definitions of the merged document s to definitions.json and one
+// document per resource to the services directory. The resource documents do not embed the
+// definitions, they reference them with $ref so that each document stays small enough for the
+// gateways that limit the size of the specifications they load.
+func (g *Generator) generateSplit(swaggerDir string, s *Swagger) error {
+	rawDefs, err := json.Marshal(s.Definitions)
+	if err != nil {
+		return err
+	}
+	defsFile := filepath.Join(swaggerDir, "definitions.json")
+	if err := ioutil.WriteFile(defsFile, ExternalizeRefs(rawDefs, "#/"), 0644); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, defsFile)
+
+	servicesDir := filepath.Join(swaggerDir, "services")
+	if err := os.MkdirAll(servicesDir, 0755); err != nil {
+		return err
+	}
+	g.genfiles = append(g.genfiles, servicesDir)
+	names := make([]string, 0, len(g.API.Resources))
+	for n := range g.API.Resources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		api, err := g.API.SelectResources([]string{n})
+		if err != nil {
+			return err
+		}
+		rs, err := New(api)
+		if err != nil {
+			return err
+		}
+		rs.Definitions = nil
+		rawJSON, err := json.Marshal(rs)
+		if err != nil {
+			return err
+		}
+		rawJSON = ExternalizeRefs(rawJSON, "../definitions.json#/")
+		rawYAML, err := JSONToYAML(rawJSON)
+		if err != nil {
+			return err
+		}
+		base := filepath.Join(servicesDir, codegen.SnakeCase(n))
+		for ext, raw := range map[string][]byte{".json": rawJSON, ".yaml": rawYAML} {
+			if err := ioutil.WriteFile(base+ext, raw, 0644); err != nil {
+				return err
+			}
+			g.genfiles = append(g.genfiles, base+ext)
+		}
+	}
+	return nil
+}
+
+// ExternalizeRefs rewrites the references to the definitions of the JSON document raw so that
+// they use the given prefix instead of "#/definitions/", e.g. "definitions.json#/".
+func ExternalizeRefs(raw []byte, prefix string) []byte {
+	return bytes.Replace(raw, []byte(`"$ref":"#/definitions/`), []byte(`"$ref":"`+prefix), -1)
+}
@@ -28,6 +28,7 @@ type (
 		Parameters          map[string]*Parameter            `json:"parameters,omitempty"`
 		Responses           map[string]*Response             `json:"responses,omitempty"`
 		SecurityDefinitions map[string]*SecurityDefinition   `json:"securityDefinitions,omitempty"`
+		Security            []map[string][]string            `json:"security,omitempty"`
 		Tags                []*Tag                           `json:"tags,omitempty"`
 		ExternalDocs        *ExternalDocs                    `json:"externalDocs,omitempty"`
 	}
@@ -165,6 +166,8 @@ type (
 		Schema *genschema.JSONSchema `json:"schema,omitempty"`
 		// Headers is a list of headers that are sent with the response.
 		Headers map[string]*Header `json:"headers,omitempty"`
+		// Examples lists the examples of the response body indexed by content type.
+		Examples map[string]interface{} `json:"examples,omitempty"`
 		// Ref references a global API response.
 		// This field is exclusive with the other fields of Response.
 		Ref string `json:"$ref,omitempty"`
@@ -323,6 +326,17 @@ func marshalJSON(v interface{}, extensions map[string]interface{}) ([]byte, erro
 	return merged, nil
 }
 
+// withField returns a copy of the given extensions that also sets the given field. It makes it
+// possible to marshal empty values that the omitempty tag of the field would drop.
+func withField(extensions map[string]interface{}, name string, val interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(extensions)+1)
+	for k, v := range extensions {
+		fields[k] = v
+	}
+	fields[name] = val
+	return fields
+}
+
 // MarshalJSON returns the JSON encoding of i.
 func (i Info) MarshalJSON() ([]byte, error) {
 	return marshalJSON(_Info(i), i.Extensions)
@@ -335,6 +349,10 @@ func (p Path) MarshalJSON() ([]byte, error) {
 
 // MarshalJSON returns the JSON encoding of o.
 func (o Operation) MarshalJSON() ([]byte, error) {
+	if o.Security != nil && len(o.Security) == 0 {
+		// An empty list opts out of the API security requirements, keep it.
+		return marshalJSON(_Operation(o), withField(o.Extensions, "security", o.Security))
+	}
 	return marshalJSON(_Operation(o), o.Extensions)
 }
 
@@ -350,6 +368,10 @@ func (r Response) MarshalJSON() ([]byte, error) {
 
 // MarshalJSON returns the JSON encoding of s.
 func (s SecurityDefinition) MarshalJSON() ([]byte, error) {
+	if s.Type == "oauth2" && len(s.Scopes) == 0 {
+		// The scopes are required for OAuth2 schemes even if there is none.
+		return marshalJSON(_SecurityDefinition(s), withField(s.Extensions, "scopes", map[string]string{}))
+	}
 	return marshalJSON(_SecurityDefinition(s), s.Extensions)
 }
 
@@ -363,7 +385,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 	if api == nil {
 		return nil, nil
 	}
-	tags := tagsFromDefinition(api.Metadata)
+	tags := describedTags(api, tagsFromDefinition(api.Metadata))
 	basePath := api.BasePath
 	if hasAbsoluteRoutes(api) {
 		basePath = ""
@@ -387,27 +409,47 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 	for _, p := range api.Produces {
 		produces = append(produces, p.MIMETypes...)
 	}
+	title := api.Title
+	if title == "" {
+		// The Swagger spec requires a title, default to the API name.
+		title = api.Name
+	}
+	host, schemes := api.Host, api.Schemes
+	infoExtensions := genschema.ExtensionsFromMetadata(api.Metadata)
+	if len(api.Servers) > 0 {
+		if host == "" {
+			host = api.Servers[0].ResolveHost(nil)
+			if len(schemes) == 0 {
+				schemes = api.Servers[0].Schemes
+			}
+		}
+		if infoExtensions == nil {
+			infoExtensions = make(map[string]interface{})
+		}
+		infoExtensions["x-servers"] = serversFromDefinition(api.Servers)
+	}
 	s := &Swagger{
 		Swagger: "2.0",
 		Info: &Info{
-			Title:          api.Title,
+			Title:          title,
 			Description:    api.Description,
 			TermsOfService: api.TermsOfService,
 			Contact:        api.Contact,
 			License:        api.License,
 			Version:        api.Version,
-			Extensions:     extensionsFromDefinition(api.Metadata),
+			Extensions:     infoExtensions,
 		},
-		Host:                api.Host,
+		Host:                host,
 		BasePath:            basePath,
 		Paths:               make(map[string]interface{}),
-		Schemes:             api.Schemes,
+		Schemes:             schemes,
 		Consumes:            consumes,
 		Produces:            produces,
 		Parameters:          paramMap,
 		Tags:                tags,
 		ExternalDocs:        docsFromDefinition(api.Docs),
 		SecurityDefinitions: securityDefsFromDefinition(api.SecuritySchemes),
+		Security:            securityFromDefinition(api.Security),
 	}
 
 	err = api.IterateResponses(func(r *design.ResponseDefinition) error {
@@ -425,7 +467,7 @@ func New(api *design.APIDefinition) (*Swagger, error) {
 		return nil, err
 	}
 	err = api.IterateResources(func(res *design.ResourceDefinition) error {
-		for k, v := range extensionsFromDefinition(res.Metadata) {
+		for k, v := range genschema.ExtensionsFromMetadata(res.Metadata) {
 			s.Paths[k] = v
 		}
 		err := res.IterateFileServers(func(fs *design.FileServerDefinition) error {
@@ -525,15 +567,39 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 			AuthorizationURL: scheme.AuthorizationURL,
 			TokenURL:         scheme.TokenURL,
 			Scopes:           scheme.Scopes,
-			Extensions:       extensionsFromDefinition(scheme.Metadata),
+			Extensions:       genschema.ExtensionsFromMetadata(scheme.Metadata),
+		}
+		if scheme.Kind == design.CookieSecurityKind {
+			def.In = "header"
+			def.Name = "Cookie"
+			if def.Extensions == nil {
+				def.Extensions = make(map[string]interface{})
+			}
+			def.Extensions["x-cookie-name"] = scheme.Name
+		}
+		if scheme.Kind == design.SPIFFESecurityKind {
+			def.In = "header"
+			def.Name = "X-Forwarded-Client-Cert"
+			def.Description = joinParagraph(def.Description, fmt.Sprintf("**Mutual TLS**: clients authenticate with the X.509 SVID of a workload of the `%s` trust domain.", scheme.TrustDomain))
+			if len(def.Scopes) != 0 {
+				def.Description = joinParagraph(def.Description, fmt.Sprintf("**Security Scopes**:\n%s", scopesMapList(def.Scopes)))
+				def.Scopes = nil
+			}
+			if def.Extensions == nil {
+				def.Extensions = make(map[string]interface{})
+			}
+			def.Extensions["x-spiffe"] = map[string]interface{}{
+				"trustDomain": scheme.TrustDomain,
+				"identities":  scheme.Identities,
+			}
 		}
 		if scheme.Kind == design.JWTSecurityKind {
 			if def.TokenURL != "" {
-				def.Description += fmt.Sprintf("\n\n**Token URL**: %s", def.TokenURL)
+				def.Description = joinParagraph(def.Description, fmt.Sprintf("**Token URL**: %s", def.TokenURL))
 				def.TokenURL = ""
 			}
 			if len(def.Scopes) != 0 {
-				def.Description += fmt.Sprintf("\n\n**Security Scopes**:\n%s", scopesMapList(def.Scopes))
+				def.Description = joinParagraph(def.Description, fmt.Sprintf("**Security Scopes**:\n%s", scopesMapList(def.Scopes)))
 				def.Scopes = nil
 			}
 		}
@@ -542,6 +608,14 @@ func securityDefsFromDefinition(schemes []*design.SecuritySchemeDefinition) map[
 	return defs
 }
 
+// joinParagraph appends the given paragraph to the description.
+func joinParagraph(description, paragraph string) string {
+	if description == "" {
+		return paragraph
+	}
+	return description + "\n\n" + paragraph
+}
+
 func scopesMapList(scopes map[string]string) string {
 	names := []string{}
 	for name := range scopes {
@@ -597,7 +671,7 @@ func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
 			tag.ExternalDocs = docs
 		}
 
-		tag.Extensions = extensionsFromDefinition(mdata)
+		tag.Extensions = genschema.ExtensionsFromMetadata(mdata)
 
 		tags = append(tags, tag)
 	}
@@ -605,6 +679,65 @@ func tagsFromDefinition(mdata dslengine.MetadataDefinition) (tags []*Tag) {
 	return
 }
 
+// serversFromDefinition builds the value of the "x-servers" extension, it uses the structure of
+// the OpenAPI 3 server objects.
+func serversFromDefinition(servers []*design.ServerDefinition) []map[string]interface{} {
+	res := make([]map[string]interface{}, len(servers))
+	for i, s := range servers {
+		scheme := "http"
+		if len(s.Schemes) > 0 {
+			scheme = s.Schemes[0]
+		}
+		server := map[string]interface{}{
+			"name": s.Name,
+			"url":  scheme + "://" + s.Host,
+		}
+		if s.Description != "" {
+			server["description"] = s.Description
+		}
+		if len(s.Variables) > 0 {
+			vars := make(map[string]interface{}, len(s.Variables))
+			for _, v := range s.Variables {
+				variable := map[string]interface{}{"default": v.Default}
+				if len(v.Values) > 0 {
+					variable["enum"] = v.Values
+				}
+				vars[v.Name] = variable
+			}
+			server["variables"] = vars
+		}
+		res[i] = server
+	}
+	return res
+}
+
+// describedTags appends the tags defined on the API resources and actions that have a description
+// or external docs to the given API tags.
+func describedTags(api *design.APIDefinition, tags []*Tag) []*Tag {
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[t.Name] = true
+	}
+	add := func(mdata dslengine.MetadataDefinition) {
+		for _, t := range tagsFromDefinition(mdata) {
+			if seen[t.Name] || (t.Description == "" && t.ExternalDocs == nil) {
+				continue
+			}
+			seen[t.Name] = true
+			t.Extensions = nil
+			tags = append(tags, t)
+		}
+	}
+	api.IterateResources(func(res *design.ResourceDefinition) error {
+		add(res.Metadata)
+		return res.IterateActions(func(a *design.ActionDefinition) error {
+			add(a.Metadata)
+			return nil
+		})
+	})
+	return tags
+}
+
 func tagNamesFromDefinitions(mdatas ...dslengine.MetadataDefinition) (tagNames []string) {
 	for _, mdata := range mdatas {
 		tags := tagsFromDefinition(mdata)
@@ -624,33 +757,6 @@ func summaryFromDefinition(name string, metadata dslengine.MetadataDefinition) s
 	return name
 }
 
-func extensionsFromDefinition(mdata dslengine.MetadataDefinition) map[string]interface{} {
-	extensions := make(map[string]interface{})
-	for key, value := range mdata {
-		chunks := strings.Split(key, ":")
-		if len(chunks) != 3 {
-			continue
-		}
-		if chunks[0] != "swagger" || chunks[1] != "extension" {
-			continue
-		}
-		if strings.HasPrefix(chunks[2], "x-") != true {
-			continue
-		}
-		val := value[0]
-		ival := interface{}(val)
-		if err := json.Unmarshal([]byte(val), &ival); err != nil {
-			extensions[chunks[2]] = val
-			continue
-		}
-		extensions[chunks[2]] = ival
-	}
-	if len(extensions) == 0 {
-		return nil
-	}
-	return extensions
-}
-
 func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*Parameter, error) {
 	if params == nil {
 		return nil, nil
@@ -662,6 +768,10 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 	res := make([]*Parameter, len(obj))
 	i := 0
 	wildcards := design.ExtractWildcards(path)
+	var catchAll string
+	if i := strings.LastIndex(path, "/*"); i >= 0 {
+		catchAll = path[i+2:]
+	}
 	obj.IterateAttributes(func(n string, at *design.AttributeDefinition) error {
 		in := "query"
 		required := params.IsRequired(n)
@@ -673,6 +783,16 @@ func paramsFromDefinition(params *design.AttributeDefinition, path string) ([]*P
 			}
 		}
 		param := paramFor(at, n, in, required)
+		if in == "path" && n == catchAll {
+			// Swagger path parameters cannot contain slashes, flag the catch-all param.
+			if param.Description == "" {
+				param.Description = "Remainder of the request path, may contain slashes"
+			}
+			if param.Extensions == nil {
+				param.Extensions = make(map[string]interface{})
+			}
+			param.Extensions["x-catch-all"] = true
+		}
 		res[i] = param
 		i++
 		return nil
@@ -687,6 +807,42 @@ func paramsFromHeaders(action *design.ActionDefinition) []*Parameter {
 		params = append(params, p)
 		return nil
 	})
+	for _, g := range action.HeaderGroups {
+		g.Type.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+			p := paramFor(att, n, "header", g.Type.IsRequired(n))
+			p.Name = g.HeaderKey(n)
+			params = append(params, p)
+			return nil
+		})
+	}
+	return params
+}
+
+// paramsFromQueryObjects returns one query parameter per leaf attribute of the action query
+// objects, named after the object with one bracketed segment per nesting level. The parameters
+// are optional as the objects may be omitted altogether, hash attributes cannot be described
+// with fixed names and are omitted.
+func paramsFromQueryObjects(action *design.ActionDefinition) []*Parameter {
+	var params []*Parameter
+	var flatten func(prefix string, obj design.Object)
+	flatten = func(prefix string, obj design.Object) {
+		obj.IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+			key := prefix + "[" + n + "]"
+			switch {
+			case att.Type.IsObject():
+				flatten(key, att.Type.ToObject())
+			case att.Type.IsHash():
+			default:
+				p := paramFor(att, n, "query", false)
+				p.Name = key
+				params = append(params, p)
+			}
+			return nil
+		})
+	}
+	for _, q := range action.QueryObjects {
+		flatten(q.Name, q.Type.ToObject())
+	}
 	return params
 }
 
@@ -714,7 +870,7 @@ func paramsFromPayload(payload *design.UserTypeDefinition) ([]*Parameter, error)
 func paramFor(at *design.AttributeDefinition, name, in string, required bool) *Parameter {
 	p := &Parameter{
 		In:          in,
-		Name:        name,
+		Name:        at.AttributeKey(name),
 		Default:     toStringMap(at.DefaultValue),
 		Description: at.Description,
 		Required:    required,
@@ -722,9 +878,18 @@ func paramFor(at *design.AttributeDefinition, name, in string, required bool) *P
 	}
 	if at.Type.IsArray() {
 		p.Items = itemsFromDefinition(at.Type.ToArray().ElemType)
-		p.CollectionFormat = "multi"
+		switch at.ParamSeparator() {
+		case ",":
+			p.CollectionFormat = "csv"
+		case "|":
+			p.CollectionFormat = "pipes"
+		case " ":
+			p.CollectionFormat = "ssv"
+		default:
+			p.CollectionFormat = "multi"
+		}
 	}
-	p.Extensions = extensionsFromDefinition(at.Metadata)
+	p.Extensions = genschema.ExtensionsFromMetadata(at.Metadata)
 	initValidations(at, p)
 	return p
 }
@@ -790,11 +955,26 @@ func responseSpecFromDefinition(s *Swagger, api *design.APIDefinition, r *design
 	if err != nil {
 		return nil, err
 	}
+	for _, g := range r.HeaderGroups {
+		if headers == nil {
+			headers = make(map[string]*Header)
+		}
+		g.Type.ToObject().IterateAttributes(func(n string, att *design.AttributeDefinition) error {
+			header := &Header{
+				Default:     att.DefaultValue,
+				Description: att.Description,
+				Type:        att.Type.Name(),
+			}
+			initValidations(att, header)
+			headers[g.HeaderKey(n)] = header
+			return nil
+		})
+	}
 	return &Response{
 		Description: r.Description,
 		Schema:      schema,
 		Headers:     headers,
-		Extensions:  extensionsFromDefinition(r.Metadata),
+		Extensions:  genschema.ExtensionsFromMetadata(r.Metadata),
 	}, nil
 }
 
@@ -881,7 +1061,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 		Schemes:      schemes,
 	}
 
-	applySecurity(operation, fs.Security)
+	applySecurity(operation, fs.Security, api)
 
 	key := design.WildcardRegex.ReplaceAllStringFunc(
 		fs.RequestPath,
@@ -900,7 +1080,7 @@ func buildPathFromFileServer(s *Swagger, api *design.APIDefinition, fs *design.F
 	}
 	p := path.(*Path)
 	p.Get = operation
-	p.Extensions = extensionsFromDefinition(fs.Metadata)
+	p.Extensions = genschema.ExtensionsFromMetadata(fs.Metadata)
 
 	return nil
 }
@@ -918,6 +1098,7 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		return err
 	}
 
+	params = append(params, paramsFromQueryObjects(action)...)
 	params = append(params, paramsFromHeaders(action)...)
 
 	responses := make(map[string]*Response, len(action.Responses))
@@ -926,6 +1107,9 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		if err != nil {
 			return err
 		}
+		if desc, ok := route.ResponseDescriptions[r.Name]; ok {
+			resp.Description = desc
+		}
 		responses[strconv.Itoa(r.Status)] = resp
 	}
 
@@ -949,7 +1133,8 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 		}
 	}
 
-	operationID := fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+	applyExamples(api, route, params, responses)
+
 	index := 0
 	for i, rt := range action.Routes {
 		if rt == route {
@@ -957,8 +1142,28 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 			break
 		}
 	}
-	if index > 0 {
-		operationID = fmt.Sprintf("%s#%d", operationID, index)
+	var operationID string
+	switch {
+	case route.OperationID != "":
+		operationID = route.OperationID
+	case action.OperationID != "":
+		operationID = action.OperationID
+		if index > 0 {
+			operationID = fmt.Sprintf("%s%d", operationID, index+1)
+		}
+	default:
+		operationID = fmt.Sprintf("%s#%s", action.Parent.Name, action.Name)
+		if index > 0 {
+			operationID = fmt.Sprintf("%s#%d", operationID, index)
+		}
+	}
+
+	summary := route.Summary
+	if summary == "" {
+		summary = action.Summary
+	}
+	if summary == "" {
+		summary = summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata)
 	}
 
 	schemes := action.Schemes
@@ -969,23 +1174,75 @@ func buildPathFromDefinition(s *Swagger, api *design.APIDefinition, route *desig
 	operation := &Operation{
 		Tags:         tagNames,
 		Description:  action.Description,
-		Summary:      summaryFromDefinition(action.Name+" "+action.Parent.Name, action.Metadata),
+		Summary:      summary,
 		ExternalDocs: docsFromDefinition(action.Docs),
 		OperationID:  operationID,
 		Parameters:   params,
 		Responses:    responses,
 		Schemes:      schemes,
-		Deprecated:   false,
-		Extensions:   extensionsFromDefinition(route.Metadata),
+		Deprecated:   action.Deprecation != nil,
+		Extensions:   genschema.ExtensionsFromMetadata(route.Metadata),
+	}
+	if d := action.Deprecation; d != nil {
+		if operation.Extensions == nil {
+			operation.Extensions = make(map[string]interface{})
+		}
+		operation.Extensions["x-deprecated-since"] = d.Since.Format("2006-01-02")
+		if !d.Sunset.IsZero() {
+			operation.Extensions["x-sunset"] = d.Sunset.Format("2006-01-02")
+		}
 	}
 
 	computeProduces(operation, s, action)
-	applySecurity(operation, action.Security)
+	applySecurity(operation, action.Security, api)
 
 	computePaths(operation, s, route, basePath)
 	return nil
 }
 
+// applyExamples sets the examples of the response bodies and the "x-examples" extension of the
+// payload parameter from the request and response examples of the route. The values of the
+// sensitive attributes of the payload and response media types are masked.
+func applyExamples(api *design.APIDefinition, route *design.RouteDefinition, params []*Parameter, responses map[string]*Response) {
+	var payload *Parameter
+	for _, p := range params {
+		if p.In == "body" {
+			payload = p
+		}
+	}
+	for _, ex := range route.AllHTTPExamples() {
+		if ex.Request != nil && payload != nil {
+			if payload.Extensions == nil {
+				payload.Extensions = make(map[string]interface{})
+			}
+			examples, _ := payload.Extensions["x-examples"].(map[string]interface{})
+			if examples == nil {
+				examples = make(map[string]interface{})
+				payload.Extensions["x-examples"] = examples
+			}
+			req := toStringMap(ex.Request)
+			if p := route.Parent.Payload; p != nil {
+				req = genschema.RedactExample(p.AttributeDefinition, req)
+			}
+			examples[ex.ContentType] = req
+		}
+		r, ok := route.Parent.Responses[ex.Response]
+		if !ok || ex.Body == nil {
+			continue
+		}
+		if resp, ok := responses[strconv.Itoa(r.Status)]; ok {
+			if resp.Examples == nil {
+				resp.Examples = make(map[string]interface{})
+			}
+			body := toStringMap(ex.Body)
+			if mt, ok := api.MediaTypes[design.CanonicalIdentifier(r.MediaType)]; ok {
+				body = genschema.RedactExample(mt.AttributeDefinition, body)
+			}
+			resp.Examples[ex.ContentType] = body
+		}
+	}
+}
+
 func computeProduces(operation *Operation, s *Swagger, action *design.ActionDefinition) {
 	produces := make(map[string]struct{})
 	action.IterateResponses(func(resp *design.ResponseDefinition) error {
@@ -1061,24 +1318,34 @@ func computePaths(operation *Operation, s *Swagger, route *design.RouteDefinitio
 	case "PATCH":
 		p.Patch = operation
 	}
-	p.Extensions = extensionsFromDefinition(route.Parent.Metadata)
+	p.Extensions = genschema.ExtensionsFromMetadata(route.Parent.Metadata)
 }
 
-func applySecurity(operation *Operation, security *design.SecurityDefinition) {
-	if security != nil && security.Scheme.Kind != design.NoSecurityKind {
-		if security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
-			if operation.Description != "" {
-				operation.Description += "\n\n"
-			}
-			operation.Description += fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes))
-		}
-		scopes := security.Scopes
-		if scopes == nil {
-			scopes = make([]string, 0)
-		}
-		sec := []map[string][]string{{security.Scheme.SchemeName: scopes}}
-		operation.Security = sec
+// applySecurity sets the security requirements of the operation. Operations that opt out of the
+// API security requirements with NoSecurity get an empty list of requirements.
+func applySecurity(operation *Operation, security *design.SecurityDefinition, api *design.APIDefinition) {
+	if security != nil && security.Scheme.Kind == design.JWTSecurityKind && len(security.Scopes) > 0 {
+		operation.Description = joinParagraph(operation.Description,
+			fmt.Sprintf("Required security scopes:\n%s", scopesList(security.Scopes)))
+	}
+	operation.Security = securityFromDefinition(security)
+	if operation.Security == nil && securityFromDefinition(api.Security) != nil {
+		operation.Security = make([]map[string][]string, 0)
+	}
+}
+
+// securityFromDefinition returns the security requirements described by the given definition. Only
+// OAuth2 requirements list scopes, Swagger requires an empty list for the other schemes so the
+// scopes of JWT requirements are documented in the operation description instead.
+func securityFromDefinition(security *design.SecurityDefinition) []map[string][]string {
+	if security == nil || security.Scheme == nil || security.Scheme.Kind == design.NoSecurityKind {
+		return nil
 	}
+	scopes := make([]string, 0, len(security.Scopes))
+	if security.Scheme.Kind == design.OAuth2SecurityKind {
+		scopes = append(scopes, security.Scopes...)
+	}
+	return []map[string][]string{{security.Scheme.SchemeName: scopes}}
 }
 
 func scopesList(scopes []string) string {
@@ -1134,31 +1401,42 @@ func initPatternValidation(def interface{}, pattern string) {
 	}
 }
 
-func initMinimumValidation(def interface{}, min *float64) {
+func initMinimumValidation(def interface{}, min *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Header:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	case *Items:
 		actual.Minimum = min
-		actual.ExclusiveMinimum = false
+		actual.ExclusiveMinimum = exclusive
 	}
 }
 
-func initMaximumValidation(def interface{}, max *float64) {
+func initMaximumValidation(def interface{}, max *float64, exclusive bool) {
 	switch actual := def.(type) {
 	case *Parameter:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Header:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
 	case *Items:
 		actual.Maximum = max
-		actual.ExclusiveMaximum = false
+		actual.ExclusiveMaximum = exclusive
+	}
+}
+
+func initMultipleOfValidation(def interface{}, multiple float64) {
+	switch actual := def.(type) {
+	case *Parameter:
+		actual.MultipleOf = multiple
+	case *Header:
+		actual.MultipleOf = multiple
+	case *Items:
+		actual.MultipleOf = multiple
 	}
 }
 
@@ -1200,11 +1478,14 @@ func initValidations(attr *design.AttributeDefinition, def interface{}) {
 	initEnumValidation(def, val.Values)
 	initFormatValidation(def, val.Format)
 	initPatternValidation(def, val.Pattern)
-	if val.Minimum != nil {
-		initMinimumValidation(def, val.Minimum)
+	if min, exclusive := val.LowerBound(); min != nil {
+		initMinimumValidation(def, min, exclusive)
+	}
+	if max, exclusive := val.UpperBound(); max != nil {
+		initMaximumValidation(def, max, exclusive)
 	}
-	if val.Maximum != nil {
-		initMaximumValidation(def, val.Maximum)
+	if val.MultipleOf != nil {
+		initMultipleOfValidation(def, *val.MultipleOf)
 	}
 	if val.MinLength != nil {
 		initMinLengthValidation(def, attr.Type.IsArray(), val.MinLength)
@@ -137,6 +137,31 @@ var _ = Describe("New", func() {
 
 		It("serializes into valid swagger JSON", func() { validateSwagger(swagger) })
 
+		Context("with decimal params", func() {
+			BeforeEach(func() {
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					BasePath("/amounts/:amount")
+					Params(func() {
+						Param("amount", Decimal, func() {
+							Precision(6)
+							Scale(2)
+						})
+					})
+				}
+			})
+
+			It("documents them as strings with the decimal format", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				p := swagger.Parameters["amount"]
+				Ω(p).ShouldNot(BeNil())
+				Ω(p.Type).Should(Equal("string"))
+				Ω(p.Format).Should(Equal("decimal"))
+				Ω(p.Pattern).Should(Equal(`^-?[0-9]{1,4}(\.[0-9]{1,2})?$`))
+			})
+		})
+
 		Context("with base params", func() {
 			const (
 				basePath    = "/s/:strParam/i/:intParam/n/:numParam/b/:boolParam"
@@ -684,5 +709,550 @@ var _ = Describe("New", func() {
 			})
 
 		})
+
+		Context("with security", func() {
+			BeforeEach(func() {
+				basic := BasicAuthSecurity("basic")
+				jwt := JWTSecurity("jwt", func() {
+					Header("Authorization")
+					Scope("api:read", "Read access")
+				})
+				oauth := OAuth2Security("oauth", func() {
+					ApplicationFlow("/token")
+				})
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					Security(basic)
+				}
+				Resource("res", func() {
+					Action("basic", func() {
+						Routing(GET("/basic"))
+						Response(NoContent)
+					})
+					Action("jwt", func() {
+						Security(jwt, func() { Scope("api:read") })
+						Routing(GET("/jwt"))
+						Response(NoContent)
+					})
+					Action("oauth", func() {
+						Security(oauth)
+						Routing(GET("/oauth"))
+						Response(NoContent)
+					})
+					Action("public", func() {
+						NoSecurity()
+						Routing(GET("/public"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("sets the API security requirements", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.Security).Should(Equal([]map[string][]string{{"basic": {}}}))
+				Ω(swagger.SecurityDefinitions).Should(HaveLen(3))
+			})
+
+			It("sets the operation security requirements", func() {
+				op := func(path string) *genswagger.Operation { return swagger.Paths[path].(*genswagger.Path).Get }
+				Ω(op("/basic").Security).Should(Equal([]map[string][]string{{"basic": {}}}))
+				Ω(op("/jwt").Security).Should(Equal([]map[string][]string{{"jwt": {}}}))
+				Ω(op("/jwt").Description).Should(ContainSubstring("`api:read`"))
+				Ω(op("/oauth").Security).Should(Equal([]map[string][]string{{"oauth": {}}}))
+				Ω(op("/public").Security).ShouldNot(BeNil())
+				Ω(op("/public").Security).Should(BeEmpty())
+			})
+
+			It("serializes a valid spec", func() {
+				Ω(swagger.Validate(Design)).ShouldNot(HaveOccurred())
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"security":[]`),
+					[]byte(`"scopes":{}`),
+				})
+			})
+		})
+
+		Context("with cookie security", func() {
+			BeforeEach(func() {
+				session := CookieSecurity("session", func() {
+					Cookie("sid")
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Security(session)
+						Routing(GET("/"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("describes the scheme as an API key in the Cookie header", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				def := swagger.SecurityDefinitions["session"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Type).Should(Equal("apiKey"))
+				Ω(def.In).Should(Equal("header"))
+				Ω(def.Name).Should(Equal("Cookie"))
+				Ω(def.Extensions).Should(HaveKeyWithValue("x-cookie-name", "sid"))
+			})
+		})
+
+		Context("with SPIFFE security", func() {
+			BeforeEach(func() {
+				workload := SPIFFESecurity("workload", func() {
+					TrustDomain("example.org")
+					Scope("bottle:read", "Read bottles")
+					Identity("spiffe://example.org/ns/prod/sa/frontend", "bottle:read")
+				})
+				Resource("res", func() {
+					Action("act", func() {
+						Security(workload, func() {
+							Scope("bottle:read")
+						})
+						Routing(GET("/"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("describes the trust domain and identities in an extension", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				def := swagger.SecurityDefinitions["workload"]
+				Ω(def).ShouldNot(BeNil())
+				Ω(def.Type).Should(Equal("apiKey"))
+				Ω(def.Scopes).Should(BeNil())
+				Ω(def.Description).Should(ContainSubstring("`bottle:read`: Read bottles"))
+				Ω(def.Extensions).Should(HaveKey("x-spiffe"))
+				Ω(def.Extensions["x-spiffe"]).Should(HaveKeyWithValue("trustDomain", "example.org"))
+			})
+		})
+
+		Context("with openapi extensions", func() {
+			BeforeEach(func() {
+				Type("Bottle", func() {
+					Attribute("name", String, func() {
+						Metadata("openapi:extension:x-kong-plugin", `{"name":"rate-limiting"}`)
+					})
+				})
+				Resource("res", func() {
+					Metadata("openapi:extension:x-resource", "res")
+					Action("act", func() {
+						Metadata("openapi:extension:x-action", "act")
+						Routing(PUT("/", func() {
+							Metadata("openapi:extension:x-amazon-apigateway-integration", `{"type":"http_proxy"}`)
+						}))
+						Payload("Bottle")
+						Response(NoContent)
+					})
+				})
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					Metadata("openapi:extension:x-api", "api")
+				}
+			})
+
+			It("sets the extensions at the corresponding spec locations", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.Info.Extensions).Should(Equal(map[string]interface{}{"x-api": "api"}))
+				Ω(swagger.Paths["x-resource"]).Should(Equal("res"))
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Extensions).Should(Equal(map[string]interface{}{"x-action": "act"}))
+				Ω(p.Put.Extensions).Should(Equal(map[string]interface{}{"x-amazon-apigateway-integration": map[string]interface{}{"type": "http_proxy"}}))
+				Ω(swagger.Definitions["ActResPayload"].Properties["name"].Extensions).Should(Equal(map[string]interface{}{"x-kong-plugin": map[string]interface{}{"name": "rate-limiting"}}))
+			})
+
+			It("serializes a valid spec", func() {
+				validateSwaggerWithFragments(swagger, [][]byte{
+					[]byte(`"x-kong-plugin":{"name":"rate-limiting"}`),
+				})
+			})
+		})
+
+		Context("with servers", func() {
+			BeforeEach(func() {
+				base := Design.DSLFunc
+				Design.DSLFunc = func() {
+					base()
+					Server("prod", func() {
+						Host("{region}.example.com")
+						Scheme("https")
+						Variable("region", "us", "us", "eu")
+					})
+				}
+			})
+
+			It("keeps the API host", func() {
+				Ω(newErr).ShouldNot(HaveOccurred())
+				Ω(swagger.Host).Should(Equal(host))
+			})
+
+			It("lists the servers in the info extensions", func() {
+				Ω(swagger.Info.Extensions["x-servers"]).Should(Equal([]map[string]interface{}{{
+					"name": "prod",
+					"url":  "https://{region}.example.com",
+					"variables": map[string]interface{}{
+						"region": map[string]interface{}{"default": "us", "enum": []string{"us", "eu"}},
+					},
+				}}))
+			})
+		})
+
+		Context("with styled array params", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("list", func() {
+						Routing(GET("/"))
+						Params(func() {
+							Param("ids", ArrayOf(Integer), func() {
+								Style(FormStyle, false)
+							})
+							Param("tags", ArrayOf(String), func() {
+								Style(SpaceDelimitedStyle)
+							})
+						})
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("sets the collection formats", func() {
+				p := swagger.Paths["/"].(*genswagger.Path)
+				formats := make(map[string]string)
+				for _, param := range p.Get.Parameters {
+					formats[param.Name] = param.CollectionFormat
+				}
+				Ω(formats).Should(Equal(map[string]string{"ids": "csv", "tags": "ssv"}))
+			})
+		})
+
+		Context("with a catch-all route", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("download", func() {
+						Routing(GET("/files/*path"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("flags the catch-all param", func() {
+				p := swagger.Paths["/files/{path}"].(*genswagger.Path)
+				Ω(p.Get.Parameters).Should(HaveLen(1))
+				param := p.Get.Parameters[0]
+				Ω(param.In).Should(Equal("path"))
+				Ω(param.Extensions).Should(Equal(map[string]interface{}{"x-catch-all": true}))
+			})
+		})
+
+		Context("with operation IDs and summaries", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("list", func() {
+						OperationID("listThings")
+						Summary("List things")
+						Routing(
+							GET("/things"),
+							GET("/items"),
+							GET("/all", func() {
+								OperationID("listAllThings")
+								Summary("List all things")
+								ResponseDescription("OK", "All the things")
+							}),
+						)
+						Response(OK)
+					})
+					Action("show", func() {
+						Routing(GET("/things/:id"), GET("/items/:id"))
+						Response(OK)
+					})
+				})
+			})
+
+			It("uses the explicit operation IDs", func() {
+				Ω(swagger.Paths["/things"].(*genswagger.Path).Get.OperationID).Should(Equal("listThings"))
+				Ω(swagger.Paths["/items"].(*genswagger.Path).Get.OperationID).Should(Equal("listThings2"))
+				Ω(swagger.Paths["/all"].(*genswagger.Path).Get.OperationID).Should(Equal("listAllThings"))
+			})
+
+			It("defaults the operation IDs", func() {
+				Ω(swagger.Paths["/things/{id}"].(*genswagger.Path).Get.OperationID).Should(Equal("res#show"))
+				Ω(swagger.Paths["/items/{id}"].(*genswagger.Path).Get.OperationID).Should(Equal("res#show#1"))
+			})
+
+			It("sets the summaries", func() {
+				Ω(swagger.Paths["/things"].(*genswagger.Path).Get.Summary).Should(Equal("List things"))
+				Ω(swagger.Paths["/all"].(*genswagger.Path).Get.Summary).Should(Equal("List all things"))
+				Ω(swagger.Paths["/things/{id}"].(*genswagger.Path).Get.Summary).Should(Equal("show res"))
+			})
+
+			It("overrides the route response descriptions", func() {
+				Ω(swagger.Paths["/things"].(*genswagger.Path).Get.Responses["200"].Description).Should(Equal("OK"))
+				Ω(swagger.Paths["/all"].(*genswagger.Path).Get.Responses["200"].Description).Should(Equal("All the things"))
+			})
+		})
+
+		Context("with request and response examples", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("create", func() {
+						Routing(
+							POST("/things"),
+							POST("/items", func() {
+								HTTPExample("application/json", map[string]interface{}{"name": "item"}, "Created", map[string]interface{}{"id": 2})
+							}),
+						)
+						Payload(func() { Attribute("name", String) })
+						HTTPExample("application/json", map[string]interface{}{"name": "thing"}, "Created", map[string]interface{}{"id": 1})
+						HTTPExample("application/xml", "<thing><name>thing</name></thing>", "Created", nil)
+						Response(Created)
+					})
+				})
+			})
+
+			It("sets the response and payload examples", func() {
+				op := swagger.Paths["/things"].(*genswagger.Path).Post
+				Ω(op.Responses["201"].Examples).Should(Equal(map[string]interface{}{
+					"application/json": map[string]interface{}{"id": 1},
+				}))
+				payload := op.Parameters[len(op.Parameters)-1]
+				Ω(payload.In).Should(Equal("body"))
+				Ω(payload.Extensions["x-examples"]).Should(Equal(map[string]interface{}{
+					"application/json": map[string]interface{}{"name": "thing"},
+					"application/xml":  "<thing><name>thing</name></thing>",
+				}))
+			})
+
+			It("overrides the action examples with the route examples", func() {
+				op := swagger.Paths["/items"].(*genswagger.Path).Post
+				Ω(op.Responses["201"].Examples).Should(Equal(map[string]interface{}{
+					"application/json": map[string]interface{}{"id": 2},
+				}))
+			})
+		})
+
+		Context("with deprecated actions", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					Action("legacy", func() {
+						Routing(GET("/legacy"))
+						Deprecated("2026-01-15", "2026-07-01")
+						Response(NoContent)
+					})
+					Action("current", func() {
+						Routing(GET("/current"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("marks the operations deprecated and documents the dates", func() {
+				op := swagger.Paths["/legacy"].(*genswagger.Path).Get
+				Ω(op.Deprecated).Should(BeTrue())
+				Ω(op.Extensions).Should(HaveKeyWithValue("x-deprecated-since", "2026-01-15"))
+				Ω(op.Extensions).Should(HaveKeyWithValue("x-sunset", "2026-07-01"))
+				Ω(swagger.Paths["/current"].(*genswagger.Path).Get.Deprecated).Should(BeFalse())
+			})
+		})
+
+		Context("with request and response examples of sensitive attributes", func() {
+			BeforeEach(func() {
+				session := MediaType("application/vnd.goa.test.session", func() {
+					Attributes(func() {
+						Attribute("login", String)
+						Attribute("token", String, func() { Sensitive() })
+					})
+					View("default", func() {
+						Attribute("login")
+						Attribute("token")
+					})
+				})
+				Resource("res", func() {
+					Action("login", func() {
+						Routing(POST("/sessions"))
+						Payload(func() {
+							Attribute("login", String)
+							Attribute("password", String, func() { Sensitive() })
+						})
+						HTTPExample("application/json", map[string]interface{}{"login": "foo", "password": "bar"},
+							"OK", map[string]interface{}{"login": "foo", "token": "baz"})
+						Response(OK, session)
+					})
+				})
+			})
+
+			It("masks the sensitive values", func() {
+				op := swagger.Paths["/sessions"].(*genswagger.Path).Post
+				Ω(op.Responses["200"].Examples).Should(Equal(map[string]interface{}{
+					"application/json": map[string]interface{}{"login": "foo", "token": "********"},
+				}))
+				payload := op.Parameters[len(op.Parameters)-1]
+				Ω(payload.Extensions["x-examples"]).Should(Equal(map[string]interface{}{
+					"application/json": map[string]interface{}{"login": "foo", "password": "********"},
+				}))
+			})
+		})
+
+		Context("with swagger tags", func() {
+			BeforeEach(func() {
+				Resource("res", func() {
+					SwaggerTag("cellar", func() {
+						Description("Cellar operations")
+						Docs(func() {
+							Description("Cellar guide")
+							URL("http://example.com/cellar")
+						})
+					})
+					Action("act", func() {
+						SwaggerTag("admin")
+						Routing(PUT("/"))
+						Response(NoContent)
+					})
+				})
+			})
+
+			It("lists the described tags in the swagger object", func() {
+				Ω(swagger.Tags).Should(HaveLen(2))
+				Ω(swagger.Tags[1]).Should(Equal(&genswagger.Tag{
+					Name:         "cellar",
+					Description:  "Cellar operations",
+					ExternalDocs: &genswagger.ExternalDocs{URL: "http://example.com/cellar", Description: "Cellar guide"},
+				}))
+			})
+
+			It("sets the action tags", func() {
+				p := swagger.Paths["/"].(*genswagger.Path)
+				Ω(p.Put.Tags).Should(Equal([]string{"cellar", "admin"}))
+			})
+		})
+	})
+})
+
+var _ = Describe("Validate", func() {
+	var swagger *genswagger.Swagger
+	var validateErr error
+	var title string
+
+	BeforeEach(func() {
+		dslengine.Reset()
+		genschema.Definitions = make(map[string]*genschema.JSONSchema)
+		title = "test"
+		API("test", func() {
+			Title(title)
+		})
+	})
+
+	JustBeforeEach(func() {
+		err := dslengine.Run()
+		Ω(err).ShouldNot(HaveOccurred())
+		if swagger == nil {
+			swagger, err = genswagger.New(Design)
+			Ω(err).ShouldNot(HaveOccurred())
+		}
+		validateErr = swagger.Validate(Design)
+	})
+
+	AfterEach(func() {
+		swagger = nil
+	})
+
+	Context("with a valid design", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/:id"))
+					Params(func() {
+						Param("id", Integer)
+					})
+					Response(OK)
+				})
+			})
+		})
+
+		It("does not return an error", func() {
+			Ω(validateErr).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with a design without title", func() {
+		BeforeEach(func() {
+			title = ""
+		})
+
+		It("defaults the title to the API name", func() {
+			Ω(validateErr).ShouldNot(HaveOccurred())
+			Ω(swagger.Info.Title).Should(Equal("test"))
+		})
+	})
+
+	Context("with an action without responses", func() {
+		BeforeEach(func() {
+			Resource("res", func() {
+				Action("act", func() {
+					Routing(GET("/"))
+				})
+			})
+		})
+
+		It("reports the action", func() {
+			Ω(validateErr).Should(HaveOccurred())
+			Ω(validateErr.Error()).Should(Equal(`resource "res" action "act": paths./.get.responses: operation must define at least one response, use Response in the action design`))
+		})
+	})
+
+	Context("with invalid parameters", func() {
+		BeforeEach(func() {
+			swagger = &genswagger.Swagger{
+				Info: &genswagger.Info{Title: "test"},
+				Paths: map[string]interface{}{
+					"/{id}": &genswagger.Path{
+						Get: &genswagger.Operation{
+							Parameters: []*genswagger.Parameter{
+								{Name: "id", In: "path", Type: "string"},
+								{Name: "tags", In: "header", Type: "array", Items: &genswagger.Items{Type: "string"}, CollectionFormat: "multi"},
+							},
+							Responses: map[string]*genswagger.Response{"200": {Description: "OK"}},
+						},
+					},
+				},
+			}
+		})
+
+		It("reports each violation", func() {
+			Ω(validateErr).Should(HaveOccurred())
+			errs, ok := validateErr.(genswagger.ValidationErrors)
+			Ω(ok).Should(BeTrue())
+			Ω(errs).Should(HaveLen(2))
+			Ω(errs[0].Path).Should(Equal("paths./{id}.get.parameters[0].required"))
+			Ω(errs[1].Error()).Should(Equal(`paths./{id}.get.parameters[1].collectionFormat: header parameter "tags" cannot use the multi collection format`))
+		})
+	})
+
+	Context("with invalid security requirements", func() {
+		BeforeEach(func() {
+			swagger = &genswagger.Swagger{
+				Info:                &genswagger.Info{Title: "test"},
+				SecurityDefinitions: map[string]*genswagger.SecurityDefinition{"key": {Type: "apiKey", Name: "k", In: "query"}},
+				Security:            []map[string][]string{{"key": {"read"}}},
+				Paths: map[string]interface{}{
+					"/": &genswagger.Path{
+						Get: &genswagger.Operation{
+							Responses: map[string]*genswagger.Response{"200": {Description: "OK"}},
+							Security:  []map[string][]string{{"oauth": {}}},
+						},
+					},
+				},
+			}
+		})
+
+		It("reports each violation", func() {
+			Ω(validateErr).Should(HaveOccurred())
+			errs, ok := validateErr.(genswagger.ValidationErrors)
+			Ω(ok).Should(BeTrue())
+			Ω(errs).Should(HaveLen(2))
+			Ω(errs[0].Error()).Should(Equal(`API "test": security[0]: security scheme "key" of type apiKey cannot list scopes`))
+			Ω(errs[1].Error()).Should(Equal(`paths./.get.security[0]: security scheme "oauth" is not defined`))
+		})
 	})
 })
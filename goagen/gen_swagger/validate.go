@@ -0,0 +1,324 @@
+package genswagger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/dslengine"
+)
+
+type (
+	// ValidationError describes a value of a generated spec that does not comply with the
+	// Swagger 2.0 specification.
+	ValidationError struct {
+		// Path is the location of the offending value in the spec, e.g.
+		// "paths./bottles.get.responses".
+		Path string
+		// Definition is the design definition that produced the value, nil if unknown.
+		Definition dslengine.Definition
+		// Message describes the violation.
+		Message string
+	}
+
+	// ValidationErrors lists the violations found in a spec.
+	ValidationErrors []*ValidationError
+)
+
+var (
+	// statusRegex matches the keys of the responses object.
+	statusRegex = regexp.MustCompile(`^([0-9]{3}|default)$`)
+
+	// templateRegex matches the parameters of a path template.
+	templateRegex = regexp.MustCompile(`{([^}]+)}`)
+
+	// paramLocations lists the valid values of the parameter "in" field.
+	paramLocations = []string{"query", "header", "path", "formData", "body"}
+
+	// paramTypes lists the valid types of the parameters not located in the body.
+	paramTypes = []string{"string", "number", "integer", "boolean", "array", "file"}
+
+	// collectionFormats lists the valid parameter collection formats.
+	collectionFormats = []string{"csv", "ssv", "tsv", "pipes", "multi"}
+)
+
+// Error returns the location of the error in the design and in the spec followed by the message.
+func (e *ValidationError) Error() string {
+	if e.Definition != nil {
+		return fmt.Sprintf("%s: %s: %s", e.Definition.Context(), e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Error returns one error per line.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Validate checks the spec generated from the given API against the rules of the Swagger 2.0
+// specification that a design may violate, e.g. operations without responses or responses without
+// descriptions. It returns ValidationErrors that refer back to the design definitions producing
+// the invalid values or nil if the spec is valid.
+func (s *Swagger) Validate(api *design.APIDefinition) error {
+	v := &validator{s: s, api: api}
+	if s.Info == nil || s.Info.Title == "" {
+		v.add("info.title", api, "title is required, set it with Title in the API design")
+	}
+	if s.BasePath != "" && !strings.HasPrefix(s.BasePath, "/") {
+		v.add("basePath", api, "base path %q must start with /", s.BasePath)
+	}
+	for i, req := range s.Security {
+		v.requirement(fmt.Sprintf("security[%d]", i), req, api)
+	}
+	for _, name := range sortedStrings(s.Parameters) {
+		v.parameter("parameters."+name, s.Parameters[name], api)
+	}
+	operationIDs := make(map[string]string)
+	for _, key := range sortedStrings(s.Paths) {
+		p, ok := s.Paths[key].(*Path)
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(key, "/") {
+			v.add("paths."+key, nil, "path must start with /")
+		}
+		for _, verb := range []string{"get", "put", "post", "delete", "options", "head", "patch"} {
+			op := p.operation(verb)
+			if op == nil {
+				continue
+			}
+			loc := fmt.Sprintf("paths.%s.%s", key, verb)
+			if op.OperationID != "" {
+				if other, ok := operationIDs[op.OperationID]; ok {
+					v.add(loc+".operationId", v.definition(op), "operation ID %q is already used by %s", op.OperationID, other)
+				}
+				operationIDs[op.OperationID] = loc
+			}
+			v.operation(loc, key, op)
+		}
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// validator accumulates the errors found while validating a spec.
+type validator struct {
+	s    *Swagger
+	api  *design.APIDefinition
+	errs ValidationErrors
+}
+
+// add records an error caused by the given design definition.
+func (v *validator) add(path string, def dslengine.Definition, format string, args ...interface{}) {
+	if d, ok := def.(*design.APIDefinition); ok && d == nil {
+		def = nil
+	}
+	v.errs = append(v.errs, &ValidationError{Path: path, Definition: def, Message: fmt.Sprintf(format, args...)})
+}
+
+// definition returns the action or file server that produced the given operation using the
+// operation ID built by New, nil if not found.
+func (v *validator) definition(op *Operation) dslengine.Definition {
+	if v.api == nil {
+		return nil
+	}
+	elems := strings.SplitN(op.OperationID, "#", 3)
+	if len(elems) < 2 {
+		return nil
+	}
+	res := v.api.Resources[elems[0]]
+	if res == nil {
+		return nil
+	}
+	if a := res.Actions[elems[1]]; a != nil {
+		return a
+	}
+	for _, fs := range res.FileServers {
+		if fs.RequestPath == elems[1] {
+			return fs
+		}
+	}
+	return nil
+}
+
+func (v *validator) operation(loc, key string, op *Operation) {
+	var (
+		seen   = make(map[string]bool)
+		inPath = make(map[string]bool)
+		body   bool
+		form   bool
+		def    = v.definition(op)
+	)
+	for i, p := range op.Parameters {
+		ploc := fmt.Sprintf("%s.parameters[%d]", loc, i)
+		v.parameter(ploc, p, def)
+		id := p.In + " " + p.Name
+		if seen[id] {
+			v.add(ploc, def, "%s parameter %q is defined more than once", p.In, p.Name)
+		}
+		seen[id] = true
+		switch p.In {
+		case "body":
+			if body {
+				v.add(ploc, def, "operation has more than one body parameter")
+			}
+			body = true
+		case "formData":
+			form = true
+		case "path":
+			inPath[p.Name] = true
+			if !strings.Contains(key, "{"+p.Name+"}") {
+				v.add(ploc, def, "path parameter %q does not appear in path %s", p.Name, key)
+			}
+		}
+	}
+	if body && form {
+		v.add(loc+".parameters", def, "operation cannot have both body and form parameters, use either a payload or a multipart form")
+	}
+	for _, m := range templateRegex.FindAllStringSubmatch(key, -1) {
+		if !inPath[m[1]] {
+			v.add(loc+".parameters", def, "path %s has no parameter %q, define it with Param", key, m[1])
+		}
+	}
+	if len(op.Responses) == 0 {
+		v.add(loc+".responses", def, "operation must define at least one response, use Response in the action design")
+	}
+	for _, status := range sortedStrings(op.Responses) {
+		rloc := fmt.Sprintf("%s.responses.%s", loc, status)
+		r := op.Responses[status]
+		if !statusRegex.MatchString(status) {
+			v.add(rloc, def, "response key must be a 3 digit HTTP status code or default")
+		}
+		if r != nil && r.Ref == "" && r.Description == "" {
+			owner := def
+			if a, ok := def.(*design.ActionDefinition); ok {
+				for _, resp := range a.Responses {
+					if strconv.Itoa(resp.Status) == status {
+						owner = resp
+						break
+					}
+				}
+			}
+			v.add(rloc+".description", owner, "response description is required, set it with Description in the response design")
+		}
+	}
+	for i, req := range op.Security {
+		v.requirement(fmt.Sprintf("%s.security[%d]", loc, i), req, def)
+	}
+}
+
+// requirement checks that the schemes of the security requirement are defined and that only
+// OAuth2 schemes list scopes.
+func (v *validator) requirement(loc string, req map[string][]string, def dslengine.Definition) {
+	for _, name := range sortedStrings(req) {
+		scheme, ok := v.s.SecurityDefinitions[name]
+		if !ok {
+			v.add(loc, def, "security scheme %q is not defined", name)
+			continue
+		}
+		if scheme.Type != "oauth2" && len(req[name]) > 0 {
+			v.add(loc, def, "security scheme %q of type %s cannot list scopes", name, scheme.Type)
+		}
+	}
+}
+
+func (v *validator) parameter(loc string, p *Parameter, owner dslengine.Definition) {
+	if p.Name == "" {
+		v.add(loc+".name", owner, "parameter name is required")
+	}
+	if !containsString(paramLocations, p.In) {
+		v.add(loc+".in", owner, "parameter %q has invalid location %q", p.Name, p.In)
+		return
+	}
+	if p.In == "body" {
+		if p.Schema == nil {
+			v.add(loc+".schema", owner, "body parameter %q must have a schema", p.Name)
+		}
+		return
+	}
+	if p.In == "path" && !p.Required {
+		v.add(loc+".required", owner, "path parameter %q must be required", p.Name)
+	}
+	if !containsString(paramTypes, p.Type) {
+		v.add(loc+".type", owner, "%s parameter %q has invalid type %q, only primitives and arrays are allowed", p.In, p.Name, p.Type)
+	}
+	if p.Type == "file" && p.In != "formData" {
+		v.add(loc+".type", owner, "file parameter %q must be a form parameter", p.Name)
+	}
+	if p.Type == "array" && p.Items == nil {
+		v.add(loc+".items", owner, "array parameter %q must define its items", p.Name)
+	}
+	if p.CollectionFormat != "" {
+		if !containsString(collectionFormats, p.CollectionFormat) {
+			v.add(loc+".collectionFormat", owner, "parameter %q has invalid collection format %q", p.Name, p.CollectionFormat)
+		}
+		if p.CollectionFormat == "multi" && p.In != "query" && p.In != "formData" {
+			v.add(loc+".collectionFormat", owner, "%s parameter %q cannot use the multi collection format", p.In, p.Name)
+		}
+	}
+}
+
+// operation returns the operation of the path for the given lower case verb.
+func (p *Path) operation(verb string) *Operation {
+	switch verb {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	}
+	return nil
+}
+
+// containsString returns true if vals contains val.
+func containsString(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedStrings returns the sorted keys of the given map indexed by strings.
+func sortedStrings(m interface{}) []string {
+	var keys []string
+	switch actual := m.(type) {
+	case map[string]*Parameter:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]interface{}:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string]*Response:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range actual {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/goadesign/goa/goagen/importer"
+	"github.com/spf13/cobra"
+)
+
+// importDesign converts the OpenAPI document given as argument into a design file written in the
+// output directory. It returns the path to the design file.
+func importDesign(c *cobra.Command, args []string, pkg string, force bool) ([]string, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("usage: goagen import DOCUMENT")
+	}
+	spec, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+	src, err := importer.Import(spec, pkg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", args[0], err)
+	}
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(out, "design.go")
+	if _, err := os.Stat(path); err == nil && !force {
+		return nil, fmt.Errorf("%s already exists, use --force to overwrite it", path)
+	}
+	if err := ioutil.WriteFile(path, src, 0644); err != nil {
+		return nil, err
+	}
+	return []string{path}, nil
+}
@@ -0,0 +1,987 @@
+/*
+Package importer converts OpenAPI documents into goa design packages.
+
+Import accepts OpenAPI 2 (Swagger) and OpenAPI 3 documents written in JSON or YAML and produces
+the source of a design file: the API definition built from the document info and servers, one
+type per object schema, and one resource per operation tag grouping the actions that correspond to
+the operations. The operations with no tag are grouped by the first segment of their path.
+
+The conversion covers the parameters, payloads and responses of the operations along with the
+validations of their schemas. Security schemes, callbacks and links are not converted. Schemas
+combined with oneOf or anyOf become Any attributes.
+*/
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goadesign/goa/design"
+	"github.com/goadesign/goa/design/apidsl"
+	"github.com/goadesign/goa/goagen/codegen"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// converter holds the state of the conversion of an OpenAPI document.
+	converter struct {
+		// doc is the OpenAPI document.
+		doc map[string]interface{}
+		// v3 is true if doc is an OpenAPI 3 document.
+		v3 bool
+		// types lists the object types in declaration order.
+		types []*typeDef
+		// refs indexes the object types by schema reference.
+		refs map[string]*typeDef
+		// current is the type being written, nil when writing the API or the resources.
+		current *typeDef
+		// names records the names of the variables already declared.
+		names map[string]bool
+		// inlining records the references of the non object schemas being inlined.
+		inlining map[string]bool
+		// multipart is true while converting the attributes of a multipart payload.
+		multipart bool
+		// usesDesign is true if the generated code references the design package.
+		usesDesign bool
+	}
+
+	// typeDef is an object type of the generated design.
+	typeDef struct {
+		name, varName string
+		schema        map[string]interface{}
+		multipart     bool
+		// body is the definition of the type where the references to other types are
+		// placeholders replaced once all the dependencies are known.
+		body []byte
+		// deps lists the types referred to by the type.
+		deps map[*typeDef]bool
+	}
+
+	// operation is an OpenAPI operation converted into an action.
+	operation struct {
+		method, path string
+		op           map[string]interface{}
+		params       []map[string]interface{}
+	}
+)
+
+// methods lists the HTTP methods of the OpenAPI path items in the order the actions are
+// generated.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// statusNames indexes the names of the goa standard responses by status code.
+var statusNames = map[int]string{
+	100: design.Continue, 101: design.SwitchingProtocols,
+	200: design.OK, 201: design.Created, 202: design.Accepted, 203: design.NonAuthoritativeInfo,
+	204: design.NoContent, 205: design.ResetContent, 206: design.PartialContent,
+	300: design.MultipleChoices, 301: design.MovedPermanently, 302: design.Found,
+	303: design.SeeOther, 304: design.NotModified, 305: design.UseProxy,
+	307: design.TemporaryRedirect,
+	400: design.BadRequest, 401: design.Unauthorized, 402: design.PaymentRequired,
+	403: design.Forbidden, 404: design.NotFound, 405: design.MethodNotAllowed,
+	406: design.NotAcceptable, 407: design.ProxyAuthRequired, 408: design.RequestTimeout,
+	409: design.Conflict, 410: design.Gone, 411: design.LengthRequired,
+	412: design.PreconditionFailed, 413: design.RequestEntityTooLarge,
+	414: design.RequestURITooLong, 415: design.UnsupportedMediaType,
+	416: design.RequestedRangeNotSatisfiable, 417: design.ExpectationFailed,
+	418: design.Teapot, 422: design.UnprocessableEntity,
+	500: design.InternalServerError, 501: design.NotImplemented, 502: design.BadGateway,
+	503: design.ServiceUnavailable, 504: design.GatewayTimeout,
+	505: design.HTTPVersionNotSupported,
+}
+
+// Import converts the given OpenAPI 2 or 3 document, in JSON or YAML, into the source of a goa
+// design file of the package with the given name.
+func Import(spec []byte, pkg string) ([]byte, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %s", err)
+	}
+	doc, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: not an object")
+	}
+	c := &converter{
+		doc:      doc,
+		refs:     make(map[string]*typeDef),
+		names:    make(map[string]bool),
+		inlining: make(map[string]bool),
+	}
+	switch {
+	case fmt.Sprint(doc["swagger"]) == "2.0":
+	case strings.HasPrefix(str(doc, "openapi"), "3."):
+		c.v3 = true
+	default:
+		return nil, fmt.Errorf("unsupported document, must be an OpenAPI 2 or 3 document")
+	}
+	src, err := format.Source(c.convert(pkg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the design: %s", err)
+	}
+	return src, nil
+}
+
+// convert returns the unformatted source of the design.
+func (c *converter) convert(pkg string) []byte {
+	c.declareSchemas()
+	var api, resources, types bytes.Buffer
+	c.writeAPI(&api)
+	c.writeResources(&resources)
+	// Writing types may declare new types for the inline object schemas they contain.
+	for i := 0; i < len(c.types); i++ {
+		c.writeType(c.types[i])
+	}
+	for _, td := range c.types {
+		types.Write(c.resolveRefs(td))
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	if c.usesDesign {
+		b.WriteString("\t. \"github.com/goadesign/goa/design\"\n")
+	}
+	b.WriteString("\t. \"github.com/goadesign/goa/design/apidsl\"\n)\n\n")
+	b.Write(api.Bytes())
+	b.Write(types.Bytes())
+	b.Write(resources.Bytes())
+	return b.Bytes()
+}
+
+// declareSchemas declares the types of the object schemas of the document definitions.
+func (c *converter) declareSchemas() {
+	prefix, schemas := "#/definitions/", obj(c.doc, "definitions")
+	if c.v3 {
+		prefix, schemas = "#/components/schemas/", obj(obj(c.doc, "components"), "schemas")
+	}
+	for _, name := range keys(schemas) {
+		schema, ok := schemas[name].(map[string]interface{})
+		if !ok || !isObject(c.merge(schema)) {
+			continue
+		}
+		c.refs[prefix+escapePointer(name)] = c.declare(name, schema)
+	}
+}
+
+// declare records a new object type.
+func (c *converter) declare(name string, schema map[string]interface{}) *typeDef {
+	base := codegen.Goify(name, true)
+	if base == "" {
+		base = "Object"
+	}
+	varName := base + "Type"
+	for i := 2; c.names[varName]; i++ {
+		varName = base + strconv.Itoa(i) + "Type"
+	}
+	c.names[varName] = true
+	typeName := strings.TrimSuffix(varName, "Type")
+	td := &typeDef{name: typeName, varName: varName, schema: schema, multipart: c.multipart}
+	c.types = append(c.types, td)
+	return td
+}
+
+// typeRef returns the expression referring to the given type. References made from type
+// definitions are placeholders resolved by resolveRefs.
+func (c *converter) typeRef(td *typeDef) string {
+	if c.current == nil {
+		return td.varName
+	}
+	c.current.deps[td] = true
+	return placeholder(td)
+}
+
+// resolveRefs returns the definition of the given type where the references to the other types
+// use the variables of the types except for the types that depend on the given type which are
+// referred to by name to avoid initialization cycles.
+func (c *converter) resolveRefs(td *typeDef) []byte {
+	body := td.body
+	for dep := range td.deps {
+		ref := dep.varName
+		if dependsOn(dep, td, make(map[*typeDef]bool)) {
+			ref = strconv.Quote(dep.name)
+		}
+		body = bytes.Replace(body, []byte(placeholder(dep)), []byte(ref), -1)
+	}
+	return body
+}
+
+// dependsOn returns true if the type from refers to the type to directly or indirectly.
+func dependsOn(from, to *typeDef, seen map[*typeDef]bool) bool {
+	if from == to {
+		return true
+	}
+	seen[from] = true
+	for dep := range from.deps {
+		if !seen[dep] && dependsOn(dep, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeholder returns the placeholder of the references to the given type.
+func placeholder(td *typeDef) string {
+	return "\x00" + td.varName + "\x00"
+}
+
+// writeAPI writes the API definition.
+func (c *converter) writeAPI(w *bytes.Buffer) {
+	info := obj(c.doc, "info")
+	name := str(info, "title")
+	if name == "" {
+		name = "api"
+	}
+	fmt.Fprintf(w, "var _ = API(%q, func() {\n", name)
+	fmt.Fprintf(w, "Title(%q)\n", name)
+	if d := str(info, "description"); d != "" {
+		fmt.Fprintf(w, "Description(%q)\n", d)
+	}
+	if v := str(info, "version"); v != "" {
+		fmt.Fprintf(w, "Version(%q)\n", v)
+	}
+	if c.v3 {
+		servers := list(c.doc, "servers")
+		for i, s := range servers {
+			server, _ := s.(map[string]interface{})
+			scheme, host, path := splitURL(str(server, "url"))
+			if i == 0 {
+				c.writeHost(w, host, scheme)
+				if p := strings.TrimSuffix(path, "/"); p != "" {
+					fmt.Fprintf(w, "BasePath(%q)\n", p)
+				}
+			}
+			if len(servers) > 1 && host != "" {
+				c.writeServer(w, i, server, scheme, host)
+			}
+		}
+	} else {
+		var schemes []string
+		for _, s := range list(c.doc, "schemes") {
+			schemes = append(schemes, fmt.Sprint(s))
+		}
+		if host := str(c.doc, "host"); host != "" {
+			fmt.Fprintf(w, "Host(%q)\n", host)
+		}
+		if len(schemes) > 0 {
+			fmt.Fprintf(w, "Scheme(%s)\n", quoteAll(schemes))
+		}
+		if p := strings.TrimSuffix(str(c.doc, "basePath"), "/"); p != "" {
+			fmt.Fprintf(w, "BasePath(%q)\n", p)
+		}
+	}
+	w.WriteString("})\n\n")
+}
+
+// writeHost writes the API host and scheme.
+func (c *converter) writeHost(w *bytes.Buffer, host, scheme string) {
+	if host != "" && !strings.Contains(host, "{") {
+		fmt.Fprintf(w, "Host(%q)\n", host)
+	}
+	if scheme == "http" || scheme == "https" {
+		fmt.Fprintf(w, "Scheme(%q)\n", scheme)
+	}
+}
+
+// writeServer writes the server definition of the i-th OpenAPI 3 server.
+func (c *converter) writeServer(w *bytes.Buffer, i int, server map[string]interface{}, scheme, host string) {
+	fmt.Fprintf(w, "Server(%q, func() {\n", fmt.Sprintf("server%d", i+1))
+	if d := str(server, "description"); d != "" {
+		fmt.Fprintf(w, "Description(%q)\n", d)
+	}
+	fmt.Fprintf(w, "Host(%q)\n", host)
+	if scheme == "http" || scheme == "https" {
+		fmt.Fprintf(w, "Scheme(%q)\n", scheme)
+	}
+	vars := obj(server, "variables")
+	for _, name := range keys(vars) {
+		if !strings.Contains(host, "{"+name+"}") {
+			continue
+		}
+		v, _ := vars[name].(map[string]interface{})
+		args := []string{strconv.Quote(name), strconv.Quote(fmt.Sprint(v["default"]))}
+		for _, e := range list(v, "enum") {
+			args = append(args, strconv.Quote(fmt.Sprint(e)))
+		}
+		fmt.Fprintf(w, "Variable(%s)\n", strings.Join(args, ", "))
+	}
+	w.WriteString("})\n")
+}
+
+// writeResources writes the resources grouping the document operations.
+func (c *converter) writeResources(w *bytes.Buffer) {
+	groups := make(map[string][]*operation)
+	paths := obj(c.doc, "paths")
+	for _, path := range keys(paths) {
+		item := c.deref(obj(paths, path))
+		for _, method := range methods {
+			op := obj(item, method)
+			if op == nil {
+				continue
+			}
+			o := &operation{method: method, path: path, op: op, params: c.parameters(item, op)}
+			res := resourceName(path, op)
+			groups[res] = append(groups[res], o)
+		}
+	}
+	tags := make(map[string]string)
+	for _, t := range list(c.doc, "tags") {
+		if tag, ok := t.(map[string]interface{}); ok {
+			tags[str(tag, "name")] = str(tag, "description")
+		}
+	}
+	for _, res := range sortedGroups(groups) {
+		fmt.Fprintf(w, "var _ = Resource(%q, func() {\n", res)
+		if d := tags[res]; d != "" {
+			fmt.Fprintf(w, "Description(%q)\n", d)
+		}
+		names := make(map[string]bool)
+		for _, o := range groups[res] {
+			c.writeAction(w, o, actionName(o, names))
+		}
+		w.WriteString("})\n\n")
+	}
+}
+
+// writeAction writes the action corresponding to the given operation.
+func (c *converter) writeAction(w *bytes.Buffer, o *operation, name string) {
+	fmt.Fprintf(w, "Action(%q, func() {\n", name)
+	desc := str(o.op, "description")
+	if desc == "" {
+		desc = str(o.op, "summary")
+	}
+	if desc != "" {
+		fmt.Fprintf(w, "Description(%q)\n", desc)
+	}
+	fmt.Fprintf(w, "Routing(%s(%q))\n", strings.ToUpper(o.method), routePath(o.path))
+	var params, headers, form []map[string]interface{}
+	var body map[string]interface{}
+	for _, p := range o.params {
+		switch str(p, "in") {
+		case "path", "query":
+			params = append(params, p)
+		case "header":
+			headers = append(headers, p)
+		case "body":
+			body = p
+		case "formData":
+			form = append(form, p)
+		}
+	}
+	c.writeParams(w, "Params", "Param", params)
+	c.writeParams(w, "Headers", "Header", headers)
+	hint := codegen.Goify(name, true)
+	switch {
+	case body != nil:
+		c.writePayload(w, obj(body, "schema"), boolean(body, "required"), false, hint)
+	case len(form) > 0:
+		schema := map[string]interface{}{"type": "object"}
+		props := make(map[string]interface{})
+		var required []interface{}
+		for _, p := range form {
+			props[str(p, "name")] = p
+			if boolean(p, "required") {
+				required = append(required, str(p, "name"))
+			}
+		}
+		schema["properties"] = props
+		schema["required"] = required
+		c.writePayload(w, schema, true, true, hint)
+	case c.v3 && o.op["requestBody"] != nil:
+		rb := c.deref(obj(o.op, "requestBody"))
+		mt, content := pickContent(obj(rb, "content"))
+		c.writePayload(w, obj(content, "schema"), boolean(rb, "required"), mt == "multipart/form-data", hint)
+	}
+	c.writeResponses(w, obj(o.op, "responses"), hint)
+	w.WriteString("})\n")
+}
+
+// writeParams writes the path, query string or header parameters of an action.
+func (c *converter) writeParams(w *bytes.Buffer, fn, attFn string, params []map[string]interface{}) {
+	if len(params) == 0 {
+		return
+	}
+	sort.Slice(params, func(i, j int) bool { return str(params[i], "name") < str(params[j], "name") })
+	fmt.Fprintf(w, "%s(func() {\n", fn)
+	var required []string
+	for _, p := range params {
+		schema := p
+		if c.v3 {
+			schema = obj(p, "schema")
+		}
+		schema = c.inline(schema)
+		if isObject(schema) {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		desc := str(p, "description")
+		if desc == "" {
+			desc = str(schema, "description")
+		}
+		c.writeAttribute(w, attFn, str(p, "name"), schema, desc, str(p, "name"))
+		if boolean(p, "required") || str(p, "in") == "path" {
+			required = append(required, str(p, "name"))
+		}
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(w, "Required(%s)\n", quoteAll(required))
+	}
+	w.WriteString("})\n")
+}
+
+// writePayload writes the payload of an action.
+func (c *converter) writePayload(w *bytes.Buffer, schema map[string]interface{}, required, multipart bool, hint string) {
+	if schema == nil {
+		return
+	}
+	c.multipart = multipart
+	expr := c.typeExpr(schema, hint+"Payload")
+	c.multipart = false
+	fn := "Payload"
+	if !required {
+		fn = "OptionalPayload"
+	}
+	fmt.Fprintf(w, "%s(%s)\n", fn, expr)
+	if multipart {
+		w.WriteString("MultipartForm()\n")
+	}
+}
+
+// writeResponses writes the responses of an action.
+func (c *converter) writeResponses(w *bytes.Buffer, responses map[string]interface{}, hint string) {
+	codes := make([]int, 0, len(responses))
+	for k := range responses {
+		if code, err := strconv.Atoi(k); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		resp := c.deref(obj(responses, strconv.Itoa(code)))
+		schema := obj(resp, "schema")
+		if c.v3 {
+			_, content := pickContent(obj(resp, "content"))
+			schema = obj(content, "schema")
+		}
+		name, ok := statusNames[code]
+		args := []string{strconv.Quote(fmt.Sprintf("Status%d", code))}
+		if ok {
+			c.usesDesign = true
+			args = []string{name}
+		}
+		if schema != nil && code != 204 && code != 304 {
+			args = append(args, c.typeExpr(schema, hint+codegen.Goify(name, true)+"Response"))
+		}
+		if !ok {
+			args = append(args, fmt.Sprintf("func() { Status(%d) }", code))
+		}
+		fmt.Fprintf(w, "Response(%s)\n", strings.Join(args, ", "))
+	}
+}
+
+// writeType writes the definition of the given object type into its body.
+func (c *converter) writeType(td *typeDef) {
+	schema := c.merge(td.schema)
+	c.multipart = td.multipart
+	c.current = td
+	td.deps = make(map[*typeDef]bool)
+	var b bytes.Buffer
+	w := &b
+	defer func() {
+		c.multipart = false
+		c.current = nil
+		td.body = b.Bytes()
+	}()
+	fmt.Fprintf(w, "var %s = Type(%q, func() {\n", td.varName, td.name)
+	if d := str(schema, "description"); d != "" {
+		fmt.Fprintf(w, "Description(%q)\n", d)
+	}
+	props := obj(schema, "properties")
+	for _, name := range keys(props) {
+		prop, _ := props[name].(map[string]interface{})
+		c.writeAttribute(w, "Attribute", name, prop, str(prop, "description"), td.name+codegen.Goify(name, true))
+	}
+	var required []string
+	for _, r := range list(schema, "required") {
+		if _, ok := props[fmt.Sprint(r)]; ok {
+			required = append(required, fmt.Sprint(r))
+		}
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(w, "Required(%s)\n", quoteAll(required))
+	}
+	w.WriteString("})\n\n")
+}
+
+// writeAttribute writes the definition of an attribute, parameter or header with the given
+// schema. hint is the name given to the type of the attribute if it is an inline object.
+func (c *converter) writeAttribute(w *bytes.Buffer, fn, name string, schema map[string]interface{}, desc, hint string) {
+	args := []string{strconv.Quote(name), c.typeExpr(schema, hint)}
+	if desc != "" {
+		args = append(args, strconv.Quote(desc))
+	}
+	if v := c.validations(c.inline(schema)); v != "" {
+		args = append(args, "func() {\n"+v+"}")
+	}
+	fmt.Fprintf(w, "%s(%s)\n", fn, strings.Join(args, ", "))
+}
+
+// typeExpr returns the DSL expression of the data type described by the given schema. The inline
+// object schemas are declared as types named after hint.
+func (c *converter) typeExpr(schema map[string]interface{}, hint string) string {
+	if ref := str(schema, "$ref"); ref != "" {
+		if td, ok := c.refs[ref]; ok {
+			return c.typeRef(td)
+		}
+		target := c.resolve(ref)
+		if target == nil || c.inlining[ref] {
+			return c.primitive("Any")
+		}
+		c.inlining[ref] = true
+		defer delete(c.inlining, ref)
+		return c.typeExpr(target, hint)
+	}
+	schema = c.merge(schema)
+	switch schemaType(schema) {
+	case "string":
+		switch str(schema, "format") {
+		case "date-time":
+			return c.primitive("DateTime")
+		case "uuid":
+			return c.primitive("UUID")
+		case "binary":
+			if c.multipart {
+				return c.primitive("File")
+			}
+		}
+		return c.primitive("String")
+	case "file":
+		return c.primitive("File")
+	case "integer":
+		return c.primitive("Integer")
+	case "number":
+		return c.primitive("Number")
+	case "boolean":
+		return c.primitive("Boolean")
+	case "array":
+		items := obj(schema, "items")
+		if items == nil {
+			return "ArrayOf(" + c.primitive("Any") + ")"
+		}
+		expr := c.typeExpr(items, hint+"Item")
+		if v := c.validations(c.inline(items)); v != "" {
+			return "ArrayOf(" + expr + ", func() {\n" + v + "})"
+		}
+		return "ArrayOf(" + expr + ")"
+	case "object":
+		if len(obj(schema, "properties")) > 0 {
+			return c.typeRef(c.declare(hint, schema))
+		}
+		if ap := obj(schema, "additionalProperties"); ap != nil {
+			return "HashOf(" + c.primitive("String") + ", " + c.typeExpr(ap, hint+"Value") + ")"
+		}
+	}
+	return c.primitive("Any")
+}
+
+// primitive returns the name of the given design primitive type and records that the design
+// package is used.
+func (c *converter) primitive(name string) string {
+	c.usesDesign = true
+	return name
+}
+
+// validations returns the DSL of the validations of the given schema.
+func (c *converter) validations(schema map[string]interface{}) string {
+	var b bytes.Buffer
+	if enum := list(schema, "enum"); len(enum) > 0 {
+		var vals []string
+		for _, e := range enum {
+			if lit, ok := literal(e); ok {
+				vals = append(vals, lit)
+			}
+		}
+		if len(vals) > 0 {
+			fmt.Fprintf(&b, "Enum(%s)\n", strings.Join(vals, ", "))
+		}
+	}
+	if lit, ok := literal(schema["default"]); ok && schemaType(schema) != "array" && schemaType(schema) != "object" {
+		fmt.Fprintf(&b, "Default(%s)\n", lit)
+	}
+	if f := str(schema, "format"); f != "date-time" && isSupportedFormat(f) {
+		fmt.Fprintf(&b, "Format(%q)\n", f)
+	}
+	if p := str(schema, "pattern"); p != "" {
+		fmt.Fprintf(&b, "Pattern(%q)\n", p)
+	}
+	for _, bound := range []struct{ key, exclusive, fn, exclusiveFn string }{
+		{"minimum", "exclusiveMinimum", "Minimum", "ExclusiveMinimum"},
+		{"maximum", "exclusiveMaximum", "Maximum", "ExclusiveMaximum"},
+	} {
+		if lit, ok := number(schema[bound.key]); ok {
+			fn := bound.fn
+			if boolean(schema, bound.exclusive) {
+				fn = bound.exclusiveFn
+			}
+			fmt.Fprintf(&b, "%s(%s)\n", fn, lit)
+		} else if lit, ok := number(schema[bound.exclusive]); ok {
+			fmt.Fprintf(&b, "%s(%s)\n", bound.exclusiveFn, lit)
+		}
+	}
+	if lit, ok := number(schema["multipleOf"]); ok {
+		fmt.Fprintf(&b, "MultipleOf(%s)\n", lit)
+	}
+	for _, length := range []struct{ key, fn string }{
+		{"minLength", "MinLength"}, {"maxLength", "MaxLength"},
+		{"minItems", "MinLength"}, {"maxItems", "MaxLength"},
+	} {
+		if lit, ok := number(schema[length.key]); ok {
+			fmt.Fprintf(&b, "%s(%s)\n", length.fn, lit)
+		}
+	}
+	if boolean(schema, "readOnly") {
+		b.WriteString("ReadOnly()\n")
+	}
+	if boolean(schema, "writeOnly") {
+		b.WriteString("WriteOnly()\n")
+	}
+	return b.String()
+}
+
+// parameters returns the parameters of the given operation including the parameters of its path
+// item it does not override.
+func (c *converter) parameters(item, op map[string]interface{}) []map[string]interface{} {
+	var params []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, l := range [][]interface{}{list(op, "parameters"), list(item, "parameters")} {
+		for _, p := range l {
+			param, _ := p.(map[string]interface{})
+			param = c.deref(param)
+			if param == nil {
+				continue
+			}
+			key := str(param, "in") + " " + str(param, "name")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// merge returns the schema resulting from the combination of the allOf schemas of the given
+// schema with its own properties, the schema itself if it does not use allOf.
+func (c *converter) merge(schema map[string]interface{}) map[string]interface{} {
+	all := list(schema, "allOf")
+	if len(all) == 0 {
+		return schema
+	}
+	merged := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		if k != "allOf" {
+			merged[k] = v
+		}
+	}
+	props := make(map[string]interface{})
+	var required []interface{}
+	for _, s := range all {
+		sub, _ := s.(map[string]interface{})
+		ref := str(sub, "$ref")
+		if c.inlining[ref] {
+			continue
+		}
+		if ref != "" {
+			c.inlining[ref] = true
+		}
+		sub = c.merge(c.inline(sub))
+		delete(c.inlining, ref)
+		for k, v := range obj(sub, "properties") {
+			props[k] = v
+		}
+		required = append(required, list(sub, "required")...)
+	}
+	for k, v := range obj(schema, "properties") {
+		props[k] = v
+	}
+	required = append(required, list(schema, "required")...)
+	if len(props) > 0 {
+		merged["type"] = "object"
+		merged["properties"] = props
+		merged["required"] = required
+	}
+	return merged
+}
+
+// inline returns the schema referred to by the given schema if it is a reference, the schema
+// itself otherwise.
+func (c *converter) inline(schema map[string]interface{}) map[string]interface{} {
+	for i := 0; i < 10; i++ {
+		ref := str(schema, "$ref")
+		if ref == "" {
+			break
+		}
+		target := c.resolve(ref)
+		if target == nil {
+			return nil
+		}
+		schema = target
+	}
+	return schema
+}
+
+// deref returns the parameter, request body, response or path item referred to by the given
+// object if it is a reference, the object itself otherwise.
+func (c *converter) deref(o map[string]interface{}) map[string]interface{} {
+	return c.inline(o)
+}
+
+// resolve returns the object of the document located at the given local JSON reference.
+func (c *converter) resolve(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var cur interface{} = c.doc
+	for _, elem := range strings.Split(ref[2:], "/") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		elem = strings.Replace(strings.Replace(elem, "~1", "/", -1), "~0", "~", -1)
+		if u, err := url.PathUnescape(elem); err == nil {
+			elem = u
+		}
+		cur = m[elem]
+	}
+	m, _ := cur.(map[string]interface{})
+	return m
+}
+
+// resourceName returns the name of the resource of the given operation: its first tag or the
+// first segment of its path.
+func resourceName(path string, op map[string]interface{}) string {
+	if tags := list(op, "tags"); len(tags) > 0 {
+		return fmt.Sprint(tags[0])
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem != "" && !strings.HasPrefix(elem, "{") {
+			return elem
+		}
+	}
+	return "default"
+}
+
+// actionName returns the unique name of the action of the given operation: its operation ID or
+// a name built from its method and path. The prefix of the operation IDs of the documents
+// generated by goa ("resource#action") is removed.
+func actionName(o *operation, names map[string]bool) string {
+	name := str(o.op, "operationId")
+	if i := strings.LastIndex(name, "#"); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		name = o.method
+		for _, elem := range strings.Split(o.path, "/") {
+			elem = strings.Trim(elem, "{}")
+			if elem != "" {
+				name += "_" + codegen.SnakeCase(elem)
+			}
+		}
+	}
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	names[unique] = true
+	return unique
+}
+
+// routePath converts the OpenAPI path template into a goa route path, e.g. "/pets/:id" for
+// "/pets/{id}".
+func routePath(path string) string {
+	elems := strings.Split(path, "/")
+	for i, elem := range elems {
+		if strings.HasPrefix(elem, "{") && strings.HasSuffix(elem, "}") {
+			elems[i] = ":" + elem[1:len(elem)-1]
+		}
+	}
+	return strings.Join(elems, "/")
+}
+
+// pickContent returns the media type and the content used to build the payload or the response
+// body: the JSON content if any, the first content in lexical order otherwise.
+func pickContent(content map[string]interface{}) (string, map[string]interface{}) {
+	mts := keys(content)
+	for _, mt := range mts {
+		if mt == "application/json" || strings.HasSuffix(mt, "+json") {
+			return mt, obj(content, mt)
+		}
+	}
+	if len(mts) == 0 {
+		return "", nil
+	}
+	return mts[0], obj(content, mts[0])
+}
+
+// sortedGroups returns the names of the resources in lexical order.
+func sortedGroups(groups map[string][]*operation) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isObject returns true if the given schema describes an object with properties.
+func isObject(schema map[string]interface{}) bool {
+	return schemaType(schema) == "object" && len(obj(schema, "properties")) > 0
+}
+
+// schemaType returns the type of the given schema, inferred from its properties if not set.
+// The "null" type of OpenAPI 3.1 type lists is ignored.
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, e := range t {
+			if s := fmt.Sprint(e); s != "null" {
+				return s
+			}
+		}
+	}
+	switch {
+	case schema["properties"] != nil || schema["additionalProperties"] != nil:
+		return "object"
+	case schema["items"] != nil:
+		return "array"
+	}
+	return ""
+}
+
+// isSupportedFormat returns true if the given format is supported by the Format DSL.
+func isSupportedFormat(f string) bool {
+	for _, s := range apidsl.SupportedValidationFormats {
+		if s == f {
+			return true
+		}
+	}
+	return false
+}
+
+// literal returns the Go literal of the given scalar value.
+func literal(v interface{}) (string, bool) {
+	switch actual := v.(type) {
+	case string:
+		return strconv.Quote(actual), true
+	case bool:
+		return strconv.FormatBool(actual), true
+	}
+	return number(v)
+}
+
+// number returns the Go literal of the given number.
+func number(v interface{}) (string, bool) {
+	switch actual := v.(type) {
+	case int:
+		return strconv.Itoa(actual), true
+	case int64:
+		return strconv.FormatInt(actual, 10), true
+	case uint64:
+		return strconv.FormatUint(actual, 10), true
+	case float64:
+		return strconv.FormatFloat(actual, 'g', -1, 64), true
+	}
+	return "", false
+}
+
+// splitURL returns the scheme, host and path of the given server URL. The URL may contain
+// server variables which the standard URL parser rejects in hosts.
+func splitURL(u string) (scheme, host, path string) {
+	if i := strings.Index(u, "://"); i >= 0 {
+		scheme, u = u[:i], u[i+3:]
+	} else if !strings.HasPrefix(u, "//") {
+		return "", "", u
+	} else {
+		u = u[2:]
+	}
+	if i := strings.Index(u, "/"); i >= 0 {
+		return scheme, u[:i], u[i:]
+	}
+	return scheme, u, ""
+}
+
+// escapePointer escapes the given JSON pointer reference token.
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+// quoteAll returns the comma separated list of the given quoted strings.
+func quoteAll(vals []string) string {
+	quoted := make([]string, len(vals))
+	for i, v := range vals {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// normalize converts the maps produced by the YAML decoder into maps indexed by strings.
+func normalize(v interface{}) interface{} {
+	switch actual := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(actual))
+		for k, e := range actual {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range actual {
+			actual[i] = normalize(e)
+		}
+	}
+	return v
+}
+
+// keys returns the keys of the given map in lexical order.
+func keys(m map[string]interface{}) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// obj returns the object value of the given key, nil if there is none.
+func obj(m map[string]interface{}, key string) map[string]interface{} {
+	o, _ := m[key].(map[string]interface{})
+	return o
+}
+
+// list returns the array value of the given key, nil if there is none.
+func list(m map[string]interface{}, key string) []interface{} {
+	l, _ := m[key].([]interface{})
+	return l
+}
+
+// str returns the string value of the given key, the empty string if there is none.
+func str(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// boolean returns the boolean value of the given key, false if there is none.
+func boolean(m map[string]interface{}, key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
@@ -0,0 +1,342 @@
+package importer_test
+
+import (
+	"github.com/goadesign/goa/goagen/importer"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Import", func() {
+	var spec string
+	var src string
+	var err error
+
+	JustBeforeEach(func() {
+		var b []byte
+		b, err = importer.Import([]byte(spec), "design")
+		src = string(b)
+	})
+
+	Context("with a document that is not an OpenAPI document", func() {
+		BeforeEach(func() {
+			spec = `{"info": {"title": "foo"}}`
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("must be an OpenAPI 2 or 3 document"))
+		})
+	})
+
+	Context("with an invalid document", func() {
+		BeforeEach(func() {
+			spec = `{"swagger": `
+		})
+
+		It("returns an error", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("with an OpenAPI 2 document", func() {
+		BeforeEach(func() {
+			spec = swaggerSpec
+		})
+
+		It("generates the design", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(src).Should(Equal(swaggerDesign))
+		})
+	})
+
+	Context("with an OpenAPI 3 document", func() {
+		BeforeEach(func() {
+			spec = openAPISpec
+		})
+
+		It("generates the design", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(src).Should(Equal(openAPIDesign))
+		})
+	})
+})
+
+const swaggerSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "petstore", "description": "Pet store", "version": "1.0"},
+  "host": "pets.io",
+  "basePath": "/v1",
+  "schemes": ["https"],
+  "tags": [{"name": "pets", "description": "Pets"}],
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "type": "integer", "minimum": 1}],
+      "get": {
+        "tags": ["pets"],
+        "operationId": "show",
+        "summary": "Show a pet",
+        "parameters": [{"name": "fields", "in": "query", "type": "array", "items": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "OK", "schema": {"$ref": "#/definitions/Pet"}},
+          "404": {"description": "Not found"}
+        }
+      }
+    },
+    "/pets": {
+      "post": {
+        "tags": ["pets"],
+        "operationId": "create",
+        "parameters": [
+          {"name": "X-Request-ID", "in": "header", "type": "string", "format": "uuid"},
+          {"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Pet"}}
+        ],
+        "responses": {"201": {"description": "Created"}, "default": {"description": "Error"}}
+      }
+    },
+    "/uploads": {
+      "post": {
+        "consumes": ["multipart/form-data"],
+        "parameters": [
+          {"name": "file", "in": "formData", "type": "file", "required": true},
+          {"name": "comment", "in": "formData", "type": "string"}
+        ],
+        "responses": {"299": {"description": "Custom"}}
+      }
+    }
+  },
+  "definitions": {
+    "Pet": {
+      "type": "object",
+      "description": "A pet",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string", "minLength": 2},
+        "kind": {"$ref": "#/definitions/Kind"},
+        "born": {"type": "string", "format": "date-time"},
+        "owner": {"type": "object", "properties": {"email": {"type": "string", "format": "email"}}}
+      }
+    },
+    "Kind": {"type": "string", "enum": ["cat", "dog"], "default": "cat"}
+  }
+}`
+
+const swaggerDesign = `package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = API("petstore", func() {
+	Title("petstore")
+	Description("Pet store")
+	Version("1.0")
+	Host("pets.io")
+	Scheme("https")
+	BasePath("/v1")
+})
+
+var PetType = Type("Pet", func() {
+	Description("A pet")
+	Attribute("born", DateTime)
+	Attribute("kind", String, func() {
+		Enum("cat", "dog")
+		Default("cat")
+	})
+	Attribute("name", String, func() {
+		MinLength(2)
+	})
+	Attribute("owner", PetOwnerType)
+	Required("name")
+})
+
+var PostUploadsPayloadType = Type("PostUploadsPayload", func() {
+	Attribute("comment", String)
+	Attribute("file", File)
+	Required("file")
+})
+
+var PetOwnerType = Type("PetOwner", func() {
+	Attribute("email", String, func() {
+		Format("email")
+	})
+})
+
+var _ = Resource("pets", func() {
+	Description("Pets")
+	Action("create", func() {
+		Routing(POST("/pets"))
+		Headers(func() {
+			Header("X-Request-ID", UUID)
+		})
+		Payload(PetType)
+		Response(Created)
+	})
+	Action("show", func() {
+		Description("Show a pet")
+		Routing(GET("/pets/:id"))
+		Params(func() {
+			Param("fields", ArrayOf(String))
+			Param("id", Integer, func() {
+				Minimum(1)
+			})
+			Required("id")
+		})
+		Response(OK, PetType)
+		Response(NotFound)
+	})
+})
+
+var _ = Resource("uploads", func() {
+	Action("post_uploads", func() {
+		Routing(POST("/uploads"))
+		Payload(PostUploadsPayloadType)
+		MultipartForm()
+		Response("Status299", func() { Status(299) })
+	})
+})
+`
+
+const openAPISpec = `
+openapi: 3.0.1
+info:
+  title: accounts
+  version: "2.0"
+servers:
+  - url: https://api.accounts.io/v2
+  - url: https://{region}.accounts.io/v2
+    description: Regional
+    variables:
+      region:
+        default: us
+        enum: [us, eu]
+paths:
+  /accounts:
+    get:
+      operationId: list
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Account'
+    post:
+      operationId: create
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name:
+                  type: string
+                  maxLength: 64
+      responses:
+        "201":
+          $ref: '#/components/responses/Created'
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        maximum: 100
+        exclusiveMaximum: true
+  responses:
+    Created:
+      description: Created
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Account'
+  schemas:
+    Account:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            id:
+              type: string
+              readOnly: true
+            tags:
+              type: object
+              additionalProperties:
+                type: string
+    Named:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+`
+
+const openAPIDesign = `package design
+
+import (
+	. "github.com/goadesign/goa/design"
+	. "github.com/goadesign/goa/design/apidsl"
+)
+
+var _ = API("accounts", func() {
+	Title("accounts")
+	Version("2.0")
+	Host("api.accounts.io")
+	Scheme("https")
+	BasePath("/v2")
+	Server("server1", func() {
+		Host("api.accounts.io")
+		Scheme("https")
+	})
+	Server("server2", func() {
+		Description("Regional")
+		Host("{region}.accounts.io")
+		Scheme("https")
+		Variable("region", "us", "us", "eu")
+	})
+})
+
+var AccountType = Type("Account", func() {
+	Attribute("id", String, func() {
+		ReadOnly()
+	})
+	Attribute("name", String)
+	Attribute("tags", HashOf(String, String))
+	Required("name")
+})
+
+var NamedType = Type("Named", func() {
+	Attribute("name", String)
+	Required("name")
+})
+
+var CreatePayloadType = Type("CreatePayload", func() {
+	Attribute("name", String, func() {
+		MaxLength(64)
+	})
+	Required("name")
+})
+
+var _ = Resource("accounts", func() {
+	Action("list", func() {
+		Routing(GET("/accounts"))
+		Params(func() {
+			Param("limit", Integer, func() {
+				ExclusiveMaximum(100)
+			})
+		})
+		Response(OK, ArrayOf(AccountType))
+	})
+	Action("create", func() {
+		Routing(POST("/accounts"))
+		OptionalPayload(CreatePayloadType)
+		Response(Created, AccountType)
+	})
+})
+`
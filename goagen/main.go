@@ -38,7 +38,8 @@ the "app" command generates the code that supports the service controllers.
 
 The "bootstrap" command runs the "app", "main", "client" and "swagger" commands generating the
 controllers supporting code and main skeleton code (if not already present) as well as a client
-package and tool and the Swagger specification for the API.
+package and tool and the Swagger specification for the API. The --client-only and --server-only
+flags of the command restrict the generation to the client or to the server code.
 `}
 	var (
 		designPkg string
@@ -61,9 +62,11 @@ package and tool and the Swagger specification for the API.
 
 	// appCmd implements the "app" command.
 	var (
-		pkg    string
-		notest bool
+		pkg, di  string
+		notest   bool
+		services []string
 	)
+	const servicesUsage = "name of a `resource` to generate the code for, may be repeated, generate all if not specified"
 	appCmd := &cobra.Command{
 		Use:   "app",
 		Short: "Generate application code",
@@ -71,11 +74,13 @@ package and tool and the Swagger specification for the API.
 	}
 	appCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
 	appCmd.Flags().BoolVar(&notest, "notest", false, "Prevent generation of test helpers")
+	appCmd.Flags().StringVar(&di, "di", "", "generate the providers of the given dependency injection `framework`, wire or fx")
+	appCmd.Flags().StringSliceVar(&services, "service", nil, servicesUsage)
 	rootCmd.AddCommand(appCmd)
 
 	// mainCmd implements the "main" command.
 	var (
-		force, regen bool
+		force, regen, stubs, deploy, http3 bool
 	)
 	mainCmd := &cobra.Command{
 		Use:   "main",
@@ -84,12 +89,16 @@ package and tool and the Swagger specification for the API.
 	}
 	mainCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
 	mainCmd.Flags().BoolVar(&regen, "regen", false, "regenerate scaffolding, maintaining controller implementations")
+	mainCmd.Flags().BoolVar(&stubs, "stubs", false, "scaffold actions that return a \"not implemented\" error")
+	mainCmd.Flags().BoolVar(&deploy, "deploy", false, "generate a Dockerfile and Kubernetes manifests deploying the service")
+	mainCmd.Flags().BoolVar(&http3, "http3", false, "generate a main that also serves HTTP/3 over QUIC when run with the -http3 flag")
+	mainCmd.Flags().StringSliceVar(&services, "service", nil, servicesUsage)
 	rootCmd.AddCommand(mainCmd)
 
 	// clientCmd implements the "client" command.
 	var (
-		toolDir, tool string
-		notool        bool
+		toolDir, tool, testAppPkg string
+		notool                    bool
 	)
 	clientCmd := &cobra.Command{
 		Use:   "client",
@@ -100,16 +109,50 @@ package and tool and the Swagger specification for the API.
 	clientCmd.Flags().StringVar(&toolDir, "tooldir", "tool", "Name of generated tool directory")
 	clientCmd.Flags().StringVar(&tool, "tool", "[API-name]-cli", "Name of generated tool")
 	clientCmd.Flags().BoolVar(&notool, "notool", false, "Prevent generation of cli tool")
+	clientCmd.Flags().StringVar(&testAppPkg, "app-pkg", "", "`import path` of Go package generated with 'goagen app', may be relative to output, generates the client encoding round-trip tests if set")
+	clientCmd.Flags().StringSliceVar(&services, "service", nil, servicesUsage)
 	rootCmd.AddCommand(clientCmd)
 
 	// swaggerCmd implements the "swagger" command.
+	var split bool
 	swaggerCmd := &cobra.Command{
 		Use:   "swagger",
 		Short: "Generate Swagger",
 		Run:   func(c *cobra.Command, _ []string) { files, err = run("genswagger", c) },
 	}
+	swaggerCmd.Flags().StringSliceVar(&services, "service", nil, servicesUsage)
+	swaggerCmd.Flags().BoolVar(&split, "split", false, "also write the shared definitions to definitions.json and one document per resource referencing them to the services directory")
 	rootCmd.AddCommand(swaggerCmd)
 
+	// gatewayCmd implements the "gateway" command.
+	var upstream string
+	gatewayCmd := &cobra.Command{
+		Use:   "gateway",
+		Short: "Generate API gateway configuration",
+		Long: `The gateway command generates the Kong declarative configuration that proxies the API routes to the
+upstream service. The routes are secured using the design security schemes and rate limited with the
+limits set using the "gateway:rate-limit" metadata.
+`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("gengateway", c) },
+	}
+	gatewayCmd.Flags().StringVar(&upstream, "upstream", "", "URL of the proxied service, defaults to the API scheme and host")
+	rootCmd.AddCommand(gatewayCmd)
+
+	// pactCmd implements the "pact" command.
+	var consumer, provider string
+	pactCmd := &cobra.Command{
+		Use:   "pact",
+		Short: "Generate Pact consumer-driven contracts",
+		Long: `The pact command generates the Pact contract file built from the request and response examples of the
+design together with the Verify function that replays its interactions against the service. It also
+scaffolds the provider verification test in pacts/provider_test.go if the file does not exist.
+`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genpact", c) },
+	}
+	pactCmd.Flags().StringVar(&consumer, "consumer", "", "name of the consumer of the contract, defaults to the API name followed by -client")
+	pactCmd.Flags().StringVar(&provider, "provider", "", "name of the provider of the contract, defaults to the API name")
+	rootCmd.AddCommand(pactCmd)
+
 	// jsCmd implements the "js" command.
 	var (
 		timeout      = time.Duration(20) * time.Second
@@ -135,6 +178,74 @@ package and tool and the Swagger specification for the API.
 	}
 	rootCmd.AddCommand(schemaCmd)
 
+	// modelCmd implements the "model" command.
+	modelCmd := &cobra.Command{
+		Use:   "model",
+		Short: "Generate comparable design model",
+		Run:   func(c *cobra.Command, _ []string) { files, err = run("genmodel", c) },
+	}
+	rootCmd.AddCommand(modelCmd)
+
+	// diffCmd implements the "diff" command.
+	var (
+		jsonOutput bool
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Report breaking changes between two design models",
+		Long: `The diff command compares two design models produced by the "model" command and reports
+the changes that may break existing clients such as removed endpoints, narrowed types or new required
+fields. The command exits with status 1 if it finds breaking changes.
+`,
+		Run: func(c *cobra.Command, args []string) { err = diff(args, jsonOutput) },
+	}
+	diffCmd.Flags().BoolVar(&jsonOutput, "json", false, "print the changes in JSON")
+	rootCmd.AddCommand(diffCmd)
+
+	// changelogCmd implements the "changelog" command.
+	var snapshot, release string
+	changelogCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate changelog of API surface",
+		Long: `The changelog command compares the design against the design model produced by the "model" command
+for the previous release and writes the Markdown changelog fragment that lists the breaking changes, the
+new endpoints, the new errors and the changed types to changelog/RELEASE.md. Run the "model" command
+once the release is cut to update the snapshot.
+`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genchangelog", c) },
+	}
+	changelogCmd.Flags().StringVar(&snapshot, "snapshot", "", "path to the design model of the previous release, defaults to model/design.json in the output directory")
+	changelogCmd.Flags().StringVar(&release, "release", "Unreleased", "name of the release described by the changelog")
+	rootCmd.AddCommand(changelogCmd)
+
+	// importCmd implements the "import" command.
+	var (
+		importPkg string
+	)
+	importCmd := &cobra.Command{
+		Use:   "import DOCUMENT",
+		Short: "Generate a design package from an OpenAPI document",
+		Long: `The import command converts an OpenAPI 2 or 3 document written in JSON or YAML into a design
+file defining the API, its types and one resource per operation tag. The design file is written to
+design.go in the output directory.
+`,
+		Run: func(c *cobra.Command, args []string) { files, err = importDesign(c, args, importPkg, force) },
+	}
+	importCmd.Flags().StringVar(&importPkg, "pkg", "design", "name of the generated design `package`")
+	importCmd.Flags().BoolVar(&force, "force", false, "overwrite an existing design file")
+	rootCmd.AddCommand(importCmd)
+
+	// lintCmd implements the "lint" command.
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check design against style rules",
+		Long: `The lint command checks the design against a set of style rules and lists the violations.
+Rules may be suppressed for a definition and its children with the "lint:ignore" metadata.
+`,
+		Run: func(c *cobra.Command, _ []string) { files, err = run("genlint", c) },
+	}
+	rootCmd.AddCommand(lintCmd)
+
 	// genCmd implements the "gen" command.
 	var (
 		pkgPath string
@@ -151,36 +262,42 @@ package and tool and the Swagger specification for the API.
 	rootCmd.AddCommand(genCmd)
 
 	// boostrapCmd implements the "bootstrap" command.
+	var (
+		clientOnly, serverOnly bool
+	)
 	bootCmd := &cobra.Command{
 		Use:   "bootstrap",
 		Short: `Equivalent to running the "app", "main", "client" and "swagger" commands.`,
-		Run: func(c *cobra.Command, a []string) {
-			appCmd.Run(c, a)
-			if err != nil {
-				return
-			}
-			prev := files
+		Long: `The bootstrap command runs the "app", "main", "client" and "swagger" commands.
 
-			mainCmd.Run(c, a)
-			if err != nil {
+With --client-only the command only runs the "client" and "swagger" commands, for services that
+consume an API implemented elsewhere. With --server-only the command only runs the "app", "main"
+and "swagger" commands, for services that do not ship a Go client.
+`,
+		Run: func(c *cobra.Command, a []string) {
+			names, e := bootstrapCommands(clientOnly, serverOnly)
+			if e != nil {
+				err = e
 				return
 			}
-			prev = append(prev, files...)
-
-			clientCmd.Run(c, a)
-			if err != nil {
-				return
+			cmds := map[string]*cobra.Command{"app": appCmd, "main": mainCmd, "client": clientCmd, "swagger": swaggerCmd}
+			var prev []string
+			for _, n := range names {
+				cmds[n].Run(c, a)
+				prev = append(prev, files...)
+				if err != nil {
+					break
+				}
 			}
-			prev = append(prev, files...)
-
-			swaggerCmd.Run(c, a)
-			files = append(prev, files...)
+			files = prev
 		},
 	}
 	bootCmd.Flags().AddFlagSet(appCmd.Flags())
 	bootCmd.Flags().AddFlagSet(mainCmd.Flags())
 	bootCmd.Flags().AddFlagSet(clientCmd.Flags())
 	bootCmd.Flags().AddFlagSet(swaggerCmd.Flags())
+	bootCmd.Flags().BoolVar(&clientOnly, "client-only", false, "generate the client package and tool but not the server code")
+	bootCmd.Flags().BoolVar(&serverOnly, "server-only", false, "generate the server code but not the client package and tool")
 	rootCmd.AddCommand(bootCmd)
 
 	// controllerCmd implements the "controller" command.
@@ -194,11 +311,49 @@ package and tool and the Swagger specification for the API.
 	}
 	controllerCmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
 	controllerCmd.Flags().BoolVar(&regen, "regen", false, "regenerate scaffolding, maintaining controller implementations")
+	controllerCmd.Flags().BoolVar(&stubs, "stubs", false, "scaffold actions that return a \"not implemented\" error")
 	controllerCmd.Flags().StringVar(&res, "res", "", "name of the `resource` to generate the controller for, generate all if not specified")
 	controllerCmd.Flags().StringVar(&pkg, "pkg", "main", "name of the generated controller `package`")
 	controllerCmd.Flags().StringVar(&appPkg, "app-pkg", "app", "`import path` of Go package generated with 'goagen app', may be relative to output")
 	rootCmd.AddCommand(controllerCmd)
 
+	// serveCmd implements the "serve" command.
+	var (
+		watch    bool
+		interval = time.Second
+	)
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Generate, build and run the service",
+		Long: `The serve command generates the application code and the main scaffolding (if not already
+present), builds the service and runs it.
+
+With --watch the command keeps polling the design package and the service sources for changes.
+Changes to the design regenerate the application code, any change rebuilds and restarts the service.
+`,
+		Run: func(c *cobra.Command, _ []string) { err = serve(c, designPkg, pkg, watch, interval) },
+	}
+	serveCmd.Flags().StringVar(&pkg, "pkg", "app", "Name of generated Go package containing controllers supporting code (contexts, media types, user types etc.)")
+	serveCmd.Flags().BoolVar(&watch, "watch", false, "regenerate, rebuild and restart the service when the design or the service sources change")
+	serveCmd.Flags().DurationVar(&interval, "interval", interval, "interval at which the sources are polled for changes")
+	rootCmd.AddCommand(serveCmd)
+
+	// cleanCmd implements the "clean" command.
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Delete generated files",
+		Long: `The clean command deletes the files recorded in the manifest written by the generators in the
+output directory. Scaffolding files created by the "main" and "controller" commands are preserved.
+`,
+		Run: func(c *cobra.Command, _ []string) {
+			var out string
+			if out, err = filepath.Abs(c.Flag("out").Value.String()); err == nil {
+				_, err = clean(out)
+			}
+		},
+	}
+	rootCmd.AddCommand(cleanCmd)
+
 	// cmdsCmd implements the commands command
 	// It lists all the commands and flags in JSON to enable shell integrations.
 	cmdsCmd := &cobra.Command{
@@ -245,7 +400,30 @@ package and tool and the Swagger specification for the API.
 	fmt.Println(strings.Join(rels, "\n"))
 }
 
+// bootstrapCommands returns the names of the commands run by the bootstrap command given the
+// values of its --client-only and --server-only flags.
+func bootstrapCommands(clientOnly, serverOnly bool) ([]string, error) {
+	switch {
+	case clientOnly && serverOnly:
+		return nil, fmt.Errorf("--client-only and --server-only cannot be used together")
+	case clientOnly:
+		return []string{"client", "swagger"}, nil
+	case serverOnly:
+		return []string{"app", "main", "swagger"}, nil
+	}
+	return []string{"app", "main", "client", "swagger"}, nil
+}
+
 func run(pkg string, c *cobra.Command) ([]string, error) {
+	flags, err := commandFlags(c)
+	if err != nil {
+		return nil, err
+	}
+	return runWith(pkg, flags)
+}
+
+// runWith runs the goagen generator package identified by pkg with the given flags.
+func runWith(pkg string, flags map[string]string) ([]string, error) {
 	pkgPath := fmt.Sprintf("github.com/goadesign/goa/goagen/gen_%s", pkg[3:])
 	pkgSrcPath, err := codegen.PackageSourcePath(pkgPath)
 	if err != nil {
@@ -255,7 +433,14 @@ func run(pkg string, c *cobra.Command) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid package import path: %s", err)
 	}
-	return generate(pkgName, pkgPath, c, nil)
+	files, err := generate(pkgName, pkgPath, flags, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateManifest(pkg, flags["out"], files); err != nil {
+		return nil, err
+	}
+	return files, nil
 }
 
 func runGen(c *cobra.Command, args []string) ([]string, error) {
@@ -268,15 +453,33 @@ func runGen(c *cobra.Command, args []string) ([]string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid plugin package import path: %s", err)
 	}
-	return generate(pkgName, pkgPath, c, args)
+	flags, err := commandFlags(c)
+	if err != nil {
+		return nil, err
+	}
+	return generate(pkgName, pkgPath, flags, args)
+}
+
+// localFlags lists the flags used by goagen itself that are not forwarded to the generators.
+var localFlags = map[string]bool{
+	"pkg-path":    true,
+	"client-only": true,
+	"server-only": true,
 }
 
-func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]string, error) {
+// commandFlags returns the flags set on the command line that get forwarded to the generator.
+func commandFlags(c *cobra.Command) (map[string]string, error) {
 	m := make(map[string]string)
 	c.Flags().Visit(func(f *pflag.Flag) {
-		if f.Name != "pkg-path" {
-			m[f.Name] = f.Value.String()
+		if localFlags[f.Name] {
+			return
+		}
+		if f.Value.Type() == "stringSlice" {
+			// forward the values of repeated flags as a comma separated list
+			m[f.Name] = strings.Trim(f.Value.String(), "[]")
+			return
 		}
+		m[f.Name] = f.Value.String()
 	})
 	if _, ok := m["out"]; !ok {
 		m["out"] = c.Flag("out").DefValue
@@ -287,11 +490,14 @@ func generate(pkgName, pkgPath string, c *cobra.Command, args []string) ([]strin
 	if err != nil {
 		return nil, err
 	}
+	return m, nil
+}
 
+func generate(pkgName, pkgPath string, flags map[string]string, args []string) ([]string, error) {
 	gen, err := meta.NewGenerator(
 		pkgName+".Generate",
 		[]*codegen.ImportSpec{codegen.SimpleImport(pkgPath)},
-		m,
+		flags,
 		args,
 	)
 	if err != nil {
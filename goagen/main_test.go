@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("bootstrapCommands", func() {
+	It("runs all the generators by default", func() {
+		cmds, err := bootstrapCommands(false, false)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cmds).Should(Equal([]string{"app", "main", "client", "swagger"}))
+	})
+
+	It("only runs the client and swagger generators with --client-only", func() {
+		cmds, err := bootstrapCommands(true, false)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cmds).Should(Equal([]string{"client", "swagger"}))
+	})
+
+	It("only runs the server and swagger generators with --server-only", func() {
+		cmds, err := bootstrapCommands(false, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(cmds).Should(Equal([]string{"app", "main", "swagger"}))
+	})
+
+	It("rejects --client-only used with --server-only", func() {
+		cmds, err := bootstrapCommands(true, true)
+		Ω(err).Should(MatchError("--client-only and --server-only cannot be used together"))
+		Ω(cmds).Should(BeNil())
+	})
+})
+
+var _ = Describe("commandFlags", func() {
+	It("does not forward the bootstrap flags to the generators", func() {
+		c := &cobra.Command{Use: "bootstrap"}
+		c.Flags().String("out", ".", "")
+		c.Flags().String("design", "", "")
+		c.Flags().Bool("client-only", false, "")
+		c.Flags().Bool("server-only", false, "")
+		Ω(c.Flags().Parse([]string{"--design", "github.com/x/design", "--client-only"})).ShouldNot(HaveOccurred())
+		flags, err := commandFlags(c)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(flags).Should(HaveKeyWithValue("design", "github.com/x/design"))
+		Ω(flags).Should(HaveKey("out"))
+		Ω(flags).ShouldNot(HaveKey("client-only"))
+	})
+})
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestFile is the name of the file written in the output directory that records the files
+// produced by each generator.
+const manifestFile = ".goagen.json"
+
+// scaffolding lists the generators that produce files meant to be edited, these files are
+// recorded in the manifest but never pruned or cleaned.
+var scaffolding = map[string]bool{"genmain": true, "gencontroller": true}
+
+// manifest records the files produced by each generator relative to the output directory.
+type manifest struct {
+	Generators map[string][]string `json:"generators"`
+	// Checksums records the checksums of the generated files, the files edited since they
+	// were generated are never deleted.
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
+// loadManifest reads the manifest of the given output directory, it returns an empty manifest if
+// there is none.
+func loadManifest(out string) (*manifest, error) {
+	m := &manifest{Generators: make(map[string][]string), Checksums: make(map[string]string)}
+	b, err := ioutil.ReadFile(filepath.Join(out, manifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	if m.Generators == nil {
+		m.Generators = make(map[string][]string)
+	}
+	if m.Checksums == nil {
+		m.Checksums = make(map[string]string)
+	}
+	return m, nil
+}
+
+// save writes the manifest in the given output directory.
+func (m *manifest) save(out string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(out, manifestFile), b, 0644)
+}
+
+// updateManifest records the files produced by the given generator and deletes the files
+// produced by the previous run of the generator that no longer exist in the design, e.g. the
+// client files of a removed resource or the package generated under a previous name.
+func updateManifest(gen, out string, files []string) error {
+	m, err := loadManifest(out)
+	if err != nil {
+		return err
+	}
+	rels := make([]string, 0, len(files))
+	current := make(map[string]bool, len(files))
+	for _, f := range files {
+		rel, err := filepath.Rel(out, f)
+		if err != nil {
+			rel = f
+		}
+		if !current[rel] {
+			current[rel] = true
+			rels = append(rels, rel)
+		}
+	}
+	if len(rels) == 0 && len(m.Generators[gen]) == 0 {
+		return nil
+	}
+	if !scaffolding[gen] {
+		var stale []string
+		for _, rel := range m.Generators[gen] {
+			if !current[rel] {
+				stale = append(stale, rel)
+			}
+		}
+		m.remove(out, stale)
+	} else {
+		// Scaffolding generators only report the files they create, keep the others.
+		for _, rel := range m.Generators[gen] {
+			if !current[rel] {
+				current[rel] = true
+				rels = append(rels, rel)
+			}
+		}
+	}
+	if !scaffolding[gen] {
+		for _, rel := range rels {
+			if sum, err := checksum(filepath.Join(out, rel)); err == nil {
+				m.Checksums[rel] = sum
+			}
+		}
+	}
+	sort.Strings(rels)
+	m.Generators[gen] = rels
+	return m.save(out)
+}
+
+// clean deletes the files recorded in the manifest of the given output directory except for
+// scaffolding and returns the paths of the deleted files.
+func clean(out string) ([]string, error) {
+	m, err := loadManifest(out)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for gen, rels := range m.Generators {
+		if scaffolding[gen] {
+			continue
+		}
+		deleted = append(deleted, m.remove(out, rels)...)
+		delete(m.Generators, gen)
+	}
+	sort.Strings(deleted)
+	if len(m.Generators) == 0 {
+		err = os.Remove(filepath.Join(out, manifestFile))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return deleted, err
+	}
+	return deleted, m.save(out)
+}
+
+// remove deletes the given files and then the given directories if they are empty and forgets
+// their checksums. Paths are relative to out, paths that lead outside of out and files whose
+// content no longer matches their checksum are left untouched. It returns the absolute paths of
+// the deleted files and directories.
+func (m *manifest) remove(out string, rels []string) []string {
+	var dirs, deleted []string
+	for _, rel := range rels {
+		sum, generated := m.Checksums[rel]
+		delete(m.Checksums, rel)
+		if !within(rel) {
+			continue
+		}
+		p := filepath.Join(out, rel)
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			dirs = append(dirs, p)
+			continue
+		}
+		if generated {
+			if current, err := checksum(p); err != nil || current != sum {
+				continue
+			}
+		}
+		if os.Remove(p) == nil {
+			deleted = append(deleted, p)
+		}
+	}
+	// Remove nested directories first.
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, d := range dirs {
+		if os.Remove(d) == nil {
+			deleted = append(deleted, d)
+		}
+	}
+	return deleted
+}
+
+// within returns true if the relative path rel designates a file of the output directory.
+func within(rel string) bool {
+	if filepath.IsAbs(rel) {
+		return false
+	}
+	rel = filepath.Clean(rel)
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the content of the given file.
+func checksum(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
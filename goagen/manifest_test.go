@@ -0,0 +1,148 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("manifest", func() {
+	var out string
+
+	write := func(rel, content string) string {
+		p := filepath.Join(out, rel)
+		Ω(os.MkdirAll(filepath.Dir(p), 0755)).ShouldNot(HaveOccurred())
+		Ω(ioutil.WriteFile(p, []byte(content), 0644)).ShouldNot(HaveOccurred())
+		return p
+	}
+
+	exists := func(rel string) bool {
+		_, err := os.Stat(filepath.Join(out, rel))
+		return err == nil
+	}
+
+	BeforeEach(func() {
+		var err error
+		out, err = ioutil.TempDir("", "goagen-manifest")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(out)
+	})
+
+	Describe("updateManifest", func() {
+		It("records the generated files", func() {
+			files := []string{write("client/bottle.go", "a"), write("client/client.go", "b")}
+			Ω(updateManifest("client", out, files)).ShouldNot(HaveOccurred())
+			m, err := loadManifest(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Generators["client"]).Should(Equal([]string{"client/bottle.go", "client/client.go"}))
+			Ω(m.Checksums).Should(HaveKey("client/bottle.go"))
+		})
+
+		It("prunes the stale generated files", func() {
+			files := []string{write("client/bottle.go", "a"), write("client/account.go", "b"), filepath.Join(out, "client")}
+			Ω(updateManifest("client", out, files)).ShouldNot(HaveOccurred())
+			Ω(updateManifest("client", out, files[:1])).ShouldNot(HaveOccurred())
+			Ω(exists("client/bottle.go")).Should(BeTrue())
+			Ω(exists("client/account.go")).Should(BeFalse())
+			m, err := loadManifest(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Generators["client"]).Should(Equal([]string{"client/bottle.go"}))
+			Ω(m.Checksums).ShouldNot(HaveKey("client/account.go"))
+		})
+
+		It("removes the stale directories once empty", func() {
+			files := []string{write("old/client.go", "a"), filepath.Join(out, "old")}
+			Ω(updateManifest("client", out, files)).ShouldNot(HaveOccurred())
+			Ω(updateManifest("client", out, []string{write("new/client.go", "a")})).ShouldNot(HaveOccurred())
+			Ω(exists("old")).Should(BeFalse())
+			Ω(exists("new/client.go")).Should(BeTrue())
+		})
+
+		It("keeps the files produced by other generators", func() {
+			Ω(updateManifest("app", out, []string{write("app/contexts.go", "a")})).ShouldNot(HaveOccurred())
+			Ω(updateManifest("client", out, []string{write("client/client.go", "b")})).ShouldNot(HaveOccurred())
+			Ω(updateManifest("client", out, nil)).ShouldNot(HaveOccurred())
+			Ω(exists("app/contexts.go")).Should(BeTrue())
+			Ω(exists("client/client.go")).Should(BeFalse())
+		})
+
+		It("never deletes scaffolding", func() {
+			Ω(updateManifest("genmain", out, []string{write("main.go", "a"), write("bottle.go", "b")})).ShouldNot(HaveOccurred())
+			Ω(updateManifest("genmain", out, nil)).ShouldNot(HaveOccurred())
+			Ω(exists("main.go")).Should(BeTrue())
+			Ω(exists("bottle.go")).Should(BeTrue())
+			m, err := loadManifest(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Generators["genmain"]).Should(Equal([]string{"bottle.go", "main.go"}))
+		})
+
+		It("never deletes the files edited since they were generated", func() {
+			Ω(updateManifest("client", out, []string{write("client/client.go", "a")})).ShouldNot(HaveOccurred())
+			write("client/client.go", "edited")
+			Ω(updateManifest("client", out, nil)).ShouldNot(HaveOccurred())
+			Ω(exists("client/client.go")).Should(BeTrue())
+			m, err := loadManifest(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Generators["client"]).Should(BeEmpty())
+			Ω(m.Checksums).Should(BeEmpty())
+		})
+
+		It("never deletes the files outside of the output directory", func() {
+			outside, err := ioutil.TempDir("", "goagen-outside")
+			Ω(err).ShouldNot(HaveOccurred())
+			defer os.RemoveAll(outside)
+			user := filepath.Join(outside, "user.go")
+			Ω(ioutil.WriteFile(user, []byte("a"), 0644)).ShouldNot(HaveOccurred())
+			rel, err := filepath.Rel(out, user)
+			Ω(err).ShouldNot(HaveOccurred())
+			m := &manifest{Generators: map[string][]string{"client": {rel, user}}}
+			Ω(m.save(out)).ShouldNot(HaveOccurred())
+
+			Ω(updateManifest("client", out, []string{write("client/client.go", "b")})).ShouldNot(HaveOccurred())
+			Ω(user).Should(BeAnExistingFile())
+		})
+	})
+
+	Describe("clean", func() {
+		It("deletes only the files listed in the manifest", func() {
+			app := write("app/contexts.go", "a")
+			Ω(updateManifest("app", out, []string{app, filepath.Join(out, "app")})).ShouldNot(HaveOccurred())
+			Ω(updateManifest("genmain", out, []string{write("main.go", "b")})).ShouldNot(HaveOccurred())
+			write("app/user.go", "c")
+			write("other.go", "d")
+
+			deleted, err := clean(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(deleted).Should(Equal([]string{app}))
+			Ω(exists("app/contexts.go")).Should(BeFalse())
+			Ω(exists("app/user.go")).Should(BeTrue())
+			Ω(exists("other.go")).Should(BeTrue())
+			Ω(exists("main.go")).Should(BeTrue())
+			m, err := loadManifest(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(m.Generators).Should(HaveKey("genmain"))
+			Ω(m.Generators).ShouldNot(HaveKey("app"))
+		})
+
+		It("deletes the manifest once all the generated files are deleted", func() {
+			Ω(updateManifest("app", out, []string{write("app/contexts.go", "a")})).ShouldNot(HaveOccurred())
+			_, err := clean(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(exists(manifestFile)).Should(BeFalse())
+		})
+
+		It("does nothing without a manifest", func() {
+			write("main.go", "a")
+			deleted, err := clean(out)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(deleted).Should(BeEmpty())
+			Ω(exists("main.go")).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goadesign/goa/goagen/codegen"
+	"github.com/spf13/cobra"
+)
+
+// serve generates the application code and main scaffolding, builds the service and runs it. If
+// watch is true serve then polls the design package and the service sources every interval:
+// changes to the design regenerate the application code and any change rebuilds and restarts the
+// service. If the service exits while watching serve reports it and starts it again on the next
+// change. serve returns when the process is interrupted.
+func serve(c *cobra.Command, designPkg, target string, watch bool, interval time.Duration) error {
+	if designPkg == "" {
+		return fmt.Errorf("missing design package flag")
+	}
+	out, err := filepath.Abs(c.Flag("out").Value.String())
+	if err != nil {
+		return err
+	}
+	designDir, err := codegen.PackageSourcePath(designPkg)
+	if err != nil {
+		return fmt.Errorf("invalid design package import path: %s", err)
+	}
+	tmpDir, err := ioutil.TempDir("", "goagen-serve")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var (
+		bin   = filepath.Join(tmpDir, filepath.Base(out))
+		flags = map[string]string{"out": out, "design": designPkg, "pkg": target}
+		svc   *process
+	)
+	regenerate := func() error {
+		if _, err := runWith("genapp", flags); err != nil {
+			return err
+		}
+		_, err := runWith("genmain", flags)
+		return err
+	}
+	restart := func() error {
+		build := exec.Command("go", "build", "-o", bin, ".")
+		build.Dir = out
+		if o, err := build.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s\n%s", err, string(o))
+		}
+		svc.stop()
+		p, err := startProcess(bin, out)
+		svc = p
+		return err
+	}
+	defer func() { svc.stop() }()
+
+	if err := regenerate(); err != nil {
+		return err
+	}
+	if err := restart(); err != nil {
+		return err
+	}
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	if !watch {
+		select {
+		case <-svc.exited():
+			return svc.err
+		case <-sig:
+			return nil
+		}
+	}
+
+	dirs := []string{designDir, out}
+	snapshot := modTimes(dirs)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sig:
+			return nil
+		case <-svc.exited():
+			if svc.err != nil {
+				fmt.Fprintf(os.Stderr, "service exited: %s, waiting for changes\n", svc.err)
+			} else {
+				fmt.Fprintln(os.Stderr, "service exited, waiting for changes")
+			}
+			svc = nil
+			continue
+		case <-ticker.C:
+		}
+		current := modTimes(dirs)
+		changed := changedFiles(snapshot, current)
+		if len(changed) == 0 {
+			continue
+		}
+		for _, f := range changed {
+			if strings.HasPrefix(f, designDir+string(filepath.Separator)) {
+				fmt.Fprintln(os.Stderr, "design changed, regenerating")
+				if err := regenerate(); err != nil {
+					fmt.Fprintln(os.Stderr, err.Error())
+				}
+				break
+			}
+		}
+		fmt.Fprintln(os.Stderr, "rebuilding and restarting service")
+		if err := restart(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		// Take the snapshot after generation so that the generated files do not retrigger.
+		snapshot = modTimes(dirs)
+	}
+}
+
+// process is a service process started by serve.
+type process struct {
+	cmd *exec.Cmd
+	// done is closed once the process exited.
+	done chan struct{}
+	// err is the error returned by Wait, set before done is closed.
+	err error
+}
+
+// startProcess starts the given service binary in dir and reaps the process once it exits.
+func startProcess(bin, dir string) (*process, error) {
+	cmd := exec.Command(bin)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// exited returns a channel closed once the process exited, the channel is nil if p is nil.
+func (p *process) exited() <-chan struct{} {
+	if p == nil {
+		return nil
+	}
+	return p.done
+}
+
+// stop interrupts the process and waits for it to exit, killing it if it does not exit in a
+// timely manner. stop does nothing if p is nil or if the process already exited.
+func (p *process) stop() {
+	if p == nil {
+		return
+	}
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.done:
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// modTimes returns the modification times of the Go source files under the given directories.
+func modTimes(dirs []string) map[string]time.Time {
+	res := make(map[string]time.Time)
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if info.IsDir() {
+				if path != dir && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, ".go") {
+				res[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return res
+}
+
+// changedFiles returns the paths of the files added, removed or modified between the two
+// snapshots.
+func changedFiles(before, after map[string]time.Time) []string {
+	var res []string
+	for path, t := range after {
+		if bt, ok := before[path]; !ok || !bt.Equal(t) {
+			res = append(res, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			res = append(res, path)
+		}
+	}
+	return res
+}
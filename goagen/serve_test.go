@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("changedFiles", func() {
+	t0 := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+
+	cases := []struct {
+		desc          string
+		before, after map[string]time.Time
+		expected      []string
+	}{
+		{
+			desc:   "no change",
+			before: map[string]time.Time{"a.go": t0, "b.go": t0},
+			after:  map[string]time.Time{"a.go": t0, "b.go": t0},
+		},
+		{
+			desc:     "added files",
+			before:   map[string]time.Time{"a.go": t0},
+			after:    map[string]time.Time{"a.go": t0, "b.go": t0, "c.go": t1},
+			expected: []string{"b.go", "c.go"},
+		},
+		{
+			desc:     "removed files",
+			before:   map[string]time.Time{"a.go": t0, "b.go": t0},
+			after:    map[string]time.Time{"b.go": t0},
+			expected: []string{"a.go"},
+		},
+		{
+			desc:     "modified files",
+			before:   map[string]time.Time{"a.go": t0, "b.go": t0},
+			after:    map[string]time.Time{"a.go": t1, "b.go": t0},
+			expected: []string{"a.go"},
+		},
+		{
+			desc:     "added, removed and modified files",
+			before:   map[string]time.Time{"a.go": t0, "b.go": t0},
+			after:    map[string]time.Time{"b.go": t1, "c.go": t0},
+			expected: []string{"a.go", "b.go", "c.go"},
+		},
+		{
+			desc:     "first snapshot",
+			after:    map[string]time.Time{"a.go": t0},
+			expected: []string{"a.go"},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		It("reports "+c.desc, func() {
+			changed := changedFiles(c.before, c.after)
+			sort.Strings(changed)
+			if c.expected == nil {
+				Ω(changed).Should(BeEmpty())
+			} else {
+				Ω(changed).Should(Equal(c.expected))
+			}
+		})
+	}
+})
+
+var _ = Describe("modTimes", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goagen-serve")
+		Ω(err).ShouldNot(HaveOccurred())
+		for _, f := range []string{"main.go", "app/contexts.go", "README.md", ".git/hook.go"} {
+			p := filepath.Join(dir, f)
+			Ω(os.MkdirAll(filepath.Dir(p), 0755)).ShouldNot(HaveOccurred())
+			Ω(ioutil.WriteFile(p, nil, 0644)).ShouldNot(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("lists the Go files outside of hidden directories", func() {
+		times := modTimes([]string{dir})
+		Ω(times).Should(HaveLen(2))
+		Ω(times).Should(HaveKey(filepath.Join(dir, "main.go")))
+		Ω(times).Should(HaveKey(filepath.Join(dir, "app", "contexts.go")))
+	})
+})
+
+var _ = Describe("process", func() {
+	start := func(name string) *process {
+		bin, err := exec.LookPath(name)
+		if err != nil {
+			Skip(name + " not found")
+		}
+		p, err := startProcess(bin, os.TempDir())
+		Ω(err).ShouldNot(HaveOccurred())
+		return p
+	}
+
+	It("reports the exit of the process", func() {
+		p := start("true")
+		Eventually(p.exited()).Should(BeClosed())
+		Ω(p.err).ShouldNot(HaveOccurred())
+	})
+
+	It("reports the crash of the process", func() {
+		p := start("false")
+		Eventually(p.exited()).Should(BeClosed())
+		Ω(p.err).Should(HaveOccurred())
+	})
+
+	It("does nothing when stopping an exited process", func() {
+		p := start("true")
+		Eventually(p.exited()).Should(BeClosed())
+		p.stop()
+	})
+
+	It("handles the absence of process", func() {
+		var p *process
+		Ω(p.exited()).Should(BeNil())
+		p.stop()
+	})
+})
@@ -0,0 +1,144 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+// hexDigits is used to escape control characters in JSON strings.
+const hexDigits = "0123456789abcdef"
+
+// AppendJSONBool appends the JSON encoding of v to dst and returns the extended buffer.
+func AppendJSONBool(dst []byte, v bool) []byte {
+	return strconv.AppendBool(dst, v)
+}
+
+// AppendJSONInt appends the JSON encoding of v to dst and returns the extended buffer.
+func AppendJSONInt(dst []byte, v int64) []byte {
+	return strconv.AppendInt(dst, v, 10)
+}
+
+// AppendJSONFloat appends the JSON encoding of v to dst and returns the extended buffer. It uses
+// the same format as the encoding/json package. NaN and infinite values have no JSON encoding,
+// callers must not use AppendJSONFloat with them.
+func AppendJSONFloat(dst []byte, v float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(v); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, v, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// AppendJSONString appends the JSON encoding of v to dst and returns the extended buffer. Like the
+// encoding/json package it escapes the HTML characters <, > and &, the U+2028 and U+2029 line
+// separators and replaces invalid UTF-8 with the replacement rune.
+func AppendJSONString(dst []byte, v string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(v); {
+		if b := v[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, v[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(v[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, v[start:i]...)
+			dst = append(dst, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, v[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, v[start:]...)
+	return append(dst, '"')
+}
+
+// PruneJSON returns the JSON encoding of v that only contains the object fields whose names are
+// listed in fields. The elements of arrays are pruned individually so that v may be a collection.
+// The generated response helpers of actions with a field mask use PruneJSON with SendJSON to
+// render the fields requested by the client.
+func PruneJSON(v interface{}, fields []string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		keep[f] = true
+	}
+	return pruneJSON(b, keep)
+}
+
+// pruneJSON removes the fields not listed in keep from the JSON object or the elements of the JSON
+// array encoded in b.
+func pruneJSON(b []byte, keep map[string]bool) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return b, nil
+	}
+	switch b[0] {
+	case '{':
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return nil, err
+		}
+		for k := range obj {
+			if !keep[k] {
+				delete(obj, k)
+			}
+		}
+		return json.Marshal(obj)
+	case '[':
+		var elems []json.RawMessage
+		if err := json.Unmarshal(b, &elems); err != nil {
+			return nil, err
+		}
+		for i, e := range elems {
+			pruned, err := pruneJSON(e, keep)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = pruned
+		}
+		return json.Marshal(elems)
+	}
+	return b, nil
+}
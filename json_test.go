@@ -0,0 +1,67 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppendJSON", func() {
+	marshal := func(v interface{}) string {
+		b, err := json.Marshal(v)
+		Ω(err).ShouldNot(HaveOccurred())
+		return string(b)
+	}
+
+	It("encodes booleans", func() {
+		Ω(string(goa.AppendJSONBool(nil, true))).Should(Equal("true"))
+		Ω(string(goa.AppendJSONBool([]byte("x"), false))).Should(Equal("xfalse"))
+	})
+
+	It("encodes integers", func() {
+		for _, v := range []int64{0, 42, -7, 1 << 62} {
+			Ω(string(goa.AppendJSONInt(nil, v))).Should(Equal(marshal(v)))
+		}
+	})
+
+	It("encodes numbers like encoding/json", func() {
+		for _, v := range []float64{0, 1.5, -3.25, 1e20, 1e21, 123456789, 1e-6, 1e-7, -3.25e-9, 2.5e300} {
+			Ω(string(goa.AppendJSONFloat(nil, v))).Should(Equal(marshal(v)))
+		}
+	})
+
+	It("encodes strings like encoding/json", func() {
+		for _, v := range []string{"", "foo", `a"b\\c`, "<a href='x'>&</a>", "tab\tline\nret\r", "\x01\x1f", "rosé", "\u2028\u2029", "bad\xffutf8"} {
+			Ω(string(goa.AppendJSONString(nil, v))).Should(Equal(marshal(v)))
+		}
+	})
+})
+
+var _ = Describe("PruneJSON", func() {
+	type bottle struct {
+		ID    int     `json:"id"`
+		Name  string  `json:"name,omitempty"`
+		Price float64 `json:"price"`
+	}
+
+	It("keeps the listed fields", func() {
+		b, err := goa.PruneJSON(&bottle{ID: 1, Name: "Muscadet", Price: 9.5}, []string{"id", "price"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"id":1,"price":9.5}`))
+	})
+
+	It("prunes the elements of collections", func() {
+		bottles := []*bottle{{ID: 1, Name: "Muscadet"}, {ID: 2, Name: "Chablis"}}
+		b, err := goa.PruneJSON(bottles, []string{"name", "unknown"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`[{"name":"Muscadet"},{"name":"Chablis"}]`))
+	})
+
+	It("leaves other values untouched", func() {
+		b, err := goa.PruneJSON(42, []string{"id"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal("42"))
+	})
+})
@@ -0,0 +1,357 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	// JSONRPCMethod describes an action exposed by the JSON-RPC 2.0 endpoint mounted with
+	// MountJSONRPC. This type is intended for the generated code.
+	JSONRPCMethod struct {
+		// Name is the JSON-RPC method name, e.g. "bottle.show".
+		Name string
+		// Method is the HTTP method of the action route.
+		Method string
+		// Path is the action route path as given to the service mux, e.g.
+		// "/bottles/:id".
+		Path string
+		// Separators contains the separators of the array query string parameters whose
+		// values are joined in a single value indexed by parameter name.
+		Separators map[string]string
+	}
+
+	// JSONRPCRequest is a JSON-RPC 2.0 request object.
+	JSONRPCRequest struct {
+		// JSONRPC is the protocol version, always "2.0".
+		JSONRPC string `json:"jsonrpc"`
+		// Method is the name of the method to invoke.
+		Method string `json:"method"`
+		// Params contains the method parameters indexed by name.
+		Params json.RawMessage `json:"params,omitempty"`
+		// ID identifies the request, the request is a notification if it has no ID.
+		ID json.RawMessage `json:"id,omitempty"`
+	}
+
+	// JSONRPCResponse is a JSON-RPC 2.0 response object.
+	JSONRPCResponse struct {
+		// JSONRPC is the protocol version, always "2.0".
+		JSONRPC string `json:"jsonrpc"`
+		// Result is the method result, set only if the call succeeded.
+		Result json.RawMessage `json:"result,omitempty"`
+		// Error describes the error, set only if the call failed.
+		Error *JSONRPCError `json:"error,omitempty"`
+		// ID is the ID of the corresponding request.
+		ID json.RawMessage `json:"id"`
+	}
+
+	// JSONRPCError is a JSON-RPC 2.0 error object.
+	JSONRPCError struct {
+		// Code is the error code, see the JSONRPC error code constants.
+		Code int `json:"code"`
+		// Message is a short description of the error.
+		Message string `json:"message"`
+		// Data is the body of the action error response if any.
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	// responseRecorder records the response written by an action handler invoked by the
+	// JSON-RPC endpoint, by the event consumers or by the batch endpoints.
+	responseRecorder struct {
+		header http.Header
+		status int
+		body   bytes.Buffer
+	}
+)
+
+// The JSON-RPC 2.0 error codes. The errors returned by the actions are mapped to
+// JSONRPCInvalidParams if their status is 400, to JSONRPCInternalError if their status is 500 and
+// to JSONRPCServerError otherwise.
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCInvalidRequest = -32600
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInvalidParams  = -32602
+	JSONRPCInternalError  = -32603
+	JSONRPCServerError    = -32000
+)
+
+// MountJSONRPC mounts a JSON-RPC 2.0 endpoint on the service mux that handles POST requests
+// sent to path. The endpoint dispatches each call to the handler mounted on the mux for the
+// route of the method with the same name, batch requests are supported. The named parameters
+// of a call are given to the handler as path and query string parameters except for "payload"
+// which is used as request body. The headers of the endpoint request are given to all the
+// handlers so that the security schemes apply. The body of a successful response becomes the
+// call result, the body of an error response the error data.
+// The length of the endpoint request bodies is limited by the MaxRequestBodyLength field of the
+// returned controller, requests with longer bodies get a JSONRPCInvalidRequest error.
+// This function is intended for the generated code, see the JSONRPC DSL.
+func (service *Service) MountJSONRPC(path string, methods []*JSONRPCMethod) *Controller {
+	byName := make(map[string]*JSONRPCMethod, len(methods))
+	for _, m := range methods {
+		byName[m.Name] = m
+	}
+	ctrl := service.NewController("JSONRPC")
+	service.Mux.Handle("POST", path, func(rw http.ResponseWriter, req *http.Request, _ url.Values) {
+		if ctrl.MaxRequestBodyLength > 0 {
+			req.Body = http.MaxBytesReader(rw, req.Body, ctrl.MaxRequestBodyLength)
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
+				writeJSONRPC(rw, newJSONRPCError(nil, JSONRPCInvalidRequest, msg))
+				return
+			}
+			writeJSONRPC(rw, newJSONRPCError(nil, JSONRPCParseError, err.Error()))
+			return
+		}
+		body = bytes.TrimSpace(body)
+		if len(body) == 0 || body[0] != '[' {
+			if resp := service.serveJSONRPC(req, byName, body); resp != nil {
+				writeJSONRPC(rw, resp)
+				return
+			}
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeJSONRPC(rw, newJSONRPCError(nil, JSONRPCParseError, err.Error()))
+			return
+		}
+		if len(batch) == 0 {
+			writeJSONRPC(rw, newJSONRPCError(nil, JSONRPCInvalidRequest, "empty batch"))
+			return
+		}
+		var resps []*JSONRPCResponse
+		for _, r := range batch {
+			if resp := service.serveJSONRPC(req, byName, r); resp != nil {
+				resps = append(resps, resp)
+			}
+		}
+		if len(resps) == 0 {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSONRPC(rw, resps)
+	})
+	return ctrl
+}
+
+// Error returns the error message.
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+// serveJSONRPC invokes the handler of the method called by the given request object and returns
+// the corresponding response, nil if the request is a notification.
+func (service *Service) serveJSONRPC(req *http.Request, methods map[string]*JSONRPCMethod, raw json.RawMessage) *JSONRPCResponse {
+	var r JSONRPCRequest
+	if err := json.Unmarshal(raw, &r); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return newJSONRPCError(nil, JSONRPCParseError, err.Error())
+		}
+		return newJSONRPCError(nil, JSONRPCInvalidRequest, err.Error())
+	}
+	if r.JSONRPC != "2.0" || r.Method == "" {
+		return newJSONRPCError(r.ID, JSONRPCInvalidRequest, "invalid JSON-RPC 2.0 request")
+	}
+	resp := service.callJSONRPC(req, methods, &r)
+	if r.ID == nil {
+		return nil
+	}
+	return resp
+}
+
+// callJSONRPC invokes the handler of the method called by r.
+func (service *Service) callJSONRPC(req *http.Request, methods map[string]*JSONRPCMethod, r *JSONRPCRequest) *JSONRPCResponse {
+	m, ok := methods[r.Method]
+	if !ok {
+		return newJSONRPCError(r.ID, JSONRPCMethodNotFound, fmt.Sprintf("unknown method %#v", r.Method))
+	}
+	handle := service.Mux.Lookup(m.Method, m.Path)
+	if handle == nil {
+		return newJSONRPCError(r.ID, JSONRPCMethodNotFound, fmt.Sprintf("method %#v is not mounted", r.Method))
+	}
+	params, payload, err := jsonrpcParams(r.Params, m.Separators)
+	if err != nil {
+		return newJSONRPCError(r.ID, JSONRPCInvalidParams, err.Error())
+	}
+	path, query, err := jsonrpcPath(m.Path, params)
+	if err != nil {
+		return newJSONRPCError(r.ID, JSONRPCInvalidParams, err.Error())
+	}
+	u := *req.URL
+	u.Path = path
+	u.RawPath = ""
+	u.RawQuery = query.Encode()
+	hreq, err := http.NewRequest(m.Method, u.String(), bytes.NewReader(payload))
+	if err != nil {
+		return newJSONRPCError(r.ID, JSONRPCInvalidParams, err.Error())
+	}
+	hreq = hreq.WithContext(req.Context())
+	for k, v := range req.Header {
+		hreq.Header[k] = v
+	}
+	hreq.Header.Del("Content-Length")
+	if payload != nil {
+		hreq.Header.Set("Content-Type", "application/json")
+	}
+	hreq.RemoteAddr = req.RemoteAddr
+	rw := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	handle(rw, hreq, params)
+
+	result := bytes.TrimSpace(rw.body.Bytes())
+	if len(result) > 0 && !json.Valid(result) {
+		result, _ = json.Marshal(string(result))
+	}
+	if rw.status < 400 {
+		if len(result) == 0 {
+			result = []byte("null")
+		}
+		return &JSONRPCResponse{JSONRPC: "2.0", Result: result, ID: r.ID}
+	}
+	code := JSONRPCServerError
+	switch rw.status {
+	case http.StatusBadRequest:
+		code = JSONRPCInvalidParams
+	case http.StatusInternalServerError:
+		code = JSONRPCInternalError
+	}
+	msg := http.StatusText(rw.status)
+	var e ErrorResponse
+	if err := json.Unmarshal(result, &e); err == nil && e.Detail != "" {
+		msg = e.Detail
+	}
+	resp := newJSONRPCError(r.ID, code, msg)
+	if len(result) > 0 {
+		resp.Error.Data = result
+	}
+	return resp
+}
+
+// jsonrpcParams returns the path and query string parameter values and the payload of the call
+// with the given params object.
+func jsonrpcParams(raw json.RawMessage, separators map[string]string) (url.Values, []byte, error) {
+	values := make(url.Values)
+	if len(raw) == 0 || string(raw) == "null" {
+		return values, nil, nil
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, nil, fmt.Errorf("params must be an object")
+	}
+	var payload []byte
+	for n, p := range params {
+		if n == "payload" {
+			if string(p) != "null" {
+				payload = p
+			}
+			continue
+		}
+		vals, err := jsonrpcValues(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for parameter %#v: %s", n, err)
+		}
+		if sep, ok := separators[n]; ok && len(vals) > 0 {
+			vals = []string{strings.Join(vals, sep)}
+		}
+		if len(vals) > 0 {
+			values[n] = vals
+		}
+	}
+	return values, payload, nil
+}
+
+// jsonrpcValues returns the string values of the given JSON parameter value, arrays produce one
+// value per element.
+func jsonrpcValues(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	elems, ok := v.([]interface{})
+	if !ok {
+		elems = []interface{}{v}
+	}
+	vals := make([]string, 0, len(elems))
+	for _, e := range elems {
+		switch actual := e.(type) {
+		case nil:
+		case string:
+			vals = append(vals, actual)
+		case json.Number:
+			vals = append(vals, actual.String())
+		case bool:
+			vals = append(vals, fmt.Sprint(actual))
+		default:
+			return nil, fmt.Errorf("must be a string, number, boolean or array")
+		}
+	}
+	return vals, nil
+}
+
+// jsonrpcPath returns the request path obtained by replacing the wildcards of the route path with
+// the corresponding parameter values and the remaining query string parameters.
+func jsonrpcPath(route string, params url.Values) (string, url.Values, error) {
+	query := make(url.Values, len(params))
+	for n, v := range params {
+		query[n] = v
+	}
+	elems := strings.Split(route, "/")
+	for i, e := range elems {
+		if !strings.HasPrefix(e, ":") && !strings.HasPrefix(e, "*") {
+			continue
+		}
+		n := e[1:]
+		v := query.Get(n)
+		if v == "" {
+			return "", nil, fmt.Errorf("missing required parameter %#v", n)
+		}
+		if e[0] == ':' {
+			v = url.PathEscape(v)
+		}
+		elems[i] = v
+		delete(query, n)
+	}
+	return strings.Join(elems, "/"), query, nil
+}
+
+// newJSONRPCError creates an error response.
+func newJSONRPCError(id json.RawMessage, code int, msg string) *JSONRPCResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &JSONRPCResponse{JSONRPC: "2.0", Error: &JSONRPCError{Code: code, Message: msg}, ID: id}
+}
+
+// writeJSONRPC writes the given response or batch of responses.
+func writeJSONRPC(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+// Header returns the response headers.
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+// Write records the response body.
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// WriteHeader records the response status.
+func (w *responseRecorder) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,194 @@
+package goa_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MountJSONRPC", func() {
+	var s *goa.Service
+	var rpc *goa.Controller
+	var body string
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Decoder.Register(goa.NewJSONDecoder, "*/*")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		ctrl := s.NewController("bottle")
+		show := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			params := goa.ContextRequest(ctx).Params
+			if params.Get("id") == "0" {
+				return s.Send(ctx, 404, goa.ErrNotFound("no bottle"))
+			}
+			return s.Send(ctx, 200, map[string]interface{}{
+				"id":   params.Get("id"),
+				"tags": params.Get("tags"),
+				"auth": req.Header.Get("Authorization"),
+			})
+		}
+		create := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return s.Send(ctx, 201, goa.ContextRequest(ctx).Payload)
+		}
+		unm := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+			var payload map[string]interface{}
+			if err := service.DecodeRequest(req, &payload); err != nil {
+				return err
+			}
+			goa.ContextRequest(ctx).Payload = payload
+			return nil
+		}
+		s.Mux.Handle("GET", "/bottles/:id", ctrl.MuxHandler("show", show, nil))
+		s.Mux.Handle("POST", "/bottles", ctrl.MuxHandler("create", create, unm))
+		rpc = s.MountJSONRPC("/rpc", []*goa.JSONRPCMethod{
+			{Name: "bottle.show", Method: "GET", Path: "/bottles/:id", Separators: map[string]string{"tags": "|"}},
+			{Name: "bottle.create", Method: "POST", Path: "/bottles"},
+			{Name: "bottle.missing", Method: "GET", Path: "/missing"},
+		})
+	})
+
+	JustBeforeEach(func() {
+		req, err := http.NewRequest("POST", "/rpc", strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer token")
+		rw = httptest.NewRecorder()
+		s.Mux.ServeHTTP(rw, req)
+	})
+
+	decode := func(v interface{}) {
+		Ω(json.Unmarshal(rw.Body.Bytes(), v)).ShouldNot(HaveOccurred())
+	}
+
+	Context("with a call", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.show","params":{"id":42,"tags":["a","b"]},"id":1}`
+		})
+
+		It("dispatches the call to the action handler", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error).Should(BeNil())
+			Ω(string(resp.ID)).Should(Equal("1"))
+			Ω(string(resp.Result)).Should(MatchJSON(`{"id":"42","tags":"a|b","auth":"Bearer token"}`))
+		})
+	})
+
+	Context("with a call with a payload", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.create","params":{"payload":{"name":"foo"}},"id":"a"}`
+		})
+
+		It("uses the payload as request body", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error).Should(BeNil())
+			Ω(string(resp.Result)).Should(MatchJSON(`{"name":"foo"}`))
+		})
+	})
+
+	Context("with a call that fails", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.show","params":{"id":0},"id":1}`
+		})
+
+		It("returns the error response", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error).ShouldNot(BeNil())
+			Ω(resp.Error.Code).Should(Equal(goa.JSONRPCServerError))
+			Ω(resp.Error.Message).Should(Equal("no bottle"))
+			Ω(string(resp.Error.Data)).Should(ContainSubstring(`"status":404`))
+		})
+	})
+
+	Context("with a call to an unknown method", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.delete","id":1}`
+		})
+
+		It("returns a method not found error", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error.Code).Should(Equal(goa.JSONRPCMethodNotFound))
+		})
+	})
+
+	Context("with a call missing a path parameter", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.show","id":1}`
+		})
+
+		It("returns an invalid params error", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error.Code).Should(Equal(goa.JSONRPCInvalidParams))
+		})
+	})
+
+	Context("with a body exceeding the maximum length", func() {
+		BeforeEach(func() {
+			rpc.MaxRequestBodyLength = 16
+			body = `{"jsonrpc":"2.0","method":"bottle.show","params":{"id":42},"id":1}`
+		})
+
+		It("returns an invalid request error", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error.Code).Should(Equal(goa.JSONRPCInvalidRequest))
+			Ω(resp.Error.Message).Should(ContainSubstring("exceeds 16 bytes"))
+		})
+	})
+
+	Context("with invalid JSON", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":`
+		})
+
+		It("returns a parse error", func() {
+			var resp goa.JSONRPCResponse
+			decode(&resp)
+			Ω(resp.Error.Code).Should(Equal(goa.JSONRPCParseError))
+			Ω(string(resp.ID)).Should(Equal("null"))
+		})
+	})
+
+	Context("with a batch", func() {
+		BeforeEach(func() {
+			body = `[
+				{"jsonrpc":"2.0","method":"bottle.show","params":{"id":1},"id":1},
+				{"jsonrpc":"2.0","method":"bottle.show","params":{"id":2}},
+				{"jsonrpc":"2.0","method":"bottle.missing","id":3},
+				1
+			]`
+		})
+
+		It("returns the responses of the calls that are not notifications", func() {
+			var resps []*goa.JSONRPCResponse
+			decode(&resps)
+			Ω(resps).Should(HaveLen(3))
+			Ω(string(resps[0].ID)).Should(Equal("1"))
+			Ω(resps[0].Error).Should(BeNil())
+			Ω(string(resps[1].ID)).Should(Equal("3"))
+			Ω(resps[1].Error.Code).Should(Equal(goa.JSONRPCMethodNotFound))
+			Ω(resps[2].Error.Code).Should(Equal(goa.JSONRPCInvalidRequest))
+		})
+	})
+
+	Context("with a notification", func() {
+		BeforeEach(func() {
+			body = `{"jsonrpc":"2.0","method":"bottle.show","params":{"id":1}}`
+		})
+
+		It("does not return a response", func() {
+			Ω(rw.Code).Should(Equal(http.StatusNoContent))
+			Ω(rw.Body.Len()).Should(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+package goa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type (
+	// JSONLimits describes the maximum sizes of the collections of a JSON document. The code
+	// generated for payloads with arrays or maps that define a maximum length uses it to reject
+	// oversized requests while the body is decoded rather than once the payload is in memory.
+	JSONLimits struct {
+		// MaxLength is the maximum number of elements of an array or of entries of a map.
+		// Zero means no limit.
+		MaxLength int
+		// Elem limits the elements of an array or the values of a map.
+		Elem *JSONLimits
+		// Fields limits the fields of an object by JSON key.
+		Fields map[string]*JSONLimits
+	}
+
+	// limitedJSONReader is a reader that scans the JSON document read from the underlying
+	// reader and fails as soon as a collection exceeds its maximum length.
+	limitedJSONReader struct {
+		r      io.Reader
+		stack  []*jsonFrame
+		next   *JSONLimits // limits of the next value
+		str    bool        // true when scanning a string
+		esc    bool        // true when the previous string character is a backslash
+		key    []byte      // raw object key being scanned, nil if not scanning a key
+		keyLen int         // length of the raw object key being scanned
+		err    error
+	}
+
+	// jsonFrame is an array or object being scanned.
+	jsonFrame struct {
+		limits   *JSONLimits
+		array    bool
+		count    int    // number of elements or keys read so far
+		key      string // last object key
+		wantElem bool   // true if an array element may start at the next non-space character
+		wantKey  bool   // true if an object key may start at the next non-space character
+	}
+)
+
+// maxKeyLength is the maximum number of raw bytes of the object keys considered while looking up
+// the field limits. JSON keys longer than that cannot match any field.
+const maxKeyLength = 4096
+
+// LimitJSON returns a reader that reads the JSON document from r and returns an error as soon as
+// an array or a map exceeds the maximum length given by limits.
+func LimitJSON(r io.Reader, limits *JSONLimits) io.Reader {
+	return &limitedJSONReader{r: r, next: limits}
+}
+
+// Read reads from the underlying reader and scans the bytes read.
+func (l *limitedJSONReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	for i := 0; i < n; i++ {
+		if l.err = l.scan(p[i]); l.err != nil {
+			return i, l.err
+		}
+	}
+	return n, err
+}
+
+// scan updates the scanner state with the next byte of the document.
+func (l *limitedJSONReader) scan(c byte) error {
+	if l.str {
+		switch {
+		case l.esc:
+			l.esc = false
+		case c == '\\':
+			l.esc = true
+		case c == '"':
+			l.str = false
+			if l.key != nil {
+				l.endKey()
+			}
+			return nil
+		}
+		if l.key != nil {
+			l.keyLen++
+			if l.keyLen <= maxKeyLength {
+				l.key = append(l.key, c)
+			}
+		}
+		return nil
+	}
+	var top *jsonFrame
+	if len(l.stack) > 0 {
+		top = l.stack[len(l.stack)-1]
+	}
+	switch c {
+	case ' ', '\t', '\n', '\r':
+		return nil
+	case ':':
+		l.next = top.child()
+		return nil
+	case ',':
+		if top != nil {
+			top.wantElem = top.array
+			top.wantKey = !top.array
+		}
+		return nil
+	case ']', '}':
+		if len(l.stack) > 0 {
+			l.stack = l.stack[:len(l.stack)-1]
+		}
+		return nil
+	}
+	if top != nil && top.wantKey {
+		top.wantKey = false
+		top.count++
+		if err := l.check(top); err != nil {
+			return err
+		}
+		l.str = true
+		l.key = []byte{'"'}
+		l.keyLen = 1
+		return nil
+	}
+	if top != nil && top.array {
+		if !top.wantElem {
+			return nil
+		}
+		top.wantElem = false
+		top.count++
+		if err := l.check(top); err != nil {
+			return err
+		}
+		l.next = top.limits.elem()
+	}
+	limits := l.next
+	l.next = nil
+	switch c {
+	case '"':
+		l.str = true
+	case '[':
+		l.stack = append(l.stack, &jsonFrame{limits: limits, array: true, wantElem: true})
+	case '{':
+		l.stack = append(l.stack, &jsonFrame{limits: limits, wantKey: true})
+	}
+	return nil
+}
+
+// endKey records the object key that was just scanned.
+func (l *limitedJSONReader) endKey() {
+	top := l.stack[len(l.stack)-1]
+	top.key = ""
+	if l.keyLen <= maxKeyLength {
+		var key string
+		if err := json.Unmarshal(append(l.key, '"'), &key); err == nil {
+			top.key = key
+		}
+	}
+	l.key = nil
+}
+
+// check returns an error if the collection being scanned exceeds its maximum length.
+func (l *limitedJSONReader) check(f *jsonFrame) error {
+	if f.limits == nil || f.limits.MaxLength <= 0 || f.count <= f.limits.MaxLength {
+		return nil
+	}
+	return fmt.Errorf("%s has more than %d elements", l.path(), f.limits.MaxLength)
+}
+
+// path returns the path to the value being scanned used in error messages.
+func (l *limitedJSONReader) path() string {
+	path := "body"
+	for i, f := range l.stack {
+		if i == len(l.stack)-1 {
+			break
+		}
+		if f.array {
+			path += fmt.Sprintf("[%d]", f.count-1)
+		} else {
+			path += "." + f.key
+		}
+	}
+	return path
+}
+
+// child returns the limits of the value of the last key of the object.
+func (f *jsonFrame) child() *JSONLimits {
+	if f == nil || f.array || f.limits == nil {
+		return nil
+	}
+	if f.limits.Fields == nil {
+		return f.limits.Elem
+	}
+	if l, ok := f.limits.Fields[f.key]; ok {
+		return l
+	}
+	// encoding/json matches the struct fields case-insensitively
+	for k, l := range f.limits.Fields {
+		if strings.EqualFold(k, f.key) {
+			return l
+		}
+	}
+	return nil
+}
+
+// elem returns the limits of the array elements or map values.
+func (l *JSONLimits) elem() *JSONLimits {
+	if l == nil {
+		return nil
+	}
+	return l.Elem
+}
@@ -0,0 +1,152 @@
+package goa_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LimitJSON", func() {
+	var limits *goa.JSONLimits
+	var doc string
+
+	var err error
+	var decoded interface{}
+
+	BeforeEach(func() {
+		limits = nil
+		doc = ""
+		decoded = nil
+	})
+
+	JustBeforeEach(func() {
+		err = json.NewDecoder(goa.LimitJSON(strings.NewReader(doc), limits)).Decode(&decoded)
+	})
+
+	Context("with an array within its limit", func() {
+		BeforeEach(func() {
+			limits = &goa.JSONLimits{MaxLength: 4}
+			doc = `[1, "a,b", [4, 5, 6, 7, 8], {"x": [1, 2, 3, 4, 5]}]`
+		})
+
+		It("decodes the document", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(HaveLen(4))
+		})
+	})
+
+	Context("with an oversized array", func() {
+		BeforeEach(func() {
+			limits = &goa.JSONLimits{MaxLength: 2}
+			doc = `[1, 2, 3]`
+		})
+
+		It("fails", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(Equal("body has more than 2 elements"))
+		})
+	})
+
+	Context("with an empty array", func() {
+		BeforeEach(func() {
+			limits = &goa.JSONLimits{MaxLength: 1}
+			doc = `[ ]`
+		})
+
+		It("decodes the document", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("with nested limits", func() {
+		BeforeEach(func() {
+			limits = &goa.JSONLimits{Fields: map[string]*goa.JSONLimits{
+				"items": {Elem: &goa.JSONLimits{Fields: map[string]*goa.JSONLimits{
+					"tags": {MaxLength: 2},
+				}}},
+			}}
+		})
+
+		Context("within the limits", func() {
+			BeforeEach(func() {
+				doc = `{"tags": [1, 2, 3], "items": [{"name": "tags][,\"", "tags": ["a", "b"]}, {"tags": []}]}`
+			})
+
+			It("decodes the document", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("with an oversized nested array", func() {
+			BeforeEach(func() {
+				doc = `{"items": [{"tags": ["a"]}, {"other": {"tags": [1, 2, 3]}, "tags": ["a", "b", "c"]}]}`
+			})
+
+			It("fails with the path to the array", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(Equal("body.items[1].tags has more than 2 elements"))
+			})
+		})
+
+		Context("with keys that differ in case", func() {
+			BeforeEach(func() {
+				doc = `{"ITEMS": [{"tags": ["a", "b", "c"]}]}`
+			})
+
+			It("matches the fields like encoding/json", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(Equal("body.ITEMS[0].tags has more than 2 elements"))
+			})
+		})
+
+		Context("with escaped keys", func() {
+			BeforeEach(func() {
+				doc = `{"\u0069tems": [{"t\u0061gs": ["a", "b", "c"]}]}`
+			})
+
+			It("unescapes the keys", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(Equal("body.items[0].tags has more than 2 elements"))
+			})
+		})
+	})
+
+	Context("with a map limit", func() {
+		BeforeEach(func() {
+			limits = &goa.JSONLimits{MaxLength: 2, Elem: &goa.JSONLimits{MaxLength: 1}}
+		})
+
+		Context("with too many entries", func() {
+			BeforeEach(func() {
+				doc = `{"a": [1], "b": [], "c": [1]}`
+			})
+
+			It("fails", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(Equal("body has more than 2 elements"))
+			})
+		})
+
+		Context("with an oversized value", func() {
+			BeforeEach(func() {
+				doc = `{"a": [1, 2]}`
+			})
+
+			It("fails", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(Equal("body.a has more than 1 elements"))
+			})
+		})
+	})
+
+	It("stops reading as soon as the limit is exceeded", func() {
+		r := &countingReader{r: strings.NewReader("[" + strings.Repeat("1,", 1<<20) + "1]")}
+		_, err := ioutil.ReadAll(goa.LimitJSON(r, &goa.JSONLimits{MaxLength: 10}))
+		Ω(err).Should(HaveOccurred())
+		Ω(r.n).Should(BeNumerically("<", 4096))
+	})
+})
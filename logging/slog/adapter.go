@@ -0,0 +1,76 @@
+/*
+Package goaslog contains an adapter that makes it possible to configure goa so it uses the log/slog
+structured logger of the standard library as logger backend.
+Usage:
+
+    logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+    // Initialize logger handler using slog package
+    service.WithLogger(goaslog.New(logger))
+    // ... Proceed with configuring and starting the goa service
+
+    // In handlers:
+    goaslog.Logger(ctx).Info("foo", "bar", "baz")
+*/
+package goaslog
+
+import (
+	"fmt"
+	"log/slog"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// adapter is the slog goa logger adapter.
+type adapter struct {
+	*slog.Logger
+}
+
+// New wraps a slog logger into a goa logger.
+func New(logger *slog.Logger) goa.LogAdapter {
+	return &adapter{Logger: logger}
+}
+
+// Logger returns the slog logger stored in the given context if any, nil otherwise.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := goa.ContextLogger(ctx)
+	if a, ok := logger.(*adapter); ok {
+		return a.Logger
+	}
+	return nil
+}
+
+// Info logs informational messages using slog.
+func (a *adapter) Info(msg string, data ...interface{}) {
+	a.Logger.Info(msg, fixData(data)...)
+}
+
+// Error logs errors using slog.
+func (a *adapter) Error(msg string, data ...interface{}) {
+	a.Logger.Error(msg, fixData(data)...)
+}
+
+// New creates a new logger given a context.
+func (a *adapter) New(data ...interface{}) goa.LogAdapter {
+	return &adapter{Logger: a.Logger.With(fixData(data)...)}
+}
+
+// fixData returns the slog arguments for the given goa key/value pairs. Keys that are not strings
+// are formatted and missing values are logged as goa.ErrMissingLogValue so that slog does not
+// log them under its !BADKEY key.
+func fixData(keyvals []interface{}) []interface{} {
+	args := make([]interface{}, 0, len(keyvals)+1)
+	for i := 0; i < len(keyvals); i += 2 {
+		k, ok := keyvals[i].(string)
+		if !ok {
+			k = fmt.Sprintf("%v", keyvals[i])
+		}
+		var v interface{} = goa.ErrMissingLogValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		args = append(args, k, v)
+	}
+	return args
+}
@@ -0,0 +1,52 @@
+package goaslog_test
+
+import (
+	"bytes"
+	"log/slog"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/logging/slog"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("goaslog", func() {
+	var logger *slog.Logger
+	var adapter goa.LogAdapter
+	var buf bytes.Buffer
+
+	BeforeEach(func() {
+		buf.Reset()
+		logger = slog.New(slog.NewTextHandler(&buf, nil))
+		adapter = goaslog.New(logger)
+	})
+
+	It("adapts info messages", func() {
+		adapter.Info("msg", "status", 200, "odd")
+		Ω(buf.String()).Should(ContainSubstring("level=INFO msg=msg status=200 odd=MISSING"))
+	})
+
+	It("adapts error messages", func() {
+		adapter.Error("failed", 42, "answer")
+		Ω(buf.String()).Should(ContainSubstring("level=ERROR msg=failed 42=answer"))
+	})
+
+	It("adds the context to the logger", func() {
+		adapter.New("req_id", "abc").Info("msg")
+		Ω(buf.String()).Should(ContainSubstring("msg=msg req_id=abc"))
+	})
+
+	Context("Logger", func() {
+		var ctx context.Context
+
+		BeforeEach(func() {
+			ctx = goa.WithLogger(context.Background(), adapter)
+		})
+
+		It("extracts the slog logger", func() {
+			Ω(goaslog.Logger(ctx)).Should(Equal(logger))
+		})
+	})
+})
@@ -0,0 +1,219 @@
+package goa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// MessageCatalog provides the translations of the messages of the errors created by the
+	// goa helper functions such as MissingAttributeError or InvalidLengthError. The
+	// translations are format strings that accept the same arguments as the corresponding
+	// DefaultMessages entries, explicit argument indexes (e.g. %[2]s) make it possible to
+	// reorder them.
+	MessageCatalog interface {
+		// Message returns the format string of the message with the given ID in the
+		// language with the given tag, e.g. "fr-CA", false if there is no translation.
+		Message(lang, id string) (string, bool)
+	}
+
+	// Catalog is a MessageCatalog that stores the format strings indexed by language tag and
+	// by message ID. A language tag with a region (e.g. "fr-CA") falls back to the primary
+	// language ("fr").
+	Catalog map[string]map[string]string
+
+	// message is an error message that can be translated.
+	message struct {
+		// id is the message ID, empty if the message cannot be translated.
+		id string
+		// args are the message format arguments.
+		args []interface{}
+		// detail is the message in English.
+		detail string
+	}
+)
+
+// The IDs of the messages of the errors created by the goa helper functions.
+const (
+	MsgMissingPayload           = "missing_payload"
+	MsgInvalidParamType         = "invalid_param_type"
+	MsgMissingParam             = "missing_param"
+	MsgInvalidAttributeType     = "invalid_attribute_type"
+	MsgMissingAttribute         = "missing_attribute"
+	MsgRequiredTogether         = "required_together"
+	MsgMutuallyExclusive        = "mutually_exclusive"
+	MsgAtLeastOneOf             = "at_least_one_of"
+	MsgMissingHeader            = "missing_header"
+	MsgInvalidEnumValue         = "invalid_enum_value"
+	MsgInvalidFormat            = "invalid_format"
+	MsgInvalidPattern           = "invalid_pattern"
+	MsgInvalidRangeMin          = "invalid_range_min"
+	MsgInvalidRangeMax          = "invalid_range_max"
+	MsgInvalidExclusiveRangeMin = "invalid_exclusive_range_min"
+	MsgInvalidExclusiveRangeMax = "invalid_exclusive_range_max"
+	MsgInvalidMultipleOf        = "invalid_multiple_of"
+	MsgInvalidLengthMin         = "invalid_length_min"
+	MsgInvalidLengthMax         = "invalid_length_max"
+	MsgNoAuthMiddleware         = "no_auth_middleware"
+	MsgMethodNotAllowed         = "method_not_allowed"
+	MsgMethodNotAllowedMultiple = "method_not_allowed_multiple"
+	MsgInvalidBodyType          = "invalid_body_type"
+	MsgInvalidBodySyntax        = "invalid_body_syntax"
+	MsgInvalidBody              = "invalid_body"
+)
+
+// DefaultMessages contains the English format strings of the error messages indexed by message
+// ID. The comments list the format arguments.
+var DefaultMessages = map[string]string{
+	MsgMissingPayload:           "missing required payload",
+	MsgInvalidParamType:         "invalid value %#v for parameter %#v, must be a %s",                           // value, param, type
+	MsgMissingParam:             "missing required parameter %#v",                                              // param
+	MsgInvalidAttributeType:     "type of %s must be %s but got value %#v",                                     // attribute, type, value
+	MsgMissingAttribute:         "attribute %#v of %s is missing and required",                                 // name, parent
+	MsgRequiredTogether:         "attributes %s of %s must be either all set or all missing",                   // names, parent
+	MsgMutuallyExclusive:        "at most one of the attributes %s of %s may be set",                           // names, parent
+	MsgAtLeastOneOf:             "at least one of the attributes %s of %s is required",                         // names, parent
+	MsgMissingHeader:            "missing required HTTP header %#v",                                            // header
+	MsgInvalidEnumValue:         "value of %s must be one of %s but got value %#v",                             // attribute, values, value
+	MsgInvalidFormat:            "%s must be formatted as a %s but got value %#v, %s",                          // attribute, format, value, error
+	MsgInvalidPattern:           "%s must match the regexp %#v but got value %#v",                              // attribute, regexp, value
+	MsgInvalidRangeMin:          "%s must be greater than or equal to %v but got value %#v",                    // attribute, min, value
+	MsgInvalidRangeMax:          "%s must be less than or equal to %v but got value %#v",                       // attribute, max, value
+	MsgInvalidExclusiveRangeMin: "%s must be greater than %v but got value %#v",                                // attribute, min, value
+	MsgInvalidExclusiveRangeMax: "%s must be less than %v but got value %#v",                                   // attribute, max, value
+	MsgInvalidMultipleOf:        "%s must be a multiple of %v but got value %#v",                               // attribute, multiple, value
+	MsgInvalidLengthMin:         "length of %s must be greater than or equal to %d but got value %#v (len=%d)", // attribute, min, value, length
+	MsgInvalidLengthMax:         "length of %s must be less than or equal to %d but got value %#v (len=%d)",    // attribute, max, value, length
+	MsgNoAuthMiddleware:         "Auth middleware for security scheme %s is not mounted",                       // scheme
+	MsgMethodNotAllowed:         "Method %s must be %s",                                                        // method, allowed method
+	MsgMethodNotAllowedMultiple: "Method %s must be one of %s",                                                 // method, allowed methods
+	MsgInvalidBodyType:          "type of %s must be %s but got %s",                                            // attribute, type, value
+	MsgInvalidBodySyntax:        "failed to decode request body with content type %#v: %s at offset %d",        // content type, error, offset
+	MsgInvalidBody:              "failed to decode request body with content type %#v: %s",                     // content type, error
+}
+
+// Message implements MessageCatalog.
+func (c Catalog) Message(lang, id string) (string, bool) {
+	if msgs, ok := c[lang]; ok {
+		if f, ok := msgs[id]; ok {
+			return f, true
+		}
+	}
+	if i := strings.Index(lang, "-"); i > 0 {
+		return c.Message(lang[:i], id)
+	}
+	return "", false
+}
+
+// Localize returns a copy of the error with the detail and the field errors translated in the
+// first of the given languages supported by cat. It returns the error itself if none of the
+// languages is supported or if the error was not created by the goa helper functions.
+func (e *ErrorResponse) Localize(cat MessageCatalog, langs ...string) *ErrorResponse {
+	lang := e.language(cat, langs)
+	if lang == "" {
+		return e
+	}
+	le := *e
+	details := make([]string, len(e.messages))
+	for i, m := range e.messages {
+		details[i] = m.format(cat, lang)
+	}
+	le.Detail = strings.Join(details, "; ")
+	if len(e.Fields) > 0 {
+		le.Fields = make([]*FieldError, len(e.Fields))
+		for i, f := range e.Fields {
+			lf := *f
+			if f.message != nil {
+				lf.Detail = f.message.format(cat, lang)
+			}
+			le.Fields[i] = &lf
+		}
+	}
+	return &le
+}
+
+// language returns the first of the given languages that cat translates the error messages in.
+func (e *ErrorResponse) language(cat MessageCatalog, langs []string) string {
+	for _, lang := range langs {
+		for _, m := range e.messages {
+			if m.id == "" {
+				continue
+			}
+			if _, ok := cat.Message(lang, m.id); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// AcceptedLanguages returns the language tags listed in the given Accept-Language header value
+// sorted by decreasing quality. The wildcard and the tags with a quality of zero are omitted.
+func AcceptedLanguages(header string) []string {
+	type tag struct {
+		name string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		elems := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.TrimSpace(elems[0])
+		if name == "" || name == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range elems[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, tag{name, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	langs := make([]string, len(tags))
+	for i, t := range tags {
+		langs[i] = t.name
+	}
+	return langs
+}
+
+// newMessage creates the message with the given ID and format arguments.
+func newMessage(id string, args ...interface{}) *message {
+	return &message{id: id, args: args, detail: fmt.Sprintf(DefaultMessages[id], args...)}
+}
+
+// format returns the message in the given language, in English if cat does not translate it.
+func (m *message) format(cat MessageCatalog, lang string) string {
+	if m.id == "" {
+		return m.detail
+	}
+	if f, ok := cat.Message(lang, m.id); ok {
+		return fmt.Sprintf(f, m.args...)
+	}
+	return m.detail
+}
+
+// withMessage records the message of err if it was created by an error class so that it can be
+// translated, it returns err unchanged otherwise.
+func withMessage(err error, m *message) error {
+	if e, ok := err.(*ErrorResponse); ok {
+		e.messages = []*message{m}
+	}
+	return err
+}
+
+// detailMessages returns the messages that make up the error detail, the detail of an error that
+// was not created by the goa helper functions is kept as is.
+func (e *ErrorResponse) detailMessages() []*message {
+	if len(e.messages) == 0 {
+		return []*message{{detail: e.Detail}}
+	}
+	return e.messages
+}
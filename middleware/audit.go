@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"context"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/jwt"
+)
+
+type (
+	// AuditEvent describes a call to an audited action.
+	AuditEvent struct {
+		// Ctrl is the name of the controller.
+		Ctrl string
+		// Action is the name of the action.
+		Action string
+		// Actor identifies the caller: the subject of the JWT claims or the basic auth user
+		// name, empty if the request is not authenticated.
+		Actor string
+		// PayloadDigest is the hex encoded SHA-256 digest of the JSON representation of
+		// the request payload, empty if the request has no payload.
+		PayloadDigest string
+		// Status is the response status code.
+		Status int
+		// Err is the error returned by the action if any.
+		Err error
+		// StartedAt is the time the request handling started.
+		StartedAt time.Time
+		// Duration is the time it took to handle the request.
+		Duration time.Duration
+	}
+
+	// AuditSink receives the audit events emitted by the Audit middleware.
+	AuditSink interface {
+		// Audit is called once the request to an audited action has been handled.
+		Audit(ctx context.Context, event *AuditEvent)
+	}
+)
+
+// DefaultAuditSink is the sink used by the code generated for the audited actions, see the Audit
+// DSL. It must be set before the controllers are mounted, no audit event is emitted if nil.
+var DefaultAuditSink AuditSink
+
+// Audit creates a middleware that sends an audit event to sink once each request has been
+// handled. The middleware must run after the security middleware so that the caller identity is
+// available. It does nothing if sink is nil.
+func Audit(sink AuditSink) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		if sink == nil {
+			return h
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+			sink.Audit(ctx, &AuditEvent{
+				Ctrl:          goa.ContextController(ctx),
+				Action:        goa.ContextAction(ctx),
+				Actor:         auditActor(ctx, req),
+				PayloadDigest: payloadDigest(goa.ContextRequest(ctx)),
+				Status:        responseStatus(ctx, err),
+				Err:           err,
+				StartedAt:     startedAt,
+				Duration:      time.Since(startedAt),
+			})
+			return err
+		}
+	}
+}
+
+// auditActor returns the subject of the JWT claims or the basic auth user name of the request.
+func auditActor(ctx context.Context, req *http.Request) string {
+	if token := jwt.ContextJWT(ctx); token != nil {
+		switch claims := token.Claims.(type) {
+		case jwtgo.MapClaims:
+			if sub, ok := claims["sub"].(string); ok {
+				return sub
+			}
+		case *jwtgo.StandardClaims:
+			return claims.Subject
+		}
+	}
+	if user, _, ok := req.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
+
+// payloadDigest returns the hex encoded SHA-256 digest of the JSON representation of the request
+// payload, the empty string if there is none.
+func payloadDigest(r *goa.RequestData) string {
+	if r == nil || r.Payload == nil {
+		return ""
+	}
+	b, err := json.Marshal(r.Payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"context"
+
+	jwtgo "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	"github.com/goadesign/goa/middleware/security/jwt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testAuditSink struct {
+	Events []*middleware.AuditEvent
+}
+
+func (s *testAuditSink) Audit(ctx context.Context, event *middleware.AuditEvent) {
+	s.Events = append(s.Events, event)
+}
+
+var _ = Describe("Audit", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var service *goa.Service
+	var sink *testAuditSink
+
+	BeforeEach(func() {
+		service = newService(nil)
+		var err error
+		req, err = http.NewRequest("DELETE", "/bottles/1", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		sink = &testAuditSink{}
+	})
+
+	It("sends the outcome of the calls to the sink", func() {
+		req.SetBasicAuth("alice", "secret")
+		goa.ContextRequest(ctx).Payload = map[string]int{"id": 1}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 204, nil)
+		}
+		Ω(middleware.Audit(sink)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(sink.Events).Should(HaveLen(1))
+		e := sink.Events[0]
+		Ω(e.Ctrl).Should(Equal("test"))
+		Ω(e.Actor).Should(Equal("alice"))
+		sum := sha256.Sum256([]byte(`{"id":1}`))
+		Ω(e.PayloadDigest).Should(Equal(hex.EncodeToString(sum[:])))
+		Ω(e.Status).Should(Equal(204))
+		Ω(e.Err).ShouldNot(HaveOccurred())
+	})
+
+	It("reports the errors and the subject of the JWT claims", func() {
+		token := &jwtgo.Token{Claims: jwtgo.MapClaims{"sub": "bob"}}
+		ctx = jwt.WithJWT(ctx, token)
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.ErrNotFound("no bottle")
+		}
+		Ω(middleware.Audit(sink)(h)(ctx, rw, req)).Should(HaveOccurred())
+		e := sink.Events[0]
+		Ω(e.Actor).Should(Equal("bob"))
+		Ω(e.PayloadDigest).Should(BeEmpty())
+		Ω(e.Status).Should(Equal(404))
+		Ω(e.Err).Should(HaveOccurred())
+	})
+
+	It("does nothing without a sink", func() {
+		called := false
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		Ω(middleware.Audit(nil)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
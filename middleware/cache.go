@@ -0,0 +1,218 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// CacheStore stores the responses of the actions wrapped by the Cache middleware.
+	// Implementations must be safe for concurrent use.
+	CacheStore interface {
+		// Get returns the response stored under key, false if there is none or if it
+		// has expired.
+		Get(key string) (*CachedResponse, bool)
+		// Set stores the response under key for the duration given by ttl.
+		Set(key string, resp *CachedResponse, ttl time.Duration)
+	}
+
+	// CachedResponse is a response stored by the Cache middleware. Stored responses must not
+	// be modified.
+	CachedResponse struct {
+		// Status is the response status code.
+		Status int
+		// Header contains the response headers.
+		Header http.Header
+		// Body is the response body.
+		Body []byte
+	}
+
+	// cacheWriter records the response written by the handler while writing it.
+	cacheWriter struct {
+		http.ResponseWriter
+		status int
+		header http.Header
+		body   bytes.Buffer
+	}
+
+	// lruCacheStore is the in-memory CacheStore returned by NewLRUCacheStore.
+	lruCacheStore struct {
+		lock    sync.Mutex
+		size    int
+		entries *list.List
+		index   map[string]*list.Element
+	}
+
+	// lruEntry is a response stored by lruCacheStore.
+	lruEntry struct {
+		key     string
+		resp    *CachedResponse
+		expires time.Time
+	}
+)
+
+// DefaultCacheStore is the store used by the code generated for cacheable actions. It may be
+// replaced with a shared store prior to mounting the controllers.
+var DefaultCacheStore CacheStore = NewLRUCacheStore(1024)
+
+// Cache returns a middleware that serves the responses of the wrapped handler from store. The
+// successful (2xx) responses are stored for ttl under a key made of the request method, path and
+// Accept header and of the values of the given params or payload attributes. Payload attributes
+// are looked up by their JSON name. The query string is used instead if no key names are given.
+// The handler is not called if a response is found in store.
+func Cache(store CacheStore, ttl time.Duration, keys ...string) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := cacheKey(ctx, req, keys)
+			resp := goa.ContextResponse(ctx)
+			if cached, ok := store.Get(key); ok {
+				for k, v := range cached.Header {
+					resp.Header()[k] = v
+				}
+				resp.WriteHeader(cached.Status)
+				_, err := resp.Write(cached.Body)
+				return err
+			}
+			cw := &cacheWriter{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(cw)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(cw.ResponseWriter)
+			if err == nil && cw.status >= 200 && cw.status < 300 {
+				store.Set(key, &CachedResponse{Status: cw.status, Header: cw.header, Body: cw.body.Bytes()}, ttl)
+			}
+			return err
+		}
+	}
+}
+
+// NewLRUCacheStore returns an in-memory CacheStore that holds up to size responses, the least
+// recently used responses are evicted first.
+func NewLRUCacheStore(size int) CacheStore {
+	return &lruCacheStore{size: size, entries: list.New(), index: make(map[string]*list.Element)}
+}
+
+// Get implements CacheStore.
+func (s *lruCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	el, ok := s.index[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expires) {
+		s.entries.Remove(el)
+		delete(s.index, key)
+		return nil, false
+	}
+	s.entries.MoveToFront(el)
+	return e.resp, true
+}
+
+// Set implements CacheStore.
+func (s *lruCacheStore) Set(key string, resp *CachedResponse, ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e := &lruEntry{key: key, resp: resp, expires: time.Now().Add(ttl)}
+	if el, ok := s.index[key]; ok {
+		el.Value = e
+		s.entries.MoveToFront(el)
+		return
+	}
+	s.index[key] = s.entries.PushFront(e)
+	for s.size > 0 && s.entries.Len() > s.size {
+		last := s.entries.Back()
+		s.entries.Remove(last)
+		delete(s.index, last.Value.(*lruEntry).key)
+	}
+}
+
+// WriteHeader records the status and headers of the response.
+func (c *cacheWriter) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+		c.header = make(http.Header, len(c.ResponseWriter.Header()))
+		for k, v := range c.ResponseWriter.Header() {
+			c.header[k] = append([]string(nil), v...)
+		}
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response body.
+func (c *cacheWriter) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.WriteHeader(http.StatusOK)
+	}
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+// cacheKey computes the cache key of a request.
+func cacheKey(ctx context.Context, req *http.Request, keys []string) string {
+	var vals string
+	if len(keys) == 0 {
+		vals = req.URL.RawQuery
+	} else {
+		rd := goa.ContextRequest(ctx)
+		v := make(url.Values, len(keys))
+		for _, k := range keys {
+			if p, ok := rd.Params[k]; ok {
+				v[k] = p
+			} else if f, ok := payloadField(rd.Payload, k); ok {
+				v.Set(k, f)
+			}
+		}
+		vals = v.Encode()
+	}
+	return req.Method + " " + req.URL.Path + "\n" + req.Header.Get("Accept") + "\n" + vals
+}
+
+// payloadField returns the string representation of the field of the payload with the given JSON
+// name, false if the payload does not have the field or if it is not set.
+func payloadField(payload interface{}, name string) (string, bool) {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	var f reflect.Value
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return "", false
+		}
+		f = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			tag := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+			if tag == name {
+				f = v.Field(i)
+				break
+			}
+		}
+	}
+	for f.IsValid() && (f.Kind() == reflect.Ptr || f.Kind() == reflect.Interface) {
+		if f.IsNil() {
+			return "", false
+		}
+		f = f.Elem()
+	}
+	if !f.IsValid() {
+		return "", false
+	}
+	return fmt.Sprint(f.Interface()), true
+}
@@ -0,0 +1,122 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		service *goa.Service
+		store   middleware.CacheStore
+		calls   int
+		status  int
+		keys    []string
+		payload interface{}
+	)
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		resp := goa.ContextResponse(ctx)
+		resp.Header().Set("Content-Type", "text/plain")
+		resp.WriteHeader(status)
+		_, err := resp.Write([]byte("hello"))
+		return err
+	}
+
+	serve := func(path string, params url.Values) *testResponseWriter {
+		req, err := http.NewRequest("GET", path, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := newTestResponseWriter()
+		ctx := newContext(service, rw, req, params)
+		goa.ContextRequest(ctx).Payload = payload
+		Ω(middleware.Cache(store, time.Minute, keys...)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		return rw
+	}
+
+	BeforeEach(func() {
+		service = newService(nil)
+		store = middleware.NewLRUCacheStore(10)
+		calls = 0
+		status = 200
+		keys = nil
+		payload = nil
+	})
+
+	It("serves subsequent requests from the store", func() {
+		serve("/foo", nil)
+		rw := serve("/foo", nil)
+		Ω(calls).Should(Equal(1))
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.Header().Get("Content-Type")).Should(Equal("text/plain"))
+		Ω(string(rw.Body)).Should(Equal("hello"))
+	})
+
+	It("uses the query string when no keys are given", func() {
+		serve("/foo?a=1", nil)
+		serve("/foo?a=2", nil)
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("does not store unsuccessful responses", func() {
+		status = 500
+		serve("/foo", nil)
+		serve("/foo", nil)
+		Ω(calls).Should(Equal(2))
+	})
+
+	Context("with keys", func() {
+		BeforeEach(func() {
+			keys = []string{"id", "name"}
+		})
+
+		It("uses the params and payload attributes", func() {
+			a, b := "a", "b"
+			payload = &struct {
+				Name *string `json:"name,omitempty"`
+			}{Name: &a}
+			serve("/foo?ignored=1", url.Values{"id": {"1"}})
+			serve("/foo?ignored=2", url.Values{"id": {"1"}})
+			Ω(calls).Should(Equal(1))
+			serve("/foo", url.Values{"id": {"2"}})
+			Ω(calls).Should(Equal(2))
+			payload = &struct {
+				Name *string `json:"name,omitempty"`
+			}{Name: &b}
+			serve("/foo", url.Values{"id": {"2"}})
+			Ω(calls).Should(Equal(3))
+		})
+	})
+})
+
+var _ = Describe("NewLRUCacheStore", func() {
+	resp := &middleware.CachedResponse{Status: 200}
+
+	It("evicts the least recently used responses", func() {
+		store := middleware.NewLRUCacheStore(2)
+		store.Set("a", resp, time.Minute)
+		store.Set("b", resp, time.Minute)
+		_, ok := store.Get("a")
+		Ω(ok).Should(BeTrue())
+		store.Set("c", resp, time.Minute)
+		_, ok = store.Get("b")
+		Ω(ok).Should(BeFalse())
+		_, ok = store.Get("a")
+		Ω(ok).Should(BeTrue())
+	})
+
+	It("expires responses", func() {
+		store := middleware.NewLRUCacheStore(2)
+		store.Set("a", resp, -time.Second)
+		_, ok := store.Get("a")
+		Ω(ok).Should(BeFalse())
+	})
+})
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// CoalesceGroup tracks the requests being handled by the handlers wrapped by the
+	// Coalesce middleware. It is safe for concurrent use.
+	CoalesceGroup struct {
+		lock  sync.Mutex
+		calls map[string]*coalescedCall
+	}
+
+	// coalescedCall is a request being handled on behalf of the identical concurrent
+	// requests.
+	coalescedCall struct {
+		done chan struct{}
+		resp *CachedResponse
+		err  error
+	}
+)
+
+// errCoalescedIncomplete is returned to the waiting requests when the request handled on their
+// behalf did not complete, e.g. because the handler panicked.
+var errCoalescedIncomplete = errors.New("coalesced request did not complete")
+
+// DefaultCoalesceGroup is the group used by the code generated for the coalesced actions, see
+// the Coalesce DSL.
+var DefaultCoalesceGroup = NewCoalesceGroup()
+
+// NewCoalesceGroup returns an empty group of coalesced requests.
+func NewCoalesceGroup() *CoalesceGroup {
+	return &CoalesceGroup{calls: make(map[string]*coalescedCall)}
+}
+
+// Coalesce returns a middleware that deduplicates the concurrent identical requests: while a
+// request is being handled the requests with the same method, path, query string and Accept
+// header wait for it to complete and are sent a copy of its response without calling the
+// wrapped handler. The waiting requests return the same error if the handler fails.
+func Coalesce(group *CoalesceGroup) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			key := cacheKey(ctx, req, nil)
+			call, leader := group.join(key)
+			resp := goa.ContextResponse(ctx)
+			if !leader {
+				select {
+				case <-call.done:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if call.err != nil {
+					return call.err
+				}
+				for k, v := range call.resp.Header {
+					resp.Header()[k] = v
+				}
+				resp.WriteHeader(call.resp.Status)
+				_, err := resp.Write(call.resp.Body)
+				return err
+			}
+			defer group.leave(key, call)
+			call.err = errCoalescedIncomplete
+			cw := &cacheWriter{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(cw)
+			err := h(ctx, rw, req)
+			resp.SwitchWriter(cw.ResponseWriter)
+			call.err = err
+			if err == nil {
+				status := cw.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				call.resp = &CachedResponse{Status: status, Header: cw.header, Body: cw.body.Bytes()}
+			}
+			return err
+		}
+	}
+}
+
+// join returns the call handling the request with the given key, the second return value is
+// true if there was none in which case the caller must handle the request and call leave.
+func (g *CoalesceGroup) join(key string) (*coalescedCall, bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if call, ok := g.calls[key]; ok {
+		return call, false
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	g.calls[key] = call
+	return call, true
+}
+
+// leave removes the call from the group and releases the requests waiting for it.
+func (g *CoalesceGroup) leave(key string, call *coalescedCall) {
+	g.lock.Lock()
+	delete(g.calls, key)
+	g.lock.Unlock()
+	close(call.done)
+}
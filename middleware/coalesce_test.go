@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Coalesce", func() {
+	var service *goa.Service
+	var started, release chan struct{}
+	var calls int
+	var herr error
+	var handler goa.Handler
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		calls++
+		started <- struct{}{}
+		<-release
+		if herr != nil {
+			return herr
+		}
+		resp := goa.ContextResponse(ctx)
+		resp.Header().Set("Content-Type", "text/plain")
+		resp.WriteHeader(200)
+		_, err := resp.Write([]byte("hello"))
+		return err
+	}
+
+	request := func(path string) (context.Context, *testResponseWriter, *http.Request) {
+		req, err := http.NewRequest("GET", path, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := newTestResponseWriter()
+		return newContext(service, rw, req, nil), rw, req
+	}
+
+	BeforeEach(func() {
+		service = newService(nil)
+		started = make(chan struct{}, 2)
+		release = make(chan struct{})
+		calls = 0
+		herr = nil
+		handler = middleware.Coalesce(middleware.NewCoalesceGroup())(h)
+	})
+
+	It("sends the response of the pending request to the identical requests", func() {
+		ctx, rw, req := request("/foo?a=1")
+		done := make(chan error)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		ctx2, rw2, req2 := request("/foo?a=1")
+		Ω(handler(ctx2, rw2, req2)).ShouldNot(HaveOccurred())
+		Ω(<-done).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(1))
+		Ω(rw2.Status).Should(Equal(200))
+		Ω(rw2.Header().Get("Content-Type")).Should(Equal("text/plain"))
+		Ω(string(rw2.Body)).Should(Equal("hello"))
+		Ω(string(rw.Body)).Should(Equal("hello"))
+	})
+
+	It("does not coalesce requests with different query strings", func() {
+		close(release)
+		ctx, rw, req := request("/foo?a=1")
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		ctx, rw, req = request("/foo?a=2")
+		Ω(handler(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(calls).Should(Equal(2))
+	})
+
+	It("returns the error of the pending request", func() {
+		herr = errors.New("boom")
+		ctx, rw, req := request("/foo")
+		done := make(chan error)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+		}()
+		ctx2, rw2, req2 := request("/foo")
+		Ω(handler(ctx2, rw2, req2)).Should(Equal(herr))
+		Ω(<-done).Should(Equal(herr))
+		Ω(calls).Should(Equal(1))
+	})
+
+	It("stops waiting when the request is canceled", func() {
+		ctx, rw, req := request("/foo")
+		done := make(chan error)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		ctx2, rw2, req2 := request("/foo")
+		cctx, cancel := context.WithCancel(ctx2)
+		cancel()
+		Ω(handler(cctx, rw2, req2)).Should(Equal(context.Canceled))
+		close(release)
+		Ω(<-done).ShouldNot(HaveOccurred())
+	})
+})
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// LimitConcurrency returns a middleware that limits the number of requests handled concurrently
+// by the handlers it wraps to limit. The requests that exceed the limit wait up to timeout for
+// another request to complete, they get a goa.ErrServiceUnavailable error if none does in time
+// or right away if timeout is zero. The handlers wrapped by the same middleware share the limit.
+func LimitConcurrency(limit int, timeout time.Duration) goa.Middleware {
+	slots := make(chan struct{}, limit)
+	reject := func() error {
+		return goa.ErrServiceUnavailable("too many concurrent requests, retry later", "limit", limit)
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			select {
+			case slots <- struct{}{}:
+			default:
+				if timeout <= 0 {
+					return reject()
+				}
+				timer := time.NewTimer(timeout)
+				select {
+				case slots <- struct{}{}:
+					timer.Stop()
+				case <-timer.C:
+					return reject()
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+			defer func() { <-slots }()
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LimitConcurrency", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var started, release chan struct{}
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	BeforeEach(func() {
+		service := newService(nil)
+		var err error
+		req, err = http.NewRequest("POST", "/reports", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		started = make(chan struct{}, 1)
+		release = make(chan struct{})
+	})
+
+	It("rejects the requests that exceed the limit", func() {
+		handler := middleware.LimitConcurrency(1, 0)(h)
+		done := make(chan error)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		err := handler(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		var se goa.ServiceError
+		Ω(errors.As(err, &se)).Should(BeTrue())
+		Ω(se.ResponseStatus()).Should(Equal(503))
+		close(release)
+		Ω(<-done).ShouldNot(HaveOccurred())
+	})
+
+	It("queues the requests until the timeout", func() {
+		handler := middleware.LimitConcurrency(1, time.Second)(h)
+		done := make(chan error, 2)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		go func() { done <- handler(ctx, rw, req) }()
+		close(release)
+		<-started
+		Ω(<-done).ShouldNot(HaveOccurred())
+		Ω(<-done).ShouldNot(HaveOccurred())
+	})
+
+	It("stops waiting when the request is canceled", func() {
+		handler := middleware.LimitConcurrency(1, time.Minute)(h)
+		done := make(chan error)
+		go func() { done <- handler(ctx, rw, req) }()
+		<-started
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		Ω(handler(cctx, rw, req)).Should(Equal(context.Canceled))
+		close(release)
+		Ω(<-done).ShouldNot(HaveOccurred())
+	})
+})
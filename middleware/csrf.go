@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+const (
+	// CSRFCookieName is the name of the cookie that holds the CSRF token.
+	CSRFCookieName = "csrf_token"
+	// CSRFHeaderName is the name of the header that must echo the CSRF token.
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// ErrInvalidCSRFToken is the error returned when a request fails the CSRF token check.
+var ErrInvalidCSRFToken = goa.NewErrorClass("invalid_csrf_token", 403)
+
+// CSRF returns a middleware that implements the double submit cookie CSRF protection. The
+// middleware issues a random token in the CSRFCookieName cookie to clients that do not have one
+// yet. Requests made with unsafe HTTP methods (any method other than GET, HEAD, OPTIONS and TRACE)
+// must echo the value of the cookie in the CSRFHeaderName header, the middleware responds with
+// ErrInvalidCSRFToken otherwise.
+func CSRF() goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			var token string
+			if c, err := req.Cookie(CSRFCookieName); err == nil {
+				token = c.Value
+			}
+			switch req.Method {
+			case "GET", "HEAD", "OPTIONS", "TRACE":
+				if token == "" {
+					if _, err := issueCSRFToken(rw, req); err != nil {
+						return err
+					}
+				}
+				return h(ctx, rw, req)
+			}
+			echoed := req.Header.Get(CSRFHeaderName)
+			if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(echoed)) != 1 {
+				return ErrInvalidCSRFToken("missing or invalid CSRF token")
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// CSRFTokenHandler returns a handler that issues a new CSRF token. The token is set in the
+// CSRFCookieName cookie and in the CSRFHeaderName header of the response, the response has no
+// body.
+func CSRFTokenHandler() goa.Handler {
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		token, err := issueCSRFToken(rw, req)
+		if err != nil {
+			return err
+		}
+		rw.Header().Set(CSRFHeaderName, token)
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+// issueCSRFToken generates a new CSRF token and sets the corresponding cookie in the response.
+// The cookie is not HTTP only so that browser clients may read and echo it.
+func issueCSRFToken(rw http.ResponseWriter, req *http.Request) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(rw, &http.Cookie{
+		Name:   CSRFCookieName,
+		Value:  token,
+		Path:   "/",
+		Secure: req.TLS != nil,
+	})
+	return token, nil
+}
@@ -0,0 +1,89 @@
+package middleware_test
+
+import (
+	"net/http"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSRF", func() {
+	var (
+		ctx     context.Context
+		req     *http.Request
+		rw      *testResponseWriter
+		service *goa.Service
+		called  bool
+		method  string
+	)
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+
+	BeforeEach(func() {
+		method = "POST"
+		called = false
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		service = newService(nil)
+		req, err = http.NewRequest(method, "/foo", strings.NewReader(`{"payload":42}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+	})
+
+	Context("with a safe method", func() {
+		BeforeEach(func() {
+			method = "GET"
+		})
+
+		It("issues a token", func() {
+			Ω(middleware.CSRF()(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+			Ω(rw.Header().Get("Set-Cookie")).Should(HavePrefix(middleware.CSRFCookieName + "="))
+		})
+	})
+
+	It("rejects requests with no token", func() {
+		err := middleware.CSRF()(h)(ctx, rw, req)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("rejects requests whose header does not match the cookie", func() {
+		req.AddCookie(&http.Cookie{Name: middleware.CSRFCookieName, Value: "token"})
+		req.Header.Set(middleware.CSRFHeaderName, "other")
+		Ω(middleware.CSRF()(h)(ctx, rw, req)).Should(HaveOccurred())
+		Ω(called).Should(BeFalse())
+	})
+
+	It("accepts requests that echo the token", func() {
+		req.AddCookie(&http.Cookie{Name: middleware.CSRFCookieName, Value: "token"})
+		req.Header.Set(middleware.CSRFHeaderName, "token")
+		Ω(middleware.CSRF()(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
+
+var _ = Describe("CSRFTokenHandler", func() {
+	It("issues a new token", func() {
+		req, err := http.NewRequest("GET", "/csrf", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := newTestResponseWriter()
+		Ω(middleware.CSRFTokenHandler()(context.Background(), rw, req)).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(http.StatusNoContent))
+		token := rw.Header().Get(middleware.CSRFHeaderName)
+		Ω(token).ShouldNot(BeEmpty())
+		Ω(rw.Header().Get("Set-Cookie")).Should(HavePrefix(middleware.CSRFCookieName + "=" + token))
+	})
+})
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// Deprecated returns a middleware that sets the Deprecation response header to since as defined
+// by RFC 9745 and the Sunset response header to sunset as defined by RFC 8594. The Sunset header
+// is omitted if sunset is the zero time. The generated code uses it for the actions that are
+// deprecated, see the Deprecated DSL.
+func Deprecated(since, sunset time.Time) goa.Middleware {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	var sunsetHeader string
+	if !sunset.IsZero() {
+		sunsetHeader = sunset.UTC().Format(http.TimeFormat)
+	}
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Deprecation", deprecation)
+			if sunsetHeader != "" {
+				rw.Header().Set("Sunset", sunsetHeader)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Deprecated", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var called bool
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+	since := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		service := newService(nil)
+		var err error
+		req, err = http.NewRequest("GET", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		called = false
+	})
+
+	It("sets the Deprecation and Sunset headers", func() {
+		sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+		Ω(middleware.Deprecated(since, sunset)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+		Ω(rw.Header().Get("Deprecation")).Should(Equal("@1768435200"))
+		Ω(rw.Header().Get("Sunset")).Should(Equal("Wed, 01 Jul 2026 00:00:00 GMT"))
+	})
+
+	It("omits the Sunset header when no sunset is scheduled", func() {
+		Ω(middleware.Deprecated(since, time.Time{})(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(rw.Header().Get("Deprecation")).Should(Equal("@1768435200"))
+		Ω(rw.Header()).ShouldNot(HaveKey("Sunset"))
+	})
+})
@@ -15,6 +15,8 @@ import (
 // them, it turns other Go error types into a 500 internal error response.
 // If verbose is false the details of internal errors is not included in HTTP responses.
 // If you use github.com/pkg/errors then wrapping the error will allow a trace to be printed to the logs
+// The responses are written with the error encoder of the controller action or of the service if
+// any, see goa.Service.ErrorEncoder.
 func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
@@ -52,6 +54,17 @@ func ErrorHandler(service *goa.Service, verbose bool) goa.Middleware {
 					}
 				}
 			}
+			enc := goa.ContextErrorEncoder(ctx)
+			if enc == nil {
+				enc = service.ErrorEncoder
+			}
+			if enc != nil {
+				err, ok := respBody.(error)
+				if !ok {
+					err = e
+				}
+				return enc(ctx, status, err)
+			}
 			return service.Send(ctx, status, respBody)
 		}
 	}
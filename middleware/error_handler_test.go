@@ -163,6 +163,30 @@ var _ = Describe("ErrorHandler", func() {
 		})
 	})
 
+	Context("with a service error encoder", func() {
+		var gerr error
+		var encoded error
+
+		BeforeEach(func() {
+			service = newService(nil)
+			gerr = goa.NewErrorClass("code", 418)("teapot")
+			encoded = nil
+			service.ErrorEncoder = func(ctx context.Context, status int, err error) error {
+				encoded = err
+				return service.Send(ctx, status, map[string]string{"error": err.(goa.ServiceError).Token()})
+			}
+			h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				return gerr
+			}
+		})
+
+		It("encodes the error with the service error encoder", func() {
+			Ω(encoded).Should(Equal(gerr))
+			Ω(rw.Status).Should(Equal(418))
+			Ω(string(rw.Body)).Should(Equal(`{"error":"` + gerr.(goa.ServiceError).Token() + `"}` + "\n"))
+		})
+	})
+
 	Context("with a handler returning a pkg errors wrapped error", func() {
 		var wrappedError error
 		var logger *testLogger
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// FlagProvider reports whether the feature flags gating the endpoints are enabled.
+	FlagProvider interface {
+		// Enabled returns true if the flag with the given name is enabled for the request
+		// being handled with ctx.
+		Enabled(ctx context.Context, flag string) bool
+	}
+
+	// FlagProviderFunc is an adapter that allows the use of ordinary functions as flag
+	// providers.
+	FlagProviderFunc func(ctx context.Context, flag string) bool
+)
+
+// ErrFeatureDisabled is the error returned to the requests made to the endpoints gated behind a
+// disabled feature flag that respond with 403.
+var ErrFeatureDisabled = goa.NewErrorClass("feature_disabled", 403)
+
+// DefaultFlagProvider is the provider used by the code generated for the actions gated behind a
+// feature flag, see the Flag DSL. It must be set before the controllers are mounted, the flags
+// are ignored if nil.
+var DefaultFlagProvider FlagProvider
+
+// Enabled calls f(ctx, flag).
+func (f FlagProviderFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// Flag creates a middleware that handles the requests only if provider reports that the given
+// feature flag is enabled. The requests made while the flag is disabled get the same not found
+// error as the requests that match no route if status is 404 and ErrFeatureDisabled otherwise.
+// It does nothing if provider is nil.
+func Flag(provider FlagProvider, flag string, status int) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		if provider == nil {
+			return h
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if provider.Enabled(ctx, flag) {
+				return h(ctx, rw, req)
+			}
+			if status == http.StatusNotFound {
+				return goa.ErrNotFound(req.URL.Path)
+			}
+			return ErrFeatureDisabled("feature is disabled", "flag", flag)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Flag", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var called bool
+	var enabled map[string]bool
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+	provider := middleware.FlagProviderFunc(func(ctx context.Context, flag string) bool {
+		return enabled[flag]
+	})
+
+	BeforeEach(func() {
+		service := newService(nil)
+		var err error
+		req, err = http.NewRequest("GET", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		called = false
+		enabled = map[string]bool{"on": true}
+	})
+
+	It("handles the requests when the flag is enabled", func() {
+		Ω(middleware.Flag(provider, "on", 404)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("returns a not found error when the flag is disabled", func() {
+		err := middleware.Flag(provider, "off", 404)(h)(ctx, rw, req)
+		Ω(called).Should(BeFalse())
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(404))
+	})
+
+	It("returns a forbidden error when the flag is disabled and the status is 403", func() {
+		err := middleware.Flag(provider, "off", 403)(h)(ctx, rw, req)
+		Ω(called).Should(BeFalse())
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+	})
+
+	It("ignores the flags when there is no provider", func() {
+		Ω(middleware.Flag(nil, "off", 404)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
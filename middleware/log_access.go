@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// LogAccess creates a middleware that writes one structured log entry per request once the
+// request has been handled. The entry lists the given route, the response status, the latency
+// and the request and response body sizes, the controller and action names as well as the values
+// of the request params. The values of the params registered as sensitive are omitted. The code
+// generated for the actions whose design enables request logging uses this middleware.
+// If sampler is not nil only the requests it samples are logged.
+func LogAccess(route string, sampler Sampler) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if sampler != nil && !sampler.Sample() {
+				return h(ctx, rw, req)
+			}
+			startedAt := time.Now()
+			err := h(ctx, rw, req)
+			resp := goa.ContextResponse(ctx)
+			keyvals := []interface{}{
+				"route", route,
+				"status", responseStatus(ctx, err),
+				"latency", time.Since(startedAt).String(),
+			}
+			if req.ContentLength >= 0 {
+				keyvals = append(keyvals, "req_bytes", req.ContentLength)
+			}
+			keyvals = append(keyvals,
+				"resp_bytes", resp.Length,
+				"ctrl", goa.ContextController(ctx),
+				"action", goa.ContextAction(ctx))
+			if reqID := ctx.Value(reqIDKey); reqID != nil {
+				keyvals = append(keyvals, "req_id", reqID)
+			}
+			if params := loggedParams(goa.ContextRequest(ctx)); len(params) > 0 {
+				keyvals = append(keyvals, "params", params)
+			}
+			if err != nil {
+				keyvals = append(keyvals, "error", err.Error())
+			}
+			goa.LogInfo(ctx, "request", keyvals...)
+			return err
+		}
+	}
+}
+
+// loggedParams returns the values of the request params that are not sensitive.
+func loggedParams(r *goa.RequestData) map[string]string {
+	if r == nil || len(r.Params) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(r.Params))
+	for k, v := range r.Params {
+		if goa.IsSensitive(k) {
+			continue
+		}
+		params[k] = strings.Join(v, ", ")
+	}
+	return params
+}
+
+// responseStatus returns the status code of the response written for the request handled with
+// the given outcome.
+func responseStatus(ctx context.Context, err error) int {
+	status := goa.ContextResponse(ctx).Status
+	if err != nil && status == 0 {
+		status = http.StatusInternalServerError
+		if serr, ok := err.(goa.ServiceError); ok {
+			status = serr.ResponseStatus()
+		}
+	}
+	return status
+}
@@ -0,0 +1,80 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LogAccess", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var logger *testLogger
+	var service *goa.Service
+
+	fields := func(e logEntry) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i+1 < len(e.Data); i += 2 {
+			m[e.Data[i].(string)] = e.Data[i+1]
+		}
+		return m
+	}
+
+	BeforeEach(func() {
+		logger = new(testLogger)
+		service = newService(logger)
+
+		var err error
+		req, err = http.NewRequest("POST", "/bottles/1", strings.NewReader(`{"name":"x"}`))
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		params := url.Values{"id": []string{"1"}, "token": []string{"s3cr3t"}}
+		ctrl := service.NewController("bottle")
+		ctx = goa.WithAction(goa.NewContext(ctrl.Context, rw, req, params), "update")
+		goa.RegisterSensitive("token")
+	})
+
+	It("logs the request", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		Ω(middleware.LogAccess("POST /bottles/:id", nil)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(1))
+		Ω(logger.InfoEntries[0].Msg).Should(Equal("request"))
+		f := fields(logger.InfoEntries[0])
+		Ω(f["route"]).Should(Equal("POST /bottles/:id"))
+		Ω(f["status"]).Should(Equal(200))
+		Ω(f["req_bytes"]).Should(Equal(int64(12)))
+		Ω(f["resp_bytes"]).Should(Equal(5))
+		Ω(f["ctrl"]).Should(Equal("bottle"))
+		Ω(f["action"]).Should(Equal("update"))
+		Ω(f).Should(HaveKey("latency"))
+		Ω(f["params"]).Should(Equal(map[string]string{"id": "1"}))
+	})
+
+	It("logs the status of errors", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.ErrNotFound("no bottle")
+		}
+		Ω(middleware.LogAccess("POST /bottles/:id", nil)(h)(ctx, rw, req)).Should(HaveOccurred())
+		f := fields(logger.InfoEntries[0])
+		Ω(f["status"]).Should(Equal(404))
+		Ω(f).Should(HaveKey("error"))
+	})
+
+	It("only logs the sampled requests", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		Ω(middleware.LogAccess("POST /bottles/:id", middleware.NewFixedSampler(0))(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(BeEmpty())
+	})
+})
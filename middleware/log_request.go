@@ -37,7 +37,7 @@ func LogRequest(verbose bool) goa.Middleware {
 					i := 0
 					for k, v := range r.Header {
 						logCtx[i] = k
-						logCtx[i+1] = interface{}(strings.Join(v, ", "))
+						logCtx[i+1] = redactValues(k, v)
 						i = i + 2
 					}
 					goa.LogInfo(ctx, "headers", logCtx...)
@@ -47,13 +47,14 @@ func LogRequest(verbose bool) goa.Middleware {
 					i := 0
 					for k, v := range r.Params {
 						logCtx[i] = k
-						logCtx[i+1] = interface{}(strings.Join(v, ", "))
+						logCtx[i+1] = redactValues(k, v)
 						i = i + 2
 					}
 					goa.LogInfo(ctx, "params", logCtx...)
 				}
 				if r.ContentLength > 0 {
-					if mp, ok := r.Payload.(map[string]interface{}); ok {
+					payload := goa.Redact(r.Payload)
+					if mp, ok := payload.(map[string]interface{}); ok {
 						logCtx := make([]interface{}, 2*len(mp))
 						i := 0
 						for k, v := range mp {
@@ -64,7 +65,7 @@ func LogRequest(verbose bool) goa.Middleware {
 						goa.LogInfo(ctx, "payload", logCtx...)
 					} else {
 						// Not the most efficient but this is used for debugging
-						js, err := json.Marshal(r.Payload)
+						js, err := json.Marshal(payload)
 						if err != nil {
 							js = []byte("<invalid JSON>")
 						}
@@ -88,6 +89,14 @@ func LogRequest(verbose bool) goa.Middleware {
 	}
 }
 
+// redactValues returns the loggable value of the header or param with the given name.
+func redactValues(name string, values []string) interface{} {
+	if goa.IsSensitive(name) {
+		return goa.RedactedValue
+	}
+	return strings.Join(values, ", ")
+}
+
 // shortID produces a "unique" 6 bytes long string.
 // Do not use as a reliable way to get unique IDs, instead use for things like logging.
 func shortID() string {
@@ -76,6 +76,29 @@ var _ = Describe("LogRequest", func() {
 		Ω(logger.InfoEntries[3].Data[11]).Should(Equal("goo"))
 	})
 
+	It("redacts sensitive values", func() {
+		type secretPayload struct {
+			Secret string `json:"secret"`
+		}
+		goa.RegisterSensitive("X-Secret")
+		goa.RegisterSensitiveFields((*secretPayload)(nil), "secret")
+		req.Header.Set("X-Secret", "shh")
+		goa.ContextRequest(ctx).Payload = &secretPayload{Secret: "shh"}
+
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, "ok")
+		}
+		lg := middleware.LogRequest(true)(h)
+		Ω(lg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(logger.InfoEntries).Should(HaveLen(5))
+
+		Ω(logger.InfoEntries[1].Data[2]).Should(Equal("X-Secret"))
+		Ω(logger.InfoEntries[1].Data[3]).Should(Equal(goa.RedactedValue))
+
+		Ω(logger.InfoEntries[3].Data[2]).Should(Equal("secret"))
+		Ω(logger.InfoEntries[3].Data[3]).Should(Equal(goa.RedactedValue))
+	})
+
 	It("logs error codes", func() {
 		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			return goa.MissingParamError("foo")
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+type (
+	// Recording describes a request made to a recorded action and its response. The values
+	// of the sensitive params, headers and payload attributes are replaced with
+	// goa.RedactedValue.
+	Recording struct {
+		// Resource is the name of the resource that defines the action.
+		Resource string `json:"resource"`
+		// Action is the name of the action.
+		Action string `json:"action"`
+		// Method is the HTTP method of the request.
+		Method string `json:"method"`
+		// Path is the path of the request URL.
+		Path string `json:"path"`
+		// Query contains the query string params of the request.
+		Query url.Values `json:"query,omitempty"`
+		// Header contains the request headers.
+		Header http.Header `json:"header,omitempty"`
+		// Payload is the JSON representation of the request payload if any.
+		Payload json.RawMessage `json:"payload,omitempty"`
+		// Status is the response status code.
+		Status int `json:"status"`
+		// ResponseHeader contains the response headers.
+		ResponseHeader http.Header `json:"response_header,omitempty"`
+		// ResponseBody is the body written by the action, it is empty if the action returned
+		// an error as the error response is written by the service error handler.
+		ResponseBody []byte `json:"response_body,omitempty"`
+		// Err is the message of the error returned by the action if any.
+		Err string `json:"error,omitempty"`
+		// StartedAt is the time the request handling started.
+		StartedAt time.Time `json:"started_at"`
+		// Duration is the time it took to handle the request.
+		Duration time.Duration `json:"duration"`
+	}
+
+	// RecordSink receives the recordings made by the Record middleware.
+	RecordSink interface {
+		// Record is called once the request to a recorded action has been handled.
+		Record(ctx context.Context, rec *Recording)
+	}
+
+	// recordingResponseWriter wraps an http.ResponseWriter and keeps a copy of the written
+	// body.
+	recordingResponseWriter struct {
+		http.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// DefaultRecordSink is the sink used by the code generated for the recorded actions, see the
+// Record DSL. It must be set before the controllers are mounted, nothing is recorded if nil.
+var DefaultRecordSink RecordSink
+
+// Record creates a middleware that sends the request and the response of the given action of
+// the given resource to sink once each request has been handled. It does nothing if sink is nil.
+func Record(sink RecordSink, resource, action string) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		if sink == nil {
+			return h
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			startedAt := time.Now()
+			resp := goa.ContextResponse(ctx)
+			recorder := &recordingResponseWriter{ResponseWriter: resp.SwitchWriter(nil)}
+			resp.SwitchWriter(recorder)
+			err := h(ctx, rw, req)
+			rec := &Recording{
+				Resource:       resource,
+				Action:         action,
+				Method:         req.Method,
+				Path:           req.URL.Path,
+				Query:          recordedValues(req.URL.Query()),
+				Header:         http.Header(recordedValues(url.Values(req.Header))),
+				Payload:        recordedPayload(goa.ContextRequest(ctx)),
+				Status:         responseStatus(ctx, err),
+				ResponseHeader: http.Header(recordedValues(url.Values(recorder.Header()))),
+				ResponseBody:   recordedBody(recorder.body.Bytes(), recorder.Header().Get("Content-Type")),
+				StartedAt:      startedAt,
+				Duration:       time.Since(startedAt),
+			}
+			if err != nil {
+				rec.Err = err.Error()
+			}
+			sink.Record(ctx, rec)
+			return err
+		}
+	}
+}
+
+// Write copies the data to the recorded body and writes it to the underlying response writer.
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// recordedValues returns a copy of vals where the values of the sensitive keys are redacted, nil
+// if vals is empty.
+func recordedValues(vals url.Values) url.Values {
+	if len(vals) == 0 {
+		return nil
+	}
+	res := make(url.Values, len(vals))
+	for k, vs := range vals {
+		if goa.IsSensitive(k) {
+			res[k] = []string{goa.RedactedValue}
+			continue
+		}
+		res[k] = append([]string(nil), vs...)
+	}
+	return res
+}
+
+// recordedPayload returns the JSON representation of the request payload with the sensitive
+// attributes redacted, nil if the request has no payload.
+func recordedPayload(r *goa.RequestData) json.RawMessage {
+	if r == nil || r.Payload == nil {
+		return nil
+	}
+	b, err := json.Marshal(goa.Redact(r.Payload))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// recordedBody returns a copy of the given response body where the sensitive attributes are
+// redacted if the body is a JSON document of a media type registered with
+// goa.RegisterSensitiveMediaType.
+func recordedBody(body []byte, contentType string) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	return append([]byte(nil), goa.RedactBody(body, contentType, nil)...)
+}
@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"net/http"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testRecordSink struct {
+	Recordings []*middleware.Recording
+}
+
+func (s *testRecordSink) Record(ctx context.Context, rec *middleware.Recording) {
+	s.Recordings = append(s.Recordings, rec)
+}
+
+// recordedBottle is a payload and media type with a sensitive attribute.
+type recordedBottle struct {
+	Name string `json:"name"`
+	Pin  string `json:"recordPin"`
+}
+
+var _ = Describe("Record", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var service *goa.Service
+	var sink *testRecordSink
+
+	BeforeEach(func() {
+		goa.RegisterSensitive("X-Record-Token", "recordPin")
+		goa.RegisterSensitiveFields((*recordedBottle)(nil), "recordPin")
+		goa.RegisterSensitiveMediaType("application/vnd.record.bottle+json", (*recordedBottle)(nil))
+		service = newService(nil)
+		var err error
+		req, err = http.NewRequest("PATCH", "/bottles/1?recordPin=1234&dry=true", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("X-Record-Token", "secret")
+		req.Header.Set("X-Request-Id", "abc")
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		sink = &testRecordSink{}
+	})
+
+	It("records the requests and responses with the sensitive values redacted", func() {
+		goa.ContextRequest(ctx).Payload = &recordedBottle{Name: "red", Pin: "1234"}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			rw.Header().Set("Content-Type", "application/vnd.record.bottle+json")
+			return service.Send(ctx, 200, &recordedBottle{Name: "red", Pin: "1234"})
+		}
+		Ω(middleware.Record(sink, "bottle", "update")(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(sink.Recordings).Should(HaveLen(1))
+		rec := sink.Recordings[0]
+		Ω(rec.Resource).Should(Equal("bottle"))
+		Ω(rec.Action).Should(Equal("update"))
+		Ω(rec.Method).Should(Equal("PATCH"))
+		Ω(rec.Path).Should(Equal("/bottles/1"))
+		Ω(rec.Query.Get("recordPin")).Should(Equal(goa.RedactedValue))
+		Ω(rec.Query.Get("dry")).Should(Equal("true"))
+		Ω(rec.Header.Get("X-Record-Token")).Should(Equal(goa.RedactedValue))
+		Ω(rec.Header.Get("X-Request-Id")).Should(Equal("abc"))
+		Ω(string(rec.Payload)).Should(MatchJSON(`{"name":"red","recordPin":"<redacted>"}`))
+		Ω(rec.Status).Should(Equal(200))
+		Ω(string(rec.ResponseBody)).Should(MatchJSON(`{"name":"red","recordPin":"<redacted>"}`))
+		Ω(string(rw.Body)).Should(MatchJSON(`{"name":"red","recordPin":"1234"}`))
+		Ω(rec.Err).Should(BeEmpty())
+	})
+
+	It("does not redact the attributes of other types with the same name", func() {
+		goa.ContextRequest(ctx).Payload = map[string]interface{}{"name": "red", "recordPin": "1234"}
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return service.Send(ctx, 200, map[string]string{"name": "red", "recordPin": "1234"})
+		}
+		Ω(middleware.Record(sink, "bottle", "update")(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		rec := sink.Recordings[0]
+		Ω(string(rec.Payload)).Should(MatchJSON(`{"name":"red","recordPin":"1234"}`))
+		Ω(string(rec.ResponseBody)).Should(MatchJSON(`{"name":"red","recordPin":"1234"}`))
+	})
+
+	It("records the errors", func() {
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return goa.ErrNotFound("no bottle")
+		}
+		Ω(middleware.Record(sink, "bottle", "update")(h)(ctx, rw, req)).Should(HaveOccurred())
+		rec := sink.Recordings[0]
+		Ω(rec.Payload).Should(BeEmpty())
+		Ω(rec.Status).Should(Equal(404))
+		Ω(rec.Err).ShouldNot(BeEmpty())
+	})
+
+	It("does nothing without a sink", func() {
+		called := false
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+		Ω(middleware.Record(nil, "bottle", "update")(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
@@ -3,12 +3,18 @@ package middleware
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
 
 	"context"
 )
@@ -50,15 +56,32 @@ func RequestIDWithHeader(requestIDHeader string) goa.Middleware {
 // request id header as the (first) argument and a length limit for truncation of the request
 // header value if it exceeds a reasonable length. The limit can be negative for unlimited.
 func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int) goa.Middleware {
+	return requestID(requestIDHeader, lengthLimit, counterID)
+}
+
+// RequestIDWithFunc behaves like the middleware RequestID, but it uses f to create the IDs of the
+// requests that do not have a RequestIDHeader header, e.g. UUIDv7, ULID or a generator returned by
+// NewSnowflake.
+func RequestIDWithFunc(f IDFunc) goa.Middleware {
+	if f == nil {
+		panic("request ID function cannot be nil")
+	}
+	return requestID(RequestIDHeader, DefaultRequestIDLengthLimit, f)
+}
+
+// requestID returns the middleware that injects the request ID read from the given header or
+// created with f into the context.
+func requestID(requestIDHeader string, lengthLimit int, f IDFunc) goa.Middleware {
 	return func(h goa.Handler) goa.Handler {
 		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
 			id := req.Header.Get(requestIDHeader)
 			if id == "" {
-				id = fmt.Sprintf("%s-%d", reqPrefix, atomic.AddInt64(&reqID, 1))
+				id = f()
 			} else if lengthLimit >= 0 && len(id) > lengthLimit {
 				id = id[:lengthLimit]
 			}
 			ctx = context.WithValue(ctx, reqIDKey, id)
+			ctx = client.SetContextRequestID(ctx, id)
 
 			return h(ctx, rw, req)
 		}
@@ -67,7 +90,9 @@ func RequestIDWithHeaderAndLengthLimit(requestIDHeader string, lengthLimit int)
 
 // RequestID is a middleware that injects a request ID into the context of each request.
 // Retrieve it using ctx.Value(ReqIDKey). If the incoming request has a RequestIDHeader header then
-// that value is used else a random value is generated.
+// that value is used else a random value is generated. The request ID is also propagated to the
+// requests made by the goa clients with the request context so that the calls made to other
+// services while handling the request carry the same ID.
 func RequestID() goa.Middleware {
 	return RequestIDWithHeader(RequestIDHeader)
 }
@@ -80,3 +105,92 @@ func ContextRequestID(ctx context.Context) (reqID string) {
 	}
 	return
 }
+
+// counterID returns the default request IDs made of the process prefix and of a counter.
+func counterID() string {
+	return fmt.Sprintf("%s-%d", reqPrefix, atomic.AddInt64(&reqID, 1))
+}
+
+// UUIDv7 returns a new version 7 UUID as defined by RFC 9562. The UUID starts with the current
+// Unix time in milliseconds so that the IDs sort by creation time.
+func UUIDv7() string {
+	var b [16]byte
+	rand.Read(b[6:])
+	putMillis(b[:], time.Now())
+	b[6] = b[6]&0x0f | 0x70 // version 7
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// crockford is the Crockford's base32 alphabet used to encode ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a new Universally Unique Lexicographically Sortable Identifier: 26 characters that
+// encode the current Unix time in milliseconds followed by 80 random bits.
+func ULID() string {
+	var b [16]byte
+	rand.Read(b[6:])
+	putMillis(b[:], time.Now())
+	// The 128 bits are encoded in 26 characters of 5 bits, the first one has 2 padding bits.
+	var out [26]byte
+	for i := range out {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v <<= 1
+			if bit := i*5 + j - 2; bit >= 0 && b[bit/8]&(0x80>>uint(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockford[v]
+	}
+	return string(out[:])
+}
+
+// putMillis writes the 48 bits of the Unix time in milliseconds of t to the first 6 bytes of b.
+func putMillis(b []byte, t time.Time) {
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixNano()/int64(time.Millisecond)))
+	copy(b[:6], ms[2:])
+}
+
+// SnowflakeEpoch is the time the timestamps of the IDs created by NewSnowflake are relative to.
+var SnowflakeEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NewSnowflake returns a function that creates snowflake IDs: 64-bit integers made of the number
+// of milliseconds elapsed since SnowflakeEpoch on 41 bits, of the given node number on 10 bits and
+// of a sequence number on 12 bits. Each instance of the service must use a distinct node number
+// between 0 and 1023 for the IDs to be unique. The IDs are formatted in decimal.
+func NewSnowflake(node int64) IDFunc {
+	if node < 0 || node > 1023 {
+		panic("snowflake node must be between 0 and 1023")
+	}
+	var (
+		mu   sync.Mutex
+		last int64
+		seq  int64
+	)
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		now := int64(time.Since(SnowflakeEpoch) / time.Millisecond)
+		if now < last {
+			// the clock went backwards, keep using the last timestamp
+			now = last
+		}
+		if now == last {
+			seq = (seq + 1) & 0xfff
+			if seq == 0 {
+				// sequence exhausted for this millisecond, wait for the next one
+				for now <= last {
+					time.Sleep(100 * time.Microsecond)
+					now = int64(time.Since(SnowflakeEpoch) / time.Millisecond)
+				}
+			}
+		} else {
+			seq = 0
+		}
+		last = now
+		return strconv.FormatInt(now<<22|node<<12|seq, 10)
+	}
+}
@@ -2,11 +2,14 @@ package middleware_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 
 	"context"
 
 	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
 	"github.com/goadesign/goa/middleware"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -83,6 +86,64 @@ var _ = Describe("RequestID", func() {
 		Ω(middleware.ContextRequestID(newCtx)).Should(Equal(string(original)))
 	})
 
+	It("uses the given function to create the missing request IDs", func() {
+		var newCtx context.Context
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			newCtx = ctx
+			return service.Send(ctx, 200, "ok")
+		}
+		req.Header.Del(middleware.RequestIDHeader)
+		rg := middleware.RequestIDWithFunc(func() string { return "generated" })(h)
+		Ω(rg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(middleware.ContextRequestID(newCtx)).Should(Equal("generated"))
+	})
+
+	It("propagates the request ID to the client requests", func() {
+		var received string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Get(middleware.RequestIDHeader)
+		}))
+		defer server.Close()
+		h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			creq, err := http.NewRequest("GET", server.URL, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			resp, err := client.New(nil).Do(ctx, creq)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			return service.Send(ctx, 200, "ok")
+		}
+		rg := middleware.RequestID()(h)
+		Ω(rg(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(received).Should(Equal(reqID))
+	})
+})
+
+var _ = Describe("Request ID functions", func() {
+	It("creates version 7 UUIDs", func() {
+		id := middleware.UUIDv7()
+		Ω(id).Should(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+		Ω(middleware.UUIDv7()).ShouldNot(Equal(id))
+	})
+
+	It("creates ULIDs", func() {
+		id := middleware.ULID()
+		Ω(id).Should(MatchRegexp(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`))
+		Ω(middleware.ULID()).ShouldNot(Equal(id))
+	})
+
+	It("creates increasing snowflake IDs", func() {
+		next := middleware.NewSnowflake(42)
+		prev := int64(0)
+		for i := 0; i < 5000; i++ {
+			id, err := strconv.ParseInt(next(), 10, 64)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(id).Should(BeNumerically(">", prev))
+			Ω(id >> 12 & 0x3ff).Should(BeEquivalentTo(42))
+			prev = id
+		}
+	})
 })
 
 func makeRequestID(length int) string {
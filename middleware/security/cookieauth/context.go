@@ -0,0 +1,20 @@
+package cookieauth
+
+import "context"
+
+type contextKey int
+
+const (
+	sessionKey contextKey = iota + 1
+)
+
+// WithSession creates a child context containing the given session.
+func WithSession(ctx context.Context, session interface{}) context.Context {
+	return context.WithValue(ctx, sessionKey, session)
+}
+
+// ContextSession retrieves the session decoded by the cookie security middleware from the
+// context, nil if there is none.
+func ContextSession(ctx context.Context) interface{} {
+	return ctx.Value(sessionKey)
+}
@@ -0,0 +1,223 @@
+package cookieauth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+)
+
+// ErrCookieAuthFailed means the session cookie is missing, invalid or expired.
+var ErrCookieAuthFailed = goa.NewErrorClass("cookie_auth_failed", 401)
+
+// New returns a middleware to be used with the CookieSecurity DSL definitions of goa. The
+// middleware reads the session cookie, verifies its signature or decrypts it using the keys
+// returned by keys and decodes its content into the value returned by newSession. The session is
+// then stored in the request context where ContextSession retrieves it. The session is validated
+// if it implements a Validate method such as the generated user types.
+//
+// The generated code includes a NewXXMiddleware function that calls New with the session type
+// defined in the design, mount it with the generated UseXX function where XX is the name of the
+// scheme, e.g.:
+//
+//    app.UseSessionMiddleware(service, app.NewSessionMiddleware(keys))
+func New(scheme *goa.CookieSecurity, keys KeyProvider, newSession func() interface{}) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			c, err := req.Cookie(cookieName(scheme))
+			if err != nil {
+				return ErrCookieAuthFailed("missing session cookie")
+			}
+			session := newSession()
+			if err := Decode(ctx, scheme, keys, c.Value, session); err != nil {
+				return ErrCookieAuthFailed(err)
+			}
+			if v, ok := session.(interface {
+				Validate() error
+			}); ok {
+				if err := v.Validate(); err != nil {
+					return ErrCookieAuthFailed(err)
+				}
+			}
+			return nextHandler(WithSession(ctx, session), rw, req)
+		}
+	}
+}
+
+// SetCookie encodes the given session and stores it in the Set-Cookie header of the response.
+// The cookie expires after maxAge, it is a browser session cookie if maxAge is 0. The cookie is
+// only sent over HTTPS, is not readable by scripts and is not sent with cross-site requests
+// other than top level navigations.
+func SetCookie(ctx context.Context, rw http.ResponseWriter, scheme *goa.CookieSecurity, keys KeyProvider, session interface{}, maxAge time.Duration) error {
+	var expires time.Time
+	if maxAge > 0 {
+		expires = time.Now().Add(maxAge)
+	}
+	val, err := Encode(ctx, scheme, keys, session, expires)
+	if err != nil {
+		return err
+	}
+	c := &http.Cookie{
+		Name:     cookieName(scheme),
+		Value:    val,
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge > 0 {
+		c.Expires = expires
+		c.MaxAge = int(maxAge.Seconds())
+	}
+	http.SetCookie(rw, c)
+	return nil
+}
+
+// ClearCookie stores a Set-Cookie header in the response that deletes the session cookie.
+func ClearCookie(rw http.ResponseWriter, scheme *goa.CookieSecurity) {
+	http.SetCookie(rw, &http.Cookie{
+		Name:     cookieName(scheme),
+		Path:     "/",
+		Secure:   true,
+		HttpOnly: true,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+	})
+}
+
+// Encode returns the value of the session cookie holding the JSON representation of session.
+// The value consists of the current key ID, the expiry time (0 if the session does not expire)
+// and the session signed with a HMAC-SHA256 or, if the scheme is encrypted, encrypted with
+// AES-256-GCM. The cookie name is part of the signed data so that the value cannot be replayed in
+// other cookies.
+func Encode(ctx context.Context, scheme *goa.CookieSecurity, keys KeyProvider, session interface{}, expires time.Time) (string, error) {
+	id, key, err := keys.CurrentKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(id, ".") {
+		return "", errors.New("key IDs cannot contain dots")
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	var exp int64
+	if !expires.IsZero() {
+		exp = expires.Unix()
+	}
+	prefix := id + "." + strconv.FormatInt(exp, 10)
+	ad := []byte(cookieName(scheme) + "|" + prefix)
+	if scheme.Encrypted {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		sealed := aead.Seal(nonce, nonce, data, ad)
+		return prefix + "." + encode(sealed), nil
+	}
+	prefix += "." + encode(data)
+	return prefix + "." + encode(sign(key, cookieName(scheme)+"|"+prefix)), nil
+}
+
+// Decode verifies or decrypts the given session cookie value produced by Encode and decodes its
+// content into session.
+func Decode(ctx context.Context, scheme *goa.CookieSecurity, keys KeyProvider, val string, session interface{}) error {
+	parts := strings.Split(val, ".")
+	if scheme.Encrypted && len(parts) != 3 || !scheme.Encrypted && len(parts) != 4 {
+		return errors.New("malformed session cookie")
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return errors.New("malformed session cookie")
+	}
+	key, err := keys.Key(ctx, parts[0])
+	if err != nil {
+		return err
+	}
+	prefix := parts[0] + "." + parts[1]
+	var data []byte
+	if scheme.Encrypted {
+		sealed, err := decode(parts[2])
+		if err != nil {
+			return errors.New("malformed session cookie")
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return err
+		}
+		if len(sealed) < aead.NonceSize() {
+			return errors.New("malformed session cookie")
+		}
+		nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		ad := []byte(cookieName(scheme) + "|" + prefix)
+		if data, err = aead.Open(nil, nonce, sealed, ad); err != nil {
+			return errors.New("invalid session cookie")
+		}
+	} else {
+		sig, err := decode(parts[3])
+		if err != nil {
+			return errors.New("malformed session cookie")
+		}
+		if !hmac.Equal(sig, sign(key, cookieName(scheme)+"|"+prefix+"."+parts[2])) {
+			return errors.New("invalid session cookie signature")
+		}
+		if data, err = decode(parts[2]); err != nil {
+			return errors.New("malformed session cookie")
+		}
+	}
+	if exp != 0 && time.Now().Unix() > exp {
+		return errors.New("session expired")
+	}
+	return json.Unmarshal(data, session)
+}
+
+// cookieName returns the name of the session cookie of the given scheme.
+func cookieName(scheme *goa.CookieSecurity) string {
+	if scheme.Name == "" {
+		return "session"
+	}
+	return scheme.Name
+}
+
+// newAEAD returns the AES-256-GCM cipher using a key derived from the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	k := sha256.Sum256(append([]byte("goa-cookie-encryption|"), key...))
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sign returns the HMAC-SHA256 of val computed with key.
+func sign(key []byte, val string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(val))
+	return mac.Sum(nil)
+}
+
+// encode returns the URL safe base64 encoding of b without padding.
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode decodes the URL safe base64 encoded string s.
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,171 @@
+package cookieauth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/cookieauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type session struct {
+	UserID int `json:"user_id"`
+}
+
+func (s *session) Validate() error {
+	if s.UserID == 0 {
+		return errors.New("missing user")
+	}
+	return nil
+}
+
+var _ = Describe("Middleware", func() {
+	var (
+		scheme  *goa.CookieSecurity
+		keys    cookieauth.KeyProvider
+		value   string
+		req     *http.Request
+		decoded *session
+		err     error
+	)
+
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		decoded, _ = cookieauth.ContextSession(ctx).(*session)
+		return nil
+	}
+
+	newSession := func() interface{} { return &session{} }
+
+	BeforeEach(func() {
+		scheme = &goa.CookieSecurity{Name: "sid"}
+		keys = cookieauth.StaticKeys("k1", map[string][]byte{"k1": []byte("secret")})
+		decoded = nil
+		value = ""
+	})
+
+	JustBeforeEach(func() {
+		req, _ = http.NewRequest("GET", "http://example.com/", nil)
+		if value != "" {
+			req.AddCookie(&http.Cookie{Name: "sid", Value: value})
+		}
+		mw := cookieauth.New(scheme, keys, newSession)
+		err = mw(handler)(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	Context("with a signed session", func() {
+		BeforeEach(func() {
+			value, err = cookieauth.Encode(context.Background(), scheme, keys, &session{UserID: 42}, time.Time{})
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("stores the session in the context", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(&session{UserID: 42}))
+		})
+	})
+
+	Context("with an encrypted session", func() {
+		BeforeEach(func() {
+			scheme.Encrypted = true
+			value, err = cookieauth.Encode(context.Background(), scheme, keys, &session{UserID: 42}, time.Time{})
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("does not expose the session", func() {
+			Ω(value).ShouldNot(ContainSubstring("eyJ1c2VyX2lkIjo0Mn0"))
+		})
+
+		It("stores the session in the context", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(&session{UserID: 42}))
+		})
+	})
+
+	Context("with a tampered session", func() {
+		BeforeEach(func() {
+			v, _ := cookieauth.Encode(context.Background(), scheme, keys, &session{UserID: 42}, time.Time{})
+			parts := strings.Split(v, ".")
+			parts[2] = "eyJ1c2VyX2lkIjoxfQ"
+			value = strings.Join(parts, ".")
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(decoded).Should(BeNil())
+		})
+	})
+
+	Context("with an expired session", func() {
+		BeforeEach(func() {
+			value, _ = cookieauth.Encode(context.Background(), scheme, keys, &session{UserID: 42}, time.Now().Add(-time.Minute))
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("expired"))
+		})
+	})
+
+	Context("with a rotated key", func() {
+		BeforeEach(func() {
+			value, _ = cookieauth.Encode(context.Background(), scheme, keys, &session{UserID: 42}, time.Time{})
+			keys = cookieauth.StaticKeys("k2", map[string][]byte{"k1": []byte("secret"), "k2": []byte("other")})
+		})
+
+		It("accepts cookies issued with the previous key", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decoded).Should(Equal(&session{UserID: 42}))
+		})
+	})
+
+	Context("with an invalid session", func() {
+		BeforeEach(func() {
+			value, _ = cookieauth.Encode(context.Background(), scheme, keys, &session{}, time.Time{})
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("without cookie", func() {
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		})
+	})
+})
+
+var _ = Describe("SetCookie", func() {
+	It("issues a cookie read by the middleware", func() {
+		scheme := &goa.CookieSecurity{Name: "sid", Encrypted: true}
+		keys := cookieauth.StaticKeys("k1", map[string][]byte{"k1": []byte("secret")})
+		rw := httptest.NewRecorder()
+		err := cookieauth.SetCookie(context.Background(), rw, scheme, keys, &session{UserID: 7}, time.Hour)
+		Ω(err).ShouldNot(HaveOccurred())
+		cookies := (&http.Response{Header: rw.Header()}).Cookies()
+		Ω(cookies).Should(HaveLen(1))
+		Ω(cookies[0].Name).Should(Equal("sid"))
+		Ω(cookies[0].HttpOnly).Should(BeTrue())
+		Ω(cookies[0].Secure).Should(BeTrue())
+		Ω(cookies[0].MaxAge).Should(Equal(3600))
+
+		var s session
+		Ω(cookieauth.Decode(context.Background(), scheme, keys, cookies[0].Value, &s)).ShouldNot(HaveOccurred())
+		Ω(s.UserID).Should(Equal(7))
+	})
+
+	It("clears the cookie", func() {
+		rw := httptest.NewRecorder()
+		cookieauth.ClearCookie(rw, &goa.CookieSecurity{Name: "sid"})
+		Ω(rw.Header().Get("Set-Cookie")).Should(ContainSubstring("sid=;"))
+		Ω(rw.Header().Get("Set-Cookie")).Should(ContainSubstring("Max-Age=0"))
+	})
+})
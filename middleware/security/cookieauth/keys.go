@@ -0,0 +1,43 @@
+package cookieauth
+
+import (
+	"context"
+	"fmt"
+)
+
+// KeyProvider provides the keys used to sign and encrypt the session cookies. Keys are
+// identified so that new keys may be rotated in while the cookies issued with the previous keys
+// remain valid until they are retired.
+type KeyProvider interface {
+	// CurrentKey returns the ID and the value of the key used to issue new cookies.
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key returns the value of the key with the given ID used to read cookies.
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// staticKeys is a KeyProvider backed by a fixed set of keys.
+type staticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// StaticKeys returns a key provider that issues cookies with the key identified by current and
+// that reads cookies issued with any of the given keys. The key IDs must not contain dots.
+func StaticKeys(current string, keys map[string][]byte) KeyProvider {
+	return &staticKeys{current: current, keys: keys}
+}
+
+// CurrentKey returns the current key.
+func (s *staticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := s.Key(ctx, s.current)
+	return s.current, key, err
+}
+
+// Key returns the key with the given ID.
+func (s *staticKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %#v", id)
+	}
+	return key, nil
+}
@@ -0,0 +1,114 @@
+package hmacauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+)
+
+// ErrHMACAuthFailed means the request signature is missing or invalid.
+var ErrHMACAuthFailed = goa.NewErrorClass("hmac_auth_failed", 401)
+
+// MaxSkew is the maximum difference allowed between the time of signing and the time the request
+// is received.
+var MaxSkew = 15 * time.Minute
+
+// SecretFunc returns the secret associated with the given key ID.
+type SecretFunc func(ctx context.Context, keyID string) (string, error)
+
+// New returns a middleware to be used with the HMACSecurity DSL definitions of goa. The
+// middleware verifies the signatures computed by the goa client HMACSigner using the secret
+// returned by secrets for the key ID given in the request.
+//
+// Mount the middleware with the generated UseXX function where XX is the name of the scheme as
+// defined in the design, e.g.:
+//
+//    app.UseHMAC(hmacauth.New(app.NewHMACSecurity(), secrets))
+//
+// The middleware reads the raw request body to verify the signature. The generated code defers the
+// decoding of the body of the actions secured with a HMAC scheme until the middleware accepts the
+// request (see the "http:body:lazy" metadata), the middleware must therefore not be used with
+// handlers mounted with Controller.MuxHandler whose request bodies have already been consumed.
+func New(scheme *goa.HMACSecurity, secrets SecretFunc) goa.Middleware {
+	name := scheme.Name
+	if name == "" {
+		name = "Authorization"
+	}
+	middleware, _ := goa.NewMiddleware(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		keyID, signed, signature, ok := parseAuthorization(r.Header.Get(name))
+		if !ok {
+			return ErrHMACAuthFailed("missing or malformed signature")
+		}
+		date := r.Header.Get(client.HMACDateHeader)
+		t, err := time.Parse(client.HMACDateFormat, date)
+		if err != nil {
+			return ErrHMACAuthFailed("missing or invalid signature date")
+		}
+		if skew := time.Since(t); skew > MaxSkew || skew < -MaxSkew {
+			return ErrHMACAuthFailed("signature expired")
+		}
+		if !contains(signed, "host") || !contains(signed, strings.ToLower(client.HMACDateHeader)) {
+			return ErrHMACAuthFailed("signature must cover the host and date headers")
+		}
+		secret, err := secrets(ctx, keyID)
+		if err != nil {
+			return ErrHMACAuthFailed("unknown key", "key", keyID)
+		}
+		var body []byte
+		if r.Body != nil {
+			if body, err = ioutil.ReadAll(r.Body); err != nil {
+				return err
+			}
+			r.Body.Close()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		expected := client.HMACSignature(secret, date, client.CanonicalRequest(r, signed, body))
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			return ErrHMACAuthFailed("signature mismatch")
+		}
+		return nil
+	})
+	return middleware
+}
+
+// parseAuthorization parses the value of the signature header.
+func parseAuthorization(val string) (keyID string, signed []string, signature string, ok bool) {
+	prefix := client.HMACAlgorithm + " "
+	if !strings.HasPrefix(val, prefix) {
+		return
+	}
+	for _, part := range strings.Split(val[len(prefix):], ",") {
+		elems := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(elems) != 2 {
+			return
+		}
+		switch elems[0] {
+		case "KeyID":
+			keyID = elems[1]
+		case "SignedHeaders":
+			signed = strings.Split(elems[1], ";")
+		case "Signature":
+			signature = elems[1]
+		}
+	}
+	ok = keyID != "" && len(signed) > 0 && signature != ""
+	return
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package hmacauth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHMACSecurityMiddleware(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HMAC Security Middleware")
+}
@@ -0,0 +1,162 @@
+package hmacauth_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/client"
+	"github.com/goadesign/goa/middleware"
+	"github.com/goadesign/goa/middleware/security/hmacauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		req    *http.Request
+		signer *client.HMACSigner
+		called bool
+		err    error
+	)
+
+	secrets := func(ctx context.Context, keyID string) (string, error) {
+		if keyID != "key" {
+			return "", fmt.Errorf("unknown key %s", keyID)
+		}
+		return "secret", nil
+	}
+
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+
+	BeforeEach(func() {
+		called = false
+		req, _ = http.NewRequest("POST", "http://example.com/foo?b=2&a=1", strings.NewReader(`{"payload":42}`))
+		signer = &client.HMACSigner{KeyID: "key", Secret: "secret"}
+	})
+
+	JustBeforeEach(func() {
+		mw := hmacauth.New(&goa.HMACSecurity{}, secrets)
+		err = mw(handler)(context.Background(), httptest.NewRecorder(), req)
+	})
+
+	Context("with a valid signature", func() {
+		BeforeEach(func() {
+			Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+		})
+
+		It("calls the handler", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(called).Should(BeTrue())
+		})
+	})
+
+	Context("with a tampered body", func() {
+		BeforeEach(func() {
+			Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+			r, _ := http.NewRequest("POST", "http://example.com/foo?b=2&a=1", strings.NewReader(`{"payload":43}`))
+			r.Header = req.Header
+			req = r
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with an unknown key", func() {
+		BeforeEach(func() {
+			signer.KeyID = "other"
+			Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(called).Should(BeFalse())
+		})
+	})
+
+	Context("with no signature", func() {
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+			Ω(called).Should(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Mounted controller", func() {
+	var (
+		service *goa.Service
+		signer  *client.HMACSigner
+		rw      *httptest.ResponseRecorder
+		payload interface{}
+	)
+
+	secrets := func(ctx context.Context, keyID string) (string, error) {
+		return "secret", nil
+	}
+
+	unmarshal := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+		var p map[string]interface{}
+		if err := service.DecodeRequest(req, &p); err != nil {
+			return err
+		}
+		goa.ContextRequest(ctx).Payload = p
+		return nil
+	}
+
+	BeforeEach(func() {
+		payload = nil
+		service = goa.New("test")
+		service.Decoder.Register(goa.NewJSONDecoder, "*/*")
+		service.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		service.Use(middleware.ErrorHandler(service, false))
+		signer = &client.HMACSigner{KeyID: "key", Secret: "secret"}
+		rw = httptest.NewRecorder()
+
+		// Mount the handler like the code generated for a HMAC secured action does.
+		ctrl := service.NewController("BottleController")
+		var h goa.Handler = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if err := goa.ContextError(ctx); err != nil {
+				return err
+			}
+			if err := goa.LoadRequestBody(ctx); err != nil {
+				return err
+			}
+			payload = goa.ContextRequest(ctx).Payload
+			rw.WriteHeader(201)
+			return nil
+		}
+		h = hmacauth.New(&goa.HMACSecurity{}, secrets)(h)
+		service.Mux.Handle("POST", "/bottles", ctrl.LazyMuxHandler("create", h, unmarshal))
+	})
+
+	It("verifies the signature of the body and decodes it", func() {
+		req, _ := http.NewRequest("POST", "http://example.com/bottles", strings.NewReader(`{"name":"sweet"}`))
+		req.Header.Set("Content-Type", "application/json")
+		Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+		service.Mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(201))
+		Ω(payload).Should(Equal(map[string]interface{}{"name": "sweet"}))
+	})
+
+	It("rejects a tampered body without decoding it", func() {
+		req, _ := http.NewRequest("POST", "http://example.com/bottles", strings.NewReader(`{"name":"sweet"}`))
+		req.Header.Set("Content-Type", "application/json")
+		Ω(signer.Sign(req)).ShouldNot(HaveOccurred())
+		tampered, _ := http.NewRequest("POST", "http://example.com/bottles", strings.NewReader(`{"name":"sour"}`))
+		tampered.Header = req.Header
+		service.Mux.ServeHTTP(rw, tampered)
+		Ω(rw.Code).Should(Equal(401))
+		Ω(payload).Should(BeNil())
+	})
+})
@@ -0,0 +1,106 @@
+package spiffeauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/goadesign/goa"
+)
+
+var (
+	// ErrSPIFFEAuthFailed means the client certificate is missing or does not hold a SPIFFE ID
+	// of the scheme trust domain.
+	ErrSPIFFEAuthFailed = goa.NewErrorClass("spiffe_auth_failed", 401)
+
+	// ErrSPIFFEForbidden means the SPIFFE ID of the client is not granted the scopes required by
+	// the action.
+	ErrSPIFFEForbidden = goa.NewErrorClass("spiffe_forbidden", 403)
+)
+
+type contextKey int
+
+const (
+	idKey contextKey = iota + 1
+)
+
+// New returns a middleware to be used with the SPIFFESecurity DSL definitions of goa. The
+// middleware reads the SPIFFE ID of the client from the URI SAN of the certificate presented
+// during the TLS handshake, checks that it belongs to the scheme trust domain and that the scopes
+// granted to it in the design include the scopes required by the action. The ID is then stored
+// in the request context where ContextID retrieves it.
+//
+// The service must be served over TLS and request the client certificates, e.g. with a
+// tls.Config whose ClientAuth is tls.RequireAndVerifyClientCert and whose ClientCAs holds the
+// trust bundle of the trust domain.
+//
+// The generated code includes a NewXXMiddleware function that calls New with the scheme defined
+// in the design, mount it with the generated UseXX function where XX is the name of the scheme,
+// e.g.:
+//
+//    app.UseWorkloadMiddleware(service, app.NewWorkloadMiddleware())
+func New(scheme *goa.SPIFFESecurity) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			id, err := PeerID(req)
+			if err != nil {
+				return ErrSPIFFEAuthFailed(err)
+			}
+			if id.Host != scheme.TrustDomain {
+				return ErrSPIFFEAuthFailed("untrusted domain", "id", id.String())
+			}
+			granted := scheme.Identities[id.String()]
+			for _, scope := range goa.ContextRequiredScopes(ctx) {
+				if !contains(granted, scope) {
+					return ErrSPIFFEForbidden("missing scope", "id", id.String(), "scope", scope)
+				}
+			}
+			return nextHandler(WithID(ctx, id.String()), rw, req)
+		}
+	}
+}
+
+// PeerID returns the SPIFFE ID held in the URI SAN of the certificate presented by the client.
+// The certificate chain must have been verified by the TLS server.
+func PeerID(req *http.Request) (*url.URL, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil, errors.New("missing client certificate")
+	}
+	var id *url.URL
+	for _, u := range req.TLS.PeerCertificates[0].URIs {
+		if u.Scheme != "spiffe" {
+			continue
+		}
+		if id != nil {
+			return nil, errors.New("client certificate holds more than one SPIFFE ID")
+		}
+		id = u
+	}
+	if id == nil || id.Host == "" {
+		return nil, errors.New("client certificate does not hold a SPIFFE ID")
+	}
+	return id, nil
+}
+
+// WithID creates a child context containing the given SPIFFE ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// ContextID retrieves the SPIFFE ID of the client authorized by the SPIFFE security middleware
+// from the context, an empty string if there is none.
+func ContextID(ctx context.Context) string {
+	id, _ := ctx.Value(idKey).(string)
+	return id
+}
+
+// contains returns true if vals contains val.
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package spiffeauth_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSPIFFESecurityMiddleware(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SPIFFE Security Middleware")
+}
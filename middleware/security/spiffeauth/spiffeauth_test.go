@@ -0,0 +1,94 @@
+package spiffeauth_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware/security/spiffeauth"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Middleware", func() {
+	var (
+		req    *http.Request
+		scopes []string
+		id     string
+		err    error
+	)
+
+	scheme := &goa.SPIFFESecurity{
+		TrustDomain: "example.org",
+		Identities: map[string][]string{
+			"spiffe://example.org/ns/prod/sa/frontend": {"bottle:read"},
+		},
+	}
+
+	handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		id = spiffeauth.ContextID(ctx)
+		return nil
+	}
+
+	peer := func(uri string) *tls.ConnectionState {
+		u, _ := url.Parse(uri)
+		return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{URIs: []*url.URL{u}}}}
+	}
+
+	BeforeEach(func() {
+		id = ""
+		scopes = []string{"bottle:read"}
+		req, _ = http.NewRequest("GET", "https://example.com/bottles", nil)
+	})
+
+	JustBeforeEach(func() {
+		ctx := goa.WithRequiredScopes(context.Background(), scopes)
+		err = spiffeauth.New(scheme)(handler)(ctx, httptest.NewRecorder(), req)
+	})
+
+	Context("with an authorized identity", func() {
+		BeforeEach(func() {
+			req.TLS = peer("spiffe://example.org/ns/prod/sa/frontend")
+		})
+
+		It("calls the handler with the ID", func() {
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(id).Should(Equal("spiffe://example.org/ns/prod/sa/frontend"))
+		})
+
+		Context("missing the required scope", func() {
+			BeforeEach(func() {
+				scopes = []string{"bottle:write"}
+			})
+
+			It("forbids the request", func() {
+				Ω(err).Should(HaveOccurred())
+				Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+				Ω(id).Should(BeEmpty())
+			})
+		})
+	})
+
+	Context("with an identity of another trust domain", func() {
+		BeforeEach(func() {
+			req.TLS = peer("spiffe://other.org/ns/prod/sa/frontend")
+		})
+
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		})
+	})
+
+	Context("without client certificate", func() {
+		It("rejects the request", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(401))
+		})
+	})
+})
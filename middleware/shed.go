@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// LoadSignal returns the current load of the service as a number between 0 and 1, e.g. the CPU
+// utilization or the ratio of in-flight requests to the service capacity.
+type LoadSignal func() float64
+
+var (
+	// DefaultLoadSignal is the load signal used by the code generated for the actions that
+	// define a priority, see the Priority DSL. It must be set before the controllers are
+	// mounted, no request is shed if nil.
+	DefaultLoadSignal LoadSignal
+
+	// DefaultShedRetryAfter is the delay sent in the Retry-After header of the responses to
+	// the requests shed by the code generated for the actions that define a priority.
+	DefaultShedRetryAfter = 5 * time.Second
+)
+
+// Shed returns a middleware that rejects the requests whose priority level threshold is exceeded
+// by the load returned by signal. The priority level is read from the given header, the requests
+// that do not set the header get defaultLevel. thresholds maps the priority levels to the load
+// above which the requests are rejected, the requests with an unknown level get the lowest
+// threshold. Rejected requests get a goa.ErrServiceUnavailable error and a Retry-After header set
+// to retryAfter rounded up to the second. Shed does nothing if signal is nil.
+func Shed(signal LoadSignal, retryAfter time.Duration, header, defaultLevel string, thresholds map[string]float64) goa.Middleware {
+	lowest := math.Inf(1)
+	for _, t := range thresholds {
+		if t < lowest {
+			lowest = t
+		}
+	}
+	seconds := strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))
+	return func(h goa.Handler) goa.Handler {
+		if signal == nil {
+			return h
+		}
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			level := req.Header.Get(header)
+			if level == "" {
+				level = defaultLevel
+			}
+			threshold, ok := thresholds[level]
+			if !ok {
+				threshold = lowest
+			}
+			if load := signal(); load > threshold {
+				rw.Header().Set("Retry-After", seconds)
+				return goa.ErrServiceUnavailable("service overloaded, retry later", "priority", level, "load", load)
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
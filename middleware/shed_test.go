@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shed", func() {
+	var ctx context.Context
+	var rw *testResponseWriter
+	var req *http.Request
+	var load float64
+	var called bool
+
+	thresholds := map[string]float64{"low": 0.5, "normal": 0.8, "critical": 1}
+
+	h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		called = true
+		return nil
+	}
+	signal := func() float64 { return load }
+
+	BeforeEach(func() {
+		service := newService(nil)
+		var err error
+		req, err = http.NewRequest("POST", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = newTestResponseWriter()
+		ctx = newContext(service, rw, req, nil)
+		called = false
+	})
+
+	shed := func() error {
+		return middleware.Shed(signal, 1500*time.Millisecond, "X-Priority", "normal", thresholds)(h)(ctx, rw, req)
+	}
+
+	It("lets the requests through while the load is below the threshold", func() {
+		load = 0.6
+		req.Header.Set("X-Priority", "normal")
+		Ω(shed()).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("rejects the requests whose threshold is exceeded", func() {
+		load = 0.6
+		req.Header.Set("X-Priority", "low")
+		err := shed()
+		Ω(err).Should(HaveOccurred())
+		var se goa.ServiceError
+		Ω(errors.As(err, &se)).Should(BeTrue())
+		Ω(se.ResponseStatus()).Should(Equal(503))
+		Ω(rw.Header().Get("Retry-After")).Should(Equal("2"))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("uses the default level for the requests that do not set the header", func() {
+		load = 0.9
+		Ω(shed()).Should(HaveOccurred())
+		load = 0.7
+		Ω(shed()).ShouldNot(HaveOccurred())
+	})
+
+	It("uses the lowest threshold for unknown levels", func() {
+		load = 0.6
+		req.Header.Set("X-Priority", "unknown")
+		Ω(shed()).Should(HaveOccurred())
+	})
+
+	It("does nothing without load signal", func() {
+		Ω(middleware.Shed(nil, time.Second, "X-Priority", "", thresholds)(h)(ctx, rw, req)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+})
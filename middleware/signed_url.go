@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+)
+
+// ErrInvalidSignature is the error returned by the VerifySignedURL middleware when the request URL
+// is not signed, the signature does not match or the URL expired.
+var ErrInvalidSignature = goa.NewErrorClass("invalid_signature", 403)
+
+// DefaultURLSigningKey is the key used by the code generated for the signed file servers to verify
+// the request URLs, see the SignedURL DSL. It must be set before the controllers are mounted, all
+// the requests are rejected if it is empty.
+var DefaultURLSigningKey []byte
+
+// SignURL returns the URL made of the escaped path and of the query string that signs it with key
+// until expires. The signature is a HMAC-SHA256 of the path and of the expiry time.
+func SignURL(key []byte, path string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{
+		"expires":   []string{exp},
+		"signature": []string{urlSignature(key, path, exp)},
+	}
+	u := url.URL{Path: path, RawQuery: q.Encode()}
+	return u.String()
+}
+
+// VerifySignedURL creates a middleware that rejects the requests whose URL was not signed with key
+// by SignURL or expired.
+func VerifySignedURL(key []byte) goa.Middleware {
+	return func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if len(key) == 0 {
+				return ErrInvalidSignature("no URL signing key")
+			}
+			q := req.URL.Query()
+			exp := q.Get("expires")
+			expires, err := strconv.ParseInt(exp, 10, 64)
+			if err != nil {
+				return ErrInvalidSignature("missing or invalid expiry time")
+			}
+			if time.Now().Unix() > expires {
+				return ErrInvalidSignature("URL expired")
+			}
+			sig := q.Get("signature")
+			if !hmac.Equal([]byte(sig), []byte(urlSignature(key, req.URL.Path, exp))) {
+				return ErrInvalidSignature("invalid signature")
+			}
+			return h(ctx, rw, req)
+		}
+	}
+}
+
+// urlSignature computes the signature of the given path and expiry time.
+func urlSignature(key []byte, path, expires string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(path))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"context"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VerifySignedURL", func() {
+	var key []byte
+	var called bool
+	var h goa.Handler
+
+	BeforeEach(func() {
+		key = []byte("secret")
+		called = false
+		h = func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			called = true
+			return nil
+		}
+	})
+
+	verify := func(u string) error {
+		req, err := http.NewRequest("GET", u, nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw := newTestResponseWriter()
+		ctx := newContext(newService(nil), rw, req, nil)
+		return middleware.VerifySignedURL(key)(h)(ctx, rw, req)
+	}
+
+	It("accepts signed URLs", func() {
+		u := middleware.SignURL(key, "/downloads/a.txt", time.Now().Add(time.Minute))
+		Ω(verify(u)).ShouldNot(HaveOccurred())
+		Ω(called).Should(BeTrue())
+	})
+
+	It("accepts signed URLs of paths that need escaping", func() {
+		u := middleware.SignURL(key, "/downloads/a b.txt", time.Now().Add(time.Minute))
+		Ω(verify(u)).ShouldNot(HaveOccurred())
+	})
+
+	It("rejects expired URLs", func() {
+		u := middleware.SignURL(key, "/downloads/a.txt", time.Now().Add(-time.Minute))
+		err := verify(u)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(403))
+		Ω(called).Should(BeFalse())
+	})
+
+	It("rejects URLs signed for another path", func() {
+		u, err := url.Parse(middleware.SignURL(key, "/downloads/a.txt", time.Now().Add(time.Minute)))
+		Ω(err).ShouldNot(HaveOccurred())
+		u.Path = "/downloads/b.txt"
+		Ω(verify(u.String())).Should(HaveOccurred())
+		Ω(called).Should(BeFalse())
+	})
+
+	It("rejects URLs signed with another key", func() {
+		u := middleware.SignURL([]byte("other"), "/downloads/a.txt", time.Now().Add(time.Minute))
+		Ω(verify(u)).Should(HaveOccurred())
+	})
+
+	It("rejects all the requests without a key", func() {
+		u := middleware.SignURL(key, "/downloads/a.txt", time.Now().Add(time.Minute))
+		key = nil
+		Ω(verify(u)).Should(HaveOccurred())
+		Ω(called).Should(BeFalse())
+	})
+})
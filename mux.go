@@ -1,6 +1,7 @@
 package goa
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 
@@ -42,6 +43,18 @@ type (
 		MuxHandler(string, Handler, Unmarshaler) MuxHandler
 	}
 
+	// RawMuxer is the adapter used to mount the handlers of the actions given the untouched
+	// request, see Controller.RawMuxHandler.
+	RawMuxer interface {
+		RawMuxHandler(string, Handler) MuxHandler
+	}
+
+	// LazyMuxer is the adapter used to mount the handlers of the actions that defer the decoding
+	// of the request body, see Controller.LazyMuxHandler.
+	LazyMuxer interface {
+		LazyMuxHandler(string, Handler, Unmarshaler) MuxHandler
+	}
+
 	// mux is the default ServeMux implementation.
 	mux struct {
 		router  *httptreemux.TreeMux
@@ -61,11 +74,13 @@ func NewMux() ServeMux {
 
 // Handle sets the handler for the given verb and path.
 func (m *mux) Handle(method, path string, handle MuxHandler) {
+	route := method + " " + path
 	hthandle := func(rw http.ResponseWriter, req *http.Request, htparams map[string]string) {
 		params := req.URL.Query()
 		for n, p := range htparams {
 			params.Set(n, p)
 		}
+		req = req.WithContext(context.WithValue(req.Context(), routeKey, route))
 		handle(rw, req, params)
 	}
 	m.handles[method+path] = handle
@@ -42,7 +42,7 @@ var _ = Describe("Mux", func() {
 		const reqPath = "/foo"
 		const reqBody = "some body"
 
-		var readMeth, readPath, readBody string
+		var readMeth, readPath, readBody, readRoute string
 
 		BeforeEach(func() {
 			var body bytes.Buffer
@@ -56,6 +56,7 @@ var _ = Describe("Mux", func() {
 				readPath = req.URL.Path
 				readMeth = req.Method
 				readBody = string(b)
+				readRoute = goa.ContextRequestInfo(goa.NewContext(nil, rw, req, vals)).Route
 			})
 		})
 
@@ -64,6 +65,10 @@ var _ = Describe("Mux", func() {
 			Ω(readPath).Should(Equal(reqPath))
 			Ω(readBody).Should(Equal(reqBody))
 		})
+
+		It("records the route in the request info", func() {
+			Ω(readRoute).Should(Equal("POST /foo"))
+		})
 	})
 
 	Context("with registered handlers and wrong method", func() {
@@ -0,0 +1,79 @@
+package goa
+
+import "strings"
+
+// ParamIterator iterates over the values of an array query string parameter whose elements are
+// joined with a separator. The values are substrings of the raw parameter values so that the
+// code generated by goagen parses them directly into the context fields without building an
+// intermediate slice. Iterating does not allocate.
+//
+//        it := goa.NewParamIterator(req.Params["ids"], ",")
+//        ids := make([]int, it.Len())
+//        for i := 0; it.Next(); i++ {
+//                ids[i], err = strconv.Atoi(it.Value())
+//        }
+type ParamIterator struct {
+	vals []string
+	sep  string
+	rest string
+	val  string
+	open bool
+}
+
+// NewParamIterator returns an iterator over the elements of vals split with sep. An empty sep
+// iterates over vals unchanged. The elements are identical to those strings.Split returns for
+// each value.
+func NewParamIterator(vals []string, sep string) ParamIterator {
+	return ParamIterator{vals: vals, sep: sep}
+}
+
+// Len returns the number of elements left to iterate over.
+func (it *ParamIterator) Len() int {
+	n := len(it.vals)
+	if it.sep != "" {
+		for _, v := range it.vals {
+			n += strings.Count(v, it.sep)
+		}
+	}
+	if it.open {
+		n++
+		if it.sep != "" {
+			n += strings.Count(it.rest, it.sep)
+		}
+	}
+	return n
+}
+
+// Next advances the iterator to the next element and returns false if there is none.
+func (it *ParamIterator) Next() bool {
+	if !it.open {
+		if len(it.vals) == 0 {
+			return false
+		}
+		it.rest, it.vals, it.open = it.vals[0], it.vals[1:], true
+	}
+	if it.sep != "" {
+		if i := strings.Index(it.rest, it.sep); i >= 0 {
+			it.val, it.rest = it.rest[:i], it.rest[i+len(it.sep):]
+			return true
+		}
+	}
+	it.val, it.rest, it.open = it.rest, "", false
+	return true
+}
+
+// Value returns the current element.
+func (it *ParamIterator) Value() string {
+	return it.val
+}
+
+// SplitParam returns the elements of vals split with sep. The returned slice is the only
+// allocation, its elements share the memory of vals.
+func SplitParam(vals []string, sep string) []string {
+	it := NewParamIterator(vals, sep)
+	res := make([]string, it.Len())
+	for i := 0; it.Next(); i++ {
+		res[i] = it.Value()
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package goa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParamIterator", func() {
+	var vals []string
+	var sep string
+
+	var elems []string
+	var lens []int
+
+	JustBeforeEach(func() {
+		elems, lens = nil, nil
+		it := goa.NewParamIterator(vals, sep)
+		lens = append(lens, it.Len())
+		for it.Next() {
+			elems = append(elems, it.Value())
+			lens = append(lens, it.Len())
+		}
+	})
+
+	Context("with a separator", func() {
+		BeforeEach(func() {
+			vals = []string{"1,2", "", "3,,4", "5"}
+			sep = ","
+		})
+
+		It("iterates over the same elements as strings.Split", func() {
+			var expected []string
+			for _, v := range vals {
+				expected = append(expected, strings.Split(v, sep)...)
+			}
+			Ω(elems).Should(Equal(expected))
+		})
+
+		It("counts the elements left", func() {
+			Ω(lens).Should(Equal([]int{7, 6, 5, 4, 3, 2, 1, 0}))
+		})
+	})
+
+	Context("with a multi-byte separator", func() {
+		BeforeEach(func() {
+			vals = []string{"a::b::", "c"}
+			sep = "::"
+		})
+
+		It("splits the values", func() {
+			Ω(elems).Should(Equal([]string{"a", "b", "", "c"}))
+			Ω(lens[0]).Should(Equal(4))
+		})
+	})
+
+	Context("with no separator", func() {
+		BeforeEach(func() {
+			vals = []string{"a,b", "c"}
+			sep = ""
+		})
+
+		It("iterates over the values", func() {
+			Ω(elems).Should(Equal(vals))
+			Ω(lens).Should(Equal([]int{2, 1, 0}))
+		})
+	})
+
+	Context("with no value", func() {
+		BeforeEach(func() {
+			vals = nil
+			sep = ","
+		})
+
+		It("does not iterate", func() {
+			Ω(elems).Should(BeEmpty())
+			Ω(lens).Should(Equal([]int{0}))
+		})
+	})
+})
+
+var _ = Describe("SplitParam", func() {
+	It("splits all the values", func() {
+		Ω(goa.SplitParam([]string{"a|b", "c"}, "|")).Should(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("allocates the result only", func() {
+		vals := []string{"1,2,3,4,5,6,7,8", "9,10"}
+		Ω(testing.AllocsPerRun(10, func() { goa.SplitParam(vals, ",") })).Should(BeNumerically("==", 1))
+	})
+})
+
+var benchParam = []string{"1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16", "17,18,19,20"}
+
+// BenchmarkSplitParam measures the splitting of array parameter values with SplitParam.
+func BenchmarkSplitParam(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		goa.SplitParam(benchParam, ",")
+	}
+}
+
+// BenchmarkSplitParamAppend measures the splitting of array parameter values by appending the
+// results of strings.Split, the approach SplitParam replaces.
+func BenchmarkSplitParamAppend(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var vals []string
+		for _, v := range benchParam {
+			vals = append(vals, strings.Split(v, ",")...)
+		}
+	}
+}
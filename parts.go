@@ -0,0 +1,97 @@
+package goa
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+)
+
+type (
+	// PartWriter writes the parts of a multipart/mixed response one at a time, each part is
+	// flushed so that the client receives it right away. The generated contexts of the actions
+	// whose responses define parts wrap it in writers with a method per part.
+	PartWriter struct {
+		ctx     context.Context
+		status  int
+		mw      *multipart.Writer
+		started bool
+	}
+
+	// streamWriter writes to the response of the request handled with ctx using StreamWrite.
+	streamWriter struct {
+		ctx context.Context
+	}
+)
+
+// NewPartWriter returns a writer for the multipart/mixed response with the given status of the
+// request handled with ctx. The status and headers are written with the first part.
+func NewPartWriter(ctx context.Context, status int) *PartWriter {
+	return &PartWriter{
+		ctx:    ctx,
+		status: status,
+		mw:     multipart.NewWriter(streamWriter{ctx: ctx}),
+	}
+}
+
+// WriteJSON writes a part with the given name containing the JSON encoding of v.
+func (w *PartWriter) WriteJSON(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.WritePart(name, "application/json", b)
+}
+
+// WritePart writes a part with the given name, content type and content.
+func (w *PartWriter) WritePart(name, contentType string, b []byte) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"name": name}))
+	pw, err := w.mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = pw.Write(b)
+	return err
+}
+
+// Close writes the closing boundary of the response, it must be called once all the parts are
+// written.
+func (w *PartWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	return w.mw.Close()
+}
+
+// start writes the response status and headers if not already written.
+func (w *PartWriter) start() error {
+	if w.started {
+		return nil
+	}
+	if err := ClientClosed(w.ctx); err != nil {
+		return err
+	}
+	resp := ContextResponse(w.ctx)
+	if resp == nil {
+		return errors.New("no response data in context")
+	}
+	resp.Header().Set("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": w.mw.Boundary()}))
+	resp.WriteHeader(w.status)
+	w.started = true
+	return nil
+}
+
+// Write writes b to the response and flushes it.
+func (s streamWriter) Write(b []byte) (int, error) {
+	if err := StreamWrite(s.ctx, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -0,0 +1,63 @@
+package goa_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PartWriter", func() {
+	var rw *TestResponseWriter
+	var w *goa.PartWriter
+
+	BeforeEach(func() {
+		r, err := http.NewRequest("GET", "/bottles/batch", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		ctx := goa.NewContext(context.Background(), rw, r, nil)
+		w = goa.NewPartWriter(ctx, 200)
+	})
+
+	It("writes a multipart/mixed response", func() {
+		Ω(w.WriteJSON("bottle", map[string]string{"name": "foo"})).ShouldNot(HaveOccurred())
+		Ω(w.WritePart("label", "image/png", []byte("png"))).ShouldNot(HaveOccurred())
+		Ω(w.Close()).ShouldNot(HaveOccurred())
+
+		Ω(rw.Status).Should(Equal(200))
+		mt, params, err := mime.ParseMediaType(rw.ParentHeader.Get("Content-Type"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(mt).Should(Equal("multipart/mixed"))
+		mr := multipart.NewReader(bytes.NewReader(rw.Body), params["boundary"])
+
+		p, err := mr.NextPart()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Header.Get("Content-Type")).Should(Equal("application/json"))
+		Ω(p.Header.Get("Content-Disposition")).Should(Equal(`inline; name=bottle`))
+		b, err := ioutil.ReadAll(p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(MatchJSON(`{"name":"foo"}`))
+
+		p, err = mr.NextPart()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(p.Header.Get("Content-Type")).Should(Equal("image/png"))
+		b, err = ioutil.ReadAll(p)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal("png"))
+
+		_, err = mr.NextPart()
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("writes an empty response when closed without parts", func() {
+		Ω(w.Close()).ShouldNot(HaveOccurred())
+		Ω(rw.Status).Should(Equal(200))
+		Ω(rw.ParentHeader.Get("Content-Type")).Should(HavePrefix("multipart/mixed; boundary="))
+	})
+})
@@ -0,0 +1,50 @@
+package goa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// RouteInfo describes a route mounted by the generated code. goagen generates the Routes
+// variable of the app package which lists the routes of all the resource actions and file
+// servers so that operators and middleware can introspect what the service exposes.
+type RouteInfo struct {
+	// Method is the HTTP method of the route.
+	Method string `json:"method"`
+	// Pattern is the path of the route as given to the service mux, e.g. "/bottles/:id".
+	Pattern string `json:"pattern"`
+	// Service is the name of the API.
+	Service string `json:"service"`
+	// Resource is the name of the resource that defines the endpoint.
+	Resource string `json:"resource"`
+	// Endpoint is the name of the handler as given to MuxHandler, e.g. "show" or
+	// "create batch".
+	Endpoint string `json:"endpoint"`
+	// Scheme is the name of the security scheme that protects the route if any.
+	Scheme string `json:"scheme,omitempty"`
+	// Scopes lists the scopes required to access the route.
+	Scopes []string `json:"scopes,omitempty"`
+	// Flag is the name of the feature flag that gates the route if any.
+	Flag string `json:"flag,omitempty"`
+	// Middleware lists the names of the middleware applied to the route in order, see the Use
+	// DSL.
+	Middleware []string `json:"middleware,omitempty"`
+}
+
+// MountRoutes mounts a handler that renders the given routes as JSON in response to the GET
+// requests sent to path. Like the health check endpoints the handler bypasses the service
+// middleware, it is meant to be mounted on a debug or an internal listener.
+func (service *Service) MountRoutes(path string, routes []*RouteInfo) {
+	body, err := json.Marshal(routes)
+	if err != nil {
+		service.LogError("routes", "err", err)
+		return
+	}
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, _ *http.Request, _ url.Values) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+		rw.Write(body)
+	})
+	service.LogInfo("mount", "routes", path, "route", "GET "+path)
+}
@@ -0,0 +1,58 @@
+package goa
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// SanitizeTrim is the name of the sanitizer that removes the leading and trailing white
+	// space.
+	SanitizeTrim = "trim"
+	// SanitizeLowercase is the name of the sanitizer that maps the letters to lower case.
+	SanitizeLowercase = "lowercase"
+	// SanitizeUppercase is the name of the sanitizer that maps the letters to upper case.
+	SanitizeUppercase = "uppercase"
+	// SanitizeCollapse is the name of the sanitizer that replaces the runs of white space with
+	// a single space and removes the leading and trailing white space.
+	SanitizeCollapse = "collapse"
+)
+
+// sanitizers holds the sanitizer functions indexed by name.
+var sanitizers = struct {
+	sync.RWMutex
+	funcs map[string]func(string) string
+}{funcs: map[string]func(string) string{
+	SanitizeTrim:      strings.TrimSpace,
+	SanitizeLowercase: strings.ToLower,
+	SanitizeUppercase: strings.ToUpper,
+	SanitizeCollapse:  func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}}
+
+// RegisterSanitizer registers the sanitizer function with the given name so that the design
+// attributes may refer to it with the Sanitize DSL. Custom sanitizers must be registered before
+// the service handles requests, typically in an init function. Registering a sanitizer with the
+// name of an existing one replaces it.
+func RegisterSanitizer(name string, fn func(string) string) {
+	sanitizers.Lock()
+	defer sanitizers.Unlock()
+	sanitizers.funcs[name] = fn
+}
+
+// Sanitize applies the sanitizers with the given names to s in order and returns the result. The
+// code generated by goagen calls Sanitize when decoding the request params, headers and payloads
+// whose attributes define sanitizers in the design, before running the validations. Sanitize
+// panics if a sanitizer is not registered.
+func Sanitize(s string, names ...string) string {
+	sanitizers.RLock()
+	defer sanitizers.RUnlock()
+	for _, n := range names {
+		fn, ok := sanitizers.funcs[n]
+		if !ok {
+			panic(fmt.Sprintf("goa: unknown sanitizer %q, use RegisterSanitizer to register it", n))
+		}
+		s = fn(s)
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package goa_test
+
+import (
+	"strings"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sanitize", func() {
+	It("applies the built-in sanitizers in order", func() {
+		Ω(goa.Sanitize("  Foo  ", goa.SanitizeTrim)).Should(Equal("Foo"))
+		Ω(goa.Sanitize(" Foo \t Bar ", goa.SanitizeCollapse, goa.SanitizeLowercase)).Should(Equal("foo bar"))
+		Ω(goa.Sanitize("foo", goa.SanitizeUppercase)).Should(Equal("FOO"))
+	})
+
+	It("returns the value unchanged when no sanitizer is given", func() {
+		Ω(goa.Sanitize(" foo ")).Should(Equal(" foo "))
+	})
+
+	Context("with a registered sanitizer", func() {
+		BeforeEach(func() {
+			goa.RegisterSanitizer("slug", func(s string) string {
+				return strings.Replace(s, " ", "-", -1)
+			})
+		})
+
+		It("applies it", func() {
+			Ω(goa.Sanitize(" Foo Bar ", goa.SanitizeTrim, goa.SanitizeLowercase, "slug")).Should(Equal("foo-bar"))
+		})
+	})
+
+	It("panics on unknown sanitizers", func() {
+		Ω(func() { goa.Sanitize("foo", "unknown") }).Should(Panic())
+	})
+})
@@ -0,0 +1,69 @@
+package goa
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SchemaVersionParam is the name of the media type parameter that selects the schema version of
+// the request and response bodies when the action does not define a version header, e.g.
+// "application/json; version=1".
+const SchemaVersionParam = "version"
+
+// RequestSchemaVersion returns the schema version of the request body: the value of the given
+// header if not empty and present in the request, the version parameter of the Content-Type header
+// otherwise. It returns an empty string if the request does not specify a version.
+func RequestSchemaVersion(req *http.Request, header string) string {
+	if header != "" {
+		if v := req.Header.Get(header); v != "" {
+			return v
+		}
+	}
+	return mediaTypeVersion(req.Header.Get("Content-Type"))
+}
+
+// ResponseSchemaVersion returns the schema version requested for the response body: the value of
+// the given header if not empty and present in the request, the version parameter of the first
+// media range of the Accept header that defines one otherwise. It returns an empty string if the
+// request does not specify a version.
+func ResponseSchemaVersion(req *http.Request, header string) string {
+	if header != "" {
+		if v := req.Header.Get(header); v != "" {
+			return v
+		}
+	}
+	for _, accept := range req.Header["Accept"] {
+		for _, r := range strings.Split(accept, ",") {
+			if v := mediaTypeVersion(r); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// ConvertSchema sets the fields of dst, a pointer, from the fields of src with the same JSON name
+// by encoding src to JSON and decoding the result into dst. The generated code uses it as the
+// default conversion between the schema versions of a payload or response body and the type used
+// by the service.
+func ConvertSchema(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// mediaTypeVersion returns the value of the version parameter of the given media type if any.
+func mediaTypeVersion(mediaType string) string {
+	if mediaType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return ""
+	}
+	return params[SchemaVersionParam]
+}
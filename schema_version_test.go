@@ -0,0 +1,67 @@
+package goa_test
+
+import (
+	"net/http"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("schema versions", func() {
+	var req *http.Request
+
+	BeforeEach(func() {
+		var err error
+		req, err = http.NewRequest("POST", "/bottles", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	Context("RequestSchemaVersion", func() {
+		It("reads the version header", func() {
+			req.Header.Set("X-Schema-Version", "2")
+			req.Header.Set("Content-Type", "application/json; version=1")
+			Ω(goa.RequestSchemaVersion(req, "X-Schema-Version")).Should(Equal("2"))
+		})
+
+		It("falls back to the content type parameter", func() {
+			req.Header.Set("Content-Type", "application/json; version=1")
+			Ω(goa.RequestSchemaVersion(req, "X-Schema-Version")).Should(Equal("1"))
+			Ω(goa.RequestSchemaVersion(req, "")).Should(Equal("1"))
+		})
+
+		It("returns an empty string when no version is set", func() {
+			req.Header.Set("Content-Type", "application/json")
+			Ω(goa.RequestSchemaVersion(req, "X-Schema-Version")).Should(BeEmpty())
+		})
+	})
+
+	Context("ResponseSchemaVersion", func() {
+		It("reads the first accepted media range with a version", func() {
+			req.Header.Set("Accept", "text/plain, application/json; version=3, application/xml; version=4")
+			Ω(goa.ResponseSchemaVersion(req, "")).Should(Equal("3"))
+		})
+
+		It("prefers the version header", func() {
+			req.Header.Set("Accept", "application/json; version=3")
+			req.Header.Set("X-Schema-Version", "1")
+			Ω(goa.ResponseSchemaVersion(req, "X-Schema-Version")).Should(Equal("1"))
+		})
+	})
+
+	Context("ConvertSchema", func() {
+		It("copies the fields with the same JSON name", func() {
+			type v1 struct {
+				Name  string `json:"name"`
+				Color string `json:"color"`
+			}
+			type v2 struct {
+				Name    string `json:"name"`
+				Vintage int    `json:"vintage"`
+			}
+			var dst v2
+			Ω(goa.ConvertSchema(&v1{Name: "merlot", Color: "red"}, &dst)).Should(Succeed())
+			Ω(dst).Should(Equal(v2{Name: "merlot"}))
+		})
+	})
+})
@@ -75,3 +75,37 @@ type JWTSecurity struct {
 	// Scopes defines a list of scopes for the security scheme, along with their description.
 	Scopes map[string]string
 }
+
+// HMACSecurity represents a scheme where requests are signed with a HMAC of their canonical
+// representation computed using a secret shared by the client and the service.
+type HMACSecurity struct {
+	// Description of the security scheme
+	Description string
+	// Name is the name of the header that holds the signature.
+	Name string
+}
+
+// CookieSecurity represents a scheme where requests are authenticated with a session cookie whose
+// value is signed and optionally encrypted by the service.
+type CookieSecurity struct {
+	// Description of the security scheme
+	Description string
+	// Name is the name of the cookie that holds the session.
+	Name string
+	// Encrypted is true if the content of the session must be encrypted in addition to being
+	// signed.
+	Encrypted bool
+}
+
+// SPIFFESecurity represents a mutual TLS scheme where clients are authenticated with the SPIFFE ID
+// of their X.509 SVID and authorized with the scopes granted to the ID.
+type SPIFFESecurity struct {
+	// Description of the security scheme
+	Description string
+	// TrustDomain is the SPIFFE trust domain of the authorized workloads.
+	TrustDomain string
+	// Identities maps the SPIFFE IDs of the authorized workloads to the scopes they are granted.
+	Identities map[string][]string
+	// Scopes defines a list of scopes for the security scheme, along with their description.
+	Scopes map[string]string
+}
@@ -0,0 +1,250 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is the value used in place of sensitive data when logging.
+const RedactedValue = "<redacted>"
+
+// sensitive holds the names of the params and headers whose values must be redacted, the keys of
+// the sensitive attributes of the generated types and the types of the media types.
+var sensitive = struct {
+	sync.RWMutex
+	names      map[string]bool
+	fields     map[reflect.Type]map[string]bool
+	mediaTypes map[string]reflect.Type
+}{
+	names:      make(map[string]bool),
+	fields:     make(map[reflect.Type]map[string]bool),
+	mediaTypes: make(map[string]reflect.Type),
+}
+
+// RegisterSensitive records the names of params and headers whose values must not appear in logs.
+// Names are case insensitive. The code generated by goagen registers the names of the params and
+// headers marked as sensitive in the design.
+func RegisterSensitive(names ...string) {
+	sensitive.Lock()
+	defer sensitive.Unlock()
+	for _, n := range names {
+		sensitive.names[strings.ToLower(n)] = true
+	}
+}
+
+// IsSensitive returns true if the param or header with the given name was registered as
+// sensitive.
+func IsSensitive(name string) bool {
+	sensitive.RLock()
+	defer sensitive.RUnlock()
+	return sensitive.names[strings.ToLower(name)]
+}
+
+// RegisterSensitiveFields records the attributes of the Go type of v whose values must not appear
+// in logs, v is typically a nil pointer to the type, e.g. (*CreateBottlePayload)(nil). The fields
+// are the keys of the attributes in the JSON representation of the type, the keys of the
+// attributes of inline objects are prefixed with the key of their parent and a dot, e.g.
+// "credentials.password". The fields of the named types referenced by the type must be registered
+// with these types. The code generated by goagen registers the sensitive attributes of the user
+// types, media types and payloads.
+func RegisterSensitiveFields(v interface{}, fields ...string) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return
+	}
+	sensitive.Lock()
+	defer sensitive.Unlock()
+	keys, ok := sensitive.fields[t]
+	if !ok {
+		keys = make(map[string]bool)
+		sensitive.fields[t] = keys
+	}
+	for _, f := range fields {
+		keys[f] = true
+	}
+}
+
+// RegisterSensitiveMediaType records the Go type of v as the type of the bodies whose content type
+// is the given media type identifier so that RedactBody can redact their sensitive attributes.
+// The "view" parameter of the identifier selects the media type view, it defaults to "default".
+func RegisterSensitiveMediaType(identifier string, v interface{}) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return
+	}
+	sensitive.Lock()
+	defer sensitive.Unlock()
+	sensitive.mediaTypes[mediaTypeKey(identifier)] = t
+}
+
+// Redact returns a value suitable for logging where the values of the sensitive attributes of v
+// are replaced with RedactedValue. The sensitive attributes are those registered with
+// RegisterSensitiveFields for the types of v and of the values it contains. Redact returns v
+// unchanged if it does not contain any sensitive attribute, otherwise it returns the generic JSON
+// representation of v (maps and slices) with the sensitive values redacted.
+func Redact(v interface{}) interface{} {
+	if v == nil || !hasSensitiveFields() {
+		return v
+	}
+	js, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err := json.Unmarshal(js, &generic); err != nil {
+		return v
+	}
+	if !redact(generic, reflect.TypeOf(v)) {
+		return v
+	}
+	return generic
+}
+
+// RedactBody returns a copy of the given JSON body where the values of the sensitive attributes
+// are replaced with RedactedValue. The body is the JSON representation of a value of the type of
+// v or, if v is nil, of the type registered with RegisterSensitiveMediaType for contentType.
+// RedactBody returns body unchanged if it is not a JSON document, if the type is unknown or if
+// the body does not contain any sensitive attribute.
+func RedactBody(body []byte, contentType string, v interface{}) []byte {
+	if len(body) == 0 || !hasSensitiveFields() {
+		return body
+	}
+	t := reflect.TypeOf(v)
+	if t == nil {
+		sensitive.RLock()
+		t = sensitive.mediaTypes[mediaTypeKey(contentType)]
+		sensitive.RUnlock()
+		if t == nil {
+			return body
+		}
+	}
+	var generic interface{}
+	if err := json.Unmarshal(body, &generic); err != nil {
+		return body
+	}
+	if !redact(generic, t) {
+		return body
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return body
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// hasSensitiveFields returns true if any sensitive field was registered.
+func hasSensitiveFields() bool {
+	sensitive.RLock()
+	defer sensitive.RUnlock()
+	return len(sensitive.fields) > 0
+}
+
+// mediaTypeKey returns the key used to index the given media type identifier: the media type
+// followed by its view.
+func mediaTypeKey(identifier string) string {
+	mt, params, err := mime.ParseMediaType(identifier)
+	if err != nil {
+		return identifier
+	}
+	view := params["view"]
+	if view == "" {
+		view = "default"
+	}
+	return mt + "; view=" + view
+}
+
+// redact replaces the values of the sensitive attributes of the given generic JSON value in place
+// given the Go type of the value it represents. It returns true if any value was replaced.
+func redact(v interface{}, t reflect.Type) bool {
+	sensitive.RLock()
+	defer sensitive.RUnlock()
+	return redactValue(v, t, nil, "")
+}
+
+// redactValue implements redact, owner is the closest named type that contains the value and
+// prefix the keys of the inline objects between the owner and the value.
+func redactValue(v interface{}, t reflect.Type, owner reflect.Type, prefix string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		owner, prefix = t, ""
+	}
+	redacted := false
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		fields := sensitive.fields[owner]
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := jsonKey(f)
+			if key == "" {
+				continue
+			}
+			e, ok := obj[key]
+			if !ok {
+				continue
+			}
+			if fields[prefix+key] {
+				obj[key] = RedactedValue
+				redacted = true
+				continue
+			}
+			if redactValue(e, f.Type, owner, prefix+key+".") {
+				redacted = true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := v.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, e := range arr {
+			if redactValue(e, t.Elem(), owner, prefix) {
+				redacted = true
+			}
+		}
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for _, e := range m {
+			if redactValue(e, t.Elem(), owner, prefix) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// jsonKey returns the key of the given struct field in the JSON representation of the struct,
+// the empty string if the field is not encoded.
+func jsonKey(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" {
+		return f.Name
+	}
+	return tag
+}
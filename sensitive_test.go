@@ -0,0 +1,90 @@
+package goa_test
+
+import (
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// redactedAccount is a type with a sensitive attribute and a sensitive attribute of an inline
+// object.
+type redactedAccount struct {
+	Login       string                `json:"login"`
+	Password    string                `json:"password,omitempty"`
+	Credentials *redactedCredentials  `json:"credentials,omitempty"`
+	Options     *struct{ Pin string } `json:"options,omitempty"`
+}
+
+// redactedCredentials is a type referenced by redactedAccount.
+type redactedCredentials struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// redactedLogin is a type with an attribute named like the sensitive attribute of
+// redactedAccount.
+type redactedLogin struct {
+	Password string `json:"password"`
+}
+
+var _ = Describe("Redact", func() {
+	BeforeEach(func() {
+		goa.RegisterSensitive("Password")
+		goa.RegisterSensitiveFields((*redactedAccount)(nil), "password", "options.Pin")
+		goa.RegisterSensitiveFields(redactedCredentials{}, "token")
+		goa.RegisterSensitiveMediaType("application/vnd.account+json", (*redactedAccount)(nil))
+	})
+
+	It("flags registered names regardless of case", func() {
+		Ω(goa.IsSensitive("password")).Should(BeTrue())
+		Ω(goa.IsSensitive("PASSWORD")).Should(BeTrue())
+		Ω(goa.IsSensitive("login")).Should(BeFalse())
+	})
+
+	It("redacts the sensitive attributes of the owning types", func() {
+		v := []*redactedAccount{{
+			Login:       "foo",
+			Password:    "bar",
+			Credentials: &redactedCredentials{Password: "baz", Token: "qux"},
+			Options:     &struct{ Pin string }{Pin: "1234"},
+		}}
+		Ω(goa.Redact(v)).Should(Equal([]interface{}{
+			map[string]interface{}{
+				"login":       "foo",
+				"password":    goa.RedactedValue,
+				"credentials": map[string]interface{}{"password": "baz", "token": goa.RedactedValue},
+				"options":     map[string]interface{}{"Pin": goa.RedactedValue},
+			},
+		}))
+	})
+
+	It("returns values with no sensitive attribute unchanged", func() {
+		login := &redactedLogin{Password: "bar"}
+		Ω(goa.Redact(login)).Should(Equal(login))
+		m := map[string]interface{}{"password": "bar"}
+		Ω(goa.Redact(m)).Should(Equal(m))
+	})
+
+	Context("RedactBody", func() {
+		It("redacts bodies given the type of their value", func() {
+			body := goa.RedactBody([]byte(`{"login":"foo","password":"bar"}`), "application/json", &redactedAccount{})
+			Ω(string(body)).Should(MatchJSON(`{"login":"foo","password":"<redacted>"}`))
+		})
+
+		It("redacts bodies given their media type", func() {
+			body := goa.RedactBody([]byte(`{"login":"foo","password":"bar"}`), "application/vnd.account+json; view=default", nil)
+			Ω(string(body)).Should(MatchJSON(`{"login":"foo","password":"<redacted>"}`))
+		})
+
+		It("returns bodies of unknown types unchanged", func() {
+			body := []byte(`{"login":"foo","password":"bar"}`)
+			Ω(goa.RedactBody(body, "application/json", nil)).Should(Equal(body))
+			Ω(goa.RedactBody(body, "application/vnd.account+json; view=tiny", nil)).Should(Equal(body))
+		})
+
+		It("returns bodies that are not JSON unchanged", func() {
+			body := []byte("password=bar")
+			Ω(goa.RedactBody(body, "application/vnd.account+json", nil)).Should(Equal(body))
+		})
+	})
+})
@@ -1,15 +1,21 @@
 package goa
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"sort"
 	"strings"
 	"sync"
@@ -39,9 +45,22 @@ type (
 		Decoder *HTTPDecoder
 		// Response body encoder
 		Encoder *HTTPEncoder
-
-		middleware []Middleware       // Middleware chain
-		cancel     context.CancelFunc // Service context cancel signal trigger
+		// Messages translates the error responses in the language requested by the client
+		// via the Accept-Language header, error responses are sent in English if nil.
+		Messages MessageCatalog
+		// PanicHook is called with the recovered value and the stack trace of the panics of the
+		// generated action handlers, e.g. to report them to an error tracker. The panics are
+		// logged with their stack trace if nil.
+		PanicHook func(ctx context.Context, v interface{}, stack []byte)
+		// ErrorEncoder overrides the encoding of the error responses written by the
+		// ErrorHandler middleware, e.g. to render a different error envelope. Controllers may
+		// override it in turn, see Controller.ErrorEncoder and Controller.SetErrorEncoder.
+		ErrorEncoder ErrorEncoder
+
+		middleware   []Middleware       // Middleware chain
+		cancel       context.CancelFunc // Service context cancel signal trigger
+		shutdownOnce sync.Once          // Initializes shutdown
+		shutdown     chan struct{}      // Closed when Server shuts down
 	}
 
 	// Controller defines the common fields and behavior of generated controllers.
@@ -68,8 +87,12 @@ type (
 		//		}
 		//	}
 		FileSystem func(string) http.FileSystem
+		// ErrorEncoder overrides the service error encoder for the errors returned by the
+		// controller actions.
+		ErrorEncoder ErrorEncoder
 
-		middleware []Middleware // Controller specific middleware if any
+		middleware    []Middleware            // Controller specific middleware if any
+		errorEncoders map[string]ErrorEncoder // Action specific error encoders if any
 	}
 
 	// FileServer is the interface implemented by controllers that can serve static files.
@@ -231,30 +254,112 @@ func (service *Service) NewController(name string) *Controller {
 }
 
 // Send serializes the given body matching the request Accept header against the service
-// encoders. It uses the default service encoder if no match is found.
+// encoders. It uses the default service encoder if no match is found. Error responses are
+// translated using the service message catalog if any. Send returns ErrClientClosedRequest
+// without writing the response if the client closed the connection.
 func (service *Service) Send(ctx context.Context, code int, body interface{}) error {
 	r := ContextResponse(ctx)
 	if r == nil {
 		return fmt.Errorf("no response data in context")
 	}
+	if err := ClientClosed(ctx); err != nil {
+		return err
+	}
+	if e, ok := body.(*ErrorResponse); ok {
+		body = service.localize(ctx, e)
+	}
 	r.WriteHeader(code)
 	return service.EncodeResponse(ctx, body)
 }
 
+// localize translates the error in the first language accepted by the request that the service
+// messages support and sets the Content-Language header accordingly. It returns the error itself
+// if there is no such language.
+func (service *Service) localize(ctx context.Context, e *ErrorResponse) *ErrorResponse {
+	if service.Messages == nil {
+		return e
+	}
+	req := ContextRequest(ctx)
+	if req == nil {
+		return e
+	}
+	langs := AcceptedLanguages(req.Header.Get("Accept-Language"))
+	lang := e.language(service.Messages, langs)
+	if lang == "" {
+		return e
+	}
+	ContextResponse(ctx).Header().Set("Content-Language", lang)
+	return e.Localize(service.Messages, lang)
+}
+
+// SendJSON sends a HTTP response with the given status code and JSON encoded body. The body is
+// followed by a newline like the output of the JSON encoder. The generated response helpers use
+// SendJSON with the AppendJSON functions to skip the encoder for primitive results. Like Send it
+// does not write the response if the client closed the connection.
+func (service *Service) SendJSON(ctx context.Context, code int, body []byte) error {
+	r := ContextResponse(ctx)
+	if r == nil {
+		return fmt.Errorf("no response data in context")
+	}
+	if err := ClientClosed(ctx); err != nil {
+		return err
+	}
+	r.WriteHeader(code)
+	_, err := r.Write(append(body, '\n'))
+	return err
+}
+
 // ServeFiles create a "FileServer" controller and calls ServerFiles on it.
 func (service *Service) ServeFiles(path, filename string) error {
 	ctrl := service.NewController("FileServer")
 	return ctrl.ServeFiles(path, filename)
 }
 
+// MountHealthCheck mounts a handler that responds to the GET requests sent to the given path with
+// a 200 status code, or with a 503 status code once the service handlers are canceled. The handler
+// bypasses the service middleware so that the probes of the deployment environment do not show in
+// the request logs.
+func (service *Service) MountHealthCheck(path string) {
+	service.Mux.Handle("GET", path, func(rw http.ResponseWriter, _ *http.Request, _ url.Values) {
+		rw.Header().Set("Content-Type", "text/plain")
+		if service.Context.Err() != nil {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			rw.Write([]byte("shutting down"))
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte("OK"))
+	})
+	service.LogInfo("mount", "health", path, "route", "GET "+path)
+}
+
 // DecodeRequest uses the HTTP decoder to unmarshal the request body into the provided value based
-// on the request Content-Type header.
+// on the request Content-Type header. Decoding failures produce the errors returned by
+// InvalidBodyError.
 func (service *Service) DecodeRequest(req *http.Request, v interface{}) error {
 	body, contentType := req.Body, req.Header.Get("Content-Type")
 	defer body.Close()
 
 	if err := service.Decoder.Decode(v, body, contentType); err != nil {
-		return fmt.Errorf("failed to decode request body with content type %#v: %s", contentType, err)
+		return InvalidBodyError(contentType, err)
+	}
+
+	return nil
+}
+
+// DecodeLimitedRequest is like DecodeRequest but fails as soon as an array or a map of a JSON
+// request body exceeds the maximum length given by limits. The generated controllers use it for
+// payloads that define such maximum lengths so that oversized collections are rejected before
+// being loaded in memory.
+func (service *Service) DecodeLimitedRequest(req *http.Request, v interface{}, limits *JSONLimits) error {
+	body, contentType := io.Reader(req.Body), req.Header.Get("Content-Type")
+	defer req.Body.Close()
+
+	if isJSONContentType(contentType) {
+		body = LimitJSON(body, limits)
+	}
+	if err := service.Decoder.Decode(v, body, contentType); err != nil {
+		return InvalidBodyError(contentType, err)
 	}
 
 	return nil
@@ -267,6 +372,52 @@ func (service *Service) EncodeResponse(ctx context.Context, v interface{}) error
 	return service.Encoder.Encode(v, ContextResponse(ctx), accept)
 }
 
+// ProxyHandler returns a handler that forwards requests to the given upstream URL and streams back
+// the upstream response. The request path is appended to the path of the upstream URL. If the
+// request payload was loaded by the controller it gets encoded again using the request content
+// type so that the upstream service receives the same (validated) payload. Errors that occur while
+// contacting the upstream service are returned as ErrBadGateway errors so that they get encoded like
+// any other error returned by the service.
+func (service *Service) ProxyHandler(upstream string) Handler {
+	target, err := url.Parse(upstream)
+	if err != nil {
+		return func(context.Context, http.ResponseWriter, *http.Request) error {
+			return fmt.Errorf("invalid proxy URL %#v: %s", upstream, err)
+		}
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(_ http.ResponseWriter, req *http.Request, err error) {
+		if perr, ok := req.Context().Value(proxyErrKey).(*error); ok {
+			*perr = err
+		}
+	}
+	return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		if payload := ContextRequest(ctx).Payload; payload != nil {
+			contentType := req.Header.Get("Content-Type")
+			if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+				contentType = mediaType
+			}
+			var buf bytes.Buffer
+			if err := service.Encoder.Encode(payload, &buf, contentType); err != nil {
+				return err
+			}
+			req.Body = ioutil.NopCloser(&buf)
+			req.ContentLength = int64(buf.Len())
+		}
+		LogInfo(ctx, "proxy", "upstream", upstream, "route", req.URL.Path)
+		var perr error
+		proxy.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), proxyErrKey, &perr)))
+		if perr != nil {
+			if err := ClientClosed(ctx); err != nil {
+				return err
+			}
+			LogError(ctx, "proxy", "upstream", upstream, "err", perr)
+			return ErrBadGateway("upstream request failed", "upstream", upstream)
+		}
+		return nil
+	}
+}
+
 // ServeFiles replies to the request with the contents of the named file or directory. See
 // FileHandler for details.
 func (ctrl *Controller) ServeFiles(path, filename string) error {
@@ -296,6 +447,85 @@ func (ctrl *Controller) Use(m Middleware) {
 // This function is intended for the controller generated code. User code should not need to call
 // it directly.
 func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) MuxHandler {
+	return ctrl.muxHandler(name, hdlr, unm, false, false)
+}
+
+// RawMuxHandler wraps a request handler into a MuxHandler that gives the handler the request
+// untouched: the body is neither decoded nor limited to MaxRequestBodyLength. It is used by the
+// code generated for the actions with the "http:raw" metadata.
+func (ctrl *Controller) RawMuxHandler(name string, hdlr Handler) MuxHandler {
+	return ctrl.muxHandler(name, hdlr, nil, true, false)
+}
+
+// LazyMuxHandler wraps a request handler into a MuxHandler like MuxHandler does but defers the
+// decoding of the request body until the handler calls LoadRequestBody. The middleware, e.g. the
+// security handlers, may thus reject requests without reading or validating their bodies. It is
+// used by the code generated for the actions with the "http:body:lazy" metadata.
+func (ctrl *Controller) LazyMuxHandler(name string, hdlr Handler, unm Unmarshaler) MuxHandler {
+	return ctrl.muxHandler(name, hdlr, unm, false, true)
+}
+
+// SetErrorEncoder overrides the encoding of the errors returned by the given action. It takes
+// precedence over the controller and service error encoders. The action error encoder is removed
+// if enc is nil.
+func (ctrl *Controller) SetErrorEncoder(action string, enc ErrorEncoder) {
+	if enc == nil {
+		delete(ctrl.errorEncoders, action)
+		return
+	}
+	if ctrl.errorEncoders == nil {
+		ctrl.errorEncoders = make(map[string]ErrorEncoder)
+	}
+	ctrl.errorEncoders[action] = enc
+}
+
+// LoadRequestBody decodes the request body whose decoding was deferred by LazyMuxHandler and
+// initializes the Payload field of the request data. It returns the error that would have been
+// set in the context had the body been decoded eagerly. LoadRequestBody does nothing if there is
+// no deferred body to decode.
+func LoadRequestBody(ctx context.Context) error {
+	r := ContextRequest(ctx)
+	if r == nil || r.loadBody == nil {
+		return nil
+	}
+	load := r.loadBody
+	r.loadBody = nil
+	return load()
+}
+
+// RecoverFault converts the panic of the current goroutine, if any, into an internal error and
+// stores it in err. The generated action handlers defer calls to RecoverFault so that panics
+// produce the fault response of the design: if respond is not nil it is called with the error to
+// write the response and err is set to the value it returns. RecoverFault must be called
+// directly by a deferred statement, it does nothing otherwise. http.ErrAbortHandler panics are
+// not recovered.
+func (service *Service) RecoverFault(ctx context.Context, err *error, respond func(error) error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+	stack := debug.Stack()
+	if service.PanicHook != nil {
+		service.PanicHook(ctx, v, stack)
+	} else {
+		LogError(ctx, "panic", "err", fmt.Sprintf("%v", v), "stack", string(stack))
+	}
+	fault := ErrInternal("internal server error")
+	if respond == nil {
+		*err = fault
+		return
+	}
+	if resp := ContextResponse(ctx); resp != nil && resp.Written() {
+		*err = fault
+		return
+	}
+	*err = respond(fault)
+}
+
+func (ctrl *Controller) muxHandler(name string, hdlr Handler, unm Unmarshaler, raw, lazy bool) MuxHandler {
 	// Use closure to enable late computation of handlers to ensure all middleware has been
 	// registered.
 	var handler Handler
@@ -318,23 +548,26 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 			}
 		})
 
-		// Build context
-		ctx := NewContext(WithAction(ctrl.Context, name), rw, req, params)
+		// Build context, canceled when the client closes the connection
+		ctx, cancel := withClientCancel(WithAction(ctrl.Context, name), req)
+		defer cancel()
+		ctx = NewContext(ctx, rw, req, params)
+		if enc, ok := ctrl.errorEncoders[name]; ok {
+			ctx = context.WithValue(ctx, errorEncoderKey, enc)
+		} else if ctrl.ErrorEncoder != nil {
+			ctx = context.WithValue(ctx, errorEncoderKey, ctrl.ErrorEncoder)
+		}
 
 		// Protect against request bodies with unreasonable length
-		if ctrl.MaxRequestBodyLength > 0 {
+		if ctrl.MaxRequestBodyLength > 0 && !raw {
 			req.Body = http.MaxBytesReader(rw, req.Body, ctrl.MaxRequestBodyLength)
 		}
 
 		// Load body if any
 		if req.ContentLength > 0 && unm != nil {
-			if err := unm(ctx, ctrl.Service, req); err != nil {
-				if err.Error() == "http: request body too large" {
-					msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
-					err = ErrRequestBodyTooLarge(msg)
-				} else {
-					err = ErrBadRequest(err)
-				}
+			if lazy {
+				ContextRequest(ctx).loadBody = func() error { return ctrl.loadBody(ctx, req, unm) }
+			} else if err := ctrl.loadBody(ctx, req, unm); err != nil {
 				ctx = WithError(ctx, err)
 			}
 		}
@@ -342,12 +575,32 @@ func (ctrl *Controller) MuxHandler(name string, hdlr Handler, unm Unmarshaler) M
 		// Invoke handler
 		if err := handler(ctx, ContextResponse(ctx), req); err != nil {
 			LogError(ctx, "uncaught error", "err", err)
+			if ContextResponse(ctx).hijacked {
+				return
+			}
 			respBody := fmt.Sprintf("Internal error: %s", err) // Sprintf catches panics
 			ctrl.Service.Send(ctx, 500, respBody)
 		}
 	}
 }
 
+// loadBody decodes the request body with unm and returns the error to respond with if it fails.
+func (ctrl *Controller) loadBody(ctx context.Context, req *http.Request, unm Unmarshaler) error {
+	err := unm(ctx, ctrl.Service, req)
+	if err == nil {
+		return nil
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		msg := fmt.Sprintf("request body length exceeds %d bytes", ctrl.MaxRequestBodyLength)
+		return ErrRequestBodyTooLarge(msg)
+	}
+	if _, ok := err.(ServiceError); ok {
+		return err
+	}
+	return ErrBadRequest(err)
+}
+
 // FileHandler returns a handler that serves files under the given filename for the given route path.
 // The logic for what to do when the filename points to a file vs. a directory is the same as the
 // standard http package ServeFile function. The path may end with a wildcard that matches the rest
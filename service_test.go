@@ -3,11 +3,15 @@ package goa_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"context"
 
@@ -40,6 +44,48 @@ var _ = Describe("Service", func() {
 		})
 	})
 
+	Describe("MountHealthCheck", func() {
+		var rw *TestResponseWriter
+
+		BeforeEach(func() {
+			s.MountHealthCheck("/healthz")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		})
+
+		It("responds to the health checks", func() {
+			req, _ := http.NewRequest("GET", "/healthz", nil)
+			s.Mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(200))
+			Ω(string(rw.Body)).Should(Equal("OK"))
+		})
+
+		It("fails the health checks once canceled", func() {
+			s.CancelAll()
+			req, _ := http.NewRequest("GET", "/healthz", nil)
+			s.Mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(503))
+		})
+	})
+
+	Describe("MountRoutes", func() {
+		var rw *TestResponseWriter
+
+		BeforeEach(func() {
+			s.MountRoutes("/debug/routes", []*goa.RouteInfo{
+				{Method: "GET", Pattern: "/bottles/:id", Service: "cellar", Resource: "bottle", Endpoint: "show", Scheme: "jwt", Scopes: []string{"api:read"}},
+			})
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+		})
+
+		It("renders the route table", func() {
+			req, _ := http.NewRequest("GET", "/debug/routes", nil)
+			s.Mux.ServeHTTP(rw, req)
+			Ω(rw.Status).Should(Equal(200))
+			Ω(rw.Header().Get("Content-Type")).Should(Equal("application/json"))
+			Ω(string(rw.Body)).Should(MatchJSON(`[{"method":"GET","pattern":"/bottles/:id","service":"cellar","resource":"bottle","endpoint":"show","scheme":"jwt","scopes":["api:read"]}]`))
+		})
+	})
+
 	Describe("NotFound", func() {
 		var rw *TestResponseWriter
 		var req *http.Request
@@ -140,6 +186,209 @@ var _ = Describe("Service", func() {
 		It("prevents reading more bytes", func() {
 			Ω(string(rw.Body)).Should(MatchRegexp(`\[.*\] 413 request_too_large: request body length exceeds 4 bytes`))
 		})
+
+		Context("with a raw handler", func() {
+			BeforeEach(func() {
+				ctrl := s.NewController("test")
+				ctrl.MaxRequestBodyLength = 4
+				handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					b, err := ioutil.ReadAll(req.Body)
+					if err != nil {
+						return err
+					}
+					rw.Write(b)
+					return nil
+				}
+				muxHandler = ctrl.RawMuxHandler("testRaw", handler)
+			})
+
+			It("does not limit the body length", func() {
+				Ω(string(rw.Body)).Should(Equal(`"234"`))
+			})
+		})
+	})
+
+	Describe("LazyMuxHandler", func() {
+		var rw *TestResponseWriter
+		var req *http.Request
+		var body *countingReader
+		var reject bool
+		var payload interface{}
+		var loadErr error
+
+		BeforeEach(func() {
+			body = &countingReader{r: bytes.NewBufferString(`{"name":"foo"}`)}
+			req, _ = http.NewRequest("POST", "/foo", body)
+			req.ContentLength = 14
+			req.Header.Set("Content-Type", "application/json")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			reject = false
+			payload, loadErr = nil, nil
+		})
+
+		JustBeforeEach(func() {
+			ctrl := s.NewController("test")
+			ctrl.Use(func(h goa.Handler) goa.Handler {
+				return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+					if reject {
+						rw.WriteHeader(401)
+						return nil
+					}
+					return h(ctx, rw, req)
+				}
+			})
+			unmarshaler := func(ctx context.Context, service *goa.Service, req *http.Request) error {
+				var p map[string]interface{}
+				if err := service.DecodeRequest(req, &p); err != nil {
+					return err
+				}
+				goa.ContextRequest(ctx).Payload = p
+				return nil
+			}
+			handler := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				Ω(goa.ContextRequest(ctx).Payload).Should(BeNil())
+				loadErr = goa.LoadRequestBody(ctx)
+				payload = goa.ContextRequest(ctx).Payload
+				Ω(goa.LoadRequestBody(ctx)).ShouldNot(HaveOccurred())
+				rw.WriteHeader(200)
+				return nil
+			}
+			ctrl.LazyMuxHandler("testLazy", handler, unmarshaler)(rw, req, nil)
+		})
+
+		It("decodes the body when the handler loads it", func() {
+			Ω(rw.Status).Should(Equal(200))
+			Ω(loadErr).ShouldNot(HaveOccurred())
+			Ω(payload).Should(Equal(map[string]interface{}{"name": "foo"}))
+		})
+
+		Context("with middleware that rejects the request", func() {
+			BeforeEach(func() {
+				reject = true
+			})
+
+			It("does not read the body", func() {
+				Ω(rw.Status).Should(Equal(401))
+				Ω(body.n).Should(Equal(0))
+			})
+		})
+
+		Context("with an invalid body", func() {
+			BeforeEach(func() {
+				body.r = bytes.NewBufferString("not json")
+				req.ContentLength = 8
+			})
+
+			It("returns a bad request error", func() {
+				Ω(loadErr).Should(HaveOccurred())
+				Ω(loadErr.(goa.ServiceError).ResponseStatus()).Should(Equal(400))
+				Ω(payload).Should(BeNil())
+			})
+		})
+
+		Context("with a body of the wrong type", func() {
+			BeforeEach(func() {
+				body.r = bytes.NewBufferString(`["foo"]`)
+				req.ContentLength = 7
+			})
+
+			It("returns an invalid encoding error", func() {
+				Ω(loadErr).Should(HaveOccurred())
+				Ω(loadErr).Should(BeAssignableToTypeOf(&goa.ErrorResponse{}))
+				err := loadErr.(*goa.ErrorResponse)
+				Ω(err.Code).Should(Equal("invalid_encoding"))
+				Ω(err.Detail).Should(Equal("type of payload must be object but got array"))
+			})
+		})
+	})
+
+	Describe("RecoverFault", func() {
+		var respond func(error) error
+		var hookValue interface{}
+		var hookStack []byte
+		var err error
+
+		BeforeEach(func() {
+			respond = nil
+			hookValue = nil
+			hookStack = nil
+			s.PanicHook = func(_ context.Context, v interface{}, stack []byte) {
+				hookValue = v
+				hookStack = stack
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = func() (err error) {
+				defer s.RecoverFault(context.Background(), &err, respond)
+				panic("boom")
+			}()
+		})
+
+		It("converts the panic into an internal error", func() {
+			Ω(err).Should(HaveOccurred())
+			Ω(err.(goa.ServiceError).ResponseStatus()).Should(Equal(500))
+		})
+
+		It("calls the panic hook with the stack trace", func() {
+			Ω(hookValue).Should(Equal("boom"))
+			Ω(string(hookStack)).Should(ContainSubstring("panic"))
+		})
+
+		Context("with a fault response", func() {
+			var responded error
+
+			BeforeEach(func() {
+				responded = nil
+				respond = func(e error) error {
+					responded = e
+					return nil
+				}
+			})
+
+			It("sends the fault response", func() {
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(responded).Should(HaveOccurred())
+				Ω(responded.(goa.ServiceError).ResponseStatus()).Should(Equal(500))
+			})
+		})
+	})
+
+	Describe("SetErrorEncoder", func() {
+		var ctrl *goa.Controller
+		var actionEnc, ctrlEnc, used goa.ErrorEncoder
+
+		BeforeEach(func() {
+			ctrl = s.NewController("test")
+			actionEnc = func(context.Context, int, error) error { return nil }
+			ctrlEnc = func(context.Context, int, error) error { return nil }
+			used = nil
+		})
+
+		serve := func(action string) {
+			h := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+				used = goa.ContextErrorEncoder(ctx)
+				return nil
+			}
+			req, err := http.NewRequest("GET", "/foo", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			rw := &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctrl.MuxHandler(action, h, nil)(rw, req, nil)
+		}
+
+		It("does not set an error encoder by default", func() {
+			serve("show")
+			Ω(used).Should(BeNil())
+		})
+
+		It("sets the action error encoder in the request context", func() {
+			ctrl.ErrorEncoder = ctrlEnc
+			ctrl.SetErrorEncoder("show", actionEnc)
+			serve("show")
+			Ω(reflect.ValueOf(used).Pointer()).Should(Equal(reflect.ValueOf(actionEnc).Pointer()))
+			serve("list")
+			Ω(reflect.ValueOf(used).Pointer()).Should(Equal(reflect.ValueOf(ctrlEnc).Pointer()))
+		})
 	})
 
 	Describe("MuxHandler", func() {
@@ -435,6 +684,153 @@ var _ = Describe("Service", func() {
 			})
 		})
 	})
+
+	Describe("Send", func() {
+		var rw *TestResponseWriter
+		var body interface{}
+
+		BeforeEach(func() {
+			s.Messages = goa.Catalog{"fr": {goa.MsgMissingParam: "paramètre obligatoire %#v manquant"}}
+			body = goa.MissingParamError("id")
+		})
+
+		JustBeforeEach(func() {
+			r, err := http.NewRequest("GET", "/bottles", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			r.Header.Set("Accept-Language", "de;q=0.9, fr-CA;q=0.8, en;q=0.5")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx := goa.NewContext(context.Background(), rw, r, nil)
+			Ω(s.Send(ctx, 400, body)).ShouldNot(HaveOccurred())
+		})
+
+		It("translates error responses", func() {
+			Ω(rw.ParentHeader.Get("Content-Language")).Should(Equal("fr-CA"))
+			Ω(string(rw.Body)).Should(ContainSubstring(`"detail":"paramètre obligatoire \"id\" manquant"`))
+		})
+
+		Context("with a body that is not an error", func() {
+			BeforeEach(func() {
+				body = map[string]string{"name": "foo"}
+			})
+
+			It("sends the body as is", func() {
+				Ω(rw.ParentHeader.Get("Content-Language")).Should(BeEmpty())
+				Ω(string(rw.Body)).Should(MatchJSON(`{"name":"foo"}`))
+			})
+		})
+	})
+
+	Describe("SendJSON", func() {
+		It("writes the same response as Send", func() {
+			r, err := http.NewRequest("GET", "/bottles", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			sent := &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx := goa.NewContext(context.Background(), sent, r, nil)
+			Ω(s.Send(ctx, 200, "foo&bar")).ShouldNot(HaveOccurred())
+			direct := &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx = goa.NewContext(context.Background(), direct, r, nil)
+			Ω(s.SendJSON(ctx, 200, goa.AppendJSONString(nil, "foo&bar"))).ShouldNot(HaveOccurred())
+			Ω(direct.Status).Should(Equal(200))
+			Ω(string(direct.Body)).Should(Equal(string(sent.Body)))
+		})
+	})
+
+	Describe("DecodeLimitedRequest", func() {
+		limits := &goa.JSONLimits{Fields: map[string]*goa.JSONLimits{"tags": {MaxLength: 2}}}
+
+		decode := func(contentType, body string) error {
+			req, err := http.NewRequest("POST", "/bottles", strings.NewReader(body))
+			Ω(err).ShouldNot(HaveOccurred())
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+			var payload map[string]interface{}
+			return s.DecodeLimitedRequest(req, &payload, limits)
+		}
+
+		It("decodes payloads within the limits", func() {
+			Ω(decode("application/json", `{"tags":["a","b"]}`)).ShouldNot(HaveOccurred())
+		})
+
+		It("rejects oversized JSON collections", func() {
+			err := decode("application/vnd.goa.bottle+json; charset=utf-8", `{"tags":["a","b","c"]}`)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("body.tags has more than 2 elements"))
+		})
+
+		It("defaults to JSON", func() {
+			Ω(decode("", `{"tags":["a","b","c"]}`)).Should(HaveOccurred())
+		})
+	})
+
+	Describe("ProxyHandler", func() {
+		var upstream *httptest.Server
+		var upstreamPath string
+		var upstreamBody []byte
+		var payload interface{}
+		var upstreamURL string
+
+		var rw *TestResponseWriter
+		var proxyErr error
+
+		BeforeEach(func() {
+			payload = nil
+			upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				upstreamPath = r.URL.Path
+				upstreamBody, _ = ioutil.ReadAll(r.Body)
+				w.WriteHeader(201)
+				w.Write([]byte("proxied"))
+			}))
+			upstreamURL = upstream.URL
+		})
+
+		AfterEach(func() {
+			upstream.Close()
+		})
+
+		JustBeforeEach(func() {
+			r, err := http.NewRequest("POST", "/bottles", nil)
+			Ω(err).ShouldNot(HaveOccurred())
+			r.Header.Set("Content-Type", "application/json; charset=utf-8")
+			rw = &TestResponseWriter{ParentHeader: make(http.Header)}
+			ctx := goa.NewContext(context.Background(), rw, r, nil)
+			goa.ContextRequest(ctx).Payload = payload
+			proxyErr = s.ProxyHandler(upstreamURL+"/legacy")(ctx, goa.ContextResponse(ctx), r)
+		})
+
+		It("forwards the request and streams back the response", func() {
+			Ω(proxyErr).ShouldNot(HaveOccurred())
+			Ω(upstreamPath).Should(Equal("/legacy/bottles"))
+			Ω(rw.Status).Should(Equal(201))
+			Ω(string(rw.Body)).Should(Equal("proxied"))
+		})
+
+		Context("with a payload", func() {
+			BeforeEach(func() {
+				payload = map[string]interface{}{"name": "foo"}
+			})
+
+			It("forwards the encoded payload", func() {
+				Ω(proxyErr).ShouldNot(HaveOccurred())
+				Ω(string(upstreamBody)).Should(MatchJSON(`{"name":"foo"}`))
+			})
+		})
+
+		Context("with an unreachable upstream", func() {
+			BeforeEach(func() {
+				upstream.Close()
+			})
+
+			It("returns a bad gateway error without writing the response", func() {
+				Ω(proxyErr).Should(HaveOccurred())
+				Ω(proxyErr).Should(BeAssignableToTypeOf(&goa.ErrorResponse{}))
+				Ω(proxyErr.(*goa.ErrorResponse).Code).Should(Equal("bad_gateway"))
+				Ω(proxyErr.(*goa.ErrorResponse).Status).Should(Equal(502))
+				Ω(rw.Status).Should(Equal(0))
+				Ω(rw.Body).Should(BeEmpty())
+			})
+		})
+	})
 })
 
 func TErrorHandler(witness *bool) goa.Middleware {
@@ -497,3 +893,15 @@ func (t *TestResponseWriter) Write(b []byte) (int, error) {
 func (t *TestResponseWriter) WriteHeader(s int) {
 	t.Status = s
 }
+
+// countingReader is a io.Reader that counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
@@ -0,0 +1,132 @@
+package goa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The time formats below name the encodings of the DateTime attributes besides the Go time
+// layouts accepted by ParseTime and FormatTime, see the TimeFormats and TimeOutput DSLs.
+const (
+	// TimeRFC3339 is the RFC3339 encoding, the default encoding of the DateTime attributes.
+	TimeRFC3339 = "rfc3339"
+	// TimeUnix is the number of seconds elapsed since January 1, 1970 UTC.
+	TimeUnix = "unix"
+	// TimeUnixMilli is the number of milliseconds elapsed since January 1, 1970 UTC.
+	TimeUnixMilli = "unixmilli"
+)
+
+// knownLocations records the locations loaded by FormatTime indexed by name.
+var (
+	knownLocations     = make(map[string]*time.Location)
+	knownLocationsLock = &sync.RWMutex{}
+)
+
+// ParseTime parses val with the first of the given formats that accepts it. The formats are
+// TimeRFC3339, TimeUnix, TimeUnixMilli or Go time layouts, ParseTime uses TimeRFC3339 if there
+// is none.
+func ParseTime(val string, formats ...string) (time.Time, error) {
+	if len(formats) == 0 {
+		formats = []string{TimeRFC3339}
+	}
+	for _, f := range formats {
+		switch f {
+		case TimeRFC3339:
+			if t, err := time.Parse(time.RFC3339, val); err == nil {
+				return t, nil
+			}
+		case TimeUnix, TimeUnixMilli:
+			n, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				continue
+			}
+			if f == TimeUnix {
+				return time.Unix(n, 0).UTC(), nil
+			}
+			return time.Unix(n/1000, (n%1000)*int64(time.Millisecond)).UTC(), nil
+		default:
+			if t, err := time.Parse(f, val); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q does not match any of the time formats %v", val, formats)
+}
+
+// FormatTime returns the representation of t in the given format converted to the location
+// with the given IANA name, e.g. "UTC" or "Europe/Paris". The time zone of t is kept if zone is
+// empty or unknown. format is one of TimeRFC3339, TimeUnix, TimeUnixMilli or a Go time layout.
+func FormatTime(t time.Time, format, zone string) string {
+	if loc := location(zone); loc != nil {
+		t = t.In(loc)
+	}
+	switch format {
+	case TimeUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	case TimeRFC3339, "":
+		return t.Format(time.RFC3339)
+	default:
+		return t.Format(format)
+	}
+}
+
+// DecodeJSONTime decodes the JSON value data with the first of the given formats that accepts
+// it, see ParseTime. The unix formats accept JSON numbers and strings, the other formats JSON
+// strings only.
+func DecodeJSONTime(data []byte, formats ...string) (time.Time, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return time.Time{}, err
+		}
+		return ParseTime(s, formats...)
+	}
+	var unix []string
+	for _, f := range formats {
+		if f == TimeUnix || f == TimeUnixMilli {
+			unix = append(unix, f)
+		}
+	}
+	if len(unix) == 0 {
+		return time.Time{}, fieldTypeError(data, time.Time{})
+	}
+	return ParseTime(string(data), unix...)
+}
+
+// EncodeJSONTime returns the JSON encoding of t in the given format and zone, see FormatTime.
+// The unix formats produce JSON numbers, the other formats JSON strings.
+func EncodeJSONTime(t time.Time, format, zone string) []byte {
+	s := FormatTime(t, format, zone)
+	if format == TimeUnix || format == TimeUnixMilli {
+		return []byte(s)
+	}
+	return AppendJSONString(nil, s)
+}
+
+// location returns the location with the given name, nil if name is empty or unknown.
+func location(name string) *time.Location {
+	if name == "" {
+		return nil
+	}
+	knownLocationsLock.RLock()
+	loc, ok := knownLocations[name]
+	knownLocationsLock.RUnlock()
+	if ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = nil
+	}
+	knownLocationsLock.Lock()
+	knownLocations[name] = loc
+	knownLocationsLock.Unlock()
+	return loc
+}
@@ -0,0 +1,87 @@
+package goa_test
+
+import (
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseTime", func() {
+	It("parses RFC3339 values by default", func() {
+		t, err := goa.ParseTime("2015-10-26T08:31:23Z")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.Unix()).Should(Equal(int64(1445848283)))
+	})
+
+	It("tries the formats in order", func() {
+		t, err := goa.ParseTime("1445848283", goa.TimeRFC3339, goa.TimeUnix)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(time.Unix(1445848283, 0).UTC()))
+		t, err = goa.ParseTime("1445848283123", goa.TimeUnixMilli)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.UnixNano()).Should(Equal(int64(1445848283123000000)))
+	})
+
+	It("accepts Go layouts", func() {
+		t, err := goa.ParseTime("2015-10-26", "2006-01-02")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t).Should(Equal(time.Date(2015, 10, 26, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("fails when no format matches", func() {
+		_, err := goa.ParseTime("2015-10-26", goa.TimeRFC3339, goa.TimeUnix)
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("FormatTime", func() {
+	t := time.Date(2015, 10, 26, 8, 31, 23, 0, time.UTC)
+
+	It("formats the time in the given format", func() {
+		Ω(goa.FormatTime(t, goa.TimeRFC3339, "")).Should(Equal("2015-10-26T08:31:23Z"))
+		Ω(goa.FormatTime(t, goa.TimeUnix, "")).Should(Equal("1445848283"))
+		Ω(goa.FormatTime(t, goa.TimeUnixMilli, "")).Should(Equal("1445848283000"))
+		Ω(goa.FormatTime(t, "2006-01-02", "")).Should(Equal("2015-10-26"))
+	})
+
+	It("converts the time to the given zone", func() {
+		Ω(goa.FormatTime(t, goa.TimeRFC3339, "Asia/Tokyo")).Should(Equal("2015-10-26T17:31:23+09:00"))
+	})
+
+	It("keeps the time zone when the zone is unknown", func() {
+		Ω(goa.FormatTime(t, goa.TimeRFC3339, "Nowhere/Land")).Should(Equal("2015-10-26T08:31:23Z"))
+	})
+})
+
+var _ = Describe("DecodeJSONTime", func() {
+	It("decodes JSON strings", func() {
+		t, err := goa.DecodeJSONTime([]byte(`"2015-10-26T08:31:23Z"`), goa.TimeRFC3339)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.Unix()).Should(Equal(int64(1445848283)))
+	})
+
+	It("decodes JSON numbers with the unix formats", func() {
+		t, err := goa.DecodeJSONTime([]byte(`1445848283`), goa.TimeRFC3339, goa.TimeUnix)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(t.Unix()).Should(Equal(int64(1445848283)))
+	})
+
+	It("rejects JSON numbers without the unix formats", func() {
+		_, err := goa.DecodeJSONTime([]byte(`1445848283`), goa.TimeRFC3339)
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("EncodeJSONTime", func() {
+	t := time.Date(2015, 10, 26, 8, 31, 23, 0, time.UTC)
+
+	It("encodes the unix formats as JSON numbers", func() {
+		Ω(string(goa.EncodeJSONTime(t, goa.TimeUnix, ""))).Should(Equal(`1445848283`))
+	})
+
+	It("encodes the other formats as JSON strings", func() {
+		Ω(string(goa.EncodeJSONTime(t, "2006-01-02", ""))).Should(Equal(`"2015-10-26"`))
+	})
+})
@@ -0,0 +1,58 @@
+package goa
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+type (
+	// TypeInfo describes a Go type generated from a user type or a media type view of the design.
+	// goagen generates the Types variable of the app package which indexes the types of the
+	// service so that generic tools such as admin UIs or request replay tools can create and
+	// describe their values without depending on the generated package.
+	TypeInfo struct {
+		// Name is the name of the type in the design, e.g. "Bottle" or "BottleTiny" for the
+		// "tiny" view of the Bottle media type.
+		Name string `json:"name"`
+		// Service is the name of the API.
+		Service string `json:"service"`
+		// Identifier is the identifier of the media type if the type is a media type view.
+		Identifier string `json:"identifier,omitempty"`
+		// View is the name of the view if the type is a media type view.
+		View string `json:"view,omitempty"`
+		// Type is the generated Go type.
+		Type reflect.Type `json:"-"`
+		// Schema is the JSON schema of the type, it includes the definitions of the types it
+		// references.
+		Schema json.RawMessage `json:"schema,omitempty"`
+	}
+
+	// TypeRegistry indexes the generated types by design name.
+	TypeRegistry map[string]*TypeInfo
+)
+
+// Lookup returns the description of the type with the given design name, nil if there is none.
+func (r TypeRegistry) Lookup(name string) *TypeInfo {
+	return r[name]
+}
+
+// New returns a pointer to a new zero value of the Go type generated for the type with the
+// given design name. The second return value is false if there is no such type.
+func (r TypeRegistry) New(name string) (interface{}, bool) {
+	info, ok := r[name]
+	if !ok || info.Type == nil {
+		return nil, false
+	}
+	return reflect.New(info.Type).Interface(), true
+}
+
+// Names returns the sorted design names of the registered types.
+func (r TypeRegistry) Names() []string {
+	names := make([]string, 0, len(r))
+	for n := range r {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
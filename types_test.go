@@ -0,0 +1,37 @@
+package goa_test
+
+import (
+	"reflect"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TypeRegistry", func() {
+	type bottle struct {
+		Name string
+	}
+
+	registry := goa.TypeRegistry{
+		"Bottle":  {Name: "Bottle", Service: "cellar", Type: reflect.TypeOf(bottle{})},
+		"Account": {Name: "Account", Service: "cellar", Type: reflect.TypeOf(bottle{})},
+	}
+
+	It("looks up the types by design name", func() {
+		Ω(registry.Lookup("Bottle").Service).Should(Equal("cellar"))
+		Ω(registry.Lookup("Unknown")).Should(BeNil())
+	})
+
+	It("creates new values of the types", func() {
+		v, ok := registry.New("Bottle")
+		Ω(ok).Should(BeTrue())
+		Ω(v).Should(Equal(&bottle{}))
+		_, ok = registry.New("Unknown")
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("lists the sorted names", func() {
+		Ω(registry.Names()).Should(Equal([]string{"Account", "Bottle"}))
+	})
+})
@@ -0,0 +1,111 @@
+package goa
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// TusResumable is the version of the tus resumable upload protocol implemented by the upload
+// offset endpoints mounted with MountUpload. It is the value of the Tus-Resumable header of the
+// requests and responses.
+const TusResumable = "1.0.0"
+
+// UploadContentType is the content type of the bodies of the requests that send upload chunks.
+const UploadContentType = "application/offset+octet-stream"
+
+var (
+	// ErrUploadConflict is the error returned to the requests that send an upload chunk at an
+	// offset that differs from the number of bytes already received.
+	ErrUploadConflict = NewErrorClass("upload_conflict", 409)
+
+	// ErrUnsupportedMediaType is the error returned to the requests that send an upload chunk
+	// with a content type other than UploadContentType.
+	ErrUnsupportedMediaType = NewErrorClass("unsupported_media_type", 415)
+)
+
+type (
+	// UploadStore keeps track of the uploads created by the resumable actions and stores their
+	// content, see MountUpload.
+	UploadStore interface {
+		// Offset returns the number of bytes received so far for the upload with the given
+		// identifier and its total length. It returns an error created with ErrNotFound if
+		// there is no such upload.
+		Offset(ctx context.Context, id string) (offset, length int64, err error)
+		// WriteChunk appends the content read from r to the upload with the given
+		// identifier, offset is the number of bytes received so far. It returns the number of
+		// bytes written which must be accounted for by Offset even if WriteChunk returns an
+		// error, e.g. because the client connection broke while reading r.
+		WriteChunk(ctx context.Context, id string, offset int64, r io.Reader) (int64, error)
+	}
+
+	// UploadFinisher is implemented by the upload stores that must be notified when all the
+	// content of an upload has been received.
+	UploadFinisher interface {
+		// Finish is called once the upload with the given identifier is complete.
+		Finish(ctx context.Context, id string) error
+	}
+)
+
+// MountUpload mounts the upload offset endpoints of a resumable action on the service mux. The
+// HEAD endpoint reports the number of bytes received in the Upload-Offset header, the PATCH
+// endpoint appends the request body to the upload if the Upload-Offset request header matches
+// that number and responds with the new offset. The upload identifier is captured by the
+// "uploadID" wildcard of path. The endpoints respond with status code 404 to the requests made
+// for uploads unknown to store.
+// This function is intended for the generated code, see the Resumable DSL.
+func (service *Service) MountUpload(path string, store UploadStore) {
+	ctrl := service.NewController("UploadController")
+	head := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		id := ContextRequest(ctx).Params.Get("uploadID")
+		offset, length, err := store.Offset(ctx, id)
+		if err != nil {
+			return err
+		}
+		rw.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		rw.Header().Set("Upload-Length", strconv.FormatInt(length, 10))
+		rw.Header().Set("Cache-Control", "no-store")
+		rw.Header().Set("Tus-Resumable", TusResumable)
+		rw.WriteHeader(http.StatusOK)
+		return nil
+	}
+	patch := func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		id := ContextRequest(ctx).Params.Get("uploadID")
+		if ct := req.Header.Get("Content-Type"); ct != UploadContentType {
+			return ErrUnsupportedMediaType("invalid upload chunk content type", "content-type", ct)
+		}
+		v := req.Header.Get("Upload-Offset")
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			return ErrBadRequest("invalid Upload-Offset header", "value", v)
+		}
+		current, length, err := store.Offset(ctx, id)
+		if err != nil {
+			return err
+		}
+		if offset != current {
+			return ErrUploadConflict("upload offset mismatch", "offset", current)
+		}
+		n, err := store.WriteChunk(ctx, id, offset, io.LimitReader(req.Body, length-offset))
+		if err != nil {
+			return err
+		}
+		offset += n
+		if offset == length {
+			if f, ok := store.(UploadFinisher); ok {
+				if err := f.Finish(ctx, id); err != nil {
+					return err
+				}
+			}
+		}
+		rw.Header().Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		rw.Header().Set("Tus-Resumable", TusResumable)
+		rw.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	service.Mux.Handle("HEAD", path, ctrl.MuxHandler("offset", head, nil))
+	service.Mux.Handle("PATCH", path, ctrl.RawMuxHandler("write", patch))
+	service.LogInfo("mount", "ctrl", "Upload", "action", "Offset", "route", "HEAD "+path)
+	service.LogInfo("mount", "ctrl", "Upload", "action", "Write", "route", "PATCH "+path)
+}
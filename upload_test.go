@@ -0,0 +1,110 @@
+package goa_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/goadesign/goa"
+	"github.com/goadesign/goa/middleware"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// memoryUpload is the state of an upload kept by memoryUploads.
+type memoryUpload struct {
+	length   int64
+	content  bytes.Buffer
+	finished bool
+}
+
+// memoryUploads is an in-memory goa.UploadStore.
+type memoryUploads map[string]*memoryUpload
+
+func (m memoryUploads) Offset(ctx context.Context, id string) (int64, int64, error) {
+	u, ok := m[id]
+	if !ok {
+		return 0, 0, goa.ErrNotFound("upload not found", "id", id)
+	}
+	return int64(u.content.Len()), u.length, nil
+}
+
+func (m memoryUploads) WriteChunk(ctx context.Context, id string, offset int64, r io.Reader) (int64, error) {
+	return io.Copy(&m[id].content, r)
+}
+
+func (m memoryUploads) Finish(ctx context.Context, id string) error {
+	m[id].finished = true
+	return nil
+}
+
+var _ = Describe("MountUpload", func() {
+	var s *goa.Service
+	var store memoryUploads
+	var rw *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		s = goa.New("test")
+		s.Encoder.Register(goa.NewJSONEncoder, "*/*")
+		s.Use(middleware.ErrorHandler(s, false))
+		store = memoryUploads{"abc": &memoryUpload{length: 6}}
+		store["abc"].content.WriteString("foo")
+		s.MountUpload("/uploads/:uploadID", store)
+		rw = httptest.NewRecorder()
+	})
+
+	patch := func(id, offset, contentType, body string) {
+		req, err := http.NewRequest("PATCH", "/uploads/"+id, strings.NewReader(body))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.Header.Set("Upload-Offset", offset)
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("Tus-Resumable", goa.TusResumable)
+		s.Mux.ServeHTTP(rw, req)
+	}
+
+	It("reports the upload offset", func() {
+		req, err := http.NewRequest("HEAD", "/uploads/abc", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(200))
+		Ω(rw.Header().Get("Upload-Offset")).Should(Equal("3"))
+		Ω(rw.Header().Get("Upload-Length")).Should(Equal("6"))
+		Ω(rw.Header().Get("Cache-Control")).Should(Equal("no-store"))
+		Ω(rw.Header().Get("Tus-Resumable")).Should(Equal(goa.TusResumable))
+	})
+
+	It("responds with 404 to unknown uploads", func() {
+		req, err := http.NewRequest("HEAD", "/uploads/unknown", nil)
+		Ω(err).ShouldNot(HaveOccurred())
+		s.Mux.ServeHTTP(rw, req)
+		Ω(rw.Code).Should(Equal(404))
+	})
+
+	It("appends the chunks and finishes the upload", func() {
+		patch("abc", "3", goa.UploadContentType, "barbaz")
+		Ω(rw.Code).Should(Equal(204))
+		Ω(rw.Header().Get("Upload-Offset")).Should(Equal("6"))
+		Ω(store["abc"].content.String()).Should(Equal("foobar"))
+		Ω(store["abc"].finished).Should(BeTrue())
+	})
+
+	It("rejects chunks sent at the wrong offset", func() {
+		patch("abc", "0", goa.UploadContentType, "foo")
+		Ω(rw.Code).Should(Equal(409))
+		Ω(store["abc"].content.String()).Should(Equal("foo"))
+	})
+
+	It("rejects invalid offsets", func() {
+		patch("abc", "three", goa.UploadContentType, "bar")
+		Ω(rw.Code).Should(Equal(400))
+	})
+
+	It("rejects other content types", func() {
+		patch("abc", "3", "application/json", "bar")
+		Ω(rw.Code).Should(Equal(415))
+		Ω(store["abc"].finished).Should(BeFalse())
+	})
+})
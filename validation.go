@@ -2,6 +2,7 @@ package goa
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"net/mail"
 	"net/url"
@@ -51,6 +52,9 @@ const (
 
 	// FormatRFC1123 defines RFC1123 date time values.
 	FormatRFC1123 = "rfc1123"
+
+	// FormatDecimal defines decimal number values encoded as strings, e.g. "12.50".
+	FormatDecimal = "decimal"
 )
 
 var (
@@ -59,6 +63,9 @@ var (
 
 	// Simple regular expression for IPv4 values, more rigorous checking is done via net.ParseIP
 	ipv4Regex = regexp.MustCompile(`^(?:[0-9]{1,3}\.){3}[0-9]{1,3}$`)
+
+	// Regular expression used to validate decimal values
+	decimalRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
 )
 
 // ValidateFormat validates a string against a standard format.
@@ -76,6 +83,7 @@ var (
 //     - "cidr": RFC4632 and RFC4291 CIDR notation IP address value
 //     - "regexp": Regular expression syntax accepted by RE2
 //     - "rfc1123": RFC1123 date time value
+//     - "decimal": decimal number value, e.g. "-12.50"
 func ValidateFormat(f Format, val string) error {
 	var err error
 	switch f {
@@ -115,6 +123,10 @@ func ValidateFormat(f Format, val string) error {
 		_, err = regexp.Compile(val)
 	case FormatRFC1123:
 		_, err = time.Parse(time.RFC1123, val)
+	case FormatDecimal:
+		if !decimalRegex.MatchString(val) {
+			err = fmt.Errorf("\"%s\" is not a decimal number", val)
+		}
 	default:
 		return fmt.Errorf("unknown format %#v", f)
 	}
@@ -147,3 +159,10 @@ func ValidatePattern(p string, val string) bool {
 	}
 	return r.MatchString(val)
 }
+
+// ValidateMultipleOf returns true if val is a multiple of m. It tolerates the rounding errors of
+// the floating point division so that for example 0.3 is a multiple of 0.1.
+func ValidateMultipleOf(val, m float64) bool {
+	q := val / m
+	return math.Abs(q-math.Round(q)) <= 1e-9*math.Max(1, math.Abs(q))
+}
@@ -0,0 +1,51 @@
+package goa
+
+type (
+	// ValidationRules describes the validations of the attributes of an object type defined in
+	// the design. The generated app package exposes the rules of each payload, media type and
+	// user type in its ValidationRules variable so that user interfaces and gateways may mirror
+	// the validations performed by the generated Validate methods without parsing the Swagger
+	// specification. The rules encode to JSON.
+	ValidationRules struct {
+		// Required lists the names of the required attributes.
+		Required []string `json:"required,omitempty"`
+		// RequiredTogether lists the groups of attributes that must be either all set or all
+		// missing.
+		RequiredTogether [][]string `json:"requiredTogether,omitempty"`
+		// MutuallyExclusive lists the groups of attributes of which at most one may be set.
+		MutuallyExclusive [][]string `json:"mutuallyExclusive,omitempty"`
+		// AtLeastOneOf lists the groups of attributes of which at least one must be set.
+		AtLeastOneOf [][]string `json:"atLeastOneOf,omitempty"`
+		// Attributes maps the names of the attributes in the encoded bodies to their rules.
+		Attributes map[string]*AttributeRules `json:"attributes,omitempty"`
+	}
+
+	// AttributeRules describes the validations of an attribute.
+	AttributeRules struct {
+		// Type is the name of the attribute type: boolean, integer, number, string,
+		// datetime, uuid, any, file, array, hash, object or the name of the Go type of user
+		// types and media types whose rules are listed under that name.
+		Type string `json:"type"`
+		// Enum lists the values the attribute may take.
+		Enum []interface{} `json:"enum,omitempty"`
+		// Format is the format of string values, see Format.
+		Format string `json:"format,omitempty"`
+		// Pattern is the regular expression string values must match.
+		Pattern string `json:"pattern,omitempty"`
+		// Minimum and Maximum are the inclusive bounds of numeric values.
+		Minimum *float64 `json:"minimum,omitempty"`
+		Maximum *float64 `json:"maximum,omitempty"`
+		// ExclusiveMinimum and ExclusiveMaximum are the exclusive bounds of numeric values.
+		ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+		// MultipleOf is the number numeric values must be a multiple of.
+		MultipleOf *float64 `json:"multipleOf,omitempty"`
+		// MinLength and MaxLength are the bounds of the length of strings, arrays and hashes.
+		MinLength *int `json:"minLength,omitempty"`
+		MaxLength *int `json:"maxLength,omitempty"`
+		// Elem describes the elements of arrays and the values of hashes.
+		Elem *AttributeRules `json:"elem,omitempty"`
+		// Object describes the attributes of inline objects.
+		Object *ValidationRules `json:"object,omitempty"`
+	}
+)
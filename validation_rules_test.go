@@ -0,0 +1,25 @@
+package goa_test
+
+import (
+	"encoding/json"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidationRules", func() {
+	It("encodes to JSON omitting the missing rules", func() {
+		min, max := 1.0, 10.0
+		rules := &goa.ValidationRules{
+			Required: []string{"name"},
+			Attributes: map[string]*goa.AttributeRules{
+				"name":  {Type: "string", Pattern: "^[a-z]+$"},
+				"count": {Type: "integer", Minimum: &min, Maximum: &max},
+			},
+		}
+		b, err := json.Marshal(rules)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(string(b)).Should(Equal(`{"required":["name"],"attributes":{"count":{"type":"integer","minimum":1,"maximum":10},"name":{"type":"string","pattern":"^[a-z]+$"}}}`))
+	})
+})
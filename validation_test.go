@@ -369,4 +369,44 @@ var _ = Describe("ValidateFormat", func() {
 			})
 		})
 	})
+
+	Context("Decimal", func() {
+		BeforeEach(func() {
+			f = goa.FormatDecimal
+		})
+
+		Context("with an invalid value", func() {
+			BeforeEach(func() {
+				val = "1.5e3"
+			})
+
+			It("does not validates", func() {
+				Ω(valErr).Should(HaveOccurred())
+			})
+		})
+
+		Context("with a valid value", func() {
+			BeforeEach(func() {
+				val = "-1250.75"
+			})
+
+			It("validates", func() {
+				Ω(valErr).ShouldNot(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("ValidateMultipleOf", func() {
+	It("validates multiples", func() {
+		Ω(goa.ValidateMultipleOf(9, 3)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(-1.5, 0.5)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(0.3, 0.1)).Should(BeTrue())
+		Ω(goa.ValidateMultipleOf(0, 0.1)).Should(BeTrue())
+	})
+
+	It("does not validate other values", func() {
+		Ω(goa.ValidateMultipleOf(10, 3)).Should(BeFalse())
+		Ω(goa.ValidateMultipleOf(0.35, 0.1)).Should(BeFalse())
+	})
 })
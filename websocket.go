@@ -0,0 +1,234 @@
+package goa
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketOptions configures the management of the websocket connections served by
+// Service.ServeWebSocket. The zero value disables all the checks.
+type WebSocketOptions struct {
+	// PingInterval is the interval between the ping frames sent to the client. The pong
+	// frames sent back by the client count as received data for IdleTimeout. No ping frame
+	// is sent if zero.
+	PingInterval time.Duration
+	// IdleTimeout is the maximum duration without receiving data from the client after which
+	// the connection is closed. It overrides the read deadlines set by the handler. No timeout
+	// applies if zero.
+	IdleTimeout time.Duration
+	// MaxDuration is the maximum duration of the connection. No limit applies if zero.
+	MaxDuration time.Duration
+}
+
+// wsGoingAway is the status code of the close frames sent by ServeWebSocket.
+const wsGoingAway = 1001
+
+type (
+	// wsResponseWriter wraps the response writer of the request upgraded by ServeWebSocket so
+	// that the hijacked connection is managed by a wsConn.
+	wsResponseWriter struct {
+		http.ResponseWriter
+		conn *wsConn
+		idle time.Duration
+	}
+
+	// wsConn is a hijacked connection that tracks the boundaries of the frames written by
+	// the websocket handler so that control frames can be written in between.
+	wsConn struct {
+		net.Conn
+		r       *bufio.Reader
+		idle    time.Duration
+		mu      sync.Mutex
+		started bool   // true once the handshake completed
+		header  []byte // partial header of the frame being written
+		left    uint64 // number of payload bytes left in the frame being written
+	}
+)
+
+// ServeWebSocket upgrades the request of ctx to a websocket connection and runs h with it. The
+// connection is closed once h returns, when it has been idle or open for longer than configured
+// by opts, when the request context is canceled (see CancelAll) or when the service HTTP server
+// shuts down. opts may be nil.
+func (service *Service) ServeWebSocket(ctx context.Context, h websocket.Handler, opts *WebSocketOptions) {
+	if opts == nil {
+		opts = &WebSocketOptions{}
+	}
+	rw := &wsResponseWriter{ResponseWriter: ContextResponse(ctx), idle: opts.IdleTimeout}
+	shutdown := service.shutdownSignal()
+	websocket.Handler(func(ws *websocket.Conn) {
+		done := make(chan struct{})
+		defer close(done)
+		rw.conn.start()
+		go rw.conn.manage(ctx, opts, shutdown, done)
+		h(ws)
+	}).ServeHTTP(rw, ContextRequest(ctx).Request)
+}
+
+// shutdownSignal returns a channel closed when the service HTTP server shuts down.
+func (service *Service) shutdownSignal() <-chan struct{} {
+	service.shutdownOnce.Do(func() {
+		service.shutdown = make(chan struct{})
+		if service.Server != nil {
+			service.Server.RegisterOnShutdown(func() { close(service.shutdown) })
+		}
+	})
+	return service.shutdown
+}
+
+// Hijack hijacks the underlying connection and wraps it into a wsConn.
+func (w *wsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	conn, brw, err := h.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	w.conn = &wsConn{Conn: conn, r: brw.Reader, idle: w.idle}
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+// start starts the tracking of the written frames and of the idle timeout once the handshake
+// response has been written.
+func (c *wsConn) start() {
+	c.mu.Lock()
+	c.started = true
+	c.mu.Unlock()
+	if c.idle > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.idle))
+	}
+}
+
+// Read reads from the connection and extends the idle timeout when data is received.
+func (c *wsConn) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 && c.idle > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.idle))
+	}
+	return n, err
+}
+
+// Write writes to the connection and records the position in the frame being written.
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n, err := c.Conn.Write(p)
+	if c.started {
+		c.advance(p[:n])
+	}
+	return n, err
+}
+
+// manage sends the ping frames and closes the connection when the limits set in opts are
+// reached, ctx is canceled or the server shuts down until done is closed.
+func (c *wsConn) manage(ctx context.Context, opts *WebSocketOptions, shutdown, done <-chan struct{}) {
+	var ping, expire <-chan time.Time
+	if opts.PingInterval > 0 {
+		t := time.NewTicker(opts.PingInterval)
+		defer t.Stop()
+		ping = t.C
+	}
+	if opts.MaxDuration > 0 {
+		t := time.NewTimer(opts.MaxDuration)
+		defer t.Stop()
+		expire = t.C
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case <-ping:
+			// Errors surface in the handler reads and writes.
+			c.writeControl(websocket.PingFrame, nil)
+		case <-expire:
+			c.close()
+			return
+		case <-ctx.Done():
+			c.close()
+			return
+		case <-shutdown:
+			c.close()
+			return
+		}
+	}
+}
+
+// close sends a close frame if possible and closes the connection.
+func (c *wsConn) close() {
+	status := make([]byte, 2)
+	binary.BigEndian.PutUint16(status, wsGoingAway)
+	c.writeControl(websocket.CloseFrame, status)
+	c.Conn.Close()
+}
+
+// writeControl writes a control frame with the given payload unless the handler is in the
+// middle of writing a frame.
+func (c *wsConn) writeControl(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.header) > 0 || c.left > 0 {
+		return nil
+	}
+	frame := append([]byte{0x80 | opcode, byte(len(payload))}, payload...)
+	_, err := c.Conn.Write(frame)
+	return err
+}
+
+// advance records the position in the frame being written after writing p.
+func (c *wsConn) advance(p []byte) {
+	for len(p) > 0 {
+		if c.left > 0 {
+			n := uint64(len(p))
+			if n > c.left {
+				n = c.left
+			}
+			c.left -= n
+			p = p[n:]
+			continue
+		}
+		c.header = append(c.header, p[0])
+		p = p[1:]
+		if size, ok := frameSize(c.header); ok {
+			c.header = c.header[:0]
+			c.left = size
+		}
+	}
+}
+
+// frameSize returns the payload length of the frame with the given header and true if the
+// header is complete, false otherwise.
+func frameSize(header []byte) (uint64, bool) {
+	if len(header) < 2 {
+		return 0, false
+	}
+	size := uint64(header[1] & 0x7f)
+	n := 2
+	switch size {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+	if header[1]&0x80 != 0 {
+		n += 4 // masking key
+	}
+	if len(header) < n {
+		return 0, false
+	}
+	switch size {
+	case 126:
+		size = uint64(binary.BigEndian.Uint16(header[2:4]))
+	case 127:
+		size = binary.BigEndian.Uint64(header[2:10])
+	}
+	return size, true
+}
@@ -0,0 +1,106 @@
+package goa_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/goadesign/goa"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+)
+
+var _ = Describe("ServeWebSocket", func() {
+	var service *goa.Service
+	var opts *goa.WebSocketOptions
+	var server *httptest.Server
+	var conn *websocket.Conn
+	var handlerDone chan struct{}
+
+	BeforeEach(func() {
+		service = goa.New("test")
+		opts = nil
+		handlerDone = make(chan struct{})
+	})
+
+	JustBeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := goa.NewContext(service.Context, w, r, nil)
+			service.ServeWebSocket(ctx, func(ws *websocket.Conn) {
+				defer close(handlerDone)
+				io.Copy(ws, ws)
+			}, opts)
+		}))
+		var err error
+		conn, err = websocket.Dial(strings.Replace(server.URL, "http", "ws", 1), "", "http://localhost/")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		server.Close()
+	})
+
+	read := func() (string, error) {
+		var msg string
+		err := websocket.Message.Receive(conn, &msg)
+		return msg, err
+	}
+
+	It("runs the handler", func() {
+		Ω(websocket.Message.Send(conn, "hello")).ShouldNot(HaveOccurred())
+		Ω(read()).Should(Equal("hello"))
+	})
+
+	Context("with an idle timeout", func() {
+		BeforeEach(func() {
+			opts = &goa.WebSocketOptions{IdleTimeout: 50 * time.Millisecond}
+		})
+
+		It("closes idle connections", func() {
+			Eventually(handlerDone).Should(BeClosed())
+			_, err := read()
+			Ω(err).Should(HaveOccurred())
+		})
+
+		Context("and pings", func() {
+			BeforeEach(func() {
+				opts.PingInterval = 10 * time.Millisecond
+			})
+
+			It("keeps the connections answering the pings open", func() {
+				msgs := make(chan string)
+				go func() {
+					defer GinkgoRecover()
+					msg, err := read()
+					Ω(err).ShouldNot(HaveOccurred())
+					msgs <- msg
+				}()
+				time.Sleep(200 * time.Millisecond)
+				Ω(websocket.Message.Send(conn, "hello")).ShouldNot(HaveOccurred())
+				Eventually(msgs).Should(Receive(Equal("hello")))
+			})
+		})
+	})
+
+	Context("with a maximum duration", func() {
+		BeforeEach(func() {
+			opts = &goa.WebSocketOptions{MaxDuration: 50 * time.Millisecond}
+		})
+
+		It("closes the connections", func() {
+			Eventually(handlerDone).Should(BeClosed())
+			_, err := read()
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	It("closes the connections when the service is canceled", func() {
+		Consistently(handlerDone, "50ms").ShouldNot(BeClosed())
+		service.CancelAll()
+		Eventually(handlerDone).Should(BeClosed())
+	})
+})